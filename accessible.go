@@ -0,0 +1,294 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"strings"
+)
+
+//======================================================================
+
+// Role says what a widget is for, in the terms used by screen readers and other assistive technology.
+type Role string
+
+const (
+	RoleNone        Role = ""
+	RoleButton      Role = "button"
+	RoleCheckBox    Role = "checkbox"
+	RoleRadioButton Role = "radiobutton"
+	RoleEdit        Role = "edit"
+	RoleList        Role = "list"
+	RoleText        Role = "text"
+	RoleProgressBar Role = "progressbar"
+	RoleDialog      Role = "dialog"
+	RoleMenu        Role = "menu"
+	RoleGroup       Role = "group"
+)
+
+// leafRoles are the roles of widgets whose children are part of them - the text of a button, say - and
+// so are not listed separately.
+var leafRoles = map[Role]bool{
+	RoleButton:      true,
+	RoleCheckBox:    true,
+	RoleRadioButton: true,
+	RoleEdit:        true,
+	RoleText:        true,
+	RoleProgressBar: true,
+}
+
+// IAccessible is implemented by a widget that declares what it is for - see AccessibilityTree. The empty
+// label of a widget like a button, whose children are part of it, is taken from the text beneath it.
+type IAccessible interface {
+	AccessibleRole() Role
+	AccessibleLabel(app IApp) string
+}
+
+// IAccessibleState is implemented by an accessible widget with a state to report, e.g. "checked", or
+// the text of an edit.
+type IAccessibleState interface {
+	AccessibleState(app IApp) string
+}
+
+// IAccessibleChildren is implemented by a container whose children aren't its SubWidget or SubWidgets,
+// like a list, so they can be found by AccessibilityTree. focus is the index of the child in focus, or -1.
+type IAccessibleChildren interface {
+	AccessibleChildren(app IApp) (children []IWidget, focus int)
+}
+
+// AccessibleWidget gives a widget a role and label of its own, or replaces those it declares. Otherwise it
+// passes everything through to the widget it wraps.
+type AccessibleWidget struct {
+	IWidget
+	role  Role
+	label string
+}
+
+var _ ICompositeWidget = (*AccessibleWidget)(nil)
+var _ IAccessible = (*AccessibleWidget)(nil)
+
+func NewAccessible(w IWidget, role Role, label string) *AccessibleWidget {
+	return &AccessibleWidget{
+		IWidget: w,
+		role:    role,
+		label:   label,
+	}
+}
+
+func (w *AccessibleWidget) String() string {
+	return fmt.Sprintf("accessible[%v]", w.IWidget)
+}
+
+func (w *AccessibleWidget) AccessibleRole() Role {
+	return w.role
+}
+
+func (w *AccessibleWidget) AccessibleLabel(app IApp) string {
+	return w.label
+}
+
+// AccessibleState returns the state of the widget wrapped, if it has one.
+func (w *AccessibleWidget) AccessibleState(app IApp) string {
+	if sw, ok := w.IWidget.(IAccessibleState); ok {
+		return sw.AccessibleState(app)
+	}
+	return ""
+}
+
+func (w *AccessibleWidget) SetLabel(label string, app IApp) {
+	w.label = label
+}
+
+func (w *AccessibleWidget) SubWidget() IWidget {
+	return w.IWidget
+}
+
+func (w *AccessibleWidget) SetSubWidget(inner IWidget, app IApp) {
+	w.IWidget = inner
+}
+
+func (w *AccessibleWidget) SubWidgetSize(size IRenderSize, focus Selector, app IApp) IRenderSize {
+	return size
+}
+
+//======================================================================
+
+// AccessibleNode is a widget with a role in a tree returned by AccessibilityTree.
+type AccessibleNode struct {
+	Role     Role
+	Label    string
+	State    string
+	Widget   IWidget
+	Focused  bool // True if the node is on the path to the focus
+	Children []*AccessibleNode
+}
+
+// AccessibilityTree returns the widgets beneath w, including w, that declare a role - see IAccessible. A
+// widget without a role is left out, and its children take its place. The result can be walked by a bridge
+// to a screen reader, or by a test looking for "the button labelled Quit". Unless w itself has a role,
+// the root of the tree has RoleNone.
+func AccessibilityTree(w IWidget, app IApp) *AccessibleNode {
+	nodes := accessibleNodes(w, true, app)
+	if len(nodes) == 1 && nodes[0].Widget == w {
+		return nodes[0]
+	}
+	return &AccessibleNode{
+		Widget:   w,
+		Focused:  true,
+		Children: nodes,
+	}
+}
+
+// AccessibilityTree returns the tree of accessible widgets in the app - see the package function.
+func (a *App) AccessibilityTree() *AccessibleNode {
+	return AccessibilityTree(a.SubWidget(), a)
+}
+
+// accessibleChildren returns the children of w, and the index of the one in focus, or -1.
+func accessibleChildren(w IWidget, app IApp) ([]IWidget, int) {
+	switch cw := w.(type) {
+	case IAccessibleChildren:
+		return cw.AccessibleChildren(app)
+	case IComposite:
+		if sub := cw.SubWidget(); sub != nil {
+			return []IWidget{sub}, 0
+		}
+	case ICompositeMultipleFocus:
+		return cw.SubWidgets(), cw.Focus()
+	case ICompositeMultiple:
+		return cw.SubWidgets(), -1
+	}
+	return nil, -1
+}
+
+func accessibleNodes(w IWidget, focused bool, app IApp) []*AccessibleNode {
+	children, focus := accessibleChildren(w, app)
+	aw, ok := w.(IAccessible)
+	if !ok || aw.AccessibleRole() == RoleNone {
+		res := []*AccessibleNode{}
+		for i, c := range children {
+			res = append(res, accessibleNodes(c, focused && i == focus, app)...)
+		}
+		return res
+	}
+	res := &AccessibleNode{
+		Role:    aw.AccessibleRole(),
+		Label:   aw.AccessibleLabel(app),
+		Widget:  w,
+		Focused: focused,
+	}
+	if sw, ok := w.(IAccessibleState); ok {
+		res.State = sw.AccessibleState(app)
+	}
+	below := []*AccessibleNode{}
+	for i, c := range children {
+		below = append(below, accessibleNodes(c, focused && i == focus, app)...)
+	}
+	if leafRoles[res.Role] {
+		if res.Label == "" {
+			res.Label = strings.Join(accessibleText(below), " ")
+		}
+	} else {
+		res.Children = below
+	}
+	return []*AccessibleNode{res}
+}
+
+// accessibleText returns the labels of the text beneath a widget.
+func accessibleText(nodes []*AccessibleNode) []string {
+	res := []string{}
+	for _, n := range nodes {
+		if n.Role == RoleText {
+			if n.Label != "" {
+				res = append(res, n.Label)
+			}
+		} else {
+			res = append(res, accessibleText(n.Children)...)
+		}
+	}
+	return res
+}
+
+// Find returns the first node in the tree, depth-first, with the given role, and label if it isn't "".
+func (n *AccessibleNode) Find(role Role, label string) (*AccessibleNode, bool) {
+	var res *AccessibleNode
+	n.Walk(func(c *AccessibleNode) bool {
+		if c.Role == role && (label == "" || c.Label == label) {
+			res = c
+			return false
+		}
+		return true
+	})
+	return res, res != nil
+}
+
+// FindAll returns every node in the tree with the given role, and label if it isn't "".
+func (n *AccessibleNode) FindAll(role Role, label string) []*AccessibleNode {
+	res := []*AccessibleNode{}
+	n.Walk(func(c *AccessibleNode) bool {
+		if c.Role == role && (label == "" || c.Label == label) {
+			res = append(res, c)
+		}
+		return true
+	})
+	return res
+}
+
+// Focus returns the deepest node on the path to the focus.
+func (n *AccessibleNode) Focus() *AccessibleNode {
+	for _, c := range n.Children {
+		if c.Focused {
+			return c.Focus()
+		}
+	}
+	return n
+}
+
+// Walk calls f with each node in the tree, depth-first, until f returns false. It returns false if f did.
+func (n *AccessibleNode) Walk(f func(n *AccessibleNode) bool) bool {
+	if !f(n) {
+		return false
+	}
+	for _, c := range n.Children {
+		if !c.Walk(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the tree as an outline, one node per line, e.g. `button "Quit" (focused)`.
+func (n *AccessibleNode) String() string {
+	var sb strings.Builder
+	n.describe(&sb, 0)
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func (n *AccessibleNode) describe(sb *strings.Builder, depth int) {
+	role := string(n.Role)
+	if role == "" {
+		role = "-"
+	}
+	sb.WriteString(strings.Repeat("  ", depth) + role)
+	if n.Label != "" {
+		fmt.Fprintf(sb, " %q", n.Label)
+	}
+	if n.State != "" {
+		fmt.Fprintf(sb, " [%s]", n.State)
+	}
+	if n.Focused {
+		sb.WriteString(" (focused)")
+	}
+	sb.WriteString("\n")
+	for _, c := range n.Children {
+		c.describe(sb, depth+1)
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,42 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+//======================================================================
+
+// ViewAnchor records where a widget that scrolls through items is, in a form that survives its data
+// being reloaded - the item in focus, by an ID that stays with it, and the row of the view it starts at.
+type ViewAnchor struct {
+	ID  interface{} // Identifies the item, e.g. a table's RowId
+	Row int         // The row of the view at which the item starts - negative if it starts above the view
+}
+
+// IViewAnchor is implemented by widgets that scroll through items, so that live data can be reloaded
+// without the view jumping under the user - see KeepView. The list and table widgets implement it.
+type IViewAnchor interface {
+	// Anchor returns the widget's anchor, as it was last rendered, and false if its focus has no ID.
+	Anchor(app IApp) (ViewAnchor, bool)
+	// RestoreAnchor focuses the item with the anchor's ID, at the anchor's row of the view. It returns
+	// false if there is no such item.
+	RestoreAnchor(a ViewAnchor, app IApp) bool
+}
+
+// KeepView calls reload, which should replace w's data - e.g. with SetModel or SetWalker - then puts the
+// item that was in focus back where it was in the view, if it is still there. It returns false if the view
+// couldn't be kept, in which case w is as reload left it.
+func KeepView(w IViewAnchor, app IApp, reload func(app IApp)) bool {
+	a, ok := w.Anchor(app)
+	reload(app)
+	if !ok {
+		return false
+	}
+	return w.RestoreAnchor(a, app)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,290 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package ansi
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// The keys sent as CSI n ~, by n
+var tildeKeys = map[int]tcell.Key{
+	1: tcell.KeyHome, 2: tcell.KeyInsert, 3: tcell.KeyDelete, 4: tcell.KeyEnd, 5: tcell.KeyPgUp,
+	6: tcell.KeyPgDn, 7: tcell.KeyHome, 8: tcell.KeyEnd,
+	11: tcell.KeyF1, 12: tcell.KeyF2, 13: tcell.KeyF3, 14: tcell.KeyF4, 15: tcell.KeyF5,
+	17: tcell.KeyF6, 18: tcell.KeyF7, 19: tcell.KeyF8, 20: tcell.KeyF9, 21: tcell.KeyF10,
+	23: tcell.KeyF11, 24: tcell.KeyF12,
+}
+
+// The keys sent as CSI x or SS3 x, by x
+var letterKeys = map[byte]tcell.Key{
+	'A': tcell.KeyUp, 'B': tcell.KeyDown, 'C': tcell.KeyRight, 'D': tcell.KeyLeft,
+	'H': tcell.KeyHome, 'F': tcell.KeyEnd, 'Z': tcell.KeyBacktab,
+	'P': tcell.KeyF1, 'Q': tcell.KeyF2, 'R': tcell.KeyF3, 'S': tcell.KeyF4,
+}
+
+// ParseInput turns bytes sent by a terminal into key and mouse events. Escape sequences are those of
+// xterm - cursor and function keys with modifiers, SGR mouse reports, and bracketed paste markers, which
+// become the keys tcell sends for them. An escape character followed by anything else is taken to be a
+// key pressed with Alt, and an escape character on its own, or beginning a sequence cut short, is the
+// Escape key - so data should hold whole sequences, as a terminal sends them.
+// Screen.Input keeps a sequence cut short until the rest arrives.
+func ParseInput(data []byte) []tcell.Event {
+	res, _ := parseInput(data, true)
+	return res
+}
+
+// maxSequence is the longest sequence waited for - anything longer is not from a terminal.
+const maxSequence = 256
+
+// parseInput is ParseInput, but unless final is true, it stops at an escape sequence or character that
+// data ends part way through, and returns the bytes it didn't parse.
+func parseInput(data []byte, final bool) ([]tcell.Event, []byte) {
+	res := make([]tcell.Event, 0, len(data))
+	for len(data) > 0 {
+		if !final && len(data) < maxSequence && incomplete(data) {
+			break
+		}
+		if n := pasteMarker(data); n > 0 {
+			res = append(res, markerKeys(data[1:n])...)
+			data = data[n:]
+			continue
+		}
+		var ev tcell.Event
+		var n int
+		if data[0] == 0x1b {
+			ev, n = parseEscape(data)
+		} else {
+			ev, n = parseKey(data, tcell.ModNone)
+		}
+		if ev != nil {
+			res = append(res, ev)
+		}
+		data = data[n:]
+	}
+	return res, data
+}
+
+// The markers a terminal in bracketed paste mode sends before and after pasted text
+var pasteMarkers = []string{"\x1b[200~", "\x1b[201~"}
+
+// pasteMarker returns the length of the bracketed paste marker at the start of data, or 0 if there isn't
+// one.
+func pasteMarker(data []byte) int {
+	for _, m := range pasteMarkers {
+		if strings.HasPrefix(string(data), m) {
+			return len(m)
+		}
+	}
+	return 0
+}
+
+// markerKeys returns the events tcell sends for a paste marker, which it doesn't recognize - Alt-[
+// followed by a key for each other character - so that the app's bracketed paste support sees the same
+// input on this screen as on a real terminal.
+func markerKeys(marker []byte) []tcell.Event {
+	res := []tcell.Event{tcell.NewEventKey(tcell.KeyRune, rune(marker[0]), tcell.ModAlt)}
+	for _, b := range marker[1:] {
+		res = append(res, tcell.NewEventKey(tcell.KeyRune, rune(b), tcell.ModNone))
+	}
+	return res
+}
+
+// incomplete returns true if data could be the start of an escape sequence or a UTF-8 character, but
+// not the whole of it. An escape character on its own might be the Escape key, or the start of a
+// sequence - only time will tell.
+func incomplete(data []byte) bool {
+	if data[0] != 0x1b {
+		return !utf8.FullRune(data)
+	}
+	if len(data) == 1 {
+		return true
+	}
+	switch data[1] {
+	case '[':
+		for _, b := range data[2:] {
+			if b >= 0x40 && b <= 0x7e {
+				return false
+			}
+		}
+		return true
+	case 'O':
+		return len(data) == 2
+	case 0x1b:
+		return false
+	}
+	return !utf8.FullRune(data[1:])
+}
+
+// parseKey returns the key at the start of data, which isn't an escape sequence, and the bytes it took.
+// Terminals send a carriage return for Enter; a line feed is Ctrl-J, as tcell has it, so that a pasted CR
+// LF is one line ending.
+func parseKey(data []byte, mod tcell.ModMask) (tcell.Event, int) {
+	r, n := utf8.DecodeRune(data)
+	switch r {
+	case '\r':
+		return tcell.NewEventKey(tcell.KeyEnter, 0, mod), n
+	case 0x7f:
+		return tcell.NewEventKey(tcell.KeyBackspace2, 0, mod), n
+	}
+	return tcell.NewEventKey(tcell.KeyRune, r, mod), n
+}
+
+// parseEscape returns the event for the escape sequence at the start of data, and the bytes it took. The
+// event is nil for a sequence that is understood but not reported, like a focus report.
+func parseEscape(data []byte) (tcell.Event, int) {
+	if len(data) == 1 {
+		return tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), 1
+	}
+	switch data[1] {
+	case '[':
+		if ev, n, ok := parseCSI(data); ok {
+			return ev, n
+		}
+		return tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), 1
+	case 'O':
+		if len(data) > 2 {
+			if k, ok := letterKeys[data[2]]; ok {
+				return tcell.NewEventKey(k, 0, tcell.ModNone), 3
+			}
+		}
+	case 0x1b:
+		return tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone), 1
+	}
+	// Alt with a key
+	ev, n := parseKey(data[1:], tcell.ModAlt)
+	return ev, n + 1
+}
+
+// parseCSI parses a control sequence - ESC [, parameters, and a final byte. It returns false if data
+// doesn't hold a whole one.
+func parseCSI(data []byte) (tcell.Event, int, bool) {
+	end := 2
+	for end < len(data) && (data[end] < 0x40 || data[end] > 0x7e) {
+		end++
+	}
+	if end == len(data) {
+		return nil, 0, false
+	}
+	n := end + 1
+	final := data[end]
+	params := string(data[2:end])
+
+	if strings.HasPrefix(params, "<") && (final == 'M' || final == 'm') {
+		ev := parseSGRMouse(params[1:], final == 'm')
+		return ev, n, ev != nil
+	}
+	args := make([]int, 0, 2)
+	for _, p := range strings.Split(params, ";") {
+		v, err := strconv.Atoi(p)
+		if err != nil && p != "" {
+			return nil, 0, false
+		}
+		args = append(args, v)
+	}
+	mod := tcell.ModNone
+	if len(args) > 1 {
+		mod = modifiers(args[1])
+	}
+	switch final {
+	case '~':
+		if k, ok := tildeKeys[args[0]]; ok {
+			return tcell.NewEventKey(k, 0, mod), n, true
+		}
+	case 'I', 'O':
+		// Focus reports
+		return nil, n, true
+	default:
+		if k, ok := letterKeys[final]; ok {
+			if k == tcell.KeyBacktab {
+				mod |= tcell.ModShift
+			}
+			return tcell.NewEventKey(k, 0, mod), n, true
+		}
+	}
+	return nil, n, true
+}
+
+// modifiers returns the modifier keys of an xterm modifier parameter, 1 plus a bit for each.
+func modifiers(p int) tcell.ModMask {
+	res := tcell.ModNone
+	p--
+	if p&1 != 0 {
+		res |= tcell.ModShift
+	}
+	if p&2 != 0 {
+		res |= tcell.ModAlt
+	}
+	if p&4 != 0 {
+		res |= tcell.ModCtrl
+	}
+	return res
+}
+
+// parseSGRMouse returns the mouse event of an SGR mouse report with parameters b;x;y, mapped to buttons as
+// tcell does.
+func parseSGRMouse(params string, release bool) tcell.Event {
+	f := strings.Split(params, ";")
+	if len(f) != 3 {
+		return nil
+	}
+	v := make([]int, 3)
+	for i := range f {
+		var err error
+		if v[i], err = strconv.Atoi(f[i]); err != nil {
+			return nil
+		}
+	}
+	b, x, y := v[0], v[1]-1, v[2]-1
+
+	button := tcell.ButtonNone
+	switch b & 0x43 {
+	case 0:
+		button = tcell.Button1
+	case 1:
+		button = tcell.Button2
+	case 2:
+		button = tcell.Button3
+	case 0x40:
+		button = tcell.WheelUp
+	case 0x41:
+		button = tcell.WheelDown
+	case 0x42:
+		button = tcell.WheelLeft
+	case 0x43:
+		button = tcell.WheelRight
+	}
+	if release && b&0x40 == 0 {
+		button = tcell.ButtonNone
+	}
+	mod := tcell.ModNone
+	if b&0x4 != 0 {
+		mod |= tcell.ModShift
+	}
+	if b&0x8 != 0 {
+		mod |= tcell.ModAlt
+	}
+	if b&0x10 != 0 {
+		mod |= tcell.ModCtrl
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+	return tcell.NewEventMouse(x, y, button, mod)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
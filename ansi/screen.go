@@ -0,0 +1,506 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package ansi provides a gowid screen for a terminal at the far end of a byte stream - a browser's
+// terminal emulator, say, or an SSH session - rather than the terminal the process is running in. The
+// screen writes ANSI escape sequences to an io.Writer, and turns the bytes the terminal sends back into
+// tcell events.
+package ansi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+	log "github.com/sirupsen/logrus"
+)
+
+//======================================================================
+
+// DefaultEscapeDelay is how long input ending part way through an escape sequence is kept waiting for
+// the rest, unless Options says otherwise. After that, an escape character on its own is the Escape key.
+var DefaultEscapeDelay = 50 * time.Millisecond
+
+// Options is used to configure the screen.
+type Options struct {
+	Colors      int           // The colors the terminal supports - 256 if 0; 1<<24 for true color
+	Charset     string        // The terminal's character set - "UTF-8" if empty
+	Term        string        // The terminal's name, its $TERM - "xterm-256color" if empty
+	EscapeDelay time.Duration // How long to wait for the rest of a sequence - DefaultEscapeDelay if 0
+}
+
+type cell struct {
+	mainc rune
+	combc []rune
+	style tcell.Style
+}
+
+func (c cell) same(o cell) bool {
+	if c.mainc != o.mainc || c.style != o.style || len(c.combc) != len(o.combc) {
+		return false
+	}
+	for i := range c.combc {
+		if c.combc[i] != o.combc[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Screen draws on the terminal at the other end of a stream. Each frame is sent with a single Write, as
+// the escape sequences that update the cells changed since the last. Feed the terminal's input to Input,
+// and tell the screen when the terminal changes size with Resize.
+type Screen struct {
+	mu      sync.Mutex
+	out     io.Writer
+	opts    Options
+	cols    int
+	rows    int
+	cells   []cell // What the app has drawn
+	shown   []cell // What the terminal shows; nil if it must be redrawn in full
+	style   tcell.Style
+	cursorX int
+	cursorY int
+	mouse   bool
+	events  chan tcell.Event
+	quit    chan struct{}
+	done    bool
+
+	inMu     sync.Mutex // Guards the input not yet parsed
+	pending  []byte     // Input ending part way through a sequence, waiting for the rest
+	inputGen int        // Changed by each Input, so an earlier call's flush does nothing
+}
+
+var _ gowid.IScreen = (*Screen)(nil)
+var _ gowid.IRawWriter = (*Screen)(nil)
+
+// NewScreen returns a screen of cols by rows that draws on the terminal at the other end of out.
+func NewScreen(out io.Writer, cols, rows int, opts ...Options) *Screen {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Colors == 0 {
+		opt.Colors = 256
+	}
+	if opt.Charset == "" {
+		opt.Charset = "UTF-8"
+	}
+	if opt.Term == "" {
+		opt.Term = "xterm-256color"
+	}
+	if opt.EscapeDelay <= 0 {
+		opt.EscapeDelay = DefaultEscapeDelay
+	}
+	res := &Screen{
+		out:     out,
+		opts:    opt,
+		cursorX: -1,
+		cursorY: -1,
+		events:  make(chan tcell.Event, 128),
+		quit:    make(chan struct{}),
+	}
+	res.resize(cols, rows)
+	return res
+}
+
+// ErrNoNewScreen is returned if an app on a remote terminal tries to replace its screen, e.g. to suspend
+// itself - there is no other terminal to give back.
+var ErrNoNewScreen = errors.New("a remote terminal's screen can't be replaced")
+
+// NewApp returns an app built from args that draws on screen. If args has no Log, the app's log is
+// discarded, rather than written to the file shared by a process's apps. If args has no Env, the app sees
+// only the screen's Term in its terminal's environment - the process's own environment describes some
+// other terminal, if any. A panic in the app doesn't end the process - see MainLoop - and isn't written to
+// the process's stderr unless args.Panic.Output says so.
+func NewApp(screen *Screen, args gowid.AppArgs) (*gowid.App, error) {
+	args.Screen = screen
+	args.NewScreen = func() (gowid.IScreen, error) {
+		return nil, ErrNoNewScreen
+	}
+	if args.Env == nil {
+		args.Env = func(name string) string {
+			if name == "TERM" {
+				return screen.Term()
+			}
+			return ""
+		}
+	}
+	if args.Log == nil {
+		logger := log.New()
+		logger.Out = ioutil.Discard
+		args.Log = logger
+	}
+	args.Panic.Exit = false
+	if args.Panic.Output == nil {
+		args.Panic.Output = ioutil.Discard
+	}
+	return gowid.NewApp(args)
+}
+
+// MainLoop runs app's main loop, like App.MainLoop, but if the app panics, only the loop ends - the
+// terminal is restored and the panic returned as a gowid.PanicInfo, rather than carried on up the
+// goroutine. A server running an app for each session uses it so that one session's panic leaves the
+// others running.
+func MainLoop(app *gowid.App, unhandled gowid.IUnhandledInput) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			info, ok := app.Panicked()
+			if !ok {
+				info = gowid.PanicInfo{Value: r, Stack: debug.Stack()}
+			}
+			err = info
+		}
+	}()
+	app.MainLoop(unhandled)
+	return nil
+}
+
+// ColorsOf returns the colors supported by a terminal, guessed from its name, the value of $TERM - 1<<24
+// for a "direct" or "truecolor" terminal, 256 for a "256color" terminal, and otherwise 8.
+func ColorsOf(term string) int {
+	switch {
+	case strings.Contains(term, "direct") || strings.Contains(term, "truecolor"):
+		return 1 << 24
+	case strings.Contains(term, "256color"):
+		return 256
+	default:
+		return 8
+	}
+}
+
+func (s *Screen) String() string {
+	return fmt.Sprintf("ansi[%dx%d]", s.cols, s.rows)
+}
+
+func (s *Screen) resize(cols, rows int) {
+	s.cols, s.rows = cols, rows
+	s.cells = make([]cell, cols*rows)
+	for i := range s.cells {
+		s.cells[i] = cell{mainc: ' ', style: s.style}
+	}
+	s.shown = nil
+}
+
+// Input turns bytes sent by the terminal into key and mouse events for the app - see ParseInput. The
+// bytes may come in pieces of any size, as read from a network connection: if data ends part way
+// through an escape sequence or character, the rest is waited for, until the next call or EscapeDelay
+// has passed. Then an escape character on its own is taken to be the Escape key.
+func (s *Screen) Input(data []byte) {
+	s.inMu.Lock()
+	defer s.inMu.Unlock()
+	evs, rest := parseInput(append(s.pending, data...), false)
+	s.pending = append([]byte(nil), rest...)
+	s.inputGen++
+	if len(s.pending) > 0 {
+		gen := s.inputGen
+		time.AfterFunc(s.opts.EscapeDelay, func() {
+			s.flushInput(gen)
+		})
+	}
+	for _, ev := range evs {
+		s.post(ev)
+	}
+}
+
+// flushInput parses the input left waiting by the Input call numbered gen, if nothing has come since.
+func (s *Screen) flushInput(gen int) {
+	s.inMu.Lock()
+	defer s.inMu.Unlock()
+	if gen != s.inputGen || len(s.pending) == 0 {
+		return
+	}
+	evs := ParseInput(s.pending)
+	s.pending = nil
+	for _, ev := range evs {
+		s.post(ev)
+	}
+}
+
+// Resize tells the screen, and the app, that the terminal is now cols by rows. The contents of the
+// screen are lost, and the app redraws them.
+func (s *Screen) Resize(cols, rows int) {
+	s.mu.Lock()
+	s.resize(cols, rows)
+	s.mu.Unlock()
+	s.post(tcell.NewEventResize(cols, rows))
+}
+
+func (s *Screen) post(ev tcell.Event) {
+	select {
+	case s.events <- ev:
+	case <-s.quit:
+	}
+}
+
+func (s *Screen) Init() error {
+	s.mu.Lock()
+	s.shown = nil
+	// The alternate screen, without a cursor until the app shows it
+	err := s.write("\x1b[?1049h\x1b[?25l")
+	cols, rows := s.cols, s.rows
+	s.mu.Unlock()
+	// As a tcell screen does, so that the app draws its first frame
+	s.post(tcell.NewEventResize(cols, rows))
+	return err
+}
+
+func (s *Screen) Fini() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done = true
+	close(s.quit)
+	seq := "\x1b[0m\x1b[?25h\x1b[?1049l"
+	if s.mouse {
+		seq = "\x1b[?1006l\x1b[?1003l\x1b[?1002l\x1b[?1000l" + seq
+	}
+	s.write(seq)
+}
+
+func (s *Screen) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.cells {
+		s.cells[i] = cell{mainc: ' ', style: s.style}
+	}
+}
+
+func (s *Screen) Show() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.show()
+}
+
+func (s *Screen) Sync() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shown = nil
+	s.show()
+}
+
+// show sends the cells that differ from those the terminal shows, or all of them if it must be redrawn.
+func (s *Screen) show() {
+	var buf bytes.Buffer
+	full := s.shown == nil
+	if full {
+		s.shown = make([]cell, len(s.cells))
+		buf.WriteString("\x1b[0m\x1b[2J")
+	}
+	// Where the terminal's cursor is, and the style it draws with; -1 if unknown
+	x, y := -1, -1
+	style := tcell.Style(-1)
+	if full {
+		style = tcell.StyleDefault
+	}
+	buf.WriteString("\x1b[?25l")
+	for row := 0; row < s.rows; row++ {
+		for col := 0; col < s.cols; col++ {
+			i := row*s.cols + col
+			c := s.cells[i]
+			if !full && c.same(s.shown[i]) {
+				continue
+			}
+			s.shown[i] = c
+			width := 1
+			if c.mainc >= ' ' {
+				width = runewidth.RuneWidth(c.mainc)
+			}
+			if width == 2 && col == s.cols-1 {
+				// A wide character doesn't fit in the last column
+				c.mainc, c.combc, width = ' ', nil, 1
+			}
+			if x != col || y != row {
+				fmt.Fprintf(&buf, "\x1b[%d;%dH", row+1, col+1)
+			}
+			if c.style != style {
+				buf.WriteString(sgr(c.style, s.opts.Colors))
+				style = c.style
+			}
+			switch {
+			case c.mainc < ' ' || c.mainc == 0x7f:
+				buf.WriteByte(' ')
+			default:
+				buf.WriteRune(c.mainc)
+				for _, r := range c.combc {
+					buf.WriteRune(r)
+				}
+			}
+			x, y = col+width, row
+			if width == 2 {
+				// The next cell is covered by this one; redraw it when the character changes
+				col++
+				s.shown[i+1] = cell{mainc: -1}
+			}
+		}
+	}
+	if s.cursorX >= 0 && s.cursorY >= 0 && s.cursorX < s.cols && s.cursorY < s.rows {
+		fmt.Fprintf(&buf, "\x1b[%d;%dH\x1b[?25h", s.cursorY+1, s.cursorX+1)
+	}
+	s.write(buf.String())
+}
+
+// sgr returns the escape sequence that makes the terminal draw in style.
+func sgr(style tcell.Style, colors int) string {
+	fg, bg, attrs := style.Decompose()
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[0")
+	for _, a := range []struct {
+		attr tcell.AttrMask
+		code string
+	}{
+		{tcell.AttrBold, ";1"},
+		{tcell.AttrDim, ";2"},
+		{tcell.AttrUnderline, ";4"},
+		{tcell.AttrBlink, ";5"},
+		{tcell.AttrReverse, ";7"},
+	} {
+		if attrs&a.attr != 0 {
+			buf.WriteString(a.code)
+		}
+	}
+	buf.WriteString(sgrColor(fg, 38, colors))
+	buf.WriteString(sgrColor(bg, 48, colors))
+	buf.WriteString("m")
+	return buf.String()
+}
+
+// sgrColor returns the parameters that set c as the foreground color, for base 38, or the background,
+// for base 48.
+func sgrColor(c tcell.Color, base int, colors int) string {
+	switch {
+	case c == tcell.ColorDefault:
+		return ""
+	case c&tcell.ColorIsRGB == 0 && c < 256:
+		return fmt.Sprintf(";%d;5;%d", base, c)
+	case colors > 256:
+		r, g, b := c.RGB()
+		return fmt.Sprintf(";%d;2;%d;%d;%d", base, r, g, b)
+	default:
+		// A named or RGB color the terminal can't show directly - choose the nearest of its palette
+		return fmt.Sprintf(";%d;5;%d", base, tcell.FindColor(c, paletteColors(colors)))
+	}
+}
+
+func paletteColors(colors int) []tcell.Color {
+	if colors > 256 || colors <= 0 {
+		colors = 256
+	}
+	res := make([]tcell.Color, colors)
+	for i := range res {
+		res[i] = tcell.Color(i)
+	}
+	return res
+}
+
+// write sends seq to the terminal. The screen's lock must be held.
+func (s *Screen) write(seq string) error {
+	if seq == "" {
+		return nil
+	}
+	_, err := io.WriteString(s.out, seq)
+	return err
+}
+
+// WriteRaw sends seq to the terminal as it is, for hyperlinks, pictures and the clipboard.
+func (s *Screen) WriteRaw(seq string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(seq)
+}
+
+func (s *Screen) Size() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cols, s.rows
+}
+
+func (s *Screen) Colors() int {
+	return s.opts.Colors
+}
+
+func (s *Screen) CharacterSet() string {
+	return s.opts.Charset
+}
+
+// Term returns the terminal's name, as it would be given by $TERM.
+func (s *Screen) Term() string {
+	return s.opts.Term
+}
+
+func (s *Screen) SetStyle(style tcell.Style) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.style = style
+}
+
+func (s *Screen) SetContent(x int, y int, mainc rune, combc []rune, style tcell.Style) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if x < 0 || y < 0 || x >= s.cols || y >= s.rows {
+		return
+	}
+	if mainc == 0 {
+		mainc = ' '
+	}
+	if style == tcell.StyleDefault {
+		style = s.style
+	}
+	s.cells[y*s.cols+x] = cell{mainc: mainc, combc: append([]rune(nil), combc...), style: style}
+}
+
+func (s *Screen) GetContent(x, y int) (mainc rune, combc []rune, style tcell.Style, width int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if x < 0 || y < 0 || x >= s.cols || y >= s.rows {
+		return ' ', nil, tcell.StyleDefault, 1
+	}
+	c := s.cells[y*s.cols+x]
+	return c.mainc, c.combc, c.style, runewidth.RuneWidth(c.mainc)
+}
+
+func (s *Screen) ShowCursor(x int, y int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursorX, s.cursorY = x, y
+}
+
+func (s *Screen) EnableMouse() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mouse = true
+	// Presses, drags and motion, reported as SGR sequences
+	s.write("\x1b[?1000h\x1b[?1002h\x1b[?1003h\x1b[?1006h")
+}
+
+// PollEvent waits for the next event from the terminal, returning nil once the screen is finalized.
+func (s *Screen) PollEvent() tcell.Event {
+	select {
+	case ev := <-s.events:
+		return ev
+	case <-s.quit:
+		return nil
+	}
+}
+
+func (s *Screen) PostEventWait(ev tcell.Event) {
+	s.post(ev)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,240 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package ansi
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func keyOf(t *testing.T, ev tcell.Event) (tcell.Key, rune, tcell.ModMask) {
+	kev, ok := ev.(*tcell.EventKey)
+	if !assert.True(t, ok, "%v is not a key", ev) {
+		return 0, 0, 0
+	}
+	return kev.Key(), kev.Rune(), kev.Modifiers()
+}
+
+func TestParseInput1(t *testing.T) {
+	evs := ParseInput([]byte("aé\r\x7f\x03"))
+	assert.Equal(t, 5, len(evs))
+	k, r, _ := keyOf(t, evs[1])
+	assert.Equal(t, tcell.KeyRune, k)
+	assert.Equal(t, 'é', r)
+	k, _, _ = keyOf(t, evs[2])
+	assert.Equal(t, tcell.KeyEnter, k)
+	k, _, _ = keyOf(t, evs[3])
+	assert.Equal(t, tcell.KeyBackspace2, k)
+	k, _, m := keyOf(t, evs[4])
+	assert.Equal(t, tcell.KeyCtrlC, k)
+	assert.Equal(t, tcell.ModCtrl, m)
+
+	evs = ParseInput([]byte("\x1b[A\x1b[1;5C\x1bOP\x1b[3~\x1b[15;2~\x1b[Z\x1bx\x1b"))
+	expected := []struct {
+		key tcell.Key
+		mod tcell.ModMask
+	}{
+		{tcell.KeyUp, tcell.ModNone},
+		{tcell.KeyRight, tcell.ModCtrl},
+		{tcell.KeyF1, tcell.ModNone},
+		{tcell.KeyDelete, tcell.ModNone},
+		{tcell.KeyF5, tcell.ModShift},
+		{tcell.KeyBacktab, tcell.ModShift},
+		{tcell.KeyRune, tcell.ModAlt},
+		{tcell.KeyEscape, tcell.ModNone},
+	}
+	if assert.Equal(t, len(expected), len(evs)) {
+		for i, e := range expected {
+			k, _, m := keyOf(t, evs[i])
+			assert.Equal(t, e.key, k, "event %d", i)
+			assert.Equal(t, e.mod, m, "event %d", i)
+		}
+	}
+
+	// Bracketed paste markers arrive as tcell sends them, Alt-[ and the rest as keys
+	evs = ParseInput([]byte("\x1b[200~hi\x1b[201~"))
+	if assert.Equal(t, 12, len(evs)) {
+		for i, r := range "[200~hi[201~" {
+			k, kr, m := keyOf(t, evs[i])
+			assert.Equal(t, tcell.KeyRune, k, "event %d", i)
+			assert.Equal(t, r, kr, "event %d", i)
+			if i == 0 || i == 7 {
+				assert.Equal(t, tcell.ModAlt, m, "event %d", i)
+			} else {
+				assert.Equal(t, tcell.ModNone, m, "event %d", i)
+			}
+		}
+	}
+}
+
+func TestParseMouse1(t *testing.T) {
+	evs := ParseInput([]byte("\x1b[<0;5;3M\x1b[<0;5;3m\x1b[<65;1;1M\x1b[<35;10;2M\x1b[<16;2;2M\x1b[<66;3;4M"))
+	if !assert.Equal(t, 6, len(evs)) {
+		return
+	}
+	expected := []struct {
+		x, y    int
+		buttons tcell.ButtonMask
+		mod     tcell.ModMask
+	}{
+		{4, 2, tcell.Button1, tcell.ModNone},
+		{4, 2, tcell.ButtonNone, tcell.ModNone},
+		{0, 0, tcell.WheelDown, tcell.ModNone},
+		{9, 1, tcell.ButtonNone, tcell.ModNone},
+		{1, 1, tcell.Button1, tcell.ModCtrl},
+		{2, 3, tcell.WheelLeft, tcell.ModNone},
+	}
+	for i, e := range expected {
+		mev, ok := evs[i].(*tcell.EventMouse)
+		if assert.True(t, ok) {
+			x, y := mev.Position()
+			assert.Equal(t, e.x, x, "event %d", i)
+			assert.Equal(t, e.y, y, "event %d", i)
+			assert.Equal(t, e.buttons, mev.Buttons(), "event %d", i)
+			assert.Equal(t, e.mod, mev.Modifiers(), "event %d", i)
+		}
+	}
+}
+
+func TestScreen1(t *testing.T) {
+	var out bytes.Buffer
+	s := NewScreen(&out, 4, 2)
+	assert.NoError(t, s.Init())
+	// Like tcell, the screen reports its size at first, so the app draws a frame
+	_, ok := s.PollEvent().(*tcell.EventResize)
+	assert.True(t, ok)
+	out.Reset()
+
+	red := tcell.StyleDefault.Foreground(tcell.ColorMaroon).Bold(true)
+	s.SetContent(0, 0, 'h', nil, tcell.StyleDefault)
+	s.SetContent(1, 0, 'i', nil, red)
+	s.Show()
+	// The first frame is drawn in full
+	assert.Equal(t, "\x1b[0m\x1b[2J\x1b[?25l\x1b[1;1Hh\x1b[0;1;38;5;1mi\x1b[0m  \x1b[2;1H    ", out.String())
+
+	// Later ones only change what has changed
+	out.Reset()
+	s.SetContent(3, 1, '世', nil, tcell.StyleDefault)
+	s.SetContent(2, 1, '!', nil, tcell.StyleDefault)
+	s.ShowCursor(1, 0)
+	s.Show()
+	assert.Equal(t, "\x1b[?25l\x1b[2;3H\x1b[0m! \x1b[1;2H\x1b[?25h", out.String())
+
+	r, _, _, _ := s.GetContent(2, 1)
+	assert.Equal(t, '!', r)
+
+	// Resizing loses the contents, and tells the app
+	s.Resize(3, 3)
+	cols, rows := s.Size()
+	assert.Equal(t, 3, cols)
+	assert.Equal(t, 3, rows)
+	ev, ok := s.PollEvent().(*tcell.EventResize)
+	if assert.True(t, ok) {
+		cols, rows = ev.Size()
+		assert.Equal(t, 3, rows)
+	}
+
+	s.Input([]byte("q"))
+	_, ok = s.PollEvent().(*tcell.EventKey)
+	assert.True(t, ok)
+	s.Fini()
+	assert.Nil(t, s.PollEvent())
+}
+
+func TestInput1(t *testing.T) {
+	var out bytes.Buffer
+	s := NewScreen(&out, 4, 2, Options{EscapeDelay: time.Hour})
+	defer s.Fini()
+
+	// A sequence split across reads, as from a network connection, is one key
+	s.Input([]byte("a\x1b["))
+	s.Input([]byte("1;5"))
+	s.Input([]byte("A\xe4"))
+	s.Input([]byte("\xb8\x96"))
+	k, r, _ := keyOf(t, s.PollEvent())
+	assert.Equal(t, 'a', r)
+	k, _, m := keyOf(t, s.PollEvent())
+	assert.Equal(t, tcell.KeyUp, k)
+	assert.Equal(t, tcell.ModCtrl, m)
+	k, r, _ = keyOf(t, s.PollEvent())
+	assert.Equal(t, tcell.KeyRune, k)
+	assert.Equal(t, '世', r)
+
+	// An escape on its own is the Escape key, once the rest hasn't come
+	s = NewScreen(&out, 4, 2, Options{EscapeDelay: time.Millisecond})
+	defer s.Fini()
+	s.Input([]byte("\x1b"))
+	k, _, _ = keyOf(t, s.PollEvent())
+	assert.Equal(t, tcell.KeyEscape, k)
+}
+
+func TestPaste1(t *testing.T) {
+	var out bytes.Buffer
+	s := NewScreen(&out, 10, 2)
+	app, err := NewApp(s, gowid.AppArgs{
+		View:           text.New("hi"),
+		BracketedPaste: true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	pasted := make(chan string, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- MainLoop(app, gowid.UnhandledInputFunc(func(app gowid.IApp, ev interface{}) bool {
+			if pev, ok := ev.(*gowid.PasteEvent); ok {
+				pasted <- pev.Text
+				app.Quit()
+			}
+			return true
+		}))
+	}()
+
+	// The app's bracketed paste support sees the markers
+	s.Input([]byte("\x1b[200~one\r\ntwo\x1b[201~"))
+	select {
+	case text := <-pasted:
+		assert.Equal(t, "one\ntwo", text)
+	case <-time.After(10 * time.Second):
+		t.Fatal("no paste event")
+	}
+	assert.NoError(t, <-done)
+}
+
+func TestNewApp1(t *testing.T) {
+	os.Setenv("TMUX", "/tmp/tmux-0/default,1,0")
+	defer os.Unsetenv("TMUX")
+	var out bytes.Buffer
+	app, err := NewApp(NewScreen(&out, 10, 2, Options{Term: "linux"}), gowid.AppArgs{
+		View: text.New("hi"),
+	})
+	if assert.NoError(t, err) {
+		// The process's environment is another terminal's
+		assert.Equal(t, "linux", app.Getenv("TERM"))
+		assert.Equal(t, "", app.Getenv("TMUX"))
+		assert.False(t, app.ClipboardSupported())
+	}
+}
+
+func TestSGR1(t *testing.T) {
+	style := tcell.StyleDefault.Foreground(tcell.NewRGBColor(255, 0, 0)).Background(tcell.Color(17)).Reverse(true)
+	assert.Equal(t, "\x1b[0;7;38;2;255;0;0;48;5;17m", sgr(style, 1<<24))
+	// Without true color, the nearest of the palette
+	assert.Equal(t, "\x1b[0;7;38;5;9;48;5;17m", sgr(style, 256))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
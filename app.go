@@ -5,8 +5,11 @@
 package gowid
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime/debug"
 	"strings"
@@ -19,10 +22,10 @@ import (
 
 //======================================================================
 
-// IGetScreen provides access to a tcell.Screen object e.g. for rendering
+// IGetScreen provides access to an IScreen object e.g. for rendering
 // a canvas to the terminal.
 type IGetScreen interface {
-	GetScreen() tcell.Screen
+	GetScreen() IScreen
 }
 
 // IColorMode provides access to a ColorMode value which represents the current
@@ -76,6 +79,8 @@ type IApp interface {
 	RefreshCopyMode()                                          // Give widgets another chance to display copy options (after the user perhaps adjusted the scope of a copy selection)
 	Clips() []ICopyResult                                      // If in copy-mode, the app will descend the widget hierarchy with a special user input, gathering options for copying data
 	CopyLevel(...int) int                                      // level we're at as we descend
+	CopyToClipboard(text string) error                         // Set the system clipboard via the terminal, if it supports OSC 52
+	ClipboardSupported() bool                                  // True if the terminal is thought to support CopyToClipboard
 }
 
 // App is an implementation of IApp. The App struct conforms to IApp and
@@ -83,7 +88,8 @@ type IApp interface {
 // palette, the screen and the state of the mouse.
 type App struct {
 	IPalette                                 // App holds an IPalette and provides it to each widget when rendering
-	screen            tcell.Screen           // Each app has one screen
+	screen            IScreen                // Each app has one screen
+	newScreen         ScreenFactory          // Creates a screen each time the app takes over the terminal
 	TCellEvents       chan tcell.Event       // Events from tcell e.g. resize
 	AfterRenderEvents chan IAfterRenderEvent // Functions intended to run on the widget goroutine
 	closing           bool                   // If true then app is in process of closing - it may be draining AfterRenderEvents.
@@ -99,20 +105,130 @@ type App struct {
 	prevWasMouseMove  bool // True if we last processed simple mouse movement. We can optimize on slow
 	// systems by discarding subsequent mouse movement events.
 
-	lastMouse    MouseState    // So I can tell if a button was previously clicked
-	MouseState                 // Track which mouse buttons are currently down
-	ClickTargets               // When mouse is clicked, track potential interaction here
-	log          log.StdLogger // For any application logging
+	lastMouse        MouseState      // So I can tell if a button was previously clicked
+	MouseState                       // Track which mouse buttons are currently down
+	ClickTargets                     // When mouse is clicked, track potential interaction here
+	log              log.StdLogger   // For any application logging
+	profiler         *Profiler       // If not nil, widget Render and UserInput timings are recorded here
+	profileOverlay   int             // If > 0, display this many of the most expensive profile entries on screen
+	crash            *crashReporter  // If not nil, recent input and the last frame are tracked for crash reports
+	runner           *AppRunner      // If not nil, the runner currently feeding tcell events to the app
+	clock            IClock          // Source of time for timers and animations
+	cache            ICache          // If not nil, the cache provided to widgets; otherwise DefaultCache
+	recorder         *eventRecorder  // If not nil, input events are recorded here
+	find             *finder         // If not nil, find mode is enabled; matches in each frame are highlighted
+	frames           *frameScheduler // If not nil, redraws are coalesced and rate-limited
+	paste            *pasteDetector  // If not nil, bracketed paste sequences are turned into PasteEvents
+	clipboard        string          // The text last sent to the clipboard
+	clipboardSupport *bool           // If not nil, overrides detection of OSC 52 support
+	stylesheet       *Stylesheet     // If not nil, widgets are styled by its rules as they are rendered
+	theme            *Theme          // If not nil, roles and palette entries are looked up here first
+	layoutUndo       *layoutUndo     // If not nil, layout changes are recorded so they can be undone
+	workspaces       workspaces      // Root layouts the app can switch between
+	hyperlinkSupport *bool           // If not nil, overrides detection of OSC 8 support
+	links            []hyperlinkRun  // The linked text in the last frame, drawn after tcell has shown it
+	graphics         graphicsState   // How to draw pictures, and the raw regions drawn after tcell has shown a frame
+	panicOpts        PanicOptions    // How panics recovered by RecoverPanic are reported
+	styleStack       styleStack      // The widgets being rendered, outermost first, for the stylesheet
+	keyMap           *KeyMap         // If not nil, consulted for each keypress before the widgets
+	focusKeys        *focusTraverser // If not nil, keys unhandled by widgets can move the focus
+	keyCast          *keyCaster      // If not nil, recently pressed keys are shown in a corner of the screen
+	keyRepeat        *keyRepeater    // If not nil, queued repeats of keys are handled in batches
+	locating         *widgetLocator  // If not nil, LocateWidget is finding where a widget is drawn
+	grab             *inputGrab      // If not nil, a widget is getting all key and mouse events
+	help             *helpState      // If not nil, a key shows help for the widget in focus
+	console          *LogConsole     // If not nil, a key shows the app's recent logs
+	capture          *outputCapture  // If not nil, stdout and stderr are redirected while the app has the terminal
+	drag             dragTracker     // Turns mouse presses, movement and releases into drags
+	clicks           clickTracker    // Counts clicks, for double and triple clicks
+	hover            hoverTracker    // Tracks the widgets under the mouse pointer
+	panicDuring      string          // What the app was doing, if a widget panics
+	panicked         *PanicInfo      // The panic reported, if any
+	asciiOnly        bool            // True if widgets should draw decorations with ASCII only
+	reduceMotion     bool            // True if animation should be kept to a minimum
+
+	getenv func(string) string // The environment of the app's terminal - see Getenv
+	ctx    context.Context     // Done when the app quits - see Context
+	cancel context.CancelFunc
+	sizing *sizeChecker // If not nil, canvases are checked against the sizes asked for
+
+	downgrade  ColorDowngradeOptions // How colors the terminal can't show are drawn
+	cursor     cursorState           // The cursor's style and visibility
+	title      titleState            // The terminal title and icon name set by the app
+	signals    signalState           // The signals the app handles, and callbacks for them
+	termColors termColorState        // The colors the terminal has reported, and callbacks for them
+	scroll     scrollAccumulator     // Adds up fractional scrolls into whole rows and columns
+	tx         transactionState      // The transactions in progress - see Transaction
 }
 
 var _ IApp = (*App)(nil)
+var _ IProfiled = (*App)(nil)
+var _ IClocked = (*App)(nil)
+var _ ICached = (*App)(nil)
+var _ IStylesheeted = (*App)(nil)
+var _ IThemed = (*App)(nil)
+var _ ILayoutRecorder = (*App)(nil)
+var _ ITransactional = (*App)(nil)
 
 // AppArgs is a helper struct, providing arguments for the initialization of App.
 type AppArgs struct {
-	View         IWidget
-	Palette      IPalette
-	Log          log.StdLogger
-	DontActivate bool
+	View           IWidget
+	Palette        IPalette
+	Log            log.StdLogger
+	DontActivate   bool
+	Clock          IClock          // If nil, DefaultClock is used
+	Cache          ICache          // If nil, DefaultCache is used - see CacheFor
+	Screen         IScreen         // If nil, a screen is created with NewScreen
+	NewScreen      ScreenFactory   // Creates the app's screens - NewTCellScreen if nil
+	MaxFPS         int             // If > 0, limit the rate of redraws - see SetMaxFPS
+	AdaptiveRedraw bool            // If true, redraw less often if the terminal is slow - see EnableAdaptiveRedraw
+	BracketedPaste bool            // If true, pasted text is delivered as a PasteEvent - see EnableBracketedPaste
+	Stylesheet     *Stylesheet     // If not nil, widgets are styled by its rules - see SetStylesheet
+	Theme          *Theme          // If not nil, the initial theme - see SetTheme
+	Panic          PanicOptions    // How panics are reported - see SetPanicOptions
+	KeyMap         *KeyMap         // If not nil, app-wide key bindings - see SetKeyMap
+	KeyCast        *KeyCastOptions // If not nil, recently pressed keys are shown - see SetKeyCast
+	Help           *HelpOptions    // If not nil, F1 shows help for the widget in focus - see SetHelp
+
+	// LogConsole, if not nil, mirrors the app's logs into a console shown with F12 - see SetLogConsole
+	LogConsole *LogConsoleOptions
+
+	// CaptureOutput, if not nil, keeps the process's stdout and stderr off the screen - see SetOutputCapture
+	CaptureOutput *OutputCaptureOptions
+
+	// KeyRepeat, if not nil, shapes auto-repeated keys - see SetKeyRepeat
+	KeyRepeat *KeyRepeatOptions
+
+	// SmoothScroll, if not nil, makes trackpad scrolling smooth - see SetSmoothScroll
+	SmoothScroll *SmoothScrollOptions
+
+	// ASCIIOnly, if true, makes the built-in widgets draw decorations in ASCII - see App.SetASCIIOnly
+	ASCIIOnly bool
+
+	// ReduceMotion, if true, keeps animation to a minimum - see App.SetReduceMotion
+	ReduceMotion bool
+
+	// QueryTerminalColors, if true, asks the terminal for its colors each time the screen is activated -
+	// see App.QueryTerminalColors
+	QueryTerminalColors bool
+
+	// ColorDowngrade says how colors the terminal can't show are drawn - see SetColorDowngrade
+	ColorDowngrade ColorDowngradeOptions
+
+	// StrictSizing, if not nil, checks that widgets' canvases are the size asked for - see SetStrictSizing
+	StrictSizing *StrictSizingOptions
+
+	// Env looks up variables in the environment of the app's terminal - if nil, os.Getenv; see App.Getenv
+	Env func(string) string
+
+	// Cursor is the style of the cursor, unless the widget in focus asks for another - see SetCursorStyle
+	Cursor CursorStyle
+
+	// Title, if not empty, is the terminal's title while the app runs - see SetTitle
+	Title string
+
+	// JobControl, if true, makes ctrl-z and SIGTSTP suspend the app to the shell - see SetJobControl
+	JobControl bool
 }
 
 // IUnhandledInput is used as a handler for application user input that is not handled by any
@@ -230,14 +346,48 @@ func NewApp(args AppArgs) (rapp *App, rerr error) {
 	return app, nil
 }
 
+var (
+	defaultLogger    log.StdLogger
+	defaultLoggerMtx sync.Mutex
+)
+
+// defaultLog returns the log for apps that aren't given one - a file in the current directory, named
+// after the program. The apps in a process share it, rather than each truncating the file.
+func defaultLog() (log.StdLogger, error) {
+	defaultLoggerMtx.Lock()
+	defer defaultLoggerMtx.Unlock()
+	if defaultLogger == nil {
+		logname := filepath.Base(os.Args[0])
+		logname = fmt.Sprintf("%s.log", strings.TrimSuffix(logname, filepath.Ext(logname)))
+		logfile, err := os.Create(logname)
+		if err != nil {
+			return nil, err
+		}
+		logger := log.New()
+		logger.Out = logfile
+		defaultLogger = logger
+	}
+	return defaultLogger, nil
+}
+
 // NewAppSafe returns an initialized App struct, or an error on failure. It will
 // initialize a tcell.Screen object behind the scenes, and enable mouse support
 // meaning that tcell will receive mouse events if the terminal supports them.
 func newApp(args AppArgs) (rapp *App, rerr error) {
-	screen, err := tcell.NewScreen()
-	if err != nil {
-		rerr = WithKVs(err, map[string]interface{}{"TERM": os.Getenv("TERM")})
-		return
+	if args.NewScreen == nil {
+		args.NewScreen = NewTCellScreen
+	}
+	if args.Env == nil {
+		args.Env = os.Getenv
+	}
+	screen := args.Screen
+	if screen == nil {
+		var err error
+		screen, err = args.NewScreen()
+		if err != nil {
+			rerr = WithKVs(err, map[string]interface{}{"TERM": args.Env("TERM")})
+			return
+		}
 	}
 
 	var palette IPalette = args.Palette
@@ -251,20 +401,17 @@ func newApp(args AppArgs) (rapp *App, rerr error) {
 	clicks := MakeClickTargets()
 
 	if args.Log == nil {
-		logname := filepath.Base(os.Args[0])
-		logname = fmt.Sprintf("%s.log", strings.TrimSuffix(logname, filepath.Ext(logname)))
-		logfile, err := os.Create(logname)
+		logger, err := defaultLog()
 		if err != nil {
 			return nil, err
 		}
-		logger := log.New()
-		logger.Out = logfile
 		args.Log = logger
 	}
 
 	res := &App{
 		IPalette:          palette,
 		screen:            screen,
+		newScreen:         args.NewScreen,
 		TCellEvents:       tch,
 		AfterRenderEvents: wch,
 		closing:           false,
@@ -273,10 +420,51 @@ func newApp(args AppArgs) (rapp *App, rerr error) {
 		colorMode:         Mode256Colors,
 		ClickTargets:      clicks,
 		log:               args.Log,
+		clock:             args.Clock,
+		cache:             args.Cache,
+		stylesheet:        args.Stylesheet,
+		theme:             args.Theme,
+		panicOpts:         args.Panic,
+		keyMap:            args.KeyMap,
+		workspaces:        workspaces{current: -1},
+		getenv:            args.Env,
+		downgrade:         args.ColorDowngrade,
+		cursor:            cursorState{style: args.Cursor},
+		title:             titleState{title: args.Title, hasTitle: args.Title != ""},
+	}
+	res.ctx, res.cancel = context.WithCancel(context.Background())
+
+	if args.MaxFPS > 0 {
+		res.frames = newFrameScheduler(args.MaxFPS)
+	}
+	if args.AdaptiveRedraw {
+		res.EnableAdaptiveRedraw()
+	}
+	if args.BracketedPaste {
+		res.paste = &pasteDetector{}
+	}
+	if args.KeyCast != nil {
+		res.keyCast = newKeyCaster(*args.KeyCast)
+	}
+	res.SetKeyRepeat(args.KeyRepeat)
+	res.SetSmoothScroll(args.SmoothScroll)
+	res.SetASCIIOnly(args.ASCIIOnly)
+	res.SetReduceMotion(args.ReduceMotion)
+	if args.Help != nil {
+		res.help = newHelpState(*args.Help)
+	}
+	if args.LogConsole != nil {
+		res.SetLogConsole(args.LogConsole)
+	}
+	if args.CaptureOutput != nil {
+		res.capture = newOutputCapture(*args.CaptureOutput) // Started with the screen
 	}
+	res.SetStrictSizing(args.StrictSizing)
+	res.termColors.onActivate = args.QueryTerminalColors
+	res.SetJobControl(args.JobControl)
 
 	if !args.DontActivate {
-		if err = res.initScreen(); err != nil {
+		if err := res.initScreen(); err != nil {
 			return nil, err
 		}
 		res.initColorMode()
@@ -289,24 +477,20 @@ func newApp(args AppArgs) (rapp *App, rerr error) {
 }
 
 func (a *App) initColorMode() {
-	cols := a.screen.Colors()
-	switch {
-	case cols > 256:
-		a.SetColorMode(Mode24BitColors)
-	case cols == 256:
-		a.SetColorMode(Mode256Colors)
-	case cols == 88:
-		a.SetColorMode(Mode88Colors)
-	case cols == 16:
-		a.SetColorMode(Mode16Colors)
-	case cols < 0:
-		a.SetColorMode(ModeMonochrome)
-	default:
-		a.SetColorMode(Mode8Colors)
+	a.SetColorMode(DetectColorMode(a.screen.Colors(), a.Getenv))
+}
+
+// Getenv returns the value of a variable in the environment of the app's terminal - the process's own
+// environment, unless the app was given another, e.g. because its terminal is remote. Detection of what
+// the terminal supports, like hyperlinks and the clipboard, looks here.
+func (a *App) Getenv(name string) string {
+	if a.getenv == nil {
+		return os.Getenv(name)
 	}
+	return a.getenv(name)
 }
 
-func (a *App) GetScreen() tcell.Screen {
+func (a *App) GetScreen() IScreen {
 	return a.screen
 }
 
@@ -369,6 +553,47 @@ func (a *App) GetLastMouseState() MouseState {
 	return a.lastMouse
 }
 
+// EnableProfiling switches on instrumentation of the widget hierarchy. The root
+// widget's Render and UserInput calls are always measured, under the name
+// "root"; wrap other widgets of interest with NewProfiledWidget. Any previous
+// measurements are discarded.
+func (a *App) EnableProfiling(opts ...ProfilerOptions) {
+	a.profiler = NewProfiler(opts...)
+}
+
+// DisableProfiling switches off instrumentation of the widget hierarchy.
+func (a *App) DisableProfiling() {
+	a.profiler = nil
+	a.profileOverlay = 0
+}
+
+// Profiler returns the app's current Profiler, or nil if profiling is not
+// enabled. It lets App conform to IProfiled.
+func (a *App) Profiler() *Profiler {
+	return a.profiler
+}
+
+// SetProfileOverlay displays the n most expensive profile entries in the
+// top-right corner of the screen each time the app is rendered. Set n to 0
+// to hide the overlay.
+func (a *App) SetProfileOverlay(n int) {
+	a.profileOverlay = n
+}
+
+// Clock returns the source of time used by the app's widgets. It lets App
+// conform to IClocked.
+func (a *App) Clock() IClock {
+	if a.clock == nil {
+		return DefaultClock
+	}
+	return a.clock
+}
+
+// SetClock replaces the app's source of time, e.g. with a FakeClock.
+func (a *App) SetClock(clock IClock) {
+	a.clock = clock
+}
+
 func (a *App) SetColorMode(mode ColorMode) {
 	a.colorMode = mode
 }
@@ -460,12 +685,36 @@ func (a *App) Clips() []ICopyResult {
 // input can be processed; other events might result in gowid updating its
 // internal state, like the size of the underlying terminal.
 func (a *App) HandleTCellEvent(ev interface{}, unhandled IUnhandledInput) {
+	a.dispatchTCellEvent(ev, unhandled)
+	a.handleKeyRepeats(ev, unhandled)
+}
+
+func (a *App) dispatchTCellEvent(ev interface{}, unhandled IUnhandledInput) {
+	a.recordEvent(ev)
+	for _, ev := range a.termColors.filter(ev, a) {
+		evs := []interface{}{ev}
+		if a.paste != nil {
+			evs = a.paste.filter(ev, a.Clock())
+		}
+		for _, ev := range evs {
+			for _, ev := range a.scroll.filter(ev, a.Clock()) {
+				a.handleTCellEvent(ev, unhandled)
+			}
+		}
+	}
+}
+
+func (a *App) handleTCellEvent(ev interface{}, unhandled IUnhandledInput) {
 	switch ev := ev.(type) {
+	case *PasteEvent:
+		a.handleInputEvent(ev, unhandled)
+		a.redraw()
 	case *tcell.EventKey:
 		// This makes for a better experience on limited hardware like raspberry pi
 		debug.SetGCPercent(-1)
 		defer debug.SetGCPercent(100)
 		cm := a.InCopyMode()
+		a.keyCastInput(ev)
 		a.handleInputEvent(ev, unhandled)
 		newCopyMode := (!cm && a.InCopyMode())
 		if newCopyMode || a.refreshCopy {
@@ -478,9 +727,9 @@ func (a *App) HandleTCellEvent(ev interface{}, unhandled IUnhandledInput) {
 			a.handleInputEvent(CopyModeEvent{}, unhandled)
 			a.refreshCopy = false
 		}
-		a.RedrawTerminal()
+		a.redraw()
 	case *tcell.EventMouse:
-		if !a.prevWasMouseMove || ev.Modifiers() != 0 || ev.Buttons() != 0 {
+		if !a.prevWasMouseMove || ev.Modifiers() != 0 || ev.Buttons() != 0 || a.hover.enabled {
 			switch ev.Buttons() {
 			case tcell.Button1:
 				a.MouseLeftClicked = true
@@ -501,7 +750,7 @@ func (a *App) HandleTCellEvent(ev interface{}, unhandled IUnhandledInput) {
 			}
 			a.lastMouse = a.MouseState
 			a.MouseState = MouseState{}
-			a.RedrawTerminal()
+			a.redraw()
 		}
 	case *tcell.EventResize:
 		if flog, ok := a.log.(log.FieldLogger); ok {
@@ -509,7 +758,9 @@ func (a *App) HandleTCellEvent(ev interface{}, unhandled IUnhandledInput) {
 		} else {
 			a.log.Printf("Terminal was resized\n")
 		}
-		a.RedrawTerminal()
+		// tcell repaints the screen, so raw regions must be drawn again
+		a.graphics.redraw = true
+		a.redraw()
 	case *tcell.EventInterrupt:
 		if flog, ok := a.log.(log.FieldLogger); ok {
 			flog.WithField("event", ev).Infof("Interrupt event from tcell")
@@ -540,7 +791,23 @@ func (a *App) HandleTCellEvent(ev interface{}, unhandled IUnhandledInput) {
 // Close should be called by a gowid application after the user terminates the application.
 // It will cleanup tcell's screen object.
 func (a *App) Close() {
+	// MainLoopCtx replaces the context, and its cancel function, under the same lock
+	a.closingMtx.Lock()
+	cancel := a.cancel
+	a.closingMtx.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	if a.screen == nil {
+		return
+	}
+	if a.paste != nil {
+		a.setBracketedPasteMode(false)
+	}
+	a.writeCursorStyle(CursorStyle{})
+	a.restoreTitle()
 	a.screen.Fini()
+	a.pauseCapture()
 }
 
 // StartTCellEvents starts a goroutine that listens for events from TCell. The
@@ -609,6 +876,7 @@ func (a *App) Runner() *AppRunner {
 func (st *AppRunner) Start() {
 	st.app.StartTCellEvents(st.quitCh, &st.wg)
 	st.started = true
+	st.app.runner = st
 }
 
 func (st *AppRunner) Stop() {
@@ -616,6 +884,9 @@ func (st *AppRunner) Stop() {
 		st.app.StopTCellEvents(st.quitCh, &st.wg)
 		st.started = false
 	}
+	if st.app.runner == st {
+		st.app.runner = nil
+	}
 }
 
 // MainLoop is the intended gowid entry point for typical applications. After the App
@@ -623,6 +894,8 @@ func (st *AppRunner) Stop() {
 // with a handler for processing input that is not consumed by any widget.
 func (a *App) MainLoop(unhandled IUnhandledInput) {
 	defer a.Close()
+	defer a.RecoverPanic()
+	defer a.saveCrashReportOnPanic()
 	st := a.Runner()
 	st.Start()
 	defer st.Stop()
@@ -631,10 +904,10 @@ func (a *App) MainLoop(unhandled IUnhandledInput) {
 
 // RunThenRenderEvent dispatches the event by calling it with the
 // app as an argument - then it will force the application to re-render
-// itself.
+// itself (subject to any limit set with SetMaxFPS).
 func (a *App) RunThenRenderEvent(ev IAfterRenderEvent) {
 	ev.RunThenRenderEvent(a)
-	a.RedrawTerminal()
+	a.redraw()
 }
 
 // handleEvents processes all gowid events. These can be either app-generated events
@@ -651,7 +924,12 @@ Loop:
 				break Loop
 			}
 			a.RunThenRenderEvent(ev)
+		case <-a.frames.due():
+			a.frames.timer = nil
+		case <-a.BracketedPasteTimeout():
+			a.FlushBracketedPaste(unhandled)
 		}
+		a.RedrawIfDue()
 	}
 }
 
@@ -660,9 +938,46 @@ Loop:
 // currentView's internal buffer is modified if currentView.Editable is true.
 func (a *App) handleInputEvent(ev interface{}, unhandled IUnhandledInput) {
 	switch ev.(type) {
-	case *tcell.EventKey, *tcell.EventMouse:
-		x, y := a.TerminalSize()
-		handled := UserInputIfSelectable(a.viewPlusMenus, ev, RenderBox{C: x, R: y}, Focused, a)
+	case *tcell.EventKey, *tcell.EventMouse, *PasteEvent:
+		if a.crashReportInput(ev) {
+			break
+		}
+		if a.findInput(ev) {
+			break
+		}
+		if a.grabInput(ev) {
+			break
+		}
+		if a.layoutUndoInput(ev) {
+			break
+		}
+		if a.workspaceInput(ev) {
+			break
+		}
+		if a.helpInput(ev) {
+			break
+		}
+		if a.logConsoleInput(ev) {
+			break
+		}
+		if a.keyMapInput(ev) {
+			break
+		}
+		if a.dragInput(ev) {
+			break
+		}
+		a.trackClicks(ev)
+		a.trackHover(ev)
+		handled := a.userInputToWidgets(ev)
+		a.clicks.current = nil
+		a.trackHoverDone()
+		a.dragInputDone(handled)
+		if !handled {
+			handled = a.focusTraversalInput(ev)
+		}
+		if !handled {
+			handled = a.jobControlInput(ev)
+		}
 		if !handled {
 			handled = unhandled.UnhandledInput(a, ev)
 			if !handled {
@@ -675,22 +990,63 @@ func (a *App) handleInputEvent(ev interface{}, unhandled IUnhandledInput) {
 		}
 	default:
 		x, y := a.TerminalSize()
+		a.panicDuring = "handling input"
 		UserInputIfSelectable(a.viewPlusMenus, ev, RenderBox{C: x, R: y}, Focused, a)
+		a.panicDuring = ""
 	}
 }
 
+// userInputToWidgets passes ev to the app's widgets, returning true if it was handled.
+func (a *App) userInputToWidgets(ev interface{}) bool {
+	x, y := a.TerminalSize()
+	var handled bool
+	a.panicDuring = "handling input"
+	a.profiler.Measure("root", ProfileUserInput, func() {
+		handled = UserInputIfSelectable(a.viewPlusMenus, ev, RenderBox{C: x, R: y}, Focused, a)
+	})
+	a.panicDuring = ""
+	return handled
+}
+
 // Sync defers immediately to tcell's Screen's Sync() function - it is for updating
 // every screen cell in the event something corrupts the screen (e.g. ssh -v logging)
 func (a *App) Sync() {
 	a.screen.Sync()
+	a.graphics.redraw = true
 }
 
 // RedrawTerminal updates the gui, re-drawing frames and buffers. Call this from
 // the widget-handling goroutine only. Intended for use by apps that construct their
-// own main loops and handle gowid events themselves.
+// own main loops and handle gowid events themselves. During a transaction, the redraw
+// is put off until the transaction ends - see Transaction.
 func (a *App) RedrawTerminal() {
-	RenderRoot(a.viewPlusMenus, a)
+	if a.tx.deferRedraw() {
+		return
+	}
+	a.panicDuring = "rendering"
+	canvas := renderRoot(a.viewPlusMenus, a)
+	var focused Rect
+	var focusFirst bool
+	if a.frames.slow() {
+		x, y := a.TerminalSize()
+		focused, focusFirst = locateFocused(a.viewPlusMenus, RenderBox{C: x, R: y}, a)
+	}
+	a.panicDuring = ""
+	a.closeLayoutStep()
+	start := a.Clock().Now()
+	if focusFirst {
+		// On a slow terminal, send the region in focus before the rest - see EnableAdaptiveRedraw
+		drawIn(canvas, a, a.screen, focused)
+		a.screen.Show()
+	}
+	drawRoot(canvas, a)
 	a.screen.Show()
+	if a.frames != nil {
+		a.frames.frameWritten(a.Clock().Since(start))
+	}
+	a.drawRawRegions()
+	a.drawHyperlinks()
+	a.drawCursorStyle(a.viewPlusMenus)
 }
 
 // RegisterMenu should be called by any widget that wants to display a
@@ -707,6 +1063,12 @@ type menuView struct {
 	*App
 }
 
+// SubWidget returns the real root of the widget hierarchy, including any menus,
+// so that UnregisterMenu can find the menu to remove.
+func (a *menuView) SubWidget() IWidget {
+	return a.viewPlusMenus
+}
+
 // SetSubWidget will set the real root of the widget hierarchy rather than
 // the one visible to users of the App. i.e. it allows for a menu to be injected
 // into the hierarchy.
@@ -779,23 +1141,30 @@ func (a *App) Redraw() {
 	a.Run(RunFunction(func(IApp) {}))
 }
 
-// Quit will terminate the gowid main loop.
+// Quit will terminate the gowid main loop. It may be called from any goroutine, and more than once.
 func (a *App) Quit() {
 	a.closingMtx.Lock()
 	defer a.closingMtx.Unlock()
 
+	if a.closing {
+		return
+	}
 	a.closing = true
+	if a.cancel != nil {
+		a.cancel()
+	}
 	close(a.AfterRenderEvents)
 }
 
-// Let screen be taken over by gowid/tcell. A new screen struct is created because
-// I can't make tcell claim and release the same screen successfully. Clients of
-// the app struct shouldn't cache the screen object returned via GetScreen().
+// Let screen be taken over by gowid/tcell. A new screen struct is created, with the
+// app's ScreenFactory, because I can't make tcell claim and release the same screen
+// successfully. Clients of the app struct shouldn't cache the screen object returned
+// via GetScreen().
 //
 func (a *App) ActivateScreen() error {
-	screen, err := tcell.NewScreen()
+	screen, err := a.newScreen()
 	if err != nil {
-		return WithKVs(err, map[string]interface{}{"TERM": os.Getenv("TERM")})
+		return WithKVs(err, map[string]interface{}{"TERM": a.Getenv("TERM")})
 	}
 	a.screen = screen
 	if err := a.initScreen(); err != nil {
@@ -805,13 +1174,91 @@ func (a *App) ActivateScreen() error {
 }
 
 func (a *App) DeactivateScreen() {
+	if a.paste != nil {
+		a.setBracketedPasteMode(false)
+	}
+	a.writeCursorStyle(CursorStyle{})
+	a.restoreTitle()
 	a.screen.Fini()
 	a.screen = nil
+	a.pauseCapture()
+}
+
+// Suspend gives the terminal back to the user for the duration of f - for example,
+// to run $EDITOR or a shell. The tcell screen is torn down, restoring the terminal to
+// its normal (cooked) state, then f is run. Afterwards a new screen is initialized and
+// the app is fully redrawn. If the app's tcell events are being delivered by an
+// AppRunner (as they are in MainLoop), the runner is stopped while f runs so that it
+// doesn't poll the old screen. Suspend must be called from the app goroutine, e.g.
+// from a widget callback or via app.Run(). The error from f is returned, unless the
+// screen cannot be reinitialized.
+func (a *App) Suspend(f func() error) error {
+	st := a.runner
+	if st != nil {
+		st.Stop()
+	}
+	a.DeactivateScreen()
+
+	ferr := f()
+
+	if err := a.ActivateScreen(); err != nil {
+		return err
+	}
+	if st != nil {
+		st.Start()
+	}
+
+	a.MouseState = MouseState{}
+	a.lastMouse = MouseState{}
+	a.screen.Clear()
+	a.Sync()
+	a.RedrawTerminal()
+
+	return ferr
+}
+
+// RunCommand uses Suspend to run cmd in the terminal, waiting for it to
+// finish. If the command's stdin, stdout and stderr are not set, they are
+// connected to the process's own.
+func (a *App) RunCommand(cmd *exec.Cmd) error {
+	if cmd.Stdin == nil {
+		cmd.Stdin = os.Stdin
+	}
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	return a.Suspend(cmd.Run)
+}
+
+// writeToTerminal sends s, typically an escape sequence that tcell doesn't
+// provide a way to send, to the screen if it is an IRawWriter, otherwise directly
+// to the controlling terminal. Nothing is written if the app's screen is simulated.
+func (a *App) writeToTerminal(s string) error {
+	if w, ok := a.screen.(IRawWriter); ok {
+		return w.WriteRaw(s)
+	}
+	// Only a tcell screen is the controlling terminal; another screen is somewhere else entirely
+	if _, ok := a.screen.(tcell.Screen); !ok {
+		return nil
+	}
+	if _, ok := a.screen.(tcell.SimulationScreen); ok {
+		return nil
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+	_, err = io.WriteString(tty, s)
+	return err
 }
 
 func (a *App) initScreen() error {
 	if err := a.screen.Init(); err != nil {
-		return WithKVs(err, map[string]interface{}{"TERM": os.Getenv("TERM")})
+		return WithKVs(err, map[string]interface{}{"TERM": a.Getenv("TERM")})
 	}
 
 	a.initColorMode()
@@ -819,7 +1266,7 @@ func (a *App) initScreen() error {
 	defFg := ColorDefault
 	defBg := ColorDefault
 	defSt := StyleNone
-	if paletteDefault, ok := a.IPalette.CellStyler("default"); ok {
+	if paletteDefault, ok := a.CellStyler("default"); ok {
 		fgCol, bgCol, style := paletteDefault.GetStyle(a)
 		defFg = IColorToTCell(fgCol, defFg, a.GetColorMode())
 		defBg = IColorToTCell(bgCol, defBg, a.GetColorMode())
@@ -831,8 +1278,15 @@ func (a *App) initScreen() error {
 	// in the absence of overriding styling from widgets.
 	a.screen.SetStyle(defStyle)
 	a.screen.EnableMouse()
+	if a.paste != nil {
+		a.setBracketedPasteMode(true)
+	}
+	a.writeTitle()
+	if a.termColors.onActivate {
+		_ = a.QueryTerminalColors()
+	}
 
-	return nil
+	return a.resumeCapture()
 }
 
 //======================================================================
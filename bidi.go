@@ -0,0 +1,402 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+
+	"golang.org/x/text/unicode/bidi"
+)
+
+//======================================================================
+
+// TextDirection is the direction in which a paragraph of text runs. Within a paragraph, runs of text in
+// the other direction - e.g. English words in a Hebrew sentence - are still displayed in their own order.
+type TextDirection int
+
+const (
+	// DirectionAuto takes the direction of each paragraph from its first letter, so a paragraph that
+	// starts in Hebrew or Arabic runs right-to-left. This is the default.
+	DirectionAuto TextDirection = iota
+	// DirectionLTR makes every paragraph run left-to-right.
+	DirectionLTR
+	// DirectionRTL makes every paragraph run right-to-left, for fully right-to-left UIs.
+	DirectionRTL
+)
+
+func (d TextDirection) String() string {
+	switch d {
+	case DirectionAuto:
+		return "auto"
+	case DirectionLTR:
+		return "ltr"
+	case DirectionRTL:
+		return "rtl"
+	default:
+		return fmt.Sprintf("direction(%d)", int(d))
+	}
+}
+
+//======================================================================
+
+// BidiText holds the embedding levels of some text, resolved by the Unicode bidirectional algorithm
+// (UAX #9) - an even level runs left-to-right and an odd level right-to-left. Text is split into
+// paragraphs at newlines. The algorithm is simplified: explicit embedding, override and isolate controls
+// are ignored, and brackets are not paired, which is enough for most text in a terminal.
+type BidiText struct {
+	runes  []rune
+	levels []int8 // nil if the text all runs left-to-right at level 0
+	base   []int8 // The level of the paragraph holding each rune, and one more for the end of the text
+}
+
+// NewBidiText resolves the levels of runes, whose paragraphs run in direction dir.
+func NewBidiText(runes []rune, dir TextDirection) *BidiText {
+	res := &BidiText{runes: runes}
+	classes := make([]bidi.Class, len(runes))
+	rtl := dir == DirectionRTL
+	for i, r := range runes {
+		classes[i] = bidiClass(r)
+		switch classes[i] {
+		case bidi.R, bidi.AL, bidi.AN:
+			rtl = true
+		}
+	}
+	if !rtl {
+		return res
+	}
+
+	res.levels = make([]int8, len(runes))
+	res.base = make([]int8, len(runes)+1)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		if i < len(runes) && classes[i] != bidi.B {
+			continue
+		}
+		level := paragraphLevel(classes[start:i], dir)
+		resolveLevels(classes[start:i], level, res.levels[start:i])
+		for j := start; j <= i; j++ {
+			res.base[j] = level
+		}
+		if i < len(runes) {
+			res.levels[i] = level
+		}
+		start = i + 1
+	}
+	return res
+}
+
+// LeftToRight returns true if all the text runs left-to-right, so it is displayed in logical order.
+func (b *BidiText) LeftToRight() bool {
+	return b.levels == nil
+}
+
+// Level returns the embedding level of the rune at index i.
+func (b *BidiText) Level(i int) int8 {
+	if b.levels == nil {
+		return 0
+	}
+	return b.levels[i]
+}
+
+// BaseLevel returns the level of the paragraph holding the rune at index i - 1 if it runs right-to-left,
+// otherwise 0. i may be the length of the text, for the last paragraph.
+func (b *BidiText) BaseLevel(i int) int8 {
+	if b.base == nil {
+		return 0
+	}
+	return b.base[i]
+}
+
+// VisualOrder returns the indices of the runes from start to end, a line of text, in the order they are
+// displayed from left to right. The runes of each grapheme cluster stay together, in logical order, so
+// that an accent still follows its letter.
+func (b *BidiText) VisualOrder(start, end int) []int {
+	res := make([]int, 0, end-start)
+	if b.levels == nil {
+		for i := start; i < end; i++ {
+			res = append(res, i)
+		}
+		return res
+	}
+
+	// Whitespace at the end of a line takes the paragraph level, so it stays at the line's end (L1)
+	levels := make([]int8, end-start)
+	copy(levels, b.levels[start:end])
+	for i := end - 1; i >= start; i-- {
+		if c := bidiClass(b.runes[i]); c != bidi.WS && c != bidi.BN {
+			break
+		}
+		levels[i-start] = b.base[start]
+	}
+
+	// Reorder grapheme clusters rather than runes
+	clusters := make([]int, 0, end-start+1)
+	for i, first := range GraphemeStarts(b.runes[start:end]) {
+		if first {
+			clusters = append(clusters, i)
+		}
+	}
+	order := make([]int, len(clusters))
+	highest, lowestOdd := int8(0), int8(127)
+	for i, c := range clusters {
+		order[i] = i
+		if levels[c] > highest {
+			highest = levels[c]
+		}
+		if levels[c]%2 == 1 && levels[c] < lowestOdd {
+			lowestOdd = levels[c]
+		}
+	}
+
+	// From the highest level down to the lowest odd level, reverse each run at that level or higher (L2)
+	for level := highest; level >= lowestOdd; level-- {
+		for i := 0; i < len(order); {
+			if levels[clusters[order[i]]] < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(order) && levels[clusters[order[j]]] >= level {
+				j++
+			}
+			for k, l := i, j-1; k < l; k, l = k+1, l-1 {
+				order[k], order[l] = order[l], order[k]
+			}
+			i = j
+		}
+	}
+
+	clusters = append(clusters, end-start)
+	for _, c := range order {
+		for i := clusters[c]; i < clusters[c+1]; i++ {
+			res = append(res, start+i)
+		}
+	}
+	return res
+}
+
+// BidiMirror returns the glyph to display for r in right-to-left text, e.g. ')' for '(', so that brackets
+// still face the text they enclose.
+func BidiMirror(r rune) rune {
+	switch r {
+	case '(':
+		return ')'
+	case ')':
+		return '('
+	case '[':
+		return ']'
+	case ']':
+		return '['
+	case '{':
+		return '}'
+	case '}':
+		return '{'
+	case '<':
+		return '>'
+	case '>':
+		return '<'
+	case '«':
+		return '»'
+	case '»':
+		return '«'
+	case '‹':
+		return '›'
+	case '›':
+		return '‹'
+	default:
+		return r
+	}
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+func bidiClass(r rune) bidi.Class {
+	p, _ := bidi.LookupRune(r)
+	if c := p.Class(); c != bidi.Control {
+		return c
+	}
+	// Explicit embeddings, overrides and isolates aren't supported
+	return bidi.BN
+}
+
+// paragraphLevel returns the level of a paragraph - from its first strong character, unless the
+// direction is given (P2, P3).
+func paragraphLevel(classes []bidi.Class, dir TextDirection) int8 {
+	switch dir {
+	case DirectionLTR:
+		return 0
+	case DirectionRTL:
+		return 1
+	}
+	for _, c := range classes {
+		switch c {
+		case bidi.L:
+			return 0
+		case bidi.R, bidi.AL:
+			return 1
+		}
+	}
+	return 0
+}
+
+// strongClass returns the direction a neutral takes from c, with numbers counting as right-to-left (N1).
+func strongClass(c bidi.Class) bidi.Class {
+	if c == bidi.L {
+		return bidi.L
+	}
+	return bidi.R
+}
+
+// resolveLevels sets the level of each character of a paragraph with no explicit embeddings, from its
+// class, following the weak (W1-W7), neutral (N1-N2) and implicit (I1-I2) rules.
+func resolveLevels(classes []bidi.Class, level int8, levels []int8) {
+	n := len(classes)
+	types := make([]bidi.Class, n)
+	copy(types, classes)
+	sos := bidi.L
+	if level%2 == 1 {
+		sos = bidi.R
+	}
+
+	// Marks and boundary neutrals take the type of the character before them (W1, X9)
+	prev := sos
+	for i, t := range types {
+		if t == bidi.NSM || t == bidi.BN {
+			types[i] = prev
+		}
+		prev = types[i]
+	}
+
+	// European numbers after Arabic letters are Arabic numbers, and Arabic letters are right-to-left (W2, W3)
+	strong := sos
+	for i, t := range types {
+		switch t {
+		case bidi.L, bidi.R:
+			strong = t
+		case bidi.AL:
+			strong = t
+			types[i] = bidi.R
+		case bidi.EN:
+			if strong == bidi.AL {
+				types[i] = bidi.AN
+			}
+		}
+	}
+
+	// A single separator between two numbers of the same type joins them (W4)
+	for i := 1; i < n-1; i++ {
+		switch {
+		case types[i] == bidi.ES && types[i-1] == bidi.EN && types[i+1] == bidi.EN:
+			types[i] = bidi.EN
+		case types[i] == bidi.CS && (types[i-1] == bidi.EN || types[i-1] == bidi.AN) && types[i+1] == types[i-1]:
+			types[i] = types[i-1]
+		}
+	}
+
+	// Terminators next to European numbers, like currency signs, join them (W5)
+	for i := 0; i < n; {
+		if types[i] != bidi.ET {
+			i++
+			continue
+		}
+		j := i
+		for j < n && types[j] == bidi.ET {
+			j++
+		}
+		if (i > 0 && types[i-1] == bidi.EN) || (j < n && types[j] == bidi.EN) {
+			for k := i; k < j; k++ {
+				types[k] = bidi.EN
+			}
+		}
+		i = j
+	}
+
+	// Remaining separators and terminators are neutral (W6), and European numbers in left-to-right text
+	// are left-to-right (W7)
+	strong = sos
+	for i, t := range types {
+		switch t {
+		case bidi.ES, bidi.ET, bidi.CS:
+			types[i] = bidi.ON
+		case bidi.L, bidi.R:
+			strong = t
+		case bidi.EN:
+			if strong == bidi.L {
+				types[i] = bidi.L
+			}
+		}
+	}
+
+	// A run of neutrals takes the direction of the text around it if that is the same on both sides,
+	// otherwise the paragraph's direction (N1, N2)
+	neutral := func(t bidi.Class) bool {
+		return t == bidi.B || t == bidi.S || t == bidi.WS || t == bidi.ON
+	}
+	for i := 0; i < n; {
+		if !neutral(types[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < n && neutral(types[j]) {
+			j++
+		}
+		before, after := sos, sos
+		if i > 0 {
+			before = strongClass(types[i-1])
+		}
+		if j < n {
+			after = strongClass(types[j])
+		}
+		dir := sos
+		if before == after {
+			dir = before
+		}
+		for k := i; k < j; k++ {
+			types[k] = dir
+		}
+		i = j
+	}
+
+	// I1, I2
+	for i, t := range types {
+		levels[i] = level
+		if level%2 == 0 {
+			switch t {
+			case bidi.R:
+				levels[i]++
+			case bidi.AN, bidi.EN:
+				levels[i] += 2
+			}
+		} else {
+			switch t {
+			case bidi.L, bidi.EN, bidi.AN:
+				levels[i]++
+			}
+		}
+	}
+
+	// Tabs, and whitespace before them or at the end of the paragraph, take the paragraph level (L1)
+	trailing := true
+	for i := n - 1; i >= 0; i-- {
+		switch classes[i] {
+		case bidi.S:
+			levels[i] = level
+			trailing = true
+		case bidi.WS, bidi.BN:
+			if trailing {
+				levels[i] = level
+			}
+		default:
+			trailing = false
+		}
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,66 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// visual returns s as displayed, given the direction of its paragraphs.
+func visual(s string, dir TextDirection) string {
+	runes := []rune(s)
+	b := NewBidiText(runes, dir)
+	res := make([]rune, 0, len(runes))
+	for _, i := range b.VisualOrder(0, len(runes)) {
+		res = append(res, runes[i])
+	}
+	return string(res)
+}
+
+func TestBidi1(t *testing.T) {
+	// Hebrew alef, bet, gimel
+	abg := "אבג"
+	gba := "גבא"
+
+	assert.True(t, NewBidiText([]rune("hello world"), DirectionAuto).LeftToRight())
+	assert.Equal(t, "hello world", visual("hello world", DirectionAuto))
+	assert.Equal(t, "abc "+gba+" def", visual("abc "+abg+" def", DirectionAuto))
+
+	// A paragraph starting with Hebrew runs right-to-left; numbers and English keep their order
+	assert.Equal(t, "def 123 "+gba, visual(abg+" 123 def", DirectionAuto))
+	b := NewBidiText([]rune(abg+" 123"), DirectionAuto)
+	assert.Equal(t, int8(1), b.BaseLevel(0))
+	assert.Equal(t, int8(1), b.Level(0))
+	assert.Equal(t, int8(2), b.Level(4))
+
+	// The direction can be forced
+	assert.Equal(t, "def "+gba, visual("def "+abg, DirectionAuto))
+	assert.Equal(t, gba+" def", visual("def "+abg, DirectionRTL))
+	assert.Equal(t, gba+" def", visual(abg+" def", DirectionLTR))
+	assert.Equal(t, int8(1), NewBidiText([]rune("def"), DirectionRTL).BaseLevel(3))
+
+	// Each paragraph has its own direction
+	assert.Equal(t, "abc\n"+gba, visual("abc\n"+abg, DirectionAuto))
+	b = NewBidiText([]rune("abc\n"+abg), DirectionAuto)
+	assert.Equal(t, int8(0), b.BaseLevel(1))
+	assert.Equal(t, int8(1), b.BaseLevel(5))
+
+	// Marks stay after the letter they belong to
+	assert.Equal(t, "באָ", visual("אָב", DirectionAuto))
+
+	// Trailing whitespace stays at the end of a line
+	assert.Equal(t, []int{1, 0, 2}, NewBidiText([]rune("אב "), DirectionLTR).VisualOrder(0, 3))
+
+	assert.Equal(t, ')', BidiMirror('('))
+	assert.Equal(t, 'a', BidiMirror('a'))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
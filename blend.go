@@ -0,0 +1,83 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+//======================================================================
+
+// BlendColors returns the color seen when upper, with the given opacity, is laid over lower. The colors are
+// mixed in RGB space, and the result is a 24-bit color, which the terminal maps to its palette if need be.
+// If upper is ColorNone, lower is returned. If either color has no known components - like the terminal's
+// default color - the colors can't be mixed, and false is returned, with whichever color the opacity
+// favors.
+func BlendColors(lower, upper TCellColor, opacity float64) (TCellColor, bool) {
+	if upper == ColorNone {
+		return lower, true
+	}
+	c1, ok1 := IColorToRGB(lower)
+	c2, ok2 := IColorToRGB(upper)
+	if !ok1 || !ok2 {
+		if opacity < 0.5 {
+			return lower, false
+		}
+		return upper, false
+	}
+	res, _ := InterpolateColor(c1, c2, opacity, RGBSpace).ToTCellColor(Mode24BitColors)
+	return res, true
+}
+
+// blendUnder returns the receiver Cell with the translucent upper Cell laid over it. If upper has a rune,
+// it is drawn in its foreground color mixed with the background showing through. If not, the receiver's
+// rune shows through, tinted by upper's background - or, if the colors can't be mixed, dimmed - which is
+// how a translucent shadow or shade darkens the text beneath it.
+func (c Cell) blendUnder(upper Cell) Cell {
+	opacity := upper.Opacity()
+	if opacity == 0 {
+		return c
+	}
+	res := c
+	if upper.codePoint != 0 {
+		res.codePoint = upper.codePoint
+		res.combining = upper.combining
+		res.raw = upper.raw
+		if upper.fg != ColorNone {
+			res.fg, _ = BlendColors(c.bg, upper.fg, opacity)
+		}
+		res.style = res.style.MergeUnder(upper.style)
+		if upper.link != "" {
+			res.link = upper.link
+		}
+	} else if upper.bg != ColorNone {
+		var ok bool
+		if res.fg, ok = BlendColors(c.fg, upper.bg, opacity); !ok {
+			res.fg = c.fg
+			res.style = res.style.MergeUnder(StyleDim)
+		}
+	}
+	res.bg, _ = BlendColors(c.bg, upper.bg, opacity)
+	return res
+}
+
+// SetCanvasOpacity makes every cell of the canvas translucent, so that when it is merged over another
+// canvas - e.g. by an overlay - the two are blended. See Cell.WithOpacity.
+func SetCanvasOpacity(canvas IRangeOverCanvas, opacity float64) {
+	RangeOverCanvas(canvas, CellRangeFunc(func(c Cell) Cell {
+		return c.WithOpacity(c.Opacity() * opacity)
+	}))
+}
+
+// ShadeCanvas lays a translucent layer of the given color over the canvas, darkening or tinting it - e.g.
+// to dim the screen behind a dialog. An opacity of 0 leaves the canvas unchanged.
+func ShadeCanvas(canvas IRangeOverCanvas, color TCellColor, opacity float64) {
+	shade := Cell{}.WithBackgroundColor(color).WithOpacity(opacity)
+	RangeOverCanvas(canvas, CellRangeFunc(func(c Cell) Cell {
+		return c.MergeUnder(shade)
+	}))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
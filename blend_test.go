@@ -0,0 +1,79 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlendColors1(t *testing.T) {
+	white := MakeTCellColorExt(tcell.NewHexColor(0xffffff))
+	black := MakeTCellColorExt(tcell.NewHexColor(0x000000))
+
+	c, ok := BlendColors(white, black, 0.5)
+	assert.True(t, ok)
+	assert.Equal(t, tcell.NewHexColor(0x808080), c.ToTCell())
+
+	c, ok = BlendColors(white, ColorNone, 0.5)
+	assert.True(t, ok)
+	assert.Equal(t, white, c)
+
+	// The terminal's default can't be mixed
+	c, ok = BlendColors(ColorDefault, black, 0.75)
+	assert.False(t, ok)
+	assert.Equal(t, black, c)
+	c, ok = BlendColors(ColorDefault, black, 0.25)
+	assert.False(t, ok)
+	assert.Equal(t, ColorDefault, c)
+}
+
+func TestBlendCell1(t *testing.T) {
+	white := MakeTCellColorExt(tcell.NewHexColor(0xffffff))
+	blue := MakeTCellColorExt(tcell.NewHexColor(0x0000ff))
+	black := MakeTCellColorExt(tcell.NewHexColor(0x000000))
+	lower := MakeCell('x', white, blue, StyleNone)
+
+	assert.Equal(t, 1.0, lower.Opacity())
+	assert.InDelta(t, 0.25, lower.WithOpacity(0.25).Opacity(), 0.01)
+
+	// Without a rune, the lower rune shows through, tinted
+	shade := MakeCell(0, ColorNone, black, StyleNone).WithOpacity(0.5)
+	res := lower.MergeUnder(shade)
+	assert.Equal(t, 'x', res.Rune())
+	assert.Equal(t, tcell.NewHexColor(0x808080), res.ForegroundColor().ToTCell())
+	assert.Equal(t, tcell.NewHexColor(0x000080), res.BackgroundColor().ToTCell())
+	assert.Equal(t, 1.0, res.Opacity())
+
+	// With a rune, the upper rune is drawn, mixed with the background beneath
+	res = lower.MergeUnder(MakeCell('y', white, black, StyleNone).WithOpacity(0.5))
+	assert.Equal(t, 'y', res.Rune())
+	assert.Equal(t, tcell.NewHexColor(0x7f7fff), res.ForegroundColor().ToTCell())
+
+	// A default foreground can't be tinted, so it is dimmed
+	res = MakeCell('x', ColorDefault, blue, StyleNone).MergeUnder(shade)
+	assert.Equal(t, ColorDefault, res.ForegroundColor())
+	assert.Equal(t, StyleDim, res.Style())
+
+	// An opaque cell over a translucent one is opaque
+	res = shade.MergeUnder(lower)
+	assert.Equal(t, 1.0, res.Opacity())
+	assert.Equal(t, lower, res)
+}
+
+func TestShadeCanvas1(t *testing.T) {
+	white := MakeTCellColorExt(tcell.NewHexColor(0xffffff))
+	c := NewCanvasOfSizeExt(2, 1, MakeCell('a', white, white, StyleNone))
+	ShadeCanvas(c, MakeTCellColorExt(tcell.NewHexColor(0x000000)), 0.5)
+	assert.Equal(t, 'a', c.CellAt(1, 0).Rune())
+	assert.Equal(t, tcell.NewHexColor(0x808080), c.CellAt(1, 0).BackgroundColor().ToTCell())
+
+	top := NewCanvasOfSizeExt(2, 1, MakeCell('b', white, white, StyleNone))
+	SetCanvasOpacity(top, 0.5)
+	c.MergeUnder(top, 0, 0, false)
+	assert.Equal(t, 'b', c.CellAt(0, 0).Rune())
+	assert.Equal(t, tcell.NewHexColor(0xbfbfbf), c.CellAt(0, 0).BackgroundColor().ToTCell())
+}
@@ -0,0 +1,332 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
+)
+
+//======================================================================
+
+// ICache stores data that is expensive to compute, so that widgets can reuse it - e.g. file metadata,
+// scaled images or syntax-highlighted text - within a session and, if the cache persists, across
+// sessions. Widgets should prefix their keys with the name of their package, so that different widgets
+// don't collide. A ttl of 0 means the value doesn't expire. Implementations must be safe for use from
+// several goroutines.
+type ICache interface {
+	Get(key string) ([]byte, bool)
+	Put(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+}
+
+// ICached is implemented by an IApp that provides a cache for its widgets, like App.
+type ICached interface {
+	Cache() ICache
+}
+
+// DefaultCache is the cache used by apps that don't provide one. If it is nil when first needed, it is set
+// to a DiskCache in DefaultCacheDir(), or a MemoryCache if there is no cache directory.
+var DefaultCache ICache
+
+var defaultCacheOnce sync.Once
+
+// DefaultMemoryCacheSize is the number of entries held by the MemoryCache used when there is no cache
+// directory.
+const DefaultMemoryCacheSize = 1000
+
+// CacheFor returns the app's cache if it provides one, otherwise DefaultCache.
+func CacheFor(app IApp) ICache {
+	if c, ok := app.(ICached); ok {
+		if res := c.Cache(); res != nil {
+			return res
+		}
+	}
+	return defaultCache()
+}
+
+func defaultCache() ICache {
+	defaultCacheOnce.Do(func() {
+		if DefaultCache != nil {
+			return
+		}
+		if dir, err := DefaultCacheDir(); err == nil {
+			DefaultCache = NewDiskCache(dir)
+		} else {
+			DefaultCache = NewMemoryCache(DefaultMemoryCacheSize)
+		}
+	})
+	return DefaultCache
+}
+
+// Cache returns the cache provided to the app's widgets. It lets App conform to ICached.
+func (a *App) Cache() ICache {
+	if a.cache == nil {
+		return defaultCache()
+	}
+	return a.cache
+}
+
+// SetCache replaces the cache provided to the app's widgets, e.g. with a MemoryCache so that nothing is
+// written to disk.
+func (a *App) SetCache(cache ICache) {
+	a.cache = cache
+}
+
+// DefaultCacheDir returns the directory for this program's cache - a directory named for the program in
+// gowid's directory in the user's cache directory, e.g. $XDG_CACHE_HOME/gowid/myprog on Linux.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return filepath.Join(dir, "gowid", strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")), nil
+}
+
+// Cached returns the value stored in cache under key, or if there is none, computes it, stores it with
+// ttl and returns it. An error storing the value is ignored - the cache is only an optimization.
+func Cached(cache ICache, key string, ttl time.Duration, compute func() ([]byte, error)) ([]byte, error) {
+	if res, ok := cache.Get(key); ok {
+		return res, nil
+	}
+	res, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	cache.Put(key, res, ttl)
+	return res, nil
+}
+
+func expiry(clock IClock, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return clock.Now().Add(ttl)
+}
+
+func expired(clock IClock, at time.Time) bool {
+	return !at.IsZero() && !clock.Now().Before(at)
+}
+
+//======================================================================
+
+// MemoryCache is an ICache that holds up to a fixed number of entries in memory, discarding the least
+// recently used. It doesn't persist across sessions.
+type MemoryCache struct {
+	Clock IClock // If nil, DefaultClock is used to expire entries
+	cache *lru.Cache
+}
+
+var _ ICache = (*MemoryCache)(nil)
+
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+func NewMemoryCache(size int) *MemoryCache {
+	cache, err := lru.New(size)
+	if err != nil {
+		panic(errors.WithStack(err))
+	}
+	return &MemoryCache{cache: cache}
+}
+
+func (c *MemoryCache) String() string {
+	return fmt.Sprintf("memorycache[%d]", c.cache.Len())
+}
+
+func (c *MemoryCache) clock() IClock {
+	if c.Clock == nil {
+		return DefaultClock
+	}
+	return c.Clock
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	v, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := v.(memoryCacheEntry)
+	if expired(c.clock(), entry.expires) {
+		c.cache.Remove(key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *MemoryCache) Put(key string, value []byte, ttl time.Duration) error {
+	c.cache.Add(key, memoryCacheEntry{value: value, expires: expiry(c.clock(), ttl)})
+	return nil
+}
+
+func (c *MemoryCache) Delete(key string) error {
+	c.cache.Remove(key)
+	return nil
+}
+
+func (c *MemoryCache) Clear() {
+	c.cache.Purge()
+}
+
+//======================================================================
+
+// DiskCache is an ICache that stores each entry in a file in a directory, so entries persist across
+// sessions. The directory is created when the first entry is stored. Expired entries are removed as they
+// are found, or all at once by Prune.
+type DiskCache struct {
+	Clock IClock // If nil, DefaultClock is used to expire entries
+	dir   string
+}
+
+var _ ICache = (*DiskCache)(nil)
+
+// diskCacheHeader is the size of the expiry time at the start of each file.
+const diskCacheHeader = 8
+
+func NewDiskCache(dir string) *DiskCache {
+	return &DiskCache{dir: dir}
+}
+
+func (c *DiskCache) String() string {
+	return fmt.Sprintf("diskcache[%s]", c.dir)
+}
+
+func (c *DiskCache) Dir() string {
+	return c.dir
+}
+
+func (c *DiskCache) clock() IClock {
+	if c.Clock == nil {
+		return DefaultClock
+	}
+	return c.Clock
+}
+
+// path returns the file for key - named by a hash, since keys may contain any characters.
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// read returns the value and expiry time stored in file.
+func (c *DiskCache) read(file string) ([]byte, time.Time, bool) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil || len(data) < diskCacheHeader {
+		return nil, time.Time{}, false
+	}
+	var expires time.Time
+	if n := int64(binary.BigEndian.Uint64(data)); n != 0 {
+		expires = time.Unix(0, n)
+	}
+	return data[diskCacheHeader:], expires, true
+}
+
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	file := c.path(key)
+	value, expires, ok := c.read(file)
+	if !ok {
+		return nil, false
+	}
+	if expired(c.clock(), expires) {
+		os.Remove(file)
+		return nil, false
+	}
+	return value, true
+}
+
+// Put stores value in a temporary file, then renames it, so that a reader - perhaps another instance of
+// the program - never sees part of an entry.
+func (c *DiskCache) Put(key string, value []byte, ttl time.Duration) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	var n int64
+	if expires := expiry(c.clock(), ttl); !expires.IsZero() {
+		n = expires.UnixNano()
+	}
+	data := make([]byte, diskCacheHeader+len(value))
+	binary.BigEndian.PutUint64(data, uint64(n))
+	copy(data[diskCacheHeader:], value)
+
+	tmp, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func (c *DiskCache) Delete(key string) error {
+	if err := os.Remove(c.path(key)); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Prune removes the entries that have expired.
+func (c *DiskCache) Prune() error {
+	return c.removeIf(func(file string) bool {
+		_, expires, ok := c.read(file)
+		return !ok || expired(c.clock(), expires)
+	})
+}
+
+// Clear removes all entries.
+func (c *DiskCache) Clear() error {
+	return c.removeIf(func(file string) bool {
+		return true
+	})
+}
+
+func (c *DiskCache) removeIf(pred func(file string) bool) error {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".tmp-") {
+			continue
+		}
+		file := filepath.Join(c.dir, f.Name())
+		if pred(file) {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return errors.WithStack(err)
+			}
+		}
+	}
+	return nil
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,100 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testCache(t *testing.T, c ICache, clock *FakeClock) {
+	_, ok := c.Get("foo")
+	assert.False(t, ok)
+
+	assert.NoError(t, c.Put("foo", []byte("bar"), 0))
+	assert.NoError(t, c.Put("baz", []byte("qux"), time.Minute))
+	v, ok := c.Get("foo")
+	assert.True(t, ok)
+	assert.Equal(t, "bar", string(v))
+	v, ok = c.Get("baz")
+	assert.True(t, ok)
+	assert.Equal(t, "qux", string(v))
+
+	clock.Advance(time.Minute)
+	_, ok = c.Get("baz")
+	assert.False(t, ok)
+	_, ok = c.Get("foo")
+	assert.True(t, ok)
+
+	assert.NoError(t, c.Delete("foo"))
+	assert.NoError(t, c.Delete("foo"))
+	_, ok = c.Get("foo")
+	assert.False(t, ok)
+
+	calls := 0
+	compute := func() ([]byte, error) {
+		calls++
+		return []byte("computed"), nil
+	}
+	for i := 0; i < 2; i++ {
+		v, err := Cached(c, "key/with spaces", 0, compute)
+		assert.NoError(t, err)
+		assert.Equal(t, "computed", string(v))
+	}
+	assert.Equal(t, 1, calls)
+
+	_, err := Cached(c, "fails", 0, func() ([]byte, error) {
+		return nil, errors.New("boom")
+	})
+	assert.Error(t, err)
+	_, ok = c.Get("fails")
+	assert.False(t, ok)
+}
+
+func TestMemoryCache1(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	c := NewMemoryCache(10)
+	c.Clock = clock
+	testCache(t, c, clock)
+}
+
+func TestDiskCache1(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gowid-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	clock := NewFakeClock(time.Unix(1000, 0))
+	c := NewDiskCache(dir + "/sub")
+	c.Clock = clock
+	testCache(t, c, clock)
+
+	// Entries persist for another cache in the same directory
+	c2 := NewDiskCache(dir + "/sub")
+	c2.Clock = clock
+	v, ok := c2.Get("key/with spaces")
+	assert.True(t, ok)
+	assert.Equal(t, "computed", string(v))
+
+	assert.NoError(t, c.Put("short", []byte("lived"), time.Second))
+	clock.Advance(time.Second)
+	assert.NoError(t, c.Prune())
+	files, _ := ioutil.ReadDir(dir + "/sub")
+	assert.Equal(t, 1, len(files))
+
+	assert.NoError(t, c.Clear())
+	_, ok = c2.Get("key/with spaces")
+	assert.False(t, ok)
+}
+
+func TestCacheFor1(t *testing.T) {
+	app := &App{}
+	mc := NewMemoryCache(10)
+	app.SetCache(mc)
+	assert.Equal(t, ICache(mc), CacheFor(app))
+}
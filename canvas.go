@@ -11,8 +11,6 @@ import (
 	"unicode/utf8"
 
 	"github.com/gcla/gowid/gwutil"
-	"github.com/gdamore/tcell"
-	"github.com/mattn/go-runewidth"
 	"github.com/pkg/errors"
 )
 
@@ -395,23 +393,40 @@ func WriteToCanvas(c IRangeOverCanvas, p []byte) (n int, err error) {
 	maxcol := c.BoxColumns()
 	line := 0
 	col := 0
+	lastx, lasty := -1, -1 // The cell holding the current grapheme cluster
+	var g GraphemeState
 	for i, chr := range string(p) {
 		if c.BoxRows() > line {
-			switch chr {
-			case '\n':
+			first, wid := g.Add(chr)
+			switch {
+			case chr == '\n':
 				for col < maxcol {
 					c.SetCellAt(col, line, Cell{})
 					col++
 				}
 				line++
 				col = 0
+				lastx = -1
+			case !first && lastx != -1:
+				// Part of the cluster in the last cell, which may widen it
+				cell := c.CellAt(lastx, lasty).AddCombining(chr)
+				col += wid
+				if col > maxcol && lastx > 0 && line+1 < c.BoxRows() {
+					// It no longer fits, so move it to the next line
+					c.SetCellAt(lastx, lasty, Cell{})
+					line++
+					lastx, lasty, col = 0, line, cell.Width()
+				}
+				c.SetCellAt(lastx, lasty, cell)
+			case wid == 0:
+				// A rune with no width and nothing to combine with takes up no cell
 			default:
-				wid := runewidth.RuneWidth(chr)
 				if col+wid > maxcol {
 					col = 0
 					line++
 				}
 				c.SetCellAt(col, line, c.CellAt(col, line).WithRune(chr))
+				lastx, lasty = col, line
 				col += wid
 			}
 			done = i + utf8.RuneLen(chr)
@@ -552,9 +567,9 @@ func CanvasToString(c ICanvas) string {
 		line := c.Line(i, LineCopy{}).Line
 		curLine := make([]rune, 0)
 		for x := 0; x < len(line); {
-			r := line[x].Rune()
-			curLine = append(curLine, r)
-			x += runewidth.RuneWidth(r)
+			curLine = append(curLine, line[x].Rune())
+			curLine = append(curLine, line[x].Combining()...)
+			x += gwutil.Max(1, line[x].Width())
 		}
 		lineStrings[i] = string(curLine)
 	}
@@ -795,8 +810,13 @@ func (c *Canvas) AlignRight() {
 	c.AlignRightWith(Cell{})
 }
 
-// Draw will render a Canvas to a tcell Screen.
-func Draw(canvas IDrawCanvas, mode IColorMode, screen tcell.Screen) {
+// Draw will render a Canvas to a screen.
+func Draw(canvas IDrawCanvas, mode IColorMode, screen IScreen) {
+	drawIn(canvas, mode, screen, Rect{Cols: canvas.BoxColumns(), Rows: canvas.BoxRows()})
+}
+
+// drawIn is like Draw, but only draws the cells of canvas inside r.
+func drawIn(canvas IDrawCanvas, mode IColorMode, screen IScreen, r Rect) {
 	cpos := CanvasPos{X: -1, Y: -1}
 	if canvas.CursorEnabled() {
 		cpos = canvas.CursorCoords()
@@ -804,15 +824,24 @@ func Draw(canvas IDrawCanvas, mode IColorMode, screen tcell.Screen) {
 
 	screen.ShowCursor(-1, -1)
 
-	for y := 0; y < canvas.BoxRows(); y++ {
+	down, _ := mode.(IColorDowngrader)
+
+	for y := gwutil.Max(0, r.Y); y < gwutil.Min(canvas.BoxRows(), r.Y+r.Rows); y++ {
 		line := canvas.Line(y, LineCopy{})
 		vline := line.Line
 		for x := 0; x < len(vline); {
 			c := vline[x]
+			if !r.Contains(x, y) {
+				x += gwutil.Max(1, c.Width())
+				continue
+			}
 			f, b, s := c.ForegroundColor(), c.BackgroundColor(), c.Style()
+			if down != nil {
+				f, b = downgradeCellColor(down, f), downgradeCellColor(down, b)
+			}
 			st := MakeCellStyle(f, b, s)
-			screen.SetContent(x, y, c.Rune(), nil, st)
-			x += runewidth.RuneWidth(c.Rune())
+			screen.SetContent(x, y, c.Rune(), c.Combining(), st)
+			x += gwutil.Max(1, c.Width())
 
 			if x == cpos.X && y == cpos.Y {
 				screen.ShowCursor(x, y)
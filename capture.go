@@ -0,0 +1,189 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+//======================================================================
+
+// CaptureNotSupportedError is returned by App.SetOutputCapture on platforms where the process's output can't be
+// redirected.
+type CaptureNotSupportedError struct{}
+
+var _ error = CaptureNotSupportedError{}
+
+func (e CaptureNotSupportedError) Error() string {
+	return "Capturing stdout and stderr is not supported on this platform"
+}
+
+// OutputCaptureOptions configures the capture of the process's standard output and error - see
+// App.SetOutputCapture. If neither Stdout nor Stderr is set, both are captured.
+type OutputCaptureOptions struct {
+	Stdout bool // Capture standard output
+	Stderr bool // Capture standard error
+	// To receives output as it is written. If nil, output goes to the app's log console if it has one - see
+	// App.SetLogConsole - or else it is held, and written to the terminal when the capture is paused.
+	To io.Writer
+}
+
+// outputCapture redirects the process's stdout and stderr while the app has the terminal.
+type outputCapture struct {
+	opts    OutputCaptureOptions
+	streams []*capturedStream // Empty while paused
+}
+
+func newOutputCapture(opts OutputCaptureOptions) *outputCapture {
+	if !opts.Stdout && !opts.Stderr {
+		opts.Stdout, opts.Stderr = true, true
+	}
+	return &outputCapture{opts: opts}
+}
+
+// capturedStream is one of the process's file descriptors, redirected into a pipe.
+type capturedStream struct {
+	file  *os.File // The original - os.Stdout or os.Stderr
+	saved int      // A duplicate of the file descriptor as it was before the capture
+	pipe  *os.File // The writing end of the pipe now behind the file descriptor
+	held  *bytes.Buffer
+	done  chan struct{} // Closed once everything written to the pipe has been copied
+}
+
+// lockedWriter serializes writes from the goroutines copying stdout and stderr.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// SetOutputCapture redirects the process's standard output and error - including whatever third-party code
+// prints directly - away from the terminal while the app is drawing on it, so stray output can't corrupt
+// the display. Output goes where opts says. The capture is paused, and the original stdout and stderr
+// restored, while the app gives up the terminal - e.g. in Suspend - and when the app closes. The process
+// has only one stdout, so only one app at a time should capture it. Pass nil to stop capturing.
+func (a *App) SetOutputCapture(opts *OutputCaptureOptions) error {
+	var err error
+	if a.capture != nil {
+		err = a.pauseCapture()
+		a.capture = nil
+	}
+	if opts != nil {
+		a.capture = newOutputCapture(*opts)
+		if a.screen != nil {
+			if rerr := a.resumeCapture(); err == nil {
+				err = rerr
+			}
+		}
+	}
+	return err
+}
+
+// OutputCaptured returns true if the process's output is being captured - see SetOutputCapture.
+func (a *App) OutputCaptured() bool {
+	return a.capture != nil && len(a.capture.streams) > 0
+}
+
+// resumeCapture redirects stdout and stderr, if the app captures them and they are not already redirected.
+func (a *App) resumeCapture() error {
+	c := a.capture
+	if c == nil || len(c.streams) > 0 {
+		return nil
+	}
+	to := c.opts.To
+	if to == nil && a.console != nil {
+		to = a.console
+	}
+	if to != nil {
+		to = &lockedWriter{w: to}
+	}
+	files := make([]*os.File, 0, 2)
+	if c.opts.Stdout {
+		files = append(files, os.Stdout)
+	}
+	if c.opts.Stderr {
+		files = append(files, os.Stderr)
+	}
+	for _, f := range files {
+		s, err := captureStream(f, to)
+		if err != nil {
+			a.pauseCapture()
+			return err
+		}
+		c.streams = append(c.streams, s)
+	}
+	return nil
+}
+
+// pauseCapture restores stdout and stderr, and writes out any output held while they were captured.
+func (a *App) pauseCapture() error {
+	c := a.capture
+	if c == nil {
+		return nil
+	}
+	var res error
+	for _, s := range c.streams {
+		if err := s.restore(); err != nil && res == nil {
+			res = err
+		}
+	}
+	c.streams = nil
+	return res
+}
+
+func captureStream(f *os.File, to io.Writer) (*capturedStream, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	saved, err := redirectFd(int(f.Fd()), w)
+	if err != nil {
+		r.Close()
+		w.Close()
+		return nil, err
+	}
+	res := &capturedStream{
+		file:  f,
+		saved: saved,
+		pipe:  w,
+		done:  make(chan struct{}),
+	}
+	if to == nil {
+		res.held = &bytes.Buffer{}
+		to = res.held
+	}
+	go func() {
+		io.Copy(to, r)
+		r.Close()
+		close(res.done)
+	}()
+	return res, nil
+}
+
+func (s *capturedStream) restore() error {
+	err := restoreFd(int(s.file.Fd()), s.saved)
+	s.pipe.Close()
+	<-s.done
+	if s.held != nil && s.held.Len() > 0 {
+		s.file.Write(s.held.Bytes())
+	}
+	return err
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,28 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+//go:build windows || plan9 || js
+// +build windows plan9 js
+
+package gowid
+
+import (
+	"os"
+)
+
+//======================================================================
+
+func redirectFd(fd int, to *os.File) (int, error) {
+	return -1, CaptureNotSupportedError{}
+}
+
+func restoreFd(fd int, saved int) error {
+	return CaptureNotSupportedError{}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
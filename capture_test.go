@@ -0,0 +1,47 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build !windows && !plan9 && !js
+// +build !windows,!plan9,!js
+
+package gowid
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureStream1(t *testing.T) {
+	f, err := ioutil.TempFile("", "gowid-capture")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	var to bytes.Buffer
+	s, err := captureStream(f, &to)
+	assert.NoError(t, err)
+	f.WriteString("captured\n")
+	assert.NoError(t, s.restore())
+	f.WriteString("not captured\n")
+	assert.Equal(t, "captured\n", to.String())
+
+	// Without a writer, output is held until the capture ends
+	s, err = captureStream(f, nil)
+	assert.NoError(t, err)
+	f.WriteString("held\n")
+	assert.NoError(t, s.restore())
+
+	data, err := ioutil.ReadFile(f.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, "not captured\nheld\n", string(data))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
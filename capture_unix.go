@@ -0,0 +1,45 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+//go:build !windows && !plan9 && !js
+// +build !windows,!plan9,!js
+
+package gowid
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+//======================================================================
+
+// redirectFd makes fd refer to to, returning a duplicate of fd as it was.
+func redirectFd(fd int, to *os.File) (int, error) {
+	saved, err := unix.Dup(fd)
+	if err != nil {
+		return -1, errors.WithStack(err)
+	}
+	if err := unix.Dup2(int(to.Fd()), fd); err != nil {
+		unix.Close(saved)
+		return -1, errors.WithStack(err)
+	}
+	return saved, nil
+}
+
+// restoreFd makes fd refer again to what saved does, and closes saved.
+func restoreFd(fd int, saved int) error {
+	defer unix.Close(saved)
+	if err := unix.Dup2(saved, fd); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
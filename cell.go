@@ -4,6 +4,10 @@
 
 package gowid
 
+import (
+	"math"
+)
+
 //======================================================================
 
 // Cell represents a single element of terminal output. The empty value
@@ -13,9 +17,13 @@ package gowid
 // Cell is instantiated.
 type Cell struct {
 	codePoint rune
+	combining string // Runes that form one grapheme cluster with codePoint, like accents - see GraphemeState
 	fg        TCellColor
 	bg        TCellColor
 	style     StyleAttrs
+	link      string     // If not empty, the URL the cell's text links to
+	raw       *RawRegion // If not nil, the cell is part of a region drawn by an escape sequence
+	clarity   uint8      // How much of the Cell beneath shows through, from 0 (none) to 255 - see WithOpacity
 }
 
 // MakeCell returns a Cell initialized with the supplied run (char to display),
@@ -35,17 +43,27 @@ func MakeCell(codePoint rune, fg TCellColor, bg TCellColor, Attr StyleAttrs) Cel
 // MergeUnder returns a Cell representing the receiver merged "underneath" the
 // Cell argument provided. This means the argument's rune value will be used
 // unless it is "empty", and the cell's color and styling come from the
-// argument's value in a similar fashion.
+// argument's value in a similar fashion. If the argument is translucent, it
+// is blended with the receiver instead - see WithOpacity.
 func (c Cell) MergeUnder(upper Cell) Cell {
+	if upper.clarity != 0 {
+		return c.blendUnder(upper)
+	}
 	res := c
 	if upper.codePoint != 0 {
 		res.codePoint = upper.codePoint
+		res.combining = upper.combining
+		res.raw = upper.raw
+	}
+	if upper.codePoint != 0 || upper.bg != ColorNone {
+		// An opaque cell on top hides whatever the receiver would have let show through
+		res.clarity = 0
 	}
 	return res.MergeDisplayAttrsUnder(upper)
 }
 
 // MergeDisplayAttrsUnder returns a Cell representing the receiver Cell with the
-// argument Cell's color and styling applied, if they are explicitly set.
+// argument Cell's color, styling and link applied, if they are explicitly set.
 func (c Cell) MergeDisplayAttrsUnder(upper Cell) Cell {
 	res := c
 	ufg, ubg, ust := upper.GetDisplayAttrs()
@@ -56,6 +74,12 @@ func (c Cell) MergeDisplayAttrsUnder(upper Cell) Cell {
 		res = res.WithForegroundColor(ufg)
 	}
 	res.style = res.style.MergeUnder(ust)
+	if upper.link != "" {
+		res.link = upper.link
+	}
+	if upper.raw != nil {
+		res.raw = upper.raw
+	}
 	return res
 }
 
@@ -89,9 +113,62 @@ func (c Cell) Rune() rune {
 // rune instead.
 func (c Cell) WithRune(r rune) Cell {
 	c.codePoint = r
+	c.combining = ""
+	c.raw = nil
+	return c
+}
+
+// RawRegion returns the region drawn by an escape sequence that the Cell is part of, or nil.
+func (c Cell) RawRegion() *RawRegion {
+	return c.raw
+}
+
+// WithRawRegion returns a Cell equal to the receiver Cell but that is part of the supplied
+// region - see RawRegion.
+func (c Cell) WithRawRegion(r *RawRegion) Cell {
+	c.raw = r
+	return c
+}
+
+// Combining returns the runes drawn in the Cell after its rune, as part of the same
+// grapheme cluster - e.g. an accent, or the rest of an emoji sequence. It returns nil
+// if there are none.
+func (c Cell) Combining() []rune {
+	if c.combining == "" {
+		return nil
+	}
+	return []rune(c.combining)
+}
+
+// WithCombining returns a Cell equal to the receiver Cell but whose rune is followed by
+// the supplied runes, forming one grapheme cluster.
+func (c Cell) WithCombining(rs ...rune) Cell {
+	c.combining = string(rs)
+	return c
+}
+
+// AddCombining returns a Cell equal to the receiver Cell but with the supplied rune
+// appended to its grapheme cluster.
+func (c Cell) AddCombining(r rune) Cell {
+	c.combining += string(r)
 	return c
 }
 
+// Width returns the number of columns the Cell's grapheme cluster takes up in a
+// terminal. An empty Cell takes up one column.
+func (c Cell) Width() int {
+	if c.codePoint == 0 {
+		return 1
+	}
+	var g GraphemeState
+	_, res := g.Add(c.codePoint)
+	for _, r := range c.combining {
+		_, w := g.Add(r)
+		res += w
+	}
+	return res
+}
+
 // BackgroundColor returns the background color of the receiver Cell.
 func (c Cell) BackgroundColor() TCellColor {
 	return c.bg
@@ -107,10 +184,24 @@ func (c Cell) Style() StyleAttrs {
 	return c.style
 }
 
+// Hyperlink returns the URL the receiver Cell links to, or "" if it is not part
+// of a link.
+func (c Cell) Hyperlink() string {
+	return c.link
+}
+
+// WithHyperlink returns a Cell equal to the receiver Cell but that links to the
+// supplied URL. An empty URL means the cell is not part of a link.
+func (c Cell) WithHyperlink(url string) Cell {
+	c.link = url
+	return c
+}
+
 // WithRune returns a Cell equal to the receiver Cell but that will render no
 // rune instead i.e. it is "empty".
 func (c Cell) WithNoRune() Cell {
 	c.codePoint = 0
+	c.combining = ""
 	return c
 }
 
@@ -141,6 +232,20 @@ func (c Cell) WithStyle(attr StyleAttrs) Cell {
 	return c
 }
 
+// Opacity returns how much the Cell hides a Cell beneath it, from 0 to 1 - see WithOpacity.
+func (c Cell) Opacity() float64 {
+	return 1 - float64(c.clarity)/255
+}
+
+// WithOpacity returns a Cell equal to the receiver Cell but that, when merged over another Cell, is
+// blended with it rather than replacing it. An opacity of 1, the default, hides the Cell beneath; 0 lets
+// it show through unchanged. In between, the colors are mixed - see Cell.MergeUnder and BlendColors.
+func (c Cell) WithOpacity(opacity float64) Cell {
+	opacity = math.Max(0, math.Min(1, opacity))
+	c.clarity = uint8(math.Round((1 - opacity) * 255))
+	return c
+}
+
 //======================================================================
 
 // CellFromRune returns a Cell with the supplied rune and with default
@@ -150,11 +255,15 @@ func CellFromRune(r rune) Cell {
 }
 
 // CellsFromString is a utility function to turn a string into an array
-// of Cells. Note that each Cell has no color or style set.
+// of Cells, one for each grapheme cluster. Note that each Cell has no color
+// or style set.
 func CellsFromString(s string) []Cell {
 	res := make([]Cell, 0, len(s)) // overcommits, counts chars and not runes, but minimizes reallocations.
+	var g GraphemeState
 	for _, r := range s {
-		if r != ' ' {
+		if first, _ := g.Add(r); !first && len(res) > 0 {
+			res[len(res)-1] = res[len(res)-1].AddCombining(r)
+		} else if r != ' ' {
 			res = append(res, CellFromRune(r))
 		} else {
 			res = append(res, Cell{})
@@ -0,0 +1,128 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// DefaultMultiClickInterval is how soon a click must follow the one before it, at the same place, to make
+// a double or triple click - unless the app is configured otherwise with SetMultiClickInterval.
+const DefaultMultiClickInterval = 400 * time.Millisecond
+
+// ClickEvent is a mouse event seen as part of a click. Count is 1 for a single click, 2 for the second
+// click of a double click, 3 for a triple click, and so on.
+type ClickEvent struct {
+	Button   tcell.ButtonMask
+	Count    int
+	Released bool // False for the press of the button, true for its release
+}
+
+func (c ClickEvent) String() string {
+	if c.Released {
+		return fmt.Sprintf("click[%d released]", c.Count)
+	}
+	return fmt.Sprintf("click[%d]", c.Count)
+}
+
+// IClickCounter is implemented by an IApp that counts clicks, like App.
+type IClickCounter interface {
+	CurrentClick() (ClickEvent, bool)
+}
+
+// ClickEventFor returns the click that ev represents, if ev is a mouse event passed to a widget's UserInput
+// that presses a button, or releases it where it was pressed. The app counts clicks made in quick
+// succession at the same place, so a widget can act on a double click, e.g.
+//
+//    if cev, ok := gowid.ClickEventFor(ev, app); ok && cev.Count == 2 && !cev.Released {
+//        ...
+//    }
+//
+func ClickEventFor(ev interface{}, app IApp) (ClickEvent, bool) {
+	if _, ok := ev.(*tcell.EventMouse); !ok {
+		return ClickEvent{}, false
+	}
+	if cc, ok := app.(IClickCounter); ok {
+		return cc.CurrentClick()
+	}
+	return ClickEvent{}, false
+}
+
+//======================================================================
+
+type clickTracker struct {
+	interval time.Duration    // If > 0, overrides DefaultMultiClickInterval
+	held     tcell.ButtonMask // The buttons held before the current event
+	button   tcell.ButtonMask // The button last pressed
+	x, y     int              // Where it was pressed
+	last     time.Time        // When it was pressed
+	count    int              // The number of clicks in a row, so far
+	current  *ClickEvent      // The click the event being passed to the widgets represents
+}
+
+// MultiClickInterval returns how soon a click must follow the one before it to make a double or triple
+// click.
+func (a *App) MultiClickInterval() time.Duration {
+	if a.clicks.interval > 0 {
+		return a.clicks.interval
+	}
+	return DefaultMultiClickInterval
+}
+
+// SetMultiClickInterval sets how soon a click must follow the one before it to make a double or triple
+// click. A duration of 0 restores the default.
+func (a *App) SetMultiClickInterval(d time.Duration) {
+	a.clicks.interval = d
+}
+
+// CurrentClick returns the click that the mouse event being processed represents, if any.
+func (a *App) CurrentClick() (ClickEvent, bool) {
+	if a.clicks.current == nil {
+		return ClickEvent{}, false
+	}
+	return *a.clicks.current, true
+}
+
+// trackClicks counts the clicks made by ev, before it is passed to the widgets.
+func (a *App) trackClicks(ev interface{}) {
+	c := &a.clicks
+	c.current = nil
+	mev, ok := ev.(*tcell.EventMouse)
+	if !ok || mev.Buttons()&^dragButtons != 0 {
+		return
+	}
+	x, y := mev.Position()
+	buttons := mev.Buttons()
+	switch {
+	case buttons != tcell.ButtonNone && c.held == tcell.ButtonNone:
+		now := a.Clock().Now()
+		if buttons == c.button && x == c.x && y == c.y && c.count > 0 && now.Sub(c.last) <= a.MultiClickInterval() {
+			c.count++
+		} else {
+			c.count = 1
+		}
+		c.button, c.x, c.y, c.last = buttons, x, y, now
+		c.current = &ClickEvent{Button: buttons, Count: c.count}
+	case buttons == tcell.ButtonNone && c.held != tcell.ButtonNone:
+		if x == c.x && y == c.y {
+			c.current = &ClickEvent{Button: c.button, Count: c.count, Released: true}
+		} else {
+			// The mouse moved while the button was held, so this was no click
+			c.count = 0
+		}
+	}
+	c.held = buttons
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
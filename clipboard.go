@@ -0,0 +1,113 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+//======================================================================
+
+// OSC52MaxBytes is the largest amount of text, before encoding, that CopyToClipboard will send. Many
+// terminals ignore longer OSC 52 sequences.
+var OSC52MaxBytes = 74994
+
+// ClipboardNotSupportedError is returned by CopyToClipboard if the terminal is not thought to support
+// OSC 52.
+type ClipboardNotSupportedError struct {
+	Term string
+}
+
+var _ error = ClipboardNotSupportedError{}
+
+func (e ClipboardNotSupportedError) Error() string {
+	return fmt.Sprintf("Terminal %q does not support setting the clipboard", e.Term)
+}
+
+// ClipboardTooLargeError is returned by CopyToClipboard if the text is longer than OSC52MaxBytes.
+type ClipboardTooLargeError struct {
+	Size int
+	Max  int
+}
+
+var _ error = ClipboardTooLargeError{}
+
+func (e ClipboardTooLargeError) Error() string {
+	return fmt.Sprintf("Text of %d bytes is too large for the clipboard (max %d)", e.Size, e.Max)
+}
+
+// osc52Supported guesses whether the terminal described by the TERM and TERM_PROGRAM environment
+// variables supports OSC 52. There's no reliable way to ask, so only terminals known not to support it
+// are ruled out.
+func osc52Supported(term string, termProgram string) bool {
+	switch {
+	case term == "", term == "dumb", term == "linux", term == "cons25":
+		return false
+	case strings.HasPrefix(term, "vt"):
+		return false
+	case termProgram == "Apple_Terminal":
+		return false
+	}
+	return true
+}
+
+// osc52Sequence returns the escape sequence to set the system clipboard to text. Inside tmux or GNU
+// screen, the sequence is wrapped so that it is passed through to the outer terminal.
+func osc52Sequence(text string, term string, inTmux bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	switch {
+	case inTmux:
+		seq = "\x1bPtmux;" + strings.Replace(seq, "\x1b", "\x1b\x1b", -1) + "\x1b\\"
+	case strings.HasPrefix(term, "screen"):
+		seq = "\x1bP" + seq + "\x1b\\"
+	}
+	return seq
+}
+
+//======================================================================
+
+// ClipboardSupported returns true if the terminal is thought to support setting the system clipboard
+// with OSC 52 escape sequences, or if support has been declared with SetClipboardSupported.
+func (a *App) ClipboardSupported() bool {
+	if a.clipboardSupport != nil {
+		return *a.clipboardSupport
+	}
+	return osc52Supported(a.Getenv("TERM"), a.Getenv("TERM_PROGRAM"))
+}
+
+// SetClipboardSupported overrides the app's guess as to whether the terminal supports OSC 52.
+func (a *App) SetClipboardSupported(supported bool) {
+	a.clipboardSupport = &supported
+}
+
+// CopyToClipboard sets the system clipboard to text, using an OSC 52 escape sequence. Because the
+// sequence is interpreted by the terminal, this works even if the app is running on a remote machine,
+// e.g. over SSH. The terminal doesn't report whether it succeeded - some terminals support OSC 52 but
+// must be configured to allow it. A ClipboardNotSupportedError is returned if the terminal is known not
+// to support it.
+func (a *App) CopyToClipboard(text string) error {
+	if !a.ClipboardSupported() {
+		return ClipboardNotSupportedError{Term: a.Getenv("TERM")}
+	}
+	if len(text) > OSC52MaxBytes {
+		return ClipboardTooLargeError{Size: len(text), Max: OSC52MaxBytes}
+	}
+	a.clipboard = text
+	return a.writeToTerminal(osc52Sequence(text, a.Getenv("TERM"), a.Getenv("TMUX") != ""))
+}
+
+// ClipboardContents returns the text most recently sent to the clipboard by the app. The user may have
+// copied something else since.
+func (a *App) ClipboardContents() string {
+	return a.clipboard
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,29 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOSC52Sequence1(t *testing.T) {
+	assert.Equal(t, "\x1b]52;c;aGVsbG8=\x07", osc52Sequence("hello", "xterm-256color", false))
+	assert.Equal(t, "\x1bPtmux;\x1b\x1b]52;c;aGVsbG8=\x07\x1b\\", osc52Sequence("hello", "screen-256color", true))
+	assert.Equal(t, "\x1bP\x1b]52;c;aGVsbG8=\x07\x1b\\", osc52Sequence("hello", "screen", false))
+
+	assert.True(t, osc52Supported("xterm-256color", ""))
+	assert.True(t, osc52Supported("xterm-256color", "iTerm.app"))
+	assert.False(t, osc52Supported("xterm-256color", "Apple_Terminal"))
+	assert.False(t, osc52Supported("linux", ""))
+	assert.False(t, osc52Supported("vt100", ""))
+	assert.False(t, osc52Supported("", ""))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
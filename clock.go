@@ -0,0 +1,269 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+//======================================================================
+
+// ITicker is a source of regular time events, like time.Ticker.
+type ITicker interface {
+	Chan() <-chan time.Time
+	Stop()
+}
+
+// ITimer is a single future event, like time.Timer. Stop returns true if the
+// call stops the timer, false if it has already fired or been stopped.
+type ITimer interface {
+	Chan() <-chan time.Time
+	Stop() bool
+}
+
+// IClock is the source of time for gowid widgets that animate, count down or
+// otherwise depend on the passing of time. Widgets should look up the clock
+// via ClockFor(app) rather than calling the time package directly, so that
+// tests can substitute a FakeClock and step time deterministically.
+type IClock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTicker(d time.Duration) ITicker
+	NewTimer(d time.Duration) ITimer
+	// AfterFunc calls f after duration d - on its own goroutine, for RealClock.
+	// The returned ITimer's channel is not used.
+	AfterFunc(d time.Duration, f func()) ITimer
+}
+
+// IClocked is implemented by an IApp that provides its own clock, like App.
+type IClocked interface {
+	Clock() IClock
+}
+
+// DefaultClock is the clock used by apps that don't provide one.
+var DefaultClock IClock = RealClock{}
+
+// ClockFor returns the app's clock if it provides one, otherwise DefaultClock.
+func ClockFor(app IApp) IClock {
+	if c, ok := app.(IClocked); ok {
+		if res := c.Clock(); res != nil {
+			return res
+		}
+	}
+	return DefaultClock
+}
+
+//======================================================================
+
+// RealClock is an IClock that defers to the time package.
+type RealClock struct{}
+
+var _ IClock = RealClock{}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) Chan() <-chan time.Time {
+	return t.C
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) Chan() <-chan time.Time {
+	return t.C
+}
+
+func (c RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c RealClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (c RealClock) NewTicker(d time.Duration) ITicker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (c RealClock) NewTimer(d time.Duration) ITimer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (c RealClock) AfterFunc(d time.Duration, f func()) ITimer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+//======================================================================
+
+// FakeClock is an IClock whose time only moves when Advance or Set is called.
+// Tickers and timers created from a FakeClock fire synchronously, in time
+// order, from within Advance. As with time.Ticker, a ticker's channel holds
+// at most one pending event; further ticks are dropped until it is read.
+type FakeClock struct {
+	sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	seq     int
+}
+
+var _ IClock = (*FakeClock)(nil)
+
+type fakeWaiter struct {
+	clock  *FakeClock
+	when   time.Time
+	period time.Duration
+	seq    int
+	ch     chan time.Time
+	f      func()
+}
+
+// NewFakeClock returns a FakeClock set to the supplied time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now: now,
+	}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *FakeClock) add(d time.Duration, period time.Duration, f func()) *fakeWaiter {
+	c.Lock()
+	defer c.Unlock()
+	c.seq++
+	w := &fakeWaiter{
+		clock:  c,
+		when:   c.now.Add(d),
+		period: period,
+		seq:    c.seq,
+		ch:     make(chan time.Time, 1),
+		f:      f,
+	}
+	c.waiters = append(c.waiters, w)
+	return w
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) ITicker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	return fakeTicker{c.add(d, d, nil)}
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) ITimer {
+	return fakeTimer{c.add(d, 0, nil)}
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) ITimer {
+	return fakeTimer{c.add(d, 0, f)}
+}
+
+// Pending returns the number of tickers and timers that have yet to fire or
+// be stopped.
+func (c *FakeClock) Pending() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.waiters)
+}
+
+// Advance moves the clock forward by d, firing any tickers and timers due in
+// that period.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to t, firing any tickers and timers due up to and
+// including t. Timer functions are called without the clock's lock held, so
+// they may themselves use the clock.
+func (c *FakeClock) Set(t time.Time) {
+	for {
+		c.Lock()
+		sort.SliceStable(c.waiters, func(i, j int) bool {
+			if !c.waiters[i].when.Equal(c.waiters[j].when) {
+				return c.waiters[i].when.Before(c.waiters[j].when)
+			}
+			return c.waiters[i].seq < c.waiters[j].seq
+		})
+		if len(c.waiters) == 0 || c.waiters[0].when.After(t) {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.Unlock()
+			return
+		}
+		w := c.waiters[0]
+		c.now = w.when
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+		} else {
+			c.waiters = c.waiters[1:]
+		}
+		now := c.now
+		c.Unlock()
+
+		if w.f != nil {
+			w.f()
+		} else {
+			select {
+			case w.ch <- now:
+			default:
+			}
+		}
+	}
+}
+
+func (c *FakeClock) remove(w *fakeWaiter) bool {
+	c.Lock()
+	defer c.Unlock()
+	for i, w2 := range c.waiters {
+		if w2 == w {
+			c.waiters = append(c.waiters[0:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+func (t fakeTicker) Chan() <-chan time.Time {
+	return t.ch
+}
+
+func (t fakeTicker) Stop() {
+	t.clock.remove(t.fakeWaiter)
+}
+
+type fakeTimer struct {
+	*fakeWaiter
+}
+
+func (t fakeTimer) Chan() <-chan time.Time {
+	return t.ch
+}
+
+func (t fakeTimer) Stop() bool {
+	return t.clock.remove(t.fakeWaiter)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,75 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock1(t *testing.T) {
+	start := time.Unix(1000, 0)
+	c := NewFakeClock(start)
+	assert.Equal(t, start, c.Now())
+
+	fired := []string{}
+	c.AfterFunc(2*time.Second, func() { fired = append(fired, "b") })
+	c.AfterFunc(1*time.Second, func() { fired = append(fired, "a") })
+	tm := c.AfterFunc(3*time.Second, func() { fired = append(fired, "c") })
+
+	c.Advance(2 * time.Second)
+	assert.Equal(t, []string{"a", "b"}, fired)
+	assert.Equal(t, 2*time.Second, c.Since(start))
+
+	assert.True(t, tm.Stop())
+	assert.False(t, tm.Stop())
+	c.Advance(10 * time.Second)
+	assert.Equal(t, []string{"a", "b"}, fired)
+	assert.Equal(t, 0, c.Pending())
+}
+
+func TestFakeClock2(t *testing.T) {
+	c := NewFakeClock(time.Unix(0, 0))
+	tk := c.NewTicker(time.Second)
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case <-tk.Chan():
+		assert.Fail(t, "Ticker fired too soon")
+	default:
+	}
+
+	c.Advance(500 * time.Millisecond)
+	assert.Equal(t, time.Unix(1, 0), <-tk.Chan())
+
+	// Ticks are dropped if the channel is not drained
+	c.Advance(3 * time.Second)
+	assert.Equal(t, time.Unix(2, 0), <-tk.Chan())
+	tk.Stop()
+	assert.Equal(t, 0, c.Pending())
+}
+
+func TestFakeClock3(t *testing.T) {
+	c := NewFakeClock(time.Unix(0, 0))
+	n := 0
+	var f func()
+	f = func() {
+		n++
+		if n < 3 {
+			c.AfterFunc(time.Second, f)
+		}
+	}
+	c.AfterFunc(time.Second, f)
+	c.Advance(10 * time.Second)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, time.Unix(10, 0), c.Now())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
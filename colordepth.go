@@ -0,0 +1,215 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gdamore/tcell"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+//======================================================================
+
+// DetectColorMode returns the color mode of a terminal - colors is the number of colors tcell says it
+// has, from the terminfo database, and getenv looks up the terminal's environment, which can say more:
+// NO_COLOR, if set, means monochrome; COLORTERM of "truecolor" or "24bit" means 24-bit color; and a TERM
+// ending in "256color" means at least 256 colors, even if the terminfo entry is missing or out of date.
+func DetectColorMode(colors int, getenv func(string) string) ColorMode {
+	if getenv("NO_COLOR") != "" {
+		return ModeMonochrome
+	}
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return Mode24BitColors
+	}
+	if strings.HasSuffix(getenv("TERM"), "256color") && colors < 256 {
+		colors = 256
+	}
+	switch {
+	case colors > 256:
+		return Mode24BitColors
+	case colors == 256:
+		return Mode256Colors
+	case colors == 88:
+		return Mode88Colors
+	case colors == 16:
+		return Mode16Colors
+	case colors <= 0:
+		return ModeMonochrome
+	default:
+		return Mode8Colors
+	}
+}
+
+//======================================================================
+
+// QuantizeStrategy says how a color that the terminal can't show - e.g. a 24-bit color on a 256-color
+// terminal - is replaced with one it can.
+type QuantizeStrategy int
+
+const (
+	QuantizeLab  QuantizeStrategy = iota // The closest color as the eye sees it, in CIE L*a*b* - the default
+	QuantizeRGB                          // The closest color in RGB - cheaper, but can match blues poorly
+	QuantizeNone                         // Colors are left for tcell to map as it sees fit
+)
+
+func (q QuantizeStrategy) String() string {
+	switch q {
+	case QuantizeLab:
+		return "lab"
+	case QuantizeRGB:
+		return "rgb"
+	case QuantizeNone:
+		return "none"
+	default:
+		return fmt.Sprintf("quantize(%d)", int(q))
+	}
+}
+
+// ColorDowngradeOptions configures how an app draws colors its terminal can't show.
+type ColorDowngradeOptions struct {
+	Strategy QuantizeStrategy
+	// Overrides replace palette entries in the color modes given, for when the nearest color isn't good
+	// enough - e.g. a subtle 24-bit highlight that would vanish on a 16-color terminal can be drawn in
+	// reverse video there instead. They are consulted before the app's theme and palette.
+	Overrides map[ColorMode]Palette
+}
+
+// IColorDowngrader is implemented by an IColorMode that replaces colors its terminal can't show - Draw
+// passes each cell's colors through it.
+type IColorDowngrader interface {
+	DowngradeColor(c tcell.Color) tcell.Color
+}
+
+// SetColorDowngrade changes how the app draws colors its terminal can't show.
+func (a *App) SetColorDowngrade(opts ColorDowngradeOptions) {
+	a.downgrade = opts
+	a.Redraw()
+}
+
+func (a *App) ColorDowngrade() ColorDowngradeOptions {
+	return a.downgrade
+}
+
+// DowngradeColor returns c, or if the app's terminal can't show it, the color that replaces it. It lets
+// App conform to IColorDowngrader.
+func (a *App) DowngradeColor(c tcell.Color) tcell.Color {
+	return QuantizeColor(c, a.GetColorMode(), a.downgrade.Strategy)
+}
+
+// paletteOverride returns the app's override for the palette entry name in its current color mode, if
+// there is one.
+func (a *App) paletteOverride(name string) (ICellStyler, bool) {
+	if p, ok := a.downgrade.Overrides[a.GetColorMode()]; ok {
+		res, ok := p[name]
+		return res, ok
+	}
+	return nil, false
+}
+
+func downgradeCellColor(d IColorDowngrader, c TCellColor) TCellColor {
+	if c == ColorNone {
+		return c
+	}
+	return MakeTCellColorExt(d.DowngradeColor(c.ToTCell()))
+}
+
+//======================================================================
+
+type quantizeKey struct {
+	color    tcell.Color
+	mode     ColorMode
+	strategy QuantizeStrategy
+}
+
+var (
+	quantizeCache *lru.Cache
+	xtermColorful [256]colorful.Color // The xterm palette, for matching in L*a*b*
+)
+
+func init() {
+	var err error
+	quantizeCache, err = lru.New(1000)
+	if err != nil {
+		panic(err)
+	}
+	for i := range xtermColorful {
+		r, g, b := tcell.Color(i).RGB()
+		xtermColorful[i] = colorful.Color{R: float64(r) / 255.0, G: float64(g) / 255.0, B: float64(b) / 255.0}
+	}
+}
+
+// quantizeRange returns the number of palette colors a terminal in mode can show, and how many of those
+// can be matched against - an 88-color terminal's cube differs from the 256-color one tcell describes, so
+// only its 16 basic colors are candidates.
+func quantizeRange(mode ColorMode) (shown int, candidates int) {
+	switch mode {
+	case Mode256Colors:
+		return 256, 256
+	case Mode88Colors:
+		return 88, 16
+	case Mode16Colors:
+		return 16, 16
+	case Mode8Colors:
+		return 8, 8
+	default:
+		return 0, 0
+	}
+}
+
+// QuantizeColor returns c if a terminal in mode can show it, or else the palette color it can show
+// closest to c by strategy. Monochrome terminals get the default color.
+func QuantizeColor(c tcell.Color, mode ColorMode, strategy QuantizeStrategy) tcell.Color {
+	if strategy == QuantizeNone || mode == Mode24BitColors || c < 0 {
+		return c
+	}
+	shown, candidates := quantizeRange(mode)
+	// tcell's named colors beyond its 256 indexed ones, like ColorDarkCyan, are left for it to map on a
+	// 256-color terminal
+	if c&tcell.ColorIsRGB == 0 && (int(c) < shown || mode == Mode256Colors) {
+		return c
+	}
+	if candidates == 0 {
+		return tcell.ColorDefault
+	}
+	key := quantizeKey{color: c, mode: mode, strategy: strategy}
+	if res, ok := quantizeCache.Get(key); ok {
+		return res.(tcell.Color)
+	}
+	r, g, b := c.RGB()
+	if r < 0 {
+		return c
+	}
+
+	target := colorful.Color{R: float64(r) / 255.0, G: float64(g) / 255.0, B: float64(b) / 255.0}
+	res := tcell.Color(0)
+	best := math.MaxFloat64
+	for i := 0; i < candidates; i++ {
+		var dist float64
+		switch strategy {
+		case QuantizeRGB:
+			cr, cg, cb := tcell.Color(i).RGB()
+			dist = float64((r-cr)*(r-cr) + (g-cg)*(g-cg) + (b-cb)*(b-cb))
+		default:
+			dist = target.DistanceLab(xtermColorful[i])
+		}
+		if dist < best {
+			best = dist
+			res = tcell.Color(i)
+		}
+	}
+	quantizeCache.Add(key, res)
+	return res
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,50 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectColorMode1(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(name string) string { return vars[name] }
+	}
+	assert.Equal(t, Mode256Colors, DetectColorMode(256, env(nil)))
+	assert.Equal(t, Mode8Colors, DetectColorMode(8, env(nil)))
+	assert.Equal(t, ModeMonochrome, DetectColorMode(0, env(nil)))
+	assert.Equal(t, Mode24BitColors, DetectColorMode(1<<24, env(nil)))
+	assert.Equal(t, Mode24BitColors, DetectColorMode(256, env(map[string]string{"COLORTERM": "truecolor"})))
+	assert.Equal(t, Mode24BitColors, DetectColorMode(8, env(map[string]string{"COLORTERM": "24bit"})))
+	assert.Equal(t, Mode256Colors, DetectColorMode(8, env(map[string]string{"TERM": "screen-256color"})))
+	assert.Equal(t, ModeMonochrome, DetectColorMode(256, env(map[string]string{"NO_COLOR": "1", "COLORTERM": "truecolor"})))
+}
+
+func TestQuantizeColor1(t *testing.T) {
+	orange := tcell.NewRGBColor(0xff, 0x87, 0x00)
+	assert.Equal(t, orange, QuantizeColor(orange, Mode24BitColors, QuantizeLab))
+	assert.Equal(t, tcell.Color208, QuantizeColor(orange, Mode256Colors, QuantizeLab))
+	assert.Equal(t, tcell.Color208, QuantizeColor(orange, Mode256Colors, QuantizeRGB))
+	assert.Equal(t, orange, QuantizeColor(orange, Mode256Colors, QuantizeNone))
+
+	// Colors the terminal can show are left as they are
+	assert.Equal(t, tcell.ColorMaroon, QuantizeColor(tcell.ColorMaroon, Mode8Colors, QuantizeLab))
+	assert.Equal(t, tcell.ColorMaroon, QuantizeColor(tcell.ColorRed, Mode8Colors, QuantizeLab))
+	assert.Equal(t, tcell.Color80, QuantizeColor(tcell.Color80, Mode88Colors, QuantizeLab))
+	assert.Equal(t, tcell.ColorDefault, QuantizeColor(tcell.ColorDefault, Mode16Colors, QuantizeLab))
+
+	assert.True(t, QuantizeColor(orange, Mode16Colors, QuantizeLab) < 16)
+	assert.True(t, QuantizeColor(tcell.Color208, Mode8Colors, QuantizeRGB) < 8)
+	assert.True(t, QuantizeColor(orange, Mode88Colors, QuantizeLab) < 16)
+	assert.Equal(t, tcell.ColorWhite, QuantizeColor(tcell.NewRGBColor(0xfe, 0xfe, 0xfe), Mode16Colors, QuantizeLab))
+	assert.Equal(t, tcell.ColorDefault, QuantizeColor(orange, ModeMonochrome, QuantizeLab))
+
+	// Grays no longer need a palette with a gray ramp
+	c, ok := MakeGrayColor("g100").ToTCellColor(Mode16Colors)
+	assert.True(t, ok)
+	assert.Equal(t, tcell.ColorWhite, c.ToTCell())
+}
@@ -0,0 +1,222 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gcla/gowid/gwutil"
+)
+
+//======================================================================
+
+// ColorScale is how a ColorMap spreads values along its palette.
+type ColorScale int
+
+const (
+	// LinearScale spreads values evenly from the map's minimum to its maximum.
+	LinearScale ColorScale = iota
+	// LogScale gives more of the palette to small values, for data spanning orders of magnitude - e.g.
+	// request counts. Values are offset by the minimum, so they needn't be positive.
+	LogScale
+	// EqualizedScale gives each color about the same share of the values the map was fitted to - see
+	// ColorMap.Fit - so that detail shows even when most values are bunched together. Also known as
+	// histogram equalization.
+	EqualizedScale
+)
+
+func (s ColorScale) String() string {
+	switch s {
+	case LinearScale:
+		return "linear"
+	case LogScale:
+		return "log"
+	case EqualizedScale:
+		return "equalized"
+	default:
+		return fmt.Sprintf("scale(%d)", int(s))
+	}
+}
+
+// ColorPalette is a gradient for mapping values to colors. A smooth gradient can't be shown on a terminal
+// with 16 colors or fewer - the nearest color to each step may not even be in order of brightness - so a
+// palette can give a ramp of basic colors to use instead.
+type ColorPalette struct {
+	Name     string
+	Gradient Gradient
+	Basic    []IColor // In order from low to high; if nil, the gradient's colors are approximated
+}
+
+func basicRamp(names ...string) []IColor {
+	res := make([]IColor, len(names))
+	for i, name := range names {
+		res[i] = NewUrwidColor(name)
+	}
+	return res
+}
+
+func rgbRamp(hex ...string) []IColor {
+	res := make([]IColor, len(hex))
+	for i, h := range hex {
+		res[i] = MakeRGBColor(h)
+	}
+	return res
+}
+
+// Standard palettes. Viridis, magma, inferno and plasma are the perceptually uniform colormaps from
+// matplotlib - a step in value looks like the same step in color all along them, and they read well
+// to color-blind users.
+var (
+	ViridisPalette = ColorPalette{
+		Name:     "viridis",
+		Gradient: MakeGradient(RGBSpace, rgbRamp("#440154", "#472d7b", "#3b528b", "#2c728e", "#21918c", "#28ae80", "#5ec962", "#addc30", "#fde725")...),
+		Basic:    basicRamp("dark magenta", "dark blue", "dark cyan", "dark green", "light green", "yellow"),
+	}
+	MagmaPalette = ColorPalette{
+		Name:     "magma",
+		Gradient: MakeGradient(RGBSpace, rgbRamp("#000004", "#180f3d", "#440f76", "#721f81", "#9e2f7f", "#cd4071", "#f1605d", "#fd9668", "#feca8d", "#fcfdbf")...),
+		Basic:    basicRamp("black", "dark blue", "dark magenta", "light magenta", "light red", "yellow", "white"),
+	}
+	InfernoPalette = ColorPalette{
+		Name:     "inferno",
+		Gradient: MakeGradient(RGBSpace, rgbRamp("#000004", "#1b0c41", "#4a0c6b", "#781c6d", "#a52c60", "#cf4446", "#ed6925", "#fb9b06", "#f7d13d", "#fcffa4")...),
+		Basic:    basicRamp("black", "dark blue", "dark magenta", "dark red", "light red", "yellow", "white"),
+	}
+	PlasmaPalette = ColorPalette{
+		Name:     "plasma",
+		Gradient: MakeGradient(RGBSpace, rgbRamp("#0d0887", "#46039f", "#7201a8", "#9c179e", "#bd3786", "#d8576b", "#ed7953", "#fb9f3a", "#fdca26", "#f0f921")...),
+		Basic:    basicRamp("dark blue", "dark magenta", "light magenta", "light red", "brown", "yellow"),
+	}
+	GrayPalette = ColorPalette{
+		Name:     "gray",
+		Gradient: MakeGradient(RGBSpace, rgbRamp("#000000", "#ffffff")...),
+		Basic:    basicRamp("black", "dark gray", "light gray", "white"),
+	}
+)
+
+//======================================================================
+
+// maxEqualizeSamples limits the values a ColorMap keeps to equalize its scale.
+const maxEqualizeSamples = 1024
+
+// ColorMap maps values to colors along a palette, e.g. for the cells of a heatmap. Values from Min to
+// Max are spread along the palette according to Scale; values outside that range get the color at the
+// nearer end.
+type ColorMap struct {
+	Palette  ColorPalette
+	Scale    ColorScale
+	Min, Max float64
+	sorted   []float64 // A sorted sample of the values fitted, for EqualizedScale
+}
+
+// NewColorMap returns a map with range 0 to 1 - call SetRange or Fit to change it.
+func NewColorMap(palette ColorPalette, scale ColorScale) *ColorMap {
+	return &ColorMap{
+		Palette: palette,
+		Scale:   scale,
+		Max:     1,
+	}
+}
+
+func (m *ColorMap) String() string {
+	return fmt.Sprintf("colormap[%s,%v,%g-%g]", m.Palette.Name, m.Scale, m.Min, m.Max)
+}
+
+func (m *ColorMap) SetRange(lo, hi float64) {
+	m.Min, m.Max = lo, hi
+	m.sorted = nil
+}
+
+// Fit sets the range of the map to that of values, ignoring NaNs. With EqualizedScale, the map also
+// remembers how the values are distributed, so it should be fitted again when the data changes.
+func (m *ColorMap) Fit(values []float64) {
+	sorted := make([]float64, 0, len(values))
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			sorted = append(sorted, v)
+		}
+	}
+	if len(sorted) == 0 {
+		m.SetRange(0, 1)
+		return
+	}
+	sort.Float64s(sorted)
+	m.Min, m.Max = sorted[0], sorted[len(sorted)-1]
+	if len(sorted) > maxEqualizeSamples {
+		sample := make([]float64, maxEqualizeSamples)
+		for i := range sample {
+			sample[i] = sorted[i*(len(sorted)-1)/(maxEqualizeSamples-1)]
+		}
+		sorted = sample
+	}
+	m.sorted = sorted
+}
+
+// Position returns how far along the palette v lies, from 0 to 1. NaN is placed at 0.
+func (m *ColorMap) Position(v float64) float64 {
+	if math.IsNaN(v) || m.Max <= m.Min {
+		return 0
+	}
+	var res float64
+	switch {
+	case m.Scale == LogScale:
+		res = math.Log1p(math.Max(0, v-m.Min)) / math.Log1p(m.Max-m.Min)
+	case m.Scale == EqualizedScale && len(m.sorted) > 1:
+		// The rank of v among the values fitted - the middle one if several are equal
+		lo := sort.Search(len(m.sorted), func(i int) bool { return m.sorted[i] >= v })
+		hi := sort.Search(len(m.sorted), func(i int) bool { return m.sorted[i] > v })
+		res = (float64(lo+hi-1) / 2) / float64(len(m.sorted)-1)
+	default:
+		res = (v - m.Min) / (m.Max - m.Min)
+	}
+	return math.Max(0, math.Min(1, res))
+}
+
+// At returns the color for v. In 24-bit, 256 and 88 color modes, this is the color along the palette's
+// gradient; with fewer colors, it is the step of the palette's basic ramp that v falls in.
+func (m *ColorMap) At(v float64) IColor {
+	t := m.Position(v)
+	res := mappedColor{color: m.Palette.Gradient.At(t)}
+	if n := len(m.Palette.Basic); n > 0 {
+		res.basic = m.Palette.Basic[gwutil.Min(int(t*float64(n)), n-1)]
+	}
+	return res
+}
+
+// Styler returns a style that fills the background with the color for v.
+func (m *ColorMap) Styler(v float64) ICellStyler {
+	return MakePaletteEntry(NoColor{}, m.At(v))
+}
+
+// mappedColor is a color from a ColorMap, which falls back to a color from the palette's basic ramp when
+// the terminal has few colors.
+type mappedColor struct {
+	color IColor
+	basic IColor
+}
+
+var _ IColor = mappedColor{}
+
+func (c mappedColor) String() string {
+	return fmt.Sprintf("%v", c.color)
+}
+
+func (c mappedColor) ToTCellColor(mode ColorMode) (TCellColor, bool) {
+	switch mode {
+	case Mode16Colors, Mode8Colors, ModeMonochrome:
+		if c.basic != nil {
+			return c.basic.ToTCellColor(mode)
+		}
+	}
+	return c.color.ToTCellColor(mode)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
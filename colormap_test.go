@@ -0,0 +1,67 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorMap1(t *testing.T) {
+	m := NewColorMap(GrayPalette, LinearScale)
+	m.SetRange(10, 20)
+	assert.Equal(t, 0.0, m.Position(5))
+	assert.Equal(t, 0.5, m.Position(15))
+	assert.Equal(t, 1.0, m.Position(25))
+	assert.Equal(t, 0.0, m.Position(math.NaN()))
+
+	m.Scale = LogScale
+	m.SetRange(0, 999)
+	assert.InDelta(t, 1.0/3, m.Position(9), 1e-9)
+	assert.InDelta(t, 2.0/3, m.Position(99), 1e-9)
+
+	// Most values are small, but each color covers a fair share of them
+	m.Scale = EqualizedScale
+	m.Fit([]float64{1, 2, 3, 4, math.NaN(), 1000})
+	assert.Equal(t, 1.0, m.Min)
+	assert.Equal(t, 1000.0, m.Max)
+	assert.Equal(t, 0.0, m.Position(1))
+	assert.Equal(t, 0.5, m.Position(3))
+	assert.Equal(t, 0.75, m.Position(4))
+	assert.Equal(t, 1.0, m.Position(1000))
+	assert.Equal(t, 0.875, m.Position(500))
+
+	// Equal values share the middle rank
+	m.Fit([]float64{1, 2, 2, 3})
+	assert.Equal(t, 0.5, m.Position(2))
+}
+
+func TestColorMap2(t *testing.T) {
+	m := NewColorMap(ViridisPalette, LinearScale)
+	c := m.At(0)
+	rgb, ok := IColorToRGB(c)
+	assert.True(t, ok)
+	assert.Equal(t, RGBColor{0x44, 0x01, 0x54}, rgb)
+
+	tc, _ := m.At(1).ToTCellColor(Mode24BitColors)
+	exp, _ := MakeRGBColor("#fde725").ToTCellColor(Mode24BitColors)
+	assert.Equal(t, exp, tc)
+
+	// With 16 colors, the basic ramp is used, so colors stay in order
+	for i, name := range []string{"dark magenta", "dark blue", "dark cyan", "dark green", "light green", "yellow"} {
+		v := (float64(i) + 0.5) / 6
+		tc, _ = m.At(v).ToTCellColor(Mode16Colors)
+		exp, _ = NewUrwidColor(name).ToTCellColor(Mode16Colors)
+		assert.Equal(t, exp, tc, "unexpected color for %v", v)
+	}
+	tc, _ = m.At(1).ToTCellColor(Mode16Colors)
+	exp, _ = NewUrwidColor("yellow").ToTCellColor(Mode16Colors)
+	assert.Equal(t, exp, tc)
+
+	f, b, _ := m.Styler(0.5).GetStyle(stateTestContext{Palette{}})
+	assert.Equal(t, NoColor{}, f)
+	assert.Equal(t, m.At(0.5), b)
+}
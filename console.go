@@ -0,0 +1,338 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+	log "github.com/sirupsen/logrus"
+)
+
+//======================================================================
+
+var (
+	// DefaultLogConsoleLines is the most log lines the console keeps, unless the options say otherwise.
+	DefaultLogConsoleLines = 1000
+	// DefaultLogConsoleHeight is the fraction of the screen the console covers, unless the options say
+	// otherwise.
+	DefaultLogConsoleHeight = 0.4
+)
+
+// LogConsoleOptions configures the log console - see App.SetLogConsole.
+type LogConsoleOptions struct {
+	Key        IKey      // Shows and hides the console - F12 if nil
+	Lines      int       // The most lines kept - DefaultLogConsoleLines if 0
+	Height     float64   // The fraction of the screen covered, from the top - DefaultLogConsoleHeight if 0
+	Level      log.Level // The least severe level mirrored; the zero value, log.PanicLevel, means all levels
+	Foreground IColor    // Defaults to light gray
+	Background IColor    // Defaults to near black
+}
+
+// LogConsole keeps the most recent lines logged by the app, and draws them in a console that drops down
+// from the top of the screen, like a game's. It is a logrus hook, so it sees the app's structured logs
+// without anything being written to the terminal; it is also an io.Writer, for other loggers.
+type LogConsole struct {
+	opts    LogConsoleOptions
+	app     IApp
+	mu      sync.Mutex
+	lines   []logConsoleLine // Oldest first
+	shown   bool
+	scroll  int  // How many lines the view is scrolled back from the newest
+	pending bool // True if a redraw has been asked for and not yet done
+	off     bool // True once the console is replaced, since a logrus hook can't be removed
+}
+
+var _ log.Hook = (*LogConsole)(nil)
+
+type logConsoleLine struct {
+	text  string
+	level log.Level
+}
+
+func newLogConsole(opts LogConsoleOptions, app IApp) *LogConsole {
+	if opts.Key == nil {
+		opts.Key = MakeKeyExt(tcell.KeyF12)
+	}
+	if opts.Lines <= 0 {
+		opts.Lines = DefaultLogConsoleLines
+	}
+	if opts.Height <= 0 || opts.Height > 1 {
+		opts.Height = DefaultLogConsoleHeight
+	}
+	if opts.Level == log.PanicLevel {
+		opts.Level = log.TraceLevel
+	}
+	if opts.Foreground == nil {
+		opts.Foreground = MakeRGBColor("#ccc")
+	}
+	if opts.Background == nil {
+		opts.Background = MakeRGBColor("#111")
+	}
+	return &LogConsole{opts: opts, app: app}
+}
+
+func (c *LogConsole) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return fmt.Sprintf("logconsole[%d]", len(c.lines))
+}
+
+// Levels returns the levels mirrored to the console. It lets LogConsole conform to logrus.Hook.
+func (c *LogConsole) Levels() []log.Level {
+	res := make([]log.Level, 0, len(log.AllLevels))
+	for _, l := range log.AllLevels {
+		if l <= c.opts.Level {
+			res = append(res, l)
+		}
+	}
+	return res
+}
+
+// Fire adds a log entry to the console. It lets LogConsole conform to logrus.Hook, and may be called from
+// any goroutine.
+func (c *LogConsole) Fire(entry *log.Entry) error {
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %-5.5s %s", entry.Time.Format("15:04:05"), strings.ToUpper(entry.Level.String()),
+		strings.TrimSuffix(entry.Message, "\n"))
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, entry.Data[k])
+	}
+	c.add(b.String(), entry.Level)
+	return nil
+}
+
+// Write adds each line of p to the console, so that a logger that isn't logrus can be mirrored too. It
+// may be called from any goroutine.
+func (c *LogConsole) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		c.add(line, log.InfoLevel)
+	}
+	return len(p), nil
+}
+
+func (c *LogConsole) add(text string, level log.Level) {
+	c.mu.Lock()
+	if c.off {
+		c.mu.Unlock()
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		c.lines = append(c.lines, logConsoleLine{text: line, level: level})
+		if c.scroll > 0 {
+			c.scroll++ // Keep the lines being read still
+		}
+	}
+	if len(c.lines) > c.opts.Lines {
+		c.lines = c.lines[len(c.lines)-c.opts.Lines:]
+	}
+	redraw := c.shown && !c.pending
+	c.pending = c.pending || redraw
+	c.mu.Unlock()
+
+	// Not app.Redraw() directly - the app's own logging happens on the app goroutine, and many lines at
+	// once shouldn't queue many redraws.
+	if redraw {
+		go c.app.Run(RunFunction(func(app IApp) {
+			c.mu.Lock()
+			c.pending = false
+			c.mu.Unlock()
+		}))
+	}
+}
+
+// Lines returns the text of the lines in the console, oldest first.
+func (c *LogConsole) Lines() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := make([]string, len(c.lines))
+	for i, l := range c.lines {
+		res[i] = l.text
+	}
+	return res
+}
+
+// Clear removes all lines from the console.
+func (c *LogConsole) Clear() {
+	c.mu.Lock()
+	c.lines = nil
+	c.scroll = 0
+	c.mu.Unlock()
+}
+
+// Shown returns true if the console is being displayed.
+func (c *LogConsole) Shown() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.shown
+}
+
+// SetShown shows or hides the console.
+func (c *LogConsole) SetShown(shown bool, app IApp) {
+	c.mu.Lock()
+	c.shown = shown
+	c.scroll = 0
+	c.mu.Unlock()
+	app.Redraw()
+}
+
+//======================================================================
+
+// SetLogConsole mirrors the app's logs into a console that drops down from the top of the screen when F12,
+// or the key in opts, is pressed - so diagnostics can be read without a separate terminal tailing the log
+// file, and without writing to stderr over the UI. While the console is shown, page up and page down
+// scroll it, and other input goes to the app as usual. The app's log must be a logrus Logger or Entry to
+// be mirrored; lines can also be written to the console from elsewhere - see LogConsole. Pass nil to
+// turn the console off.
+func (a *App) SetLogConsole(opts *LogConsoleOptions) *LogConsole {
+	if a.console != nil {
+		a.console.mu.Lock()
+		a.console.off = true
+		a.console.mu.Unlock()
+	}
+	a.console = nil
+	if opts != nil {
+		a.console = newLogConsole(*opts, a)
+		switch l := a.log.(type) {
+		case *log.Logger:
+			l.AddHook(a.console)
+		case *log.Entry:
+			l.Logger.AddHook(a.console)
+		}
+	}
+	a.Redraw()
+	return a.console
+}
+
+// LogConsole returns the app's log console, or nil if there is none - see SetLogConsole.
+func (a *App) LogConsole() *LogConsole {
+	return a.console
+}
+
+// logConsoleInput shows or hides the console, and scrolls it. It returns true if the event was consumed.
+func (a *App) logConsoleInput(ev interface{}) bool {
+	c := a.console
+	if c == nil {
+		return false
+	}
+	kev, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return false
+	}
+	if KeysEqual(kev, c.opts.Key) {
+		c.SetShown(!c.Shown(), a)
+		return true
+	}
+	if !c.Shown() {
+		return false
+	}
+	_, rows := a.TerminalSize()
+	page := gwutil.Max(1, c.height(rows)-2)
+	switch kev.Key() {
+	case tcell.KeyPgUp:
+		c.scrollBy(page)
+	case tcell.KeyPgDn:
+		c.scrollBy(-page)
+	default:
+		return false
+	}
+	a.Redraw()
+	return true
+}
+
+func (c *LogConsole) scrollBy(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scroll = gwutil.Max(0, gwutil.Min(c.scroll+n, len(c.lines)-1))
+}
+
+// height returns how many rows the console covers on a screen of rows, including its bottom border.
+func (c *LogConsole) height(rows int) int {
+	return gwutil.Min(rows, gwutil.Max(2, int(float64(rows)*c.opts.Height)))
+}
+
+// levelColor returns the color in which a line logged at level is drawn.
+func (c *LogConsole) levelColor(level log.Level) IColor {
+	switch {
+	case level <= log.ErrorLevel:
+		return MakeRGBColor("#f55")
+	case level == log.WarnLevel:
+		return MakeRGBColor("#fd5")
+	case level >= log.DebugLevel:
+		return MakeRGBColor("#888")
+	default:
+		return c.opts.Foreground
+	}
+}
+
+// draw draws the console over the top of canvas, if it is being shown.
+func (c *LogConsole) draw(canvas ICanvas, mode ColorMode, app IApp) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.shown {
+		return
+	}
+	cols, rows := canvas.BoxColumns(), canvas.BoxRows()
+	if cols == 0 || rows == 0 {
+		return
+	}
+	height := c.height(rows)
+	bg := IColorToTCell(c.opts.Background, ColorNone, mode)
+	blank := MakeCell(' ', IColorToTCell(c.opts.Foreground, ColorNone, mode), bg, StyleNone)
+	for row := 0; row < height; row++ {
+		for col := 0; col < cols; col++ {
+			canvas.SetCellAt(col, row, blank)
+		}
+	}
+
+	// The newest lines at the bottom, less any scrolled back
+	end := len(c.lines) - c.scroll
+	start := gwutil.Max(0, end-(height-1))
+	for i, line := range c.lines[start:end] {
+		cell := blank.WithForegroundColor(IColorToTCell(c.levelColor(line.level), ColorNone, mode))
+		x := 0
+		for _, r := range line.text {
+			w := runewidth.RuneWidth(r)
+			if x+w > cols {
+				break
+			}
+			canvas.SetCellAt(x, i, cell.WithRune(r))
+			x += gwutil.Max(1, w)
+		}
+	}
+
+	status := fmt.Sprintf(" log: %d lines ", len(c.lines))
+	if c.scroll > 0 {
+		status = fmt.Sprintf(" log: %d lines, %d more below ", len(c.lines), c.scroll)
+	}
+	hor := ASCIIRune(app, '─', '-')
+	x := 0
+	for col := 0; col < cols; col++ {
+		canvas.SetCellAt(col, height-1, blank.WithRune(hor))
+	}
+	for _, r := range status {
+		if x+2 >= cols {
+			break
+		}
+		canvas.SetCellAt(x+2, height-1, blank.WithRune(r))
+		x++
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,46 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogConsole1(t *testing.T) {
+	c := newLogConsole(LogConsoleOptions{Lines: 3, Level: log.InfoLevel}, nil)
+
+	logger := log.New()
+	logger.Out = &strings.Builder{}
+	logger.SetLevel(log.DebugLevel)
+	logger.AddHook(c)
+
+	at := time.Date(2020, 1, 2, 13, 4, 5, 0, time.UTC)
+	logger.WithTime(at).WithField("b", 2).WithField("a", "x").Infof("hello")
+	logger.WithTime(at).Debugf("not mirrored")
+	logger.WithTime(at).Errorf("oops")
+	assert.Equal(t, []string{"13:04:05 INFO  hello a=x b=2", "13:04:05 ERROR oops"}, c.Lines())
+
+	c.Write([]byte("one\ntwo\n"))
+	assert.Equal(t, []string{"13:04:05 ERROR oops", "one", "two"}, c.Lines())
+
+	c.shown = true
+	canvas := NewCanvasOfSize(12, 10)
+	c.draw(canvas, Mode256Colors, nil)
+	lines := strings.Split(canvas.String(), "\n")
+	assert.Equal(t, "one         ", lines[1])
+	assert.Equal(t, "two         ", lines[2])
+	assert.Equal(t, "── log: 3 li", lines[3])
+	assert.Equal(t, "            ", lines[4])
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,94 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"context"
+)
+
+//======================================================================
+
+// IContexted is implemented by an IApp that provides a context for the work done on its behalf, like
+// App. The context is done once the app quits, so goroutines started by widgets should watch it - via
+// ContextFor(app) - and stop.
+type IContexted interface {
+	Context() context.Context
+}
+
+var _ IContexted = (*App)(nil)
+
+// ContextFor returns the app's context if it provides one, otherwise context.Background().
+func ContextFor(app IApp) context.Context {
+	if c, ok := app.(IContexted); ok {
+		if res := c.Context(); res != nil {
+			return res
+		}
+	}
+	return context.Background()
+}
+
+// RunContextFunction is like RunFunction, but is also given the app's context - see ContextFor.
+type RunContextFunction func(ctx context.Context, app IApp)
+
+// RunThenRenderEvent lets the receiver implement IAfterRenderEvent.
+func (f RunContextFunction) RunThenRenderEvent(app IApp) {
+	f(ContextFor(app), app)
+}
+
+//======================================================================
+
+// Context returns a context that is done once the app quits, or is closed. Within MainLoopCtx, it is derived from the
+// context given, so carries its values. It may be called from any goroutine.
+func (a *App) Context() context.Context {
+	a.closingMtx.Lock()
+	defer a.closingMtx.Unlock()
+	return a.ctx
+}
+
+// MainLoopCtx is like MainLoop, but the app also quits when ctx is done - so the UI can be shut down from
+// outside, e.g. by a signal handler, or by a server whose client has gone. The app's context is derived
+// from ctx while the loop runs. ctx.Err() is returned if ctx is done, otherwise nil.
+func (a *App) MainLoopCtx(ctx context.Context, unhandled IUnhandledInput) error {
+	a.closingMtx.Lock()
+	outer := a.cancel
+	a.ctx, a.cancel = context.WithCancel(ctx)
+	if a.closing {
+		a.cancel()
+	}
+	a.closingMtx.Unlock()
+	// A context handed out before the loop began is done when it ends
+	if outer != nil {
+		defer outer()
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			a.Quit()
+		case <-stop:
+		}
+	}()
+	a.MainLoop(unhandled)
+	return ctx.Err()
+}
+
+// Go runs f on a goroutine of its own, with the app's context, which is done when the app quits - so f
+// should return soon after. Like the rest of the app, f's panics are reported by RecoverPanic. f must
+// use Run to change the widgets.
+func (a *App) Go(f func(ctx context.Context)) {
+	ctx := a.Context()
+	go func() {
+		defer a.RecoverPanic()
+		f(ctx)
+	}()
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,270 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// WidgetNode is a serializable description of one widget in a widget
+// hierarchy, and recursively of its children.
+type WidgetNode struct {
+	Type       string        `json:"type"`
+	Desc       string        `json:"desc,omitempty"`
+	Selectable bool          `json:"selectable"`
+	Focus      int           `json:"focus"`
+	Children   []*WidgetNode `json:"children,omitempty"`
+}
+
+// maxWidgetDescLen limits the length of each widget's description in a
+// crash report; some widgets can describe themselves at great length.
+const maxWidgetDescLen = 80
+
+// DescribeWidgetTree walks the widget hierarchy rooted at w and returns a
+// description suitable for serializing. Children are found via IComposite and
+// ICompositeMultiple. Focus is the index of the focus child for IFocus
+// widgets, and -1 otherwise. Only leaf widgets are given a Desc, because
+// container widgets typically describe themselves by including their
+// children's descriptions.
+func DescribeWidgetTree(w IWidget) *WidgetNode {
+	if w == nil {
+		return nil
+	}
+	res := &WidgetNode{
+		Type:       fmt.Sprintf("%T", w),
+		Selectable: w.Selectable(),
+		Focus:      -1,
+	}
+	if fw, ok := w.(IFocus); ok {
+		res.Focus = fw.Focus()
+	}
+	switch cw := w.(type) {
+	case ICompositeMultiple:
+		for _, sw := range cw.SubWidgets() {
+			res.Children = append(res.Children, DescribeWidgetTree(sw))
+		}
+	case IComposite:
+		if sw := cw.SubWidget(); sw != nil {
+			res.Children = append(res.Children, DescribeWidgetTree(sw))
+		}
+	default:
+		if s, ok := w.(fmt.Stringer); ok {
+			desc := []rune(s.String())
+			if len(desc) > maxWidgetDescLen {
+				desc = append(desc[0:maxWidgetDescLen-3], []rune("...")...)
+			}
+			res.Desc = string(desc)
+		}
+	}
+	return res
+}
+
+//======================================================================
+
+// CrashReport is a bug-report bundle describing the state of a gowid
+// application - the shape of the widget hierarchy, the last frame rendered to
+// the terminal and the most recent user input. It can be generated on request
+// (e.g. via a keybinding) or when the application panics.
+type CrashReport struct {
+	Time    time.Time   `json:"time"`
+	Reason  string      `json:"reason"`
+	Stack   string      `json:"stack,omitempty"`
+	Columns int         `json:"columns"`
+	Rows    int         `json:"rows"`
+	Widgets *WidgetNode `json:"widgets"`
+	Screen  string      `json:"screen"`
+	Events  []string    `json:"events"`
+}
+
+// JSON returns the report serialized as indented JSON.
+func (r *CrashReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Save writes the report to dir as two files - a JSON file holding the full
+// report, and a text file holding a screenshot of the last rendered frame
+// followed by the recent input events, for quick inspection. The names of the
+// files written are returned.
+func (r *CrashReport) Save(dir string) ([]string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	base := filepath.Join(dir, fmt.Sprintf("%s-crash-%s",
+		filepath.Base(os.Args[0]), r.Time.Format("20060102-150405.000")))
+
+	js, err := r.JSON()
+	if err != nil {
+		return nil, err
+	}
+	jsonFile := base + ".json"
+	if err := ioutil.WriteFile(jsonFile, js, 0644); err != nil {
+		return nil, err
+	}
+
+	txt := fmt.Sprintf("%s\n\n%s\n\nRecent events:\n", r.Reason, r.Screen)
+	for _, ev := range r.Events {
+		txt += ev + "\n"
+	}
+	if r.Stack != "" {
+		txt += "\n" + r.Stack
+	}
+	txtFile := base + ".txt"
+	if err := ioutil.WriteFile(txtFile, []byte(txt), 0644); err != nil {
+		return []string{jsonFile}, err
+	}
+
+	return []string{jsonFile, txtFile}, nil
+}
+
+//======================================================================
+
+// CrashReportOptions configures the App's crash reporting.
+type CrashReportOptions struct {
+	Dir       string // Directory in which to save reports; defaults to the working directory
+	Key       IKey   // If not nil, this keypress saves a report instead of being passed to the widgets
+	MaxEvents int    // Number of recent input events to keep; defaults to 50
+	OnPanic   bool   // If true, save a report if MainLoop panics
+}
+
+type crashReporter struct {
+	opts   CrashReportOptions
+	events []string
+	next   int
+	screen ICanvas // The last frame rendered - only made into text for a report
+}
+
+func newCrashReporter(opts CrashReportOptions) *crashReporter {
+	if opts.MaxEvents <= 0 {
+		opts.MaxEvents = 50
+	}
+	return &crashReporter{
+		opts:   opts,
+		events: make([]string, 0, opts.MaxEvents),
+	}
+}
+
+func describeInputEvent(ev interface{}) string {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		return fmt.Sprintf("%s key %s", ev.When().Format("15:04:05.000"), ev.Name())
+	case *tcell.EventMouse:
+		x, y := ev.Position()
+		return fmt.Sprintf("%s mouse x=%d y=%d buttons=%d mods=%v", ev.When().Format("15:04:05.000"),
+			x, y, ev.Buttons(), PrettyModMask(ev.Modifiers()))
+	default:
+		return fmt.Sprintf("%T %v", ev, ev)
+	}
+}
+
+func (c *crashReporter) record(ev interface{}) {
+	desc := describeInputEvent(ev)
+	if len(c.events) < c.opts.MaxEvents {
+		c.events = append(c.events, desc)
+	} else {
+		c.events[c.next] = desc
+		c.next = (c.next + 1) % c.opts.MaxEvents
+	}
+}
+
+func (c *crashReporter) recentEvents() []string {
+	res := make([]string, 0, len(c.events))
+	res = append(res, c.events[c.next:]...)
+	res = append(res, c.events[0:c.next]...)
+	return res
+}
+
+//======================================================================
+
+// EnableCrashReports makes the App keep track of recent input and the last
+// rendered frame so that a CrashReport can be generated.
+func (a *App) EnableCrashReports(opts CrashReportOptions) {
+	a.crash = newCrashReporter(opts)
+}
+
+// CrashReport returns a report describing the current state of the app. The
+// screen and recent events are only available if EnableCrashReports has
+// been called.
+func (a *App) CrashReport(reason string) *CrashReport {
+	x, y := a.TerminalSize()
+	res := &CrashReport{
+		Time:    time.Now(),
+		Reason:  reason,
+		Columns: x,
+		Rows:    y,
+		Widgets: DescribeWidgetTree(a.viewPlusMenus),
+		Events:  []string{},
+	}
+	if a.crash != nil {
+		if a.crash.screen != nil {
+			res.Screen = a.crash.screen.String()
+		}
+		res.Events = a.crash.recentEvents()
+	}
+	return res
+}
+
+// SaveCrashReport generates a CrashReport and saves it to the directory
+// configured via EnableCrashReports.
+func (a *App) SaveCrashReport(reason string) ([]string, error) {
+	dir := ""
+	if a.crash != nil {
+		dir = a.crash.opts.Dir
+	}
+	return a.CrashReport(reason).Save(dir)
+}
+
+// crashReportInput records the input event, and returns true if the event
+// was the keypress configured to save a report.
+func (a *App) crashReportInput(ev interface{}) bool {
+	if a.crash == nil {
+		return false
+	}
+	a.crash.record(ev)
+	if a.crash.opts.Key != nil {
+		if kev, ok := ev.(*tcell.EventKey); ok && KeysEqual(kev, a.crash.opts.Key) {
+			if files, err := a.SaveCrashReport("Requested by user"); err != nil {
+				a.log.Printf("Could not save crash report: %v\n", err)
+			} else {
+				a.log.Printf("Saved crash report to %v\n", files)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// saveCrashReportOnPanic should be deferred. If a report is generated, the
+// panic is propagated once the report is saved.
+func (a *App) saveCrashReportOnPanic() {
+	if a.crash == nil || !a.crash.opts.OnPanic {
+		return
+	}
+	if r := recover(); r != nil {
+		res := a.CrashReport(fmt.Sprintf("Panic: %v", r))
+		res.Stack = string(debug.Stack())
+		if files, err := res.Save(a.crash.opts.Dir); err != nil {
+			a.log.Printf("Could not save crash report: %v\n", err)
+		} else {
+			a.log.Printf("Saved crash report to %v\n", files)
+		}
+		panic(r)
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,28 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrashReportEvents1(t *testing.T) {
+	c := newCrashReporter(CrashReportOptions{MaxEvents: 3})
+	for _, r := range "abcde" {
+		c.record(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	evs := c.recentEvents()
+	assert.Equal(t, 3, len(evs))
+	assert.Contains(t, evs[0], "Rune[c]")
+	assert.Contains(t, evs[2], "Rune[e]")
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
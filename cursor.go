@@ -0,0 +1,156 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+)
+
+//======================================================================
+
+// CursorShape is the shape of the terminal's cursor.
+type CursorShape int
+
+const (
+	CursorDefault   CursorShape = iota // Whatever the terminal uses by default - usually a block
+	CursorBlock                        // Covers the whole cell
+	CursorUnderline                    // A line under the cell
+	CursorBar                          // A line at the left of the cell, e.g. to show text is inserted
+)
+
+func (s CursorShape) String() string {
+	switch s {
+	case CursorDefault:
+		return "default"
+	case CursorBlock:
+		return "block"
+	case CursorUnderline:
+		return "underline"
+	case CursorBar:
+		return "bar"
+	default:
+		return fmt.Sprintf("cursorshape(%d)", int(s))
+	}
+}
+
+// CursorStyle is how the terminal draws its cursor. The zero value leaves it as the terminal draws it by
+// default.
+type CursorStyle struct {
+	Shape CursorShape
+	Blink bool // Ignored with CursorDefault
+}
+
+func (s CursorStyle) String() string {
+	if s.Blink && s.Shape != CursorDefault {
+		return "blinking " + s.Shape.String()
+	}
+	return s.Shape.String()
+}
+
+// sequence returns the DECSCUSR escape sequence that sets the terminal's cursor to s.
+func (s CursorStyle) sequence() string {
+	n := 0
+	switch s.Shape {
+	case CursorBlock:
+		n = 2
+	case CursorUnderline:
+		n = 4
+	case CursorBar:
+		n = 6
+	}
+	if n > 0 && s.Blink {
+		n--
+	}
+	return fmt.Sprintf("\x1b[%d q", n)
+}
+
+// ICursorStyler is implemented by a widget that wants the cursor drawn in a particular style while it has
+// the focus - e.g. an editor showing whether typing inserts or overwrites. The deepest widget on the path
+// to the focus with a style other than CursorDefault decides; if there is none, the app's style is used.
+type ICursorStyler interface {
+	CursorStyle() CursorStyle
+}
+
+// ICursorController is implemented by an IApp that lets its cursor be styled and hidden, like App.
+type ICursorController interface {
+	CursorStyle() CursorStyle
+	SetCursorStyle(style CursorStyle)
+	CursorVisible() bool
+	SetCursorVisible(visible bool)
+}
+
+var _ ICursorController = (*App)(nil)
+
+// cursorState tracks the style of the app's cursor, and what was last sent to the terminal.
+type cursorState struct {
+	style   CursorStyle // Used unless a widget in focus asks for another
+	hidden  bool        // If true, the cursor isn't shown even if a widget places it
+	written CursorStyle // The style the terminal was last set to
+}
+
+// CursorStyle returns the style of the cursor, unless the widget in focus asks for another - see
+// SetCursorStyle.
+func (a *App) CursorStyle() CursorStyle {
+	return a.cursor.style
+}
+
+// SetCursorStyle sets the shape of the cursor, and whether it blinks, when the widget in focus doesn't
+// ask for a style of its own - see ICursorStyler. The terminal's default style is restored when the app
+// closes.
+func (a *App) SetCursorStyle(style CursorStyle) {
+	a.cursor.style = style
+	a.Redraw()
+}
+
+// CursorVisible returns false if the cursor is hidden - see SetCursorVisible.
+func (a *App) CursorVisible() bool {
+	return !a.cursor.hidden
+}
+
+// SetCursorVisible shows or hides the cursor. While it is hidden, the cursor isn't drawn even where a
+// widget - like a focused edit - places it.
+func (a *App) SetCursorVisible(visible bool) {
+	a.cursor.hidden = !visible
+	a.Redraw()
+}
+
+// FocusCursorStyle returns the style asked for by the deepest widget on the path to the focus beneath w
+// that is an ICursorStyler, and true - or false if none asks for a style.
+func FocusCursorStyle(w IWidget) (CursorStyle, bool) {
+	var res CursorStyle
+	found := false
+	FindInHierarchy(w, true, WidgetPredicate(func(w IWidget) bool {
+		if cs, ok := w.(ICursorStyler); ok {
+			if s := cs.CursorStyle(); s.Shape != CursorDefault {
+				res, found = s, true
+			}
+		}
+		return false
+	}))
+	return res, found
+}
+
+// drawCursorStyle sets the terminal's cursor to the style for view, if it has changed.
+func (a *App) drawCursorStyle(view IWidget) {
+	style := a.cursor.style
+	if s, ok := FocusCursorStyle(view); ok {
+		style = s
+	}
+	a.writeCursorStyle(style)
+}
+
+func (a *App) writeCursorStyle(style CursorStyle) {
+	if style != a.cursor.written {
+		if a.writeToTerminal(style.sequence()) == nil {
+			a.cursor.written = style
+		}
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
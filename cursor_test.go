@@ -0,0 +1,51 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cursorStyled struct {
+	*HelpWidget
+	style CursorStyle
+}
+
+func (w *cursorStyled) CursorStyle() CursorStyle {
+	return w.style
+}
+
+func TestCursorStyle1(t *testing.T) {
+	assert.Equal(t, "\x1b[0 q", CursorStyle{}.sequence())
+	assert.Equal(t, "\x1b[0 q", CursorStyle{Blink: true}.sequence())
+	assert.Equal(t, "\x1b[1 q", CursorStyle{Shape: CursorBlock, Blink: true}.sequence())
+	assert.Equal(t, "\x1b[4 q", CursorStyle{Shape: CursorUnderline}.sequence())
+	assert.Equal(t, "\x1b[6 q", CursorStyle{Shape: CursorBar}.sequence())
+	assert.Equal(t, "blinking bar", CursorStyle{Shape: CursorBar, Blink: true}.String())
+
+	bar := CursorStyle{Shape: CursorBar}
+	inner := &cursorStyled{HelpWidget: NewHelp(nil, ""), style: bar}
+	outer := &cursorStyled{HelpWidget: NewHelp(inner, ""), style: CursorStyle{Shape: CursorUnderline}}
+	s, ok := FocusCursorStyle(outer)
+	assert.True(t, ok)
+	assert.Equal(t, bar, s)
+
+	// A widget asking for the default defers to those above it
+	inner.style = CursorStyle{}
+	s, ok = FocusCursorStyle(outer)
+	assert.True(t, ok)
+	assert.Equal(t, CursorUnderline, s.Shape)
+
+	outer.style = CursorStyle{}
+	_, ok = FocusCursorStyle(outer)
+	assert.False(t, ok)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
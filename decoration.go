@@ -869,6 +869,9 @@ func (s GrayColor) ToTCellColor(mode ColorMode) (TCellColor, bool) {
 	case Mode88Colors:
 		x := tcell.Color(grayAdjustment88(grayLookup88_101[s.Val]) + 1)
 		return MakeTCellColorExt(x), true
+	case Mode16Colors, Mode8Colors, ModeMonochrome:
+		adj := int32(intScale(s.Val, 101, 0x100))
+		return MakeTCellColorExt(QuantizeColor(tcell.NewRGBColor(adj, adj, adj), mode, QuantizeLab)), true
 	default:
 		panic(errors.WithStack(ColorModeMismatch{Color: s, Mode: mode}))
 	}
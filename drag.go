@@ -0,0 +1,242 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// DragPhase is the part of a drag that a mouse event represents - see DragEventFor.
+type DragPhase int
+
+const (
+	DragNone  DragPhase = iota // The mouse event isn't part of a drag
+	DragStart                  // The mouse has moved with a button held; sent where the button was pressed
+	DragOver                   // The mouse has moved during a drag; sent where the mouse is
+	Drop                       // The button has been released; sent where the mouse is
+)
+
+func (p DragPhase) String() string {
+	switch p {
+	case DragStart:
+		return "drag-start"
+	case DragOver:
+		return "drag-over"
+	case Drop:
+		return "drop"
+	default:
+		return "none"
+	}
+}
+
+// IDragSource is implemented by a widget that accepts a drag - see Drag.Accept. It is told about each
+// movement of the mouse, and about the end of the drag, wherever the mouse is - e.g. so that a divider
+// can follow the mouse to resize a pane.
+type IDragSource interface {
+	DragMoved(d *Drag, app IApp)
+	DragEnded(d *Drag, app IApp) // d.Dropped or d.Cancelled say how the drag ended
+}
+
+// Drag describes a drag in progress. Positions are in screen coordinates.
+type Drag struct {
+	Button         tcell.ButtonMask // The button held down
+	StartX, StartY int              // Where the button was pressed
+	X, Y           int              // Where the mouse is now
+	Payload        interface{}      // Set by the widget that accepts the drag, for the drop target
+	Dropped        bool             // True once the drag has ended with a Drop that a widget handled
+	Cancelled      bool             // True if the drag ended without a drop, e.g. because Esc was pressed
+	source         IDragSource
+	accepted       bool
+	starting       bool
+}
+
+func (d *Drag) String() string {
+	return fmt.Sprintf("drag[(%d,%d)->(%d,%d)]", d.StartX, d.StartY, d.X, d.Y)
+}
+
+// Delta returns how far the mouse has moved since the button was pressed.
+func (d *Drag) Delta() (int, int) {
+	return d.X - d.StartX, d.Y - d.StartY
+}
+
+// Accept claims the drag for the widget handling the DragStart event, with a payload for the widget it is
+// dropped on. source may be nil; otherwise it is told as the drag moves and ends. Accept returns false if
+// the drag is not starting, or has already been accepted.
+func (d *Drag) Accept(source IDragSource, payload interface{}) bool {
+	if !d.starting || d.accepted {
+		return false
+	}
+	d.accepted = true
+	d.source = source
+	d.Payload = payload
+	return true
+}
+
+// Accepted returns true if a widget has accepted the drag.
+func (d *Drag) Accepted() bool {
+	return d.accepted
+}
+
+func (d *Drag) Source() IDragSource {
+	return d.source
+}
+
+// DragEvent is a mouse event seen as part of a drag. X and Y are relative to the widget receiving it, like
+// the position of the mouse event.
+type DragEvent struct {
+	Phase DragPhase
+	X, Y  int
+	*Drag
+}
+
+// IDragger is implemented by an IApp that synthesizes drags from mouse events, like App.
+type IDragger interface {
+	CurrentDrag() (*Drag, DragPhase)
+}
+
+// DragEventFor returns the drag event that ev represents, if ev is a mouse event passed to a widget's
+// UserInput as part of a drag. The app turns a press, movement and release of a mouse button into a drag:
+// when the mouse first moves, a DragStart is sent to the widget under the point where the button was
+// pressed, which can then accept the drag; each movement is sent as a DragOver to the widget under the
+// mouse; and the release is sent as a Drop. Containers route these as they do any mouse event, so a
+// widget sees them in its own coordinates, e.g.
+//
+//    if dev, ok := gowid.DragEventFor(ev, app); ok && dev.Phase == gowid.Drop {
+//        ...
+//    }
+//
+func DragEventFor(ev interface{}, app IApp) (DragEvent, bool) {
+	mev, ok := ev.(*tcell.EventMouse)
+	if !ok {
+		return DragEvent{}, false
+	}
+	dr, ok := app.(IDragger)
+	if !ok {
+		return DragEvent{}, false
+	}
+	d, phase := dr.CurrentDrag()
+	if d == nil || phase == DragNone {
+		return DragEvent{}, false
+	}
+	x, y := mev.Position()
+	return DragEvent{Phase: phase, X: x, Y: y, Drag: d}, true
+}
+
+//======================================================================
+
+const dragButtons = tcell.Button1 | tcell.Button2 | tcell.Button3
+
+type dragTracker struct {
+	press *tcell.EventMouse // The press that would begin a drag
+	drag  *Drag             // The drag in progress
+	phase DragPhase         // The phase of the event being passed to the widgets
+}
+
+// CurrentDrag returns the drag in progress, if any, and the phase of the mouse event being processed.
+func (a *App) CurrentDrag() (*Drag, DragPhase) {
+	return a.drag.drag, a.drag.phase
+}
+
+// CancelDrag ends the drag in progress, if any, without a drop. Pressing Esc during a drag does this too.
+func (a *App) CancelDrag() {
+	d := a.drag.drag
+	if d == nil {
+		return
+	}
+	a.drag = dragTracker{}
+	d.Cancelled = true
+	a.ClickTargets.DeleteClickTargets(d.Button)
+	if d.source != nil {
+		d.source.DragEnded(d, a)
+	}
+}
+
+// dragInput updates the drag state for ev before it is passed to the widgets. It returns true if ev was
+// consumed.
+func (a *App) dragInput(ev interface{}) bool {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		if a.drag.drag != nil && ev.Key() == tcell.KeyEsc {
+			a.CancelDrag()
+			return true
+		}
+	case *tcell.EventMouse:
+		if ev.Buttons()&^dragButtons != 0 {
+			// Wheel events don't affect drags
+			return false
+		}
+		x, y := ev.Position()
+		buttons := ev.Buttons()
+		d := a.drag.drag
+		switch {
+		case d != nil && buttons == d.Button:
+			d.X, d.Y = x, y
+			a.drag.phase = DragOver
+		case d != nil && buttons == tcell.ButtonNone:
+			d.X, d.Y = x, y
+			a.drag.phase = Drop
+			if d.accepted {
+				// Don't let the release click the widget the drag began on
+				a.ClickTargets.DeleteClickTargets(d.Button)
+			}
+		case d != nil:
+			a.CancelDrag()
+		case buttons == tcell.ButtonNone:
+			a.drag.press = nil
+		case a.drag.press == nil || a.drag.press.Buttons() != buttons:
+			a.drag.press = ev
+		default:
+			press := a.drag.press
+			px, py := press.Position()
+			if x == px && y == py {
+				return false
+			}
+			d = &Drag{
+				Button:   buttons,
+				StartX:   px,
+				StartY:   py,
+				X:        x,
+				Y:        y,
+				starting: true,
+			}
+			a.drag = dragTracker{drag: d, phase: DragStart}
+			a.userInputToWidgets(press)
+			d.starting = false
+			a.drag.phase = DragOver
+		}
+	}
+	return false
+}
+
+// dragInputDone notifies the source of the drag after the event has been passed to the widgets.
+func (a *App) dragInputDone(handled bool) {
+	d, phase := a.drag.drag, a.drag.phase
+	a.drag.phase = DragNone
+	if d == nil {
+		return
+	}
+	switch phase {
+	case DragOver:
+		if d.source != nil {
+			d.source.DragMoved(d, a)
+		}
+	case Drop:
+		a.drag = dragTracker{}
+		d.Dropped = handled
+		if d.source != nil {
+			d.source.DragEnded(d, a)
+		}
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,393 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"unicode"
+
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+//======================================================================
+
+// FindSpec describes what the app's find mode is looking for.
+type FindSpec struct {
+	Query      string
+	IgnoreCase bool
+}
+
+func (s FindSpec) fold(r rune) rune {
+	if s.IgnoreCase {
+		return unicode.ToLower(r)
+	}
+	return r
+}
+
+// index returns the rune index of the first occurrence of the query in line at
+// or after start, or -1.
+func (s FindSpec) index(line []rune, start int) int {
+	q := []rune(s.Query)
+	if len(q) == 0 {
+		return -1
+	}
+outer:
+	for i := start; i+len(q) <= len(line); i++ {
+		for j, r := range q {
+			if s.fold(line[i+j]) != s.fold(r) {
+				continue outer
+			}
+		}
+		return i
+	}
+	return -1
+}
+
+// Matches returns true if str contains the query.
+func (s FindSpec) Matches(str string) bool {
+	return s.index([]rune(str), 0) != -1
+}
+
+// MatchesCanvas returns true if any line of the canvas contains the query.
+func (s FindSpec) MatchesCanvas(c IDrawCanvas) bool {
+	return len(s.findInSnapshot(SnapshotCanvas(c), -1)) > 0
+}
+
+// FindMatch is the position of a match on the screen. Columns is the number of
+// screen columns the match covers.
+type FindMatch struct {
+	X, Y    int
+	Columns int
+}
+
+// findInSnapshot returns the matches in the first rows of the snapshot, or all
+// rows if rows is negative.
+func (s FindSpec) findInSnapshot(snap *Snapshot, rows int) []FindMatch {
+	res := make([]FindMatch, 0)
+	if rows < 0 || rows > snap.Rows {
+		rows = snap.Rows
+	}
+	for y := 0; y < rows; y++ {
+		line := make([]rune, 0, snap.Cols)
+		cols := make([]int, 0, snap.Cols+1)
+		for x, c := range snap.Cells[y] {
+			if c.Rune != 0 {
+				line = append(line, c.Rune)
+				cols = append(cols, x)
+			}
+		}
+		cols = append(cols, snap.Cols)
+		for i := s.index(line, 0); i != -1; i = s.index(line, i+len([]rune(s.Query))) {
+			end := i + len([]rune(s.Query))
+			res = append(res, FindMatch{
+				X:       cols[i],
+				Y:       y,
+				Columns: cols[end-1] + runewidth.RuneWidth(line[end-1]) - cols[i],
+			})
+		}
+	}
+	return res
+}
+
+// IFindable is implemented by widgets that hold more content than they display,
+// like lists. When the app's find mode has visited every match on the screen, it
+// calls FindNext on the first IFindable widget in the focus path. The widget
+// should move focus to the next (or previous, if forward is false) part of its
+// content, beyond what is displayed, that matches the spec, and scroll it into
+// view. It returns false if there is no such content.
+type IFindable interface {
+	FindNext(spec FindSpec, forward bool, app IApp) bool
+}
+
+//======================================================================
+
+// FindOptions configures the app's find mode.
+type FindOptions struct {
+	Key          IKey        // If not nil, pressing this key opens the find prompt
+	IgnoreCase   bool        // Match without regard to case
+	MatchStyle   ICellStyler // Applied to each match on screen; defaults to reverse video
+	CurrentStyle ICellStyler // Applied to the selected match; defaults to black on yellow
+	PromptStyle  ICellStyler // Applied to the find prompt; defaults to reverse video
+}
+
+type finder struct {
+	opts    FindOptions
+	prompt  bool // true if the prompt is displayed and takes keyboard input
+	query   string
+	matches []FindMatch
+	current int
+	// After an IFindable widget scrolls, select the first (1) or last (-1)
+	// match of the next frame.
+	selectAfterRender int
+}
+
+func newFinder(opts FindOptions) *finder {
+	if opts.MatchStyle == nil {
+		opts.MatchStyle = MakeStyledAs(StyleReverse)
+	}
+	if opts.CurrentStyle == nil {
+		opts.CurrentStyle = MakePaletteEntry(ColorBlack, ColorYellow)
+	}
+	if opts.PromptStyle == nil {
+		opts.PromptStyle = MakeStyledAs(StyleReverse)
+	}
+	return &finder{
+		opts: opts,
+	}
+}
+
+func (f *finder) spec() FindSpec {
+	return FindSpec{Query: f.query, IgnoreCase: f.opts.IgnoreCase}
+}
+
+func styleCell(styler ICellStyler, app IApp) Cell {
+	fg, bg, st := styler.GetStyle(app)
+	return MakeCell(0,
+		IColorToTCell(fg, ColorNone, app.GetColorMode()),
+		IColorToTCell(bg, ColorNone, app.GetColorMode()),
+		st,
+	)
+}
+
+// update finds matches in the rendered frame, highlights them, and draws the
+// prompt on the bottom line if it's open.
+func (f *finder) update(canvas ICanvas, app IApp) {
+	rows := canvas.BoxRows()
+	if f.prompt {
+		rows--
+	}
+	f.matches = f.spec().findInSnapshot(SnapshotCanvas(canvas), rows)
+
+	switch {
+	case f.selectAfterRender > 0:
+		f.current = 0
+	case f.selectAfterRender < 0:
+		f.current = len(f.matches) - 1
+	}
+	f.selectAfterRender = 0
+	if f.current >= len(f.matches) {
+		f.current = len(f.matches) - 1
+	}
+	if f.current < 0 && len(f.matches) > 0 {
+		f.current = 0
+	}
+
+	matchCell := styleCell(f.opts.MatchStyle, app)
+	currentCell := styleCell(f.opts.CurrentStyle, app)
+	for i, m := range f.matches {
+		style := matchCell
+		if i == f.current {
+			style = currentCell
+		}
+		for x := m.X; x < m.X+m.Columns && x < canvas.BoxColumns(); x++ {
+			canvas.SetCellAt(x, m.Y, canvas.CellAt(x, m.Y).MergeDisplayAttrsUnder(style))
+		}
+	}
+
+	if f.prompt && canvas.BoxRows() > 0 {
+		var status string
+		switch {
+		case f.query == "":
+			status = ""
+		case len(f.matches) == 0:
+			status = " no matches "
+		default:
+			status = fmt.Sprintf(" %d/%d ", f.current+1, len(f.matches))
+		}
+		cols := canvas.BoxColumns()
+		line := make([]rune, cols)
+		for i := range line {
+			line[i] = ' '
+		}
+		copy(line, []rune("Find: "+f.query))
+		if st := []rune(status); len(st) <= cols {
+			copy(line[cols-len(st):], st)
+		}
+		style := styleCell(f.opts.PromptStyle, app)
+		y := canvas.BoxRows() - 1
+		for x := 0; x < cols; x++ {
+			canvas.SetCellAt(x, y, style.WithRune(line[x]))
+		}
+		canvas.SetCursorCoords(gwutil.Min(6+len([]rune(f.query)), cols-1), y)
+	}
+}
+
+//======================================================================
+
+// EnableFind sets up the app's find mode, which searches the rendered screen
+// for text, like a web browser's find-in-page. Matches are highlighted in every
+// widget; the selected match is highlighted differently. Once every match on
+// the screen has been visited, the first IFindable widget in the focus path is
+// asked to scroll to further matches.
+func (a *App) EnableFind(opts ...FindOptions) {
+	var opt FindOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	a.find = newFinder(opt)
+}
+
+// DisableFind switches off find mode and removes any highlights.
+func (a *App) DisableFind() {
+	a.find = nil
+}
+
+// StartFind opens the find prompt at the bottom of the screen. Typed characters
+// extend the query; Enter or Down selects the next match, Up the previous match,
+// and Esc closes the prompt and removes the highlights. If find mode has not been
+// enabled, it is enabled with default options.
+func (a *App) StartFind() {
+	if a.find == nil {
+		a.EnableFind()
+	}
+	a.find.prompt = true
+}
+
+// StopFind closes the find prompt and clears the query.
+func (a *App) StopFind() {
+	if a.find != nil {
+		a.find.prompt = false
+		a.find.query = ""
+		a.find.matches = nil
+		a.find.current = 0
+	}
+}
+
+// SetFindQuery sets the text to look for, and selects the first match on the
+// next frame. If find mode has not been enabled, it is enabled with default
+// options, but the prompt is not opened.
+func (a *App) SetFindQuery(query string) {
+	if a.find == nil {
+		a.EnableFind()
+	}
+	a.find.query = query
+	a.find.current = 0
+}
+
+// FindQuery returns the text being looked for.
+func (a *App) FindQuery() string {
+	if a.find == nil {
+		return ""
+	}
+	return a.find.query
+}
+
+// FindMatches returns the matches in the last rendered frame, and the index of
+// the selected match, which is -1 if there are no matches.
+func (a *App) FindMatches() ([]FindMatch, int) {
+	if a.find == nil || len(a.find.matches) == 0 {
+		return []FindMatch{}, -1
+	}
+	return a.find.matches, a.find.current
+}
+
+// FindNext selects the next match. If the last match on screen is selected, an
+// IFindable widget in the focus path is asked to scroll to the next match;
+// otherwise the selection wraps to the first match.
+func (a *App) FindNext() {
+	a.findStep(true)
+}
+
+// FindPrevious selects the previous match, in the manner of FindNext.
+func (a *App) FindPrevious() {
+	a.findStep(false)
+}
+
+func (a *App) findStep(forward bool) {
+	f := a.find
+	if f == nil || f.query == "" {
+		return
+	}
+	if forward && f.current+1 < len(f.matches) {
+		f.current++
+		a.focusFindMatch()
+		return
+	}
+	if !forward && f.current > 0 {
+		f.current--
+		a.focusFindMatch()
+		return
+	}
+	if fw := FindInHierarchy(a.viewPlusMenus, true, WidgetPredicate(func(w IWidget) bool {
+		_, ok := w.(IFindable)
+		return ok
+	})); fw != nil {
+		if fw.(IFindable).FindNext(f.spec(), forward, a) {
+			if forward {
+				f.selectAfterRender = 1
+			} else {
+				f.selectAfterRender = -1
+			}
+			return
+		}
+	}
+	if forward {
+		f.current = 0
+	} else {
+		f.current = len(f.matches) - 1
+	}
+	a.focusFindMatch()
+}
+
+// focusFindMatch moves the focus to the widget that drew the selected match, if it can take the focus.
+func (a *App) focusFindMatch() {
+	f := a.find
+	if f.current < 0 || f.current >= len(f.matches) {
+		return
+	}
+	m := f.matches[f.current]
+	stops := appendFocusStops(a.viewPlusMenus, []int{}, nil)
+	targets := make([]IWidget, 0, len(stops))
+	for _, s := range stops {
+		targets = append(targets, s.w)
+	}
+	cols, rows := a.TerminalSize()
+	if i := locateAt(a.viewPlusMenus, RenderBox{C: cols, R: rows}, Focused, a, targets, m.X, m.Y); i != -1 {
+		SetFocusPath(a.viewPlusMenus, intFocusPath(stops[i].path), a)
+	}
+}
+
+// findInput handles input for find mode. It returns true if the event was
+// consumed.
+func (a *App) findInput(ev interface{}) bool {
+	if a.find == nil {
+		return false
+	}
+	kev, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return false
+	}
+	f := a.find
+	if !f.prompt {
+		if f.opts.Key != nil && KeysEqual(kev, f.opts.Key) {
+			a.StartFind()
+			return true
+		}
+		return false
+	}
+	switch kev.Key() {
+	case tcell.KeyEscape:
+		a.StopFind()
+	case tcell.KeyEnter, tcell.KeyDown, tcell.KeyCtrlN:
+		a.FindNext()
+	case tcell.KeyUp, tcell.KeyCtrlP:
+		a.FindPrevious()
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if q := []rune(f.query); len(q) > 0 {
+			a.SetFindQuery(string(q[0 : len(q)-1]))
+		}
+	case tcell.KeyRune:
+		a.SetFindQuery(f.query + string(kev.Rune()))
+	}
+	return true
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
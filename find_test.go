@@ -0,0 +1,32 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindSpec1(t *testing.T) {
+	c := NewCanvasOfSize(6, 2)
+	for i, r := range []rune("世ab") {
+		c.SetCellAt([]int{0, 2, 3}[i], 0, MakeCell(r, ColorNone, ColorNone, StyleNone))
+	}
+	for i, r := range "xAbab" {
+		c.SetCellAt(i, 1, MakeCell(r, ColorNone, ColorNone, StyleNone))
+	}
+
+	spec := FindSpec{Query: "ab"}
+	assert.Equal(t, []FindMatch{{2, 0, 2}, {3, 1, 2}}, spec.findInSnapshot(SnapshotCanvas(c), -1))
+	assert.Equal(t, []FindMatch{{2, 0, 2}}, spec.findInSnapshot(SnapshotCanvas(c), 1))
+
+	spec = FindSpec{Query: "世a"}
+	assert.Equal(t, []FindMatch{{0, 0, 3}}, spec.findInSnapshot(SnapshotCanvas(c), -1))
+
+	spec = FindSpec{Query: "ab", IgnoreCase: true}
+	assert.Equal(t, []FindMatch{{2, 0, 2}, {1, 1, 2}, {3, 1, 2}}, spec.findInSnapshot(SnapshotCanvas(c), -1))
+	assert.True(t, spec.Matches("xABy"))
+	assert.False(t, spec.MatchesCanvas(NewCanvasOfSize(2, 2)))
+}
@@ -0,0 +1,321 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// FocusGroupOptions is used to configure a FocusGroup.
+type FocusGroupOptions struct {
+	// If true, NextFocus and PrevFocus move past the group as a whole, rather than through each of the
+	// widgets in it - e.g. for a row of radio buttons that the user moves between with the arrow keys.
+	Single bool
+	// If true, NextFocus and PrevFocus stay within the group while the focus is inside it - e.g. for a
+	// dialog.
+	Trap bool
+}
+
+// FocusGroup marks a part of the widget hierarchy that focus traversal treats specially - see
+// FocusGroupOptions. Otherwise it passes everything through to the widget it wraps.
+type FocusGroup struct {
+	IWidget
+	opts FocusGroupOptions
+}
+
+var _ ICompositeWidget = (*FocusGroup)(nil)
+
+func NewFocusGroup(w IWidget, opts ...FocusGroupOptions) *FocusGroup {
+	var opt FocusGroupOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return &FocusGroup{
+		IWidget: w,
+		opts:    opt,
+	}
+}
+
+func (w *FocusGroup) String() string {
+	return fmt.Sprintf("focusgroup[%v]", w.IWidget)
+}
+
+func (w *FocusGroup) Options() FocusGroupOptions {
+	return w.opts
+}
+
+func (w *FocusGroup) SubWidget() IWidget {
+	return w.IWidget
+}
+
+func (w *FocusGroup) SetSubWidget(inner IWidget, app IApp) {
+	w.IWidget = inner
+}
+
+func (w *FocusGroup) SubWidgetSize(size IRenderSize, focus Selector, app IApp) IRenderSize {
+	return size
+}
+
+//======================================================================
+
+// focusChildren returns the children of w that focus can move between, if there are any.
+func focusChildren(w IWidget) ([]IWidget, bool) {
+	if _, ok := w.(IComposite); ok {
+		return nil, false
+	}
+	if cw, ok := w.(ICompositeMultipleFocus); ok {
+		return cw.SubWidgets(), true
+	}
+	return nil, false
+}
+
+// focusStop is a place in the hierarchy that can take the focus - its focus path, and the widget there.
+type focusStop struct {
+	path []int
+	w    IWidget
+}
+
+func appendFocusStops(w IWidget, path []int, res []focusStop) []focusStop {
+	stop := w
+	for {
+		if g, ok := w.(*FocusGroup); ok && g.opts.Single {
+			break
+		}
+		if children, ok := focusChildren(w); ok {
+			for i, child := range children {
+				if child.Selectable() {
+					res = appendFocusStops(child, append(path[:len(path):len(path)], i), res)
+				}
+			}
+			return res
+		}
+		if _, ok := w.(IFocus); ok {
+			// Its children can't be enumerated, so it's a stop of its own
+			break
+		}
+		if cw, ok := w.(IComposite); ok {
+			w = cw.SubWidget()
+			continue
+		}
+		break
+	}
+	if stop.Selectable() {
+		res = append(res, focusStop{path: path, w: stop})
+	}
+	return res
+}
+
+// FocusStops returns every place in the hierarchy under w that can take the focus, in the order that
+// NextFocus visits them. Each is a focus path, as used by SetFocusPath. Containers that are an
+// ICompositeMultiple and IFocus, like pile and columns, are entered; other selectable widgets, like
+// lists and edits, are a single stop.
+func FocusStops(w IWidget) [][]interface{} {
+	stops := appendFocusStops(w, []int{}, nil)
+	res := make([][]interface{}, 0, len(stops))
+	for _, s := range stops {
+		res = append(res, intFocusPath(s.path))
+	}
+	return res
+}
+
+func intFocusPath(path []int) []interface{} {
+	res := make([]interface{}, 0, len(path))
+	for _, i := range path {
+		res = append(res, i)
+	}
+	return res
+}
+
+// currentFocusStop returns the index of the stop in stops that has the focus, or -1.
+func currentFocusStop(w IWidget, stops []focusStop) int {
+	cur := FocusPath(w)
+Loop:
+	for i, s := range stops {
+		if len(s.path) > len(cur) {
+			continue
+		}
+		for j, p := range s.path {
+			if cur[j] != p {
+				continue Loop
+			}
+		}
+		return i
+	}
+	return -1
+}
+
+// focusScope returns the innermost FocusGroup that traps the focus and contains it, or else w.
+func focusScope(w IWidget) IWidget {
+	res := w
+	for cur := w; cur != nil; {
+		if g, ok := cur.(*FocusGroup); ok && g.opts.Trap {
+			res = g
+		}
+		cur = FindInHierarchy(cur, false, WidgetPredicate(func(w IWidget) bool {
+			return true
+		}))
+	}
+	return res
+}
+
+func moveFocus(w IWidget, dir Direction, wrap bool, app IApp) bool {
+	w = focusScope(w)
+	stops := appendFocusStops(w, []int{}, nil)
+	if len(stops) == 0 {
+		return false
+	}
+	cur := currentFocusStop(w, stops)
+	next := cur + int(dir)
+	switch {
+	case cur == -1 && dir == Forwards:
+		next = 0
+	case cur == -1:
+		next = len(stops) - 1
+	case next < 0 || next >= len(stops):
+		if !wrap {
+			return false
+		}
+		next = (next + len(stops)) % len(stops)
+	}
+	if next == cur {
+		return false
+	}
+	return SetFocusPath(w, intFocusPath(stops[next].path), app).Succeeded
+}
+
+// NextFocus moves the focus to the next widget in the hierarchy under w that can take it, across
+// containers at any depth - unlike pile and columns, which only move the focus between their own
+// children. If the focus is inside a FocusGroup that traps it, the focus stays in the group. It returns
+// false if the focus didn't move - because it was on the last widget and wrap is false, or there's
+// nowhere else to go.
+func NextFocus(w IWidget, app IApp, wrap bool) bool {
+	return moveFocus(w, Forwards, wrap, app)
+}
+
+// PrevFocus moves the focus to the previous widget in the hierarchy under w that can take it - see
+// NextFocus.
+func PrevFocus(w IWidget, app IApp, wrap bool) bool {
+	return moveFocus(w, Backwards, wrap, app)
+}
+
+// FindFocusPath returns the focus path from w to target, for SetFocusPath, if target is in the hierarchy
+// under w and can be reached by setting the focus of containers that are an ICompositeMultiple and
+// IFocus.
+func FindFocusPath(w IWidget, target IWidget) ([]interface{}, bool) {
+	if path, ok := findFocusPath(w, target, []int{}); ok {
+		return intFocusPath(path), true
+	}
+	return nil, false
+}
+
+func findFocusPath(w IWidget, target IWidget, path []int) ([]int, bool) {
+	for {
+		if w == target {
+			return path, true
+		}
+		if children, ok := focusChildren(w); ok {
+			for i, child := range children {
+				if res, ok := findFocusPath(child, target, append(path[:len(path):len(path)], i)); ok {
+					return res, true
+				}
+			}
+			return nil, false
+		}
+		if _, ok := w.(IFocus); ok {
+			return nil, false
+		}
+		if cw, ok := w.(IComposite); ok {
+			w = cw.SubWidget()
+			continue
+		}
+		return nil, false
+	}
+}
+
+// FocusOn moves the focus to target, which must be in the hierarchy under w - see FindFocusPath. It
+// returns false if target can't be reached.
+func FocusOn(w IWidget, target IWidget, app IApp) bool {
+	path, ok := FindFocusPath(w, target)
+	if !ok {
+		return false
+	}
+	return SetFocusPath(w, path, app).Succeeded
+}
+
+//======================================================================
+
+// FocusTraversalOptions is used to configure focus traversal - see EnableFocusTraversal.
+type FocusTraversalOptions struct {
+	Next   IKey // Moves the focus to the next widget; defaults to Tab
+	Prev   IKey // Moves the focus to the previous widget; defaults to Shift-Tab
+	NoWrap bool // If true, the focus doesn't wrap from the last widget to the first, or vice versa
+}
+
+type focusTraverser struct {
+	opts FocusTraversalOptions
+}
+
+// EnableFocusTraversal makes Tab and Shift-Tab, or the keys configured, move the focus through the whole
+// widget hierarchy with NextFocus and PrevFocus. The keys are only used for this if no widget handles
+// them first.
+func (a *App) EnableFocusTraversal(opts ...FocusTraversalOptions) {
+	var opt FocusTraversalOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Next == nil {
+		opt.Next = MakeKeyExt(tcell.KeyTab)
+	}
+	if opt.Prev == nil {
+		opt.Prev = MakeKeyExt(tcell.KeyBacktab)
+	}
+	a.focusKeys = &focusTraverser{opts: opt}
+}
+
+func (a *App) DisableFocusTraversal() {
+	a.focusKeys = nil
+}
+
+// NextFocus moves the focus to the next widget in the app that can take it - see the package function
+// NextFocus.
+func (a *App) NextFocus(wrap bool) bool {
+	return NextFocus(a.viewPlusMenus, a, wrap)
+}
+
+// PrevFocus moves the focus to the previous widget in the app that can take it.
+func (a *App) PrevFocus(wrap bool) bool {
+	return PrevFocus(a.viewPlusMenus, a, wrap)
+}
+
+// focusTraversalInput returns true if ev was a key configured to move the focus.
+func (a *App) focusTraversalInput(ev interface{}) bool {
+	if a.focusKeys == nil {
+		return false
+	}
+	opts := a.focusKeys.opts
+	kev, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return false
+	}
+	switch {
+	case KeysEqual(kev, opts.Next):
+		a.NextFocus(!opts.NoWrap)
+		return true
+	case KeysEqual(kev, opts.Prev):
+		a.PrevFocus(!opts.NoWrap)
+		return true
+	}
+	return false
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,229 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"time"
+)
+
+//======================================================================
+
+// frameScheduler coalesces requests to redraw the screen so that the app
+// renders at most once per interval. A nil *frameScheduler means every
+// request is drawn immediately.
+type frameScheduler struct {
+	base      time.Duration   // The interval set by SetMaxFPS, or 0 if there is no limit
+	interval  time.Duration   // The current interval - longer than base if the terminal is slow
+	adaptive  *adaptiveRedraw // If not nil, the interval adapts to the time taken to write frames
+	dirty     bool            // A redraw has been requested since the last frame
+	last      time.Time       // When the last frame was drawn
+	timer     ITimer          // If not nil, fires when the next frame is due
+	requested int
+	drawn     int
+}
+
+func newFrameScheduler(fps int) *frameScheduler {
+	res := &frameScheduler{}
+	if fps > 0 {
+		res.base = time.Second / time.Duration(fps)
+	}
+	res.interval = res.base
+	return res
+}
+
+// AdaptiveRedrawOptions is used to configure adaptive redraw - see
+// EnableAdaptiveRedraw.
+type AdaptiveRedrawOptions struct {
+	SlowWrite   time.Duration // Frames taking longer than this to write, on average, slow the frame rate; defaults to 30ms
+	MaxInterval time.Duration // The longest time between frames; defaults to 1s
+}
+
+// adaptiveIdleFactor is the ratio of the time between frames to the time
+// taken to write one, when the terminal is slow. The link is left idle most of
+// the time, so that keys the user types are echoed promptly.
+const adaptiveIdleFactor = 4
+
+type adaptiveRedraw struct {
+	opts AdaptiveRedrawOptions
+	avg  time.Duration // Smoothed time taken to write a frame
+}
+
+// frameWritten adjusts the interval between frames according to the time
+// taken to write the last frame to the terminal.
+func (f *frameScheduler) frameWritten(d time.Duration) {
+	ad := f.adaptive
+	if ad == nil {
+		return
+	}
+	if ad.avg == 0 {
+		ad.avg = d
+	} else {
+		ad.avg = (ad.avg*7 + d*3) / 10
+	}
+	f.interval = f.base
+	if ad.avg > ad.opts.SlowWrite {
+		iv := ad.avg * adaptiveIdleFactor
+		if iv > ad.opts.MaxInterval {
+			iv = ad.opts.MaxInterval
+		}
+		if iv > f.interval {
+			f.interval = iv
+		}
+	}
+}
+
+// slow returns true if adaptive redraw is on and frames are taking longer than SlowWrite to write, on
+// average.
+func (f *frameScheduler) slow() bool {
+	return f != nil && f.adaptive != nil && f.adaptive.avg > f.adaptive.opts.SlowWrite
+}
+
+// due returns a channel that is readable when a deferred frame should be
+// drawn. If no frame is pending, the channel is nil, so blocks forever.
+func (f *frameScheduler) due() <-chan time.Time {
+	if f == nil || f.timer == nil {
+		return nil
+	}
+	return f.timer.Chan()
+}
+
+//======================================================================
+
+// SetMaxFPS limits the rate at which the app redraws the terminal. Redraws
+// requested by input events, or by functions passed to Run(), are deferred
+// until at least 1/fps seconds have passed since the previous frame; several
+// requests in that time result in a single redraw. This stops apps that
+// receive high-rate background updates from spending all their time
+// rendering. An fps of 0 or less removes the limit, so that the terminal is
+// redrawn after every event - the default.
+func (a *App) SetMaxFPS(fps int) {
+	var adaptive *adaptiveRedraw
+	if a.frames != nil {
+		adaptive = a.frames.adaptive
+	}
+	a.setFrameScheduler(fps, adaptive)
+}
+
+func (a *App) setFrameScheduler(fps int, adaptive *adaptiveRedraw) {
+	if a.frames != nil && a.frames.timer != nil {
+		a.frames.timer.Stop()
+	}
+	if fps <= 0 && adaptive == nil {
+		if a.frames != nil && a.frames.dirty {
+			a.RedrawTerminal()
+		}
+		a.frames = nil
+	} else {
+		a.frames = newFrameScheduler(fps)
+		a.frames.adaptive = adaptive
+	}
+}
+
+// MaxFPS returns the limit on the rate of redraws, or 0 if there is none.
+func (a *App) MaxFPS() int {
+	if a.frames == nil || a.frames.base == 0 {
+		return 0
+	}
+	return int(time.Second / a.frames.base)
+}
+
+// EnableAdaptiveRedraw makes the app redraw less often when the terminal is
+// slow to accept output, e.g. over a congested SSH connection or a serial
+// line. The time taken to write each frame is measured; while it's more than
+// SlowWrite, on average, the time between frames is stretched to several times
+// the time taken to write one, up to MaxInterval. Redraws requested in between
+// are coalesced, as they are by SetMaxFPS, so intermediate frames are dropped
+// and the terminal only receives the latest state - and since only changed
+// cells are written, the link is kept free for the user's keystrokes and
+// their echo. While writes are slow, each frame is also sent in two parts: first
+// the region of the widget in focus, then the rest of the screen, so what the
+// user is working with is updated before, say, a busy status bar. Finding that
+// region means rendering the widgets a second time, which costs little next
+// to a slow write. When writes speed up again, so does the frame rate, back to
+// any limit set by SetMaxFPS.
+func (a *App) EnableAdaptiveRedraw(opts ...AdaptiveRedrawOptions) {
+	var opt AdaptiveRedrawOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.SlowWrite <= 0 {
+		opt.SlowWrite = 30 * time.Millisecond
+	}
+	if opt.MaxInterval <= 0 {
+		opt.MaxInterval = time.Second
+	}
+	a.setFrameScheduler(a.MaxFPS(), &adaptiveRedraw{opts: opt})
+}
+
+// DisableAdaptiveRedraw stops the frame rate adapting to the speed of the
+// terminal.
+func (a *App) DisableAdaptiveRedraw() {
+	a.setFrameScheduler(a.MaxFPS(), nil)
+}
+
+// FrameInterval returns the minimum time between frames - set by SetMaxFPS,
+// and stretched by adaptive redraw if the terminal is slow. It is 0 if every
+// redraw request is drawn immediately.
+func (a *App) FrameInterval() time.Duration {
+	if a.frames == nil {
+		return 0
+	}
+	return a.frames.interval
+}
+
+// FrameStats returns the number of redraws requested, and the number of
+// frames drawn, since SetMaxFPS or EnableAdaptiveRedraw was called. Both are 0
+// if there is no limit.
+func (a *App) FrameStats() (requested int, drawn int) {
+	if a.frames == nil {
+		return 0, 0
+	}
+	return a.frames.requested, a.frames.drawn
+}
+
+// redraw is called after an event has been processed. The terminal is
+// redrawn now if there is no frame rate limit; otherwise the redraw is left
+// to RedrawIfDue.
+func (a *App) redraw() {
+	if a.frames == nil {
+		a.RedrawTerminal()
+	} else {
+		a.frames.dirty = true
+		a.frames.requested++
+	}
+}
+
+// RedrawIfDue draws a frame if a redraw has been requested and the frame
+// rate limit allows it; if the frame is not yet due, a timer is started to
+// signal when it is. The main loop calls this after processing each event -
+// apps that run their own loop with a frame rate limit should do the same.
+func (a *App) RedrawIfDue() {
+	f := a.frames
+	if f == nil || !f.dirty {
+		return
+	}
+	clock := a.Clock()
+	now := clock.Now()
+	if elapsed := now.Sub(f.last); !f.last.IsZero() && elapsed < f.interval {
+		if f.timer == nil {
+			f.timer = clock.NewTimer(f.interval - elapsed)
+		}
+		return
+	}
+	if f.timer != nil {
+		f.timer.Stop()
+		f.timer = nil
+	}
+	a.RedrawTerminal()
+	f.last = now
+	f.dirty = false
+	f.drawn++
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,54 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestAdaptiveRedraw1(t *testing.T) {
+	f := newFrameScheduler(20)
+	assert.Equal(t, 50*time.Millisecond, f.interval)
+
+	// Without adaptive redraw, the time taken to write is ignored
+	f.frameWritten(time.Second)
+	assert.Equal(t, 50*time.Millisecond, f.interval)
+
+	f.adaptive = &adaptiveRedraw{opts: AdaptiveRedrawOptions{SlowWrite: 30 * time.Millisecond, MaxInterval: time.Second}}
+	f.frameWritten(10 * time.Millisecond)
+	assert.Equal(t, 50*time.Millisecond, f.interval)
+
+	// Slow writes stretch the interval
+	f.frameWritten(100 * time.Millisecond)
+	assert.Equal(t, 37*time.Millisecond, f.adaptive.avg)
+	assert.Equal(t, 148*time.Millisecond, f.interval)
+
+	for i := 0; i < 20; i++ {
+		f.frameWritten(500 * time.Millisecond)
+	}
+	assert.Equal(t, time.Second, f.interval)
+
+	// And fast writes bring it back to the limit
+	for i := 0; i < 20; i++ {
+		f.frameWritten(time.Millisecond)
+	}
+	assert.Equal(t, 50*time.Millisecond, f.interval)
+
+	f = newFrameScheduler(0)
+	assert.Equal(t, time.Duration(0), f.interval)
+	f.adaptive = &adaptiveRedraw{opts: AdaptiveRedrawOptions{SlowWrite: 30 * time.Millisecond, MaxInterval: time.Second}}
+	f.frameWritten(100 * time.Millisecond)
+	assert.Equal(t, 400*time.Millisecond, f.interval)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,119 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"context"
+	"fmt"
+)
+
+//======================================================================
+
+// FutureFunction is run on the app's widget goroutine by RunFuture and RunSync.
+// Its results are passed back to the calling goroutine.
+type FutureFunction func(app IApp) (interface{}, error)
+
+// FuturePanicError is returned from a Future if its function panicked.
+type FuturePanicError struct {
+	Value interface{}
+}
+
+var _ error = FuturePanicError{}
+
+func (e FuturePanicError) Error() string {
+	return fmt.Sprintf("Function run on the widget goroutine panicked: %v", e.Value)
+}
+
+// Future holds the eventual result of a function sent to the widget
+// goroutine with RunFuture.
+type Future struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+func newFuture() *Future {
+	return &Future{
+		done: make(chan struct{}),
+	}
+}
+
+func (f *Future) complete(val interface{}, err error) {
+	f.val, f.err = val, err
+	close(f.done)
+}
+
+// Done returns a channel that is closed once the result is available.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the function has run, then returns its results. Don't call
+// Wait from the widget goroutine - e.g. from a widget's UserInput - because the
+// function can't run until that goroutine is free.
+func (f *Future) Wait() (interface{}, error) {
+	<-f.done
+	return f.val, f.err
+}
+
+// WaitContext is like Wait, but gives up when ctx is done, returning ctx's error.
+// The function may still run later.
+func (f *Future) WaitContext(ctx context.Context) (interface{}, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Result returns the results, and true, if the function has run; otherwise it
+// returns false without blocking.
+func (f *Future) Result() (interface{}, error, bool) {
+	select {
+	case <-f.done:
+		return f.val, f.err, true
+	default:
+		return nil, nil, false
+	}
+}
+
+//======================================================================
+
+// RunFuture is like Run, but returns a Future from which another goroutine can
+// collect f's results once it has run on the widget goroutine. If f panics, the
+// panic is recovered and returned from the Future as a FuturePanicError. If the
+// app is closing, the Future's error is AppClosingErr.
+func (a *App) RunFuture(f FutureFunction) *Future {
+	res := newFuture()
+	err := a.Run(RunFunction(func(app IApp) {
+		var val interface{}
+		var err error
+		defer func() {
+			if r := recover(); r != nil {
+				err = FuturePanicError{Value: r}
+			}
+			res.complete(val, err)
+		}()
+		val, err = f(app)
+	}))
+	if err != nil {
+		res.complete(nil, err)
+	}
+	return res
+}
+
+// RunSync runs f on the widget goroutine, waits for it to finish, and returns
+// its results. It must not be called from the widget goroutine itself, or it
+// will never return.
+func (a *App) RunSync(f FutureFunction) (interface{}, error) {
+	return a.RunFuture(f).Wait()
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
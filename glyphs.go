@@ -0,0 +1,70 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"unicode/utf8"
+)
+
+//======================================================================
+
+// IASCIIOnly is implemented by an app that can be told to draw with ASCII only, like App.
+type IASCIIOnly interface {
+	ASCIIOnly() bool
+}
+
+var _ IASCIIOnly = (*App)(nil)
+
+// ASCIIOnly returns true if widgets drawn in app should draw their decorations with ASCII characters
+// only. It is false if app is nil, or can't be told to draw with ASCII only.
+func ASCIIOnly(app IApp) bool {
+	if a, ok := app.(IASCIIOnly); ok {
+		return a.ASCIIOnly()
+	}
+	return false
+}
+
+// ASCIIRune returns r, unless widgets drawn in app should draw with ASCII only and r isn't ASCII, in which
+// case it returns fallback. Widgets use it to choose the glyphs they decorate with.
+func ASCIIRune(app IApp, r rune, fallback rune) rune {
+	if r >= utf8.RuneSelf && ASCIIOnly(app) {
+		return fallback
+	}
+	return r
+}
+
+// ASCIIString returns s, unless widgets drawn in app should draw with ASCII only and s isn't all ASCII,
+// in which case it returns fallback.
+func ASCIIString(app IApp, s string, fallback string) string {
+	if !ASCIIOnly(app) {
+		return s
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return fallback
+		}
+	}
+	return s
+}
+
+// SetASCIIOnly makes the built-in widgets of this app draw their frames, lines, scrollbars, bars and other
+// decorations with ASCII characters only, for a terminal or locale that shows Unicode box-drawing and
+// block characters as garbage. Text given to widgets by the app is drawn as it is. Other apps in the
+// process - e.g. those served to other remote terminals - are unaffected. AppArgs.ASCIIOnly sets it when
+// the app is made.
+func (a *App) SetASCIIOnly(on bool) {
+	a.asciiOnly = on
+}
+
+// ASCIIOnly returns true if widgets should draw with ASCII only in this app - see SetASCIIOnly.
+func (a *App) ASCIIOnly() bool {
+	return a.asciiOnly
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,34 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// asciiApp is an app that can be told to draw with ASCII only.
+type asciiApp struct {
+	IApp
+	ascii bool
+}
+
+func (a asciiApp) ASCIIOnly() bool {
+	return a.ascii
+}
+
+func TestASCIIOnly1(t *testing.T) {
+	assert.Equal(t, '─', ASCIIRune(nil, '─', '-'))
+	assert.Equal(t, "Next ▸", ASCIIString(nil, "Next ▸", "Next >"))
+
+	app := asciiApp{ascii: true}
+	assert.False(t, ASCIIOnly(nil))
+	assert.False(t, ASCIIOnly(asciiApp{}))
+	assert.True(t, ASCIIOnly(app))
+	assert.Equal(t, '-', ASCIIRune(app, '─', '-'))
+	assert.Equal(t, 'x', ASCIIRune(app, 'x', '-'))
+	assert.Equal(t, "Next >", ASCIIString(app, "Next ▸", "Next >"))
+	assert.Equal(t, "Skip", ASCIIString(app, "Skip", "?"))
+}
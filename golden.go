@@ -0,0 +1,371 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+)
+
+//======================================================================
+
+// The annotated format of a snapshot, used for golden files, is a header line, then for each row a line
+// of text followed by a line giving the style of each column, then a legend of the styles:
+//
+//    gowid snapshot 6x1 cursor 2,0
+//    |Quit! |
+//    |aaaab |
+//    a 9/default bold
+//    b default/default
+//
+// Rows are wrapped in | so trailing spaces survive editors. A style is the foreground and background
+// colors - default, a number from the terminal's palette, or #rrggbb - then any attributes. The cursor
+// is only given if it is shown.
+
+const annotatedHeader = "gowid snapshot"
+
+var styleKeys = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+
+func styleKey(i int) rune {
+	if i < len(styleKeys) {
+		return styleKeys[i]
+	}
+	return rune(0x100 + i)
+}
+
+var styleAttrs = []struct {
+	name string
+	attr tcell.AttrMask
+	set  func(tcell.Style, bool) tcell.Style
+}{
+	{"bold", tcell.AttrBold, tcell.Style.Bold},
+	{"dim", tcell.AttrDim, tcell.Style.Dim},
+	{"underline", tcell.AttrUnderline, tcell.Style.Underline},
+	{"blink", tcell.AttrBlink, tcell.Style.Blink},
+	{"reverse", tcell.AttrReverse, tcell.Style.Reverse},
+}
+
+func describeColor(c tcell.Color) string {
+	switch {
+	case c == tcell.ColorDefault:
+		return "default"
+	case c&tcell.ColorIsRGB != 0:
+		return fmt.Sprintf("#%06x", c.Hex())
+	default:
+		return fmt.Sprintf("%d", int(c))
+	}
+}
+
+func parseColor(s string) (tcell.Color, error) {
+	switch {
+	case s == "default":
+		return tcell.ColorDefault, nil
+	case strings.HasPrefix(s, "#"):
+		v, err := strconv.ParseInt(s[1:], 16, 32)
+		if err != nil {
+			return 0, err
+		}
+		return tcell.NewHexColor(int32(v)), nil
+	default:
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, err
+		}
+		return tcell.Color(v), nil
+	}
+}
+
+// DescribeStyle returns a style as it appears in the annotated format of a snapshot, e.g. "9/default bold".
+func DescribeStyle(st tcell.Style) string {
+	fg, bg, attrs := st.Decompose()
+	res := []string{describeColor(fg) + "/" + describeColor(bg)}
+	for _, a := range styleAttrs {
+		if attrs&a.attr != 0 {
+			res = append(res, a.name)
+		}
+	}
+	return strings.Join(res, " ")
+}
+
+func parseStyle(s string) (tcell.Style, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return tcell.StyleDefault, errors.New("missing colors")
+	}
+	colors := strings.Split(fields[0], "/")
+	if len(colors) != 2 {
+		return tcell.StyleDefault, errors.Errorf("expected foreground/background, not %q", fields[0])
+	}
+	fg, err := parseColor(colors[0])
+	if err != nil {
+		return tcell.StyleDefault, errors.WithMessagef(err, "bad foreground %q", colors[0])
+	}
+	bg, err := parseColor(colors[1])
+	if err != nil {
+		return tcell.StyleDefault, errors.WithMessagef(err, "bad background %q", colors[1])
+	}
+	res := tcell.StyleDefault.Foreground(fg).Background(bg)
+Attrs:
+	for _, name := range fields[1:] {
+		for _, a := range styleAttrs {
+			if a.name == name {
+				res = a.set(res, true)
+				continue Attrs
+			}
+		}
+		return tcell.StyleDefault, errors.Errorf("unknown attribute %q", name)
+	}
+	return res, nil
+}
+
+// sameStyle compares styles by what they look like.
+func sameStyle(a, b tcell.Style) bool {
+	fa, ba, aa := a.Decompose()
+	fb, bb, ab := b.Decompose()
+	return fa == fb && ba == bb && aa == ab
+}
+
+//======================================================================
+
+// Annotated returns the snapshot as text annotated with the style of every cell, in a format meant to be
+// checked in as a golden file and reviewed in a diff - see ParseSnapshot.
+func (s *Snapshot) Annotated() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %dx%d", annotatedHeader, s.Cols, s.Rows)
+	if s.Cursor.X >= 0 && s.Cursor.Y >= 0 {
+		fmt.Fprintf(&sb, " cursor %d,%d", s.Cursor.X, s.Cursor.Y)
+	}
+	sb.WriteString("\n")
+
+	styles := []tcell.Style{}
+	keyOf := func(st tcell.Style) rune {
+		for i, other := range styles {
+			if sameStyle(st, other) {
+				return styleKey(i)
+			}
+		}
+		styles = append(styles, st)
+		return styleKey(len(styles) - 1)
+	}
+	text := strings.Split(s.Text(), "\n")
+	for y := 0; y < s.Rows; y++ {
+		sb.WriteString("|" + text[y] + "|\n|")
+		for _, c := range s.Cells[y] {
+			sb.WriteRune(keyOf(c.Style))
+		}
+		sb.WriteString("|\n")
+	}
+	for i, st := range styles {
+		fmt.Fprintf(&sb, "%c %s\n", styleKey(i), DescribeStyle(st))
+	}
+	return sb.String()
+}
+
+// ParseSnapshot reads a snapshot in the format returned by Annotated.
+func ParseSnapshot(text string) (*Snapshot, error) {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	var cols, rows int
+	cursor := CanvasPos{X: -1, Y: -1}
+	header := strings.TrimPrefix(lines[0], annotatedHeader)
+	if header == lines[0] {
+		return nil, errors.Errorf("expected %q at the start", annotatedHeader)
+	}
+	if _, err := fmt.Sscanf(header, " %dx%d cursor %d,%d", &cols, &rows, &cursor.X, &cursor.Y); err != nil {
+		if _, err := fmt.Sscanf(header, " %dx%d", &cols, &rows); err != nil {
+			return nil, errors.WithMessagef(err, "bad header %q", lines[0])
+		}
+	}
+	if len(lines) < 1+2*rows {
+		return nil, errors.Errorf("expected %d rows, found %d", rows, (len(lines)-1)/2)
+	}
+	row := func(i int) ([]rune, error) {
+		line := lines[i]
+		if len(line) < 2 || line[0] != '|' || line[len(line)-1] != '|' {
+			return nil, errors.Errorf("line %d is not wrapped in |", i+1)
+		}
+		return []rune(line[1 : len(line)-1]), nil
+	}
+
+	legend := map[rune]tcell.Style{}
+	for i := 1 + 2*rows; i < len(lines); i++ {
+		r := []rune(lines[i])
+		if len(r) < 3 || r[1] != ' ' {
+			return nil, errors.Errorf("line %d is not a style", i+1)
+		}
+		st, err := parseStyle(string(r[2:]))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "line %d", i+1)
+		}
+		legend[r[0]] = st
+	}
+
+	res := newSnapshot(cols, rows)
+	res.Cursor = cursor
+	for y := 0; y < rows; y++ {
+		text, err := row(1 + 2*y)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := row(2 + 2*y)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) != cols {
+			return nil, errors.Errorf("line %d gives styles for %d columns, not %d", 3+2*y, len(keys), cols)
+		}
+		widths := GraphemeWidths(text)
+		starts := GraphemeStarts(text)
+		x := 0
+		for i := 0; i < len(text); {
+			j := i + 1
+			for j < len(text) && !starts[j] {
+				j++
+			}
+			if x >= cols {
+				return nil, errors.Errorf("line %d is wider than %d columns", 2+2*y, cols)
+			}
+			x += res.set(x, y, SnapshotCell{Rune: text[i], Combining: string(text[i+1 : j])}, widths[i])
+			i = j
+		}
+		if x != cols {
+			return nil, errors.Errorf("line %d is %d columns wide, not %d", 2+2*y, x, cols)
+		}
+		for x, k := range keys {
+			st, ok := legend[k]
+			if !ok {
+				return nil, errors.Errorf("line %d uses style %q, which is not in the legend", 3+2*y, k)
+			}
+			res.Cells[y][x].Style = st
+		}
+	}
+	return res, nil
+}
+
+// Equal returns true if the snapshots look the same - the same size, with the same cursor, and the same
+// characters in the same styles.
+func (s *Snapshot) Equal(other *Snapshot) bool {
+	return DiffSnapshots(s, other, false) == ""
+}
+
+//======================================================================
+
+const (
+	diffRed    = "\x1b[0;1;31m"
+	diffGreen  = "\x1b[0;1;32m"
+	diffYellow = "\x1b[0;1;33m"
+	diffReset  = "\x1b[0m"
+)
+
+type styleDiff struct {
+	first, last int
+	desc        string
+}
+
+// DiffSnapshots returns a description of the differences between the snapshot expected and the one
+// got, or "" if there are none. Each row that differs is shown as expected and as got, with a line
+// beneath marking the columns whose characters differ with ^, and those that only differ in style with
+// ~, followed by the styles that differ. If color is true, the rows are drawn in their own styles, and
+// the markers are colored, using ANSI escape sequences - e.g. for CI output.
+func DiffSnapshots(want, got *Snapshot, color bool) string {
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + diffReset
+	}
+	drawRow := func(s *Snapshot, y, cols int) string {
+		if y >= s.Rows {
+			return "(missing)"
+		}
+		var sb strings.Builder
+		sb.WriteString("|")
+		for x := 0; x < cols; x++ {
+			c := SnapshotCell{Rune: ' '}
+			if x < s.Cols {
+				c = s.Cells[y][x]
+			}
+			if c.Rune == 0 {
+				continue
+			}
+			if color {
+				sb.WriteString(ansiStyle(c.Style))
+			}
+			sb.WriteRune(c.Rune)
+			sb.WriteString(c.Combining)
+		}
+		if color {
+			sb.WriteString(diffReset)
+		}
+		sb.WriteString("|")
+		return sb.String()
+	}
+
+	var sb strings.Builder
+	if want.Cols != got.Cols || want.Rows != got.Rows {
+		fmt.Fprintf(&sb, "size: expected %dx%d, got %dx%d\n", want.Cols, want.Rows, got.Cols, got.Rows)
+	}
+	if want.Cursor != got.Cursor {
+		fmt.Fprintf(&sb, "cursor: expected %v, got %v\n", want.Cursor, got.Cursor)
+	}
+	cols, rows := gwutil.Max(want.Cols, got.Cols), gwutil.Max(want.Rows, got.Rows)
+	cell := func(s *Snapshot, x, y int) (SnapshotCell, bool) {
+		if x >= s.Cols || y >= s.Rows {
+			return SnapshotCell{}, false
+		}
+		return s.Cells[y][x], true
+	}
+	for y := 0; y < rows; y++ {
+		var marks strings.Builder
+		styles := []styleDiff{}
+		differs := false
+		for x := 0; x < cols; x++ {
+			w, wok := cell(want, x, y)
+			g, gok := cell(got, x, y)
+			switch {
+			case !wok || !gok || w.Rune != g.Rune || w.Combining != g.Combining:
+				marks.WriteString(paint(diffRed, "^"))
+				differs = true
+			case !sameStyle(w.Style, g.Style):
+				marks.WriteString(paint(diffYellow, "~"))
+				// Runs of columns with the same difference are described together
+				st := fmt.Sprintf("expected %s, got %s", DescribeStyle(w.Style), DescribeStyle(g.Style))
+				if n := len(styles); n > 0 && styles[n-1].desc == st && styles[n-1].last == x-1 {
+					styles[n-1].last = x
+				} else {
+					styles = append(styles, styleDiff{first: x, last: x, desc: st})
+				}
+				differs = true
+			default:
+				marks.WriteString(" ")
+			}
+		}
+		if !differs {
+			continue
+		}
+		fmt.Fprintf(&sb, "row %d:\n", y)
+		fmt.Fprintf(&sb, "  %s %s\n", paint(diffRed, "expected"), drawRow(want, y, cols))
+		fmt.Fprintf(&sb, "  %s      %s\n", paint(diffGreen, "got"), drawRow(got, y, cols))
+		fmt.Fprintf(&sb, "            %s\n", strings.TrimRight(marks.String(), " "))
+		for _, st := range styles {
+			if st.first == st.last {
+				fmt.Fprintf(&sb, "  column %d: %s\n", st.first, st.desc)
+			} else {
+				fmt.Fprintf(&sb, "  columns %d-%d: %s\n", st.first, st.last, st.desc)
+			}
+		}
+	}
+	return sb.String()
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
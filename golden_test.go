@@ -0,0 +1,87 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotated1(t *testing.T) {
+	c := NewCanvasOfSize(4, 2)
+	red := MakeCell('a', MakeTCellColorExt(tcell.ColorRed), ColorNone, StyleBold)
+	c.SetCellAt(0, 0, red)
+	c.SetCellAt(1, 0, red.WithRune('|'))
+	c.SetCellAt(0, 1, MakeCell('世', ColorNone, MakeTCellColorExt(tcell.NewHexColor(0x102030)), StyleNone))
+
+	s := SnapshotCanvas(c)
+	expected := "gowid snapshot 4x2\n" +
+		"|a|  |\n" +
+		"|aabb|\n" +
+		"|世  |\n" +
+		"|ccbb|\n" +
+		"a 9/default bold\n" +
+		"b default/default\n" +
+		"c default/#102030\n"
+	assert.Equal(t, expected, s.Annotated())
+
+	s2, err := ParseSnapshot(expected)
+	assert.NoError(t, err)
+	assert.True(t, s.Equal(s2))
+	assert.Equal(t, rune(0), s2.Cells[1][1].Rune)
+	assert.Equal(t, expected, s2.Annotated())
+
+	s2.Cursor = CanvasPos{X: 1, Y: 1}
+	s3, err := ParseSnapshot(s2.Annotated())
+	assert.NoError(t, err)
+	assert.Equal(t, CanvasPos{X: 1, Y: 1}, s3.Cursor)
+	assert.False(t, s.Equal(s3))
+
+	for _, bad := range []string{
+		"snapshot 1x1\n| |\n|a|\na default/default\n",
+		"gowid snapshot 2x1\n| |\n|aa|\na default/default\n",
+		"gowid snapshot 1x1\n| |\n|b|\na default/default\n",
+		"gowid snapshot 1x1\n| |\n|a|\na default/default italic\n",
+	} {
+		_, err := ParseSnapshot(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestDiffSnapshots1(t *testing.T) {
+	want, err := ParseSnapshot("gowid snapshot 5x2\n" +
+		"|hello|\n|aaaaa|\n" +
+		"|world|\n|bbbbb|\n" +
+		"a default/default\nb 1/default\n")
+	assert.NoError(t, err)
+	got, err := ParseSnapshot("gowid snapshot 5x2\n" +
+		"|hello|\n|aaaaa|\n" +
+		"|wOrld|\n|bbccb|\n" +
+		"a default/default\nb 1/default\nc 1/default underline\n")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "", DiffSnapshots(want, want, false))
+	assert.Equal(t, "row 1:\n"+
+		"  expected |world|\n"+
+		"  got      |wOrld|\n"+
+		"             ^~~\n"+
+		"  columns 2-3: expected 1/default, got 1/default underline\n", DiffSnapshots(want, got, false))
+
+	colored := DiffSnapshots(want, got, true)
+	assert.Contains(t, colored, "\x1b[0;1;31m^\x1b[0m\x1b[0;1;33m~")
+	assert.Contains(t, colored, "\x1b[0;4;31mr")
+
+	got.Cols = 4
+	got.Cells[0] = got.Cells[0][0:4]
+	got.Cells[1] = got.Cells[1][0:4]
+	assert.Contains(t, DiffSnapshots(want, got, false), "size: expected 5x2, got 4x2\n")
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
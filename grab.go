@@ -0,0 +1,138 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// GrabOptions is used to configure an input grab - see App.GrabInput. The zero value releases the grab
+// when Esc is pressed, or when a mouse button is pressed outside the widget, and consumes the event that
+// released it.
+type GrabOptions struct {
+	NoEscRelease          bool // If true, Esc is delivered to the widget like any other key
+	NoClickOutsideRelease bool // If true, presses outside the widget are delivered to it, and don't release
+	// PassClickOutside, if true, sends a press outside the widget, once it has released the grab, on to the
+	// widgets as usual - e.g. so that clicking another button while a dropdown is open closes the dropdown
+	// and presses the button.
+	PassClickOutside bool
+	OnRelease        func(app IApp) // If not nil, called when the grab is released, however that happens
+}
+
+type inputGrab struct {
+	w    IWidget
+	opts GrabOptions
+}
+
+// IInputGrabber is implemented by an IApp that lets a widget grab user input, like App.
+type IInputGrabber interface {
+	GrabInput(w IWidget, opts ...GrabOptions)
+	ReleaseInput() bool
+}
+
+// GrabInput sends all key and mouse events to w, if the app supports it - see App.GrabInput. It returns
+// false if the app doesn't.
+func GrabInput(app IApp, w IWidget, opts ...GrabOptions) bool {
+	if g, ok := app.(IInputGrabber); ok {
+		g.GrabInput(w, opts...)
+		return true
+	}
+	return false
+}
+
+// ReleaseInput releases an input grab, if the app supports grabs and one is in place.
+func ReleaseInput(app IApp) bool {
+	if g, ok := app.(IInputGrabber); ok {
+		return g.ReleaseInput()
+	}
+	return false
+}
+
+//======================================================================
+
+// GrabInput sends all key and mouse events to w, whatever has the focus, until the grab is released -
+// e.g. so that an open dropdown gets the keys while the focus stays with the widget that opened it. The
+// widget gets the events with the size and focus it was last rendered with, and mouse events in its own
+// coordinates, so that it can tell when the pointer is outside it. Esc, or a press outside the widget,
+// releases the grab, unless the options say otherwise; so does w no longer being drawn. A new grab
+// replaces any grab in place, which is released.
+func (a *App) GrabInput(w IWidget, opts ...GrabOptions) {
+	var opt GrabOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	a.ReleaseInput()
+	a.grab = &inputGrab{w: w, opts: opt}
+}
+
+// ReleaseInput releases the input grab, calling its OnRelease callback. It returns false if there was no
+// grab.
+func (a *App) ReleaseInput() bool {
+	g := a.grab
+	if g == nil {
+		return false
+	}
+	a.grab = nil
+	if g.opts.OnRelease != nil {
+		g.opts.OnRelease(a)
+	}
+	return true
+}
+
+// InputGrabbedBy returns the widget that has grabbed input, or nil if there is none.
+func (a *App) InputGrabbedBy() IWidget {
+	if a.grab == nil {
+		return nil
+	}
+	return a.grab.w
+}
+
+// grabInput delivers ev to the widget that has grabbed input, if there is one, and returns true if the
+// event should go no further.
+func (a *App) grabInput(ev interface{}) bool {
+	g := a.grab
+	if g == nil {
+		return false
+	}
+	x, y := a.TerminalSize()
+	rect, l, ok := locateWidget(a.viewPlusMenus, RenderBox{C: x, R: y}, Focused, a, func(w IWidget) bool {
+		return w == g.w
+	})
+	if !ok {
+		// The widget isn't drawn any more, e.g. its container was closed
+		a.ReleaseInput()
+		return false
+	}
+
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		if ev.Key() == tcell.KeyEscape && !g.opts.NoEscRelease {
+			a.ReleaseInput()
+			return true
+		}
+		g.w.UserInput(ev, l.size, l.focus, a)
+		return true
+	case *tcell.EventMouse:
+		mx, my := ev.Position()
+		if ev.Buttons()&(tcell.Button1|tcell.Button2|tcell.Button3) != 0 && !rect.Contains(mx, my) &&
+			!g.opts.NoClickOutsideRelease {
+			a.ReleaseInput()
+			return !g.opts.PassClickOutside
+		}
+		g.w.UserInput(TranslatedMouseEvent(ev, -rect.X, -rect.Y), l.size, l.focus, a)
+		return true
+	default:
+		g.w.UserInput(ev, l.size, l.focus, a)
+		return true
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
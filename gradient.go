@@ -0,0 +1,182 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"math"
+
+	"github.com/gdamore/tcell"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+//======================================================================
+
+// ColorSpace determines how colors are blended by InterpolateColor.
+type ColorSpace int
+
+const (
+	// RGBSpace blends each of red, green and blue separately.
+	RGBSpace ColorSpace = iota
+	// HSLSpace blends hue, saturation and lightness, taking the shorter way round the color wheel. Blends
+	// between saturated colors stay saturated, e.g. red to green passes through yellow rather than brown.
+	HSLSpace
+)
+
+// IColorToRGB returns the red, green and blue components of c. Colors from the terminal's palette, such
+// as urwid color names, are converted using the standard xterm values. False is returned if c expresses
+// no color preference or asks for the terminal's default color, neither of which has known components.
+func IColorToRGB(c IColor) (RGBColor, bool) {
+	if rgb, ok := c.(RGBColor); ok {
+		return rgb, true
+	}
+	if c == nil {
+		return RGBColor{}, false
+	}
+	tc, ok := c.ToTCellColor(Mode24BitColors)
+	if !ok || tc == ColorNone {
+		return RGBColor{}, false
+	}
+	r, g, b := tc.ToTCell().RGB()
+	if r < 0 || tc.ToTCell() == tcell.ColorDefault {
+		return RGBColor{}, false
+	}
+	return RGBColor{int(r), int(g), int(b)}, true
+}
+
+func toColorful(c RGBColor) colorful.Color {
+	return colorful.Color{R: float64(c.Red) / 255.0, G: float64(c.Green) / 255.0, B: float64(c.Blue) / 255.0}
+}
+
+func fromColorful(c colorful.Color) RGBColor {
+	r, g, b := c.Clamped().RGB255()
+	return RGBColor{int(r), int(g), int(b)}
+}
+
+// InterpolateColor returns the color a fraction t of the way from one color to another, blended in the
+// color space provided. t is limited to [0, 1]. If either color has no known components - see
+// IColorToRGB - the result is whichever color t is nearer.
+func InterpolateColor(from, to IColor, t float64, space ColorSpace) IColor {
+	t = math.Max(0, math.Min(1, t))
+	c1, ok1 := IColorToRGB(from)
+	c2, ok2 := IColorToRGB(to)
+	if !ok1 || !ok2 {
+		if t < 0.5 {
+			return from
+		}
+		return to
+	}
+	switch space {
+	case HSLSpace:
+		h1, s1, l1 := toColorful(c1).Hsl()
+		h2, s2, l2 := toColorful(c2).Hsl()
+		// A gray has no hue - use the other color's, so the blend doesn't sweep through unrelated hues
+		if s1 == 0 {
+			h1 = h2
+		} else if s2 == 0 {
+			h2 = h1
+		}
+		if h2-h1 > 180 {
+			h1 += 360
+		} else if h1-h2 > 180 {
+			h2 += 360
+		}
+		h := math.Mod(h1+(h2-h1)*t, 360)
+		return fromColorful(colorful.Hsl(h, s1+(s2-s1)*t, l1+(l2-l1)*t))
+	default:
+		return fromColorful(toColorful(c1).BlendRgb(toColorful(c2), t))
+	}
+}
+
+//======================================================================
+
+// Gradient is a sequence of colors, evenly spaced from 0 to 1, blended in Space.
+type Gradient struct {
+	Stops []IColor
+	Space ColorSpace
+}
+
+// MakeGradient returns a Gradient through stops, blended in space.
+func MakeGradient(space ColorSpace, stops ...IColor) Gradient {
+	return Gradient{Stops: stops, Space: space}
+}
+
+// At returns the color a fraction t of the way along the gradient. A gradient with no stops returns
+// NoColor.
+func (g Gradient) At(t float64) IColor {
+	switch len(g.Stops) {
+	case 0:
+		return NoColor{}
+	case 1:
+		return g.Stops[0]
+	}
+	t = math.Max(0, math.Min(1, t))
+	pos := t * float64(len(g.Stops)-1)
+	i := int(pos)
+	if i >= len(g.Stops)-1 {
+		return g.Stops[len(g.Stops)-1]
+	}
+	return InterpolateColor(g.Stops[i], g.Stops[i+1], pos-float64(i), g.Space)
+}
+
+//======================================================================
+
+// IPositionStyler is an ICellStyler whose style varies along a row of cells - like GradientStyler.
+// Widgets that support it, like styled.Widget and progress.Widget, call ForPosition for each column;
+// others just use GetStyle.
+type IPositionStyler interface {
+	ICellStyler
+	ForPosition(pos int, length int) ICellStyler
+}
+
+// StylerForPosition returns the style for column pos of length, if styler is an IPositionStyler;
+// otherwise it returns styler unchanged.
+func StylerForPosition(styler ICellStyler, pos int, length int) ICellStyler {
+	if ps, ok := styler.(IPositionStyler); ok {
+		return ps.ForPosition(pos, length)
+	}
+	return styler
+}
+
+// GradientStyler colors a row of cells along a gradient, from the first column to the last. A gradient
+// with no stops leaves that color unspecified, so e.g. text can be given a gradient background and keep
+// its foreground color.
+type GradientStyler struct {
+	Foreground Gradient
+	Background Gradient
+	Style      StyleAttrs
+}
+
+var _ IPositionStyler = GradientStyler{}
+
+// MakeForegroundGradient returns a GradientStyler that colors text along a gradient through stops.
+func MakeForegroundGradient(space ColorSpace, stops ...IColor) GradientStyler {
+	return GradientStyler{Foreground: MakeGradient(space, stops...)}
+}
+
+// MakeBackgroundGradient returns a GradientStyler that fills the background along a gradient through
+// stops.
+func MakeBackgroundGradient(space ColorSpace, stops ...IColor) GradientStyler {
+	return GradientStyler{Background: MakeGradient(space, stops...)}
+}
+
+// ForPosition returns the style for column pos of a row of length cells.
+func (g GradientStyler) ForPosition(pos int, length int) ICellStyler {
+	t := 0.0
+	if length > 1 {
+		t = float64(pos) / float64(length-1)
+	}
+	return MakeStyledPaletteEntry(g.Foreground.At(t), g.Background.At(t), g.Style)
+}
+
+// GetStyle implements ICellStyler, returning the style at the start of the gradient.
+func (g GradientStyler) GetStyle(prov IRenderContext) (x IColor, y IColor, z StyleAttrs) {
+	return g.ForPosition(0, 1).GetStyle(prov)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,72 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIColorToRGB1(t *testing.T) {
+	c, ok := IColorToRGB(MakeRGBColor("#123456"))
+	assert.True(t, ok)
+	assert.Equal(t, RGBColor{0x12, 0x34, 0x56}, c)
+
+	c, ok = IColorToRGB(ColorRed)
+	assert.True(t, ok)
+	assert.Equal(t, RGBColor{0xff, 0, 0}, c)
+
+	c, ok = IColorToRGB(NewUrwidColor("dark red"))
+	assert.True(t, ok)
+	assert.Equal(t, RGBColor{0x80, 0, 0}, c)
+
+	_, ok = IColorToRGB(NoColor{})
+	assert.False(t, ok)
+	_, ok = IColorToRGB(ColorDefault)
+	assert.False(t, ok)
+}
+
+func TestInterpolateColor1(t *testing.T) {
+	black, white := MakeRGBColor("#000000"), MakeRGBColor("#ffffff")
+	assert.Equal(t, RGBColor{0x80, 0x80, 0x80}, InterpolateColor(black, white, 0.5, RGBSpace))
+	assert.Equal(t, white, InterpolateColor(black, white, 2, RGBSpace))
+
+	red, green := MakeRGBColor("#ff0000"), MakeRGBColor("#00ff00")
+	assert.Equal(t, RGBColor{0x80, 0x80, 0}, InterpolateColor(red, green, 0.5, RGBSpace))
+	assert.Equal(t, RGBColor{0xff, 0xff, 0}, InterpolateColor(red, green, 0.5, HSLSpace))
+
+	// Hue takes the shorter way round - from magenta to red, not through green
+	magenta := MakeRGBColor("#ff00ff")
+	assert.Equal(t, RGBColor{0xff, 0, 0x80}, InterpolateColor(magenta, red, 0.5, HSLSpace))
+
+	// Without known components, the nearer color is used
+	assert.Equal(t, NoColor{}, InterpolateColor(NoColor{}, red, 0.4, RGBSpace))
+	assert.Equal(t, red, InterpolateColor(NoColor{}, red, 0.6, RGBSpace))
+}
+
+func TestGradient1(t *testing.T) {
+	g := MakeGradient(RGBSpace, MakeRGBColor("#000000"), MakeRGBColor("#ff0000"), MakeRGBColor("#ffff00"))
+	assert.Equal(t, RGBColor{0, 0, 0}, g.At(0))
+	assert.Equal(t, RGBColor{0x80, 0, 0}, g.At(0.25))
+	assert.Equal(t, RGBColor{0xff, 0, 0}, g.At(0.5))
+	assert.Equal(t, RGBColor{0xff, 0xff, 0}, g.At(1))
+	assert.Equal(t, NoColor{}, Gradient{}.At(0.5))
+
+	ctx := stateTestContext{Palette{}}
+	s := MakeBackgroundGradient(RGBSpace, MakeRGBColor("#000000"), MakeRGBColor("#ffffff"))
+	f, b, _ := StylerForPosition(s, 4, 5).GetStyle(ctx)
+	assert.Equal(t, NoColor{}, f)
+	assert.Equal(t, RGBColor{0xff, 0xff, 0xff}, b)
+	_, b, _ = s.GetStyle(ctx)
+	assert.Equal(t, RGBColor{0, 0, 0}, b)
+
+	assert.Equal(t, MakeForeground(ColorRed), StylerForPosition(MakeForeground(ColorRed), 3, 5))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
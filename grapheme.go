@@ -0,0 +1,145 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+)
+
+//======================================================================
+
+// GraphemeState follows a sequence of runes, one at a time, to split it into grapheme clusters - the
+// units a user sees as single characters, like an accented letter made of a letter and a combining
+// accent, a flag made of two regional indicators, or an emoji sequence joined with zero-width joiners.
+// A terminal draws each cluster in one or two columns, however many runes it has. The zero value is ready
+// to use.
+type GraphemeState struct {
+	prev  rune // The last rune added
+	width int  // The width of the current cluster
+	ri    bool // True if the current cluster is a single regional indicator, waiting for its pair
+	emoji bool // True if the current cluster began with a wide rune, like an emoji
+}
+
+const (
+	zeroWidthJoiner = '\u200d'
+	emojiVariation  = '\ufe0f'
+)
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || (r >= 0x7f && r < 0xa0)
+}
+
+// Add adds r to the sequence. It returns true if r begins a new cluster, and the number of columns by
+// which r widens the text - for a rune that joins the current cluster, usually 0.
+func (g *GraphemeState) Add(r rune) (bool, int) {
+	prev := g.prev
+	g.prev = r
+
+	if prev != 0 && !isControl(prev) && !isControl(r) {
+		extend, width := false, g.width
+		switch {
+		case r == zeroWidthJoiner:
+			extend = true
+		case prev == zeroWidthJoiner && g.emoji:
+			// The next emoji of a sequence like a family, drawn as one
+			extend = true
+		case r == emojiVariation:
+			// Asks for emoji presentation, which is two columns wide
+			extend, width = true, 2
+			g.emoji = true
+		case r >= 0xfe00 && r <= 0xfe0e:
+			extend = true
+		case r >= 0x1f3fb && r <= 0x1f3ff && g.emoji:
+			// Skin tone modifiers
+			extend = true
+		case r >= 0xe0020 && r <= 0xe007f:
+			// Tags, as in the flags of subdivisions like Scotland
+			extend = true
+		case isRegionalIndicator(r) && g.ri:
+			extend, width = true, 2
+			g.ri = false
+		case r >= 0x1160 && r <= 0x11ff && prev >= 0x1100 && prev <= 0x11ff:
+			// Hangul vowel and final consonant jamo, composed with the preceding jamo
+			extend = true
+		case unicode.In(r, unicode.Mn, unicode.Me):
+			extend = true
+		case unicode.Is(unicode.Mc, r):
+			extend, width = true, g.width+runewidth.RuneWidth(r)
+		}
+		if extend {
+			delta := width - g.width
+			g.width = width
+			return false, delta
+		}
+	}
+
+	g.width = runewidth.RuneWidth(r)
+	g.ri = isRegionalIndicator(r)
+	g.emoji = g.width == 2 || g.ri
+	if g.ri {
+		// A lone regional indicator is drawn like a letter
+		g.width = 1
+	}
+	return true, g.width
+}
+
+// Reset forgets the runes added, so that the next rune begins a cluster.
+func (g *GraphemeState) Reset() {
+	*g = GraphemeState{}
+}
+
+//======================================================================
+
+// GraphemeWidths returns the number of columns each of runes takes up, grouped into grapheme clusters:
+// the first rune of each cluster has the width of the whole cluster, and the others have width 0. Text
+// laid out with these widths never splits a cluster across lines.
+func GraphemeWidths(runes []rune) []int {
+	res := make([]int, len(runes))
+	var g GraphemeState
+	start := 0
+	for i, r := range runes {
+		first, width := g.Add(r)
+		if first {
+			start = i
+		}
+		res[start] += width
+	}
+	return res
+}
+
+// GraphemeStarts returns, for each of runes, true if it begins a grapheme cluster. A cursor should only
+// stop at the start of a cluster.
+func GraphemeStarts(runes []rune) []bool {
+	res := make([]bool, len(runes))
+	var g GraphemeState
+	for i, r := range runes {
+		res[i], _ = g.Add(r)
+	}
+	return res
+}
+
+// StringWidth returns the number of columns s takes up in a terminal, like runewidth.StringWidth, but
+// measuring grapheme clusters rather than runes.
+func StringWidth(s string) int {
+	res := 0
+	var g GraphemeState
+	for _, r := range s {
+		_, width := g.Add(r)
+		res += width
+	}
+	return res
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
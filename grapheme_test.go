@@ -0,0 +1,53 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphemeWidths1(t *testing.T) {
+	for _, tc := range []struct {
+		str    string
+		widths []int
+	}{
+		{"ab", []int{1, 1}},
+		{"e\u0301x", []int{1, 0, 1}},                                         // Combining accent
+		{"\U0001F1EF\U0001F1F5\U0001F1EF", []int{2, 0, 1}},                   // Flag, then a lone regional indicator
+		{"\U0001F468\u200d\U0001F469\u200d\U0001F467", []int{2, 0, 0, 0, 0}}, // Family
+		{"\u2764\ufe0f\u2764", []int{2, 0, 1}},                               // Emoji and text presentation
+		{"\U0001F44D\U0001F3FD", []int{2, 0}},                                // Skin tone
+		{"\u1100\u1161\u11a8", []int{2, 0, 0}},                               // Hangul jamo
+		{"a\n\u0301", []int{1, 0, 0}},                                        // Nothing to combine with after a newline
+		{"\u0301a", []int{0, 1}},
+	} {
+		assert.Equal(t, tc.widths, GraphemeWidths([]rune(tc.str)), "for %q", tc.str)
+		sum := 0
+		for _, w := range tc.widths {
+			sum += w
+		}
+		assert.Equal(t, sum, StringWidth(tc.str), "for %q", tc.str)
+	}
+
+	assert.Equal(t, []bool{true, false, true}, GraphemeStarts([]rune("e\u0301x")))
+}
+
+func TestGraphemeCells1(t *testing.T) {
+	cells := CellsFromString("a\U0001F1EF\U0001F1F5e\u0301")
+	assert.Equal(t, 3, len(cells))
+	assert.Equal(t, '\U0001F1EF', cells[1].Rune())
+	assert.Equal(t, []rune{'\U0001F1F5'}, cells[1].Combining())
+	assert.Equal(t, 2, cells[1].Width())
+	assert.Equal(t, 1, cells[2].Width())
+	assert.Equal(t, 1, cells[2].WithRune('x').Width())
+	assert.Nil(t, cells[2].WithRune('x').Combining())
+
+	c := NewCanvasOfSize(4, 2)
+	_, err := c.Write([]byte("\U0001F468\u200d\U0001F469e\u0301\u2764\ufe0f"))
+	assert.NoError(t, err)
+	assert.Equal(t, "\U0001F468\u200d\U0001F469e\u0301 \n\u2764\ufe0f  ", c.String())
+	assert.Equal(t, []rune{'\u200d', '\U0001F469'}, c.CellAt(0, 0).Combining())
+}
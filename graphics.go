@@ -0,0 +1,318 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gcla/gowid/gwutil"
+)
+
+//======================================================================
+
+// GraphicsProtocol is a way of drawing pictures in a terminal.
+type GraphicsProtocol int
+
+const (
+	// GraphicsDefault means the protocol of the terminal, as detected or declared to the app - see
+	// GraphicsProtocolFor.
+	GraphicsDefault GraphicsProtocol = iota
+	// GraphicsBlocks draws pictures with colored half-block characters, two pixels to a cell. It works
+	// on any terminal with 256 colors or more.
+	GraphicsBlocks
+	// GraphicsSixel draws pictures with DEC Sixel sequences, supported by e.g. foot, mlterm and
+	// WezTerm.
+	GraphicsSixel
+	// GraphicsITerm2 draws pictures with iTerm2's inline image sequence, supported by iTerm2, WezTerm
+	// and mintty.
+	GraphicsITerm2
+	// GraphicsKitty draws pictures with the Kitty graphics protocol, supported by kitty and Ghostty. The
+	// terminal scales pictures to their cells and replaces them in place, so they are sharper and quicker
+	// to update than with Sixel.
+	GraphicsKitty
+)
+
+func (p GraphicsProtocol) String() string {
+	switch p {
+	case GraphicsDefault:
+		return "default"
+	case GraphicsBlocks:
+		return "blocks"
+	case GraphicsSixel:
+		return "sixel"
+	case GraphicsITerm2:
+		return "iterm2"
+	case GraphicsKitty:
+		return "kitty"
+	default:
+		return fmt.Sprintf("graphics(%d)", int(p))
+	}
+}
+
+// IGraphics is implemented by an IApp that knows how its terminal can draw pictures, like App.
+type IGraphics interface {
+	GraphicsProtocol() GraphicsProtocol
+	// CellPixelSize returns the width and height of a cell of the terminal, in pixels.
+	CellPixelSize() (int, int)
+}
+
+// DefaultCellPixelSize is the size in pixels of a terminal cell, assumed when drawing pictures with a
+// protocol that needs it, like Sixel, unless the app is told otherwise.
+var DefaultCellPixelSize = [2]int{10, 20}
+
+// GraphicsProtocolFor returns the protocol with which to draw pictures for app - GraphicsBlocks if the
+// app doesn't say.
+func GraphicsProtocolFor(app IApp) GraphicsProtocol {
+	if g, ok := app.(IGraphics); ok {
+		if res := g.GraphicsProtocol(); res != GraphicsDefault {
+			return res
+		}
+	}
+	return GraphicsBlocks
+}
+
+// CellPixelSizeFor returns the width and height in pixels of a cell of app's terminal.
+func CellPixelSizeFor(app IApp) (int, int) {
+	if g, ok := app.(IGraphics); ok {
+		return g.CellPixelSize()
+	}
+	return DefaultCellPixelSize[0], DefaultCellPixelSize[1]
+}
+
+// graphicsProtocol guesses the graphics protocol of the terminal described by the environment. Like
+// OSC 8, only terminals known to support a protocol are ruled in, since others may display the
+// sequences. Inside tmux or GNU screen, pictures are drawn with blocks.
+func graphicsProtocol(getenv func(string) string) GraphicsProtocol {
+	term := getenv("TERM")
+	if getenv("TMUX") != "" || strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux") {
+		return GraphicsBlocks
+	}
+	if getenv("KITTY_WINDOW_ID") != "" {
+		return GraphicsKitty
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "mintty":
+		return GraphicsITerm2
+	}
+	if getenv("LC_TERMINAL") == "iTerm2" {
+		return GraphicsITerm2
+	}
+	switch term {
+	case "xterm-kitty", "xterm-ghostty":
+		return GraphicsKitty
+	case "foot", "foot-extra", "mlterm", "yaft-256color", "contour":
+		return GraphicsSixel
+	case "wezterm":
+		return GraphicsITerm2
+	}
+	return GraphicsBlocks
+}
+
+// GraphicsProtocol returns the protocol with which widgets should draw pictures - guessed from the
+// environment unless declared with SetGraphicsProtocol. It lets App conform to IGraphics.
+func (a *App) GraphicsProtocol() GraphicsProtocol {
+	if a.graphics.protocol != GraphicsDefault {
+		return a.graphics.protocol
+	}
+	return graphicsProtocol(a.Getenv)
+}
+
+// SetGraphicsProtocol overrides the app's guess as to how the terminal can draw pictures. Set
+// GraphicsDefault to guess again.
+func (a *App) SetGraphicsProtocol(p GraphicsProtocol) {
+	a.graphics.protocol = p
+	a.Redraw()
+}
+
+// CellPixelSize returns the size in pixels of a terminal cell - DefaultCellPixelSize unless declared with
+// SetCellPixelSize.
+func (a *App) CellPixelSize() (int, int) {
+	if a.graphics.cellWidth > 0 && a.graphics.cellHeight > 0 {
+		return a.graphics.cellWidth, a.graphics.cellHeight
+	}
+	return DefaultCellPixelSize[0], DefaultCellPixelSize[1]
+}
+
+// SetCellPixelSize declares the size in pixels of a terminal cell, so that pictures drawn with Sixel
+// fill the cells they are given.
+func (a *App) SetCellPixelSize(width, height int) {
+	a.graphics.cellWidth, a.graphics.cellHeight = width, height
+	a.Redraw()
+}
+
+//======================================================================
+
+// RawRegion is a rectangle of the screen drawn by an escape sequence that gowid writes to the terminal
+// itself, because tcell can't send it - e.g. an inline image. A widget renders the rectangle with
+// ordinary cells, as it should look on a terminal that can't draw the sequence, then marks each of them
+// as part of the region with MarkRawRegion. After tcell has shown a frame, the sequence is written with
+// the cursor at the region's top left cell - but only if all of the region's cells are on the screen, so
+// if it is partly scrolled out of view or covered by an overlay, the ordinary cells are shown instead.
+type RawRegion struct {
+	Cols, Rows int
+	Sequence   string
+	// Clear erases what Sequence drew, for sequences that draw on a layer of their own that tcell's
+	// output doesn't overwrite, like Kitty's images. It is written when the region leaves the screen or
+	// is replaced, unless by a region with the same Clear sequence, which the terminal draws in its place.
+	// If empty, the screen is repainted instead when the region leaves it.
+	Clear string
+}
+
+func NewRawRegion(cols, rows int, seq string) *RawRegion {
+	return &RawRegion{Cols: cols, Rows: rows, Sequence: seq}
+}
+
+func (r *RawRegion) String() string {
+	return fmt.Sprintf("rawregion[%dx%d]", r.Cols, r.Rows)
+}
+
+// MarkRawRegion marks each cell of canvas as part of region. The canvas should be region's size.
+func MarkRawRegion(canvas IRangeOverCanvas, region *RawRegion) {
+	RangeOverCanvas(canvas, CellRangeFunc(func(c Cell) Cell {
+		return c.WithRawRegion(region)
+	}))
+}
+
+// rawPlacement is a raw region that is wholly on the screen, at column X and row Y.
+type rawPlacement struct {
+	X, Y   int
+	Region *RawRegion
+}
+
+// sameRect returns true if p and q cover the same cells.
+func (p rawPlacement) sameRect(q rawPlacement) bool {
+	return p.X == q.X && p.Y == q.Y && p.Region.Cols == q.Region.Cols && p.Region.Rows == q.Region.Rows
+}
+
+// same returns true if p and q draw the same thing in the same place.
+func (p rawPlacement) same(q rawPlacement) bool {
+	return p.sameRect(q) && (p.Region == q.Region || *p.Region == *q.Region)
+}
+
+// rawPlacements returns the raw regions wholly on the screen in canvas, in the order they are first found.
+func rawPlacements(canvas IDrawCanvas) []rawPlacement {
+	type extent struct {
+		x0, y0, x1, y1 int
+		count          int
+	}
+	found := make(map[*RawRegion]*extent)
+	order := make([]*RawRegion, 0)
+	for y := 0; y < canvas.BoxRows(); y++ {
+		line := canvas.Line(y, LineCopy{}).Line
+		for x, c := range line {
+			r := c.RawRegion()
+			if r == nil {
+				continue
+			}
+			e, ok := found[r]
+			if !ok {
+				e = &extent{x0: x, y0: y, x1: x, y1: y}
+				found[r] = e
+				order = append(order, r)
+			}
+			e.x0, e.x1 = gwutil.Min(e.x0, x), gwutil.Max(e.x1, x)
+			e.y1 = y
+			e.count++
+		}
+	}
+	res := make([]rawPlacement, 0, len(order))
+	for _, r := range order {
+		e := found[r]
+		if e.count == r.Cols*r.Rows && e.x1-e.x0+1 == r.Cols && e.y1-e.y0+1 == r.Rows {
+			res = append(res, rawPlacement{X: e.x0, Y: e.y0, Region: r})
+		}
+	}
+	return res
+}
+
+// rawSequence returns the escape sequences to draw each placement, saving and restoring the cursor
+// position around them.
+func rawSequence(placements []rawPlacement) string {
+	if len(placements) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\x1b7")
+	for _, p := range placements {
+		fmt.Fprintf(&b, "\x1b[%d;%dH", p.Y+1, p.X+1)
+		b.WriteString(p.Region.Sequence)
+	}
+	b.WriteString("\x1b8")
+	return b.String()
+}
+
+// graphicsState is the app's knowledge of how to draw pictures, and of the raw regions on the screen.
+type graphicsState struct {
+	protocol              GraphicsProtocol // If not GraphicsDefault, overrides detection
+	cellWidth, cellHeight int              // If not 0, overrides DefaultCellPixelSize
+	pending               []rawPlacement   // The raw regions in the frame being drawn
+	shown                 []rawPlacement   // The raw regions drawn on the terminal
+	redraw                bool             // True if the screen was repainted, so all regions must be drawn
+}
+
+// prepareRawRegions is called with each frame before it is drawn, to find the raw regions to draw after it.
+func (a *App) prepareRawRegions(canvas ICanvas) {
+	a.graphics.pending = rawPlacements(canvas)
+}
+
+// drawRawRegions is called after each frame is shown, to draw the frame's raw regions.
+func (a *App) drawRawRegions() {
+	seq, repaint := rawUpdate(a.graphics.shown, a.graphics.pending, a.graphics.redraw)
+	if repaint {
+		a.screen.Sync()
+		seq, _ = rawUpdate(a.graphics.shown, a.graphics.pending, true)
+	}
+	a.graphics.shown = a.graphics.pending
+	a.graphics.redraw = false
+	if seq != "" {
+		a.writeToTerminal(seq)
+	}
+}
+
+// rawUpdate returns the escape sequences to change the raw regions on the terminal from shown to cur.
+// Regions that have gone or changed are cleared. tcell doesn't know what a region without a Clear sequence
+// drew over, so if one has gone, rawUpdate returns true: the screen must be repainted, and all regions
+// drawn. Otherwise only new regions are drawn, unless redraw is true.
+func rawUpdate(shown, cur []rawPlacement, redraw bool) (string, bool) {
+	var b strings.Builder
+	for _, p := range shown {
+		kept, replaced := false, false
+		for _, q := range cur {
+			kept = kept || p.same(q) || (p.Region.Clear != "" && p.Region.Clear == q.Region.Clear)
+			replaced = replaced || p.sameRect(q)
+		}
+		switch {
+		case kept:
+			// Still there, or replaced by a region that the terminal draws in its place
+		case p.Region.Clear != "":
+			b.WriteString(p.Region.Clear)
+		case !replaced && !redraw:
+			return "", true
+		}
+	}
+
+	draw := cur
+	if !redraw {
+		draw = make([]rawPlacement, 0, len(cur))
+		for _, q := range cur {
+			drawn := false
+			for _, p := range shown {
+				drawn = drawn || p.same(q)
+			}
+			if !drawn {
+				draw = append(draw, q)
+			}
+		}
+	}
+	b.WriteString(rawSequence(draw))
+	return b.String(), false
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
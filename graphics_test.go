@@ -0,0 +1,86 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphicsProtocol1(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(k string) string {
+			return vars[k]
+		}
+	}
+	assert.Equal(t, GraphicsSixel, graphicsProtocol(env(map[string]string{"TERM": "foot"})))
+	assert.Equal(t, GraphicsITerm2, graphicsProtocol(env(map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app"})))
+	assert.Equal(t, GraphicsITerm2, graphicsProtocol(env(map[string]string{"TERM": "xterm-256color", "LC_TERMINAL": "iTerm2"})))
+	assert.Equal(t, GraphicsKitty, graphicsProtocol(env(map[string]string{"TERM": "xterm-kitty"})))
+	assert.Equal(t, GraphicsKitty, graphicsProtocol(env(map[string]string{"TERM": "xterm-256color", "KITTY_WINDOW_ID": "1"})))
+	assert.Equal(t, GraphicsBlocks, graphicsProtocol(env(map[string]string{"TERM": "foot", "TMUX": "/tmp/tmux-0/default"})))
+	assert.Equal(t, GraphicsBlocks, graphicsProtocol(env(map[string]string{"TERM": "xterm-256color"})))
+	assert.Equal(t, "sixel", GraphicsSixel.String())
+}
+
+func TestRawRegions1(t *testing.T) {
+	c := NewCanvasOfSize(6, 3)
+	a := NewRawRegion(2, 2, "A")
+	b := NewRawRegion(2, 1, "B")
+	clipped := NewRawRegion(3, 3, "C")
+	img := NewCanvasOfSize(2, 2)
+	MarkRawRegion(img, a)
+	c.MergeUnder(img, 1, 0, false)
+	c.SetCellAt(4, 2, CellFromRune('y').WithRawRegion(b))
+	c.SetCellAt(5, 2, CellFromRune('y').WithRawRegion(b))
+	// Only one row of this region is on the screen
+	c.SetCellAt(0, 2, CellFromRune('z').WithRawRegion(clipped))
+
+	placements := rawPlacements(c)
+	assert.Equal(t, []rawPlacement{{X: 1, Y: 0, Region: a}, {X: 4, Y: 2, Region: b}}, placements)
+	assert.Equal(t, "\x1b7\x1b[1;2HA\x1b[3;5HB\x1b8", rawSequence(placements))
+	assert.Equal(t, "", rawSequence(nil))
+
+	// Covered by part of an overlay, a region isn't drawn
+	c.SetCellAt(2, 1, c.CellAt(2, 1).MergeUnder(CellFromRune('o')))
+	assert.Equal(t, []rawPlacement{{X: 4, Y: 2, Region: b}}, rawPlacements(c))
+
+	// A region survives having its colors changed, but not its text
+	cell := CellFromRune('x').WithRawRegion(a)
+	assert.Equal(t, a, cell.MergeDisplayAttrsUnder(CellFromRune('q').WithForegroundColor(ColorRed)).RawRegion())
+	assert.Equal(t, a, CellFromRune('q').MergeUnder(cell).RawRegion())
+	assert.Nil(t, cell.WithRune('w').RawRegion())
+}
+
+func TestRawUpdate1(t *testing.T) {
+	sixel := rawPlacement{X: 0, Y: 0, Region: NewRawRegion(1, 1, "S")}
+	kitty := rawPlacement{X: 2, Y: 0, Region: &RawRegion{Cols: 1, Rows: 1, Sequence: "K1", Clear: "-K"}}
+	other := rawPlacement{X: 4, Y: 0, Region: &RawRegion{Cols: 1, Rows: 1, Sequence: "L", Clear: "-L"}}
+
+	seq, repaint := rawUpdate(nil, []rawPlacement{sixel, kitty}, false)
+	assert.Equal(t, "\x1b7\x1b[1;1HS\x1b[1;3HK1\x1b8", seq)
+	assert.False(t, repaint)
+
+	// Nothing changed
+	seq, _ = rawUpdate([]rawPlacement{sixel, kitty}, []rawPlacement{sixel, kitty}, false)
+	assert.Equal(t, "", seq)
+
+	// A new picture with the same Clear sequence replaces the last without clearing it
+	kitty2 := rawPlacement{X: 2, Y: 1, Region: &RawRegion{Cols: 1, Rows: 1, Sequence: "K2", Clear: "-K"}}
+	seq, _ = rawUpdate([]rawPlacement{sixel, kitty, other}, []rawPlacement{sixel, kitty2}, false)
+	assert.Equal(t, "-L\x1b7\x1b[2;3HK2\x1b8", seq)
+
+	// A region without a Clear sequence can only be removed by repainting
+	_, repaint = rawUpdate([]rawPlacement{sixel, kitty}, []rawPlacement{kitty}, false)
+	assert.True(t, repaint)
+	seq, _ = rawUpdate([]rawPlacement{sixel, kitty}, []rawPlacement{kitty}, true)
+	assert.Equal(t, "\x1b7\x1b[1;3HK1\x1b8", seq)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,74 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/boxadapter"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/checkbox"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/list"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/progress"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessible1(t *testing.T) {
+	quit := button.New(text.New("Quit"))
+	clicks := 0
+	quit.OnClick(gowid.WidgetCallback{Name: "cb", WidgetChangedFunction: func(app gowid.IApp, w gowid.IWidget) {
+		clicks++
+	}})
+	cb := checkbox.New(true)
+	w := pile.NewFlow(
+		text.New("  Settings  "),
+		edit.New(edit.Options{Caption: "Name: ", Text: "bob"}),
+		edit.New(edit.Options{Caption: "Password: ", Text: "secret", Mask: edit.MakeMask('*')}),
+		gowid.NewAccessible(columns.NewFixed(cb, text.New(" Verbose")), gowid.RoleGroup, "Options"),
+		progress.New(progress.Options{Current: 25, Normal: gowid.MakePaletteRef("x"), Complete: gowid.MakePaletteRef("y")}),
+		boxadapter.New(list.New(list.NewSimpleListWalker([]gowid.IWidget{
+			text.New("one"),
+			button.New(text.New("two")),
+		})), 2),
+		quit,
+	)
+	sim := NewSimT(t, w, SimOptions{Cols: 20, Rows: 10})
+	defer sim.Close()
+
+	tree := sim.AccessibilityTree()
+	assert.Equal(t, `- (focused)
+  text "Settings"
+  edit "Name:" [bob] (focused)
+  edit "Password:"
+  group "Options"
+    checkbox [checked]
+    text "Verbose"
+  progressbar [25 %]
+  list
+    text "one"
+    button "two"
+  button "Quit"`, tree.String())
+	assert.Equal(t, gowid.RoleEdit, tree.Focus().Role)
+	assert.Len(t, tree.FindAll(gowid.RoleText, ""), 3)
+
+	n, ok := tree.Find(gowid.RoleButton, "Quit")
+	assert.True(t, ok)
+	assert.Equal(t, quit, n.Widget)
+	_, ok = tree.Find(gowid.RoleButton, "Cancel")
+	assert.False(t, ok)
+
+	assert.True(t, sim.ClickAccessible(gowid.RoleButton, "Quit"))
+	assert.Equal(t, 1, clicks)
+	assert.Equal(t, "button \"Quit\" (focused)", sim.AccessibilityTree().Focus().String())
+	assert.False(t, sim.ClickAccessible(gowid.RoleButton, "Cancel"))
+
+	sim.ClickAccessible(gowid.RoleCheckBox, "")
+	n, _ = sim.AccessibilityTree().Find(gowid.RoleCheckBox, "")
+	assert.Equal(t, "unchecked", n.State)
+}
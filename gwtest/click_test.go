@@ -0,0 +1,96 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/list"
+	"github.com/gcla/gowid/widgets/selectable"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+// clickCounter records the count of each click it sees pressed.
+type clickCounter struct {
+	*edit.Widget
+	counts []int
+}
+
+func (w *clickCounter) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if cev, ok := gowid.ClickEventFor(ev, app); ok && !cev.Released {
+		w.counts = append(w.counts, cev.Count)
+	}
+	return w.Widget.UserInput(ev, size, focus, app)
+}
+
+func TestClickCount1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Unix(1000, 0))
+	e := edit.New(edit.Options{Text: "foo bar_1 baz\nqux"})
+	cc := &clickCounter{Widget: e}
+	sim := NewSimT(t, cc, SimOptions{Cols: 20, Rows: 2, Clock: clock})
+	defer sim.Close()
+
+	sim.Click(6, 0, tcell.Button1)
+	_, _, ok := e.Selection()
+	assert.False(t, ok)
+	sim.Click(6, 0, tcell.Button1)
+	assert.Equal(t, "bar_1", e.SelectedText())
+	assert.Equal(t, 9, e.CursorPos())
+	sim.Click(6, 0, tcell.Button1)
+	assert.Equal(t, "foo bar_1 baz", e.SelectedText())
+	sim.Click(6, 0, tcell.Button1)
+	assert.Equal(t, []int{1, 2, 3, 4}, cc.counts)
+	sim.AssertLine(t, 0, "foo bar_1 baz       ")
+	_, st := sim.Cell(2, 0)
+	_, _, attrs := st.Decompose()
+	assert.NotEqual(t, tcell.AttrNone, attrs&tcell.AttrReverse)
+
+	// Typing replaces the selection
+	sim.Rune('x')
+	assert.Equal(t, "x\nqux", e.Text())
+	_, _, ok = e.Selection()
+	assert.False(t, ok)
+
+	// Clicks too far apart, in time or space, start again
+	cc.counts = nil
+	sim.Click(0, 1, tcell.Button1)
+	clock.Advance(time.Second)
+	sim.Click(0, 1, tcell.Button1)
+	sim.Click(1, 1, tcell.Button1)
+	sim.SetMultiClickInterval(2 * time.Second)
+	clock.Advance(time.Second)
+	sim.Click(1, 1, tcell.Button1)
+	assert.Equal(t, []int{1, 1, 1, 2}, cc.counts)
+	assert.Equal(t, "qux", e.SelectedText())
+
+	// Other keys just clear the selection
+	sim.Key(tcell.KeyLeft)
+	assert.Equal(t, "x\nqux", e.Text())
+	assert.Equal(t, "", e.SelectedText())
+}
+
+func TestClickCount2(t *testing.T) {
+	items := []gowid.IWidget{
+		selectable.New(text.New("a")),
+		selectable.New(text.New("b")),
+		selectable.New(text.New("c")),
+	}
+	l := list.New(list.NewSimpleListWalker(items))
+	activated := []gowid.IWidget{}
+	l.OnActivate(gowid.MakeWidgetCallback("test", func(app gowid.IApp, w gowid.IWidget) {
+		activated = append(activated, w)
+	}))
+	sim := NewSimT(t, l, SimOptions{Cols: 3, Rows: 3})
+	defer sim.Close()
+
+	sim.Click(0, 1, tcell.Button1)
+	assert.Empty(t, activated)
+	sim.Click(0, 1, tcell.Button1)
+	assert.Equal(t, []gowid.IWidget{items[1]}, activated)
+}
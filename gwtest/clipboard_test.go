@@ -0,0 +1,43 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClipboard1(t *testing.T) {
+	e := edit.New(edit.Options{Text: "secret"})
+	sim := NewSimT(t, e)
+	defer sim.Close()
+
+	sim.SetClipboardSupported(false)
+	assert.False(t, sim.ClipboardSupported())
+	assert.IsType(t, gowid.ClipboardNotSupportedError{}, e.CopyToClipboard(sim))
+	assert.Equal(t, "", sim.ClipboardContents())
+
+	sim.SetClipboardSupported(true)
+	assert.NoError(t, e.CopyToClipboard(sim))
+	assert.Equal(t, "secret", sim.ClipboardContents())
+
+	err := sim.CopyToClipboard(strings.Repeat("x", gowid.OSC52MaxBytes+1))
+	assert.IsType(t, gowid.ClipboardTooLargeError{}, err)
+	assert.Equal(t, "secret", sim.ClipboardContents())
+
+	// Masked text is not copied
+	pw := edit.New(edit.Options{Text: "hunter2", Mask: edit.MakeMask('*')})
+	assert.Equal(t, edit.MaskedCopyError{}, pw.CopyToClipboard(sim))
+	assert.Equal(t, "secret", sim.ClipboardContents())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,62 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/styled"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorDowngrade1(t *testing.T) {
+	orange := tcell.NewRGBColor(0xff, 0x87, 0x00)
+	hot := styled.New(text.New("hot"), gowid.MakeForeground(gowid.MakeTCellColorExt(orange)))
+	subtle := styled.New(text.New("sub"), gowid.MakePaletteRef("subtle"))
+	view := pile.NewFlow(hot, subtle)
+
+	sim := NewSimT(t, view, SimOptions{Cols: 4, Rows: 2, Palette: gowid.Palette{
+		"subtle": gowid.MakeBackground(gowid.MakeRGBColor("#112")),
+	}})
+	defer sim.Close()
+
+	fg := func(y int) tcell.Color {
+		_, st := sim.Cell(0, y)
+		fg, _, _ := st.Decompose()
+		return fg
+	}
+	bg := func(y int) tcell.Color {
+		_, st := sim.Cell(0, y)
+		_, bg, _ := st.Decompose()
+		return bg
+	}
+
+	// The sim's terminal shows 256 colors, so 24-bit colors are drawn as the nearest of those
+	assert.Equal(t, gowid.Mode256Colors, sim.GetColorMode())
+	assert.Equal(t, tcell.Color208, fg(0))
+
+	sim.SetColorDowngrade(gowid.ColorDowngradeOptions{Strategy: gowid.QuantizeNone})
+	sim.Frame()
+	assert.Equal(t, orange, fg(0))
+
+	// On a 16-color terminal, the override replaces the entry that would be lost
+	sim.SetColorMode(gowid.Mode16Colors)
+	sim.SetColorDowngrade(gowid.ColorDowngradeOptions{
+		Overrides: map[gowid.ColorMode]gowid.Palette{
+			gowid.Mode16Colors: {"subtle": gowid.MakeBackground(gowid.ColorBlue)},
+		},
+	})
+	sim.Frame()
+	assert.True(t, fg(0) < 16)
+	assert.Equal(t, tcell.ColorBlue, bg(1))
+
+	sim.SetColorMode(gowid.Mode256Colors)
+	sim.Redraw()
+	sim.Frame()
+	assert.NotEqual(t, tcell.ColorBlue, bg(1))
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxKey struct{}
+
+func TestMainLoopCtx1(t *testing.T) {
+	logger := log.New()
+	logger.Out = ioutil.Discard
+	app, err := gowid.NewApp(gowid.AppArgs{
+		View:   text.New("hello"),
+		Log:    logger,
+		Screen: tcell.NewSimulationScreen(""),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	early := app.Context()
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "server"))
+	res := make(chan error, 1)
+	go func() {
+		res <- app.MainLoopCtx(ctx, gowid.IgnoreUnhandledInput)
+	}()
+
+	// Run callbacks see the loop's context, and its values
+	seen := make(chan interface{}, 1)
+	app.Run(gowid.RunContextFunction(func(ctx context.Context, app gowid.IApp) {
+		seen <- ctx.Value(ctxKey{})
+	}))
+	assert.Equal(t, "server", <-seen)
+
+	stopped := make(chan struct{})
+	app.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	cancel()
+	select {
+	case err := <-res:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("main loop did not end")
+	}
+	<-stopped
+	<-early.Done()
+	assert.Equal(t, gowid.AppClosingErr, app.Run(gowid.RunFunction(func(app gowid.IApp) {})))
+}
@@ -0,0 +1,34 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrashReportScreen1(t *testing.T) {
+	e := edit.New()
+	sim := NewSimT(t, e, SimOptions{Cols: 4, Rows: 1})
+	defer sim.Close()
+
+	assert.Equal(t, "", sim.CrashReport("test").Screen)
+
+	sim.EnableCrashReports(gowid.CrashReportOptions{})
+	sim.Type("ab")
+	assert.Equal(t, "ab  ", sim.CrashReport("test").Screen)
+
+	// The report has the last frame, not the one before
+	sim.Type("c")
+	assert.Equal(t, "abc ", sim.CrashReport("test").Screen)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,95 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+// dragBox records the drag events it sees, and accepts drags if it is a source.
+type dragBox struct {
+	*text.Widget
+	name   string
+	source bool
+	events []string
+}
+
+func (w *dragBox) Selectable() bool {
+	return true
+}
+
+func (w *dragBox) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	dev, ok := gowid.DragEventFor(ev, app)
+	if !ok {
+		return false
+	}
+	w.events = append(w.events, fmt.Sprintf("%v %d,%d %v", dev.Phase, dev.X, dev.Y, dev.Payload))
+	if dev.Phase == gowid.DragStart && w.source {
+		dev.Accept(w, w.name)
+	}
+	return dev.Phase == gowid.Drop && !w.source
+}
+
+func (w *dragBox) DragMoved(d *gowid.Drag, app gowid.IApp) {
+	dx, dy := d.Delta()
+	w.events = append(w.events, fmt.Sprintf("moved %d,%d", dx, dy))
+}
+
+func (w *dragBox) DragEnded(d *gowid.Drag, app gowid.IApp) {
+	w.events = append(w.events, fmt.Sprintf("ended %v %v", d.Dropped, d.Cancelled))
+}
+
+func TestDrag1(t *testing.T) {
+	src := &dragBox{Widget: text.New("src"), name: "src", source: true}
+	dst := &dragBox{Widget: text.New("dst"), name: "dst"}
+	root := columns.NewFixed(src, text.New(" "), dst)
+
+	sim := NewSimT(t, root, SimOptions{Cols: 8, Rows: 1})
+	defer sim.Close()
+
+	sim.Drag(1, 0, 5, 0, tcell.Button1)
+	assert.Equal(t, []string{"drag-start 1,0 <nil>", "moved 4,0", "ended true false"}, src.events)
+	assert.Equal(t, []string{"drag-over 1,0 src", "drop 1,0 src"}, dst.events)
+	d, phase := sim.CurrentDrag()
+	assert.Nil(t, d)
+	assert.Equal(t, gowid.DragNone, phase)
+
+	// A press and release without movement is not a drag
+	src.events, dst.events = nil, nil
+	sim.Click(1, 0, tcell.Button1)
+	assert.Empty(t, src.events)
+
+	// Esc cancels a drag
+	sim.Mouse(0, 0, tcell.Button1)
+	sim.Mouse(4, 0, tcell.Button1)
+	sim.Key(tcell.KeyEsc)
+	assert.Equal(t, []string{"drag-start 0,0 <nil>", "moved 4,0", "ended false true"}, src.events)
+	sim.Mouse(4, 0, tcell.ButtonNone)
+	assert.Equal(t, []string{"drag-over 0,0 src"}, dst.events)
+}
+
+func TestDrag2(t *testing.T) {
+	// A drag that no widget accepts still clicks a button it is released on
+	clicks := 0
+	btn := button.New(text.New("b"))
+	btn.OnClick(gowid.MakeWidgetCallback("cb", func(app gowid.IApp, w gowid.IWidget) {
+		clicks++
+	}))
+	sim := NewSimT(t, btn, SimOptions{Cols: 3, Rows: 1})
+	defer sim.Close()
+
+	sim.Drag(0, 0, 2, 0, tcell.Button1)
+	assert.Equal(t, 1, clicks)
+
+	sim.Click(1, 0, tcell.Button1)
+	assert.Equal(t, 2, clicks)
+}
@@ -0,0 +1,121 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/list"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/selectable"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFind1(t *testing.T) {
+	p := pile.NewFlow(text.New("one two"), text.New("two three"))
+	sim := NewSimT(t, p, SimOptions{Cols: 16, Rows: 3})
+	defer sim.Close()
+
+	sim.EnableFind(gowid.FindOptions{
+		Key: gowid.MakeKey('/'),
+	})
+
+	sim.Rune('/')
+	sim.Type("two")
+	sim.AssertLine(t, 2, "Find: two   1/2 ")
+
+	ms, cur := sim.FindMatches()
+	assert.Equal(t, []gowid.FindMatch{{4, 0, 3}, {0, 1, 3}}, ms)
+	assert.Equal(t, 0, cur)
+
+	_, st := sim.Cell(4, 0)
+	_, bg, _ := st.Decompose()
+	assert.Equal(t, tcell.ColorYellow, bg)
+	_, st = sim.Cell(0, 1)
+	_, _, attrs := st.Decompose()
+	assert.NotEqual(t, 0, attrs&tcell.AttrReverse)
+
+	sim.Key(tcell.KeyEnter)
+	_, cur = sim.FindMatches()
+	assert.Equal(t, 1, cur)
+
+	// Wrap around
+	sim.Key(tcell.KeyEnter)
+	_, cur = sim.FindMatches()
+	assert.Equal(t, 0, cur)
+
+	sim.Key(tcell.KeyBackspace2)
+	assert.Equal(t, "tw", sim.FindQuery())
+
+	sim.Key(tcell.KeyEscape)
+	sim.AssertLine(t, 2, "                ")
+	ms, cur = sim.FindMatches()
+	assert.Equal(t, 0, len(ms))
+	assert.Equal(t, -1, cur)
+}
+
+func TestFind2(t *testing.T) {
+	ws := make([]gowid.IWidget, 0)
+	for i := 0; i < 20; i++ {
+		ws = append(ws, selectable.New(text.New(fmt.Sprintf("row%02d", i))))
+	}
+	lb := list.New(list.NewSimpleListWalker(ws))
+	sim := NewSimT(t, lb, SimOptions{Cols: 6, Rows: 3})
+	defer sim.Close()
+
+	sim.SetFindQuery("row1")
+	sim.RedrawTerminal()
+	ms, _ := sim.FindMatches()
+	assert.Equal(t, 0, len(ms))
+
+	// Nothing on screen, so the list is searched
+	sim.FindNext()
+	sim.RedrawTerminal()
+	sim.AssertLine(t, 0, "row10 ")
+	assert.Equal(t, 10, lb.Walker().Focus().(list.ListPos).ToInt())
+	ms, cur := sim.FindMatches()
+	assert.Equal(t, 3, len(ms))
+	assert.Equal(t, 0, cur)
+
+	sim.FindNext()
+	sim.FindNext()
+	sim.FindNext()
+	sim.RedrawTerminal()
+	sim.AssertLine(t, 0, "row13 ")
+
+	sim.FindPrevious()
+	sim.RedrawTerminal()
+	sim.AssertLine(t, 2, "row12 ")
+	_, cur = sim.FindMatches()
+	assert.Equal(t, 2, cur)
+}
+
+func TestFind3(t *testing.T) {
+	p := pile.NewFlow(
+		selectable.New(text.New("one x")),
+		selectable.New(text.New("two")),
+		selectable.New(text.New("three x")),
+	)
+	sim := NewSimT(t, p, SimOptions{Cols: 8, Rows: 4})
+	defer sim.Close()
+
+	sim.SetFindQuery("x")
+	sim.RedrawTerminal()
+	assert.Equal(t, 0, p.Focus())
+
+	// The selected match is in the third row, so it takes the focus
+	sim.FindNext()
+	_, cur := sim.FindMatches()
+	assert.Equal(t, 1, cur)
+	assert.Equal(t, 2, p.Focus())
+
+	sim.FindNext()
+	_, cur = sim.FindMatches()
+	assert.Equal(t, 0, cur)
+	assert.Equal(t, 0, p.Focus())
+}
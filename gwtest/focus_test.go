@@ -0,0 +1,112 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func focusPath(ints ...int) []interface{} {
+	res := make([]interface{}, 0)
+	for _, i := range ints {
+		res = append(res, i)
+	}
+	return res
+}
+
+func TestFocusTraversal1(t *testing.T) {
+	a := button.NewBare(text.New("a"))
+	b := button.NewBare(text.New("b"))
+	c := button.NewBare(text.New("c"))
+	d := button.NewBare(text.New("d"))
+	top := columns.NewFixed(a, text.New(" "), b)
+	bottom := pile.NewFlow(c, d)
+	root := pile.NewFlow(top, text.New("--"), bottom)
+
+	assert.Equal(t, [][]interface{}{focusPath(0, 0), focusPath(0, 2), focusPath(2, 0), focusPath(2, 1)}, gowid.FocusStops(root))
+
+	sim := NewSimT(t, root, SimOptions{Cols: 4, Rows: 5})
+	defer sim.Close()
+	assert.Equal(t, focusPath(0, 0), gowid.FocusPath(root))
+
+	// Not enabled, so Tab does nothing
+	sim.Key(tcell.KeyTab)
+	assert.Equal(t, focusPath(0, 0), gowid.FocusPath(root))
+
+	sim.EnableFocusTraversal()
+	sim.Key(tcell.KeyTab)
+	assert.Equal(t, focusPath(0, 2), gowid.FocusPath(root))
+	sim.Key(tcell.KeyTab)
+	assert.Equal(t, focusPath(2, 0), gowid.FocusPath(root))
+	sim.Key(tcell.KeyTab)
+	assert.Equal(t, focusPath(2, 1), gowid.FocusPath(root))
+	sim.Key(tcell.KeyTab)
+	assert.Equal(t, focusPath(0, 0), gowid.FocusPath(root))
+	sim.Key(tcell.KeyBacktab)
+	assert.Equal(t, focusPath(2, 1), gowid.FocusPath(root))
+
+	assert.False(t, sim.NextFocus(false))
+	assert.True(t, sim.PrevFocus(false))
+	assert.Equal(t, focusPath(2, 0), gowid.FocusPath(root))
+
+	assert.True(t, gowid.FocusOn(root, b, sim))
+	assert.Equal(t, focusPath(0, 2), gowid.FocusPath(root))
+	path, ok := gowid.FindFocusPath(root, d)
+	assert.True(t, ok)
+	assert.Equal(t, focusPath(2, 1), path)
+	_, ok = gowid.FindFocusPath(root, button.NewBare(text.New("x")))
+	assert.False(t, ok)
+}
+
+func TestFocusGroup1(t *testing.T) {
+	a := button.NewBare(text.New("a"))
+	b := button.NewBare(text.New("b"))
+	c := button.NewBare(text.New("c"))
+	d := button.NewBare(text.New("d"))
+	e := button.NewBare(text.New("e"))
+	radios := gowid.NewFocusGroup(columns.NewFixed(a, b), gowid.FocusGroupOptions{Single: true})
+	dialog := gowid.NewFocusGroup(pile.NewFlow(c, d), gowid.FocusGroupOptions{Trap: true})
+	root := pile.NewFlow(radios, dialog, e)
+
+	assert.Equal(t, [][]interface{}{focusPath(0), focusPath(1, 0), focusPath(1, 1), focusPath(2)}, gowid.FocusStops(root))
+
+	sim := NewSimT(t, root, SimOptions{Cols: 4, Rows: 4})
+	defer sim.Close()
+	sim.EnableFocusTraversal(gowid.FocusTraversalOptions{Next: gowid.MakeKey('n'), NoWrap: true})
+
+	// The group is a single stop, and keeps its own focus
+	assert.True(t, gowid.FocusOn(root, b, sim))
+	sim.Rune('n')
+	assert.Equal(t, focusPath(1, 0), gowid.FocusPath(root))
+	assert.True(t, gowid.FocusOn(root, a, sim))
+	assert.True(t, gowid.FocusOn(root, b, sim))
+
+	// Focus is trapped in the dialog
+	assert.True(t, gowid.FocusOn(root, c, sim))
+	sim.Rune('n')
+	assert.Equal(t, focusPath(1, 1), gowid.FocusPath(root))
+	sim.Rune('n')
+	assert.Equal(t, focusPath(1, 1), gowid.FocusPath(root))
+	assert.True(t, sim.NextFocus(true))
+	assert.Equal(t, focusPath(1, 0), gowid.FocusPath(root))
+
+	// But not outside it
+	assert.True(t, gowid.FocusOn(root, e, sim))
+	assert.True(t, sim.NextFocus(true))
+	assert.Equal(t, focusPath(0, 1), gowid.FocusPath(root))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
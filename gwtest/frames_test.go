@@ -0,0 +1,136 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/text"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxFPS1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := edit.New()
+	sim := NewSimT(t, e, SimOptions{Cols: 6, Rows: 1, Clock: clock})
+	defer sim.Close()
+
+	sim.SetMaxFPS(10)
+	assert.Equal(t, 10, sim.MaxFPS())
+
+	// The first frame is drawn straight away
+	sim.Rune('a')
+	sim.AssertLine(t, 0, "a     ")
+
+	// Later requests in the same frame interval are coalesced
+	sim.Rune('b')
+	sim.Rune('c')
+	for i := 0; i < 5; i++ {
+		sim.Run(gowid.RunFunction(func(app gowid.IApp) {}))
+	}
+	sim.Frame()
+	sim.AssertLine(t, 0, "a     ")
+	assert.Equal(t, "abc", e.Text())
+
+	clock.Advance(50 * time.Millisecond)
+	sim.Frame()
+	sim.AssertLine(t, 0, "a     ")
+
+	clock.Advance(50 * time.Millisecond)
+	sim.Frame()
+	sim.AssertLine(t, 0, "abc   ")
+
+	req, drawn := sim.FrameStats()
+	assert.Equal(t, 8, req)
+	assert.Equal(t, 2, drawn)
+
+	// Removing the limit draws anything pending
+	sim.Rune('d')
+	sim.SetMaxFPS(0)
+	sim.AssertLine(t, 0, "abcd  ")
+	sim.Rune('e')
+	sim.AssertLine(t, 0, "abcde ")
+}
+
+func TestAdaptiveRedraw1(t *testing.T) {
+	e := edit.New()
+	sim := NewSimT(t, e, SimOptions{Cols: 6, Rows: 1})
+	defer sim.Close()
+
+	// With a fast terminal, every redraw is drawn at once
+	sim.EnableAdaptiveRedraw()
+	assert.Equal(t, 0, sim.MaxFPS())
+	assert.Equal(t, time.Duration(0), sim.FrameInterval())
+	sim.Rune('a')
+	sim.Rune('b')
+	sim.AssertLine(t, 0, "ab    ")
+	_, drawn := sim.FrameStats()
+	assert.Equal(t, 2, drawn)
+
+	// The frame rate limit survives adaptive redraw being switched on and off
+	sim.SetMaxFPS(10)
+	sim.DisableAdaptiveRedraw()
+	assert.Equal(t, 10, sim.MaxFPS())
+	sim.EnableAdaptiveRedraw()
+	assert.Equal(t, 10, sim.MaxFPS())
+	assert.Equal(t, 100*time.Millisecond, sim.FrameInterval())
+
+	sim.SetMaxFPS(0)
+	sim.DisableAdaptiveRedraw()
+	assert.Equal(t, time.Duration(0), sim.FrameInterval())
+	sim.Rune('c')
+	sim.AssertLine(t, 0, "abc   ")
+}
+
+// slowScreen is a terminal that takes 100ms to show each frame, and remembers what it showed.
+type slowScreen struct {
+	*simScreen
+	clock *gowid.FakeClock
+	shown []string
+}
+
+func (s *slowScreen) Show() {
+	s.simScreen.Show()
+	s.shown = append(s.shown, gowid.SnapshotScreen(s).Text())
+	s.clock.Advance(100 * time.Millisecond)
+}
+
+func TestAdaptiveRedraw2(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	screen := &slowScreen{simScreen: newSimScreen(8, 1), clock: clock}
+	e := edit.New()
+	status := text.New("----")
+	logger := log.New()
+	logger.Out = ioutil.Discard
+	app, err := gowid.NewApp(gowid.AppArgs{
+		View: columns.New([]gowid.IContainerWidget{
+			&gowid.ContainerWidget{IWidget: e, D: gowid.RenderWithUnits{U: 4}},
+			&gowid.ContainerWidget{IWidget: status, D: gowid.RenderWithUnits{U: 4}},
+		}),
+		Log:            logger,
+		Clock:          clock,
+		Screen:         screen,
+		AdaptiveRedraw: true,
+	})
+	assert.NoError(t, err)
+	screen.SetSize(8, 1)
+
+	// While the terminal is fast, each frame is shown at once
+	app.RedrawTerminal()
+	assert.Equal(t, []string{"    ----"}, screen.shown)
+
+	// Once it's slow, the widget in focus is shown first
+	app.RedrawTerminal()
+	screen.shown = nil
+	e.SetText("ab", app)
+	status.SetText("++++", app)
+	app.RedrawTerminal()
+	assert.Equal(t, []string{"ab  ----", "ab  ++++"}, screen.shown)
+}
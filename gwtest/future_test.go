@@ -0,0 +1,76 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuture1(t *testing.T) {
+	txt := text.New("hello")
+	sim := NewSimT(t, txt, SimOptions{Cols: 8, Rows: 1})
+	defer sim.Close()
+
+	fut := sim.RunFuture(func(app gowid.IApp) (interface{}, error) {
+		txt.SetText("world", app)
+		return txt.Content().String(), nil
+	})
+	_, _, ok := fut.Result()
+	assert.False(t, ok)
+
+	sim.Frame()
+	val, err, ok := fut.Result()
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, "world", val)
+	sim.AssertLine(t, 0, "world   ")
+
+	fut = sim.RunFuture(func(app gowid.IApp) (interface{}, error) {
+		panic("oops")
+	})
+	sim.Frame()
+	_, err = fut.Wait()
+	assert.Equal(t, gowid.FuturePanicError{Value: "oops"}, err)
+
+	// RunSync from another goroutine, while this one plays the main loop
+	resCh := make(chan interface{})
+	go func() {
+		v, _ := sim.RunSync(func(app gowid.IApp) (interface{}, error) {
+			return txt.Content().Length(), nil
+		})
+		resCh <- v
+	}()
+	var res interface{}
+Loop:
+	for {
+		select {
+		case res = <-resCh:
+			break Loop
+		default:
+			sim.Frame()
+		}
+	}
+	assert.Equal(t, 5, res)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	fut = sim.RunFuture(func(app gowid.IApp) (interface{}, error) {
+		return nil, fmt.Errorf("not reached")
+	})
+	_, err = fut.WaitContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	sim.Quit()
+	_, err = sim.RunSync(func(app gowid.IApp) (interface{}, error) {
+		return nil, nil
+	})
+	assert.Equal(t, gowid.AppClosingErr, err)
+}
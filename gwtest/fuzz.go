@@ -0,0 +1,117 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gwtest
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/gcla/gowid"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+var fuzzKeys []tcell.Key
+
+var fuzzButtons = []tcell.ButtonMask{
+	tcell.ButtonNone,
+	tcell.Button1,
+	tcell.Button2,
+	tcell.Button3,
+	tcell.WheelUp,
+	tcell.WheelDown,
+}
+
+var fuzzMods = []tcell.ModMask{
+	tcell.ModNone,
+	tcell.ModShift,
+	tcell.ModCtrl,
+	tcell.ModAlt,
+}
+
+func init() {
+	for k := range tcell.KeyNames {
+		fuzzKeys = append(fuzzKeys, k)
+	}
+	sort.Slice(fuzzKeys, func(i, j int) bool {
+		return fuzzKeys[i] < fuzzKeys[j]
+	})
+}
+
+// DecodeFuzzInput turns an arbitrary byte sequence into a sequence of tcell
+// events. Every input decodes successfully, and similar inputs decode to similar
+// events, which suits coverage-guided fuzzers like go-fuzz. Each event starts
+// with an opcode byte:
+//
+// - 0: a key press of the UTF-8 rune that follows, or of a printable ASCII character
+// - 1: a key press of a special key, like KeyUp, with a modifier
+// - 2: a mouse event at a position, with a button mask and modifier
+// - 3: a terminal resize
+//
+// Coordinates and sizes are taken modulo the supplied maximum columns and rows.
+func DecodeFuzzInput(data []byte, cols, rows int) []tcell.Event {
+	res := make([]tcell.Event, 0)
+	next := func() int {
+		if len(data) == 0 {
+			return 0
+		}
+		b := data[0]
+		data = data[1:]
+		return int(b)
+	}
+	for len(data) > 0 {
+		switch next() % 4 {
+		case 0:
+			r, n := utf8.DecodeRune(data)
+			if r == utf8.RuneError || n == 0 {
+				r = rune(' ' + next()%('~'-' '+1))
+			} else {
+				data = data[n:]
+			}
+			res = append(res, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+		case 1:
+			k := fuzzKeys[next()%len(fuzzKeys)]
+			m := fuzzMods[next()%len(fuzzMods)]
+			res = append(res, tcell.NewEventKey(k, 0, m))
+		case 2:
+			x := next() % cols
+			y := next() % rows
+			b := fuzzButtons[next()%len(fuzzButtons)]
+			m := fuzzMods[next()%len(fuzzMods)]
+			res = append(res, tcell.NewEventMouse(x, y, b, m))
+		case 3:
+			c := 1 + next()%cols
+			r := 1 + next()%rows
+			res = append(res, tcell.NewEventResize(c, r))
+		}
+	}
+	return res
+}
+
+// RunFuzzInput builds a Sim, displaying w on a simulated terminal of the given
+// size, and dispatches to it the events decoded from data via DecodeFuzzInput.
+// Panics in widgets are not recovered, so that a fuzzer can report them. An
+// error is returned only if the Sim cannot be constructed.
+func RunFuzzInput(data []byte, w gowid.IWidget, cols, rows int) error {
+	sim, err := NewSim(w, SimOptions{Cols: cols, Rows: rows})
+	if err != nil {
+		return err
+	}
+	defer sim.Close()
+
+	for _, ev := range DecodeFuzzInput(data, cols, rows) {
+		if !sim.Event(ev) {
+			break
+		}
+	}
+	return nil
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
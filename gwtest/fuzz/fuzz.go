@@ -0,0 +1,28 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+//go:build gofuzz
+// +build gofuzz
+
+package fuzz
+
+import (
+	"github.com/gcla/gowid/gwtest"
+)
+
+//======================================================================
+
+// Fuzz is the go-fuzz entry point.
+func Fuzz(data []byte) int {
+	if err := gwtest.RunFuzzInput(data, NewTree(), Cols, Rows); err != nil {
+		panic(err)
+	}
+	return 0
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,40 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gcla/gowid/gwtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzSeeds1(t *testing.T) {
+	seeds := [][]byte{
+		{},
+		[]byte("\x00a\x00b\x00c"),
+		{1, 0, 0, 1, 5, 1, 2, 10, 5, 1, 0, 3, 40, 20},
+		{2, 3, 25, 1, 0, 2, 3, 25, 0, 0, 2, 3, 25, 4, 0},
+		{3, 0, 0, 3, 200, 200, 1, 7, 0},
+	}
+	for _, seed := range seeds {
+		assert.NoError(t, gwtest.RunFuzzInput(seed, NewTree(), Cols, Rows))
+	}
+}
+
+func TestFuzzRandom1(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		data := make([]byte, 200)
+		rnd.Read(data)
+		assert.NoError(t, gwtest.RunFuzzInput(data, NewTree(), Cols, Rows))
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,98 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package fuzz provides an entry point for fuzzing gowid's input handling with
+// go-fuzz. It dispatches decoded key, mouse and resize events to a widget
+// hierarchy built from many of the stock widgets. To run:
+//
+//   go-fuzz-build github.com/gcla/gowid/gwtest/fuzz
+//   go-fuzz -bin=fuzz-fuzz.zip -workdir=workdir
+//
+package fuzz
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/checkbox"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/divider"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/framed"
+	"github.com/gcla/gowid/widgets/list"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/progress"
+	"github.com/gcla/gowid/widgets/radio"
+	"github.com/gcla/gowid/widgets/table"
+	"github.com/gcla/gowid/widgets/text"
+)
+
+//======================================================================
+
+// Cols and Rows are the maximum dimensions of the simulated terminal.
+const (
+	Cols = 80
+	Rows = 30
+)
+
+// NewTree returns a fresh widget hierarchy that exercises a representative
+// set of widgets - editing, buttons, lists, tables and nested containers.
+func NewTree() gowid.IWidget {
+	e1 := edit.New(edit.Options{Caption: "Name: ", Text: "gowid"})
+	e2 := edit.New(edit.Options{Caption: "Notes: ", Text: "line one\nline two"})
+
+	group := []radio.IWidget{}
+	r1 := radio.New(&group)
+	r2 := radio.New(&group)
+
+	cb := checkbox.New(false)
+	pb := progress.New(progress.Options{
+		Normal:   gowid.MakePaletteEntry(gowid.ColorWhite, gowid.ColorBlack),
+		Complete: gowid.MakePaletteEntry(gowid.ColorBlack, gowid.ColorWhite),
+		Current:  3,
+		Target:   10,
+	})
+
+	b := button.New(text.New("Press"))
+	b.OnClick(gowid.WidgetCallback{"cb", func(app gowid.IApp, w gowid.IWidget) {
+		pb.SetProgress(app, (pb.Progress()+1)%(pb.Target()+1))
+	}})
+
+	buttons := columns.NewFixed(b, text.New(" "), cb, text.New(" "), r1, r2)
+
+	ws := make([]gowid.IWidget, 0)
+	for i := 0; i < 30; i++ {
+		ws = append(ws, button.New(text.New(fmt.Sprintf("Item %d %s", i, strings.Repeat("=", i)))))
+	}
+	lb := list.New(list.NewSimpleListWalker(ws))
+
+	headers := []string{"one", "two", "three"}
+	data := [][]string{}
+	for i := 0; i < 20; i++ {
+		data = append(data, []string{fmt.Sprintf("%d", i), "abc", "the quick brown fox"})
+	}
+	tb := table.New(table.NewSimpleModel(headers, data))
+
+	body := columns.New([]gowid.IContainerWidget{
+		&gowid.ContainerWidget{IWidget: framed.New(lb), D: gowid.RenderWithWeight{W: 1}},
+		&gowid.ContainerWidget{IWidget: framed.New(tb), D: gowid.RenderWithWeight{W: 2}},
+	})
+
+	return pile.New([]gowid.IContainerWidget{
+		&gowid.ContainerWidget{IWidget: e1, D: gowid.RenderFlow{}},
+		&gowid.ContainerWidget{IWidget: e2, D: gowid.RenderFlow{}},
+		&gowid.ContainerWidget{IWidget: divider.NewUnicode(), D: gowid.RenderFlow{}},
+		&gowid.ContainerWidget{IWidget: buttons, D: gowid.RenderFlow{}},
+		&gowid.ContainerWidget{IWidget: pb, D: gowid.RenderFlow{}},
+		&gowid.ContainerWidget{IWidget: body, D: gowid.RenderWithWeight{W: 1}},
+	})
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,85 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gwtest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gcla/gowid"
+)
+
+//======================================================================
+
+// GoldenDir is the directory holding golden files, relative to the package being tested.
+var GoldenDir = "testdata"
+
+// UpdateGoldenEnv is the environment variable that, if set to anything but "", makes AssertGolden write
+// the snapshot it is given to the golden file, rather than compare with it - to create the file, or
+// accept a change in the way a widget looks:
+//
+//    GOWID_UPDATE_GOLDEN=1 go test ./...
+//
+// Review the changes to the golden files before committing them.
+const UpdateGoldenEnv = "GOWID_UPDATE_GOLDEN"
+
+// GoldenPath returns the path of the golden file with the given name.
+func GoldenPath(name string) string {
+	return filepath.Join(GoldenDir, name+".golden")
+}
+
+// AssertGolden checks that the snapshot matches the golden file with the given name, which holds a
+// snapshot in the format of gowid.Snapshot.Annotated(). If not, the test fails, showing the rows that
+// differ, and marking the characters and styles that changed - see gowid.DiffSnapshots. The diff is
+// colored unless the NO_COLOR environment variable is set.
+func AssertGolden(t *testing.T, name string, snap *gowid.Snapshot) bool {
+	t.Helper()
+	path := GoldenPath(name)
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Could not create directory for golden file: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(snap.Annotated()), 0644); err != nil {
+			t.Fatalf("Could not write golden file: %v", err)
+		}
+		return true
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("Could not read golden file - run with %s=1 to create it: %v", UpdateGoldenEnv, err)
+		return false
+	}
+	want, err := gowid.ParseSnapshot(string(data))
+	if err != nil {
+		t.Errorf("Could not parse golden file %s: %v", path, err)
+		return false
+	}
+	if diff := gowid.DiffSnapshots(want, snap, os.Getenv("NO_COLOR") == ""); diff != "" {
+		t.Errorf("Snapshot does not match golden file %s - run with %s=1 to update it:\n%s",
+			path, UpdateGoldenEnv, diff)
+		return false
+	}
+	return true
+}
+
+// AssertGoldenWidget renders w as gowid.RenderSnapshot() does, then checks it with AssertGolden.
+func AssertGoldenWidget(t *testing.T, name string, w gowid.IWidget, cols, rows int, palette gowid.IPalette) bool {
+	t.Helper()
+	return AssertGolden(t, name, gowid.RenderSnapshot(w, cols, rows, palette))
+}
+
+// AssertGolden checks the screen with AssertGolden.
+func (s *Sim) AssertGolden(t *testing.T, name string) bool {
+	t.Helper()
+	return AssertGolden(t, name, s.Snapshot())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,58 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/styled"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/stretchr/testify/assert"
+)
+
+func goldenPalette() gowid.Palette {
+	return gowid.Palette{
+		"warn":  gowid.MakeStyledPaletteEntry(gowid.ColorRed, gowid.ColorNone, gowid.StyleBold),
+		"input": gowid.MakePaletteEntry(gowid.ColorBlack, gowid.MakeRGBColor("#123")),
+	}
+}
+
+func TestGolden1(t *testing.T) {
+	w := pile.NewFlow(
+		styled.New(text.New("Careful!"), gowid.MakePaletteRef("warn")),
+		button.New(text.New("Quit")),
+	)
+	AssertGoldenWidget(t, "warning", w, 10, 3, goldenPalette())
+
+	e := edit.New(edit.Options{Caption: "> ", Text: "abc"})
+	sim := NewSimT(t, styled.New(e, gowid.MakePaletteRef("input")), SimOptions{Cols: 8, Rows: 1, Palette: goldenPalette()})
+	defer sim.Close()
+	sim.AssertGolden(t, "edit")
+}
+
+func TestGolden2(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gowid-golden")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	defer func(old string) {
+		GoldenDir = old
+	}(GoldenDir)
+	GoldenDir = dir
+
+	w := text.New("new")
+	os.Setenv(UpdateGoldenEnv, "1")
+	AssertGoldenWidget(t, "new", w, 3, 1, nil)
+	os.Unsetenv(UpdateGoldenEnv)
+
+	data, err := ioutil.ReadFile(GoldenPath("new"))
+	assert.NoError(t, err)
+	assert.Equal(t, "gowid snapshot 3x1\n|new|\n|aaa|\na default/default\n", string(data))
+	AssertGoldenWidget(t, "new", w, 3, 1, nil)
+}
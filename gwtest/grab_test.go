@@ -0,0 +1,82 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+type grabbingText struct {
+	*text.Widget
+	events []string
+}
+
+func (w *grabbingText) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		w.events = append(w.events, fmt.Sprintf("key %v", ev.Name()))
+	case *tcell.EventMouse:
+		if ev.Buttons() == tcell.ButtonNone {
+			break
+		}
+		x, y := ev.Position()
+		w.events = append(w.events, fmt.Sprintf("mouse %d,%d", x, y))
+	}
+	return true
+}
+
+func TestGrabInput1(t *testing.T) {
+	e := edit.New()
+	menu := &grabbingText{Widget: text.New("menu")}
+	p := pile.NewFlow(e, menu)
+	sim := NewSimT(t, p, SimOptions{Cols: 6, Rows: 3})
+	defer sim.Close()
+
+	released := 0
+	sim.GrabInput(menu, gowid.GrabOptions{
+		OnRelease: func(app gowid.IApp) { released++ },
+	})
+	assert.Equal(t, menu, sim.InputGrabbedBy())
+
+	// Keys go to the menu, not the edit in focus; mouse events are in the menu's coordinates
+	sim.Rune('a')
+	sim.Click(2, 1, tcell.Button1)
+	assert.Equal(t, "", e.Text())
+	assert.Equal(t, []string{"key Rune[a]", "mouse 2,0"}, menu.events)
+
+	// Esc releases, and is consumed
+	sim.Key(tcell.KeyEscape)
+	assert.Nil(t, sim.InputGrabbedBy())
+	assert.Equal(t, 1, released)
+	sim.Rune('b')
+	assert.Equal(t, "b", e.Text())
+
+	// A click outside releases, and is passed on if asked
+	sim.GrabInput(menu, gowid.GrabOptions{PassClickOutside: true})
+	sim.Click(3, 0, tcell.Button1)
+	assert.Nil(t, sim.InputGrabbedBy())
+	assert.Equal(t, 2, len(menu.events))
+
+	sim.GrabInput(menu, gowid.GrabOptions{NoEscRelease: true, NoClickOutsideRelease: true})
+	sim.Key(tcell.KeyEscape)
+	sim.Click(3, 2, tcell.Button1)
+	assert.Equal(t, menu, sim.InputGrabbedBy())
+	assert.Equal(t, []string{"key Rune[a]", "mouse 2,0", "key Esc", "mouse 3,1"}, menu.events)
+
+	// The grab goes when the widget is no longer drawn
+	assert.True(t, gowid.ReleaseInput(sim))
+	assert.True(t, gowid.GrabInput(sim, menu))
+	p.SetSubWidgets([]gowid.IWidget{e}, sim)
+	sim.Rune('c')
+	assert.Nil(t, sim.InputGrabbedBy())
+	assert.Equal(t, "bc", e.Text())
+}
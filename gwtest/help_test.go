@@ -0,0 +1,63 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelp1(t *testing.T) {
+	name := edit.New(edit.Options{Caption: "Name: "})
+	email := edit.New(edit.Options{Caption: "Email: "})
+	form := gowid.NewHelp(pile.NewFlow(gowid.NewHelp(name, "Your full name"), email), "Fill in the form")
+	sim := NewSimT(t, form, SimOptions{Cols: 30, Rows: 8})
+	defer sim.Close()
+
+	text, from, ok := gowid.FindHelp(form, sim)
+	assert.True(t, ok)
+	assert.Equal(t, "Your full name", text)
+	assert.NotEqual(t, form, from)
+
+	// Without help enabled, F1 is just a key
+	sim.Key(tcell.KeyF1)
+	assert.False(t, sim.HelpShown())
+
+	sim.SetHelp(&gowid.HelpOptions{})
+	sim.Key(tcell.KeyF1)
+	assert.True(t, sim.HelpShown())
+	// Beneath the name
+	sim.AssertLine(t, 1, "┌────────────────┐            ")
+	sim.AssertLine(t, 2, "│ Your full name │            ")
+	sim.AssertLine(t, 3, "└────────────────┘            ")
+	sim.Key(tcell.KeyF1)
+	assert.False(t, sim.HelpShown())
+	sim.AssertLine(t, 1, "Email:                        ")
+
+	// The email has no help of its own, so the form's is shown - in the middle, since the form fills the
+	// screen
+	sim.Key(tcell.KeyDown)
+	sim.Key(tcell.KeyF1)
+	sim.AssertLine(t, 2, "     ┌──────────────────┐     ")
+	sim.AssertLine(t, 3, "     │ Fill in the form │     ")
+
+	// Any other key hides help, and still does what it would
+	sim.Rune('x')
+	assert.False(t, sim.HelpShown())
+	assert.Equal(t, "x", email.Text())
+
+	sim.SetHelp(&gowid.HelpOptions{Panel: true, Width: 12, Key: gowid.MakeKey('?')})
+	sim.Rune('?')
+	sim.AssertLine(t, 0, "Name:             ┌──────────┐")
+	sim.AssertLine(t, 1, "Email: x          │ Fill in  │")
+	sim.AssertLine(t, 2, "                  │ the form │")
+	sim.AssertLine(t, 7, "                  └──────────┘")
+	sim.Key(tcell.KeyEscape)
+	assert.False(t, sim.HelpShown())
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/selectable"
+	"github.com/gcla/gowid/widgets/styled"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+// hoverRecorder records the hover notifications it receives.
+type hoverRecorder struct {
+	*styled.Widget
+	seen []bool
+}
+
+func (w *hoverRecorder) SetHovered(hovered bool, app gowid.IApp) {
+	w.seen = append(w.seen, hovered)
+	w.Widget.SetHovered(hovered, app)
+}
+
+func (w *hoverRecorder) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	gowid.TrackHover(w, ev, app)
+	return w.Widget.UserInput(ev, size, focus, app)
+}
+
+func background(sim *Sim, x, y int) tcell.Color {
+	_, st := sim.Cell(x, y)
+	_, bg, _ := st.Decompose()
+	return bg
+}
+
+func TestHover1(t *testing.T) {
+	hover := gowid.MakePaletteEntry(gowid.ColorBlack, gowid.ColorBlue)
+	row1 := &hoverRecorder{Widget: styled.NewHover(selectable.New(text.New("row1")), hover)}
+	row2 := styled.NewHover(selectable.New(text.New("row2")), hover)
+	btn := button.New(text.New("ok"))
+	sbtn := styled.New(btn, gowid.StateStyle{Hover: gowid.MakePaletteEntry(gowid.ColorBlack, gowid.ColorRed)})
+
+	sim := NewSimT(t, pile.NewFlow(row1, row2, sbtn), SimOptions{Cols: 10, Rows: 3})
+	defer sim.Close()
+
+	// Hover tracking is off by default
+	sim.Mouse(1, 0, tcell.ButtonNone)
+	assert.False(t, row1.Hovered())
+	assert.NotEqual(t, tcell.ColorBlue, background(sim, 1, 0))
+
+	sim.EnableHover()
+	sim.Mouse(1, 0, tcell.ButtonNone)
+	assert.True(t, row1.Hovered())
+	assert.True(t, sim.IsHovered(row1))
+	assert.Equal(t, []bool{true}, row1.seen)
+	assert.Equal(t, tcell.ColorBlue, background(sim, 1, 0))
+	assert.NotEqual(t, tcell.ColorBlue, background(sim, 1, 1))
+
+	// Moving within the widget doesn't notify it again
+	sim.Mouse(2, 0, tcell.ButtonNone)
+	assert.Equal(t, []bool{true}, row1.seen)
+
+	sim.Mouse(1, 1, tcell.ButtonNone)
+	assert.False(t, row1.Hovered())
+	assert.True(t, row2.Hovered())
+	assert.Equal(t, []bool{true, false}, row1.seen)
+	assert.NotEqual(t, tcell.ColorBlue, background(sim, 1, 0))
+	assert.Equal(t, tcell.ColorBlue, background(sim, 1, 1))
+
+	// The button reports its hover state, so its styled parent uses the hover style
+	sim.Mouse(1, 2, tcell.ButtonNone)
+	assert.False(t, row2.Hovered())
+	assert.True(t, btn.Hovered())
+	assert.Equal(t, tcell.ColorRed, background(sim, 1, 2))
+
+	sim.DisableHover()
+	assert.False(t, btn.Hovered())
+	assert.False(t, sim.IsHovered(btn))
+}
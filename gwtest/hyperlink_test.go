@@ -0,0 +1,51 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHyperlink1(t *testing.T) {
+	w := text.NewFromContent(text.NewContent([]text.ContentSegment{
+		text.StringContent("see "),
+		text.LinkedContent("docs", "https://example.com/docs", gowid.MakeForeground(gowid.ColorRed)),
+	}))
+	sim := NewSimT(t, w, SimOptions{Cols: 10, Rows: 1})
+	defer sim.Close()
+
+	underlined := func(x int) bool {
+		_, st := sim.Cell(x, 0)
+		_, _, attr := st.Decompose()
+		return attr&tcell.AttrUnderline != 0
+	}
+
+	// Without OSC 8, links are underlined
+	sim.SetHyperlinksSupported(false)
+	sim.Frame()
+	sim.AssertLine(t, 0, "see docs  ")
+	assert.False(t, underlined(0))
+	assert.True(t, underlined(4))
+	assert.True(t, underlined(7))
+	assert.False(t, underlined(8))
+	_, st := sim.Cell(4, 0)
+	fg, _, _ := st.Decompose()
+	assert.Equal(t, tcell.ColorRed, fg)
+
+	sim.SetHyperlinksSupported(true)
+	sim.Frame()
+	assert.True(t, sim.HyperlinksSupported())
+	assert.False(t, underlined(4))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
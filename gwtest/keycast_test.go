@@ -0,0 +1,55 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyCast1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := edit.New()
+	sim := NewSimT(t, e, SimOptions{Cols: 20, Rows: 3, Clock: clock})
+	defer sim.Close()
+
+	sim.SetKeyCast(&gowid.KeyCastOptions{Keys: 2, Duration: time.Second, Fade: 200 * time.Millisecond})
+	sim.Type("ab")
+	sim.Rune('b')
+	sim.Key(tcell.KeyCtrlS, tcell.ModCtrl)
+	// Keys are still passed to the widgets
+	assert.Equal(t, "abb", e.Text())
+	sim.AssertLine(t, 1, "     b ×2   Ctrl+S  ")
+
+	// Shown in full until they fade, then gone
+	clock.Advance(800 * time.Millisecond)
+	sim.Frame()
+	sim.AssertLine(t, 1, "     b ×2   Ctrl+S  ")
+	_, style := sim.Cell(18, 1)
+	full, _, _ := style.Decompose()
+	clock.Advance(100 * time.Millisecond)
+	sim.Frame()
+	_, style = sim.Cell(18, 1)
+	fading, _, _ := style.Decompose()
+	assert.NotEqual(t, full, fading)
+	clock.Advance(100 * time.Millisecond)
+	sim.Frame()
+	sim.AssertLine(t, 1, "                    ")
+	assert.Equal(t, 0, clock.Pending())
+
+	sim.SetKeyCast(&gowid.KeyCastOptions{Corner: gowid.CornerTopLeft})
+	sim.Key(tcell.KeyEsc)
+	sim.Rune(' ')
+	sim.AssertLine(t, 1, "  Esc   Space       ")
+
+	sim.SetKeyCast(nil)
+	sim.Rune('x')
+	sim.Frame()
+	sim.AssertLine(t, 1, "                    ")
+}
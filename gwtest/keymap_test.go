@@ -0,0 +1,81 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyMap1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := edit.New()
+	sim := NewSimT(t, e, SimOptions{Cols: 10, Rows: 1, Clock: clock})
+	defer sim.Close()
+
+	var ran []string
+	action := func(name string) gowid.KeyAction {
+		return func(app gowid.IApp) {
+			ran = append(ran, name)
+		}
+	}
+	m := gowid.NewKeyMap()
+	assert.NoError(t, m.Bind("Ctrl-x Ctrl-c", "quit", action("quit")))
+	assert.NoError(t, m.Bind("g g", "top", action("top")))
+	assert.NoError(t, m.Bind("Alt-z", "zoom", action("zoom")))
+	m.Timeout = time.Second
+	sim.SetKeyMap(m)
+
+	// Unbound keys reach the widgets
+	sim.Type("ab")
+	assert.Equal(t, "ab", e.Text())
+
+	sim.Key(tcell.KeyCtrlX, tcell.ModCtrl)
+	assert.Equal(t, 0, len(ran))
+	assert.Equal(t, "Ctrl+X", gowid.KeySequenceString(m.Pending()))
+	sim.Key(tcell.KeyCtrlC, tcell.ModCtrl)
+	assert.Equal(t, []string{"quit"}, ran)
+	assert.Equal(t, 0, len(m.Pending()))
+
+	sim.Rune('g')
+	sim.Rune('g')
+	assert.Equal(t, []string{"quit", "top"}, ran)
+	assert.Equal(t, "ab", e.Text())
+
+	// A key that doesn't continue the chord cancels it
+	sim.Rune('g')
+	sim.Rune('x')
+	assert.Equal(t, 2, len(ran))
+	assert.Equal(t, "ab", e.Text())
+
+	// So does waiting too long
+	sim.Rune('g')
+	clock.Advance(2 * time.Second)
+	sim.Rune('g')
+	assert.Equal(t, 2, len(ran))
+	assert.Equal(t, "g", gowid.KeySequenceString(m.Pending()))
+	sim.Rune('g')
+	assert.Equal(t, []string{"quit", "top", "top"}, ran)
+
+	// Modifiers must match
+	sim.Rune('z')
+	assert.Equal(t, "abz", e.Text())
+	sim.Rune('z', tcell.ModAlt)
+	assert.Equal(t, []string{"quit", "top", "top", "zoom"}, ran)
+
+	sim.SetKeyMap(nil)
+	sim.Rune('g')
+	assert.Equal(t, "abzg", e.Text())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
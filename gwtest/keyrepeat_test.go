@@ -0,0 +1,79 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/list"
+	"github.com/gcla/gowid/widgets/selectable"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyRepeat1(t *testing.T) {
+	items := make([]gowid.IWidget, 0)
+	for i := 0; i < 100; i++ {
+		items = append(items, selectable.New(text.New(fmt.Sprintf("item %d", i))))
+	}
+	l := list.New(list.NewSimpleListWalker(items))
+	unhandled := []string{}
+	sim := NewSimT(t, l, SimOptions{
+		Cols: 8,
+		Rows: 3,
+		Unhandled: gowid.UnhandledInputFunc(func(app gowid.IApp, ev interface{}) bool {
+			if kev, ok := ev.(*tcell.EventKey); ok {
+				unhandled = append(unhandled, string(kev.Rune()))
+			}
+			return true
+		}),
+	})
+	defer sim.Close()
+
+	queue := func(n int, k tcell.Key) {
+		for i := 0; i < n; i++ {
+			sim.TCellEvents <- tcell.NewEventKey(k, 0, tcell.ModNone)
+		}
+	}
+
+	// Without shaping, queued keys wait for the main loop
+	queue(3, tcell.KeyDown)
+	sim.Key(tcell.KeyDown)
+	assert.Equal(t, list.ListPos(1), l.Walker().Focus())
+	assert.Equal(t, 3, len(sim.TCellEvents))
+	for len(sim.TCellEvents) > 0 {
+		sim.HandleTCellEvent(<-sim.TCellEvents, sim.Unhandled)
+	}
+
+	// The queued repeats are handled with the key, up to the first other event
+	sim.SetKeyRepeat(&gowid.KeyRepeatOptions{})
+	queue(20, tcell.KeyDown)
+	sim.TCellEvents <- tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone)
+	queue(5, tcell.KeyDown)
+	sim.Key(tcell.KeyDown)
+	assert.Equal(t, list.ListPos(25), l.Walker().Focus())
+	assert.Equal(t, []string{"x"}, unhandled)
+	assert.Equal(t, 5, len(sim.TCellEvents))
+	sim.Key(tcell.KeyDown)
+	assert.Equal(t, list.ListPos(31), l.Walker().Focus())
+
+	// Other keys aren't shaped
+	queue(2, tcell.KeyHome)
+	sim.Key(tcell.KeyHome)
+	assert.Equal(t, 2, len(sim.TCellEvents))
+	for len(sim.TCellEvents) > 0 {
+		sim.HandleTCellEvent(<-sim.TCellEvents, sim.Unhandled)
+	}
+
+	// Repeats past the most allowed are dropped
+	sim.SetKeyRepeat(&gowid.KeyRepeatOptions{MaxRepeats: 5})
+	queue(50, tcell.KeyDown)
+	sim.Key(tcell.KeyDown)
+	assert.Equal(t, list.ListPos(5), l.Walker().Focus())
+	assert.Equal(t, 0, len(sim.TCellEvents))
+	sim.AssertLine(t, 2, "item 5  ")
+}
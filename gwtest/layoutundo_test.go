@@ -0,0 +1,131 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/holder"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayoutUndo1(t *testing.T) {
+	a := button.NewBare(text.New("a"))
+	b := button.NewBare(text.New("b"))
+	c := button.NewBare(text.New("c"))
+	cols := columns.New([]gowid.IContainerWidget{
+		&gowid.ContainerWidget{IWidget: a, D: gowid.RenderWithUnits{U: 2}},
+		&gowid.ContainerWidget{IWidget: b, D: gowid.RenderWithUnits{U: 2}},
+		&gowid.ContainerWidget{IWidget: c, D: gowid.RenderWithUnits{U: 2}},
+	})
+	hl := holder.New(cols)
+
+	sim := NewSimT(t, hl, SimOptions{Cols: 8, Rows: 1})
+	defer sim.Close()
+	sim.AssertLine(t, 0, "a b c   ")
+
+	// Not enabled, so nothing is recorded
+	sim.RecordLayout(cols)
+	assert.Equal(t, 0, sim.LayoutUndoAvailable())
+
+	sim.EnableLayoutUndo(gowid.LayoutUndoOptions{Key: gowid.MakeKeyExt(tcell.KeyCtrlU), Depth: 2})
+	cols.SetFocus(sim, 1)
+
+	// Close the focused pane
+	sim.ChangeLayout(func(app gowid.IApp) {
+		ws := cols.SubWidgets()
+		cols.SetSubWidgets([]gowid.IWidget{ws[0], ws[2]}, app)
+	}, cols)
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 0, "a c     ")
+
+	// Resize a pane in place
+	gowid.RecordLayout(sim, cols)
+	cols.SubWidgets()[0].(*gowid.ContainerWidget).D = gowid.RenderWithUnits{U: 4}
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 0, "a   c   ")
+	assert.Equal(t, 2, sim.LayoutUndoAvailable())
+
+	assert.True(t, sim.UndoLayout())
+	sim.Frame()
+	sim.AssertLine(t, 0, "a c     ")
+
+	sim.Key(tcell.KeyCtrlU)
+	sim.Frame()
+	sim.AssertLine(t, 0, "a b c   ")
+	assert.Equal(t, 1, cols.Focus())
+	assert.False(t, sim.UndoLayout())
+
+	// Only the last two changes are kept
+	sim.RecordLayout(cols)
+	sim.RecordLayout(cols)
+	sim.RecordLayout(cols)
+	assert.Equal(t, 2, sim.LayoutUndoAvailable())
+	sim.UndoLayout()
+	sim.UndoLayout()
+	assert.Equal(t, 0, sim.LayoutUndoAvailable())
+
+	// Replacing the holder's widget can be undone too
+	sim.RecordLayout(hl)
+	hl.SetSubWidget(text.New("gone"), sim)
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 0, "gone    ")
+	sim.UndoLayout()
+	sim.Frame()
+	sim.AssertLine(t, 0, "a b c   ")
+}
+
+func TestLayoutUndo2(t *testing.T) {
+	a := button.NewBare(text.New("a"))
+	b := button.NewBare(text.New("b"))
+	cols := columns.New([]gowid.IContainerWidget{
+		&gowid.ContainerWidget{IWidget: a, D: gowid.RenderWithUnits{U: 2}},
+		&gowid.ContainerWidget{IWidget: b, D: gowid.RenderWithUnits{U: 2}},
+	})
+	hl := holder.New(cols)
+
+	sim := NewSimT(t, hl, SimOptions{Cols: 6, Rows: 1})
+	defer sim.Close()
+	sim.EnableLayoutUndo()
+
+	// Setting the children and dimensions before the next redraw is one step
+	cols.SetSubWidgets([]gowid.IWidget{cols.SubWidgets()[1]}, sim)
+	cols.SetDimensions([]gowid.IWidgetDimension{gowid.RenderWithUnits{U: 4}}, sim)
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 0, "b     ")
+	assert.Equal(t, 1, sim.LayoutUndoAvailable())
+
+	hl.SetSubWidget(text.New("gone"), sim)
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 0, "gone  ")
+	assert.Equal(t, 2, sim.LayoutUndoAvailable())
+
+	// Undoing isn't recorded as a change of its own
+	assert.True(t, sim.UndoLayout())
+	assert.True(t, sim.UndoLayout())
+	sim.Frame()
+	sim.AssertLine(t, 0, "a b   ")
+	assert.Equal(t, 0, sim.LayoutUndoAvailable())
+
+	gowid.WithoutLayoutUndo(sim, func(app gowid.IApp) {
+		hl.SetSubWidget(text.New("kept"), app)
+	})
+	assert.Equal(t, 0, sim.LayoutUndoAvailable())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,45 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultipleApps1(t *testing.T) {
+	e1 := edit.New()
+	e2 := edit.New()
+	sim1 := NewSimT(t, e1, SimOptions{Cols: 20, Rows: 2, Env: map[string]string{"TERM": "xterm-256color"}})
+	sim2 := NewSimT(t, e2, SimOptions{Cols: 10, Rows: 3, Env: map[string]string{"TERM": "linux"}})
+	defer sim1.Close()
+	defer sim2.Close()
+
+	// Each app looks at its own terminal
+	assert.Equal(t, "xterm-256color", sim1.Getenv("TERM"))
+	assert.True(t, sim1.ClipboardSupported())
+	assert.False(t, sim2.ClipboardSupported())
+
+	var wg sync.WaitGroup
+	for _, s := range []*Sim{sim1, sim2} {
+		wg.Add(1)
+		go func(s *Sim) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				s.Type("ab")
+				s.Resize(10+i%5, 3)
+			}
+		}(s)
+	}
+	wg.Wait()
+	assert.Equal(t, 100, len(e1.Text()))
+	assert.Equal(t, 100, len(e2.Text()))
+
+	sim1.Type("!")
+	assert.Equal(t, 101, len(e1.Text()))
+	assert.Equal(t, 100, len(e2.Text()))
+}
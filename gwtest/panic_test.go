@@ -0,0 +1,111 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/stretchr/testify/assert"
+)
+
+type panicWidget struct {
+	*button.Widget
+	onRender bool
+}
+
+func (w *panicWidget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	if w.onRender {
+		panic("render boom")
+	}
+	return w.Widget.Render(size, focus, app)
+}
+
+func (w *panicWidget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	panic("input boom")
+}
+
+// recoverFrom runs f the way MainLoop does, returning the value the app panics with again once it has
+// reported the panic.
+func recoverFrom(app *gowid.App, f func()) (res interface{}) {
+	defer func() {
+		res = recover()
+	}()
+	defer app.RecoverPanic()
+	f()
+	return nil
+}
+
+func TestPanic1(t *testing.T) {
+	w := &panicWidget{Widget: button.NewBare(text.New("hi"))}
+	sim := NewSimT(t, w, SimOptions{Cols: 4, Rows: 1})
+	defer sim.Close()
+	sim.AssertLine(t, 0, "hi  ")
+
+	var out bytes.Buffer
+	var info gowid.PanicInfo
+	hooked := 0
+	sim.SetPanicOptions(gowid.PanicOptions{
+		Output: &out,
+		Hook: func(app gowid.IApp, p gowid.PanicInfo) {
+			hooked++
+			info = p
+		},
+	})
+
+	res := recoverFrom(sim.App, func() { sim.Rune('x') })
+	assert.Equal(t, "input boom", res)
+	assert.Equal(t, 1, hooked)
+	assert.Equal(t, "input boom", info.Value)
+	assert.Equal(t, "handling input", info.During)
+	assert.Contains(t, out.String(), "panic while handling input: input boom\n\n")
+	assert.Contains(t, out.String(), "(*panicWidget).UserInput")
+
+	// Only the first panic is reported
+	res = recoverFrom(sim.App, func() { panic("again") })
+	assert.Equal(t, "again", res)
+	assert.Equal(t, 1, hooked)
+}
+
+func TestPanic2(t *testing.T) {
+	w := &panicWidget{Widget: button.NewBare(text.New("hi"))}
+	sim := NewSimT(t, w, SimOptions{Cols: 4, Rows: 1})
+	defer sim.Close()
+
+	var out bytes.Buffer
+	var info gowid.PanicInfo
+	sim.SetPanicOptions(gowid.PanicOptions{
+		Output: &out,
+		Hook: func(app gowid.IApp, p gowid.PanicInfo) {
+			info = p
+		},
+	})
+
+	w.onRender = true
+	res := recoverFrom(sim.App, func() {
+		sim.Redraw()
+		sim.Frame()
+	})
+	assert.Equal(t, "render boom", res)
+	assert.Equal(t, "rendering", info.During)
+	assert.Contains(t, out.String(), "panic while rendering: render boom")
+
+	// A panic outside widget dispatch
+	sim2 := NewSimT(t, text.New("hi"), SimOptions{Cols: 4, Rows: 1})
+	defer sim2.Close()
+	out.Reset()
+	sim2.SetPanicOptions(gowid.PanicOptions{Output: &out})
+	res = recoverFrom(sim2.App, func() { panic("elsewhere") })
+	assert.Equal(t, "elsewhere", res)
+	assert.Contains(t, out.String(), "panic: elsewhere\n\n")
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,72 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBracketedPaste1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := edit.New(edit.Options{Text: "<>"})
+	e.SetCursorPos(1, nil)
+	sim := NewSimT(t, e, SimOptions{Cols: 10, Rows: 3, Clock: clock})
+	defer sim.Close()
+
+	sets := 0
+	e.OnTextSet(gowid.WidgetCallback{"cb", func(app gowid.IApp, w gowid.IWidget) {
+		sets++
+	}})
+
+	sim.EnableBracketedPaste()
+	assert.True(t, sim.BracketedPasteEnabled())
+
+	// What tcell delivers for ESC[200~ab<CR>c d<CR><LF>ESC[201~
+	sim.Rune('[', tcell.ModAlt)
+	sim.Type("200~ab")
+	sim.Key(tcell.KeyCR)
+	sim.Type("c d")
+	sim.Key(tcell.KeyCR)
+	sim.Key(tcell.KeyLF)
+	sim.Rune('[', tcell.ModAlt)
+	sim.Type("201~")
+
+	assert.Equal(t, "<ab\nc d\n>", e.Text())
+	assert.Equal(t, 1, sets)
+	assert.Equal(t, 8, e.CursorPos())
+	sim.AssertLine(t, 1, "c d       ")
+
+	// Alt-[ followed by something other than a paste marker is ordinary input
+	sim.Rune('[', tcell.ModAlt)
+	sim.Rune('2')
+	assert.Equal(t, "<ab\nc d\n>", e.Text())
+	sim.Rune('x')
+	assert.Equal(t, "<ab\nc d\n[2x>", e.Text())
+
+	// Held keys are dispatched if the rest of the marker doesn't arrive
+	sim.Rune('[', tcell.ModAlt)
+	sim.Frame()
+	assert.Equal(t, "<ab\nc d\n[2x>", e.Text())
+	clock.Advance(gowid.PasteMarkerTimeout)
+	sim.Frame()
+	assert.Equal(t, "<ab\nc d\n[2x[>", e.Text())
+
+	// Without bracketed paste, the markers are just keys
+	sim.DisableBracketedPaste()
+	sim.Rune('[', tcell.ModAlt)
+	sim.Type("200~")
+	assert.Equal(t, "<ab\nc d\n[2x[[200~>", e.Text())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,77 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecording1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Unix(0, 0))
+	e := edit.New()
+	sim := NewSimT(t, e, SimOptions{Cols: 10, Rows: 1, Clock: clock})
+	defer sim.Close()
+
+	var buf bytes.Buffer
+	sim.StartRecording(&buf)
+	sim.Type("ab")
+	clock.Advance(time.Second)
+	sim.Key(tcell.KeyLeft)
+	sim.Type("X")
+	sim.Resize(8, 1)
+	assert.NoError(t, sim.StopRecording())
+	sim.Type("ignored")
+	assert.Equal(t, "aXignoredb", e.Text())
+
+	rec, err := gowid.LoadRecording(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(rec))
+	assert.Equal(t, time.Duration(0), rec[1].Offset)
+	assert.Equal(t, time.Second, rec[2].Offset)
+	assert.Equal(t, "resize", rec[4].Type)
+
+	e2 := edit.New()
+	sim2 := NewSimT(t, e2, SimOptions{Cols: 10, Rows: 1})
+	defer sim2.Close()
+	for _, ev := range rec.Events() {
+		sim2.Event(ev)
+	}
+	assert.Equal(t, "aXb", e2.Text())
+	sim2.AssertLine(t, 0, "aXb     ")
+}
+
+func TestReplay1(t *testing.T) {
+	sim := NewSimT(t, edit.New(), SimOptions{Cols: 10, Rows: 1})
+	defer sim.Close()
+
+	rec := gowid.Recording{
+		{Offset: 0, Type: "key", Key: tcell.KeyRune, Rune: 'a'},
+		{Offset: time.Hour, Type: "key", Key: tcell.KeyRune, Rune: 'b'},
+	}
+	r := sim.Replay(rec, gowid.ReplayOptions{Speed: 0})
+	<-r.Done()
+	assert.Equal(t, 2, len(sim.TCellEvents))
+	for len(sim.TCellEvents) > 0 {
+		sim.Event(<-sim.TCellEvents)
+	}
+	sim.AssertLine(t, 0, "ab        ")
+}
+
+func TestLoadRecording1(t *testing.T) {
+	_, err := gowid.LoadRecording(bytes.NewBufferString(`{"t":0,"type":"bogus"}`))
+	assert.Error(t, err)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,27 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/styled"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderString1(t *testing.T) {
+	w := text.New("hello\nworld!")
+	assert.Equal(t, "hello\nworld\n!    ", gowid.RenderString(w, 5, 0, nil))
+	assert.Equal(t, "hello \nworld!\n      ", gowid.RenderString(w, 6, 3, nil))
+	assert.Equal(t, "hello \nworld!", gowid.RenderString(w, 0, 0, nil))
+
+	palette := gowid.Palette{
+		"warn": gowid.MakePaletteEntry(gowid.ColorRed, gowid.ColorNone),
+	}
+	sw := styled.New(text.New("hi"), gowid.MakePaletteRef("warn"))
+	assert.Equal(t, "hi", gowid.RenderString(sw, 2, 0, palette))
+	assert.Equal(t, "\x1b[0;91mhi\x1b[0m", gowid.RenderANSIString(sw, 2, 0, palette))
+}
@@ -0,0 +1,120 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	stdimage "image"
+	"image/color"
+	"image/draw"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/image"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gdamore/tcell"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// gridScreen is a backend that isn't tcell - a grid of runes, like a remote display might keep.
+type gridScreen struct {
+	cols, rows int
+	cells      [][]rune
+	raw        strings.Builder
+	active     bool
+}
+
+var _ gowid.IScreen = (*gridScreen)(nil)
+var _ gowid.IRawWriter = (*gridScreen)(nil)
+
+func newGridScreen(cols, rows int) *gridScreen {
+	res := &gridScreen{cols: cols, rows: rows}
+	res.Clear()
+	return res
+}
+
+func (s *gridScreen) Init() error                  { s.active = true; return nil }
+func (s *gridScreen) Fini()                        { s.active = false }
+func (s *gridScreen) Show()                        {}
+func (s *gridScreen) Sync()                        {}
+func (s *gridScreen) Size() (int, int)             { return s.cols, s.rows }
+func (s *gridScreen) Colors() int                  { return 256 }
+func (s *gridScreen) CharacterSet() string         { return "UTF-8" }
+func (s *gridScreen) SetStyle(style tcell.Style)   {}
+func (s *gridScreen) ShowCursor(x int, y int)      {}
+func (s *gridScreen) EnableMouse()                 {}
+func (s *gridScreen) PollEvent() tcell.Event       { return nil }
+func (s *gridScreen) PostEventWait(ev tcell.Event) {}
+func (s *gridScreen) WriteRaw(str string) error    { s.raw.WriteString(str); return nil }
+
+func (s *gridScreen) Clear() {
+	s.cells = make([][]rune, s.rows)
+	for y := range s.cells {
+		s.cells[y] = []rune(strings.Repeat(" ", s.cols))
+	}
+}
+
+func (s *gridScreen) SetContent(x int, y int, mainc rune, combc []rune, style tcell.Style) {
+	if x >= 0 && x < s.cols && y >= 0 && y < s.rows {
+		s.cells[y][x] = mainc
+	}
+}
+
+func (s *gridScreen) GetContent(x, y int) (rune, []rune, tcell.Style, int) {
+	return s.cells[y][x], nil, tcell.StyleDefault, 1
+}
+
+func (s *gridScreen) line(y int) string {
+	return string(s.cells[y])
+}
+
+func TestScreenBackend1(t *testing.T) {
+	logger := log.New()
+	logger.Out = ioutil.Discard
+	screen := newGridScreen(4, 2)
+	var created []*gridScreen
+	pic := stdimage.NewRGBA(stdimage.Rect(0, 0, 8, 4))
+	draw.Draw(pic, pic.Bounds(), stdimage.NewUniform(color.White), stdimage.Point{}, draw.Src)
+	img := image.New(pic, image.Options{Protocol: gowid.GraphicsSixel})
+	e := edit.New()
+	view := pile.New([]gowid.IContainerWidget{
+		&gowid.ContainerWidget{IWidget: e, D: gowid.RenderWithUnits{U: 1}},
+		&gowid.ContainerWidget{IWidget: img, D: gowid.RenderWithUnits{U: 1}},
+	})
+	app, err := gowid.NewApp(gowid.AppArgs{
+		View:   view,
+		Log:    logger,
+		Screen: screen,
+		NewScreen: func() (gowid.IScreen, error) {
+			created = append(created, newGridScreen(4, 2))
+			return created[len(created)-1], nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, screen.active)
+
+	app.HandleTCellEvent(tcell.NewEventKey(tcell.KeyRune, 'h', tcell.ModNone), gowid.IgnoreUnhandledInput)
+	app.HandleTCellEvent(tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone), gowid.IgnoreUnhandledInput)
+	app.RedrawTerminal()
+	assert.Equal(t, "hi", e.Text())
+	assert.Equal(t, "hi  ", screen.line(0))
+	assert.Equal(t, "▀▀▀▀", screen.line(1))
+	assert.Equal(t, screen.line(1), strings.Split(gowid.SnapshotScreen(app.GetScreen()).Text(), "\n")[1])
+
+	// Escape sequences go to the backend, not the terminal
+	assert.True(t, strings.HasPrefix(screen.raw.String(), "\x1b7\x1b[2;1H\x1bP"))
+
+	// When the app takes the terminal back, it asks the factory for a new screen
+	assert.NoError(t, app.Suspend(func() error {
+		assert.False(t, screen.active)
+		return nil
+	}))
+	assert.Equal(t, 1, len(created))
+	assert.True(t, created[0].active)
+	assert.True(t, app.GetScreen() == created[0])
+	assert.Equal(t, "hi  ", created[0].line(0))
+}
@@ -0,0 +1,310 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gwtest
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gdamore/tcell"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+// Sim is a gowid App running headless on a tcell simulation screen. Unlike D,
+// it is a real App - so tests can drive a widget hierarchy end-to-end by
+// injecting key and mouse events, then check what would be displayed on the
+// terminal. Events are processed synchronously, just as the App's main loop
+// would process them, and the screen is redrawn after each one.
+type Sim struct {
+	*gowid.App
+	Screen    tcell.SimulationScreen
+	Unhandled gowid.IUnhandledInput
+	quit      bool
+}
+
+// SimOptions is used to configure a Sim. Cols and Rows default to 80x24.
+type SimOptions struct {
+	Cols      int
+	Rows      int
+	Palette   gowid.IPalette
+	Clock     gowid.IClock
+	Unhandled gowid.IUnhandledInput // Defaults to gowid.IgnoreUnhandledInput
+	Env       map[string]string     // The terminal's environment - empty if nil, whatever the tests run in
+}
+
+// NewSim returns a Sim displaying w. The initial frame is rendered before
+// it returns.
+func NewSim(w gowid.IWidget, opts ...SimOptions) (*Sim, error) {
+	var opt SimOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Cols == 0 {
+		opt.Cols = 80
+	}
+	if opt.Rows == 0 {
+		opt.Rows = 24
+	}
+	if opt.Unhandled == nil {
+		opt.Unhandled = gowid.IgnoreUnhandledInput
+	}
+
+	screen := newSimScreen(opt.Cols, opt.Rows)
+	logger := log.New()
+	logger.Out = ioutil.Discard
+
+	var res *Sim
+	app, err := gowid.NewApp(gowid.AppArgs{
+		View:    w,
+		Palette: opt.Palette,
+		Log:     logger,
+		Clock:   opt.Clock,
+		Screen:  screen,
+		// When the app takes the terminal back, e.g. after Suspend, it gets a new screen of the same size
+		NewScreen: func() (gowid.IScreen, error) {
+			s := newSimScreen(screen.cols, screen.rows)
+			screen, res.Screen = s, s
+			return s, nil
+		},
+		Env: func(name string) string {
+			return opt.Env[name]
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res = &Sim{
+		App:       app,
+		Screen:    screen,
+		Unhandled: opt.Unhandled,
+	}
+	screen.SetSize(opt.Cols, opt.Rows)
+	res.RedrawTerminal()
+	return res, nil
+}
+
+// simScreen is a simulation screen that remembers its size, since tcell forgets it when the screen is
+// finalized and uses a default size when it's initialized.
+type simScreen struct {
+	tcell.SimulationScreen
+	cols, rows int
+}
+
+func newSimScreen(cols, rows int) *simScreen {
+	return &simScreen{SimulationScreen: tcell.NewSimulationScreen(""), cols: cols, rows: rows}
+}
+
+func (s *simScreen) SetSize(cols, rows int) {
+	s.cols, s.rows = cols, rows
+	s.SimulationScreen.SetSize(cols, rows)
+}
+
+func (s *simScreen) Init() error {
+	if err := s.SimulationScreen.Init(); err != nil {
+		return err
+	}
+	s.SetSize(s.cols, s.rows)
+	return nil
+}
+
+// NewSimT is like NewSim, but fails the test if the Sim can't be built.
+func NewSimT(t *testing.T, w gowid.IWidget, opts ...SimOptions) *Sim {
+	res, err := NewSim(w, opts...)
+	if err != nil {
+		t.Fatalf("Could not create simulation app: %v", err)
+	}
+	return res
+}
+
+// Event dispatches a tcell event to the app, then runs any functions
+// queued via app.Run() as a result. It returns false if the app has quit.
+func (s *Sim) Event(ev tcell.Event) bool {
+	if s.quit {
+		return false
+	}
+	if rev, ok := ev.(*tcell.EventResize); ok {
+		s.Screen.SetSize(rev.Size())
+	}
+	s.HandleTCellEvent(ev, s.Unhandled)
+	return s.Frame()
+}
+
+// Frame runs any functions queued via app.Run() - e.g. from timers or other
+// goroutines - redrawing the screen after each, or once at the end if the
+// app's frame rate is limited and a frame is due. Keys held back because they
+// might start a bracketed paste are dispatched if their timeout has expired.
+// It returns false if the app has quit.
+func (s *Sim) Frame() bool {
+	for !s.quit {
+		select {
+		case ev, ok := <-s.AfterRenderEvents:
+			if !ok || ev == nil {
+				s.quit = true
+			} else {
+				s.RunThenRenderEvent(ev)
+			}
+		case <-s.BracketedPasteTimeout():
+			s.FlushBracketedPaste(s.Unhandled)
+		default:
+			s.RedrawIfDue()
+			return true
+		}
+	}
+	return false
+}
+
+// Quitting returns true if the app has been told to quit.
+func (s *Sim) Quitting() bool {
+	return s.quit
+}
+
+// Key simulates pressing a special key, like tcell.KeyEnter.
+func (s *Sim) Key(k tcell.Key, mods ...tcell.ModMask) bool {
+	mod := tcell.ModNone
+	if len(mods) > 0 {
+		mod = mods[0]
+	}
+	return s.Event(tcell.NewEventKey(k, 0, mod))
+}
+
+// Rune simulates pressing a key that generates a character.
+func (s *Sim) Rune(r rune, mods ...tcell.ModMask) bool {
+	mod := tcell.ModNone
+	if len(mods) > 0 {
+		mod = mods[0]
+	}
+	return s.Event(tcell.NewEventKey(tcell.KeyRune, r, mod))
+}
+
+// Type simulates typing each character of str.
+func (s *Sim) Type(str string) bool {
+	for _, r := range str {
+		if !s.Rune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Mouse simulates a mouse event at column x, row y.
+func (s *Sim) Mouse(x, y int, buttons tcell.ButtonMask, mods ...tcell.ModMask) bool {
+	mod := tcell.ModNone
+	if len(mods) > 0 {
+		mod = mods[0]
+	}
+	return s.Event(tcell.NewEventMouse(x, y, buttons, mod))
+}
+
+// Click simulates pressing then releasing a mouse button at column x, row y.
+func (s *Sim) Click(x, y int, button tcell.ButtonMask) bool {
+	return s.Mouse(x, y, button) && s.Mouse(x, y, tcell.ButtonNone)
+}
+
+// Drag simulates pressing a mouse button at column x1, row y1, moving the mouse to column x2, row y2 with
+// the button held, then releasing it.
+func (s *Sim) Drag(x1, y1, x2, y2 int, button tcell.ButtonMask) bool {
+	return s.Mouse(x1, y1, button) && s.Mouse(x2, y2, button) && s.Mouse(x2, y2, tcell.ButtonNone)
+}
+
+// FindAccessible returns the first widget in the app with the given role, and label if it isn't "" - see
+// gowid.AccessibilityTree.
+func (s *Sim) FindAccessible(role gowid.Role, label string) (gowid.IWidget, bool) {
+	if n, ok := s.AccessibilityTree().Find(role, label); ok {
+		return n.Widget, true
+	}
+	return nil, false
+}
+
+// ClickAccessible clicks the middle of the widget found by FindAccessible, e.g. the button labelled Quit.
+// It returns false if there is no such widget on the screen.
+func (s *Sim) ClickAccessible(role gowid.Role, label string) bool {
+	w, ok := s.FindAccessible(role, label)
+	if !ok {
+		return false
+	}
+	cols, rows := s.Screen.Size()
+	r, ok := gowid.LocateWidget(s.SubWidget(), gowid.RenderBox{C: cols, R: rows}, gowid.Focused, s,
+		func(wi gowid.IWidget) bool {
+			return wi == w
+		})
+	if !ok {
+		return false
+	}
+	return s.Click(r.X+r.Cols/2, r.Y+r.Rows/2, tcell.Button1)
+}
+
+// Resize simulates the terminal changing size.
+func (s *Sim) Resize(cols, rows int) bool {
+	return s.Event(tcell.NewEventResize(cols, rows))
+}
+
+// Text returns the contents of the screen, one line per row.
+func (s *Sim) Text() string {
+	return s.Snapshot().Text()
+}
+
+// Line returns the contents of row y of the screen.
+func (s *Sim) Line(y int) string {
+	lines := strings.Split(s.Text(), "\n")
+	if y < 0 || y >= len(lines) {
+		return ""
+	}
+	return lines[y]
+}
+
+// Cell returns the character and style displayed at column x, row y.
+func (s *Sim) Cell(x, y int) (rune, tcell.Style) {
+	r, _, st, _ := s.Screen.GetContent(x, y)
+	return r, st
+}
+
+// Cursor returns the position of the cursor, and whether it is visible.
+func (s *Sim) Cursor() (int, int, bool) {
+	return s.Screen.GetCursor()
+}
+
+// Find returns the column and row at which str first appears on the screen.
+func (s *Sim) Find(str string) (int, int, bool) {
+	for y, line := range strings.Split(s.Text(), "\n") {
+		if i := strings.Index(line, str); i != -1 {
+			return len([]rune(line[0:i])), y, true
+		}
+	}
+	return -1, -1, false
+}
+
+// AssertLine checks that row y of the screen is exactly expected.
+func (s *Sim) AssertLine(t *testing.T, y int, expected string) bool {
+	return assert.Equal(t, expected, s.Line(y), "Unexpected contents of line %d", y)
+}
+
+// AssertContains checks that str appears somewhere on the screen.
+func (s *Sim) AssertContains(t *testing.T, str string) bool {
+	return assert.Contains(t, s.Text(), str)
+}
+
+// AssertNotContains checks that str does not appear on the screen.
+func (s *Sim) AssertNotContains(t *testing.T, str string) bool {
+	return assert.NotContains(t, s.Text(), str)
+}
+
+// AssertCursor checks that the cursor is visible at column x, row y.
+func (s *Sim) AssertCursor(t *testing.T, x, y int) bool {
+	cx, cy, vis := s.Cursor()
+	return assert.True(t, vis, "Cursor is not visible") &&
+		assert.Equal(t, []int{x, y}, []int{cx, cy}, "Cursor is in the wrong position")
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
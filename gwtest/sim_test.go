@@ -0,0 +1,80 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSim1(t *testing.T) {
+	e := edit.New(edit.Options{Caption: "> "})
+	clicks := 0
+	b := button.New(text.New("ok"))
+	b.OnClick(gowid.WidgetCallback{"cb", func(app gowid.IApp, w gowid.IWidget) {
+		clicks++
+	}})
+	p := pile.NewFlow(e, b)
+
+	sim := NewSimT(t, p, SimOptions{Cols: 10, Rows: 3})
+	defer sim.Close()
+
+	sim.AssertLine(t, 0, ">         ")
+	sim.AssertLine(t, 1, "<ok      >")
+	sim.AssertCursor(t, 2, 0)
+
+	sim.Type("hi")
+	sim.AssertLine(t, 0, "> hi      ")
+	sim.AssertCursor(t, 4, 0)
+	assert.Equal(t, "hi", e.Text())
+
+	x, y, ok := sim.Find("ok")
+	assert.True(t, ok)
+	assert.Equal(t, []int{1, 1}, []int{x, y})
+
+	sim.Click(x, y, tcell.Button1)
+	assert.Equal(t, 1, clicks)
+
+	sim.Key(tcell.KeyEnter)
+	assert.Equal(t, 2, clicks)
+
+	sim.Resize(6, 2)
+	sim.AssertLine(t, 1, "<ok  >")
+}
+
+func TestSim2(t *testing.T) {
+	sim := NewSimT(t, text.New("hello"), SimOptions{
+		Cols:      8,
+		Rows:      1,
+		Unhandled: gowid.UnhandledInputFunc(gowid.HandleQuitKeys),
+	})
+	defer sim.Close()
+
+	sim.AssertContains(t, "hello")
+	assert.True(t, sim.Frame())
+
+	ran := false
+	sim.Run(gowid.RunFunction(func(app gowid.IApp) {
+		ran = true
+	}))
+	assert.False(t, ran)
+	assert.True(t, sim.Frame())
+	assert.True(t, ran)
+
+	assert.False(t, sim.Rune('q'))
+	assert.True(t, sim.Quitting())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,62 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/list"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/stretchr/testify/assert"
+)
+
+// shortWidget draws a row fewer than it should.
+type shortWidget struct {
+	*text.Widget
+}
+
+func (w shortWidget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	res := w.Widget.Render(size, focus, app)
+	res.Truncate(0, 1)
+	return res
+}
+
+func TestStrictSizing1(t *testing.T) {
+	bad := shortWidget{text.New("one\ntwo")}
+	view := pile.NewFlow(text.New("fine"), bad, list.New(list.NewSimpleListWalker(nil)))
+	sim := NewSimT(t, view, SimOptions{Cols: 10, Rows: 6})
+	defer sim.Close()
+
+	var errs []gowid.SizingError
+	sim.SetStrictSizing(&gowid.StrictSizingOptions{
+		OnError: func(app gowid.IApp, err gowid.SizingError) {
+			errs = append(errs, err)
+		},
+	})
+	sim.RedrawTerminal()
+	if assert.Equal(t, 1, len(errs)) {
+		assert.Equal(t, bad, errs[0].Widget())
+		assert.Equal(t, view, errs[0].Path[0])
+		assert.Equal(t, 1, errs[0].Rows)
+		assert.Contains(t, errs[0].Error(), "RenderSize returned 10x2")
+	}
+
+	// Without a handler, the app panics
+	sim.SetStrictSizing(&gowid.StrictSizingOptions{})
+	assert.Panics(t, func() {
+		sim.RedrawTerminal()
+	})
+
+	errs = nil
+	view.SetSubWidgets([]gowid.IWidget{text.New("fine"), list.New(list.NewSimpleListWalker(nil))}, sim)
+	sim.SetStrictSizing(&gowid.StrictSizingOptions{
+		OnError: func(app gowid.IApp, err gowid.SizingError) {
+			errs = append(errs, err)
+		},
+	})
+	sim.RedrawTerminal()
+	assert.Equal(t, 0, len(errs))
+}
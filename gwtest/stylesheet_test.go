@@ -0,0 +1,138 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStylesheet1(t *testing.T) {
+	s := gowid.NewStylesheet()
+	assert.IsType(t, gowid.SelectorError{}, s.Add("button:sleepy", gowid.MakeStyledAs(gowid.StyleBold)))
+	assert.IsType(t, gowid.SelectorError{}, s.Add("button, ", gowid.MakeStyledAs(gowid.StyleBold)))
+	assert.IsType(t, gowid.SelectorError{}, s.Add("#", gowid.MakeStyledAs(gowid.StyleBold)))
+	assert.NoError(t, s.Add("dialog button:focus, #sidebar .warning, *", gowid.MakeStyledAs(gowid.StyleBold)))
+
+	assert.Equal(t, "button", gowid.StyleTypeOf(button.New(text.New("ok"))))
+	assert.Equal(t, "text", gowid.StyleTypeOf(text.New("ok")))
+	assert.Equal(t, "profiledwidget", gowid.StyleTypeOf(gowid.NewProfiledWidget("x", text.New("ok"))))
+}
+
+func TestStylesheet2(t *testing.T) {
+	t1 := text.New("one")
+	t2 := text.New("two")
+	sidebar := pile.NewFlow(t2)
+	btn := button.New(text.New("ok"))
+	view := pile.NewFlow(t1, sidebar, btn)
+
+	s := gowid.NewStylesheet()
+	// The more specific rule wins, though it was added first
+	assert.NoError(t, s.Add("#sidebar text", gowid.MakeBackground(gowid.ColorRed)))
+	assert.NoError(t, s.Add("text", gowid.MakeBackground(gowid.ColorBlue)))
+	assert.NoError(t, s.Add("pile button:focus", gowid.MakeStyledAs(gowid.StyleReverse)))
+	assert.NoError(t, s.Add(".warning", gowid.MakeForeground(gowid.ColorYellow)))
+	s.SetID(sidebar, "sidebar")
+
+	sim := NewSimT(t, view, SimOptions{Cols: 10, Rows: 3})
+	defer sim.Close()
+
+	_, st := sim.Cell(0, 0)
+	_, bg, _ := st.Decompose()
+	assert.Equal(t, tcell.ColorDefault, bg)
+
+	sim.SetStylesheet(s)
+	sim.Frame()
+	sim.AssertLine(t, 0, "one       ")
+
+	_, st = sim.Cell(0, 0)
+	fg, bg, _ := st.Decompose()
+	assert.Equal(t, tcell.ColorBlue, bg)
+	assert.Equal(t, tcell.ColorDefault, fg)
+
+	_, st = sim.Cell(0, 1)
+	_, bg, _ = st.Decompose()
+	assert.Equal(t, tcell.ColorRed, bg)
+
+	// The button has focus; its label is also a text widget
+	_, st = sim.Cell(1, 2)
+	_, bg, attr := st.Decompose()
+	assert.Equal(t, tcell.ColorBlue, bg)
+	assert.NotEqual(t, tcell.AttrMask(0), attr&tcell.AttrReverse)
+
+	// Classes can be added after the widgets are built
+	s.AddClass(t1, "warning")
+	assert.True(t, s.HasClass(t1, "warning"))
+	sim.Redraw()
+	sim.Frame()
+	_, st = sim.Cell(0, 0)
+	fg, bg, _ = st.Decompose()
+	assert.Equal(t, tcell.ColorYellow, fg)
+	assert.Equal(t, tcell.ColorBlue, bg)
+
+	sim.SetStylesheet(nil)
+	sim.Frame()
+	_, st = sim.Cell(0, 1)
+	_, bg, _ = st.Decompose()
+	assert.Equal(t, tcell.ColorDefault, bg)
+}
+
+// unhashable is a widget that can't be a map key.
+type unhashable struct {
+	*text.Widget
+	lines []string
+}
+
+func TestStylesheet3(t *testing.T) {
+	s := gowid.NewStylesheet()
+	assert.NoError(t, s.Add("#sidebar text", gowid.MakeBackground(gowid.ColorRed)))
+	w := unhashable{Widget: text.New("x")}
+	assert.NotPanics(t, func() {
+		s.SetID(w, "sidebar")
+		s.AddClass(w, "warning")
+		s.RemoveClass(w, "warning")
+	})
+	assert.Equal(t, "", s.ID(w))
+	assert.False(t, s.HasClass(w, "warning"))
+
+	// Apps rendering at the same time can share a stylesheet
+	sidebars := []gowid.IWidget{pile.NewFlow(text.New("one")), pile.NewFlow(text.New("one"))}
+	for _, sidebar := range sidebars {
+		s.SetID(sidebar, "sidebar")
+	}
+	done := make(chan bool)
+	for _, sidebar := range sidebars {
+		sidebar := sidebar
+		go func() {
+			sim := NewSimT(t, pile.NewFlow(text.New("two"), sidebar), SimOptions{Cols: 5, Rows: 2})
+			defer sim.Close()
+			sim.SetStylesheet(s)
+			ok := true
+			for j := 0; j < 50 && ok; j++ {
+				sim.Redraw()
+				sim.Frame()
+				_, st := sim.Cell(0, 0)
+				_, bg0, _ := st.Decompose()
+				_, st = sim.Cell(0, 1)
+				_, bg1, _ := st.Decompose()
+				ok = bg0 == tcell.ColorDefault && bg1 == tcell.ColorRed
+			}
+			done <- ok
+		}()
+	}
+	assert.True(t, <-done)
+	assert.True(t, <-done)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -77,6 +77,30 @@ func Test1(t *testing.T) {
 	assert.Equal(t, 0, c2.Focus())
 }
 
+func TestDescribeWidgetTree1(t *testing.T) {
+	fx := gowid.RenderFixed{}
+	t1 := edit.New(edit.Options{Text: "foo"})
+	ct1 := &gowid.ContainerWidget{IWidget: t1, D: fx}
+	w2 := styled.New(t1, gowid.MakeForeground(gowid.ColorBlack))
+	ct2 := &gowid.ContainerWidget{IWidget: w2, D: fx}
+
+	p1 := pile.New([]gowid.IContainerWidget{ct1, ct2})
+	p1.SetFocus(D, 1)
+
+	n := gowid.DescribeWidgetTree(p1)
+	assert.Equal(t, "*pile.Widget", n.Type)
+	assert.Equal(t, 1, n.Focus)
+	assert.Equal(t, 2, len(n.Children))
+	assert.Equal(t, "*gowid.ContainerWidget", n.Children[1].Type)
+	assert.Equal(t, "*styled.Widget", n.Children[1].Children[0].Type)
+
+	leaf := n.Children[1].Children[0].Children[0]
+	assert.Equal(t, "*edit.Widget", leaf.Type)
+	assert.Equal(t, true, leaf.Selectable)
+	assert.Equal(t, -1, leaf.Focus)
+	assert.Equal(t, 0, len(leaf.Children))
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
@@ -0,0 +1,87 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+// nextKey returns the next key the app's event runner delivers.
+func nextKey(t *testing.T, sim *Sim) *tcell.EventKey {
+	for {
+		select {
+		case ev := <-sim.TCellEvents:
+			if kev, ok := ev.(*tcell.EventKey); ok {
+				return kev
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("No key was delivered")
+			return nil
+		}
+	}
+}
+
+func TestSuspend1(t *testing.T) {
+	e := edit.New()
+	sim := NewSimT(t, e, SimOptions{Cols: 6, Rows: 1})
+	defer sim.Close()
+
+	runner := sim.Runner()
+	runner.Start()
+	defer runner.Stop()
+
+	sim.Screen.InjectKey(tcell.KeyRune, 'a', tcell.ModNone)
+	sim.Event(nextKey(t, sim))
+	sim.AssertLine(t, 0, "a     ")
+
+	old := sim.Screen
+	boom := errors.New("boom")
+	err := sim.Suspend(func() error {
+		// The screen is finalized while f runs
+		assert.Nil(t, sim.GetScreen())
+		return boom
+	})
+	assert.Equal(t, boom, err)
+
+	// A new screen was initialized, and the app redrawn on it
+	assert.True(t, sim.GetScreen() == gowid.IScreen(sim.Screen))
+	assert.False(t, sim.Screen == old)
+	sim.AssertLine(t, 0, "a     ")
+
+	// The runner polls the new screen, and input still reaches the widgets
+	sim.Screen.InjectKey(tcell.KeyRune, 'b', tcell.ModNone)
+	sim.Event(nextKey(t, sim))
+	assert.Equal(t, "ab", e.Text())
+	sim.AssertLine(t, 0, "ab    ")
+}
+
+func TestRunCommand1(t *testing.T) {
+	sim := NewSimT(t, edit.New(), SimOptions{Cols: 6, Rows: 1})
+	defer sim.Close()
+
+	var out bytes.Buffer
+	cmd := exec.Command("sh", "-c", "echo hello; exit 3")
+	cmd.Stdout = &out
+	err := sim.RunCommand(cmd)
+	var exitErr *exec.ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 3, exitErr.ExitCode())
+	assert.Equal(t, "hello\n", out.String())
+	assert.NotNil(t, sim.GetScreen())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
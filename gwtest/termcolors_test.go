@@ -0,0 +1,71 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseXColor1(t *testing.T) {
+	c, ok := gowid.ParseXColor("rgb:1e1e/2020/ffff")
+	assert.True(t, ok)
+	assert.Equal(t, gowid.RGBColor{0x1e, 0x20, 0xff}, c)
+	c, ok = gowid.ParseXColor("rgb:f/8/0")
+	assert.True(t, ok)
+	assert.Equal(t, gowid.RGBColor{255, 136, 0}, c)
+	c, ok = gowid.ParseXColor("#102030")
+	assert.True(t, ok)
+	assert.Equal(t, gowid.RGBColor{0x10, 0x20, 0x30}, c)
+	_, ok = gowid.ParseXColor("rgb:1/2")
+	assert.False(t, ok)
+}
+
+func TestTerminalColors1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	e := edit.New()
+	sim := NewSimT(t, e, SimOptions{Cols: 10, Rows: 1, Clock: clock})
+	defer sim.Close()
+
+	reported := 0
+	sim.OnTerminalColors(gowid.WidgetCallback{Name: "test", WidgetChangedFunction: func(app gowid.IApp, w gowid.IWidget) {
+		reported++
+	}})
+	assert.NoError(t, sim.QueryTerminalColors())
+
+	// What tcell delivers for ESC]11;rgb:1e1e/1e1e/1e1e BEL and ESC]4;4;rgb:0000/0000/eeee ESC\
+	sim.Rune(']', tcell.ModAlt)
+	sim.Type("11;rgb:1e1e/1e1e/1e1e")
+	sim.Key(tcell.KeyBEL)
+	sim.Rune(']', tcell.ModAlt)
+	sim.Type("4;4;rgb:0000/0000/eeee")
+	sim.Rune('\\', tcell.ModAlt)
+	assert.Equal(t, "", e.Text())
+
+	colors := sim.TerminalColors()
+	assert.Equal(t, gowid.RGBColor{0x1e, 0x1e, 0x1e}, colors.Background)
+	assert.Equal(t, gowid.RGBColor{0, 0, 0xee}, colors.ANSIOr(4, nil))
+	assert.Equal(t, gowid.ColorRed, colors.ForegroundOr(gowid.ColorRed))
+	dark, known := colors.Dark()
+	assert.True(t, dark)
+	assert.True(t, known)
+
+	// Keys typed while waiting that aren't an answer reach the widgets
+	sim.Rune(']', tcell.ModAlt)
+	sim.Type("1x")
+	assert.Equal(t, "]1x", e.Text())
+
+	// The rest never arrive, so callbacks run once the wait is over
+	assert.Equal(t, 0, reported)
+	clock.Advance(gowid.TerminalColorsTimeout)
+	sim.Frame()
+	assert.Equal(t, 1, reported)
+	sim.Rune(']', tcell.ModAlt)
+	assert.Equal(t, "]1x]", e.Text())
+}
@@ -27,6 +27,8 @@ type testApp struct {
 	doQuit bool
 	gowid.ClickTargets
 	lastMouse gowid.MouseState
+	clock     gowid.IClock
+	clipboard string
 }
 
 func NewTestApp() *testApp {
@@ -36,6 +38,14 @@ func NewTestApp() *testApp {
 	return a
 }
 
+// NewTestAppWithClock returns a test app whose widgets will use the supplied
+// clock, typically a gowid.FakeClock.
+func NewTestAppWithClock(clock gowid.IClock) *testApp {
+	a := NewTestApp()
+	a.clock = clock
+	return a
+}
+
 var D *testApp = NewTestApp()
 
 func ClearTestApp() {
@@ -67,6 +77,10 @@ func (d testApp) Run(f gowid.IAfterRenderEvent) error {
 	return nil
 }
 
+func (d testApp) Clock() gowid.IClock {
+	return d.clock
+}
+
 func (d testApp) GetColorMode() gowid.ColorMode {
 	return gowid.Mode256Colors
 }
@@ -99,6 +113,21 @@ func (d testApp) CopyModeClaimedBy(...gowid.IIdentity) gowid.IIdentity {
 	panic(errors.New("Must not call!"))
 }
 
+// CopyToClipboard records the text, so that tests can check it with
+// ClipboardContents.
+func (d *testApp) CopyToClipboard(text string) error {
+	d.clipboard = text
+	return nil
+}
+
+func (d testApp) ClipboardSupported() bool {
+	return true
+}
+
+func (d testApp) ClipboardContents() string {
+	return d.clipboard
+}
+
 func (d testApp) RefreshCopyMode()                            { panic(errors.New("Must not call!")) }
 func (d testApp) CopyLevel(...int) int                        { panic(errors.New("Must not call!")) }
 func (d testApp) Clips() []gowid.ICopyResult                  { panic(errors.New("Must not call!")) }
@@ -108,7 +137,7 @@ func (d testApp) UnregisterMenu(m gowid.IMenuCompatible) bool { panic(errors.New
 func (d testApp) GetLog() log.StdLogger                       { panic(errors.New("Must not call!")) }
 func (d testApp) SetLog(log.StdLogger)                        { panic(errors.New("Must not call!")) }
 func (d testApp) ID() interface{}                             { panic(errors.New("Must not call!")) }
-func (d testApp) GetScreen() tcell.Screen                     { panic(errors.New("Must not call!")) }
+func (d testApp) GetScreen() gowid.IScreen                    { panic(errors.New("Must not call!")) }
 func (d testApp) Redraw()                                     { panic(errors.New("Must not call!")) }
 func (d testApp) Sync()                                       { panic(errors.New("Must not call!")) }
 func (d testApp) SetColorMode(gowid.ColorMode)                { panic(errors.New("Must not call!")) }
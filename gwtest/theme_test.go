@@ -0,0 +1,102 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/styled"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTheme1(t *testing.T) {
+	dark := gowid.NewTheme("dark", gowid.Palette{
+		"button":       gowid.MakePaletteEntry(gowid.ColorWhite, gowid.ColorBlue),
+		"button:focus": gowid.MakeStyledAs(gowid.StyleReverse),
+		"title":        gowid.MakeForeground(gowid.ColorYellow),
+	}, nil)
+	light := gowid.NewTheme("light", gowid.Palette{
+		"button": gowid.MakePaletteEntry(gowid.ColorBlack, gowid.ColorCyan),
+	}, dark)
+
+	v, ok := light.CellStyler("title")
+	assert.True(t, ok)
+	assert.Equal(t, gowid.MakeForeground(gowid.ColorYellow), v)
+
+	count := 0
+	light.RangeOverPalette(func(k string, v gowid.ICellStyler) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, 3, count)
+
+	// "dialog.button" falls back to "button"; "banner" comes from the palette
+	btn := styled.New(text.New("ok"), gowid.MakeThemeRef("dialog.button"))
+	banner := styled.New(text.New("hi"), gowid.MakePaletteRef("banner"))
+	view := pile.NewFlow(btn, banner)
+
+	sim := NewSimT(t, view, SimOptions{Cols: 4, Rows: 2, Palette: gowid.Palette{
+		"banner": gowid.MakeBackground(gowid.ColorGreen),
+	}})
+	defer sim.Close()
+
+	_, st := sim.Cell(0, 0)
+	_, bg, _ := st.Decompose()
+	assert.Equal(t, tcell.ColorDefault, bg)
+
+	sim.SetTheme(dark)
+	sim.Frame()
+	_, st = sim.Cell(0, 0)
+	fg, bg, attr := st.Decompose()
+	assert.Equal(t, tcell.ColorWhite, fg)
+	assert.Equal(t, tcell.ColorBlue, bg)
+	assert.Equal(t, tcell.AttrMask(0), attr&tcell.AttrReverse)
+	_, st = sim.Cell(0, 1)
+	_, bg, _ = st.Decompose()
+	assert.Equal(t, tcell.ColorGreen, bg)
+
+	sim.SetTheme(light)
+	sim.Frame()
+	_, st = sim.Cell(0, 0)
+	fg, bg, _ = st.Decompose()
+	assert.Equal(t, tcell.ColorBlack, fg)
+	assert.Equal(t, tcell.ColorDarkCyan, bg)
+
+	// A theme can override palette entries too
+	light.Set("banner", gowid.MakeBackground(gowid.ColorRed))
+	sim.Redraw()
+	sim.Frame()
+	_, st = sim.Cell(0, 1)
+	_, bg, _ = st.Decompose()
+	assert.Equal(t, tcell.ColorRed, bg)
+	assert.Equal(t, light, gowid.ThemeFor(sim.App))
+}
+
+func TestTheme2(t *testing.T) {
+	theme := gowid.NewTheme("t", gowid.Palette{
+		"button":       gowid.MakeBackground(gowid.ColorBlue),
+		"button:focus": gowid.MakeStyledAs(gowid.StyleReverse),
+	}, nil)
+	ref := gowid.MakeThemeRef("dialog.button")
+
+	sim := NewSimT(t, text.New("x"), SimOptions{Cols: 1, Rows: 1})
+	defer sim.Close()
+	sim.SetTheme(theme)
+
+	_, _, st := gowid.StylerForState(ref, gowid.StateFocus).GetStyle(sim.App)
+	assert.Equal(t, gowid.StyleReverse, st)
+	_, bg, st := ref.GetStyle(sim.App)
+	assert.Equal(t, gowid.StyleNone, st)
+	assert.Equal(t, gowid.ColorBlue, bg)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,80 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimers1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := text.New("")
+	sim := NewSimT(t, w, SimOptions{Cols: 10, Rows: 1, Clock: clock})
+	defer sim.Close()
+
+	ticks := 0
+	every := sim.Every(time.Second, func(app gowid.IApp) {
+		ticks++
+		w.SetText(fmt.Sprintf("tick %d", ticks), app)
+	})
+	fired := 0
+	after := sim.After(1500*time.Millisecond, func(app gowid.IApp) {
+		fired++
+	})
+
+	clock.Advance(time.Second)
+	sim.Frame()
+	// The app is redrawn after each run
+	sim.AssertLine(t, 0, "tick 1    ")
+	assert.Equal(t, 0, fired)
+
+	clock.Advance(time.Second)
+	sim.Frame()
+	sim.AssertLine(t, 0, "tick 2    ")
+	assert.Equal(t, 1, fired)
+	assert.True(t, after.Stopped())
+	assert.False(t, after.Stop())
+
+	// A tick that is due, but waiting for the app goroutine, doesn't run once stopped
+	clock.Advance(time.Second)
+	assert.True(t, every.Stop())
+	sim.Frame()
+	assert.Equal(t, 2, ticks)
+	clock.Advance(time.Second)
+	sim.Frame()
+	assert.Equal(t, 2, ticks)
+	assert.Equal(t, 0, clock.Pending())
+
+	// Cancelled before it is due
+	after = sim.After(time.Second, func(app gowid.IApp) {
+		fired++
+	})
+	assert.True(t, after.Stop())
+	clock.Advance(time.Second)
+	sim.Frame()
+	assert.Equal(t, 1, fired)
+
+	// A timer can stop itself
+	var self *gowid.Timer
+	self = sim.Every(time.Second, func(app gowid.IApp) {
+		ticks++
+		self.Stop()
+	})
+	clock.Advance(time.Second)
+	sim.Frame()
+	clock.Advance(time.Second)
+	sim.Frame()
+	assert.Equal(t, 3, ticks)
+
+	// An interval of zero would keep the app busy running f
+	assert.Panics(t, func() {
+		sim.Every(0, func(app gowid.IApp) {})
+	})
+}
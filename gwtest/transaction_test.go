@@ -0,0 +1,63 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction1(t *testing.T) {
+	t1, t2 := text.New("a"), text.New("b")
+	sim := NewSimT(t, pile.NewFlow(t1, t2), SimOptions{Cols: 4, Rows: 2})
+	defer sim.Close()
+
+	set := func(w *text.Widget, val string) {
+		old := w.Content().String()
+		w.SetText(val, sim)
+		gowid.OnRollback(sim, func(app gowid.IApp) {
+			w.SetText(old, app)
+		})
+	}
+
+	// Nothing is drawn until the transaction ends
+	assert.NoError(t, sim.Transaction(func() error {
+		set(t1, "c")
+		sim.RedrawTerminal()
+		sim.AssertLine(t, 0, "a   ")
+		set(t2, "d")
+		assert.True(t, sim.InTransaction())
+		return nil
+	}))
+	assert.False(t, sim.InTransaction())
+	sim.AssertLine(t, 0, "c   ")
+	sim.AssertLine(t, 1, "d   ")
+
+	// A failed transaction is rolled back, with the inner transactions that succeeded
+	failed := errors.New("failed")
+	err := sim.Transaction(func() error {
+		set(t1, "e")
+		assert.NoError(t, sim.Transaction(func() error {
+			set(t2, "f")
+			return nil
+		}))
+		assert.Equal(t, failed, sim.Transaction(func() error {
+			set(t1, "g")
+			return failed
+		}))
+		assert.Equal(t, "e", t1.Content().String())
+		return failed
+	})
+	assert.Equal(t, failed, err)
+	sim.AssertLine(t, 0, "c   ")
+	sim.AssertLine(t, 1, "d   ")
+
+	// Rollbacks aren't registered outside a transaction
+	assert.False(t, gowid.OnRollback(sim, func(app gowid.IApp) {}))
+}
@@ -0,0 +1,169 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/fill"
+	"github.com/gcla/gowid/widgets/hpadding"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEasing1(t *testing.T) {
+	for _, e := range []gowid.Easing{gowid.Linear, gowid.EaseInOut, gowid.EaseSpring} {
+		assert.InDelta(t, 0, e(0), 1e-9)
+		assert.InDelta(t, 1, e(1), 1e-9)
+	}
+	assert.InDelta(t, 0.5, gowid.EaseInOut(0.5), 1e-9)
+	assert.True(t, gowid.EaseInOut(0.1) < 0.1)
+	assert.True(t, gowid.EaseInOut(0.9) > 0.9)
+
+	// A spring overshoots
+	max := 0.0
+	for i := 0; i <= 100; i++ {
+		if v := gowid.EaseSpring(float64(i) / 100); v > max {
+			max = v
+		}
+	}
+	assert.True(t, max > 1)
+}
+
+func TestTween1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := text.New("")
+	sim := NewSimT(t, w, SimOptions{Cols: 10, Rows: 1, Clock: clock})
+	defer sim.Close()
+
+	done := 0
+	tw := gowid.NewTween(gowid.TweenOptions{
+		From:          0,
+		To:            10,
+		Duration:      100 * time.Millisecond,
+		Easing:        gowid.Linear,
+		FrameInterval: 25 * time.Millisecond,
+		OnUpdate: func(v float64, app gowid.IApp) {
+			w.SetText(fmt.Sprintf("%.1f", v), app)
+		},
+		OnDone: func(app gowid.IApp) {
+			done++
+		},
+	})
+	tw.Start(sim)
+	sim.Redraw()
+	sim.Frame()
+	assert.True(t, tw.Running())
+	sim.AssertLine(t, 0, "0.0       ")
+
+	clock.Advance(25 * time.Millisecond)
+	sim.Frame()
+	sim.AssertLine(t, 0, "2.5       ")
+	assert.InDelta(t, 0.25, tw.Progress(), 1e-9)
+
+	// A late frame catches up
+	clock.Advance(50 * time.Millisecond)
+	sim.Frame()
+	sim.AssertLine(t, 0, "7.5       ")
+	clock.Advance(25 * time.Millisecond)
+	sim.Frame()
+	sim.AssertLine(t, 0, "10.0      ")
+	assert.False(t, tw.Running())
+	assert.Equal(t, 1, done)
+	assert.Equal(t, 0, clock.Pending())
+
+	// Stopping leaves the value where it is
+	tw.Start(sim)
+	clock.Advance(50 * time.Millisecond)
+	sim.Frame()
+	tw.Stop()
+	clock.Advance(time.Second)
+	sim.Frame()
+	assert.Equal(t, 5.0, tw.Value())
+	assert.Equal(t, 1, done)
+
+	tw.Finish(sim)
+	assert.Equal(t, 10.0, tw.Value())
+	assert.Equal(t, 2, done)
+
+	// With motion reduced in the app, the tween jumps to its end; other apps are left alone
+	other := NewSimT(t, text.New("x"), SimOptions{Cols: 1, Rows: 1})
+	defer other.Close()
+	sim.SetReduceMotion(true)
+	assert.True(t, gowid.ReduceMotion(sim))
+	assert.False(t, gowid.ReduceMotion(other))
+	tw.Start(sim)
+	assert.False(t, tw.Running())
+	assert.Equal(t, 10.0, tw.Value())
+	assert.Equal(t, 3, done)
+	assert.Equal(t, 0, clock.Pending())
+}
+
+func TestAnimate1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	txt := text.New("x")
+	w := hpadding.New(fill.New('#'), gowid.HAlignLeft{}, gowid.RenderWithUnits{U: 0})
+	sim := NewSimT(t, pile.NewFlow(txt, w), SimOptions{Cols: 10, Rows: 2, Clock: clock})
+	defer sim.Close()
+
+	opts := gowid.AnimateOptions{
+		Duration:      100 * time.Millisecond,
+		Easing:        gowid.Linear,
+		FrameInterval: 25 * time.Millisecond,
+	}
+	// Slide the panel open
+	gowid.AnimateDimension(sim, gowid.RenderWithUnits{U: 0}, gowid.RenderWithUnits{U: 8}, w.SetWidth, opts)
+	ints := []int{}
+	gowid.AnimateInt(sim, 0, 2, func(v int, app gowid.IApp) {
+		ints = append(ints, v)
+		txt.SetText(fmt.Sprintf("%d", v), app)
+	}, opts)
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 1, "          ")
+
+	clock.Advance(25 * time.Millisecond)
+	sim.Frame()
+	sim.AssertLine(t, 1, "##        ")
+	clock.Advance(75 * time.Millisecond)
+	sim.Frame()
+	sim.AssertLine(t, 0, "2         ")
+	sim.AssertLine(t, 1, "########  ")
+	// Only changes are passed on
+	assert.Equal(t, []int{0, 1, 2}, ints)
+	assert.Equal(t, 0, clock.Pending())
+
+	var color gowid.IColor
+	tw := gowid.AnimateColor(sim, gowid.NewUrwidColor("black"), gowid.NewUrwidColor("white"), gowid.RGBSpace,
+		func(c gowid.IColor, app gowid.IApp) {
+			color = c
+		}, opts)
+	clock.Advance(50 * time.Millisecond)
+	sim.Frame()
+	c, _ := gowid.IColorToRGB(color)
+	assert.Equal(t, gowid.RGBColor{0x80, 0x80, 0x80}, c)
+	tw.Finish(sim)
+	c, _ = gowid.IColorToRGB(color)
+	assert.Equal(t, gowid.RGBColor{0xff, 0xff, 0xff}, c)
+}
+
+func TestInterpolateDimension1(t *testing.T) {
+	assert.Equal(t, gowid.RenderWithUnits{U: 5}, gowid.InterpolateDimension(gowid.RenderWithUnits{U: 0}, gowid.RenderWithUnits{U: 10}, 0.5))
+	assert.Equal(t, gowid.RenderWithUnits{U: 0}, gowid.InterpolateDimension(gowid.RenderWithUnits{U: 2}, gowid.RenderWithUnits{U: 10}, -1))
+	assert.Equal(t, gowid.RenderWithRatio{R: 0.75}, gowid.InterpolateDimension(gowid.RenderWithRatio{R: 0.5}, gowid.RenderWithRatio{R: 1}, 0.5))
+	assert.Equal(t, gowid.RenderBox{C: 3, R: 6}, gowid.InterpolateDimension(gowid.RenderBox{C: 2, R: 4}, gowid.RenderBox{C: 4, R: 8}, 0.5))
+	// Different kinds switch half way
+	assert.Equal(t, gowid.RenderFlow{}, gowid.InterpolateDimension(gowid.RenderFlow{}, gowid.RenderWithUnits{U: 3}, 0.4))
+	assert.Equal(t, gowid.RenderWithUnits{U: 3}, gowid.InterpolateDimension(gowid.RenderFlow{}, gowid.RenderWithUnits{U: 3}, 0.5))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,92 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gwtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/list"
+	"github.com/gcla/gowid/widgets/selectable"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+type pausingText struct {
+	*text.Widget
+	events []string
+}
+
+func (w *pausingText) WorkspaceShown(name string, app gowid.IApp) {
+	w.events = append(w.events, "shown "+name)
+}
+
+func (w *pausingText) WorkspaceHidden(name string, app gowid.IApp) {
+	w.events = append(w.events, "hidden "+name)
+}
+
+func TestWorkspaces1(t *testing.T) {
+	items := make([]gowid.IWidget, 0)
+	for i := 0; i < 10; i++ {
+		items = append(items, selectable.New(text.New(fmt.Sprintf("item %d", i))))
+	}
+	capture := list.New(list.NewSimpleListWalker(items))
+	analysis := &pausingText{Widget: text.New("analysis")}
+
+	sim := NewSimT(t, text.New("starting"), SimOptions{Cols: 8, Rows: 3})
+	defer sim.Close()
+
+	events := []string{}
+	assert.NoError(t, sim.AddWorkspace(gowid.Workspace{
+		Name:   "capture",
+		View:   capture,
+		Key:    gowid.MakeKey('1'),
+		OnShow: func(app gowid.IApp) { events = append(events, "show capture") },
+		OnHide: func(app gowid.IApp) { events = append(events, "hide capture") },
+	}))
+	assert.NoError(t, sim.AddWorkspace(gowid.Workspace{
+		Name: "analysis",
+		View: analysis,
+		Key:  gowid.MakeKey('2'),
+	}))
+	assert.Equal(t, gowid.DuplicateWorkspaceError{Name: "analysis"}, sim.AddWorkspace(gowid.Workspace{Name: "analysis"}))
+	assert.Equal(t, []string{"capture", "analysis"}, sim.Workspaces())
+
+	// The first workspace is shown straight away
+	sim.Redraw()
+	sim.Frame()
+	assert.Equal(t, "capture", sim.CurrentWorkspace())
+	assert.Equal(t, []string{"show capture"}, events)
+	sim.AssertLine(t, 0, "item 0  ")
+
+	for i := 0; i < 5; i++ {
+		sim.Key(tcell.KeyDown)
+	}
+	sim.AssertLine(t, 2, "item 5  ")
+
+	sim.Rune('2')
+	assert.Equal(t, "analysis", sim.CurrentWorkspace())
+	sim.AssertLine(t, 0, "analysis")
+	assert.Equal(t, []string{"show capture", "hide capture"}, events)
+	assert.Equal(t, []string{"shown analysis"}, analysis.events)
+
+	// The list is scrolled, and focused, as it was
+	sim.Rune('1')
+	sim.AssertLine(t, 2, "item 5  ")
+	assert.Equal(t, list.ListPos(5), capture.Walker().Focus())
+	assert.Equal(t, []string{"shown analysis", "hidden analysis"}, analysis.events)
+
+	sim.SetWorkspaceKeys(gowid.MakeKeyExt(tcell.KeyTab), nil)
+	sim.Key(tcell.KeyTab)
+	assert.Equal(t, "analysis", sim.CurrentWorkspace())
+	sim.Key(tcell.KeyTab)
+	assert.Equal(t, "capture", sim.CurrentWorkspace())
+
+	assert.False(t, sim.SwitchWorkspace("nowhere"))
+	assert.True(t, sim.RemoveWorkspace("capture"))
+	assert.Equal(t, "analysis", sim.CurrentWorkspace())
+	assert.False(t, sim.RemoveWorkspace("analysis"))
+}
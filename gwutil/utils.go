@@ -304,6 +304,45 @@ func (i RuneOption) String() string {
 
 //======================================================================
 
+// Float64Option is intended to represent an Option[float64]
+type Float64Option struct {
+	some bool
+	val  float64
+}
+
+var _ fmt.Stringer = Float64Option{}
+var _ IOption = Float64Option{}
+
+func SomeFloat64(x float64) Float64Option {
+	return Float64Option{true, x}
+}
+
+func NoneFloat64() Float64Option {
+	return Float64Option{}
+}
+
+func (i Float64Option) IsNone() bool {
+	return !i.some
+}
+
+func (i Float64Option) Value() interface{} {
+	return i.Val()
+}
+
+func (i Float64Option) Val() float64 {
+	if i.IsNone() {
+		panic(errors.New("Called Val on empty Float64Option"))
+	}
+	return i.val
+}
+
+// For fmt.Stringer
+func (i Float64Option) String() string {
+	return OptionString(i)
+}
+
+//======================================================================
+
 const float64EqualityThreshold = 1e-5
 
 // AlmostEqual returns true if its two arguments are within 1e-5 of each other.
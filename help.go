@@ -0,0 +1,309 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+//======================================================================
+
+// IHelpProvider is implemented by a widget that can explain itself. Help returns the text shown when
+// the user asks for help while the widget, or one beneath it with no help of its own, has the focus - or
+// "" to defer to the widgets above it.
+type IHelpProvider interface {
+	Help(app IApp) string
+}
+
+// HelpWidget attaches help to a widget that doesn't provide its own. Otherwise it passes everything
+// through to the widget it wraps.
+type HelpWidget struct {
+	IWidget
+	text string
+}
+
+var _ ICompositeWidget = (*HelpWidget)(nil)
+var _ IHelpProvider = (*HelpWidget)(nil)
+
+func NewHelp(w IWidget, text string) *HelpWidget {
+	return &HelpWidget{
+		IWidget: w,
+		text:    text,
+	}
+}
+
+func (w *HelpWidget) String() string {
+	return fmt.Sprintf("help[%v]", w.IWidget)
+}
+
+func (w *HelpWidget) Help(app IApp) string {
+	return w.text
+}
+
+func (w *HelpWidget) SetHelp(text string, app IApp) {
+	w.text = text
+}
+
+func (w *HelpWidget) SubWidget() IWidget {
+	return w.IWidget
+}
+
+func (w *HelpWidget) SetSubWidget(inner IWidget, app IApp) {
+	w.IWidget = inner
+}
+
+func (w *HelpWidget) SubWidgetSize(size IRenderSize, focus Selector, app IApp) IRenderSize {
+	return size
+}
+
+// FindHelp returns the help for the widget in focus beneath w, and the widget that provided it: the
+// deepest widget on the path to the focus that has help to give. False is returned if there is none.
+func FindHelp(w IWidget, app IApp) (string, IWidget, bool) {
+	var text string
+	var from IWidget
+	FindInHierarchy(w, true, WidgetPredicate(func(w IWidget) bool {
+		if hp, ok := w.(IHelpProvider); ok {
+			if t := hp.Help(app); t != "" {
+				text, from = t, w
+			}
+		}
+		return false
+	}))
+	return text, from, from != nil
+}
+
+//======================================================================
+
+var (
+	// DefaultHelpWidth is the widest help is shown, unless the options say otherwise.
+	DefaultHelpWidth = 40
+	// DefaultNoHelp is shown when no widget on the path to the focus has help, unless the options say
+	// otherwise.
+	DefaultNoHelp = "No help is available here."
+)
+
+// HelpOptions configures contextual help - see App.SetHelp.
+type HelpOptions struct {
+	Key        IKey   // Shows and hides help - F1 if nil
+	Panel      bool   // If true, help is shown in a panel at the right of the screen, rather than beside the widget
+	Width      int    // The widest help is shown - DefaultHelpWidth if 0
+	NoHelp     string // Shown when there's no help for the focus - DefaultNoHelp if empty
+	Foreground IColor // Defaults to black
+	Background IColor // Defaults to light yellow
+}
+
+// helpState shows help for the focus when asked.
+type helpState struct {
+	opts  HelpOptions
+	shown bool
+}
+
+func newHelpState(opts HelpOptions) *helpState {
+	if opts.Key == nil {
+		opts.Key = MakeKeyExt(tcell.KeyF1)
+	}
+	if opts.Width <= 0 {
+		opts.Width = DefaultHelpWidth
+	}
+	if opts.NoHelp == "" {
+		opts.NoHelp = DefaultNoHelp
+	}
+	if opts.Foreground == nil {
+		opts.Foreground = MakeRGBColor("#000")
+	}
+	if opts.Background == nil {
+		opts.Background = MakeRGBColor("#ffa")
+	}
+	return &helpState{opts: opts}
+}
+
+// SetHelp lets the user ask for help with whatever has the focus, by pressing F1 or the key in opts. The
+// help comes from the deepest widget on the path to the focus that is an IHelpProvider with something
+// to say - so a container can explain a whole part of the app, and a widget within it can add detail of
+// its own. Help is shown beside the widget it came from, or in a panel, until the key is pressed again,
+// or any other key. Pass nil to turn help off.
+func (a *App) SetHelp(opts *HelpOptions) {
+	a.help = nil
+	if opts != nil {
+		a.help = newHelpState(*opts)
+	}
+	a.Redraw()
+}
+
+// ShowHelp shows help for the widget in focus, if help is enabled - see SetHelp.
+func (a *App) ShowHelp() {
+	if a.help != nil {
+		a.help.shown = true
+		a.Redraw()
+	}
+}
+
+// HideHelp hides help shown with ShowHelp, or by the user.
+func (a *App) HideHelp() {
+	if a.help != nil && a.help.shown {
+		a.help.shown = false
+		a.Redraw()
+	}
+}
+
+// HelpShown returns true if help is being shown.
+func (a *App) HelpShown() bool {
+	return a.help != nil && a.help.shown
+}
+
+// helpInput shows or hides help. It returns true if the event was consumed.
+func (a *App) helpInput(ev interface{}) bool {
+	if a.help == nil {
+		return false
+	}
+	kev, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return false
+	}
+	switch {
+	case KeysEqual(kev, a.help.opts.Key):
+		if a.help.shown {
+			a.HideHelp()
+		} else {
+			a.ShowHelp()
+		}
+		return true
+	case a.help.shown && kev.Key() == tcell.KeyEscape:
+		a.HideHelp()
+		return true
+	case a.help.shown:
+		// Out of the way of whatever the key does
+		a.HideHelp()
+	}
+	return false
+}
+
+// draw draws help for the focus beneath view on canvas, if it is being shown.
+func (h *helpState) draw(canvas ICanvas, view IWidget, size IRenderSize, app IApp) {
+	if !h.shown {
+		return
+	}
+	cols, rows := canvas.BoxColumns(), canvas.BoxRows()
+	text, from, ok := FindHelp(view, app)
+	if !ok {
+		text = h.opts.NoHelp
+	}
+	mode := app.GetColorMode()
+	blank := MakeCell(' ', IColorToTCell(h.opts.Foreground, ColorNone, mode),
+		IColorToTCell(h.opts.Background, ColorNone, mode), StyleNone)
+
+	width := gwutil.Min(h.opts.Width, cols)
+	if width < 5 || rows < 3 {
+		return
+	}
+	lines := wrapText(text, width-4)
+	var x, y, height int
+	if h.opts.Panel {
+		x, y, height = cols-width, 0, rows
+	} else {
+		natural := 0
+		for _, line := range lines {
+			natural = gwutil.Max(natural, runewidth.StringWidth(line))
+		}
+		width = natural + 4
+		height = gwutil.Min(len(lines)+2, rows)
+		target := Rect{}
+		found := false
+		if ok {
+			target, found = LocateWidget(view, size, Focused, app, func(w IWidget) bool {
+				return isStyleKey(w) && isStyleKey(from) && w == from
+			})
+		}
+		x, y = placeHelp(target, found, width, height, cols, rows)
+	}
+
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			canvas.SetCellAt(x+col, y+row, blank)
+		}
+	}
+	drawHelpFrame(canvas, x, y, width, height, blank, app)
+	for i, line := range lines {
+		if i >= height-2 {
+			break
+		}
+		cx := x + 2
+		for _, r := range line {
+			canvas.SetCellAt(cx, y+1+i, blank.WithRune(r))
+			cx += gwutil.Max(1, runewidth.RuneWidth(r))
+		}
+	}
+}
+
+// placeHelp returns where help of width by height is drawn on a canvas of cols by rows - below the
+// widget it explains if there's room, or else above it, or else in the middle.
+func placeHelp(target Rect, found bool, width, height, cols, rows int) (int, int) {
+	x := gwutil.Max(0, gwutil.Min(target.X, cols-width))
+	switch {
+	case !found:
+	case target.Y+target.Rows+height <= rows:
+		return x, target.Y + target.Rows
+	case target.Y-height >= 0:
+		return x, target.Y - height
+	}
+	return gwutil.Max(0, (cols-width)/2), gwutil.Max(0, (rows-height)/2)
+}
+
+func drawHelpFrame(canvas ICanvas, x, y, width, height int, blank Cell, app IApp) {
+	hor, ver := ASCIIRune(app, '─', '-'), ASCIIRune(app, '│', '|')
+	for col := 1; col < width-1; col++ {
+		canvas.SetCellAt(x+col, y, blank.WithRune(hor))
+		canvas.SetCellAt(x+col, y+height-1, blank.WithRune(hor))
+	}
+	for row := 1; row < height-1; row++ {
+		canvas.SetCellAt(x, y+row, blank.WithRune(ver))
+		canvas.SetCellAt(x+width-1, y+row, blank.WithRune(ver))
+	}
+	canvas.SetCellAt(x, y, blank.WithRune(ASCIIRune(app, '┌', '+')))
+	canvas.SetCellAt(x+width-1, y, blank.WithRune(ASCIIRune(app, '┐', '+')))
+	canvas.SetCellAt(x, y+height-1, blank.WithRune(ASCIIRune(app, '└', '+')))
+	canvas.SetCellAt(x+width-1, y+height-1, blank.WithRune(ASCIIRune(app, '┘', '+')))
+}
+
+// wrapText breaks text into lines no wider than width, between words where it can.
+func wrapText(text string, width int) []string {
+	res := make([]string, 0)
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			for runewidth.StringWidth(word) > width {
+				if line != "" {
+					res = append(res, line)
+					line = ""
+				}
+				head := runewidth.Truncate(word, width, "")
+				res = append(res, head)
+				word = word[len(head):]
+			}
+			switch {
+			case line == "":
+				line = word
+			case runewidth.StringWidth(line)+1+runewidth.StringWidth(word) <= width:
+				line += " " + word
+			default:
+				res = append(res, line)
+				line = word
+			}
+		}
+		res = append(res, line)
+	}
+	return res
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,142 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// IHoverable is implemented by widgets that want to know when the mouse pointer moves onto them, and off
+// them again. Such a widget calls TrackHover from its UserInput.
+type IHoverable interface {
+	IIdentity
+	SetHovered(hovered bool, app IApp)
+}
+
+// IHoverTracker is implemented by an IApp that tracks which widgets the mouse pointer is over, like App.
+type IHoverTracker interface {
+	PointerOver(w IHoverable)
+}
+
+// TrackHover should be called by an IHoverable widget with each event passed to its UserInput. Mouse
+// events reach the widgets under the pointer, so if ev is a mouse event, the app notes that the pointer is
+// over w. Once the event has been processed, the app calls SetHovered on each widget the pointer has moved
+// onto, or off. This only happens if hover tracking is enabled - see App.EnableHover.
+func TrackHover(w IHoverable, ev interface{}, app IApp) {
+	if _, ok := ev.(*tcell.EventMouse); !ok {
+		return
+	}
+	if ht, ok := app.(IHoverTracker); ok {
+		ht.PointerOver(w)
+	}
+}
+
+// Hoverable can be embedded in a widget to record whether the pointer is over it. It provides
+// SetHovered, and StyleState so that a styled widget around it can use a hover style - the widget must
+// provide ID, e.g. by embedding AddressProvidesID, and call TrackHover.
+type Hoverable struct {
+	hovered bool
+}
+
+func (h *Hoverable) Hovered() bool {
+	return h.hovered
+}
+
+func (h *Hoverable) SetHovered(hovered bool, app IApp) {
+	h.hovered = hovered
+}
+
+func (h *Hoverable) StyleState() StyleState {
+	if h.hovered {
+		return StateHover
+	}
+	return 0
+}
+
+//======================================================================
+
+type hoverTracker struct {
+	enabled  bool
+	tracking bool         // True while a mouse event is passed to the widgets
+	over     []IHoverable // The widgets the pointer is over
+	next     []IHoverable // The widgets the pointer is over, according to the current event
+}
+
+// EnableHover turns on hover tracking: widgets that call TrackHover are told when the mouse pointer
+// moves onto or off them, and styled widgets use their hover styles. Every movement of the mouse is then
+// passed to the widgets - otherwise, the app skips movements that follow one another, to save work on
+// slow systems.
+func (a *App) EnableHover() {
+	a.hover.enabled = true
+}
+
+// DisableHover turns off hover tracking. Widgets the pointer is over are told that it has left them.
+func (a *App) DisableHover() {
+	over := a.hover.over
+	a.hover = hoverTracker{}
+	for _, w := range over {
+		w.SetHovered(false, a)
+	}
+}
+
+func (a *App) HoverEnabled() bool {
+	return a.hover.enabled
+}
+
+// IsHovered returns true if the mouse pointer is over w, according to the last mouse event.
+func (a *App) IsHovered(w IIdentity) bool {
+	return hoverIndex(a.hover.over, w) != -1
+}
+
+// PointerOver implements IHoverTracker.
+func (a *App) PointerOver(w IHoverable) {
+	if a.hover.tracking && hoverIndex(a.hover.next, w) == -1 {
+		a.hover.next = append(a.hover.next, w)
+	}
+}
+
+func hoverIndex(ws []IHoverable, w IIdentity) int {
+	for i, h := range ws {
+		if h.ID() == w.ID() {
+			return i
+		}
+	}
+	return -1
+}
+
+// trackHover starts collecting the widgets under the pointer, if ev is a mouse event.
+func (a *App) trackHover(ev interface{}) {
+	if _, ok := ev.(*tcell.EventMouse); ok && a.hover.enabled {
+		a.hover.tracking = true
+		a.hover.next = nil
+	}
+}
+
+// trackHoverDone tells the widgets the pointer has moved onto, or off, after a mouse event.
+func (a *App) trackHoverDone() {
+	if !a.hover.tracking {
+		return
+	}
+	prev, cur := a.hover.over, a.hover.next
+	a.hover.over, a.hover.next, a.hover.tracking = cur, nil, false
+	for _, w := range prev {
+		if hoverIndex(cur, w) == -1 {
+			w.SetHovered(false, a)
+		}
+	}
+	for _, w := range cur {
+		if hoverIndex(prev, w) == -1 {
+			w.SetHovered(true, a)
+		}
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,238 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// IHyperlinker is implemented by an ICellStyler whose text links to a URL, like Hyperlink.
+type IHyperlinker interface {
+	Hyperlink() string
+}
+
+// HyperlinkOf returns the URL that text styled by styler links to, or "" if there is none.
+func HyperlinkOf(styler ICellStyler) string {
+	if h, ok := styler.(IHyperlinker); ok {
+		return h.Hyperlink()
+	}
+	return ""
+}
+
+// Hyperlink is an ICellStyler for text that links to URL. The text is styled with Style, which may be
+// nil. On terminals that support OSC 8, the user can open the link, e.g. by clicking it; elsewhere
+// the text is underlined.
+type Hyperlink struct {
+	URL   string
+	Style ICellStyler
+}
+
+var _ ICellStyler = Hyperlink{}
+var _ IHyperlinker = Hyperlink{}
+
+// MakeHyperlink returns a Hyperlink to url, styled with style if one is provided.
+func MakeHyperlink(url string, style ...ICellStyler) Hyperlink {
+	res := Hyperlink{URL: url}
+	if len(style) > 0 {
+		res.Style = style[0]
+	}
+	return res
+}
+
+func (h Hyperlink) Hyperlink() string {
+	return h.URL
+}
+
+// GetStyle implements ICellStyler.
+func (h Hyperlink) GetStyle(prov IRenderContext) (x IColor, y IColor, z StyleAttrs) {
+	if h.Style == nil {
+		return NoColor{}, NoColor{}, StyleNone
+	}
+	return h.Style.GetStyle(prov)
+}
+
+//======================================================================
+
+// osc8Supported guesses whether the terminal described by the environment supports OSC 8 hyperlinks.
+// Terminals that don't understand the sequence may display it, so unlike OSC 52, only terminals known
+// to support it are ruled in. Inside tmux or GNU screen the outer terminal can't be identified.
+func osc8Supported(getenv func(string) string) bool {
+	term := getenv("TERM")
+	if getenv("TMUX") != "" || strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux") {
+		return false
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "Hyper", "ghostty":
+		return true
+	}
+	if v, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && v >= 5000 {
+		return true
+	}
+	switch term {
+	case "xterm-kitty", "alacritty", "foot", "foot-extra", "wezterm", "xterm-ghostty", "contour":
+		return true
+	}
+	return false
+}
+
+// hyperlinkRun is a sequence of cells on one line of the screen that link to the same URL.
+type hyperlinkRun struct {
+	X, Y  int
+	URL   string
+	Cells []Cell
+}
+
+// hyperlinkRuns returns the runs of linked cells in canvas.
+func hyperlinkRuns(canvas IDrawCanvas) []hyperlinkRun {
+	res := make([]hyperlinkRun, 0)
+	for y := 0; y < canvas.BoxRows(); y++ {
+		line := canvas.Line(y, LineCopy{}).Line
+		var cur *hyperlinkRun
+		for x := 0; x < len(line); {
+			c := line[x]
+			switch {
+			case c.Hyperlink() == "":
+				cur = nil
+			case cur != nil && cur.URL == c.Hyperlink():
+				cur.Cells = append(cur.Cells, c)
+			default:
+				res = append(res, hyperlinkRun{X: x, Y: y, URL: c.Hyperlink(), Cells: []Cell{c}})
+				cur = &res[len(res)-1]
+			}
+			x += gwutil.Max(1, c.Width())
+		}
+	}
+	return res
+}
+
+// sgrColor returns the SGR parameters to set a foreground color, or a background color if base is 40.
+func sgrColor(c TCellColor, base int) string {
+	tc := c.ToTCell()
+	switch {
+	case c == ColorNone || tc == tcell.ColorDefault:
+		return strconv.Itoa(base + 9)
+	case tc&tcell.ColorIsRGB != 0:
+		r, g, b := tc.RGB()
+		return fmt.Sprintf("%d;2;%d;%d;%d", base+8, r, g, b)
+	case tc < 8:
+		return strconv.Itoa(base + int(tc))
+	case tc < 16:
+		return strconv.Itoa(base + 60 + int(tc) - 8)
+	default:
+		return fmt.Sprintf("%d;5;%d", base+8, int(tc))
+	}
+}
+
+// sgr returns the escape sequence to draw text with c's colors and styling.
+func sgr(c Cell) string {
+	params := []string{"0"}
+	st := c.Style()
+	for _, a := range []struct {
+		mask tcell.AttrMask
+		code string
+	}{
+		{tcell.AttrBold, "1"},
+		{tcell.AttrDim, "2"},
+		{tcell.AttrUnderline, "4"},
+		{tcell.AttrBlink, "5"},
+		{tcell.AttrReverse, "7"},
+	} {
+		if st.OnOff&st.Set&a.mask != 0 {
+			params = append(params, a.code)
+		}
+	}
+	params = append(params, sgrColor(c.ForegroundColor(), 30), sgrColor(c.BackgroundColor(), 40))
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+// sanitizeURL removes control characters, which would end the OSC 8 sequence early.
+func sanitizeURL(url string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, url)
+}
+
+// osc8Sequence returns the escape sequences to redraw each run of cells as a hyperlink. tcell has no
+// support for OSC 8, so after it has drawn the screen, the linked cells are drawn again with the link
+// set. The cursor position and text attributes are saved and restored around the runs.
+func osc8Sequence(runs []hyperlinkRun) string {
+	if len(runs) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\x1b7")
+	for _, run := range runs {
+		fmt.Fprintf(&b, "\x1b[%d;%dH\x1b]8;;%s\x1b\\", run.Y+1, run.X+1, sanitizeURL(run.URL))
+		last := ""
+		for _, c := range run.Cells {
+			if s := sgr(c); s != last {
+				b.WriteString(s)
+				last = s
+			}
+			b.WriteRune(c.Rune())
+			b.WriteString(string(c.Combining()))
+		}
+		b.WriteString("\x1b]8;;\x1b\\")
+	}
+	b.WriteString("\x1b[0m\x1b8")
+	return b.String()
+}
+
+//======================================================================
+
+// HyperlinksSupported returns true if the terminal is thought to support OSC 8 hyperlinks, or if
+// support has been declared with SetHyperlinksSupported.
+func (a *App) HyperlinksSupported() bool {
+	if a.hyperlinkSupport != nil {
+		return *a.hyperlinkSupport
+	}
+	return osc8Supported(a.Getenv)
+}
+
+// SetHyperlinksSupported overrides the app's guess as to whether the terminal supports OSC 8. If
+// not, linked text is underlined instead.
+func (a *App) SetHyperlinksSupported(supported bool) {
+	a.hyperlinkSupport = &supported
+	a.Redraw()
+}
+
+// prepareHyperlinks is called with each frame before it is drawn. If the terminal supports OSC 8, the
+// frame's linked cells are collected so they can be redrawn as links; otherwise they are underlined.
+func (a *App) prepareHyperlinks(canvas ICanvas) {
+	a.links = nil
+	if a.HyperlinksSupported() {
+		a.links = hyperlinkRuns(canvas)
+		return
+	}
+	RangeOverCanvas(canvas, CellRangeFunc(func(c Cell) Cell {
+		if c.Hyperlink() != "" {
+			c = c.WithStyle(c.Style().MergeUnder(StyleUnderline))
+		}
+		return c
+	}))
+}
+
+// drawHyperlinks is called after each frame is shown, to draw the frame's links.
+func (a *App) drawHyperlinks() {
+	if len(a.links) > 0 {
+		a.writeToTerminal(osc8Sequence(a.links))
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,64 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOSC8Supported1(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(k string) string {
+			return vars[k]
+		}
+	}
+	assert.True(t, osc8Supported(env(map[string]string{"TERM": "xterm-kitty"})))
+	assert.True(t, osc8Supported(env(map[string]string{"TERM": "xterm-256color", "TERM_PROGRAM": "iTerm.app"})))
+	assert.True(t, osc8Supported(env(map[string]string{"TERM": "xterm-256color", "VTE_VERSION": "6003"})))
+	assert.False(t, osc8Supported(env(map[string]string{"TERM": "xterm-256color", "VTE_VERSION": "4205"})))
+	assert.False(t, osc8Supported(env(map[string]string{"TERM": "xterm-256color"})))
+	assert.False(t, osc8Supported(env(map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux-0/default"})))
+	assert.False(t, osc8Supported(env(map[string]string{"TERM": "linux"})))
+}
+
+func TestHyperlinkRuns1(t *testing.T) {
+	c := NewCanvasOfSize(8, 2)
+	link := CellFromRune('a').WithHyperlink("https://a.example")
+	c.SetCellAt(1, 0, link)
+	c.SetCellAt(2, 0, link.WithForegroundColor(ColorRed))
+	c.SetCellAt(3, 0, CellFromRune('b').WithHyperlink("https://b.example"))
+	c.SetCellAt(6, 1, link)
+
+	runs := hyperlinkRuns(c)
+	assert.Equal(t, 3, len(runs))
+	assert.Equal(t, 1, runs[0].X)
+	assert.Equal(t, 2, len(runs[0].Cells))
+	assert.Equal(t, "https://b.example", runs[1].URL)
+	assert.Equal(t, 6, runs[2].X)
+	assert.Equal(t, 1, runs[2].Y)
+
+	assert.Equal(t,
+		"\x1b7\x1b[1;2H\x1b]8;;https://a.example\x1b\\\x1b[0;39;49ma\x1b[0;91;49ma\x1b]8;;\x1b\\\x1b[0m\x1b8",
+		osc8Sequence(runs[0:1]))
+	assert.Equal(t, "", osc8Sequence(nil))
+	assert.Equal(t, "https://x.example/a\\b", sanitizeURL("https://x.example/a\x1b\\b"))
+
+	// The link is kept when cells are layered
+	assert.Equal(t, "https://a.example", CellFromRune('x').MergeUnder(link).Hyperlink())
+	assert.Equal(t, "https://a.example", link.MergeUnder(CellFromRune('x')).Hyperlink())
+}
+
+func TestSGR1(t *testing.T) {
+	c := MakeCell('x', MakeTCellColorExt(208), MakeTCellColorExt(12), StyleBold.MergeUnder(StyleUnderline))
+	assert.Equal(t, "\x1b[0;1;4;38;5;208;104m", sgr(c))
+	assert.Equal(t, "\x1b[0;39;49m", sgr(CellFromRune('x').WithStyle(StyleAttrs{OnOff: 0, Set: StyleAllSet})))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,82 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+)
+
+//======================================================================
+
+// Justify says where a container of widgets laid out in a line, like columns or pile, puts the space
+// its widgets leave over - if none of them are weighted, there may be some.
+type Justify int
+
+const (
+	JustifyStart        Justify = iota // All the space after the last widget - the default
+	JustifyEnd                         // All the space before the first widget
+	JustifyCenter                      // Split before the first widget and after the last
+	JustifySpaceBetween                // Split evenly between the widgets
+)
+
+func (j Justify) String() string {
+	switch j {
+	case JustifyStart:
+		return "start"
+	case JustifyEnd:
+		return "end"
+	case JustifyCenter:
+		return "center"
+	case JustifySpaceBetween:
+		return "space-between"
+	default:
+		return fmt.Sprintf("justify(%d)", int(j))
+	}
+}
+
+// IJustify is implemented by containers whose leftover space is placed as configured.
+type IJustify interface {
+	Justify() Justify
+}
+
+// JustifyOf returns the container's Justify, or JustifyStart if it doesn't say.
+func JustifyOf(w interface{}) Justify {
+	if j, ok := w.(IJustify); ok {
+		return j.Justify()
+	}
+	return JustifyStart
+}
+
+// JustifyGaps returns the space to leave before each of n widgets, with the space after the last widget
+// at the end, so that leftover is placed as j says. The n+1 gaps add up to leftover.
+func JustifyGaps(j Justify, leftover int, n int) []int {
+	res := make([]int, n+1)
+	if leftover <= 0 {
+		return res
+	}
+	switch {
+	case j == JustifyEnd:
+		res[0] = leftover
+	case j == JustifyCenter:
+		res[0] = leftover / 2
+		res[n] = leftover - res[0]
+	case j == JustifySpaceBetween && n > 1:
+		for i := 1; i < n; i++ {
+			res[i] = leftover / (n - 1)
+			if i <= leftover%(n-1) {
+				res[i]++
+			}
+		}
+	default:
+		res[n] = leftover
+	}
+	return res
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,260 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// Corner is a corner of the screen.
+type Corner int
+
+const (
+	CornerBottomRight Corner = iota
+	CornerBottomLeft
+	CornerTopRight
+	CornerTopLeft
+)
+
+func (c Corner) String() string {
+	switch c {
+	case CornerBottomRight:
+		return "bottom-right"
+	case CornerBottomLeft:
+		return "bottom-left"
+	case CornerTopRight:
+		return "top-right"
+	case CornerTopLeft:
+		return "top-left"
+	default:
+		return fmt.Sprintf("corner(%d)", int(c))
+	}
+}
+
+var (
+	// DefaultKeyCastKeys is the most keys shown at once, unless the options say otherwise.
+	DefaultKeyCastKeys = 6
+	// DefaultKeyCastDuration is how long a key is shown, including its fade, unless the options say
+	// otherwise.
+	DefaultKeyCastDuration = 2 * time.Second
+	// DefaultKeyCastFade is how long a key takes to fade out, unless the options say otherwise.
+	DefaultKeyCastFade = 500 * time.Millisecond
+)
+
+// KeyCastOptions configures the display of recently pressed keys - see App.SetKeyCast.
+type KeyCastOptions struct {
+	Corner     Corner        // Where the keys are shown - the bottom right by default
+	Keys       int           // The most keys shown at once - DefaultKeyCastKeys if 0
+	Duration   time.Duration // How long a key is shown - DefaultKeyCastDuration if 0
+	Fade       time.Duration // How long a key takes to fade out at the end - DefaultKeyCastFade if 0
+	Foreground IColor        // Defaults to white
+	Background IColor        // Defaults to dark gray
+}
+
+// castKey is a key displayed by the key caster - count presses of it in a row.
+type castKey struct {
+	name  string
+	count int
+	at    time.Time // When it was last pressed
+}
+
+// keyCaster displays the keys pressed recently, for screencasts.
+type keyCaster struct {
+	opts  KeyCastOptions
+	keys  []castKey // Oldest first
+	timer *Timer
+}
+
+func newKeyCaster(opts KeyCastOptions) *keyCaster {
+	if opts.Keys <= 0 {
+		opts.Keys = DefaultKeyCastKeys
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = DefaultKeyCastDuration
+	}
+	if opts.Fade <= 0 {
+		opts.Fade = DefaultKeyCastFade
+	}
+	opts.Fade = minDuration(opts.Fade, opts.Duration)
+	if opts.Foreground == nil {
+		opts.Foreground = MakeRGBColor("#fff")
+	}
+	if opts.Background == nil {
+		opts.Background = MakeRGBColor("#333")
+	}
+	return &keyCaster{opts: opts}
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// SetKeyCast shows the keys pressed recently in a corner of the screen, as in a screencast - handy when
+// recording a tutorial, or pairing over a screen share. A key pressed several times in a row is shown
+// once, with a count, and each key fades away after a while. Pass nil to stop.
+func (a *App) SetKeyCast(opts *KeyCastOptions) {
+	if a.keyCast != nil {
+		a.keyCast.stop()
+	}
+	a.keyCast = nil
+	if opts != nil {
+		a.keyCast = newKeyCaster(*opts)
+	}
+	a.Redraw()
+}
+
+// KeyCast returns true if recently pressed keys are being shown - see SetKeyCast.
+func (a *App) KeyCast() bool {
+	return a.keyCast != nil
+}
+
+// keyCastInput records ev if it is a keypress to be shown.
+func (a *App) keyCastInput(ev interface{}) {
+	if a.keyCast == nil {
+		return
+	}
+	if kev, ok := ev.(*tcell.EventKey); ok {
+		a.keyCast.add(KeyCastName(kev), a)
+	}
+}
+
+// KeyCastName returns the name with which a key is shown by the app's key cast - e.g. "Ctrl+S", "Space"
+// or "g".
+func KeyCastName(k IKey) string {
+	res := MakeKeyExt2(k.Modifiers(), k.Key(), k.Rune()).String()
+	switch {
+	case res == " ":
+		res = "Space"
+	case strings.HasPrefix(res, "Ctrl-"):
+		res = "Ctrl+" + res[5:]
+	}
+	return res
+}
+
+func (k *keyCaster) add(name string, app IApp) {
+	now := ClockFor(app).Now()
+	k.expire(now)
+	if n := len(k.keys); n > 0 && k.keys[n-1].name == name {
+		k.keys[n-1].count++
+		k.keys[n-1].at = now
+	} else {
+		k.keys = append(k.keys, castKey{name: name, count: 1, at: now})
+		if len(k.keys) > k.opts.Keys {
+			k.keys = k.keys[len(k.keys)-k.opts.Keys:]
+		}
+	}
+	k.schedule(app)
+}
+
+// expire forgets the keys no longer shown at now.
+func (k *keyCaster) expire(now time.Time) {
+	i := 0
+	for i < len(k.keys) && now.Sub(k.keys[i].at) >= k.opts.Duration {
+		i++
+	}
+	k.keys = k.keys[i:]
+}
+
+func (k *keyCaster) stop() {
+	if k.timer != nil {
+		k.timer.Stop()
+		k.timer = nil
+	}
+}
+
+// schedule arranges for the display to be redrawn when the next key starts to fade, and then with each
+// frame of its fade, until no keys are left.
+func (k *keyCaster) schedule(app IApp) {
+	k.stop()
+	if len(k.keys) == 0 {
+		return
+	}
+	clock := ClockFor(app)
+	wait := k.opts.Duration - k.opts.Fade - clock.Since(k.keys[0].at)
+	if wait <= 0 {
+		wait = DefaultTweenFrameInterval
+	}
+	k.timer = After(app, wait, func(app IApp) {
+		k.timer = nil
+		k.expire(ClockFor(app).Now())
+		k.schedule(app)
+	})
+}
+
+// draw draws the keys shown at now in the corner of canvas, newest nearest the corner.
+func (k *keyCaster) draw(canvas ICanvas, now time.Time, mode ColorMode, app IApp) {
+	cols, rows := canvas.BoxColumns(), canvas.BoxRows()
+	if rows == 0 {
+		return
+	}
+	bg := IColorToTCell(k.opts.Background, ColorNone, mode)
+
+	// From the newest key back, as many as fit with a margin of a column on either side
+	labels := make([][]rune, 0, len(k.keys))
+	fgs := make([]TCellColor, 0, len(k.keys))
+	width := 0
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		key := k.keys[i]
+		age := now.Sub(key.at)
+		if age >= k.opts.Duration {
+			continue
+		}
+		label := " " + key.name + " "
+		if key.count > 1 {
+			label = fmt.Sprintf(" %s %c%d ", key.name, ASCIIRune(app, '×', 'x'), key.count)
+		}
+		l := []rune(label)
+		if width+len(l)+1 > cols-1 {
+			break
+		}
+		fg := k.opts.Foreground
+		if fadeAt := k.opts.Duration - k.opts.Fade; age > fadeAt {
+			fg = InterpolateColor(k.opts.Foreground, k.opts.Background, float64(age-fadeAt)/float64(k.opts.Fade), RGBSpace)
+		}
+		labels = append(labels, l)
+		fgs = append(fgs, IColorToTCell(fg, ColorNone, mode))
+		width += len(l) + 1
+	}
+	if len(labels) == 0 {
+		return
+	}
+	width--
+
+	y := rows - 1
+	if rows > 2 {
+		y = rows - 2
+	}
+	if k.opts.Corner == CornerTopRight || k.opts.Corner == CornerTopLeft {
+		y = gwutil.Min(1, rows-1)
+	}
+	x := cols - 1 - width
+	if k.opts.Corner == CornerBottomLeft || k.opts.Corner == CornerTopLeft {
+		x = 1
+	}
+	// Oldest first, from the left
+	for i := len(labels) - 1; i >= 0; i-- {
+		for _, r := range labels[i] {
+			canvas.SetCellAt(x, y, MakeCell(r, fgs[i], bg, StyleNone))
+			x++
+		}
+		x++
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,228 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+)
+
+//======================================================================
+
+// InvalidKeyboardLayoutError is returned when a keyboard layout's keys don't line up with those of the US
+// QWERTY layout, or a layout is asked for by a name that isn't known.
+type InvalidKeyboardLayoutError struct {
+	Name string
+}
+
+var _ error = InvalidKeyboardLayoutError{}
+
+func (e InvalidKeyboardLayoutError) Error() string {
+	return fmt.Sprintf("Invalid keyboard layout %q", e.Name)
+}
+
+// qwertyKeys are the unshifted characters of the US QWERTY layout, row by row. Other layouts are given
+// as the characters typed at the same positions.
+const qwertyKeys = "`1234567890-=qwertyuiop[]\\asdfghjkl;'zxcvbnm,./"
+
+// KeyboardLayout maps the characters typed on a keyboard layout to the positions of the keys that type
+// them. Positions are named by the character the US QWERTY layout types there - so on AZERTY, the key
+// that types "a" is at position "q".
+type KeyboardLayout struct {
+	Name      string
+	toPos     map[rune]rune
+	fromPos   map[rune]rune
+	isDefault bool
+}
+
+// NewKeyboardLayout returns a layout named name, whose keys type the characters of keys - listed in the
+// order of the US QWERTY keys "`1234567890-=", "qwertyuiop[]\", "asdfghjkl;'" and "zxcvbnm,./", with
+// no spaces. Letters are mapped in upper case too.
+func NewKeyboardLayout(name string, keys string) (*KeyboardLayout, error) {
+	typed := []rune(keys)
+	pos := []rune(qwertyKeys)
+	if len(typed) != len(pos) {
+		return nil, errors.WithStack(InvalidKeyboardLayoutError{Name: name})
+	}
+	res := &KeyboardLayout{
+		Name:      name,
+		toPos:     make(map[rune]rune, 2*len(pos)),
+		fromPos:   make(map[rune]rune, 2*len(pos)),
+		isDefault: keys == qwertyKeys,
+	}
+	for i, p := range pos {
+		t := typed[i]
+		res.toPos[t] = p
+		res.fromPos[p] = t
+		if unicode.IsLetter(p) && unicode.IsLetter(t) {
+			res.toPos[unicode.ToUpper(t)] = unicode.ToUpper(p)
+			res.fromPos[unicode.ToUpper(p)] = unicode.ToUpper(t)
+		}
+	}
+	return res, nil
+}
+
+func mustKeyboardLayout(name string, keys string) *KeyboardLayout {
+	res, err := NewKeyboardLayout(name, keys)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+var (
+	LayoutQWERTY  = mustKeyboardLayout("qwerty", qwertyKeys)
+	LayoutAZERTY  = mustKeyboardLayout("azerty", "²&é\"'(-è_çà)=azertyuiop^$*qsdfghjklmùwxcvbn,;:!")
+	LayoutQWERTZ  = mustKeyboardLayout("qwertz", "^1234567890ß´qwertzuiopü+#asdfghjklöäyxcvbnm,.-")
+	LayoutDvorak  = mustKeyboardLayout("dvorak", "`1234567890[]',.pyfgcrl/=\\aoeuidhtns-;qjkxbmwvz")
+	LayoutColemak = mustKeyboardLayout("colemak", "`1234567890-=qwfpgjluy;[]\\arstdhneio'zxcvbkm,./")
+)
+
+// KeyboardLayouts are the layouts known by name to KeyboardLayoutByName. Add to it to support others.
+var KeyboardLayouts = []*KeyboardLayout{LayoutQWERTY, LayoutAZERTY, LayoutQWERTZ, LayoutDvorak, LayoutColemak}
+
+// KeyboardLayoutByName returns the layout in KeyboardLayouts with the name given, ignoring case - e.g. from
+// a user's configuration file.
+func KeyboardLayoutByName(name string) (*KeyboardLayout, error) {
+	for _, l := range KeyboardLayouts {
+		if strings.EqualFold(l.Name, name) {
+			return l, nil
+		}
+	}
+	return nil, errors.WithStack(InvalidKeyboardLayoutError{Name: name})
+}
+
+// Position returns the key at the position of k on the US QWERTY layout - e.g. on AZERTY, "z" is at
+// position "w", and Ctrl-Z at Ctrl-W. Keys without a character, like Enter, are returned as they are.
+func (l *KeyboardLayout) Position(k IKey) IKey {
+	return l.translate(k, l.toPos)
+}
+
+// Typed returns the key typed at the position named by k on the US QWERTY layout - the inverse of
+// Position. Use it to show a key bound by position as the user sees it.
+func (l *KeyboardLayout) Typed(k IKey) IKey {
+	return l.translate(k, l.fromPos)
+}
+
+func (l *KeyboardLayout) translate(k IKey, m map[rune]rune) IKey {
+	if l.isDefault {
+		return k
+	}
+	switch {
+	case k.Key() == tcell.KeyRune:
+		if r, ok := m[k.Rune()]; ok {
+			return MakeKeyExt2(k.Modifiers(), tcell.KeyRune, r)
+		}
+	case k.Key() >= tcell.KeyCtrlA && k.Key() <= tcell.KeyCtrlZ && !isTypeableControl(k.Key()):
+		// Terminals send Ctrl with a letter as a control character, named after the letter typed
+		if r, ok := m[rune('a'+k.Key()-tcell.KeyCtrlA)]; ok && r >= 'a' && r <= 'z' {
+			return MakeKeyExt2(k.Modifiers(), tcell.KeyCtrlA+tcell.Key(r-'a'), rune(tcell.KeyCtrlA)+r-'a')
+		}
+	}
+	return k
+}
+
+// isTypeableControl returns true for the control characters sent by keys of their own, like Tab and
+// Enter, which are in the same place whatever the layout.
+func isTypeableControl(k tcell.Key) bool {
+	switch k {
+	case tcell.KeyBackspace, tcell.KeyTab, tcell.KeyEnter, tcell.KeyLF:
+		return true
+	}
+	return false
+}
+
+//======================================================================
+
+// KeyMatch says how a KeyMap matches keypresses against its bindings.
+type KeyMatch int
+
+const (
+	// MatchCharacter binds keys by the character they type, so "Ctrl-Z" is the key labeled Z, wherever
+	// it is on the keyboard. This is the default.
+	MatchCharacter KeyMatch = iota
+	// MatchPosition binds keys by where they are on the keyboard, named as on the US QWERTY layout - so
+	// "Ctrl-Z" and "Ctrl-Y" stay side by side at the bottom left, even on AZERTY, and vim-style "h j k l"
+	// stay in a row on Dvorak.
+	MatchPosition
+)
+
+// SetLayout tells the key map the user's keyboard layout, and whether bindings name keys by the
+// character they type or by their position - see KeyMatch. A nil layout is US QWERTY.
+func (m *KeyMap) SetLayout(layout *KeyboardLayout, match KeyMatch) {
+	m.layout = layout
+	m.match = match
+}
+
+// Layout returns the user's keyboard layout, and how keys are matched - see SetLayout.
+func (m *KeyMap) Layout() (*KeyboardLayout, KeyMatch) {
+	return m.layout, m.match
+}
+
+// boundKey returns the key, as bindings name it, of a key the user pressed.
+func (m *KeyMap) boundKey(k IKey) IKey {
+	if m.match == MatchPosition && m.layout != nil {
+		return m.layout.Position(k)
+	}
+	return k
+}
+
+// KeyLabel returns the bound key sequence keys as the user types it on their keyboard layout, in the form
+// accepted by ParseKeySequence - e.g. "Ctrl-W" for "Ctrl-Z" bound by position on AZERTY. Use it to show
+// shortcuts in menus and help.
+func (m *KeyMap) KeyLabel(keys []IKey) string {
+	if m.match != MatchPosition || m.layout == nil {
+		return KeySequenceString(keys)
+	}
+	typed := make([]IKey, 0, len(keys))
+	for _, k := range keys {
+		typed = append(typed, m.layout.Typed(k))
+	}
+	return KeySequenceString(typed)
+}
+
+// Rebind moves the binding for the key sequence seq to the sequence to, keeping its action and help - e.g.
+// to apply a user's preferences over an app's default bindings. If to is bound already, or would conflict
+// with another binding, seq stays bound and a KeyBindingConflictError is returned.
+func (m *KeyMap) Rebind(seq string, to string) error {
+	from, err := ParseKeySequence(seq)
+	if err != nil {
+		return err
+	}
+	keys, err := ParseKeySequence(to)
+	if err != nil {
+		return err
+	}
+	for _, b := range m.bindings {
+		if len(b.Keys) == len(keys) && keySequenceHasPrefix(b.Keys, keys) &&
+			!(len(b.Keys) == len(from) && keySequenceHasPrefix(b.Keys, from)) {
+			return errors.WithStack(KeyBindingConflictError{Keys: KeySequenceString(keys), Existing: b.String()})
+		}
+	}
+	for i, b := range m.bindings {
+		if len(b.Keys) != len(from) || !keySequenceHasPrefix(b.Keys, from) {
+			continue
+		}
+		saved := m.Bindings()
+		m.bindings = append(m.bindings[:i], m.bindings[i+1:]...)
+		if err := m.BindKeys(keys, b.Help, b.Action); err != nil {
+			m.bindings = saved
+			return err
+		}
+		m.pending = nil
+		return nil
+	}
+	return errors.WithStack(InvalidKeyError{Name: seq})
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,63 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestKeyboardLayout1(t *testing.T) {
+	assert.Equal(t, MakeKey('q'), LayoutAZERTY.Position(MakeKey('a')))
+	assert.Equal(t, MakeKey('A'), LayoutAZERTY.Typed(MakeKey('Q')))
+	assert.Equal(t, MakeKeyExt(tcell.KeyEnter), LayoutAZERTY.Position(MakeKeyExt(tcell.KeyEnter)))
+	assert.Equal(t, tcell.KeyCtrlW, LayoutAZERTY.Position(MakeKeyExt(tcell.KeyCtrlZ)).Key())
+	assert.Equal(t, tcell.KeyTab, LayoutDvorak.Position(MakeKeyExt(tcell.KeyTab)).Key())
+
+	l, err := KeyboardLayoutByName("Dvorak")
+	assert.NoError(t, err)
+	assert.Equal(t, LayoutDvorak, l)
+	_, err = KeyboardLayoutByName("klingon")
+	assert.IsType(t, InvalidKeyboardLayoutError{}, errors.Cause(err))
+	_, err = NewKeyboardLayout("short", "abc")
+	assert.Error(t, err)
+}
+
+func TestKeyMapLayout1(t *testing.T) {
+	undo := 0
+	m := NewKeyMap()
+	assert.NoError(t, m.Bind("Ctrl-Z", "undo", func(app IApp) { undo++ }))
+
+	// By character, Ctrl-Z is the key that types z
+	assert.True(t, m.HandleKey(tcell.NewEventKey(tcell.KeyCtrlZ, rune(tcell.KeyCtrlZ), tcell.ModCtrl), nil))
+	assert.Equal(t, 1, undo)
+
+	// By position on AZERTY, it's the key that types w
+	m.SetLayout(LayoutAZERTY, MatchPosition)
+	assert.False(t, m.HandleKey(tcell.NewEventKey(tcell.KeyCtrlZ, rune(tcell.KeyCtrlZ), tcell.ModCtrl), nil))
+	assert.True(t, m.HandleKey(tcell.NewEventKey(tcell.KeyCtrlW, rune(tcell.KeyCtrlW), tcell.ModCtrl), nil))
+	assert.Equal(t, 2, undo)
+	assert.Equal(t, "Ctrl-W", m.KeyLabel(m.Bindings()[0].Keys))
+
+	assert.NoError(t, m.Bind("u", "up", nil))
+	err := m.Rebind("Ctrl-Z", "u")
+	assert.IsType(t, KeyBindingConflictError{}, errors.Cause(err))
+	assert.NoError(t, m.Rebind("Ctrl-Z", "Alt-z"))
+	bs := m.Bindings()
+	assert.Equal(t, 2, len(bs))
+	assert.Equal(t, "undo", bs[1].Help)
+	assert.Equal(t, "Alt+w", m.KeyLabel(bs[1].Keys))
+	assert.Error(t, m.Rebind("Ctrl-Z", "x"))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,327 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+)
+
+//======================================================================
+
+// InvalidKeyError is returned when a key or key sequence can't be parsed.
+type InvalidKeyError struct {
+	Name string
+}
+
+var _ error = InvalidKeyError{}
+
+func (e InvalidKeyError) Error() string {
+	return fmt.Sprintf("Invalid key %q", e.Name)
+}
+
+// KeyBindingConflictError is returned when a key sequence can't be bound because it starts with a sequence
+// that is already bound, or because a bound sequence starts with it - e.g. "g" and "g g".
+type KeyBindingConflictError struct {
+	Keys     string
+	Existing string
+}
+
+var _ error = KeyBindingConflictError{}
+
+func (e KeyBindingConflictError) Error() string {
+	return fmt.Sprintf("Key sequence %q conflicts with bound sequence %q", e.Keys, e.Existing)
+}
+
+//======================================================================
+
+// keyNames maps the lower-case names of tcell's keys to the keys.
+var keyNames = func() map[string]tcell.Key {
+	res := make(map[string]tcell.Key, len(tcell.KeyNames)+8)
+	for k, name := range tcell.KeyNames {
+		res[strings.ToLower(name)] = k
+	}
+	// tcell names these keys by what they usually are, not by how they're typed
+	res["ctrl-h"] = tcell.KeyCtrlH
+	res["ctrl-i"] = tcell.KeyCtrlI
+	res["ctrl-m"] = tcell.KeyCtrlM
+	res["ctrl-["] = tcell.KeyEsc
+	res["escape"] = tcell.KeyEsc
+	res["return"] = tcell.KeyEnter
+	return res
+}()
+
+var keyModifiers = []struct {
+	name string
+	mask tcell.ModMask
+}{
+	{"ctrl", tcell.ModCtrl},
+	{"alt", tcell.ModAlt},
+	{"meta", tcell.ModMeta},
+	{"shift", tcell.ModShift},
+}
+
+// ParseKey returns the Key with the name provided. A name is a single character, like "g" or "?"; "Space";
+// or the name tcell gives a key, like "Enter", "Esc", "PgDn", "F5" or "Ctrl-X". A name can be prefixed by
+// modifiers - "Ctrl", "Alt", "Meta" or "Shift" - each followed by "-" or "+", e.g. "Alt-x" or
+// "Shift+Left". Names are not case-sensitive, apart from single characters.
+func ParseKey(name string) (Key, error) {
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		return MakeKey(r), nil
+	}
+	lname := strings.ToLower(name)
+	if lname == "space" {
+		return MakeKey(' '), nil
+	}
+	if k, ok := keyNames[lname]; ok {
+		return MakeKeyExt(k), nil
+	}
+	for _, mod := range keyModifiers {
+		if len(lname) <= len(mod.name)+1 || !strings.HasPrefix(lname, mod.name) {
+			continue
+		}
+		if sep := lname[len(mod.name)]; sep != '-' && sep != '+' {
+			continue
+		}
+		rest := name[len(mod.name)+1:]
+		if mod.mask == tcell.ModCtrl {
+			// Ctrl+x is delivered by tcell as the key Ctrl-X
+			if k, ok := keyNames["ctrl-"+strings.ToLower(rest)]; ok {
+				return MakeKeyExt(k), nil
+			}
+		}
+		k, err := ParseKey(rest)
+		if err != nil {
+			break
+		}
+		return MakeKeyExt2(k.mod|mod.mask, k.key, k.ch), nil
+	}
+	return Key{}, errors.WithStack(InvalidKeyError{Name: name})
+}
+
+// ParseKeySequence returns the keys named in seq, separated by spaces - e.g. "g g" or "Ctrl-x Ctrl-c".
+// See ParseKey.
+func ParseKeySequence(seq string) ([]IKey, error) {
+	names := strings.Fields(seq)
+	if len(names) == 0 {
+		return nil, errors.WithStack(InvalidKeyError{Name: seq})
+	}
+	res := make([]IKey, 0, len(names))
+	for _, name := range names {
+		k, err := ParseKey(name)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, k)
+	}
+	return res, nil
+}
+
+// KeySequenceString returns keys in the form accepted by ParseKeySequence.
+func KeySequenceString(keys []IKey) string {
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		name := MakeKeyExt2(k.Modifiers(), k.Key(), k.Rune()).String()
+		if name == " " {
+			name = "Space"
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, " ")
+}
+
+//======================================================================
+
+// KeyAction is run when the keys it is bound to are pressed.
+type KeyAction func(app IApp)
+
+// KeyBinding is a key sequence bound to an action in a KeyMap.
+type KeyBinding struct {
+	Keys   []IKey
+	Help   string // A description of the action, for listing bindings to the user
+	Action KeyAction
+}
+
+func (b KeyBinding) String() string {
+	return KeySequenceString(b.Keys)
+}
+
+// KeyMap binds actions to keys, and to chords - sequences of keys, like "g g" or "Ctrl-x Ctrl-c". Once
+// set with App.SetKeyMap, the app consults it for each keypress before the keypress is passed to its
+// widgets. A key that completes a binding runs the action; a key that begins or continues a chord is
+// held until the chord is complete. A key that doesn't continue the chord in progress cancels it, and is
+// discarded. Keys that are bound, or begin a chord, are never seen by widgets - so bind plain characters
+// with care in apps where the user types text. Keys can be bound by the character they type or by their
+// position on the keyboard - see SetLayout.
+type KeyMap struct {
+	Timeout  time.Duration // If > 0, a chord in progress is abandoned if no key is pressed for this long
+	bindings []KeyBinding
+	pending  []IKey
+	last     time.Time
+	layout   *KeyboardLayout // The user's keyboard layout; nil for US QWERTY
+	match    KeyMatch
+}
+
+// NewKeyMap returns an empty KeyMap.
+func NewKeyMap() *KeyMap {
+	return &KeyMap{}
+}
+
+// keysMatch is like KeysEqual, but modifiers must match too - so "Shift+Left" is not bound to Left. The
+// modifiers of control characters, like Ctrl-X and Esc, are ignored, since terminals differ in reporting
+// them.
+func keysMatch(k1, k2 IKey) bool {
+	if !KeysEqual(k1, k2) {
+		return false
+	}
+	if k1.Key() < tcell.KeyRune || k1.Key() == tcell.KeyDEL {
+		return true
+	}
+	return k1.Modifiers() == k2.Modifiers()
+}
+
+func keySequenceHasPrefix(keys []IKey, prefix []IKey) bool {
+	if len(prefix) > len(keys) {
+		return false
+	}
+	for i, k := range prefix {
+		if !keysMatch(keys[i], k) {
+			return false
+		}
+	}
+	return true
+}
+
+// Bind binds the key sequence seq, e.g. "Ctrl-x Ctrl-c", to action - see ParseKeySequence. help describes
+// the action when the bindings are listed.
+func (m *KeyMap) Bind(seq string, help string, action KeyAction) error {
+	keys, err := ParseKeySequence(seq)
+	if err != nil {
+		return err
+	}
+	return m.BindKeys(keys, help, action)
+}
+
+// BindKeys binds the key sequence keys to action. If the sequence is already bound, the binding is
+// replaced; if it would be ambiguous with a bound sequence, a KeyBindingConflictError is returned.
+func (m *KeyMap) BindKeys(keys []IKey, help string, action KeyAction) error {
+	if len(keys) == 0 {
+		return errors.WithStack(InvalidKeyError{})
+	}
+	binding := KeyBinding{
+		Keys:   append([]IKey(nil), keys...),
+		Help:   help,
+		Action: action,
+	}
+	for i, b := range m.bindings {
+		if len(b.Keys) == len(keys) && keySequenceHasPrefix(b.Keys, keys) {
+			m.bindings[i] = binding
+			return nil
+		}
+		if keySequenceHasPrefix(b.Keys, keys) || keySequenceHasPrefix(keys, b.Keys) {
+			return errors.WithStack(KeyBindingConflictError{Keys: KeySequenceString(keys), Existing: b.String()})
+		}
+	}
+	m.bindings = append(m.bindings, binding)
+	return nil
+}
+
+// Unbind removes the binding for the key sequence seq. It returns false if seq was not bound.
+func (m *KeyMap) Unbind(seq string) bool {
+	keys, err := ParseKeySequence(seq)
+	if err != nil {
+		return false
+	}
+	for i, b := range m.bindings {
+		if len(b.Keys) == len(keys) && keySequenceHasPrefix(b.Keys, keys) {
+			m.bindings = append(m.bindings[:i], m.bindings[i+1:]...)
+			m.pending = nil
+			return true
+		}
+	}
+	return false
+}
+
+// Bindings returns the current bindings, in the order they were made.
+func (m *KeyMap) Bindings() []KeyBinding {
+	res := make([]KeyBinding, len(m.bindings))
+	copy(res, m.bindings)
+	return res
+}
+
+// Pending returns the keys of the chord in progress, if any - e.g. to show them in a status bar.
+func (m *KeyMap) Pending() []IKey {
+	return append([]IKey(nil), m.pending...)
+}
+
+// HandleKey processes a keypress, running the action it completes, if any. It returns true if the key
+// was consumed - because it is bound, is part of a chord, or cancelled one.
+func (m *KeyMap) HandleKey(k IKey, app IApp) bool {
+	now := ClockFor(app).Now()
+	if len(m.pending) > 0 && m.Timeout > 0 && now.Sub(m.last) > m.Timeout {
+		m.pending = nil
+	}
+	seq := append(m.Pending(), m.boundKey(k))
+	chord := false
+	for _, b := range m.bindings {
+		if !keySequenceHasPrefix(b.Keys, seq) {
+			continue
+		}
+		if len(b.Keys) == len(seq) {
+			m.pending = nil
+			if b.Action != nil {
+				b.Action(app)
+			}
+			return true
+		}
+		chord = true
+	}
+	if chord {
+		m.pending = seq
+		m.last = now
+		return true
+	}
+	if len(m.pending) > 0 {
+		m.pending = nil
+		return true
+	}
+	return false
+}
+
+//======================================================================
+
+// KeyMap returns the app's key map, or nil if none has been set.
+func (a *App) KeyMap() *KeyMap {
+	return a.keyMap
+}
+
+// SetKeyMap sets the key map the app consults for each keypress before passing it to its widgets. A nil
+// key map turns this off.
+func (a *App) SetKeyMap(m *KeyMap) {
+	a.keyMap = m
+}
+
+// keyMapInput returns true if ev was consumed by the app's key map.
+func (a *App) keyMapInput(ev interface{}) bool {
+	if a.keyMap == nil {
+		return false
+	}
+	if kev, ok := ev.(*tcell.EventKey); ok {
+		return a.keyMap.HandleKey(kev, a)
+	}
+	return false
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,85 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestParseKey1(t *testing.T) {
+	for name, expected := range map[string]Key{
+		"g":          MakeKey('g'),
+		"G":          MakeKey('G'),
+		"?":          MakeKey('?'),
+		"Space":      MakeKey(' '),
+		"enter":      MakeKeyExt(tcell.KeyEnter),
+		"Esc":        MakeKeyExt(tcell.KeyEsc),
+		"F5":         MakeKeyExt(tcell.KeyF5),
+		"Ctrl-X":     MakeKeyExt(tcell.KeyCtrlX),
+		"ctrl+x":     MakeKeyExt(tcell.KeyCtrlX),
+		"Ctrl-h":     MakeKeyExt(tcell.KeyCtrlH),
+		"Alt-x":      MakeKeyExt2(tcell.ModAlt, tcell.KeyRune, 'x'),
+		"Shift+Left": MakeKeyExt2(tcell.ModShift, tcell.KeyLeft, 0),
+		"Ctrl-Up":    MakeKeyExt2(tcell.ModCtrl, tcell.KeyUp, 0),
+	} {
+		k, err := ParseKey(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, expected, k, name)
+	}
+
+	for _, name := range []string{"", "gg", "Ctrl-", "Hyper-x", "Alt-nope"} {
+		_, err := ParseKey(name)
+		assert.IsType(t, InvalidKeyError{}, errors.Cause(err), name)
+	}
+}
+
+func TestParseKeySequence1(t *testing.T) {
+	keys, err := ParseKeySequence("Ctrl-x  Ctrl-c")
+	assert.NoError(t, err)
+	assert.Equal(t, []IKey{MakeKeyExt(tcell.KeyCtrlX), MakeKeyExt(tcell.KeyCtrlC)}, keys)
+	assert.Equal(t, "Ctrl-X Ctrl-C", KeySequenceString(keys))
+
+	keys, err = ParseKeySequence("g Space Alt-x")
+	assert.NoError(t, err)
+	assert.Equal(t, "g Space Alt+x", KeySequenceString(keys))
+
+	_, err = ParseKeySequence("  ")
+	assert.Error(t, err)
+}
+
+func TestKeyMapBind1(t *testing.T) {
+	m := NewKeyMap()
+	assert.NoError(t, m.Bind("g g", "top", nil))
+	assert.NoError(t, m.Bind("G", "bottom", nil))
+
+	err := m.Bind("g", "go", nil)
+	assert.Equal(t, KeyBindingConflictError{Keys: "g", Existing: "g g"}, errors.Cause(err))
+	err = m.Bind("G x", "nope", nil)
+	assert.Equal(t, KeyBindingConflictError{Keys: "G x", Existing: "G"}, errors.Cause(err))
+
+	// Rebinding replaces
+	assert.NoError(t, m.Bind("g  g", "first line", nil))
+	bs := m.Bindings()
+	assert.Equal(t, 2, len(bs))
+	assert.Equal(t, "g g", bs[0].String())
+	assert.Equal(t, "first line", bs[0].Help)
+	assert.Equal(t, "G", bs[1].String())
+
+	assert.True(t, m.Unbind("g g"))
+	assert.False(t, m.Unbind("g g"))
+	assert.False(t, m.Unbind("!!"))
+	assert.Equal(t, 1, len(m.Bindings()))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
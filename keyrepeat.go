@@ -0,0 +1,98 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// DefaultRepeatKeys are the keys whose repeats are shaped, unless KeyRepeatOptions says otherwise - the
+// keys that are held down to scroll.
+var DefaultRepeatKeys = []IKey{
+	MakeKeyExt(tcell.KeyUp),
+	MakeKeyExt(tcell.KeyDown),
+	MakeKeyExt(tcell.KeyLeft),
+	MakeKeyExt(tcell.KeyRight),
+	MakeKeyExt(tcell.KeyPgUp),
+	MakeKeyExt(tcell.KeyPgDn),
+}
+
+// KeyRepeatOptions is used to configure the shaping of auto-repeated keys - see App.SetKeyRepeat.
+type KeyRepeatOptions struct {
+	Keys []IKey // The keys whose repeats are shaped; if nil, DefaultRepeatKeys
+	// MaxRepeats, if greater than 0, is the most presses of a key acted on in one batch, counting the
+	// first - the rest are dropped, so that when the user lets go of the key, the app stops soon after,
+	// however far behind it was.
+	MaxRepeats int
+}
+
+type keyRepeater struct {
+	opts KeyRepeatOptions
+}
+
+// SetKeyRepeat turns on the shaping of auto-repeated keys, for when a key held down arrives faster than
+// the app can redraw - e.g. when scrolling a huge list. Once a shaped key has been handled, any repeats of
+// it already queued behind it are handled straight away, as one batch, and the app is redrawn once,
+// rather than after each. A widget moving its focus a row per key sees the same keys, but the user sees it
+// move several rows per frame, smoothly, and it stops when the key is released rather than working
+// through a backlog of thousands of keys. Repeats beyond KeyRepeatOptions.MaxRepeats in one batch are
+// dropped. Nil options turn shaping off.
+func (a *App) SetKeyRepeat(opts *KeyRepeatOptions) {
+	if opts == nil {
+		a.keyRepeat = nil
+		return
+	}
+	opt := *opts
+	if opt.Keys == nil {
+		opt.Keys = DefaultRepeatKeys
+	}
+	a.keyRepeat = &keyRepeater{opts: opt}
+}
+
+func (r *keyRepeater) shaped(ev *tcell.EventKey) bool {
+	for _, k := range r.opts.Keys {
+		if keysMatch(ev, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameKey(k1, k2 *tcell.EventKey) bool {
+	return k1.Key() == k2.Key() && k1.Rune() == k2.Rune() && k1.Modifiers() == k2.Modifiers()
+}
+
+// handleKeyRepeats handles the repeats of ev queued behind it, if it is a shaped key. It stops at the
+// first event that isn't a repeat, which is handled as usual.
+func (a *App) handleKeyRepeats(ev interface{}, unhandled IUnhandledInput) {
+	kev, ok := ev.(*tcell.EventKey)
+	if !ok || a.keyRepeat == nil || !a.keyRepeat.shaped(kev) {
+		return
+	}
+	repeats := 1
+	for {
+		select {
+		case next := <-a.TCellEvents:
+			if nkev, ok := next.(*tcell.EventKey); ok && sameKey(kev, nkev) {
+				repeats++
+				if a.keyRepeat.opts.MaxRepeats <= 0 || repeats <= a.keyRepeat.opts.MaxRepeats {
+					a.dispatchTCellEvent(nkev, unhandled)
+				}
+				continue
+			}
+			a.HandleTCellEvent(next, unhandled)
+		default:
+		}
+		return
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
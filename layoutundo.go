@@ -0,0 +1,245 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// DefaultLayoutUndoDepth is the number of layout changes remembered, unless
+// LayoutUndoOptions says otherwise.
+var DefaultLayoutUndoDepth = 50
+
+// LayoutUndoOptions is used to configure layout undo - see EnableLayoutUndo.
+type LayoutUndoOptions struct {
+	Key   IKey // If not nil, pressing this key undoes the last layout change
+	Depth int  // The most changes remembered; defaults to DefaultLayoutUndoDepth
+}
+
+// layoutState is the layout of one container at the time it was recorded.
+type layoutState struct {
+	container IWidget
+	widgets   []IWidget          // If the container is an ICompositeMultiple and ISettableSubWidgets
+	dims      []IWidgetDimension // If the container is an ICompositeMultipleDimensions and ISettableDimensions
+	focus     int                // If the container is an IFocus
+	sub       IWidget            // If the container is an ISettableComposite
+}
+
+func recordLayoutState(w IWidget) layoutState {
+	res := layoutState{container: w, focus: -1}
+	if cm, ok := w.(ICompositeMultiple); ok {
+		if _, ok := w.(ISettableSubWidgets); ok {
+			res.widgets = cm.SubWidgets()
+		}
+	}
+	if cd, ok := w.(ICompositeMultipleDimensions); ok {
+		if _, ok := w.(ISettableDimensions); ok {
+			dims := cd.Dimensions()
+			res.dims = make([]IWidgetDimension, len(dims))
+			copy(res.dims, dims)
+		}
+	}
+	if f, ok := w.(IFocus); ok {
+		res.focus = f.Focus()
+	}
+	if c, ok := w.(ISettableComposite); ok {
+		res.sub = c.SubWidget()
+	}
+	return res
+}
+
+func (s layoutState) restore(app IApp) {
+	if s.widgets != nil {
+		s.container.(ISettableSubWidgets).SetSubWidgets(s.widgets, app)
+	}
+	// Set the dimensions after the children, since a resize may have changed the dimension of a child
+	// in place
+	if s.dims != nil {
+		s.container.(ISettableDimensions).SetDimensions(s.dims, app)
+	}
+	if s.sub != nil {
+		s.container.(ISettableComposite).SetSubWidget(s.sub, app)
+	}
+	if s.focus != -1 {
+		s.container.(IFocus).SetFocus(app, s.focus)
+	}
+}
+
+type layoutUndo struct {
+	opts   LayoutUndoOptions
+	steps  [][]layoutState // Oldest first
+	open   bool            // True if the last step is still being made - it's closed when the app redraws
+	paused int             // While positive, nothing is recorded
+}
+
+//======================================================================
+
+// ILayoutRecorder is implemented by an IApp that can undo layout changes, like App.
+type ILayoutRecorder interface {
+	RecordLayout(containers ...IWidget)
+	LayoutChanging(container IWidget)
+	WithoutLayoutUndo(f func(app IApp))
+}
+
+// RecordLayout records the layout of containers before they are changed, if the app supports layout
+// undo - see App.RecordLayout. Widgets that change their own layout, e.g. to close or resize a pane,
+// should call it first.
+func RecordLayout(app IApp, containers ...IWidget) {
+	if r, ok := app.(ILayoutRecorder); ok {
+		r.RecordLayout(containers...)
+	}
+}
+
+// LayoutChanging is called by containers, like pile, columns and holder, just before their children or
+// dimensions are set, so that the change can be undone - see App.LayoutChanging.
+func LayoutChanging(app IApp, container IWidget) {
+	if r, ok := app.(ILayoutRecorder); ok {
+		r.LayoutChanging(container)
+	}
+}
+
+// WithoutLayoutUndo calls f, and doesn't record any layout changes it makes. Use it for changes that
+// couldn't be undone on their own, e.g. reordering rows that mirror some other state.
+func WithoutLayoutUndo(app IApp, f func(app IApp)) {
+	if r, ok := app.(ILayoutRecorder); ok {
+		r.WithoutLayoutUndo(f)
+	} else {
+		f(app)
+	}
+}
+
+// EnableLayoutUndo starts recording layout changes so they can be undone, e.g. if the user closes a
+// pane by mistake. Setting the children or dimensions of a pile or columns, or the widget in a holder, is
+// recorded as it happens - changes made before the app next redraws are one step. Changes to other
+// containers are recorded with RecordLayout, before the containers affected by them are changed.
+// UndoLayout, or pressing the configured key, restores the exact layout and focus of the containers as
+// they were.
+func (a *App) EnableLayoutUndo(opts ...LayoutUndoOptions) {
+	var opt LayoutUndoOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Depth <= 0 {
+		opt.Depth = DefaultLayoutUndoDepth
+	}
+	a.layoutUndo = &layoutUndo{opts: opt}
+}
+
+// DisableLayoutUndo stops recording layout changes, and forgets those recorded.
+func (a *App) DisableLayoutUndo() {
+	a.layoutUndo = nil
+}
+
+// RecordLayout saves the layout of containers - their children, the children's dimensions, and which
+// child has focus - as one step that UndoLayout can restore. Call it before making a change, passing
+// every container the change affects, e.g. both a pile that loses a pane and the holder that displays
+// the pile. Containers can be any widget that is an ISettableSubWidgets, ISettableDimensions, IFocus or
+// ISettableComposite, like pile, columns and holder. Containers changed after this, before the app
+// redraws, are added to the same step. RecordLayout does nothing if layout undo has not been enabled.
+func (a *App) RecordLayout(containers ...IWidget) {
+	u := a.layoutUndo
+	if u == nil || u.paused > 0 || len(containers) == 0 {
+		return
+	}
+	step := make([]layoutState, 0, len(containers))
+	for _, c := range containers {
+		step = append(step, recordLayoutState(c))
+	}
+	u.steps = append(u.steps, step)
+	if len(u.steps) > u.opts.Depth {
+		u.steps = u.steps[len(u.steps)-u.opts.Depth:]
+	}
+	u.open = true
+}
+
+// LayoutChanging records the layout of container, which is about to change. If a step is still being
+// made - something was recorded since the app last redrew - container is added to it, unless it's
+// already there; otherwise a new step is started. It does nothing if layout undo has not been enabled.
+func (a *App) LayoutChanging(container IWidget) {
+	u := a.layoutUndo
+	if u == nil || u.paused > 0 {
+		return
+	}
+	if !u.open || len(u.steps) == 0 {
+		a.RecordLayout(container)
+		return
+	}
+	last := len(u.steps) - 1
+	for _, s := range u.steps[last] {
+		if s.container == container {
+			return
+		}
+	}
+	u.steps[last] = append(u.steps[last], recordLayoutState(container))
+}
+
+// WithoutLayoutUndo calls f, and doesn't record any layout changes it makes.
+func (a *App) WithoutLayoutUndo(f func(app IApp)) {
+	if a.layoutUndo != nil {
+		a.layoutUndo.paused++
+		defer func() { a.layoutUndo.paused-- }()
+	}
+	f(a)
+}
+
+// closeLayoutStep ends the step being made, so that the next change starts a new one.
+func (a *App) closeLayoutStep() {
+	if a.layoutUndo != nil {
+		a.layoutUndo.open = false
+	}
+}
+
+// ChangeLayout records the layout of containers, then calls f to change them.
+func (a *App) ChangeLayout(f func(app IApp), containers ...IWidget) {
+	a.RecordLayout(containers...)
+	f(a)
+}
+
+// UndoLayout restores the containers recorded by the most recent call to RecordLayout to the layout
+// they had then. It returns false if there is nothing to undo.
+func (a *App) UndoLayout() bool {
+	u := a.layoutUndo
+	if u == nil || len(u.steps) == 0 {
+		return false
+	}
+	step := u.steps[len(u.steps)-1]
+	u.steps = u.steps[:len(u.steps)-1]
+	u.open = false
+	a.WithoutLayoutUndo(func(app IApp) {
+		for i := len(step) - 1; i >= 0; i-- {
+			step[i].restore(app)
+		}
+	})
+	a.Redraw()
+	return true
+}
+
+// LayoutUndoAvailable returns the number of layout changes that can be undone.
+func (a *App) LayoutUndoAvailable() int {
+	if a.layoutUndo == nil {
+		return 0
+	}
+	return len(a.layoutUndo.steps)
+}
+
+// layoutUndoInput returns true if ev was the keypress configured to undo a layout change.
+func (a *App) layoutUndoInput(ev interface{}) bool {
+	if a.layoutUndo == nil || a.layoutUndo.opts.Key == nil {
+		return false
+	}
+	if kev, ok := ev.(*tcell.EventKey); ok && KeysEqual(kev, a.layoutUndo.opts.Key) {
+		a.UndoLayout()
+		return true
+	}
+	return false
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
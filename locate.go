@@ -0,0 +1,206 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+)
+
+//======================================================================
+
+// Rect is a rectangle of cells, with its top-left corner at X, Y.
+type Rect struct {
+	X, Y, Cols, Rows int
+}
+
+func (r Rect) String() string {
+	return fmt.Sprintf("%dx%d@(%d,%d)", r.Cols, r.Rows, r.X, r.Y)
+}
+
+// Empty returns true if the rectangle contains no cells.
+func (r Rect) Empty() bool {
+	return r.Cols <= 0 || r.Rows <= 0
+}
+
+// Contains returns true if the cell at x, y is inside the rectangle.
+func (r Rect) Contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.Cols && y >= r.Y && y < r.Y+r.Rows
+}
+
+// IWidgetLocating is implemented by an app that can find where widgets are drawn - see LocateWidget.
+type IWidgetLocating interface {
+	widgetLocator() *widgetLocator
+	setWidgetLocator(l *widgetLocator)
+}
+
+// widgetLocator marks the cells drawn by the first widget rendered that matches - or, if targets isn't
+// nil, the cells drawn by each of targets, with marks of their own.
+type widgetLocator struct {
+	match   func(IWidget) bool
+	focused bool       // If true, match the first widget to finish rendering in focus, instead of using match
+	mark    *RawRegion // Set on the matching widget's cells - nothing is drawn with it
+	marked  bool
+	size    IRenderSize // The size the matching widget was rendered with
+	focus   Selector    // The focus the matching widget was rendered with
+	targets []IWidget
+	marks   []*RawRegion // Set on the cells of the target at the same index
+}
+
+func (a *App) widgetLocator() *widgetLocator {
+	return a.locating
+}
+
+func (a *App) setWidgetLocator(l *widgetLocator) {
+	a.locating = l
+}
+
+// LocateWidget renders w as Render would, and returns the rectangle covered by the first widget beneath it,
+// in rendering order, for which match returns true - for example, the widget to point at with a tooltip
+// or a guided tour. A ContainerWidget is matched by the widget it holds. False is returned if no such
+// widget is drawn, or if the app can't locate widgets. The canvas rendered is discarded, so this is
+// only as cheap as rendering w.
+func LocateWidget(w IWidget, size IRenderSize, focus Selector, app IApp, match func(IWidget) bool) (Rect, bool) {
+	res, _, ok := locateWidget(w, size, focus, app, match)
+	return res, ok
+}
+
+// locateWidget is like LocateWidget, but also returns the locator, which holds the size and focus the
+// widget was rendered with.
+func locateWidget(w IWidget, size IRenderSize, focus Selector, app IApp, match func(IWidget) bool) (Rect, *widgetLocator, bool) {
+	l := &widgetLocator{match: match, mark: &RawRegion{}}
+	res, ok := locateWith(w, size, focus, app, l)
+	return res, l, ok
+}
+
+// locateFocused renders w in focus, as LocateWidget does, and returns the rectangle covered by the
+// deepest widget in the focus path - the first to finish rendering in focus, since a widget's children
+// finish before it does.
+func locateFocused(w IWidget, size IRenderSize, app IApp) (Rect, bool) {
+	return locateWith(w, size, Focused, app, &widgetLocator{focused: true, mark: &RawRegion{}})
+}
+
+// locateWith renders w with l marking the cells of the widget it matches, and returns the rectangle
+// they cover.
+func locateWith(w IWidget, size IRenderSize, focus Selector, app IApp, l *widgetLocator) (Rect, bool) {
+	la, ok := app.(IWidgetLocating)
+	if !ok {
+		return Rect{}, false
+	}
+	saved := la.widgetLocator()
+	la.setWidgetLocator(l)
+	defer la.setWidgetLocator(saved)
+
+	canvas := Render(w, size, focus, app)
+	if !l.marked {
+		return Rect{}, false
+	}
+	x0, y0, x1, y1 := -1, -1, -1, -1
+	for y := 0; y < canvas.BoxRows(); y++ {
+		for x := 0; x < canvas.BoxColumns(); x++ {
+			if canvas.CellAt(x, y).RawRegion() != l.mark {
+				continue
+			}
+			if x0 == -1 || x < x0 {
+				x0 = x
+			}
+			if y0 == -1 {
+				y0 = y
+			}
+			if x > x1 {
+				x1 = x
+			}
+			y1 = y
+		}
+	}
+	if x0 == -1 {
+		// Drawn, but scrolled or clipped out of sight
+		return Rect{}, false
+	}
+	return Rect{X: x0, Y: y0, Cols: x1 - x0 + 1, Rows: y1 - y0 + 1}, true
+}
+
+// locateAt renders w as LocateWidget does, and returns the index of the widget in targets that drew the
+// cell at x, y, or -1 if none did. No target should contain another.
+func locateAt(w IWidget, size IRenderSize, focus Selector, app IApp, targets []IWidget, x, y int) int {
+	la, ok := app.(IWidgetLocating)
+	if !ok {
+		return -1
+	}
+	l := &widgetLocator{targets: targets, marks: make([]*RawRegion, len(targets))}
+	for i := range l.marks {
+		l.marks[i] = &RawRegion{}
+	}
+	saved := la.widgetLocator()
+	la.setWidgetLocator(l)
+	defer la.setWidgetLocator(saved)
+
+	canvas := Render(w, size, focus, app)
+	if x < 0 || y < 0 || x >= canvas.BoxColumns() || y >= canvas.BoxRows() {
+		return -1
+	}
+	mark := canvas.CellAt(x, y).RawRegion()
+	for i, m := range l.marks {
+		if m == mark {
+			return i
+		}
+	}
+	return -1
+}
+
+// locateIn marks the cells of canvas, just rendered by w, if w is the widget being located.
+func locateIn(w IWidget, size IRenderSize, focus Selector, canvas ICanvas, app IApp) {
+	la, ok := app.(IWidgetLocating)
+	if !ok {
+		return
+	}
+	l := la.widgetLocator()
+	if l != nil && l.targets != nil {
+		uw := unwrapContainer(w)
+		for i, t := range l.targets {
+			if uw == unwrapContainer(t) {
+				mark := l.marks[i]
+				RangeOverCanvas(canvas, CellRangeFunc(func(c Cell) Cell {
+					return c.WithRawRegion(mark)
+				}))
+				break
+			}
+		}
+		return
+	}
+	if l == nil || l.marked {
+		return
+	}
+	if l.focused {
+		if !focus.Focus {
+			return
+		}
+	} else if !l.match(unwrapContainer(w)) {
+		return
+	}
+	l.marked = true
+	l.size = size
+	l.focus = focus
+	RangeOverCanvas(canvas, CellRangeFunc(func(c Cell) Cell {
+		return c.WithRawRegion(l.mark)
+	}))
+}
+
+// unwrapContainer returns the widget held by w, if w is a ContainerWidget, which only adds layout
+// information for its parent.
+func unwrapContainer(w IWidget) IWidget {
+	for {
+		cw, ok := w.(*ContainerWidget)
+		if !ok {
+			return w
+		}
+		w = cw.IWidget
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,56 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+type menuTestRoot struct {
+	IWidget
+}
+
+type menuTestMenu struct {
+	IWidget
+}
+
+func (w *menuTestMenu) SubWidget() IWidget {
+	return w.IWidget
+}
+
+func (w *menuTestMenu) SetSubWidget(wi IWidget, app IApp) {
+	w.IWidget = wi
+}
+
+func TestUnregisterMenu1(t *testing.T) {
+	root := &menuTestRoot{}
+	a := &App{view: root, viewPlusMenus: root}
+	m1, m2 := &menuTestMenu{}, &menuTestMenu{}
+
+	a.RegisterMenu(m1)
+	a.RegisterMenu(m2)
+	assert.Equal(t, IWidget(m2), a.viewPlusMenus)
+	assert.Equal(t, IWidget(m1), m2.SubWidget())
+	assert.Equal(t, IWidget(root), m1.SubWidget())
+
+	// The first menu is under the second
+	assert.True(t, a.UnregisterMenu(m1))
+	assert.Equal(t, IWidget(m2), a.viewPlusMenus)
+	assert.Equal(t, IWidget(root), m2.SubWidget())
+	assert.False(t, a.UnregisterMenu(m1))
+
+	assert.True(t, a.UnregisterMenu(m2))
+	assert.Equal(t, IWidget(root), a.viewPlusMenus)
+	assert.False(t, a.UnregisterMenu(m2))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
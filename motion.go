@@ -0,0 +1,42 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+//======================================================================
+
+// IReducedMotion is implemented by an app that can be told to keep animation to a minimum, like App.
+type IReducedMotion interface {
+	ReduceMotion() bool
+}
+
+var _ IReducedMotion = (*App)(nil)
+
+// ReduceMotion returns true if animation in app should be kept to a minimum. Widgets that animate check
+// it as they start; it is false if app is nil.
+func ReduceMotion(app IApp) bool {
+	if a, ok := app.(IReducedMotion); ok {
+		return a.ReduceMotion()
+	}
+	return false
+}
+
+// SetReduceMotion asks for animation to be kept to a minimum in this app, for a user who finds movement
+// on screen distracting or uncomfortable. Tweens, and the animations built on them, jump straight to
+// their end values; effects like those of the effects package are still shown, but without movement.
+// AppArgs.ReduceMotion sets it when the app is made.
+func (a *App) SetReduceMotion(on bool) {
+	a.reduceMotion = on
+}
+
+// ReduceMotion returns true if animation should be kept to a minimum in this app - see SetReduceMotion.
+func (a *App) ReduceMotion() bool {
+	return a.reduceMotion
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,264 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+)
+
+//======================================================================
+
+// PaletteConfigError is returned when a palette can't be built from a config file. Entry is the name
+// of the palette entry at fault, if there is one.
+type PaletteConfigError struct {
+	Entry  string
+	Reason string
+}
+
+var _ error = PaletteConfigError{}
+
+func (e PaletteConfigError) Error() string {
+	if e.Entry == "" {
+		return fmt.Sprintf("Invalid palette config: %s", e.Reason)
+	}
+	return fmt.Sprintf("Invalid palette entry %q: %s", e.Entry, e.Reason)
+}
+
+// PaletteDecoder turns the contents of a config file into Go values, like json.Unmarshal. The
+// Unmarshal functions of the common TOML and YAML packages have this signature.
+type PaletteDecoder func(data []byte, v interface{}) error
+
+// paletteColorModes lists, for each color mode, the color variant keys of a palette entry to use, in
+// order of preference.
+var paletteColorModes = map[ColorMode][]string{
+	Mode24BitColors: {"truecolor", "256", "16"},
+	Mode256Colors:   {"256", "truecolor", "16"},
+	Mode88Colors:    {"256", "truecolor", "16"},
+	Mode16Colors:    {"16", "256", "truecolor"},
+	Mode8Colors:     {"16", "256", "truecolor"},
+	ModeMonochrome:  {"16", "256", "truecolor"},
+}
+
+var paletteAttrs = map[string]tcell.AttrMask{
+	"bold":      tcell.AttrBold,
+	"blink":     tcell.AttrBlink,
+	"dim":       tcell.AttrDim,
+	"reverse":   tcell.AttrReverse,
+	"underline": tcell.AttrUnderline,
+}
+
+// stringMap returns v as a map with string keys, if it is one. YAML decoders produce maps with keys of
+// type interface{}.
+func stringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			res[fmt.Sprintf("%v", k)] = v
+		}
+		return res, true
+	}
+	return nil, false
+}
+
+func parsePaletteColor(name string, v interface{}) (IColor, error) {
+	switch c := v.(type) {
+	case nil:
+		return NoColor{}, nil
+	case string:
+		col, err := MakeColorSafe(c)
+		if err != nil {
+			return nil, PaletteConfigError{Entry: name, Reason: fmt.Sprintf("unknown color %q", c)}
+		}
+		return col, nil
+	}
+	variants, ok := stringMap(v)
+	if !ok {
+		return nil, PaletteConfigError{Entry: name, Reason: fmt.Sprintf("a color must be a string or a table of variants, not %v", v)}
+	}
+	cols := make(map[string]IColor)
+	for k, cv := range variants {
+		k = strings.ToLower(k)
+		if k == "24bit" {
+			k = "truecolor"
+		}
+		if k != "16" && k != "256" && k != "truecolor" {
+			return nil, PaletteConfigError{Entry: name, Reason: fmt.Sprintf("unknown color variant %q - use 16, 256 or truecolor", k)}
+		}
+		s, ok := cv.(string)
+		if !ok {
+			return nil, PaletteConfigError{Entry: name, Reason: fmt.Sprintf("color variant %s must be a string", k)}
+		}
+		col, err := parsePaletteColor(name, s)
+		if err != nil {
+			return nil, err
+		}
+		cols[k] = col
+	}
+	if len(cols) == 0 {
+		return NoColor{}, nil
+	}
+	byMode := make(map[ColorMode]IColor)
+	for mode, prefs := range paletteColorModes {
+		for _, k := range prefs {
+			if col, ok := cols[k]; ok {
+				byMode[mode] = col
+				break
+			}
+		}
+	}
+	return MakeColorByMode(byMode), nil
+}
+
+func parsePaletteAttrs(name string, v interface{}) (StyleAttrs, error) {
+	var attrs []string
+	switch a := v.(type) {
+	case nil:
+	case string:
+		attrs = strings.FieldsFunc(a, func(r rune) bool { return r == ',' || r == ' ' })
+	case []interface{}:
+		for _, av := range a {
+			s, ok := av.(string)
+			if !ok {
+				return StyleNone, PaletteConfigError{Entry: name, Reason: fmt.Sprintf("attribute %v must be a string", av)}
+			}
+			attrs = append(attrs, s)
+		}
+	case []string:
+		attrs = a
+	default:
+		return StyleNone, PaletteConfigError{Entry: name, Reason: fmt.Sprintf("attributes must be a list, not %v", v)}
+	}
+	res := StyleNone
+	for _, attr := range attrs {
+		attr = strings.ToLower(strings.TrimSpace(attr))
+		off := strings.HasPrefix(attr, "!")
+		mask, ok := paletteAttrs[strings.TrimPrefix(attr, "!")]
+		if !ok {
+			return StyleNone, PaletteConfigError{Entry: name, Reason: fmt.Sprintf("unknown attribute %q", attr)}
+		}
+		if off {
+			res = res.MergeUnder(StyleAttrs{OnOff: 0, Set: mask})
+		} else {
+			res = res.MergeUnder(StyleAttrs{OnOff: mask, Set: mask})
+		}
+	}
+	return res, nil
+}
+
+// ParsePalette builds a Palette from a description decoded from a config file. Each key names a palette
+// entry, and its value is a table with any of
+//
+//   - "fg" (or "foreground") and "bg" (or "background") - a color, in any form MakeColorSafe accepts,
+//     like "yellow", "dark blue", "#ffaf00" or "g50"; or a table of variants for terminals of different
+//     color depths, keyed by "16", "256" and "truecolor". A terminal uses the variant for its depth,
+//     if there is one, and otherwise the nearest there is.
+//   - "attrs" (or "style") - a list of "bold", "blink", "dim", "reverse" and "underline", or a string
+//     of them separated by commas. A name prefixed with "!" turns the attribute off.
+//
+// e.g. in JSON:
+//
+//   {
+//     "banner": {"fg": "white", "bg": "#003", "attrs": ["bold"]},
+//     "title": {"fg": {"16": "yellow", "256": "#fa0", "truecolor": "#ffaf00"}}
+//   }
+//
+// A PaletteConfigError is returned if the description is invalid.
+func ParsePalette(config interface{}) (Palette, error) {
+	entries, ok := stringMap(config)
+	if !ok {
+		return nil, errors.WithStack(PaletteConfigError{Reason: "expected a table of palette entries"})
+	}
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	// So the same error is reported for the same invalid config each time
+	sort.Strings(names)
+
+	res := make(Palette, len(entries))
+	for _, name := range names {
+		fields, ok := stringMap(entries[name])
+		if !ok {
+			return nil, errors.WithStack(PaletteConfigError{Entry: name, Reason: "expected a table with fg, bg and attrs"})
+		}
+		var fgv, bgv, attrv interface{}
+		for k, v := range fields {
+			switch strings.ToLower(k) {
+			case "fg", "foreground":
+				fgv = v
+			case "bg", "background":
+				bgv = v
+			case "attrs", "style":
+				attrv = v
+			default:
+				return nil, errors.WithStack(PaletteConfigError{Entry: name, Reason: fmt.Sprintf("unknown field %q", k)})
+			}
+		}
+		fg, err := parsePaletteColor(name, fgv)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		bg, err := parsePaletteColor(name, bgv)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		style, err := parsePaletteAttrs(name, attrv)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		res[name] = MakeStyledPaletteEntry(fg, bg, style)
+	}
+	return res, nil
+}
+
+// LoadPalette decodes data with decode, then builds a Palette from it with ParsePalette. If decode is
+// nil, data is decoded as JSON.
+func LoadPalette(data []byte, decode PaletteDecoder) (Palette, error) {
+	if decode == nil {
+		decode = json.Unmarshal
+	}
+	var config interface{}
+	if err := decode(data, &config); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ParsePalette(config)
+}
+
+// LoadPaletteFile reads a Palette from the config file at path - see ParsePalette. Files named
+// *.json are decoded as JSON. gowid doesn't depend on a TOML or YAML package, so for other formats,
+// pass the decoder from the package your app uses, e.g.
+//
+//   palette, err := gowid.LoadPaletteFile("colors.toml", toml.Unmarshal)
+//
+func LoadPaletteFile(path string, decode ...PaletteDecoder) (Palette, error) {
+	var dec PaletteDecoder
+	if len(decode) > 0 {
+		dec = decode[0]
+	} else if strings.ToLower(filepath.Ext(path)) != ".json" {
+		return nil, errors.WithStack(PaletteConfigError{Reason: fmt.Sprintf("no decoder provided for %s", path)})
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return LoadPalette(data, dec)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,104 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestPaletteConfig1(t *testing.T) {
+	p, err := LoadPalette([]byte(`{
+		"banner": {"fg": "white", "bg": "#003300", "attrs": ["bold", "!underline"]},
+		"title": {"foreground": {"16": "yellow", "256": "#ff8700", "truecolor": "#ffaf00"}, "style": "reverse, dim"},
+		"plain": {}
+	}`), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(p))
+
+	ctx := stateTestContext{p}
+	banner, ok := p.CellStyler("banner")
+	assert.True(t, ok)
+	x, y, z := banner.GetStyle(ctx)
+	assert.Equal(t, MakeTCellColorExt(tcell.ColorWhite), IColorToTCell(x, ColorNone, Mode256Colors))
+	rgb, ok := IColorToRGB(y)
+	assert.True(t, ok)
+	assert.Equal(t, RGBColor{0, 0x33, 0}, rgb)
+	assert.Equal(t, StyleAttrs{OnOff: tcell.AttrBold, Set: tcell.AttrBold | tcell.AttrUnderline}, z)
+
+	title, _ := p.CellStyler("title")
+	x, y, z = title.GetStyle(ctx)
+	assert.Equal(t, MakeTCellColorExt(tcell.ColorYellow), IColorToTCell(x, ColorNone, Mode16Colors))
+	assert.Equal(t, IColorToTCell(MakeRGBColor("#ff8700"), ColorNone, Mode256Colors), IColorToTCell(x, ColorNone, Mode256Colors))
+	assert.Equal(t, MakeTCellColorExt(tcell.NewRGBColor(0xff, 0xaf, 0)), IColorToTCell(x, ColorNone, Mode24BitColors))
+	assert.Equal(t, ColorNone, IColorToTCell(y, ColorNone, Mode256Colors))
+	assert.Equal(t, StyleReverse.MergeUnder(StyleDim), z)
+
+	// A missing variant falls back to the nearest
+	p, err = LoadPalette([]byte(`{"x": {"bg": {"256": "dark blue"}}}`), nil)
+	assert.NoError(t, err)
+	_, y, _ = p["x"].GetStyle(ctx)
+	assert.Equal(t, IColorToTCell(NewUrwidColor("dark blue"), ColorNone, Mode24BitColors), IColorToTCell(y, ColorNone, Mode24BitColors))
+}
+
+func TestPaletteConfig2(t *testing.T) {
+	for _, bad := range []string{
+		`[]`,
+		`{"x": "red"}`,
+		`{"x": {"fg": "notacolor"}}`,
+		`{"x": {"fg": {"8": "red"}}}`,
+		`{"x": {"attrs": ["italic"]}}`,
+		`{"x": {"colour": "red"}}`,
+	} {
+		_, err := LoadPalette([]byte(bad), nil)
+		assert.IsType(t, PaletteConfigError{}, errors.Cause(err), bad)
+	}
+
+	_, err := LoadPalette([]byte(`{`), nil)
+	assert.Error(t, err)
+}
+
+func TestPaletteConfig3(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gowid-palette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "colors.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"x": {"fg": "red"}}`), 0644))
+	p, err := LoadPaletteFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(p))
+
+	// Other formats need a decoder; any func with Unmarshal's signature will do
+	path = filepath.Join(dir, "colors.toml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`ignored`), 0644))
+	_, err = LoadPaletteFile(path)
+	assert.IsType(t, PaletteConfigError{}, errors.Cause(err))
+
+	decode := func(data []byte, v interface{}) error {
+		*(v.(*interface{})) = map[interface{}]interface{}{
+			"x": map[interface{}]interface{}{"bg": "blue", "attrs": []interface{}{"bold"}},
+		}
+		return nil
+	}
+	p, err = LoadPaletteFile(path, decode)
+	assert.NoError(t, err)
+	_, y, z := p["x"].GetStyle(stateTestContext{p})
+	assert.Equal(t, MakeTCellColorExt(tcell.ColorBlue), IColorToTCell(y, ColorNone, Mode256Colors))
+	assert.Equal(t, StyleBold, z)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,119 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+)
+
+//======================================================================
+
+// PanicInfo describes a panic recovered by the app.
+type PanicInfo struct {
+	Value  interface{} // The value passed to panic
+	Stack  []byte      // The stack of the goroutine that panicked
+	During string      // "rendering" or "handling input" if a widget panicked; otherwise empty
+}
+
+func (p PanicInfo) String() string {
+	if p.During == "" {
+		return fmt.Sprintf("panic: %v", p.Value)
+	}
+	return fmt.Sprintf("panic while %s: %v", p.During, p.Value)
+}
+
+// Error lets a PanicInfo be returned as an error by code that recovers the panic, like ansi.MainLoop.
+func (p PanicInfo) Error() string {
+	return p.String()
+}
+
+// PanicOptions configures what the app does when it recovers a panic - see RecoverPanic.
+type PanicOptions struct {
+	Output io.Writer                   // The panic and stack are written here; defaults to os.Stderr
+	Hook   func(app IApp, p PanicInfo) // If not nil, called once the terminal has been restored
+	Exit   bool                        // If true, exit with PanicExitCode instead of panicking again
+}
+
+// panicExit is called to end the program once a panic has been reported, if PanicOptions.Exit is set.
+var panicExit = os.Exit
+
+// PanicExitCode is the status the program exits with after a panic is reported, if PanicOptions.Exit is
+// set - the same as the Go runtime uses for an unrecovered panic.
+const PanicExitCode = 2
+
+//======================================================================
+
+// SetPanicOptions configures how panics recovered by the app are reported.
+func (a *App) SetPanicOptions(opts PanicOptions) {
+	a.panicOpts = opts
+}
+
+// RecoverPanic must be deferred. If the goroutine is panicking, the terminal is restored to a sane state -
+// the alt screen is left and raw mode turned off - then the panic and its stack are printed, the hook
+// configured with SetPanicOptions is called, and the goroutine panics again with the same value, so that
+// the caller's deferred functions run and it can recover the panic - or, if PanicOptions.Exit is set, the
+// program exits. Without this, a panic leaves the
+// terminal unusable, and the panic message is lost when the screen is cleared. MainLoop defers it, so
+// panics in widgets' Render and UserInput, and in functions passed to App.Run, are covered; apps that run
+// their own event loop, or start goroutines that can panic while the app is running, should defer it too:
+//
+//   go func() {
+//       defer app.RecoverPanic()
+//       ...
+//   }()
+//
+func (a *App) RecoverPanic() {
+	if r := recover(); r != nil {
+		a.handlePanic(PanicInfo{
+			Value:  r,
+			Stack:  debug.Stack(),
+			During: a.panicDuring,
+		})
+	}
+}
+
+// Panicked returns the panic reported by RecoverPanic, if there has been one.
+func (a *App) Panicked() (PanicInfo, bool) {
+	if a.panicked == nil {
+		return PanicInfo{}, false
+	}
+	return *a.panicked, true
+}
+
+func (a *App) handlePanic(info PanicInfo) {
+	// Only the first panic is reported - the restored terminal can't be restored again, and a panic
+	// raised again may be recovered by an outer RecoverPanic.
+	if a.panicked != nil {
+		panic(info.Value)
+	}
+	a.panicked = &info
+
+	if a.screen != nil {
+		a.DeactivateScreen()
+	}
+
+	out := a.panicOpts.Output
+	if out == nil {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "%v\n\n%s", info, info.Stack)
+
+	if a.panicOpts.Hook != nil {
+		a.panicOpts.Hook(a, info)
+	}
+	if a.panicOpts.Exit {
+		panicExit(PanicExitCode)
+	}
+	panic(info.Value)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
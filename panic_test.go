@@ -0,0 +1,43 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPanicExit1(t *testing.T) {
+	code := -1
+	defer func(f func(int)) { panicExit = f }(panicExit)
+	panicExit = func(c int) { code = c }
+
+	// By default, the panic carries on once it's reported
+	var out bytes.Buffer
+	a := &App{}
+	a.SetPanicOptions(PanicOptions{Output: &out})
+	assert.PanicsWithValue(t, "boom", func() {
+		defer a.RecoverPanic()
+		panic("boom")
+	})
+	assert.Equal(t, -1, code)
+	assert.Contains(t, out.String(), "panic: boom")
+
+	// Exiting is opt-in - the real os.Exit wouldn't return to panic again
+	a = &App{}
+	a.SetPanicOptions(PanicOptions{Output: &out, Exit: true})
+	assert.Panics(t, func() {
+		defer a.RecoverPanic()
+		panic("boom")
+	})
+	assert.Equal(t, PanicExitCode, code)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
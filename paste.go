@@ -0,0 +1,227 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// PasteEvent is sent through the widget hierarchy, like a key event, when the
+// user pastes text into a terminal with bracketed paste mode enabled. Text holds
+// everything pasted, with line endings converted to "\n", so that a widget can
+// insert it in one operation instead of treating each character - in particular
+// each newline - as a keypress.
+type PasteEvent struct {
+	Text string
+	Time time.Time
+}
+
+var _ tcell.Event = (*PasteEvent)(nil)
+
+func (e *PasteEvent) When() time.Time {
+	return e.Time
+}
+
+// PasteMarkerTimeout is how long the app waits for the rest of a paste start
+// marker before treating the keys received so far as ordinary input.
+var PasteMarkerTimeout = 50 * time.Millisecond
+
+// A terminal in bracketed paste mode surrounds pasted text with ESC[200~ and
+// ESC[201~. TCell doesn't recognize these sequences, so they arrive as the key
+// Alt-[ followed by the runes of the rest of the marker.
+var (
+	pasteStart = []rune("[200~")
+	pasteEnd   = []rune("[201~")
+)
+
+// pasteDetector turns the key events between paste markers into a PasteEvent.
+type pasteDetector struct {
+	held    []interface{} // Keys matching the start of pasteStart, not yet dispatched
+	timer   ITimer        // If not nil, fires when held keys should be dispatched
+	pasting bool          // True once pasteStart has been seen
+	matched int           // The number of runes of pasteEnd seen while pasting
+	text    strings.Builder
+	lastCR  bool
+	when    time.Time
+}
+
+func markerKey(ev interface{}, marker []rune, i int) bool {
+	kev, ok := ev.(*tcell.EventKey)
+	if !ok || i >= len(marker) || kev.Key() != tcell.KeyRune || kev.Rune() != marker[i] {
+		return false
+	}
+	if i == 0 {
+		return kev.Modifiers() == tcell.ModAlt
+	}
+	return kev.Modifiers() == tcell.ModNone
+}
+
+// due returns a channel that is readable when held keys should be dispatched.
+func (p *pasteDetector) due() <-chan time.Time {
+	if p == nil || p.timer == nil {
+		return nil
+	}
+	return p.timer.Chan()
+}
+
+func (p *pasteDetector) stopTimer() {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+}
+
+// flush returns any held keys, which are no longer thought to be part of a paste
+// marker.
+func (p *pasteDetector) flush() []interface{} {
+	res := p.held
+	p.held = nil
+	p.stopTimer()
+	return res
+}
+
+// filter is given each event from tcell, and returns the events that should be
+// dispatched in its place - none if the event might be part of a paste.
+func (p *pasteDetector) filter(ev interface{}, clock IClock) []interface{} {
+	if p.pasting {
+		kev, ok := ev.(*tcell.EventKey)
+		if !ok {
+			return []interface{}{ev}
+		}
+		if markerKey(kev, pasteEnd, p.matched) {
+			p.matched++
+			if p.matched < len(pasteEnd) {
+				return nil
+			}
+			res := &PasteEvent{Text: p.text.String(), Time: p.when}
+			p.pasting = false
+			p.matched = 0
+			p.text.Reset()
+			p.lastCR = false
+			return []interface{}{res}
+		}
+		if p.matched > 0 {
+			// Not the end after all - this was pasted text
+			p.text.WriteString("\x1b" + string(pasteEnd[:p.matched]))
+			p.matched = 0
+			p.lastCR = false
+			return p.filter(ev, clock)
+		}
+		p.addKey(kev)
+		return nil
+	}
+
+	if markerKey(ev, pasteStart, len(p.held)) {
+		p.held = append(p.held, ev)
+		if len(p.held) == len(pasteStart) {
+			p.pasting = true
+			p.when = p.held[0].(*tcell.EventKey).When()
+			p.flush()
+		} else if p.timer == nil {
+			p.timer = clock.NewTimer(PasteMarkerTimeout)
+		}
+		return nil
+	}
+	if len(p.held) == 0 {
+		return []interface{}{ev}
+	}
+	// The held keys weren't a paste marker; ev might begin one
+	return append(p.flush(), p.filter(ev, clock)...)
+}
+
+// addKey appends the text corresponding to a key received while pasting. The
+// terminal sends Enter for each newline, which is written as "\n", as is a CR LF
+// pair.
+func (p *pasteDetector) addKey(kev *tcell.EventKey) {
+	cr := false
+	switch k := kev.Key(); {
+	case k == tcell.KeyRune:
+		if kev.Modifiers()&tcell.ModAlt != 0 {
+			p.text.WriteRune('\x1b')
+		}
+		p.text.WriteRune(kev.Rune())
+	case k == tcell.KeyCR:
+		p.text.WriteRune('\n')
+		cr = true
+	case k == tcell.KeyLF:
+		if !p.lastCR {
+			p.text.WriteRune('\n')
+		}
+	case k < tcell.KeyRune:
+		// Other control characters
+		p.text.WriteRune(rune(k))
+	}
+	p.lastCR = cr
+}
+
+//======================================================================
+
+// EnableBracketedPaste asks the terminal to mark the start and end of pasted
+// text. Text pasted by the user is then delivered to widgets as a single
+// PasteEvent, rather than as a series of key events. Bracketed paste mode is
+// switched off when the screen is deactivated or the app is closed, and back on
+// when the screen is next activated.
+func (a *App) EnableBracketedPaste() {
+	if a.paste == nil {
+		a.paste = &pasteDetector{}
+	}
+	a.setBracketedPasteMode(true)
+}
+
+// DisableBracketedPaste switches off bracketed paste mode. Any paste in progress
+// is discarded.
+func (a *App) DisableBracketedPaste() {
+	if a.paste != nil {
+		a.paste.stopTimer()
+		a.setBracketedPasteMode(false)
+		a.paste = nil
+	}
+}
+
+// BracketedPasteEnabled returns true if pasted text is delivered as a
+// PasteEvent.
+func (a *App) BracketedPasteEnabled() bool {
+	return a.paste != nil
+}
+
+// BracketedPasteTimeout returns a channel that is readable when keys held back,
+// because they might have been the start of a paste, should be dispatched
+// anyway. The channel is nil if no keys are held. Apps that run their own main
+// loop should call FlushBracketedPaste when it is readable.
+func (a *App) BracketedPasteTimeout() <-chan time.Time {
+	return a.paste.due()
+}
+
+// FlushBracketedPaste dispatches any keys held back because they might have
+// been the start of a paste.
+func (a *App) FlushBracketedPaste(unhandled IUnhandledInput) {
+	if a.paste == nil {
+		return
+	}
+	for _, ev := range a.paste.flush() {
+		a.handleTCellEvent(ev, unhandled)
+	}
+}
+
+// setBracketedPasteMode writes the escape sequence to switch bracketed paste
+// on or off.
+func (a *App) setBracketedPasteMode(on bool) {
+	if on {
+		a.writeToTerminal("\x1b[?2004h")
+	} else {
+		a.writeToTerminal("\x1b[?2004l")
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,290 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//======================================================================
+
+// ProfileOp identifies the widget operation being measured by a Profiler.
+type ProfileOp int
+
+const (
+	ProfileRender ProfileOp = iota
+	ProfileUserInput
+)
+
+func (p ProfileOp) String() string {
+	switch p {
+	case ProfileRender:
+		return "Render"
+	case ProfileUserInput:
+		return "UserInput"
+	default:
+		return fmt.Sprintf("Unknown (%d)", int(p))
+	}
+}
+
+// ProfileEntry holds the accumulated measurements for one named widget and
+// one operation. Durations include time spent in child widgets.
+type ProfileEntry struct {
+	Name   string
+	Op     ProfileOp
+	Calls  int
+	Total  time.Duration
+	Max    time.Duration
+	Allocs uint64
+}
+
+// Mean returns the average duration of each call.
+func (e ProfileEntry) Mean() time.Duration {
+	if e.Calls == 0 {
+		return 0
+	}
+	return e.Total / time.Duration(e.Calls)
+}
+
+func (e ProfileEntry) String() string {
+	return fmt.Sprintf("%s.%v calls=%d total=%v mean=%v max=%v allocs=%d",
+		e.Name, e.Op, e.Calls, e.Total, e.Mean(), e.Max, e.Allocs)
+}
+
+type profileKey struct {
+	name string
+	op   ProfileOp
+}
+
+// Profiler accumulates per-widget Render and UserInput timings and allocation
+// counts. It is safe to use from multiple goroutines. A nil *Profiler records
+// nothing, so widgets can call it unconditionally.
+type Profiler struct {
+	sync.Mutex
+	entries     map[profileKey]*ProfileEntry
+	countAllocs bool
+}
+
+// ProfilerOptions is used to configure a Profiler.
+type ProfilerOptions struct {
+	// CountAllocs enables counting heap allocations for each measured call. This
+	// relies on runtime.ReadMemStats, which is expensive, so is off by default.
+	CountAllocs bool
+}
+
+// NewProfiler returns an initialized and empty Profiler.
+func NewProfiler(opts ...ProfilerOptions) *Profiler {
+	var opt ProfilerOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return &Profiler{
+		entries:     make(map[profileKey]*ProfileEntry),
+		countAllocs: opt.CountAllocs,
+	}
+}
+
+func readMallocs() uint64 {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.Mallocs
+}
+
+// Measure runs f and records its duration (and allocations, if enabled) under
+// the supplied name and operation.
+func (p *Profiler) Measure(name string, op ProfileOp, f func()) {
+	if p == nil {
+		f()
+		return
+	}
+	var mallocs uint64
+	if p.countAllocs {
+		mallocs = readMallocs()
+	}
+	start := time.Now()
+	f()
+	dur := time.Since(start)
+	if p.countAllocs {
+		mallocs = readMallocs() - mallocs
+	}
+	p.Record(name, op, dur, mallocs)
+}
+
+// Record adds a single measurement to the profile.
+func (p *Profiler) Record(name string, op ProfileOp, dur time.Duration, allocs uint64) {
+	if p == nil {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+	k := profileKey{name: name, op: op}
+	e, ok := p.entries[k]
+	if !ok {
+		e = &ProfileEntry{Name: name, Op: op}
+		p.entries[k] = e
+	}
+	e.Calls++
+	e.Total += dur
+	e.Allocs += allocs
+	if dur > e.Max {
+		e.Max = dur
+	}
+}
+
+// Reset discards all measurements.
+func (p *Profiler) Reset() {
+	p.Lock()
+	defer p.Unlock()
+	p.entries = make(map[profileKey]*ProfileEntry)
+}
+
+// Report returns a copy of all measurements, most expensive (by total time) first.
+func (p *Profiler) Report() []ProfileEntry {
+	p.Lock()
+	res := make([]ProfileEntry, 0, len(p.entries))
+	for _, e := range p.entries {
+		res = append(res, *e)
+	}
+	p.Unlock()
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Total != res[j].Total {
+			return res[i].Total > res[j].Total
+		}
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].Op < res[j].Op
+	})
+	return res
+}
+
+// Lookup returns the measurements for the named widget and operation, if any exist.
+func (p *Profiler) Lookup(name string, op ProfileOp) (ProfileEntry, bool) {
+	p.Lock()
+	defer p.Unlock()
+	if e, ok := p.entries[profileKey{name: name, op: op}]; ok {
+		return *e, true
+	}
+	return ProfileEntry{}, false
+}
+
+// String returns the report as a table, one line per entry.
+func (p *Profiler) String() string {
+	rep := p.Report()
+	lines := make([]string, 0, len(rep)+1)
+	lines = append(lines, fmt.Sprintf("%-24s %-9s %8s %12s %12s %12s %10s", "widget", "op", "calls", "total", "mean", "max", "allocs"))
+	for _, e := range rep {
+		lines = append(lines, fmt.Sprintf("%-24s %-9v %8d %12v %12v %12v %10d", e.Name, e.Op, e.Calls, e.Total, e.Mean(), e.Max, e.Allocs))
+	}
+	return strings.Join(lines, "\n")
+}
+
+//======================================================================
+
+// IProfiled is implemented by an IApp that can record widget timings, like App.
+type IProfiled interface {
+	Profiler() *Profiler
+}
+
+// ProfilerFor returns the app's Profiler if the app supports profiling and
+// profiling is enabled; otherwise nil is returned.
+func ProfilerFor(app IApp) *Profiler {
+	if p, ok := app.(IProfiled); ok {
+		return p.Profiler()
+	}
+	return nil
+}
+
+// ProfiledWidget wraps a widget and records the cost of its Render and UserInput
+// calls in the app's Profiler, if profiling is enabled. Wrap the widgets in a deep
+// hierarchy that you suspect are slow, and give each a name to identify it in
+// the report.
+type ProfiledWidget struct {
+	IWidget
+	Name string
+}
+
+var _ ICompositeWidget = (*ProfiledWidget)(nil)
+
+// NewProfiledWidget returns a ProfiledWidget wrapping w. If name is empty,
+// the inner widget's type is used.
+func NewProfiledWidget(name string, w IWidget) *ProfiledWidget {
+	if name == "" {
+		name = fmt.Sprintf("%T", w)
+	}
+	return &ProfiledWidget{
+		IWidget: w,
+		Name:    name,
+	}
+}
+
+func (w *ProfiledWidget) String() string {
+	return fmt.Sprintf("profiled[%s,%v]", w.Name, w.IWidget)
+}
+
+func (w *ProfiledWidget) SubWidget() IWidget {
+	return w.IWidget
+}
+
+func (w *ProfiledWidget) SetSubWidget(inner IWidget, app IApp) {
+	w.IWidget = inner
+}
+
+func (w *ProfiledWidget) SubWidgetSize(size IRenderSize, focus Selector, app IApp) IRenderSize {
+	return size
+}
+
+func (w *ProfiledWidget) Render(size IRenderSize, focus Selector, app IApp) ICanvas {
+	var res ICanvas
+	ProfilerFor(app).Measure(w.Name, ProfileRender, func() {
+		res = w.IWidget.Render(size, focus, app)
+	})
+	return res
+}
+
+func (w *ProfiledWidget) UserInput(ev interface{}, size IRenderSize, focus Selector, app IApp) bool {
+	var res bool
+	ProfilerFor(app).Measure(w.Name, ProfileUserInput, func() {
+		res = w.IWidget.UserInput(ev, size, focus, app)
+	})
+	return res
+}
+
+//======================================================================
+
+// drawProfileOverlay writes the most expensive entries of the profile over the
+// top-right corner of the supplied canvas.
+func drawProfileOverlay(p *Profiler, canvas ICanvas, maxEntries int) {
+	rep := p.Report()
+	if len(rep) > maxEntries {
+		rep = rep[0:maxEntries]
+	}
+	style := MakeCell(' ', ColorWhite, ColorBlack, StyleNone)
+	for i, e := range rep {
+		if i >= canvas.BoxRows() {
+			break
+		}
+		line := []rune(fmt.Sprintf(" %s.%v %v/%d ", e.Name, e.Op, e.Mean(), e.Calls))
+		start := canvas.BoxColumns() - len(line)
+		if start < 0 {
+			line = line[-start:]
+			start = 0
+		}
+		for j, r := range line {
+			canvas.SetCellAt(start+j, i, style.WithRune(r))
+		}
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
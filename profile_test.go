@@ -0,0 +1,65 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiler1(t *testing.T) {
+	p := NewProfiler()
+
+	p.Record("a", ProfileRender, 10*time.Millisecond, 0)
+	p.Record("a", ProfileRender, 30*time.Millisecond, 0)
+	p.Record("b", ProfileRender, 5*time.Millisecond, 0)
+	p.Record("a", ProfileUserInput, 1*time.Millisecond, 0)
+
+	e, ok := p.Lookup("a", ProfileRender)
+	assert.True(t, ok)
+	assert.Equal(t, 2, e.Calls)
+	assert.Equal(t, 40*time.Millisecond, e.Total)
+	assert.Equal(t, 30*time.Millisecond, e.Max)
+	assert.Equal(t, 20*time.Millisecond, e.Mean())
+
+	rep := p.Report()
+	assert.Equal(t, 3, len(rep))
+	assert.Equal(t, "a", rep[0].Name)
+	assert.Equal(t, "b", rep[1].Name)
+	assert.Equal(t, ProfileUserInput, rep[2].Op)
+
+	p.Reset()
+	_, ok = p.Lookup("a", ProfileRender)
+	assert.False(t, ok)
+}
+
+func TestProfiler2(t *testing.T) {
+	var p *Profiler
+	x := 0
+	p.Measure("nil", ProfileRender, func() { x++ })
+	assert.Equal(t, 1, x)
+
+	p = NewProfiler()
+	p.Measure("w", ProfileRender, func() { x++ })
+	e, ok := p.Lookup("w", ProfileRender)
+	assert.True(t, ok)
+	assert.Equal(t, 1, e.Calls)
+	assert.Equal(t, 2, x)
+}
+
+func TestProfiler3(t *testing.T) {
+	p := NewProfiler()
+	p.Record("root", ProfileRender, time.Millisecond, 0)
+	c := NewCanvasOfSize(30, 2)
+	drawProfileOverlay(p, c, 5)
+	assert.Contains(t, c.String(), "root.Render 1ms/1")
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,224 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// RecordedEvent is a serializable form of a tcell key, mouse or resize event,
+// together with its offset from the start of the recording.
+type RecordedEvent struct {
+	Offset  time.Duration    `json:"t"`
+	Type    string           `json:"type"`
+	Key     tcell.Key        `json:"key,omitempty"`
+	Rune    rune             `json:"rune,omitempty"`
+	Mod     tcell.ModMask    `json:"mod,omitempty"`
+	X       int              `json:"x,omitempty"`
+	Y       int              `json:"y,omitempty"`
+	Buttons tcell.ButtonMask `json:"buttons,omitempty"`
+}
+
+// MakeRecordedEvent converts a tcell event into a RecordedEvent. Only key,
+// mouse and resize events can be recorded; for others, false is returned.
+func MakeRecordedEvent(ev interface{}, offset time.Duration) (RecordedEvent, bool) {
+	res := RecordedEvent{Offset: offset}
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		res.Type = "key"
+		res.Key = ev.Key()
+		res.Rune = ev.Rune()
+		res.Mod = ev.Modifiers()
+	case *tcell.EventMouse:
+		res.Type = "mouse"
+		res.X, res.Y = ev.Position()
+		res.Buttons = ev.Buttons()
+		res.Mod = ev.Modifiers()
+	case *tcell.EventResize:
+		res.Type = "resize"
+		res.X, res.Y = ev.Size()
+	default:
+		return res, false
+	}
+	return res, true
+}
+
+// Event returns a new tcell event equivalent to the one recorded.
+func (r RecordedEvent) Event() (tcell.Event, error) {
+	switch r.Type {
+	case "key":
+		return tcell.NewEventKey(r.Key, r.Rune, r.Mod), nil
+	case "mouse":
+		return tcell.NewEventMouse(r.X, r.Y, r.Buttons, r.Mod), nil
+	case "resize":
+		return tcell.NewEventResize(r.X, r.Y), nil
+	default:
+		return nil, fmt.Errorf("Unknown recorded event type %q", r.Type)
+	}
+}
+
+// Recording is a sequence of events in the order they were handled.
+type Recording []RecordedEvent
+
+// LoadRecording reads a recording written by App.StartRecording - one JSON
+// object per line.
+func LoadRecording(r io.Reader) (Recording, error) {
+	res := make(Recording, 0)
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var ev RecordedEvent
+		if err := dec.Decode(&ev); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, WithKVs(err, map[string]interface{}{"event": len(res)})
+		}
+		if _, err := ev.Event(); err != nil {
+			return nil, WithKVs(err, map[string]interface{}{"event": len(res)})
+		}
+		res = append(res, ev)
+	}
+	return res, nil
+}
+
+// Events returns the recorded events as tcell events, e.g. to be fed one at
+// a time to App.HandleTCellEvent in a test.
+func (r Recording) Events() []tcell.Event {
+	res := make([]tcell.Event, 0, len(r))
+	for _, rev := range r {
+		if ev, err := rev.Event(); err == nil {
+			res = append(res, ev)
+		}
+	}
+	return res
+}
+
+//======================================================================
+
+type eventRecorder struct {
+	enc   *json.Encoder
+	start time.Time
+	err   error
+}
+
+// StartRecording makes the app write each key, mouse and resize event it
+// handles to w, with a timestamp relative to the start of the recording. The
+// events can be loaded with LoadRecording and replayed with App.Replay.
+func (a *App) StartRecording(w io.Writer) {
+	a.recorder = &eventRecorder{
+		enc:   json.NewEncoder(w),
+		start: a.Clock().Now(),
+	}
+}
+
+// StopRecording stops recording events. It returns the first error
+// encountered writing events, if any.
+func (a *App) StopRecording() error {
+	var err error
+	if a.recorder != nil {
+		err = a.recorder.err
+		a.recorder = nil
+	}
+	return err
+}
+
+func (a *App) recordEvent(ev interface{}) {
+	if a.recorder == nil || a.recorder.err != nil {
+		return
+	}
+	if rev, ok := MakeRecordedEvent(ev, a.Clock().Since(a.recorder.start)); ok {
+		a.recorder.err = a.recorder.enc.Encode(rev)
+	}
+}
+
+//======================================================================
+
+// ReplayOptions controls the replay of a recording.
+type ReplayOptions struct {
+	// Speed scales the playback rate - 2.0 replays events twice as fast as
+	// they were recorded. If Speed is zero or negative, there is no delay
+	// between events.
+	Speed float64
+}
+
+// Replayer delivers the events of a recording to a running app.
+type Replayer struct {
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Stop abandons the replay.
+func (r *Replayer) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+// Done returns a channel that is closed when the replay finishes or is stopped.
+func (r *Replayer) Done() <-chan struct{} {
+	return r.done
+}
+
+// Replay posts the events of rec to the app's event channel, timed according to
+// the offsets in the recording, so that they are processed by the app's main
+// loop exactly as user input would be. Delays are measured with the app's
+// clock. User input that arrives during the replay is processed as usual.
+func (a *App) Replay(rec Recording, opts ...ReplayOptions) *Replayer {
+	opt := ReplayOptions{Speed: 1.0}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	res := &Replayer{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	clock := a.Clock()
+
+	go func() {
+		defer close(res.done)
+		start := clock.Now()
+		for _, rev := range rec {
+			ev, err := rev.Event()
+			if err != nil {
+				continue
+			}
+			if opt.Speed > 0 {
+				due := time.Duration(float64(rev.Offset) / opt.Speed)
+				if wait := due - clock.Since(start); wait > 0 {
+					t := clock.NewTimer(wait)
+					select {
+					case <-t.Chan():
+					case <-res.stop:
+						t.Stop()
+						return
+					}
+				}
+			}
+			select {
+			case a.TCellEvents <- ev:
+			case <-res.stop:
+				return
+			}
+		}
+	}()
+
+	return res
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
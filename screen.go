@@ -0,0 +1,64 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// IScreen is the terminal an app draws on and takes input from - the part of tcell.Screen that gowid
+// uses. Any tcell screen will do, but so will another backend: a different terminal library, a remote
+// display, or a test double. Cells are styled with tcell.Style, and input is delivered as tcell events -
+// built by the backend with e.g. tcell.NewEventKey, tcell.NewEventMouse and tcell.NewEventResize - since
+// these are the vocabulary of gowid's widgets.
+type IScreen interface {
+	// Init prepares the screen for use; Fini restores the terminal.
+	Init() error
+	Fini()
+	Clear()
+	// Show makes the content set since the last call visible; Sync redraws every cell.
+	Show()
+	Sync()
+	Size() (int, int)
+	// Colors returns the number of colors the screen supports - 0 or less if it is monochrome.
+	Colors() int
+	CharacterSet() string
+	SetStyle(style tcell.Style)
+	SetContent(x int, y int, mainc rune, combc []rune, style tcell.Style)
+	GetContent(x, y int) (mainc rune, combc []rune, style tcell.Style, width int)
+	// ShowCursor displays the cursor at x, y - or hides it if either is negative.
+	ShowCursor(x int, y int)
+	EnableMouse()
+	// PollEvent waits for the next event, returning nil once the screen is finalized.
+	PollEvent() tcell.Event
+	PostEventWait(ev tcell.Event)
+}
+
+var _ IScreen = (tcell.Screen)(nil)
+
+// IRawWriter is implemented by a screen that can send escape sequences of its own to the terminal,
+// outside the cells it draws - for hyperlinks, inline images and the clipboard. For a screen that
+// doesn't implement it, sequences are written to the controlling terminal, /dev/tty, unless the screen is
+// a tcell.SimulationScreen.
+type IRawWriter interface {
+	WriteRaw(s string) error
+}
+
+// ScreenFactory returns a new screen for an app to draw on. The app calls it when it is created without
+// a screen, and whenever it takes the terminal back after giving it up - see ActivateScreen.
+type ScreenFactory func() (IScreen, error)
+
+// NewTCellScreen is the default ScreenFactory, returning a tcell screen for the current terminal.
+func NewTCellScreen() (IScreen, error) {
+	return tcell.NewScreen()
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,154 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"math"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// ScrollEvent is a scroll by a precise amount, from a trackpad or a high-resolution wheel, for screens that
+// can report one - tcell only reports whole wheel clicks. The app adds up the fractions and passes each
+// whole row or column scrolled through the widget hierarchy as a mouse wheel event, so every scrollable
+// widget moves smoothly without having to handle ScrollEvent itself.
+type ScrollEvent struct {
+	X, Y   int     // The cell under the pointer
+	DX, DY float64 // Columns and rows scrolled - positive for right and down
+	Mod    tcell.ModMask
+	Time   time.Time
+}
+
+var _ tcell.Event = (*ScrollEvent)(nil)
+
+func (e *ScrollEvent) When() time.Time {
+	return e.Time
+}
+
+// SmoothScrollOptions is used to configure smooth scrolling - see App.SetSmoothScroll.
+type SmoothScrollOptions struct {
+	// Burst is the longest time between wheel events from a trackpad, which sends them in a rapid stream.
+	// Defaults to DefaultScrollBurst.
+	Burst time.Duration
+	// Step is the rows scrolled by each wheel event after the first in a burst. Defaults to
+	// DefaultScrollStep.
+	Step float64
+}
+
+var (
+	// DefaultScrollBurst is the longest time between the wheel events of one trackpad gesture, unless the
+	// options say otherwise.
+	DefaultScrollBurst = 40 * time.Millisecond
+	// DefaultScrollStep is the fraction of a row scrolled by each wheel event in a burst, unless the options
+	// say otherwise.
+	DefaultScrollStep = 1.0 / 3
+)
+
+// scrollAccumulator adds up fractional scrolls, and the wheel events of trackpad gestures, into whole
+// rows and columns.
+type scrollAccumulator struct {
+	opts   *SmoothScrollOptions // If nil, wheel events are passed on as they are
+	dx, dy float64              // The fractions not yet scrolled
+	last   time.Time            // When the last wheel event arrived
+	button tcell.ButtonMask     // The last wheel event's direction
+}
+
+// SetSmoothScroll turns on smooth scrolling for trackpads. A trackpad reports a gesture as a rapid burst
+// of mouse wheel events, each of which would normally scroll a whole row, so the view jumps. With
+// smoothing, the first event of a burst scrolls a row, as a wheel click does, but the rest each count as
+// a fraction of one - see SmoothScrollOptions - and the widget under the pointer sees a wheel event only
+// when they add up to a row. Precise ScrollEvents are added up the same way whether or not smoothing is
+// on. Nil options turn smoothing off.
+func (a *App) SetSmoothScroll(opts *SmoothScrollOptions) {
+	if opts == nil {
+		a.scroll = scrollAccumulator{}
+		return
+	}
+	opt := *opts
+	if opt.Burst <= 0 {
+		opt.Burst = DefaultScrollBurst
+	}
+	if opt.Step <= 0 {
+		opt.Step = DefaultScrollStep
+	}
+	a.scroll = scrollAccumulator{opts: &opt}
+}
+
+// filter returns the events to handle in place of ev - ev itself, unless it scrolls. Bursts of wheel events
+// are timed by clock, rather than by the events' own times, so that they can be tested.
+func (s *scrollAccumulator) filter(ev interface{}, clock IClock) []interface{} {
+	switch ev := ev.(type) {
+	case *ScrollEvent:
+		return s.add(ev.X, ev.Y, ev.DX, ev.DY, ev.Mod)
+	case *tcell.EventMouse:
+		if s.opts == nil {
+			break
+		}
+		b := ev.Buttons()
+		dx, dy := 0.0, 0.0
+		switch b {
+		case tcell.WheelUp:
+			dy = -1
+		case tcell.WheelDown:
+			dy = 1
+		case tcell.WheelLeft:
+			dx = -1
+		case tcell.WheelRight:
+			dx = 1
+		default:
+			return []interface{}{ev}
+		}
+		now := clock.Now()
+		if b == s.button && now.Sub(s.last) < s.opts.Burst {
+			dx, dy = dx*s.opts.Step, dy*s.opts.Step
+		}
+		s.last, s.button = now, b
+		x, y := ev.Position()
+		return s.add(x, y, dx, dy, ev.Modifiers())
+	}
+	return []interface{}{ev}
+}
+
+// add adds dx and dy to the fractions not yet scrolled, returning a wheel event for each whole row and
+// column. A scroll in the other direction starts again from nothing.
+func (s *scrollAccumulator) add(x, y int, dx, dy float64, mod tcell.ModMask) []interface{} {
+	if dx*s.dx < 0 {
+		s.dx = 0
+	}
+	if dy*s.dy < 0 {
+		s.dy = 0
+	}
+	s.dx += dx
+	s.dy += dy
+	res := make([]interface{}, 0)
+	res = appendWheels(res, x, y, &s.dx, tcell.WheelLeft, tcell.WheelRight, mod)
+	res = appendWheels(res, x, y, &s.dy, tcell.WheelUp, tcell.WheelDown, mod)
+	return res
+}
+
+// appendWheels appends a wheel event for each whole unit in *d, leaving the fraction.
+func appendWheels(res []interface{}, x, y int, d *float64, back, forward tcell.ButtonMask,
+	mod tcell.ModMask) []interface{} {
+	// Allow for rounding, so three thirds make a row
+	n := math.Trunc(*d + math.Copysign(1e-9, *d))
+	*d -= n
+	b := forward
+	if n < 0 {
+		b, n = back, -n
+	}
+	for i := 0; i < int(n); i++ {
+		res = append(res, tcell.NewEventMouse(x, y, b, mod))
+	}
+	return res
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
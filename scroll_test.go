@@ -0,0 +1,57 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func wheels(evs []interface{}) []tcell.ButtonMask {
+	res := make([]tcell.ButtonMask, 0)
+	for _, ev := range evs {
+		res = append(res, ev.(*tcell.EventMouse).Buttons())
+	}
+	return res
+}
+
+func TestScrollAccumulator1(t *testing.T) {
+	var s scrollAccumulator
+
+	// Without smoothing, wheel events are passed on, and precise scrolls are added up
+	ev := tcell.NewEventMouse(1, 2, tcell.WheelDown, 0)
+	assert.Equal(t, []interface{}{ev}, s.filter(ev, DefaultClock))
+	assert.Empty(t, s.filter(&ScrollEvent{DY: 0.6}, DefaultClock))
+	assert.Equal(t, []tcell.ButtonMask{tcell.WheelDown}, wheels(s.filter(&ScrollEvent{DY: 0.6}, DefaultClock)))
+	assert.Equal(t, []tcell.ButtonMask{tcell.WheelLeft, tcell.WheelLeft}, wheels(s.filter(&ScrollEvent{DX: -2.5}, DefaultClock)))
+	// Changing direction starts again
+	assert.Empty(t, s.filter(&ScrollEvent{DY: -0.9}, DefaultClock))
+	assert.Empty(t, s.filter(&ScrollEvent{DX: 0.4}, DefaultClock))
+
+	a := &App{}
+	a.SetSmoothScroll(&SmoothScrollOptions{})
+	s = a.scroll
+	clock := NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	up := tcell.NewEventMouse(1, 2, tcell.WheelUp, 0)
+	res := make([]tcell.ButtonMask, 0)
+	for i := 0; i < 7; i++ {
+		res = append(res, wheels(s.filter(up, clock))...)
+		clock.Advance(10 * time.Millisecond)
+	}
+	// A row for the first of the burst, then one for every three after
+	assert.Equal(t, []tcell.ButtonMask{tcell.WheelUp, tcell.WheelUp, tcell.WheelUp}, res)
+
+	// A wheel click on its own scrolls a whole row
+	clock.Advance(time.Second)
+	assert.Equal(t, []tcell.ButtonMask{tcell.WheelUp}, wheels(s.filter(up, clock)))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
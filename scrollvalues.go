@@ -0,0 +1,51 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+//======================================================================
+
+// Orientation is the direction along which content scrolls.
+type Orientation int
+
+const (
+	Vertical Orientation = iota
+	Horizontal
+)
+
+func (o Orientation) String() string {
+	if o == Horizontal {
+		return "horizontal"
+	}
+	return "vertical"
+}
+
+// ScrollValues says how far a widget's content is scrolled along one axis, in whatever units the widget
+// scrolls by - rows, lines, columns or list items.
+type ScrollValues struct {
+	Total   int // The size of the content
+	Offset  int // The first unit in view
+	Visible int // How many units are in view
+}
+
+// CanScroll returns true if there is more content than is in view.
+func (v ScrollValues) CanScroll() bool {
+	return v.Total > v.Visible
+}
+
+// IScrollValues is implemented by widgets whose content scrolls, so that a scrollbar can show and set
+// their position - see the scrollbar package. The list, text, table and frozen widgets implement it.
+type IScrollValues interface {
+	// ScrollValues returns how far the widget is scrolled along the axis given, when rendered at size, and
+	// false if it doesn't scroll that way.
+	ScrollValues(o Orientation, size IRenderSize, focus Selector, app IApp) (ScrollValues, bool)
+	// ScrollTo scrolls the widget, rendered at size, so that offset is the first unit in view.
+	ScrollTo(o Orientation, offset int, size IRenderSize, focus Selector, app IApp)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
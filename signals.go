@@ -0,0 +1,183 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"os"
+	"os/signal"
+
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// JobControlNotSupportedError is returned by App.SuspendToShell on platforms without job control.
+type JobControlNotSupportedError struct{}
+
+var _ error = JobControlNotSupportedError{}
+
+func (e JobControlNotSupportedError) Error() string {
+	return "Suspending to the shell is not supported on this platform"
+}
+
+// signalState tracks the signals the app is notified of, and the callbacks registered for them.
+type signalState struct {
+	callbacks  *Callbacks        // Keyed by the os.Signal
+	ch         chan os.Signal    // Nil until a signal is first watched
+	watched    map[os.Signal]int // How many reasons there are to be notified of each signal
+	jobControl bool              // If true, ctrl-z and SIGTSTP suspend the app to the shell
+	resumed    bool              // True if the app has just continued after stopping itself
+}
+
+// OnSignal arranges for cb to be called on the app goroutine each time the process receives sig - e.g.
+// syscall.SIGHUP to reload configuration, or syscall.SIGWINCH to react to the terminal being resized.
+// The callback's widget argument is nil, and sig is passed as its only data. Signals the process is
+// notified of are no longer handled as Go does by default - see os/signal.
+func (a *App) OnSignal(sig os.Signal, cb IWidgetChangedCallback) {
+	if a.signals.callbacks == nil {
+		a.signals.callbacks = NewCallbacks()
+	}
+	AddWidgetCallback(a.signals.callbacks, sig, cb)
+	a.watchSignal(sig)
+}
+
+// RemoveOnSignal removes the callback for sig identified by id, added with OnSignal. Once the last is
+// removed, Go's default handling of sig is restored, unless the app needs it for job control.
+func (a *App) RemoveOnSignal(sig os.Signal, id IIdentity) {
+	if a.signals.callbacks == nil {
+		return
+	}
+	if a.signals.callbacks.RemoveCallback(sig, id) {
+		if cbs, _ := a.signals.callbacks.CopyOfCallbacks(sig); len(cbs) == 0 {
+			a.unwatchSignal(sig)
+		}
+	}
+}
+
+// JobControl returns true if ctrl-z and SIGTSTP suspend the app to the shell - see SetJobControl.
+func (a *App) JobControl() bool {
+	return a.signals.jobControl
+}
+
+// SetJobControl makes ctrl-z, when no widget handles it, and SIGTSTP, e.g. from kill, suspend the app to
+// the shell - see SuspendToShell. While it is on, if the process is continued after being stopped by
+// something else, like SIGSTOP, the terminal is set up again and the app redrawn. Without it, the
+// terminal is left in raw mode for the shell if the process is stopped, and the screen is stale when it
+// continues. It does nothing on platforms without job control.
+func (a *App) SetJobControl(on bool) {
+	if on == a.signals.jobControl || suspendSignal == nil {
+		return
+	}
+	a.signals.jobControl = on
+	for _, sig := range []os.Signal{suspendSignal, continueSignal} {
+		if on {
+			a.watchSignal(sig)
+		} else {
+			a.unwatchSignal(sig)
+		}
+	}
+}
+
+// SuspendToShell gives the terminal back and stops the process, as ctrl-z does for a program that
+// isn't in raw mode. When the shell continues it, e.g. with fg, the terminal is set up again and the app
+// fully redrawn. It must be called from the app goroutine. On platforms without job control, a
+// JobControlNotSupportedError is returned and the app carries on.
+func (a *App) SuspendToShell() error {
+	if suspendSignal == nil {
+		return JobControlNotSupportedError{}
+	}
+	return a.Suspend(func() error {
+		err := stopProcess()
+		a.signals.resumed = err == nil
+		return err
+	})
+}
+
+// watchSignal asks for the process to be notified of sig, starting the goroutine that passes signals to
+// the app goroutine if it isn't running yet.
+func (a *App) watchSignal(sig os.Signal) {
+	if a.signals.watched == nil {
+		a.signals.watched = make(map[os.Signal]int)
+	}
+	a.signals.watched[sig]++
+	if a.signals.ch == nil {
+		a.signals.ch = make(chan os.Signal, 8)
+		go a.forwardSignals(a.signals.ch)
+	}
+	signal.Notify(a.signals.ch, sig)
+}
+
+func (a *App) unwatchSignal(sig os.Signal) {
+	if a.signals.watched[sig] == 0 {
+		return
+	}
+	a.signals.watched[sig]--
+	if a.signals.watched[sig] == 0 {
+		delete(a.signals.watched, sig)
+		signal.Reset(sig)
+	}
+}
+
+// forwardSignals runs the app's handling of each signal received on ch on the app goroutine, until the
+// app quits.
+func (a *App) forwardSignals(ch chan os.Signal) {
+	defer signal.Stop(ch)
+	for {
+		select {
+		case sig := <-ch:
+			if a.Run(RunFunction(func(app IApp) { a.handleSignal(sig) })) != nil {
+				return
+			}
+		case <-a.Context().Done():
+			return
+		}
+	}
+}
+
+// handleSignal is called on the app goroutine for each signal the process is notified of.
+func (a *App) handleSignal(sig os.Signal) {
+	if a.signals.jobControl && sig != nil {
+		switch sig {
+		case suspendSignal:
+			if err := a.SuspendToShell(); err != nil {
+				a.logSignalError(sig, err)
+			}
+		case continueSignal:
+			if a.signals.resumed {
+				// Continued after SuspendToShell, which has set the terminal up already
+				a.signals.resumed = false
+			} else if err := a.Suspend(func() error { return nil }); err != nil {
+				a.logSignalError(sig, err)
+			}
+		}
+	}
+	RunWidgetCallbacks(a.signals.callbacks, sig, a, nil, sig)
+}
+
+func (a *App) logSignalError(sig os.Signal, err error) {
+	a.log.Printf("Could not handle signal %v: %v\n", sig, err)
+}
+
+// jobControlInput suspends the app to the shell on ctrl-z, if job control is on. It returns true if the
+// event was consumed.
+func (a *App) jobControlInput(ev interface{}) bool {
+	if !a.signals.jobControl {
+		return false
+	}
+	kev, ok := ev.(*tcell.EventKey)
+	if !ok || kev.Key() != tcell.KeyCtrlZ {
+		return false
+	}
+	if err := a.SuspendToShell(); err != nil {
+		a.logSignalError(suspendSignal, err)
+	}
+	return true
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
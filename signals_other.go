@@ -0,0 +1,27 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+//go:build windows || plan9 || js
+// +build windows plan9 js
+
+package gowid
+
+import (
+	"os"
+)
+
+//======================================================================
+
+// There is no job control - suspendSignal is nil.
+var suspendSignal, continueSignal os.Signal
+
+func stopProcess() error {
+	return JobControlNotSupportedError{}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,47 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build !windows && !plan9 && !js
+// +build !windows,!plan9,!js
+
+package gowid
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func TestOnSignal1(t *testing.T) {
+	a := &App{AfterRenderEvents: make(chan IAfterRenderEvent, 10)}
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+	defer a.cancel()
+
+	var got []interface{}
+	cb := MakeWidgetCallbackExt("usr1", func(app IApp, w IWidget, data ...interface{}) {
+		got = append(got, data...)
+	})
+	a.OnSignal(unix.SIGUSR1, cb)
+	assert.NoError(t, unix.Kill(os.Getpid(), unix.SIGUSR1))
+
+	select {
+	case ev := <-a.AfterRenderEvents:
+		ev.RunThenRenderEvent(a) // As the app goroutine would
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Signal was not forwarded to the app goroutine")
+	}
+	assert.Equal(t, []interface{}{unix.SIGUSR1}, got)
+
+	a.RemoveOnSignal(unix.SIGUSR1, CallbackID{Name: "usr1"})
+	assert.Empty(t, a.signals.watched)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,32 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+//go:build !windows && !plan9 && !js
+// +build !windows,!plan9,!js
+
+package gowid
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+//======================================================================
+
+var (
+	suspendSignal  os.Signal = unix.SIGTSTP
+	continueSignal os.Signal = unix.SIGCONT
+)
+
+// stopProcess stops the process's group, as the terminal does for ctrl-z, returning when it is continued.
+func stopProcess() error {
+	return unix.Kill(0, unix.SIGSTOP)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
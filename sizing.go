@@ -0,0 +1,135 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"strings"
+)
+
+//======================================================================
+
+// SizingError describes a widget whose canvas breaks the sizing contract - the canvas returned by Render
+// must be the size asked for, agree with RenderSize, and have lines of the same length.
+type SizingError struct {
+	Path    []IWidget   // The widgets being rendered, from the outermost, down to the offending one
+	Size    IRenderSize // The size the widget was asked to render at
+	Cols    int         // The columns of the canvas it returned
+	Rows    int         // And its rows
+	Problem string
+}
+
+var _ error = SizingError{}
+
+func (e SizingError) Widget() IWidget {
+	return e.Path[len(e.Path)-1]
+}
+
+func (e SizingError) Error() string {
+	path := make([]string, 0, len(e.Path))
+	for _, w := range e.Path {
+		path = append(path, fmt.Sprintf("%T", w))
+	}
+	return fmt.Sprintf("widget %v rendered at %v returned a %dx%d canvas: %s (path %s)",
+		e.Widget(), e.Size, e.Cols, e.Rows, e.Problem, strings.Join(path, " > "))
+}
+
+// StrictSizingOptions configures checks of the sizing contract - see App.SetStrictSizing.
+type StrictSizingOptions struct {
+	// OnError is called for each widget that breaks the contract - if nil, the app panics with err
+	OnError func(app IApp, err SizingError)
+}
+
+// IStrictSizing is implemented by an app that can check the sizing contract as widgets are rendered.
+type IStrictSizing interface {
+	sizeChecker() *sizeChecker
+}
+
+var _ IStrictSizing = (*App)(nil)
+
+// sizeChecker checks each canvas returned through Render, tracking the widgets being rendered so the
+// offending one can be found.
+type sizeChecker struct {
+	opts StrictSizingOptions
+	path []IWidget
+}
+
+func (a *App) sizeChecker() *sizeChecker {
+	return a.sizing
+}
+
+// SetStrictSizing turns on checks of the sizing contract, for debugging layout. After each widget rendered
+// with Render - which includes the root of the app, and the widgets rendered through it, but not a
+// widget's children rendered by calling their Render methods directly - its canvas is checked: it must
+// be the size asked for, the size the widget's RenderSize returns, and each line must be as long as the
+// canvas is wide. A widget that breaks the contract is reported with the path to it, rather than silently
+// corrupting the layout. The checks slow rendering, so pass nil to turn them off again.
+func (a *App) SetStrictSizing(opts *StrictSizingOptions) {
+	a.sizing = nil
+	if opts != nil {
+		a.sizing = &sizeChecker{opts: *opts}
+	}
+}
+
+// checkSizing returns the app's checker, if the sizing contract is being checked.
+func checkSizing(app IApp) *sizeChecker {
+	if sa, ok := app.(IStrictSizing); ok {
+		return sa.sizeChecker()
+	}
+	return nil
+}
+
+func (c *sizeChecker) check(w IWidget, size IRenderSize, focus Selector, canvas ICanvas, app IApp) {
+	cols, rows := canvas.BoxColumns(), canvas.BoxRows()
+	problem := ""
+	switch sz := size.(type) {
+	case IRenderBox:
+		switch {
+		case sz.BoxColumns() < 0 || sz.BoxRows() < 0:
+			problem = "the size asked for is negative, so the widget rendering it is at fault"
+		case (cols != sz.BoxColumns() && rows > 0) || rows != sz.BoxRows():
+			problem = fmt.Sprintf("expected %dx%d", sz.BoxColumns(), sz.BoxRows())
+		}
+	case IRenderFlowWith:
+		if cols != sz.FlowColumns() {
+			problem = fmt.Sprintf("expected %d columns", sz.FlowColumns())
+		}
+	}
+	if problem == "" {
+		for y := 0; y < rows; y++ {
+			if n := len(canvas.Line(y, LineCopy{}).Line); n != cols {
+				problem = fmt.Sprintf("line %d has %d cells", y, n)
+				break
+			}
+		}
+	}
+	if problem == "" {
+		// RenderSize may render the widget again, which is checked in its turn
+		if box := w.RenderSize(size, focus, app); box.BoxColumns() != cols || box.BoxRows() != rows {
+			problem = fmt.Sprintf("RenderSize returned %dx%d", box.BoxColumns(), box.BoxRows())
+		}
+	}
+	if problem == "" {
+		return
+	}
+	err := SizingError{
+		Path:    append([]IWidget(nil), c.path...),
+		Size:    size,
+		Cols:    cols,
+		Rows:    rows,
+		Problem: problem,
+	}
+	if c.opts.OnError != nil {
+		c.opts.OnError(app, err)
+	} else {
+		panic(err)
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,349 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"strings"
+
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gdamore/tcell"
+	log "github.com/sirupsen/logrus"
+)
+
+//======================================================================
+
+// SnapshotCell is one cell of a Snapshot. A Rune of 0 marks a cell covered by
+// the wide character to its left.
+type SnapshotCell struct {
+	Rune      rune
+	Combining string // Runes drawn with Rune, as one grapheme cluster
+	Style     tcell.Style
+}
+
+// Snapshot is a copy of a rendered screen - either from the terminal, via
+// App.Snapshot(), or from a canvas, via SnapshotCanvas(). It can be serialized
+// as plain text, text with ANSI escape sequences, or styled HTML, e.g. for
+// bug reports, documentation screenshots and golden-file tests.
+type Snapshot struct {
+	Cols   int
+	Rows   int
+	Cells  [][]SnapshotCell // Indexed by row, then column
+	Cursor CanvasPos        // X and Y are -1 if the cursor is not shown
+}
+
+func newSnapshot(cols, rows int) *Snapshot {
+	res := &Snapshot{
+		Cols:   cols,
+		Rows:   rows,
+		Cells:  make([][]SnapshotCell, rows),
+		Cursor: CanvasPos{X: -1, Y: -1},
+	}
+	for y := 0; y < rows; y++ {
+		res.Cells[y] = make([]SnapshotCell, cols)
+		for x := 0; x < cols; x++ {
+			res.Cells[y][x].Rune = ' '
+		}
+	}
+	return res
+}
+
+// set stores the cell at (x, y) and marks any columns covered by a wide
+// character. It returns the number of columns used.
+func (s *Snapshot) set(x, y int, c SnapshotCell, width int) int {
+	width = gwutil.Max(width, 1)
+	s.Cells[y][x] = c
+	for i := 1; i < width && x+i < s.Cols; i++ {
+		s.Cells[y][x+i] = SnapshotCell{Style: c.Style}
+	}
+	return width
+}
+
+// SnapshotCanvas captures the canvas as it would be drawn on the terminal.
+func SnapshotCanvas(canvas IDrawCanvas) *Snapshot {
+	res := newSnapshot(canvas.BoxColumns(), canvas.BoxRows())
+	if canvas.CursorEnabled() {
+		res.Cursor = canvas.CursorCoords()
+	}
+	for y := 0; y < res.Rows; y++ {
+		line := canvas.Line(y, LineCopy{}).Line
+		for x := 0; x < len(line) && x < res.Cols; {
+			c := line[x]
+			x += res.set(x, y, SnapshotCell{
+				Rune:      c.Rune(),
+				Combining: string(c.Combining()),
+				Style:     MakeCellStyle(c.ForegroundColor(), c.BackgroundColor(), c.Style()),
+			}, c.Width())
+		}
+	}
+	return res
+}
+
+// SnapshotScreen captures the contents of a screen.
+func SnapshotScreen(screen IScreen) *Snapshot {
+	cols, rows := screen.Size()
+	res := newSnapshot(cols, rows)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; {
+			r, comb, st, width := screen.GetContent(x, y)
+			if r == 0 {
+				r = ' '
+			}
+			x += res.set(x, y, SnapshotCell{Rune: r, Combining: string(comb), Style: st}, width)
+		}
+	}
+	return res
+}
+
+// Snapshot captures the app's screen as last shown on the terminal.
+func (a *App) Snapshot() *Snapshot {
+	return SnapshotScreen(a.screen)
+}
+
+// RenderString renders w, in focus, and returns it as plain text, one line per row. No app or screen is
+// needed, so it suits testing a widget, or showing one in documentation or a log. The widget is rendered
+// as a box widget of cols by rows - or if rows is 0, as a flow widget cols wide, and if cols is 0 too, as
+// a fixed widget. The palette may be nil.
+func RenderString(w IWidget, cols, rows int, palette IPalette) string {
+	return RenderSnapshot(w, cols, rows, palette).Text()
+}
+
+// RenderANSIString is like RenderString, but the text has ANSI escape sequences for the widget's colors
+// and styles, in 256 colors.
+func RenderANSIString(w IWidget, cols, rows int, palette IPalette) string {
+	return RenderSnapshot(w, cols, rows, palette).ANSI()
+}
+
+// RenderSnapshot is like RenderString, but returns the snapshot of the widget, e.g. to compare with a golden
+// file.
+func RenderSnapshot(w IWidget, cols, rows int, palette IPalette) *Snapshot {
+	logger := log.New()
+	logger.Out = ioutil.Discard
+	// An app that is never activated, for the widgets that need one to render
+	app, err := NewApp(AppArgs{
+		View:         w,
+		Palette:      palette,
+		Log:          logger,
+		Screen:       tcell.NewSimulationScreen(""),
+		DontActivate: true,
+		Env: func(name string) string {
+			return ""
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	var size IRenderSize
+	switch {
+	case rows > 0:
+		size = RenderBox{C: cols, R: rows}
+	case cols > 0:
+		size = RenderFlowWith{C: cols}
+	default:
+		size = RenderFixed{}
+	}
+	return SnapshotCanvas(Render(w, size, Focused, app))
+}
+
+// Text returns the snapshot as plain text, one line per row.
+func (s *Snapshot) Text() string {
+	lines := make([]string, s.Rows)
+	for y := 0; y < s.Rows; y++ {
+		var sb strings.Builder
+		for _, c := range s.Cells[y] {
+			if c.Rune != 0 {
+				sb.WriteRune(c.Rune)
+				sb.WriteString(c.Combining)
+			}
+		}
+		lines[y] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *Snapshot) String() string {
+	return s.Text()
+}
+
+func ansiColor(c tcell.Color, fg bool) string {
+	base := 30
+	if !fg {
+		base = 40
+	}
+	switch {
+	case c == tcell.ColorDefault:
+		return fmt.Sprintf("%d", base+9)
+	case c&tcell.ColorIsRGB != 0:
+		r, g, b := c.RGB()
+		return fmt.Sprintf("%d;2;%d;%d;%d", base+8, r, g, b)
+	case c < 8:
+		return fmt.Sprintf("%d", base+int(c))
+	case c < 16:
+		return fmt.Sprintf("%d", base+60+int(c)-8)
+	default:
+		return fmt.Sprintf("%d;5;%d", base+8, int(c))
+	}
+}
+
+func ansiStyle(st tcell.Style) string {
+	fg, bg, attrs := st.Decompose()
+	codes := []string{"0"}
+	if attrs&tcell.AttrBold != 0 {
+		codes = append(codes, "1")
+	}
+	if attrs&tcell.AttrDim != 0 {
+		codes = append(codes, "2")
+	}
+	if attrs&tcell.AttrUnderline != 0 {
+		codes = append(codes, "4")
+	}
+	if attrs&tcell.AttrBlink != 0 {
+		codes = append(codes, "5")
+	}
+	if attrs&tcell.AttrReverse != 0 {
+		codes = append(codes, "7")
+	}
+	if fg != tcell.ColorDefault {
+		codes = append(codes, ansiColor(fg, true))
+	}
+	if bg != tcell.ColorDefault {
+		codes = append(codes, ansiColor(bg, false))
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// ANSI returns the snapshot as text with embedded ANSI SGR escape sequences
+// reproducing each cell's colors and attributes. Styles are reset at the end
+// of each line.
+func (s *Snapshot) ANSI() string {
+	lines := make([]string, s.Rows)
+	for y := 0; y < s.Rows; y++ {
+		var sb strings.Builder
+		first := true
+		var cur tcell.Style
+		for _, c := range s.Cells[y] {
+			if c.Rune == 0 {
+				continue
+			}
+			if first || c.Style != cur {
+				sb.WriteString(ansiStyle(c.Style))
+				cur = c.Style
+				first = false
+			}
+			sb.WriteRune(c.Rune)
+			sb.WriteString(c.Combining)
+		}
+		sb.WriteString("\x1b[0m")
+		lines[y] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// HTMLOptions controls the rendering of a Snapshot as HTML. The default
+// colors are used where a cell specifies the terminal's default color, and
+// must be CSS colors.
+type HTMLOptions struct {
+	DefaultForeground string // Defaults to #ffffff
+	DefaultBackground string // Defaults to #000000
+	Class             string // The class of the enclosing <pre> element; defaults to gowid-snapshot
+}
+
+func htmlColor(c tcell.Color, def string) string {
+	if c == tcell.ColorDefault {
+		return def
+	}
+	if v := c.Hex(); v >= 0 {
+		return fmt.Sprintf("#%06x", v)
+	}
+	return def
+}
+
+func htmlStyle(st tcell.Style, opts HTMLOptions) string {
+	fg, bg, attrs := st.Decompose()
+	fgs := htmlColor(fg, opts.DefaultForeground)
+	bgs := htmlColor(bg, opts.DefaultBackground)
+	if attrs&tcell.AttrReverse != 0 {
+		fgs, bgs = bgs, fgs
+	}
+	res := []string{}
+	if fgs != opts.DefaultForeground {
+		res = append(res, "color:"+fgs)
+	}
+	if bgs != opts.DefaultBackground {
+		res = append(res, "background-color:"+bgs)
+	}
+	if attrs&tcell.AttrBold != 0 {
+		res = append(res, "font-weight:bold")
+	}
+	if attrs&tcell.AttrDim != 0 {
+		res = append(res, "opacity:0.6")
+	}
+	if attrs&tcell.AttrUnderline != 0 {
+		res = append(res, "text-decoration:underline")
+	}
+	return strings.Join(res, ";")
+}
+
+// HTML returns the snapshot as a <pre> element, with runs of identically
+// styled cells wrapped in <span> elements carrying inline CSS styles.
+func (s *Snapshot) HTML(opts ...HTMLOptions) string {
+	var opt HTMLOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.DefaultForeground == "" {
+		opt.DefaultForeground = "#ffffff"
+	}
+	if opt.DefaultBackground == "" {
+		opt.DefaultBackground = "#000000"
+	}
+	if opt.Class == "" {
+		opt.Class = "gowid-snapshot"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<pre class=\"%s\" style=\"color:%s;background-color:%s\">",
+		html.EscapeString(opt.Class), opt.DefaultForeground, opt.DefaultBackground)
+	for y := 0; y < s.Rows; y++ {
+		if y > 0 {
+			sb.WriteString("\n")
+		}
+		var run strings.Builder
+		runStyle := ""
+		flush := func() {
+			if run.Len() > 0 {
+				if runStyle == "" {
+					sb.WriteString(html.EscapeString(run.String()))
+				} else {
+					fmt.Fprintf(&sb, "<span style=\"%s\">%s</span>", runStyle, html.EscapeString(run.String()))
+				}
+				run.Reset()
+			}
+		}
+		for _, c := range s.Cells[y] {
+			if c.Rune == 0 {
+				continue
+			}
+			st := htmlStyle(c.Style, opt)
+			if st != runStyle {
+				flush()
+				runStyle = st
+			}
+			run.WriteRune(c.Rune)
+			run.WriteString(c.Combining)
+		}
+		flush()
+	}
+	sb.WriteString("</pre>")
+	return sb.String()
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,48 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot1(t *testing.T) {
+	c := NewCanvasOfSize(4, 2)
+	red := MakeCell('a', MakeTCellColorExt(tcell.ColorRed), ColorNone, StyleBold)
+	c.SetCellAt(0, 0, red)
+	c.SetCellAt(1, 0, red.WithRune('<'))
+	c.SetCellAt(0, 1, MakeCell('世', ColorNone, ColorNone, StyleNone))
+
+	s := SnapshotCanvas(c)
+	assert.Equal(t, "a<  \n世  ", s.Text())
+	assert.Equal(t, rune(0), s.Cells[1][1].Rune)
+
+	assert.Equal(t, "\x1b[0;1;91ma<\x1b[0m  \x1b[0m\n\x1b[0m世  \x1b[0m", s.ANSI())
+
+	h := s.HTML()
+	assert.Equal(t, "<pre class=\"gowid-snapshot\" style=\"color:#ffffff;background-color:#000000\">"+
+		"<span style=\"color:#ff0000;font-weight:bold\">a&lt;</span>  \n世  </pre>", h)
+}
+
+func TestSnapshot2(t *testing.T) {
+	sc := tcell.NewSimulationScreen("")
+	assert.NoError(t, sc.Init())
+	sc.SetSize(3, 1)
+	sc.SetContent(0, 0, 'x', nil, tcell.StyleDefault.Reverse(true))
+	sc.Show()
+
+	s := SnapshotScreen(sc)
+	assert.Equal(t, "x  ", s.Text())
+	assert.Contains(t, s.ANSI(), "\x1b[0;7mx")
+	assert.Contains(t, s.HTML(), "<span style=\"color:#000000;background-color:#ffffff\">x</span>")
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,149 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package sshapp runs a gowid app for each session of an SSH server, so that many users can share a
+// terminal service, each with an app of their own. It works with any SSH server library - a session
+// need only provide its channel, the terminal's name and size from the pty request, and the window
+// changes that follow. With github.com/gliderlabs/ssh, for example:
+//
+//	ssh.Handle(func(s ssh.Session) {
+//	    pty, windows, ok := s.Pty()
+//	    if !ok {
+//	        io.WriteString(s, "A terminal is needed\n")
+//	        s.Exit(1)
+//	        return
+//	    }
+//	    resize := make(chan sshapp.Window)
+//	    go func() {
+//	        for w := range windows {
+//	            resize <- sshapp.Window{Cols: w.Width, Rows: w.Height}
+//	        }
+//	        close(resize)
+//	    }()
+//	    sshapp.Serve(sshapp.Session{
+//	        ReadWriter: s,
+//	        Term:       pty.Term,
+//	        Window:     sshapp.Window{Cols: pty.Window.Width, Rows: pty.Window.Height},
+//	        Resize:     resize,
+//	    }, newApp)
+//	})
+package sshapp
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/ansi"
+)
+
+//======================================================================
+
+// Window is the size of a session's terminal.
+type Window struct {
+	Cols int
+	Rows int
+}
+
+func (w Window) String() string {
+	return fmt.Sprintf("%dx%d", w.Cols, w.Rows)
+}
+
+// Session is an SSH session with a pty.
+type Session struct {
+	io.ReadWriter               // The session's channel - the user's keys, and the terminal's display
+	Term          string        // The terminal's $TERM, from the pty request
+	Window        Window        // The terminal's size, from the pty request - 80x24 if unknown
+	Resize        <-chan Window // The terminal's new size, whenever it changes; may be nil
+}
+
+// AppFunc returns the arguments for the app run for a session. The Screen is set by Serve, and if no
+// Log is given, the app's log is discarded.
+type AppFunc func(s Session) (gowid.AppArgs, error)
+
+// Options is used to configure Serve.
+type Options struct {
+	Unhandled gowid.IUnhandledInput // Passed to the app's MainLoop - gowid.HandleQuitKeys if nil
+	Colors    int                   // The colors the terminal supports - guessed from its name if 0
+}
+
+// Serve runs an app built by newApp on the session's terminal, returning when the app quits - or when
+// the session's input ends, since the user has gone. The session should then be closed; until it is,
+// a goroutine remains blocked reading from it. If the app panics, the session's terminal is restored, the
+// session is closed if it is an io.Closer, and the panic is returned as a gowid.PanicInfo - other
+// sessions carry on.
+func Serve(s Session, newApp AppFunc, opts ...Options) error {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Unhandled == nil {
+		opt.Unhandled = gowid.UnhandledInputFunc(gowid.HandleQuitKeys)
+	}
+	if opt.Colors == 0 {
+		opt.Colors = ansi.ColorsOf(s.Term)
+	}
+	if s.Window.Cols <= 0 || s.Window.Rows <= 0 {
+		s.Window = Window{Cols: 80, Rows: 24}
+	}
+
+	args, err := newApp(s)
+	if err != nil {
+		return err
+	}
+	screen := ansi.NewScreen(s, s.Window.Cols, s.Window.Rows, ansi.Options{
+		Colors: opt.Colors,
+		Term:   s.Term,
+	})
+	app, err := ansi.NewApp(screen, args)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := s.Read(buf)
+			if n > 0 {
+				screen.Input(buf[:n])
+			}
+			if err != nil {
+				break
+			}
+		}
+		app.Quit()
+	}()
+	if s.Resize != nil {
+		go func() {
+			for {
+				select {
+				case w, ok := <-s.Resize:
+					if !ok {
+						return
+					}
+					if w.Cols > 0 && w.Rows > 0 {
+						screen.Resize(w.Cols, w.Rows)
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	if err := ansi.MainLoop(app, opt.Unhandled); err != nil {
+		if c, ok := s.ReadWriter.(io.Closer); ok {
+			c.Close()
+		}
+		return err
+	}
+	return nil
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
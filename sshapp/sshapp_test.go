@@ -0,0 +1,237 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package sshapp
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+// session stands in for an SSH session - what the user types goes to in, and what the app draws comes
+// from out.
+type session struct {
+	io.Reader
+	io.Writer
+}
+
+// receive reads what the app draws until s arrives, or the output ends.
+func receive(r io.Reader, s string) bool {
+	var all []byte
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		all = append(all, buf[:n]...)
+		if strings.Contains(string(all), s) {
+			return true
+		}
+		if err != nil {
+			return false
+		}
+	}
+}
+
+func TestServe1(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	resize := make(chan Window)
+	res := make(chan error, 1)
+	go func() {
+		res <- Serve(Session{
+			ReadWriter: session{inR, outW},
+			Term:       "xterm-256color",
+			Window:     Window{Cols: 30, Rows: 4},
+			Resize:     resize,
+		}, func(s Session) (gowid.AppArgs, error) {
+			return gowid.AppArgs{View: text.New("hello " + s.Window.String())}, nil
+		})
+		outW.Close()
+	}()
+
+	assert.True(t, receive(outR, "hello 30x4"))
+	resize <- Window{Cols: 20, Rows: 3}
+	// Redrawn in full at the new size
+	assert.True(t, receive(outR, "\x1b[2J"))
+
+	// q quits the app
+	inW.Write([]byte("q"))
+	go io.Copy(ioutil.Discard, outR)
+	select {
+	case err := <-res:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("app did not quit")
+	}
+	inW.Close()
+}
+
+// keys is a widget that passes the keys it is given, other than runes, to a channel.
+type keys struct {
+	*text.Widget
+	ch chan tcell.Key
+}
+
+func (w keys) Selectable() bool {
+	return true
+}
+
+func (w keys) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if kev, ok := ev.(*tcell.EventKey); ok && kev.Key() != tcell.KeyRune {
+		w.ch <- kev.Key()
+		return true
+	}
+	return false
+}
+
+func TestSplitInput1(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	go io.Copy(ioutil.Discard, outR)
+	w := keys{Widget: text.New("keys"), ch: make(chan tcell.Key, 8)}
+	res := make(chan error, 1)
+	go func() {
+		res <- Serve(Session{ReadWriter: session{inR, outW}}, func(s Session) (gowid.AppArgs, error) {
+			return gowid.AppArgs{View: w}, nil
+		})
+	}()
+
+	// One sequence in two reads - each write to the pipe is read on its own
+	inW.Write([]byte("\x1b["))
+	inW.Write([]byte("A"))
+	select {
+	case k := <-w.ch:
+		assert.Equal(t, tcell.KeyUp, k)
+	case <-time.After(10 * time.Second):
+		t.Fatal("no key")
+	}
+
+	inW.Write([]byte("q"))
+	select {
+	case err := <-res:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("app did not quit")
+	}
+	assert.Equal(t, 0, len(w.ch))
+	inW.Close()
+}
+
+func TestServe2(t *testing.T) {
+	// The user goes away
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	go io.Copy(ioutil.Discard, outR)
+	res := make(chan error, 1)
+	go func() {
+		res <- Serve(Session{ReadWriter: session{inR, outW}}, func(s Session) (gowid.AppArgs, error) {
+			assert.Equal(t, Window{Cols: 80, Rows: 24}, s.Window)
+			return gowid.AppArgs{View: text.New("hello")}, nil
+		})
+	}()
+	inW.Close()
+	select {
+	case err := <-res:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("app did not quit")
+	}
+}
+
+// closer is a session that records whether it was closed.
+type closer struct {
+	session
+	closed chan struct{}
+}
+
+func (s closer) Close() error {
+	close(s.closed)
+	return nil
+}
+
+// boom is a widget that panics when given a key.
+type boom struct {
+	*text.Widget
+}
+
+func (w boom) Selectable() bool {
+	return true
+}
+
+func (w boom) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	panic("boom")
+}
+
+func TestPanic1(t *testing.T) {
+	// One session's app panics
+	inR1, inW1 := io.Pipe()
+	outR1, outW1 := io.Pipe()
+	go io.Copy(ioutil.Discard, outR1)
+	s1 := closer{session: session{inR1, outW1}, closed: make(chan struct{})}
+	res1 := make(chan error, 1)
+	go func() {
+		res1 <- Serve(Session{ReadWriter: s1}, func(s Session) (gowid.AppArgs, error) {
+			return gowid.AppArgs{View: boom{text.New("boom")}}, nil
+		})
+	}()
+
+	inR2, inW2 := io.Pipe()
+	outR2, outW2 := io.Pipe()
+	go io.Copy(ioutil.Discard, outR2)
+	w := keys{Widget: text.New("keys"), ch: make(chan tcell.Key, 8)}
+	res2 := make(chan error, 1)
+	go func() {
+		res2 <- Serve(Session{ReadWriter: session{inR2, outW2}}, func(s Session) (gowid.AppArgs, error) {
+			return gowid.AppArgs{View: w}, nil
+		})
+	}()
+
+	inW1.Write([]byte("x"))
+	select {
+	case err := <-res1:
+		p, ok := err.(gowid.PanicInfo)
+		assert.True(t, ok)
+		assert.Equal(t, "boom", p.Value)
+		assert.Equal(t, "handling input", p.During)
+	case <-time.After(10 * time.Second):
+		t.Fatal("app did not end")
+	}
+	select {
+	case <-s1.closed:
+	default:
+		t.Fatal("session was not closed")
+	}
+	inW1.Close()
+
+	// The other session carries on
+	inW2.Write([]byte("\x1b[A"))
+	select {
+	case k := <-w.ch:
+		assert.Equal(t, tcell.KeyUp, k)
+	case <-time.After(10 * time.Second):
+		t.Fatal("no key")
+	}
+	inW2.Write([]byte("q"))
+	select {
+	case err := <-res2:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("app did not quit")
+	}
+	inW2.Close()
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,199 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"strings"
+)
+
+//======================================================================
+
+// StyleState is a set of pseudo-states that a widget can be in when it is rendered - like CSS's :focus
+// or :disabled. A style that implements IStateStyler can provide a variant for each.
+type StyleState uint
+
+const (
+	StateSelected StyleState = 1 << iota // The widget is in the focus path, though it may not have focus
+	StateFocus                           // The widget has keyboard focus
+	StateHover                           // The mouse pointer is over the widget
+	StateActive                          // A mouse button was pressed over the widget and not yet released
+	StateDisabled                        // The widget does not accept input
+)
+
+var stateNames = []string{"selected", "focus", "hover", "active", "disabled"}
+
+// Has returns true if s includes every state in o.
+func (s StyleState) Has(o StyleState) bool {
+	return s&o == o
+}
+
+func (s StyleState) String() string {
+	res := make([]string, 0)
+	for i, name := range stateNames {
+		if s.Has(1 << uint(i)) {
+			res = append(res, name)
+		}
+	}
+	return strings.Join(res, "|")
+}
+
+// StateFromSelector returns the states implied by the Selector passed to a widget's Render function.
+func StateFromSelector(focus Selector) StyleState {
+	var res StyleState
+	if focus.Selected {
+		res |= StateSelected
+	}
+	if focus.Focus {
+		res |= StateFocus
+	}
+	return res
+}
+
+// IStyleStater is implemented by widgets that know of states not conveyed by the Selector passed to
+// Render - e.g. that they are disabled, or being clicked.
+type IStyleStater interface {
+	StyleState() StyleState
+}
+
+// StyleStateOf returns the states of w when rendered with focus. The states reported by w, and by each
+// widget it wraps - following SubWidget() for as long as the widgets are IComposite - are included, so
+// that e.g. a styled widget around a disabled button is rendered as disabled.
+func StyleStateOf(w IWidget, focus Selector) StyleState {
+	res := StateFromSelector(focus)
+	for w != nil {
+		if ws, ok := w.(IStyleStater); ok {
+			res |= ws.StyleState()
+		}
+		wc, ok := w.(IComposite)
+		if !ok {
+			break
+		}
+		w = wc.SubWidget()
+	}
+	return res
+}
+
+//======================================================================
+
+// IStateStyler is an ICellStyler that provides variants of its style for pseudo-states. GetStyle
+// returns the style for no state.
+type IStateStyler interface {
+	ICellStyler
+	ForState(state StyleState) ICellStyler
+}
+
+// StateStyle implements IStateStyler with a style for each state. When a widget is in several states,
+// the variant for each state is layered over Normal, in the order Selected, Focus, Hover, Active then
+// Disabled - so a variant need only specify what differs from Normal, and e.g. the Disabled variant
+// wins over Focus. A nil variant leaves the style unchanged for that state.
+type StateStyle struct {
+	Normal   ICellStyler
+	Selected ICellStyler
+	Focus    ICellStyler
+	Hover    ICellStyler
+	Active   ICellStyler
+	Disabled ICellStyler
+}
+
+var _ IStateStyler = StateStyle{}
+
+func (s StateStyle) ForState(state StyleState) ICellStyler {
+	res := styleLayers{}
+	if s.Normal != nil {
+		res = append(res, s.Normal)
+	}
+	for i, v := range []ICellStyler{s.Selected, s.Focus, s.Hover, s.Active, s.Disabled} {
+		if v != nil && state.Has(1<<uint(i)) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// GetStyle implements ICellStyler.
+func (s StateStyle) GetStyle(prov IRenderContext) (x IColor, y IColor, z StyleAttrs) {
+	return s.ForState(0).GetStyle(prov)
+}
+
+// PaletteStateRef implements IStateStyler by looking up palette entries by name, like PaletteRef. The
+// variant for each state is the entry with the state's name appended after a colon - e.g. for Name
+// "button", the entries "button:focus" and "button:disabled". Variants are layered as they are by
+// StateStyle. Missing variants leave the style unchanged.
+type PaletteStateRef struct {
+	Name string
+}
+
+var _ IStateStyler = PaletteStateRef{}
+
+func MakePaletteStateRef(name string) PaletteStateRef {
+	return PaletteStateRef{name}
+}
+
+func (a PaletteStateRef) ForState(state StyleState) ICellStyler {
+	return paletteStateRef{name: a.Name, state: state}
+}
+
+// GetStyle implements ICellStyler.
+func (a PaletteStateRef) GetStyle(prov IRenderContext) (x IColor, y IColor, z StyleAttrs) {
+	return a.ForState(0).GetStyle(prov)
+}
+
+type paletteStateRef struct {
+	name  string
+	state StyleState
+}
+
+func (a paletteStateRef) GetStyle(prov IRenderContext) (x IColor, y IColor, z StyleAttrs) {
+	res := styleLayers{MakePaletteRef(a.name)}
+	for i, name := range stateNames {
+		if a.state.Has(1 << uint(i)) {
+			res = append(res, MakePaletteRef(a.name+":"+name))
+		}
+	}
+	return res.GetStyle(prov)
+}
+
+// styleLayers is an ICellStyler that layers each styler over the previous ones. Unlike StyleMod, a
+// layer's colors are only used if they express a preference, so a layer that just adds reverse video
+// keeps the colors beneath it.
+type styleLayers []ICellStyler
+
+func (l styleLayers) GetStyle(prov IRenderContext) (x IColor, y IColor, z StyleAttrs) {
+	x, y = NoColor{}, NoColor{}
+	for _, styler := range l {
+		f, b, s := styler.GetStyle(prov)
+		if hasColor(f, prov) {
+			x = f
+		}
+		if hasColor(b, prov) {
+			y = b
+		}
+		z = z.MergeUnder(s)
+	}
+	return
+}
+
+func hasColor(c IColor, prov IRenderContext) bool {
+	if c == nil {
+		return false
+	}
+	tc, ok := c.ToTCellColor(prov.GetColorMode())
+	return ok && tc != ColorNone
+}
+
+// StylerForState returns the variant of styler for state, if it is an IStateStyler; otherwise it returns
+// styler unchanged.
+func StylerForState(styler ICellStyler, state StyleState) ICellStyler {
+	if ss, ok := styler.(IStateStyler); ok {
+		return ss.ForState(state)
+	}
+	return styler
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
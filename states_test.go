@@ -0,0 +1,81 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stateTestContext struct {
+	Palette
+}
+
+func (c stateTestContext) GetColorMode() ColorMode {
+	return Mode256Colors
+}
+
+func TestStyleState1(t *testing.T) {
+	assert.Equal(t, "selected|focus", StateFromSelector(Focused).String())
+	assert.Equal(t, "selected", StateFromSelector(Selected).String())
+	assert.Equal(t, "", StateFromSelector(NotSelected).String())
+	assert.True(t, (StateFocus | StateDisabled).Has(StateDisabled))
+	assert.False(t, StateFocus.Has(StateFocus|StateDisabled))
+}
+
+func TestStateStyle1(t *testing.T) {
+	ctx := stateTestContext{Palette{}}
+	s := StateStyle{
+		Normal:   MakePaletteEntry(ColorWhite, ColorBlue),
+		Focus:    MakeStyledAs(StyleReverse),
+		Disabled: MakeForeground(ColorDarkGray),
+	}
+
+	f, b, st := s.GetStyle(ctx)
+	assert.Equal(t, ColorWhite, f)
+	assert.Equal(t, ColorBlue, b)
+	assert.Equal(t, StyleNone, st)
+
+	// Layers only override what they specify
+	f, b, st = s.ForState(StateSelected | StateFocus).GetStyle(ctx)
+	assert.Equal(t, ColorWhite, f)
+	assert.Equal(t, ColorBlue, b)
+	assert.Equal(t, StyleReverse, st)
+
+	f, b, st = s.ForState(StateFocus | StateDisabled).GetStyle(ctx)
+	assert.Equal(t, ColorDarkGray, f)
+	assert.Equal(t, ColorBlue, b)
+	assert.Equal(t, StyleReverse, st)
+
+	assert.Equal(t, MakeStyledAs(StyleBold), StylerForState(MakeStyledAs(StyleBold), StateFocus))
+}
+
+func TestPaletteStateRef1(t *testing.T) {
+	ctx := stateTestContext{Palette{
+		"button":          MakePaletteEntry(ColorWhite, ColorBlue),
+		"button:focus":    MakeBackground(ColorRed),
+		"button:disabled": MakeStyledPaletteEntry(ColorDarkGray, ColorBlack, StyleDim),
+	}}
+	s := MakePaletteStateRef("button")
+
+	f, b, _ := s.GetStyle(ctx)
+	assert.Equal(t, ColorWhite, f)
+	assert.Equal(t, ColorBlue, b)
+
+	f, b, _ = s.ForState(StateFocus | StateHover).GetStyle(ctx)
+	assert.Equal(t, ColorWhite, f)
+	assert.Equal(t, ColorRed, b)
+
+	f, b, st := s.ForState(StateFocus | StateDisabled).GetStyle(ctx)
+	assert.Equal(t, ColorDarkGray, f)
+	assert.Equal(t, ColorBlack, b)
+	assert.Equal(t, StyleDim, st)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
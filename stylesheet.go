@@ -0,0 +1,416 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+//======================================================================
+
+// SelectorError is returned by Stylesheet.Add if a selector can't be parsed.
+type SelectorError struct {
+	Selector string
+	Reason   string
+}
+
+var _ error = SelectorError{}
+
+func (e SelectorError) Error() string {
+	return fmt.Sprintf("Invalid selector %q: %s", e.Selector, e.Reason)
+}
+
+// IStyleTyped is implemented by widgets that should be matched by a stylesheet under a type name other
+// than the default - see StyleTypeOf.
+type IStyleTyped interface {
+	StyleType() string
+}
+
+// StyleTypeOf returns the name by which a stylesheet's type selectors match w. Unless w implements
+// IStyleTyped, this is the name of w's package if its type is named like Widget - so a *button.Widget is
+// "button" - and otherwise the type's name, in lower case.
+func StyleTypeOf(w IWidget) string {
+	if wt, ok := w.(IStyleTyped); ok {
+		return wt.StyleType()
+	}
+	typ := reflect.TypeOf(w)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	name := typ.Name()
+	if name == "" || strings.HasPrefix(name, "Widget") {
+		pkg := typ.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:]
+	}
+	return strings.ToLower(name)
+}
+
+// StylePathEntry is one widget in the path from the root of the hierarchy to a widget being styled,
+// with the states the widget is in.
+type StylePathEntry struct {
+	Widget IWidget
+	State  StyleState
+}
+
+//======================================================================
+
+// compound is one step of a selector, e.g. "button#ok.primary:focus".
+type compound struct {
+	typ     string // Empty matches any type
+	id      string
+	classes []string
+	states  StyleState
+}
+
+type selector struct {
+	parts       []compound // Outermost ancestor first
+	specificity [3]int     // IDs; classes and states; types
+}
+
+type styleRule struct {
+	sel   selector
+	style ICellStyler
+	order int
+}
+
+func isSelectorChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_'
+}
+
+func parseCompound(sel string, s string) (compound, error) {
+	var res compound
+	ident := func(i int) (string, int) {
+		j := i
+		for j < len(s) && isSelectorChar(rune(s[j])) {
+			j++
+		}
+		return s[i:j], j
+	}
+	i := 0
+	if s[0] == '*' {
+		i = 1
+	} else if isSelectorChar(rune(s[0])) {
+		res.typ, i = ident(0)
+		res.typ = strings.ToLower(res.typ)
+	}
+	for i < len(s) {
+		kind := s[i]
+		name, j := ident(i + 1)
+		if name == "" {
+			return res, SelectorError{Selector: sel, Reason: fmt.Sprintf("expected a name after %q", kind)}
+		}
+		switch kind {
+		case '#':
+			res.id = name
+		case '.':
+			res.classes = append(res.classes, name)
+		case ':':
+			found := false
+			for k, state := range stateNames {
+				if state == name {
+					res.states |= 1 << uint(k)
+					found = true
+				}
+			}
+			if !found {
+				return res, SelectorError{Selector: sel, Reason: fmt.Sprintf("unknown state %q", name)}
+			}
+		default:
+			return res, SelectorError{Selector: sel, Reason: fmt.Sprintf("unexpected %q", kind)}
+		}
+		i = j
+	}
+	return res, nil
+}
+
+func parseSelector(sel string) (selector, error) {
+	var res selector
+	fields := strings.Fields(sel)
+	if len(fields) == 0 {
+		return res, SelectorError{Selector: sel, Reason: "empty selector"}
+	}
+	for _, field := range fields {
+		c, err := parseCompound(sel, field)
+		if err != nil {
+			return res, err
+		}
+		if c.id != "" {
+			res.specificity[0]++
+		}
+		for i := range stateNames {
+			if c.states.Has(1 << uint(i)) {
+				res.specificity[1]++
+			}
+		}
+		res.specificity[1] += len(c.classes)
+		if c.typ != "" {
+			res.specificity[2]++
+		}
+		res.parts = append(res.parts, c)
+	}
+	return res, nil
+}
+
+//======================================================================
+
+// Stylesheet styles widgets by matching them against CSS-like selectors when they are rendered, so an
+// app's look can be changed without changing the code that builds its widgets. A selector is a list of
+// steps separated by spaces, each matching a widget that is a descendant of the widget matched by the
+// previous step. A step is made of
+//
+//   - a type, like "button" (see StyleTypeOf), or "*" for any type, then
+//   - optionally, an ID like "#sidebar" and classes like ".warning" (see SetID and AddClass), then
+//   - optionally, states like ":focus", ":hover" or ":disabled" (see StyleState)
+//
+// so "dialog button:focus" matches a focused button inside a dialog, and "#sidebar list" a list inside
+// the widget with ID "sidebar". Several selectors can share a style by separating them with commas.
+//
+// If several rules match a widget, their styles are layered in order of the selectors' specificity - the
+// number of IDs, then of classes and states, then of types - with later rules winning ties, as in CSS.
+// The result is merged beneath the widget's canvas, so colors set by the widget itself, or by a rule
+// matching a widget inside it, take precedence.
+//
+// The stylesheet is applied by gowid.Render, which container widgets use to render their children. Set
+// it with App.SetStylesheet. Rendering doesn't change the stylesheet, so several apps can share one.
+type Stylesheet struct {
+	rules   []styleRule
+	ids     map[IWidget]string
+	classes map[IWidget][]string
+}
+
+func NewStylesheet() *Stylesheet {
+	return &Stylesheet{
+		ids:     make(map[IWidget]string),
+		classes: make(map[IWidget][]string),
+	}
+}
+
+// Add adds a rule styling the widgets matched by selectors, a comma-separated list. If style is an
+// IStateStyler, the variant for the widget's states is used. A SelectorError is returned, and no rule
+// added, if a selector is invalid.
+func (s *Stylesheet) Add(selectors string, style ICellStyler) error {
+	sels := make([]selector, 0)
+	for _, sel := range strings.Split(selectors, ",") {
+		parsed, err := parseSelector(sel)
+		if err != nil {
+			return err
+		}
+		sels = append(sels, parsed)
+	}
+	for _, sel := range sels {
+		s.rules = append(s.rules, styleRule{sel: sel, style: style, order: len(s.rules)})
+	}
+	return nil
+}
+
+// SetID sets the ID by which w is matched, e.g. "sidebar" for the selector "#sidebar". An empty id
+// removes it. w must be usable as a map key - widgets are usually pointers, so this is rarely a concern;
+// if it isn't, nothing is done.
+func (s *Stylesheet) SetID(w IWidget, id string) {
+	if !isStyleKey(w) {
+		return
+	}
+	if id == "" {
+		delete(s.ids, w)
+	} else {
+		s.ids[w] = id
+	}
+}
+
+// ID returns the ID set for w with SetID, or "" if there is none.
+func (s *Stylesheet) ID(w IWidget) string {
+	if !isStyleKey(w) {
+		return ""
+	}
+	return s.ids[w]
+}
+
+// AddClass adds classes by which w is matched, e.g. "warning" for the selector ".warning". Like SetID and
+// RemoveClass, the change is seen when the app next redraws, and nothing is done if w can't be a map key.
+func (s *Stylesheet) AddClass(w IWidget, classes ...string) {
+	if !isStyleKey(w) {
+		return
+	}
+	for _, class := range classes {
+		if !s.HasClass(w, class) {
+			s.classes[w] = append(s.classes[w], class)
+		}
+	}
+}
+
+// RemoveClass removes classes added to w with AddClass.
+func (s *Stylesheet) RemoveClass(w IWidget, classes ...string) {
+	if !isStyleKey(w) {
+		return
+	}
+	res := make([]string, 0)
+	for _, class := range s.classes[w] {
+		keep := true
+		for _, c := range classes {
+			if c == class {
+				keep = false
+			}
+		}
+		if keep {
+			res = append(res, class)
+		}
+	}
+	if len(res) == 0 {
+		delete(s.classes, w)
+	} else {
+		s.classes[w] = res
+	}
+}
+
+// HasClass returns true if class has been added to w with AddClass.
+func (s *Stylesheet) HasClass(w IWidget, class string) bool {
+	if !isStyleKey(w) {
+		return false
+	}
+	for _, c := range s.classes[w] {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// isStyleKey returns true if w can be looked up in the ID and class maps; a widget that isn't comparable
+// can't have been added to them.
+func isStyleKey(w IWidget) bool {
+	return w != nil && reflect.TypeOf(w).Comparable()
+}
+
+func (s *Stylesheet) matches(c compound, e StylePathEntry) bool {
+	if c.typ != "" && c.typ != StyleTypeOf(e.Widget) {
+		return false
+	}
+	if c.id != "" && c.id != s.ID(e.Widget) {
+		return false
+	}
+	for _, class := range c.classes {
+		if !s.HasClass(e.Widget, class) {
+			return false
+		}
+	}
+	return e.State.Has(c.states)
+}
+
+func (s *Stylesheet) selects(sel selector, path []StylePathEntry) bool {
+	n := len(sel.parts) - 1
+	if !s.matches(sel.parts[n], path[len(path)-1]) {
+		return false
+	}
+	i := len(path) - 2
+	for n--; n >= 0; n-- {
+		for i >= 0 && !s.matches(sel.parts[n], path[i]) {
+			i--
+		}
+		if i < 0 {
+			return false
+		}
+		i--
+	}
+	return true
+}
+
+// StyleFor returns the style for the last widget in path, which lists its ancestors outermost first, or
+// nil if no rule matches it.
+func (s *Stylesheet) StyleFor(path []StylePathEntry) ICellStyler {
+	if len(path) == 0 {
+		return nil
+	}
+	matched := make([]styleRule, 0)
+	for _, rule := range s.rules {
+		if s.selects(rule.sel, path) {
+			matched = append(matched, rule)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i].sel.specificity, matched[j].sel.specificity
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return matched[i].order < matched[j].order
+	})
+	state := path[len(path)-1].State
+	res := make(styleLayers, 0, len(matched))
+	for _, rule := range matched {
+		res = append(res, StylerForState(rule.style, state))
+	}
+	return res
+}
+
+//======================================================================
+
+// IStylesheeted is implemented by an IApp that can style widgets with a Stylesheet, like App.
+type IStylesheeted interface {
+	Stylesheet() *Stylesheet
+}
+
+// StylesheetFor returns the app's Stylesheet if the app supports them and one has been set; otherwise
+// nil is returned.
+func StylesheetFor(app IApp) *Stylesheet {
+	if s, ok := app.(IStylesheeted); ok {
+		return s.Stylesheet()
+	}
+	return nil
+}
+
+// IStylePathed is implemented by an IApp that keeps the path to the widget being rendered, which its
+// stylesheet's selectors are matched against, like App. The path belongs to the app rather than the
+// stylesheet, so that apps rendering on different goroutines can share a stylesheet.
+type IStylePathed interface {
+	stylePath() *[]StylePathEntry
+}
+
+// styleStack is the path to the widget being rendered, outermost first.
+type styleStack []StylePathEntry
+
+func (a *App) stylePath() *[]StylePathEntry {
+	return (*[]StylePathEntry)(&a.styleStack)
+}
+
+// Stylesheet returns the stylesheet set with SetStylesheet, or nil. It lets App conform to
+// IStylesheeted.
+func (a *App) Stylesheet() *Stylesheet {
+	return a.stylesheet
+}
+
+// SetStylesheet styles the app's widgets with s from the next redraw. A nil stylesheet removes styling.
+func (a *App) SetStylesheet(s *Stylesheet) {
+	a.stylesheet = s
+	a.Redraw()
+}
+
+// applyStylesheet merges the style for the widget last in path beneath canvas.
+func applyStylesheet(s *Stylesheet, path []StylePathEntry, canvas ICanvas, app IApp) {
+	styler := s.StyleFor(path)
+	if styler == nil {
+		return
+	}
+	fgCol, bgCol, style := styler.GetStyle(app)
+	mode := app.GetColorMode()
+	fg := IColorToTCell(fgCol, ColorNone, mode)
+	bg := IColorToTCell(bgCol, ColorNone, mode)
+	RangeOverCanvas(canvas, CellRangeFunc(func(c Cell) Cell {
+		return MakeCell(c.codePoint, fg, bg, style).MergeDisplayAttrsUnder(c)
+	}))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
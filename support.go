@@ -95,6 +95,13 @@ type IRenderMaxUnits interface {
 	MaxUnits() int
 }
 
+// Used in widgets laid out side-by-side - the fewest columns a widget can be given. A weighted widget is
+// given at least this many before the rest are divided up; a fixed widget can be shrunk to this many,
+// rather than cut off, if there isn't room for every widget at its natural width - see RenderNatural.
+type IRenderMinUnits interface {
+	MinUnits() int
+}
+
 //======================================================================
 
 // RenderFlowWith is an object passed to a widget's Render function that specifies that
@@ -180,6 +187,29 @@ func (r RenderFixed) ImplementsWidgetDimension() {}
 
 //======================================================================
 
+// RenderNatural is used by widgets within a container. Like RenderFixed, the widget is given its natural
+// width - the width it has when rendered fixed - if there is room. If not, the container can shrink it,
+// down to Min columns, rendering it as a flow widget with the columns it is given, so it must support
+// that. The widget is rendered as a flow widget, at its natural width, even if it isn't shrunk. In a pile,
+// it is the same as RenderFixed.
+type RenderNatural struct {
+	Min int
+}
+
+func (f RenderNatural) String() string {
+	return fmt.Sprintf("natural(min:%d)", f.Min)
+}
+
+func (f RenderNatural) Fixed() {}
+
+func (f RenderNatural) MinUnits() int {
+	return f.Min
+}
+
+func (r RenderNatural) ImplementsWidgetDimension() {}
+
+//======================================================================
+
 type RenderWithWeight struct {
 	W int
 }
@@ -807,6 +837,52 @@ func RenderSize(w IWidget, size IRenderSize, focus Selector, app IApp) IRenderBo
 	return w.RenderSize(size, focus, app)
 }
 
+// Render currently passes control through to the widget's Render method, unless
+// the app has a Stylesheet. Container widgets use it to render their children;
+// it tracks the path from the root of the widget hierarchy to each widget as it
+// is rendered, so that the stylesheet's rules can be matched against it, and
+// merges the matching rules' style beneath the widget's canvas. It also marks
+// the cells of the widget sought by LocateWidget.
+func Render(w IWidget, size IRenderSize, focus Selector, app IApp) ICanvas {
+	sc := checkSizing(app)
+	if sc != nil {
+		sc.path = append(sc.path, unwrapContainer(w))
+		defer func() {
+			sc.path = sc.path[:len(sc.path)-1]
+		}()
+	}
+	res := renderStyled(w, size, focus, app)
+	if sc != nil {
+		sc.check(w, size, focus, res, app)
+	}
+	locateIn(w, size, focus, res, app)
+	return res
+}
+
+func renderStyled(w IWidget, size IRenderSize, focus Selector, app IApp) ICanvas {
+	s := StylesheetFor(app)
+	if s == nil || len(s.rules) == 0 {
+		return w.Render(size, focus, app)
+	}
+	// A ContainerWidget only adds layout information for its parent, so the widget it holds is matched
+	entry := StylePathEntry{Widget: unwrapContainer(w), State: StyleStateOf(w, focus)}
+	sp, ok := app.(IStylePathed)
+	if !ok {
+		// Without a path, the widget is matched without its ancestors
+		res := w.Render(size, focus, app)
+		applyStylesheet(s, []StylePathEntry{entry}, res, app)
+		return res
+	}
+	path := sp.stylePath()
+	*path = append(*path, entry)
+	defer func() {
+		*path = (*path)[:len(*path)-1]
+	}()
+	res := w.Render(size, focus, app)
+	applyStylesheet(s, *path, res, app)
+	return res
+}
+
 // SubWidgetSize currently passes control through to the widget's SubWidgetSize
 // method. Having this function allows for easier instrumentation of the
 // SubWidgetSize path. The function should compute the size that it will itself
@@ -821,8 +897,17 @@ func SubWidgetSize(w ICompositeWidget, size IRenderSize, focus Selector, app IAp
 // widget rendering process. It starts at the root of the widget hierarchy
 // with an IRenderBox size argument equal to the size of the current terminal.
 func RenderRoot(w IWidget, t *App) {
+	drawRoot(renderRoot(w, t), t)
+}
+
+// renderRoot renders the widget hierarchy for the terminal, as RenderRoot does, without drawing it on the
+// screen.
+func renderRoot(w IWidget, t *App) ICanvas {
 	maxX, maxY := t.TerminalSize()
-	canvas := w.Render(RenderBox{C: maxX, R: maxY}, Focused, t)
+	var canvas ICanvas
+	t.profiler.Measure("root", ProfileRender, func() {
+		canvas = Render(w, RenderBox{C: maxX, R: maxY}, Focused, t)
+	})
 
 	// tcell will apply its default style to empty cells. But because gowid's model
 	// is to layer styles, here we explicitly merge each canvas cell on top of a cell
@@ -842,7 +927,42 @@ func RenderRoot(w IWidget, t *App) {
 		}))
 	}
 
+	if t.find != nil {
+		t.find.update(canvas, t)
+	}
+
+	if t.profiler != nil && t.profileOverlay > 0 {
+		drawProfileOverlay(t.profiler, canvas, t.profileOverlay)
+	}
+
+	if t.help != nil {
+		t.help.draw(canvas, w, RenderBox{C: maxX, R: maxY}, t)
+	}
+
+	if t.console != nil {
+		t.console.draw(canvas, t.GetColorMode(), t)
+	}
+
+	if t.keyCast != nil {
+		t.keyCast.draw(canvas, ClockFor(t).Now(), t.GetColorMode(), t)
+	}
+
+	if t.crash != nil {
+		t.crash.screen = canvas
+	}
+
+	t.prepareHyperlinks(canvas)
+	t.prepareRawRegions(canvas)
+
+	return canvas
+}
+
+// drawRoot draws the canvas rendered by renderRoot on the screen.
+func drawRoot(canvas ICanvas, t *App) {
 	Draw(canvas, t, t.GetScreen())
+	if t.cursor.hidden {
+		t.GetScreen().ShowCursor(-1, -1)
+	}
 }
 
 func FindNextSelectableFrom(w ICompositeMultipleDimensions, start int, dir Direction, wrap bool) (int, bool) {
@@ -0,0 +1,289 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// TerminalColorsTimeout is how long the app waits for the terminal to answer a query for its colors -
+// see App.QueryTerminalColors. Terminals that don't understand the query don't answer at all.
+var TerminalColorsTimeout = 500 * time.Millisecond
+
+// TerminalColorsCB is the callback name used when the terminal has reported its colors.
+type TerminalColorsCB struct{}
+
+// TerminalColors are the colors the terminal draws with - its default foreground and background, and
+// the first 16 colors of its palette, which the user's terminal theme usually sets. A color the terminal
+// hasn't reported is nil. Reported colors are RGBColors.
+type TerminalColors struct {
+	Foreground IColor
+	Background IColor
+	ANSI       [16]IColor // Black, red, green, yellow, blue, magenta, cyan, white, then their bright forms
+}
+
+// Known returns true if the terminal has reported any of its colors.
+func (c TerminalColors) Known() bool {
+	if c.Foreground != nil || c.Background != nil {
+		return true
+	}
+	for _, a := range c.ANSI {
+		if a != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ForegroundOr returns the terminal's default foreground color, or def if it isn't known.
+func (c TerminalColors) ForegroundOr(def IColor) IColor {
+	if c.Foreground == nil {
+		return def
+	}
+	return c.Foreground
+}
+
+// BackgroundOr returns the terminal's default background color, or def if it isn't known.
+func (c TerminalColors) BackgroundOr(def IColor) IColor {
+	if c.Background == nil {
+		return def
+	}
+	return c.Background
+}
+
+// ANSIOr returns color i of the terminal's palette, from 0 to 15, or def if it isn't known - e.g.
+// ANSIOr(4, def) for the user's blue, as an accent.
+func (c TerminalColors) ANSIOr(i int, def IColor) IColor {
+	if i < 0 || i >= len(c.ANSI) || c.ANSI[i] == nil {
+		return def
+	}
+	return c.ANSI[i]
+}
+
+// Dark returns true if the terminal's background is dark, and false as the second result if it isn't
+// known - e.g. to choose between a light and a dark theme.
+func (c TerminalColors) Dark() (bool, bool) {
+	rgb, ok := IColorToRGB(c.Background)
+	if !ok {
+		return false, false
+	}
+	// Relative luminance, weighted for the eye's sensitivity to each component
+	lum := 0.2126*float64(rgb.Red) + 0.7152*float64(rgb.Green) + 0.0722*float64(rgb.Blue)
+	return lum < 128, true
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// TerminalColors returns the colors the terminal has reported - see QueryTerminalColors.
+func (a *App) TerminalColors() TerminalColors {
+	return a.termColors.colors
+}
+
+// QueryTerminalColors asks the terminal for its default foreground and background colors, and the first
+// 16 colors of its palette, with the OSC 10, 11 and 4 escape sequences. The answers arrive as input, and
+// are kept from the widgets. Once they have arrived, or after TerminalColorsTimeout, TerminalColors returns
+// them, and callbacks registered with OnTerminalColors are run - e.g. to build a theme that blends with
+// the user's terminal, rather than one with fixed colors. It must be called on the app goroutine, once
+// the screen is active - or use AppArgs.QueryTerminalColors to ask each time it is activated.
+func (a *App) QueryTerminalColors() error {
+	if a.screen == nil {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("\x1b]10;?\x07\x1b]11;?\x07")
+	for i := range a.termColors.colors.ANSI {
+		fmt.Fprintf(&sb, "\x1b]4;%d;?\x07", i)
+	}
+	if err := a.writeToTerminal(sb.String()); err != nil {
+		return err
+	}
+	a.termColors.start(a)
+	return nil
+}
+
+// OnTerminalColors registers a callback run on the app goroutine when the terminal has reported its
+// colors. The callback's widget argument is nil, and the TerminalColors are passed as its only data.
+func (a *App) OnTerminalColors(cb IWidgetChangedCallback) {
+	if a.termColors.callbacks == nil {
+		a.termColors.callbacks = NewCallbacks()
+	}
+	AddWidgetCallback(a.termColors.callbacks, TerminalColorsCB{}, cb)
+}
+
+// RemoveOnTerminalColors removes the callback identified by id, added with OnTerminalColors.
+func (a *App) RemoveOnTerminalColors(id IIdentity) {
+	if a.termColors.callbacks != nil {
+		RemoveWidgetCallback(a.termColors.callbacks, TerminalColorsCB{}, id)
+	}
+}
+
+//======================================================================
+
+// termColorState collects the terminal's answers to a query for its colors. TCell doesn't recognize
+// them, so an answer - ESC ] then e.g. "11;rgb:1e1e/1e1e/1e1e", then BEL or ESC \ - arrives as the key
+// Alt-] followed by the runes of the rest, then ctrl-g or Alt-\.
+type termColorState struct {
+	colors     TerminalColors
+	callbacks  *Callbacks
+	deadline   time.Time     // Answers are looked for until then
+	timer      ITimer        // Reports what has arrived once the deadline passes
+	held       []interface{} // Keys that might be part of an answer, not yet dispatched
+	text       strings.Builder
+	changed    bool // True if colors have arrived since callbacks were last run
+	onActivate bool // If true, the terminal is asked each time the screen is activated
+}
+
+func (t *termColorState) start(a *App) {
+	clock := a.Clock()
+	t.deadline = clock.Now().Add(TerminalColorsTimeout)
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.timer = clock.AfterFunc(TerminalColorsTimeout, func() {
+		a.Run(RunFunction(func(app IApp) {
+			t.finish(a)
+		}))
+	})
+}
+
+// finish stops looking for answers, and runs the callbacks if any colors have arrived.
+func (t *termColorState) finish(a *App) {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	t.deadline = time.Time{}
+	if t.changed {
+		t.changed = false
+		RunWidgetCallbacks(t.callbacks, TerminalColorsCB{}, a, nil, t.colors)
+	}
+}
+
+// flush returns the held keys, which weren't an answer after all.
+func (t *termColorState) flush() []interface{} {
+	res := t.held
+	t.held = nil
+	t.text.Reset()
+	return res
+}
+
+// filter is given each event from tcell, and returns the events that should be dispatched in its place -
+// none if the event might be part of an answer.
+func (t *termColorState) filter(ev interface{}, a *App) []interface{} {
+	if t.deadline.IsZero() {
+		// Keys held when the deadline passed weren't an answer
+		return append(t.flush(), ev)
+	}
+	if !a.Clock().Now().Before(t.deadline) {
+		t.finish(a)
+		return append(t.flush(), ev)
+	}
+	kev, ok := ev.(*tcell.EventKey)
+	if !ok {
+		return append(t.flush(), ev)
+	}
+	if len(t.held) == 0 {
+		if kev.Key() == tcell.KeyRune && kev.Rune() == ']' && kev.Modifiers() == tcell.ModAlt {
+			t.held = append(t.held, ev)
+			return nil
+		}
+		return []interface{}{ev}
+	}
+	switch {
+	case kev.Key() == tcell.KeyBEL,
+		kev.Key() == tcell.KeyRune && kev.Rune() == '\\' && kev.Modifiers() == tcell.ModAlt:
+		if t.answer(t.text.String(), a) {
+			t.flush()
+			return nil
+		}
+	case kev.Key() == tcell.KeyRune && kev.Modifiers() == tcell.ModNone && strings.ContainsRune(answerRunes, kev.Rune()):
+		t.held = append(t.held, ev)
+		t.text.WriteRune(kev.Rune())
+		return nil
+	}
+	// Not an answer - ev might start one
+	return append(t.flush(), t.filter(ev, a)...)
+}
+
+// The runes an answer is made of, after ESC ]
+const answerRunes = "0123456789abcdefABCDEF;:/#rg"
+
+// answer records the color reported by an answer, e.g. "4;1;rgb:cdcd/0000/0000", and returns false if
+// it isn't one.
+func (t *termColorState) answer(s string, a *App) bool {
+	parts := strings.Split(s, ";")
+	var slot *IColor
+	switch {
+	case len(parts) == 2 && parts[0] == "10":
+		slot = &t.colors.Foreground
+	case len(parts) == 2 && parts[0] == "11":
+		slot = &t.colors.Background
+	case len(parts) == 3 && parts[0] == "4":
+		i, err := strconv.Atoi(parts[1])
+		if err != nil || i < 0 || i >= len(t.colors.ANSI) {
+			return false
+		}
+		slot = &t.colors.ANSI[i]
+	default:
+		return false
+	}
+	c, ok := ParseXColor(parts[len(parts)-1])
+	if !ok {
+		return false
+	}
+	*slot = c
+	t.changed = true
+	// The last color asked for - the others have arrived, if the terminal is going to send them
+	if len(parts) == 3 && parts[1] == strconv.Itoa(len(t.colors.ANSI)-1) {
+		t.finish(a)
+	}
+	return true
+}
+
+// ParseXColor parses a color in the forms terminals use to report them - "rgb:RRRR/GGGG/BBBB", with one
+// to four hex digits per component, or "#RRGGBB".
+func ParseXColor(s string) (RGBColor, bool) {
+	if strings.HasPrefix(s, "#") && len(s) == 7 {
+		v, err := strconv.ParseUint(s[1:], 16, 32)
+		if err != nil {
+			return RGBColor{}, false
+		}
+		return RGBColor{int(v >> 16), int(v >> 8 & 0xff), int(v & 0xff)}, true
+	}
+	if !strings.HasPrefix(s, "rgb:") {
+		return RGBColor{}, false
+	}
+	comps := strings.Split(s[len("rgb:"):], "/")
+	if len(comps) != 3 {
+		return RGBColor{}, false
+	}
+	var rgb [3]int
+	for i, comp := range comps {
+		if len(comp) < 1 || len(comp) > 4 {
+			return RGBColor{}, false
+		}
+		v, err := strconv.ParseUint(comp, 16, 16)
+		if err != nil {
+			return RGBColor{}, false
+		}
+		// Scale to 8 bits - e.g. "ffff" and "f" are both 255
+		max := uint64(1)<<(4*uint(len(comp))) - 1
+		rgb[i] = int((v*255 + max/2) / max)
+	}
+	return RGBColor{rgb[0], rgb[1], rgb[2]}, true
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
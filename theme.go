@@ -0,0 +1,221 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"strings"
+)
+
+//======================================================================
+
+// Theme maps semantic role names, like "dialog.button" or "list.focus", to stylers. Widgets are styled
+// with a ThemeRef naming their role, and the role is looked up in the app's current theme each time
+// they are rendered - so switching themes with App.SetTheme, e.g. from dark to light, restyles the whole
+// app without rebuilding any widgets. Roles the theme doesn't define are looked up in Parent, if there
+// is one, so a variant need only list what it changes.
+//
+// Theme implements IPalette. The app's theme takes precedence over its palette, so a theme can also
+// restyle widgets that refer to palette entries with a PaletteRef.
+type Theme struct {
+	Name   string
+	Roles  Palette
+	Parent *Theme
+}
+
+var _ IPalette = (*Theme)(nil)
+
+// NewTheme returns a Theme with the roles provided. parent may be nil.
+func NewTheme(name string, roles Palette, parent *Theme) *Theme {
+	if roles == nil {
+		roles = make(Palette)
+	}
+	return &Theme{
+		Name:   name,
+		Roles:  roles,
+		Parent: parent,
+	}
+}
+
+// Set sets the styler for role. Like changes to the app's palette, the change is seen when the app
+// next redraws.
+func (t *Theme) Set(role string, styler ICellStyler) {
+	t.Roles[role] = styler
+}
+
+// CellStyler returns the styler for role, from this theme or else its ancestors.
+func (t *Theme) CellStyler(role string) (ICellStyler, bool) {
+	for cur := t; cur != nil; cur = cur.Parent {
+		if res, ok := cur.Roles[role]; ok {
+			return res, true
+		}
+	}
+	return nil, false
+}
+
+// RangeOverPalette applies f to each role the theme defines, including those inherited from its
+// ancestors. If f returns false, the loop terminates early.
+func (t *Theme) RangeOverPalette(f func(key string, value ICellStyler) bool) {
+	seen := make(map[string]bool)
+	for cur := t; cur != nil; cur = cur.Parent {
+		for k, v := range cur.Roles {
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			if !f(k, v) {
+				return
+			}
+		}
+	}
+}
+
+//======================================================================
+
+// roleCandidates returns the names under which role is looked up, most specific first. Leading parts of
+// a dotted role are dropped in turn, so "dialog.button" falls back to "button".
+func roleCandidates(role string) []string {
+	res := []string{role}
+	for {
+		i := strings.Index(role, ".")
+		if i == -1 {
+			return res
+		}
+		role = role[i+1:]
+		res = append(res, role)
+	}
+}
+
+// ThemeRef implements IStateStyler by looking up a role in the app's current theme when a widget is
+// rendered. If the role isn't defined, the more general roles made by dropping its leading parts are
+// tried in turn - so "dialog.button" falls back to "button". Like PaletteStateRef, the variant for each
+// state is the role with the state's name appended after a colon, e.g. "dialog.button:focus", and
+// variants are layered over the role's style. Roles are also looked up in the app's palette, if the
+// theme doesn't define them.
+type ThemeRef struct {
+	Role string
+}
+
+var _ IStateStyler = ThemeRef{}
+
+func MakeThemeRef(role string) ThemeRef {
+	return ThemeRef{role}
+}
+
+func (a ThemeRef) ForState(state StyleState) ICellStyler {
+	return themeStateRef{role: a.Role, state: state}
+}
+
+// GetStyle implements ICellStyler.
+func (a ThemeRef) GetStyle(prov IRenderContext) (x IColor, y IColor, z StyleAttrs) {
+	return a.ForState(0).GetStyle(prov)
+}
+
+type themeStateRef struct {
+	role  string
+	state StyleState
+}
+
+func (a themeStateRef) GetStyle(prov IRenderContext) (x IColor, y IColor, z StyleAttrs) {
+	candidates := roleCandidates(a.role)
+	res := styleLayers{themeRoleRef(candidates, "")}
+	for i, name := range stateNames {
+		if a.state.Has(1 << uint(i)) {
+			res = append(res, themeRoleRef(candidates, ":"+name))
+		}
+	}
+	return res.GetStyle(prov)
+}
+
+// themeRoleRef returns the styler for the first of candidates, each with suffix appended, that the
+// render context defines.
+func themeRoleRef(candidates []string, suffix string) ICellStyler {
+	res := make(roleLookup, 0, len(candidates))
+	for _, role := range candidates {
+		res = append(res, role+suffix)
+	}
+	return res
+}
+
+type roleLookup []string
+
+func (a roleLookup) GetStyle(prov IRenderContext) (x IColor, y IColor, z StyleAttrs) {
+	for _, role := range a {
+		if spec, ok := prov.CellStyler(role); ok {
+			return spec.GetStyle(prov)
+		}
+	}
+	return NoColor{}, NoColor{}, StyleAttrs{}
+}
+
+//======================================================================
+
+// IThemed is implemented by an IApp that styles widgets with a Theme, like App.
+type IThemed interface {
+	Theme() *Theme
+}
+
+// ThemeFor returns the app's current Theme if the app supports them and one has been set; otherwise
+// nil is returned.
+func ThemeFor(app IApp) *Theme {
+	if t, ok := app.(IThemed); ok {
+		return t.Theme()
+	}
+	return nil
+}
+
+// Theme returns the theme set with SetTheme, or nil. It lets App conform to IThemed.
+func (a *App) Theme() *Theme {
+	return a.theme
+}
+
+// SetTheme switches the app to theme t, restyling its widgets from the next redraw. A nil theme leaves
+// just the palette.
+func (a *App) SetTheme(t *Theme) {
+	a.theme = t
+	a.Redraw()
+}
+
+// CellStyler looks up name in the app's overrides for its color mode - see ColorDowngradeOptions - then
+// in its theme, then in its palette. It lets App conform to IPalette.
+func (a *App) CellStyler(name string) (ICellStyler, bool) {
+	if res, ok := a.paletteOverride(name); ok {
+		return res, true
+	}
+	if a.theme != nil {
+		if res, ok := a.theme.CellStyler(name); ok {
+			return res, true
+		}
+	}
+	return a.IPalette.CellStyler(name)
+}
+
+// RangeOverPalette applies f to each of the theme's roles, then to the palette's entries that the theme
+// doesn't override. If f returns false, the loop terminates early.
+func (a *App) RangeOverPalette(f func(key string, value ICellStyler) bool) {
+	done := false
+	if a.theme != nil {
+		a.theme.RangeOverPalette(func(k string, v ICellStyler) bool {
+			done = !f(k, v)
+			return !done
+		})
+	}
+	if done {
+		return
+	}
+	a.IPalette.RangeOverPalette(func(k string, v ICellStyler) bool {
+		if a.theme != nil {
+			if _, ok := a.theme.CellStyler(k); ok {
+				return true
+			}
+		}
+		return f(k, v)
+	})
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,111 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//======================================================================
+
+// Timer is a function scheduled to run on the app goroutine with After or Every. Stop cancels it.
+type Timer struct {
+	mu      sync.Mutex
+	timer   ITimer // Counting down to the next run
+	stopped bool
+}
+
+// Stop cancels the timer, so its function won't run again - even if it is already due, and waiting for
+// the app goroutine. It returns false if the timer had already been stopped, or was made with After and
+// has run. It may be called from any goroutine, including from the timer's own function.
+func (t *Timer) Stop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return false
+	}
+	t.stopped = true
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	return true
+}
+
+// Stopped returns true once the timer has been stopped, or was made with After and has run.
+func (t *Timer) Stopped() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stopped
+}
+
+// schedule runs f on the app goroutine after d, unless the timer is stopped first, then calls next -
+// also on the app goroutine.
+func (t *Timer) schedule(app IApp, d time.Duration, f func(app IApp), next func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return
+	}
+	t.timer = ClockFor(app).AfterFunc(d, func() {
+		err := app.Run(RunFunction(func(app IApp) {
+			if t.Stopped() {
+				return
+			}
+			f(app)
+			next()
+		}))
+		if err != nil {
+			// The app is closing
+			t.Stop()
+		}
+	})
+}
+
+// After runs f on the app goroutine once d has passed, by the app's clock, then redraws the app. Use the
+// Timer returned to cancel it.
+func After(app IApp, d time.Duration, f func(app IApp)) *Timer {
+	res := &Timer{}
+	res.schedule(app, d, f, func() {
+		res.Stop()
+	})
+	return res
+}
+
+// Every runs f on the app goroutine each time d passes, by the app's clock, redrawing the app after each
+// run, until the Timer returned is stopped or the app quits. The next run is scheduled once f returns,
+// so runs don't pile up behind a busy app. Like time.NewTicker, Every panics if d is not positive - the
+// app would otherwise do nothing but run f.
+func Every(app IApp, d time.Duration, f func(app IApp)) *Timer {
+	if d <= 0 {
+		panic(errors.New("non-positive interval for gowid.Every"))
+	}
+	res := &Timer{}
+	var next func()
+	next = func() {
+		res.schedule(app, d, f, next)
+	}
+	next()
+	return res
+}
+
+// After runs f on the app goroutine once d has passed - see After.
+func (a *App) After(d time.Duration, f func(app IApp)) *Timer {
+	return After(a, d, f)
+}
+
+// Every runs f on the app goroutine each time d passes - see Every.
+func (a *App) Every(d time.Duration, f func(app IApp)) *Timer {
+	return Every(a, d, f)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,252 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+//======================================================================
+
+// TimePoint is a value in a TimeSeries.
+type TimePoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// DownsampleMethod is how a TimeSeries reduces its points to fit the space a chart has to draw them in.
+type DownsampleMethod int
+
+const (
+	// DownsampleLTTB picks the points that best preserve the shape of the series - the
+	// largest-triangle-three-buckets algorithm. It suits line charts.
+	DownsampleLTTB DownsampleMethod = iota
+	// DownsampleMinMax keeps the smallest and largest value in each bucket, in time order, so spikes are
+	// never lost. It returns up to twice as many points as requested.
+	DownsampleMinMax
+)
+
+func (m DownsampleMethod) String() string {
+	switch m {
+	case DownsampleLTTB:
+		return "lttb"
+	case DownsampleMinMax:
+		return "minmax"
+	default:
+		return fmt.Sprintf("downsample(%d)", int(m))
+	}
+}
+
+// TimeSeries is a fixed-size buffer of timestamped values, for charts that stream data. Once it is full,
+// adding a point discards the oldest. Points should be added in time order. A TimeSeries is safe to use
+// from several goroutines - e.g. filled from one and rendered on the app's.
+type TimeSeries struct {
+	mu     sync.Mutex
+	points []TimePoint
+	start  int // The index of the oldest point
+	size   int // The number of points held
+}
+
+// NewTimeSeries returns a TimeSeries that holds up to capacity points.
+func NewTimeSeries(capacity int) *TimeSeries {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &TimeSeries{
+		points: make([]TimePoint, capacity),
+	}
+}
+
+func (s *TimeSeries) String() string {
+	return fmt.Sprintf("timeseries[%d/%d]", s.Len(), s.Capacity())
+}
+
+// Add appends a value at time t, discarding the oldest point if the series is full.
+func (s *TimeSeries) Add(t time.Time, v float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size < len(s.points) {
+		s.points[(s.start+s.size)%len(s.points)] = TimePoint{Time: t, Value: v}
+		s.size++
+		return
+	}
+	s.points[s.start] = TimePoint{Time: t, Value: v}
+	s.start = (s.start + 1) % len(s.points)
+}
+
+// AddNow appends a value at the current time according to the app's clock.
+func (s *TimeSeries) AddNow(v float64, app IApp) {
+	s.Add(ClockFor(app).Now(), v)
+}
+
+func (s *TimeSeries) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+func (s *TimeSeries) Capacity() int {
+	return len(s.points)
+}
+
+func (s *TimeSeries) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start, s.size = 0, 0
+}
+
+// Points returns a copy of the points held, oldest first.
+func (s *TimeSeries) Points() []TimePoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pointsLocked()
+}
+
+func (s *TimeSeries) pointsLocked() []TimePoint {
+	res := make([]TimePoint, s.size)
+	for i := 0; i < s.size; i++ {
+		res[i] = s.points[(s.start+i)%len(s.points)]
+	}
+	return res
+}
+
+// Window returns the points from time from up to, but not including, time to, oldest first. A zero from
+// or to leaves that end of the window open.
+func (s *TimeSeries) Window(from, to time.Time) []TimePoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := s.pointsLocked()
+	if !from.IsZero() {
+		i := sort.Search(len(res), func(i int) bool {
+			return !res[i].Time.Before(from)
+		})
+		res = res[i:]
+	}
+	if !to.IsZero() {
+		i := sort.Search(len(res), func(i int) bool {
+			return !res[i].Time.Before(to)
+		})
+		res = res[:i]
+	}
+	return res
+}
+
+// Downsampled returns the points in the window from time from to time to, reduced to about n points with
+// method - n is usually the number of columns a chart has to draw in. A window with n points or fewer is
+// returned as it is.
+func (s *TimeSeries) Downsampled(from, to time.Time, n int, method DownsampleMethod) []TimePoint {
+	return Downsample(s.Window(from, to), n, method)
+}
+
+//======================================================================
+
+// Downsample reduces points, in time order, to about n points with method - see DownsampleMethod.
+func Downsample(points []TimePoint, n int, method DownsampleMethod) []TimePoint {
+	if n < 1 || len(points) <= n {
+		return points
+	}
+	switch method {
+	case DownsampleMinMax:
+		return downsampleMinMax(points, n)
+	default:
+		return downsampleLTTB(points, n)
+	}
+}
+
+// TimePointValues returns the values of points, e.g. for a chart that doesn't use their times.
+func TimePointValues(points []TimePoint) []float64 {
+	res := make([]float64, len(points))
+	for i, p := range points {
+		res[i] = p.Value
+	}
+	return res
+}
+
+// downsampleMinMax splits points into n buckets and keeps the smallest and largest value of each.
+func downsampleMinMax(points []TimePoint, n int) []TimePoint {
+	res := make([]TimePoint, 0, n*2)
+	for b := 0; b < n; b++ {
+		bucket := points[b*len(points)/n : (b+1)*len(points)/n]
+		if len(bucket) == 0 {
+			continue
+		}
+		lo, hi := 0, 0
+		for i, p := range bucket {
+			if p.Value < bucket[lo].Value {
+				lo = i
+			}
+			if p.Value > bucket[hi].Value {
+				hi = i
+			}
+		}
+		switch {
+		case lo == hi:
+			res = append(res, bucket[lo])
+		case lo < hi:
+			res = append(res, bucket[lo], bucket[hi])
+		default:
+			res = append(res, bucket[hi], bucket[lo])
+		}
+	}
+	return res
+}
+
+// downsampleLTTB implements largest-triangle-three-buckets: the first and last points are kept, and from
+// each of n-2 buckets in between, the point that makes the largest triangle with the point kept from the
+// previous bucket and the average of the next.
+func downsampleLTTB(points []TimePoint, n int) []TimePoint {
+	if n < 3 {
+		return []TimePoint{points[0], points[len(points)-1]}[:n]
+	}
+	x := func(p TimePoint) float64 {
+		return float64(p.Time.Sub(points[0].Time))
+	}
+	res := make([]TimePoint, 0, n)
+	res = append(res, points[0])
+	size := float64(len(points)-2) / float64(n-2)
+	prev := points[0]
+	for b := 0; b < n-2; b++ {
+		start := int(float64(b)*size) + 1
+		end := int(float64(b+1)*size) + 1
+
+		// The average of the next bucket, or the last point
+		nextEnd := int(float64(b+2)*size) + 1
+		if nextEnd > len(points) {
+			nextEnd = len(points)
+		}
+		var avgX, avgY float64
+		next := points[end:nextEnd]
+		if b == n-3 || len(next) == 0 {
+			next = points[len(points)-1:]
+		}
+		for _, p := range next {
+			avgX += x(p)
+			avgY += p.Value
+		}
+		avgX /= float64(len(next))
+		avgY /= float64(len(next))
+
+		best, bestArea := start, -1.0
+		for i := start; i < end; i++ {
+			area := math.Abs((x(prev)-avgX)*(points[i].Value-prev.Value) - (x(prev)-x(points[i]))*(avgY-prev.Value))
+			if area > bestArea {
+				best, bestArea = i, area
+			}
+		}
+		prev = points[best]
+		res = append(res, prev)
+	}
+	return append(res, points[len(points)-1])
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
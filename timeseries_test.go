@@ -0,0 +1,62 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeSeries1(t *testing.T) {
+	start := time.Unix(1000, 0)
+	at := func(i int) time.Time {
+		return start.Add(time.Duration(i) * time.Second)
+	}
+	s := NewTimeSeries(4)
+	for i := 0; i < 6; i++ {
+		s.Add(at(i), float64(i))
+	}
+	assert.Equal(t, 4, s.Len())
+	assert.Equal(t, []float64{2, 3, 4, 5}, TimePointValues(s.Points()))
+	assert.Equal(t, []float64{3, 4}, TimePointValues(s.Window(at(3), at(5))))
+	assert.Equal(t, []float64{4, 5}, TimePointValues(s.Window(at(4), time.Time{})))
+	assert.Equal(t, []float64{2}, TimePointValues(s.Window(time.Time{}, at(3))))
+	assert.Equal(t, 0, len(s.Window(at(10), time.Time{})))
+	s.Clear()
+	assert.Equal(t, 0, len(s.Points()))
+}
+
+func TestDownsample1(t *testing.T) {
+	start := time.Unix(1000, 0)
+	points := make([]TimePoint, 0)
+	for i := 0; i < 100; i++ {
+		v := float64(i % 10)
+		if i == 42 {
+			v = 100
+		}
+		points = append(points, TimePoint{Time: start.Add(time.Duration(i) * time.Second), Value: v})
+	}
+
+	assert.Equal(t, points[:5], Downsample(points[:5], 10, DownsampleLTTB))
+
+	lttb := Downsample(points, 10, DownsampleLTTB)
+	assert.Equal(t, 10, len(lttb))
+	assert.Equal(t, points[0], lttb[0])
+	assert.Equal(t, points[99], lttb[9])
+	assert.Contains(t, lttb, points[42])
+	for i := 1; i < len(lttb); i++ {
+		assert.True(t, lttb[i-1].Time.Before(lttb[i].Time))
+	}
+
+	mm := Downsample(points, 10, DownsampleMinMax)
+	assert.Equal(t, 20, len(mm))
+	assert.Equal(t, []float64{0, 9, 0, 9, 0, 9, 0, 9, 0, 100}, TimePointValues(mm[:10]))
+	for i := 1; i < len(mm); i++ {
+		assert.True(t, mm[i-1].Time.Before(mm[i].Time))
+	}
+
+	assert.Equal(t, 2, len(Downsample(points, 2, DownsampleLTTB)))
+}
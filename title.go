@@ -0,0 +1,114 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+//======================================================================
+
+// ITitleController is implemented by an IApp that can set the title of the terminal's window or tab, and
+// its icon name, like App.
+type ITitleController interface {
+	Title() string
+	SetTitle(title string)
+	IconName() string
+	SetIconName(name string)
+}
+
+var _ ITitleController = (*App)(nil)
+
+// titleState tracks the title and icon name the app has set, and whether the terminal has been asked to
+// save the ones it had before.
+type titleState struct {
+	title    string
+	icon     string
+	hasTitle bool // True if SetTitle has been called
+	hasIcon  bool // True if SetIconName has been called; otherwise the icon name follows the title
+	pushed   bool // True if the terminal's own title and icon name have been saved, to be restored later
+}
+
+// Title returns the title the app has set for the terminal - see SetTitle.
+func (a *App) Title() string {
+	return a.title.title
+}
+
+// SetTitle sets the title of the terminal's window or tab, e.g. to show the file being edited or the
+// progress of a long job. Unless SetIconName has been called, the icon name is set too. The title the
+// terminal had before is saved the first time, and restored when the app closes or gives up the terminal
+// - for terminals that support xterm's title stack. Control characters in title are dropped.
+func (a *App) SetTitle(title string) {
+	a.title.title = title
+	a.title.hasTitle = true
+	a.writeTitle()
+}
+
+// IconName returns the icon name the app has set for the terminal - see SetIconName. Until then, it is
+// the title.
+func (a *App) IconName() string {
+	if a.title.hasIcon {
+		return a.title.icon
+	}
+	return a.title.title
+}
+
+// SetIconName sets the terminal's icon name - the label shown for the window when it is minimized, on
+// terminals that have one. Once it is set, SetTitle no longer changes it.
+func (a *App) SetIconName(name string) {
+	a.title.icon = name
+	a.title.hasIcon = true
+	a.writeTitle()
+}
+
+// titleSequence returns the OSC sequence that sets the text of the terminal property ps - 0 for the icon
+// name and title together, 1 for the icon name, 2 for the title.
+func titleSequence(ps int, text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text)
+	return fmt.Sprintf("\x1b]%d;%s\x07", ps, text)
+}
+
+// writeTitle sends the app's title and icon name to the terminal, first saving the terminal's own if they
+// haven't been saved yet.
+func (a *App) writeTitle() {
+	if !(a.title.hasTitle || a.title.hasIcon) || a.screen == nil {
+		return
+	}
+	if !a.title.pushed {
+		if a.writeToTerminal("\x1b[22;0t") != nil {
+			return
+		}
+		a.title.pushed = true
+	}
+	switch {
+	case !a.title.hasIcon:
+		_ = a.writeToTerminal(titleSequence(0, a.title.title))
+	case !a.title.hasTitle:
+		_ = a.writeToTerminal(titleSequence(1, a.title.icon))
+	default:
+		_ = a.writeToTerminal(titleSequence(2, a.title.title) + titleSequence(1, a.title.icon))
+	}
+}
+
+// restoreTitle asks the terminal to go back to the title and icon name it had before the app set them.
+func (a *App) restoreTitle() {
+	if a.title.pushed {
+		_ = a.writeToTerminal("\x1b[23;0t")
+		a.title.pushed = false
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
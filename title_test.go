@@ -0,0 +1,30 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package gowid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTitle1(t *testing.T) {
+	assert.Equal(t, "\x1b]2;build: 3/10\x07", titleSequence(2, "build: 3/10"))
+	assert.Equal(t, "\x1b]0;a]0;b\x07", titleSequence(0, "a\x1b]0;\x07b"))
+
+	a := &App{}
+	a.SetTitle("main.go")
+	assert.Equal(t, "main.go", a.Title())
+	assert.Equal(t, "main.go", a.IconName())
+	a.SetIconName("ed")
+	a.SetTitle("other.go")
+	assert.Equal(t, "ed", a.IconName())
+	assert.False(t, a.title.pushed) // No screen to save the title on
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
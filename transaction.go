@@ -0,0 +1,100 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+//======================================================================
+
+// ITransactional is implemented by an IApp that can change many widgets as one - see App.Transaction.
+type ITransactional interface {
+	Transaction(f func() error) error
+	OnRollback(undo func(app IApp)) bool
+}
+
+// OnRollback registers undo to be run if the app's transaction in progress fails - see App.Transaction.
+// It returns false if the app doesn't support transactions, or none is in progress. Code that changes
+// state in a transaction should call it with a function that puts the old state back, e.g.
+//
+//	old := w.Text()
+//	w.SetText(val, app)
+//	gowid.OnRollback(app, func(app gowid.IApp) { w.SetText(old, app) })
+func OnRollback(app IApp, undo func(app IApp)) bool {
+	if t, ok := app.(ITransactional); ok {
+		return t.OnRollback(undo)
+	}
+	return false
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// Transaction calls f, and changes the screen only once it returns - so when many widgets must change
+// together, the user never sees some changed and others not. Redraws asked for while f runs are put off
+// until it returns, then done once. If f returns an error, or panics, the functions registered with
+// OnRollback while it ran are called, latest first, to put the widgets back as they were, and the error
+// is returned. Transactions can be nested - if an outer transaction fails, the changes of the inner
+// ones that succeeded are rolled back too. It must be called on the app goroutine.
+func (a *App) Transaction(f func() error) (err error) {
+	a.tx.undo = append(a.tx.undo, nil)
+	depth := len(a.tx.undo)
+	ok := false
+	defer func() {
+		undo := a.tx.undo[depth-1]
+		a.tx.undo = a.tx.undo[:depth-1]
+		if ok {
+			if depth > 1 {
+				// The outer transaction may yet fail
+				a.tx.undo[depth-2] = append(a.tx.undo[depth-2], undo...)
+			}
+		} else {
+			for i := len(undo) - 1; i >= 0; i-- {
+				undo[i](a)
+			}
+		}
+		if depth == 1 && a.tx.dirty {
+			a.tx.dirty = false
+			a.redraw()
+		}
+	}()
+	err = f()
+	ok = err == nil
+	return err
+}
+
+// InTransaction returns true if a transaction is in progress - see Transaction.
+func (a *App) InTransaction() bool {
+	return len(a.tx.undo) > 0
+}
+
+// OnRollback registers undo to be run if the transaction in progress fails. It returns false, and does
+// nothing, if no transaction is in progress.
+func (a *App) OnRollback(undo func(app IApp)) bool {
+	if len(a.tx.undo) == 0 {
+		return false
+	}
+	a.tx.undo[len(a.tx.undo)-1] = append(a.tx.undo[len(a.tx.undo)-1], undo)
+	return true
+}
+
+//======================================================================
+
+// transactionState tracks the transactions in progress.
+type transactionState struct {
+	undo  [][]func(IApp) // The rollback functions of each transaction in progress, outermost first
+	dirty bool           // True if a redraw was put off until the outermost transaction ends
+}
+
+// deferRedraw returns true, and remembers a redraw is due, if a transaction is in progress.
+func (t *transactionState) deferRedraw() bool {
+	if len(t.undo) == 0 {
+		return false
+	}
+	t.dirty = true
+	return true
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,282 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/gcla/gowid/gwutil"
+)
+
+//======================================================================
+
+// Easing maps the fraction of an animation's duration that has passed, from 0 to 1, to the fraction of
+// the change that should have been made. It should return 0 for 0 and 1 for 1, but may go beyond 1 in
+// between, e.g. to overshoot.
+type Easing func(t float64) float64
+
+// Linear changes the value at a constant rate.
+func Linear(t float64) float64 {
+	return t
+}
+
+// EaseInOut starts slowly, speeds up, then slows down at the end - a cubic curve.
+func EaseInOut(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	return 1 - math.Pow(-2*t+2, 3)/2
+}
+
+// Spring returns an Easing that overshoots the end value and oscillates about it, like a damped spring.
+// oscillations is the number of swings over the duration; the larger damping is, the sooner they die
+// away.
+func Spring(oscillations float64, damping float64) Easing {
+	return func(t float64) float64 {
+		if t >= 1 {
+			return 1
+		}
+		return 1 - math.Exp(-damping*t)*math.Cos(2*math.Pi*oscillations*t)
+	}
+}
+
+// EaseSpring is a Spring that overshoots once, noticeably, then settles.
+var EaseSpring = Spring(1.5, 6)
+
+// DefaultTweenFrameInterval is the time between the updates of a Tween, unless its options say
+// otherwise - about 30 updates a second.
+var DefaultTweenFrameInterval = time.Second / 30
+
+//======================================================================
+
+// TweenOptions is used to configure a Tween.
+type TweenOptions struct {
+	From, To      float64
+	Duration      time.Duration
+	Easing        Easing                    // Defaults to EaseInOut
+	FrameInterval time.Duration             // Defaults to DefaultTweenFrameInterval
+	OnUpdate      func(v float64, app IApp) // Called with each new value, on the app goroutine
+	OnDone        func(app IApp)            // Called when the tween reaches To, after the last OnUpdate
+}
+
+// Tween animates a number from one value to another over a duration. Each frame, the number is passed
+// to OnUpdate, which should apply it to a widget - e.g. set a dimension, or pick a color with
+// InterpolateColor. Frames are timed with the app's clock (see ClockFor), and run on the app goroutine
+// with app.Run(), so the app is redrawn after each. Tests can use a FakeClock to step through the
+// animation.
+type Tween struct {
+	opts    TweenOptions
+	start   time.Time
+	timer   *Timer
+	running bool
+	value   float64
+	eased   float64
+}
+
+func NewTween(opts TweenOptions) *Tween {
+	if opts.Easing == nil {
+		opts.Easing = EaseInOut
+	}
+	if opts.FrameInterval <= 0 {
+		opts.FrameInterval = DefaultTweenFrameInterval
+	}
+	return &Tween{
+		opts:  opts,
+		value: opts.From,
+	}
+}
+
+// Start begins the animation from the From value, restarting it if it's running. It must be called on
+// the app goroutine. If motion should be reduced in app - see ReduceMotion - the tween finishes at once.
+func (t *Tween) Start(app IApp) {
+	t.Stop()
+	if ReduceMotion(app) {
+		t.Finish(app)
+		return
+	}
+	clock := ClockFor(app)
+	t.start = clock.Now()
+	t.running = true
+	t.step(clock, app)
+	if t.running {
+		t.timer = Every(app, t.opts.FrameInterval, func(app IApp) {
+			t.step(clock, app)
+		})
+	}
+}
+
+// Stop halts the animation at its current value. OnDone is not called.
+func (t *Tween) Stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	t.running = false
+}
+
+// Finish jumps to the end of the animation, calling OnUpdate with the To value, then OnDone.
+func (t *Tween) Finish(app IApp) {
+	t.Stop()
+	t.update(1, app)
+	if t.opts.OnDone != nil {
+		t.opts.OnDone(app)
+	}
+}
+
+// Running returns true if the animation has started and has not yet finished or been stopped.
+func (t *Tween) Running() bool {
+	return t.running
+}
+
+// Value returns the most recent value of the animation.
+func (t *Tween) Value() float64 {
+	return t.value
+}
+
+// Progress returns the eased fraction of the change made so far - 0 at the start and 1 at the end.
+// Use it to animate something that isn't a number, e.g. with InterpolateColor.
+func (t *Tween) Progress() float64 {
+	return t.eased
+}
+
+func (t *Tween) update(eased float64, app IApp) {
+	t.eased = eased
+	t.value = t.opts.From + (t.opts.To-t.opts.From)*eased
+	if t.opts.OnUpdate != nil {
+		t.opts.OnUpdate(t.value, app)
+	}
+}
+
+func (t *Tween) step(clock IClock, app IApp) {
+	frac := 1.0
+	if t.opts.Duration > 0 {
+		frac = float64(clock.Since(t.start)) / float64(t.opts.Duration)
+	}
+	if frac >= 1 {
+		t.Finish(app)
+		return
+	}
+	t.update(t.opts.Easing(frac), app)
+}
+
+//======================================================================
+
+// AnimateOptions configures the animations started by AnimateFloat, AnimateInt, AnimateColor and
+// AnimateDimension. The fields mean what they do in TweenOptions.
+type AnimateOptions struct {
+	Duration      time.Duration
+	Easing        Easing         // Defaults to EaseInOut
+	FrameInterval time.Duration  // Defaults to DefaultTweenFrameInterval
+	OnDone        func(app IApp) // Called when the animation reaches its end value
+}
+
+func (o AnimateOptions) start(from, to float64, update func(t *Tween, app IApp), app IApp) *Tween {
+	var res *Tween
+	res = NewTween(TweenOptions{
+		From:          from,
+		To:            to,
+		Duration:      o.Duration,
+		Easing:        o.Easing,
+		FrameInterval: o.FrameInterval,
+		OnUpdate: func(v float64, app IApp) {
+			update(res, app)
+		},
+		OnDone: o.OnDone,
+	})
+	res.Start(app)
+	return res
+}
+
+// AnimateFloat starts a Tween that passes set a number moving from one value to another over the
+// duration. It must be called on the app goroutine. Use the Tween returned to stop the animation, or
+// jump to its end.
+func AnimateFloat(app IApp, from, to float64, set func(v float64, app IApp), opts AnimateOptions) *Tween {
+	return opts.start(from, to, func(t *Tween, app IApp) {
+		set(t.Value(), app)
+	}, app)
+}
+
+// AnimateInt is like AnimateFloat, but the value is rounded to an int, and set is only called when it
+// changes - e.g. to fill a progress bar smoothly.
+func AnimateInt(app IApp, from, to int, set func(v int, app IApp), opts AnimateOptions) *Tween {
+	last, first := 0, true
+	return opts.start(float64(from), float64(to), func(t *Tween, app IApp) {
+		v := int(gwutil.Round(t.Value()))
+		if first || v != last {
+			first, last = false, v
+			set(v, app)
+		}
+	}, app)
+}
+
+// AnimateColor is like AnimateFloat, but passes set a color blended from one to the other in the color
+// space given - see InterpolateColor.
+func AnimateColor(app IApp, from, to IColor, space ColorSpace, set func(c IColor, app IApp), opts AnimateOptions) *Tween {
+	return opts.start(0, 1, func(t *Tween, app IApp) {
+		set(InterpolateColor(from, to, t.Progress(), space), app)
+	}, app)
+}
+
+// AnimateDimension is like AnimateFloat, but passes set a dimension moving from one to the other - see
+// InterpolateDimension. The setters of widgets like overlay, padding and dialog can be passed as is, to
+// slide a panel in, or grow a dialog as it opens:
+//
+//    gowid.AnimateDimension(app, gowid.RenderWithUnits{U: 0}, gowid.RenderWithUnits{U: 30},
+//        panel.SetWidth, gowid.AnimateOptions{Duration: 200 * time.Millisecond})
+//
+func AnimateDimension(app IApp, from, to IWidgetDimension, set func(d IWidgetDimension, app IApp), opts AnimateOptions) *Tween {
+	var last IWidgetDimension
+	return opts.start(0, 1, func(t *Tween, app IApp) {
+		// A dimension of the caller's own may not be comparable with ==
+		if d := InterpolateDimension(from, to, t.Progress()); last == nil || !reflect.DeepEqual(d, last) {
+			last = d
+			set(d, app)
+		}
+	}, app)
+}
+
+// InterpolateDimension returns the dimension a fraction t of the way from one dimension to another.
+// Dimensions of the same kind - units, weight, ratio, box, or flow with a number of columns - are
+// blended, rounding to whole cells. Otherwise, there's nothing in between, so from is returned until t
+// reaches 0.5, then to. Sizes don't go below zero, even if t does.
+func InterpolateDimension(from, to IWidgetDimension, t float64) IWidgetDimension {
+	lerp := func(a, b int) int {
+		return gwutil.Max(0, int(gwutil.Round(float64(a)+float64(b-a)*t)))
+	}
+	switch f := from.(type) {
+	case RenderWithUnits:
+		if g, ok := to.(RenderWithUnits); ok {
+			return RenderWithUnits{U: lerp(f.U, g.U)}
+		}
+	case RenderWithWeight:
+		if g, ok := to.(RenderWithWeight); ok {
+			return RenderWithWeight{W: lerp(f.W, g.W)}
+		}
+	case RenderWithRatio:
+		if g, ok := to.(RenderWithRatio); ok {
+			return RenderWithRatio{R: math.Max(0, f.R+(g.R-f.R)*t)}
+		}
+	case RenderBox:
+		if g, ok := to.(RenderBox); ok {
+			return RenderBox{C: lerp(f.C, g.C), R: lerp(f.R, g.R)}
+		}
+	case RenderFlowWith:
+		if g, ok := to.(RenderFlowWith); ok {
+			return RenderFlowWith{C: lerp(f.C, g.C)}
+		}
+	}
+	if t < 0.5 {
+		return from
+	}
+	return to
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
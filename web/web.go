@@ -0,0 +1,265 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package web serves gowid apps to a browser. The page it serves runs xterm.js, a terminal emulator, and
+// connects to the server with a websocket; each connection runs an app of its own, drawing on the
+// browser's terminal and taking its keys and mouse. Any app works unchanged - it is only given a
+// different screen.
+package web
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/ansi"
+)
+
+//======================================================================
+
+// Where the page loads xterm.js and its fit addon from. Point them at your own copies to serve apps
+// without reaching the internet.
+var (
+	XtermCSS   = "https://cdn.jsdelivr.net/npm/xterm@5.3.0/css/xterm.css"
+	XtermJS    = "https://cdn.jsdelivr.net/npm/xterm@5.3.0/lib/xterm.js"
+	XtermFitJS = "https://cdn.jsdelivr.net/npm/xterm-addon-fit@0.8.0/lib/xterm-addon-fit.js"
+)
+
+// The subresource integrity hashes of the files above, e.g. "sha384-...". The browser refuses a file that
+// doesn't match its hash, so a CDN serving something else can't run script in the app's page. A file with
+// no hash is loaded unchecked. Generate a hash with
+//
+//	curl -s URL | openssl dgst -sha384 -binary | openssl base64 -A
+//
+// and change it whenever the file's URL changes.
+var (
+	XtermCSSIntegrity   = ""
+	XtermJSIntegrity    = ""
+	XtermFitJSIntegrity = ""
+)
+
+// The most columns or rows accepted from a browser.
+const maxSize = 1000
+
+// AppFunc returns the arguments for the app run for a browser, given the request that opened its
+// websocket. The Screen is set by the handler, and if no Log is given, the app's log is discarded.
+type AppFunc func(r *http.Request) (gowid.AppArgs, error)
+
+// Options is used to configure the handler.
+type Options struct {
+	Title     string                // The page's title - "gowid" if empty
+	Unhandled gowid.IUnhandledInput // Passed to each app's MainLoop - gowid.HandleQuitKeys if nil
+	Colors    int                   // The colors the browser's terminal is told to use - true color if 0
+
+	// CheckOrigin returns true if a websocket may be opened by the request given. If nil, SameOrigin is
+	// used, so that other sites the user visits can't open a websocket and drive the app.
+	CheckOrigin func(r *http.Request) bool
+}
+
+// Handler serves the page at the path it is mounted at, and each app at "ws" beneath it - so mount it at
+// a path ending in a slash, like "/" or "/app/".
+type Handler struct {
+	newApp AppFunc
+	opts   Options
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// NewHandler returns a handler that runs an app built from newApp for each browser that connects.
+func NewHandler(newApp AppFunc, opts ...Options) *Handler {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Title == "" {
+		opt.Title = "gowid"
+	}
+	if opt.Unhandled == nil {
+		opt.Unhandled = gowid.UnhandledInputFunc(gowid.HandleQuitKeys)
+	}
+	if opt.Colors == 0 {
+		opt.Colors = 1 << 24
+	}
+	if opt.CheckOrigin == nil {
+		opt.CheckOrigin = SameOrigin
+	}
+	return &Handler{
+		newApp: newApp,
+		opts:   opt,
+	}
+}
+
+// SameOrigin returns true if r has no Origin header, as from a client that isn't a browser, or if the host
+// of its Origin is the host r was sent to.
+func SameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if path.Base(r.URL.Path) == "ws" {
+		h.serveApp(w, r)
+	} else {
+		h.servePage(w, r)
+	}
+}
+
+func (h *Handler) servePage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	page.Execute(w, map[string]string{
+		"Title":          h.opts.Title,
+		"CSS":            XtermCSS,
+		"CSSIntegrity":   XtermCSSIntegrity,
+		"JS":             XtermJS,
+		"JSIntegrity":    XtermJSIntegrity,
+		"FitJS":          XtermFitJS,
+		"FitJSIntegrity": XtermFitJSIntegrity,
+		"Socket":         "ws",
+	})
+}
+
+// message is sent by the page - the terminal's input, or its new size.
+type message struct {
+	Type string `json:"type"` // "input" or "resize"
+	Data string `json:"data"`
+	Cols int    `json:"cols"`
+	Rows int    `json:"rows"`
+}
+
+func (m message) size() (int, int, bool) {
+	ok := m.Type == "resize" && m.Cols > 0 && m.Rows > 0 && m.Cols <= maxSize && m.Rows <= maxSize
+	return m.Cols, m.Rows, ok
+}
+
+func readMessage(c *conn) (message, error) {
+	var res message
+	_, data, err := c.readMessage()
+	if err != nil {
+		return res, err
+	}
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+func (h *Handler) serveApp(w http.ResponseWriter, r *http.Request) {
+	if !h.opts.CheckOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	c, err := upgrade(w, r)
+	if err != nil {
+		if _, ok := err.(BadHandshakeError); ok {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	defer c.Close()
+
+	// The page sends the terminal's size first, so the app starts at the right size
+	var cols, rows int
+	for {
+		msg, err := readMessage(c)
+		if err != nil {
+			return
+		}
+		var ok bool
+		if cols, rows, ok = msg.size(); ok {
+			break
+		}
+	}
+
+	args, err := h.newApp(r)
+	if err != nil {
+		return
+	}
+	screen := ansi.NewScreen(c, cols, rows, ansi.Options{Colors: h.opts.Colors})
+	app, err := ansi.NewApp(screen, args)
+	if err != nil {
+		return
+	}
+
+	go func() {
+		// Until the browser goes away
+		for {
+			msg, err := readMessage(c)
+			if err != nil {
+				break
+			}
+			if cols, rows, ok := msg.size(); ok {
+				screen.Resize(cols, rows)
+			} else if msg.Type == "input" {
+				screen.Input([]byte(msg.Data))
+			}
+		}
+		app.Quit()
+	}()
+	// A panic ends only this session, closing its websocket as this returns. Apps that want to hear of it
+	// set a hook in their AppArgs' Panic options.
+	ansi.MainLoop(app, h.opts.Unhandled)
+}
+
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.CSS}}"{{with .CSSIntegrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}>
+<script src="{{.JS}}"{{with .JSIntegrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}></script>
+<script src="{{.FitJS}}"{{with .FitJSIntegrity}} integrity="{{.}}" crossorigin="anonymous"{{end}}></script>
+<style>
+html, body, #terminal { height: 100%; margin: 0; background: #000; }
+</style>
+</head>
+<body>
+<div id="terminal"></div>
+<script>
+const term = new Terminal();
+const fit = new FitAddon.FitAddon();
+term.loadAddon(fit);
+term.open(document.getElementById("terminal"));
+fit.fit();
+
+const url = new URL("{{.Socket}}", location.href);
+url.protocol = url.protocol === "https:" ? "wss:" : "ws:";
+const ws = new WebSocket(url);
+ws.binaryType = "arraybuffer";
+const send = (msg) => {
+	if (ws.readyState === WebSocket.OPEN) {
+		ws.send(JSON.stringify(msg));
+	}
+};
+ws.onopen = () => {
+	send({type: "resize", cols: term.cols, rows: term.rows});
+	term.focus();
+};
+ws.onmessage = (ev) => term.write(new Uint8Array(ev.data));
+ws.onclose = () => term.write("\r\n\x1b[0m[disconnected]\r\n");
+term.onData((data) => send({type: "input", data: data}));
+term.onResize((size) => send({type: "resize", cols: size.cols, rows: size.rows}));
+window.addEventListener("resize", () => fit.fit());
+</script>
+</body>
+</html>
+`))
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,218 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package web
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+// dial opens a websocket to the server at url, as a browser would.
+func dial(t *testing.T, url string) (net.Conn, *bufio.Reader) {
+	c, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetDeadline(time.Now().Add(10 * time.Second))
+	req := "GET /ws HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n"
+	if _, err := c.Write([]byte(req)); err != nil {
+		t.Fatal(err)
+	}
+	r := bufio.NewReader(c)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	// From the example in RFC 6455
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+	return c, r
+}
+
+// send sends a masked text frame, as browsers do.
+func send(t *testing.T, c net.Conn, msg string) {
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{0x81, 0x80 | byte(len(msg))}
+	frame = append(frame, mask...)
+	for i := 0; i < len(msg); i++ {
+		frame = append(frame, msg[i]^mask[i%4])
+	}
+	if _, err := c.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// receive reads frames until one containing s arrives, or the websocket is closed.
+func receive(r *bufio.Reader, s string) bool {
+	var all []byte
+	for {
+		var head [2]byte
+		if _, err := io.ReadFull(r, head[:]); err != nil {
+			return false
+		}
+		n := uint64(head[1] & 0x7f)
+		switch n {
+		case 126:
+			var ext [2]byte
+			io.ReadFull(r, ext[:])
+			n = uint64(binary.BigEndian.Uint16(ext[:]))
+		case 127:
+			var ext [8]byte
+			io.ReadFull(r, ext[:])
+			n = binary.BigEndian.Uint64(ext[:])
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return false
+		}
+		all = append(all, payload...)
+		if strings.Contains(string(all), s) {
+			return true
+		}
+	}
+}
+
+func TestHandler1(t *testing.T) {
+	h := NewHandler(func(r *http.Request) (gowid.AppArgs, error) {
+		return gowid.AppArgs{View: text.New("hello from gowid")}, nil
+	}, Options{Title: "Demo"})
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(page), "<title>Demo</title>")
+	assert.Contains(t, string(page), XtermJS)
+	assert.NotContains(t, string(page), "integrity")
+
+	// Files with a hash are checked by the browser
+	XtermJSIntegrity = "sha384-abc"
+	defer func() { XtermJSIntegrity = "" }()
+	resp, err = http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(page), `<script src="`+XtermJS+`" integrity="sha384-abc" crossorigin="anonymous"></script>`)
+	assert.Contains(t, string(page), `<script src="`+XtermFitJS+`"></script>`)
+
+	// Not a websocket
+	resp, err = http.Get(srv.URL + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	c, r := dial(t, srv.URL)
+	defer c.Close()
+	send(t, c, `{"type":"resize","cols":40,"rows":5}`)
+	assert.True(t, receive(r, "hello from gowid"))
+
+	// q quits the app, which leaves the alternate screen and closes the websocket
+	send(t, c, `{"type":"input","data":"q"}`)
+	assert.True(t, receive(r, "\x1b[?1049l"))
+	assert.False(t, receive(r, "anything"))
+}
+
+func TestOrigin1(t *testing.T) {
+	h := NewHandler(func(r *http.Request) (gowid.AppArgs, error) {
+		return gowid.AppArgs{View: text.New("hello from gowid")}, nil
+	})
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	upgrade := func(origin string) int {
+		req, _ := http.NewRequest("GET", srv.URL+"/ws", nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Origin", origin)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Another site can't open a websocket; the page the handler serves can
+	assert.Equal(t, http.StatusForbidden, upgrade("http://evil.example.com"))
+	assert.Equal(t, http.StatusSwitchingProtocols, upgrade(srv.URL))
+
+	h.opts.CheckOrigin = func(r *http.Request) bool { return true }
+	assert.Equal(t, http.StatusSwitchingProtocols, upgrade("http://evil.example.com"))
+}
+
+// boom is a widget that panics when x is pressed.
+type boom struct {
+	*text.Widget
+}
+
+func (w boom) Selectable() bool {
+	return true
+}
+
+func (w boom) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if kev, ok := ev.(*tcell.EventKey); ok && kev.Rune() == 'x' {
+		panic("boom")
+	}
+	return false
+}
+
+func TestPanic1(t *testing.T) {
+	h := NewHandler(func(r *http.Request) (gowid.AppArgs, error) {
+		return gowid.AppArgs{View: boom{text.New("hello from gowid")}}, nil
+	})
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	c1, r1 := dial(t, srv.URL)
+	defer c1.Close()
+	send(t, c1, `{"type":"resize","cols":40,"rows":5}`)
+	assert.True(t, receive(r1, "hello from gowid"))
+	c2, r2 := dial(t, srv.URL)
+	defer c2.Close()
+	send(t, c2, `{"type":"resize","cols":40,"rows":5}`)
+	assert.True(t, receive(r2, "hello from gowid"))
+
+	// The first session's app panics; its terminal is restored and its websocket closed
+	send(t, c1, `{"type":"input","data":"x"}`)
+	assert.True(t, receive(r1, "\x1b[?1049l"))
+	assert.False(t, receive(r1, "anything"))
+
+	// The second carries on
+	send(t, c2, `{"type":"resize","cols":30,"rows":4}`)
+	assert.True(t, receive(r2, "hello from gowid"))
+	send(t, c2, `{"type":"input","data":"q"}`)
+	assert.True(t, receive(r2, "\x1b[?1049l"))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,223 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package web
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+//======================================================================
+
+// Just enough of RFC 6455 for a page to exchange messages with the server it was loaded from.
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// maxMessage is the largest message accepted from a browser - input and resizes are small.
+const maxMessage = 1 << 20
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// BadHandshakeError is returned if a request can't be upgraded to a websocket.
+type BadHandshakeError struct {
+	Reason string
+}
+
+func (e BadHandshakeError) Error() string {
+	return fmt.Sprintf("websocket handshake failed: %s", e.Reason)
+}
+
+// conn is the server's end of a websocket.
+type conn struct {
+	c   net.Conn
+	r   *bufio.Reader
+	wmu sync.Mutex // Guards writes, which come from the app and the reading goroutine
+}
+
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h[http.CanonicalHeaderKey(name)] {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upgrade completes the websocket handshake begun by r, and takes over its connection.
+func upgrade(w http.ResponseWriter, r *http.Request) (*conn, error) {
+	if r.Method != http.MethodGet {
+		return nil, BadHandshakeError{"method is not GET"}
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		return nil, BadHandshakeError{"not a websocket upgrade"}
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, BadHandshakeError{"unsupported version"}
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, BadHandshakeError{"no key"}
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, BadHandshakeError{"connection can't be hijacked"}
+	}
+	c, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n"
+	if _, err := c.Write([]byte(resp)); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return &conn{c: c, r: rw.Reader}, nil
+}
+
+// readMessage returns the next text or binary message, answering pings along the way. io.EOF is returned
+// when the browser closes the websocket.
+func (c *conn) readMessage() (int, []byte, error) {
+	var msg []byte
+	op := -1
+	for {
+		fin, fop, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch fop {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			c.writeFrame(opClose, nil)
+			return 0, nil, io.EOF
+		case opContinuation:
+			if op == -1 {
+				return 0, nil, errors.New("websocket continuation without a message")
+			}
+		default:
+			op = fop
+		}
+		msg = append(msg, payload...)
+		if len(msg) > maxMessage {
+			return 0, nil, errors.New("websocket message too large")
+		}
+		if fin {
+			return op, msg, nil
+		}
+	}
+}
+
+func (c *conn) readFrame() (bool, int, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := head[0]&0x80 != 0
+	op := int(head[0] & 0x0f)
+	masked := head[1]&0x80 != 0
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxMessage {
+		return false, 0, nil, errors.New("websocket frame too large")
+	}
+	if !masked {
+		// Browsers must mask what they send
+		return false, 0, nil, errors.New("websocket frame from client is not masked")
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, op, payload, nil
+}
+
+// writeFrame sends payload as a single, unmasked frame.
+func (c *conn) writeFrame(op int, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	head := make([]byte, 0, 10)
+	head = append(head, 0x80|byte(op))
+	switch n := len(payload); {
+	case n < 126:
+		head = append(head, byte(n))
+	case n <= 0xffff:
+		head = append(head, 126, byte(n>>8), byte(n))
+	default:
+		var ext [8]byte
+		binary.BigEndian.PutUint64(ext[:], uint64(n))
+		head = append(head, 127)
+		head = append(head, ext[:]...)
+	}
+	if _, err := c.c.Write(head); err != nil {
+		return err
+	}
+	_, err := c.c.Write(payload)
+	return err
+}
+
+// Write sends p as a binary message, so that the app's screen can draw on the browser's terminal.
+func (c *conn) Write(p []byte) (int, error) {
+	if err := c.writeFrame(opBinary, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *conn) Close() error {
+	return c.c.Close()
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
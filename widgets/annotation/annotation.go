@@ -0,0 +1,219 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package annotation provides reference lines and shaded regions for charts, like an SLO threshold
+// across a bar graph or a marker at the time of a deploy. Charts that support annotations read them
+// through IAnnotated and draw them with Draw.
+package annotation
+
+import (
+	"math"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/mattn/go-runewidth"
+)
+
+//======================================================================
+
+// The theme roles used to style annotations that don't have a style of their own. If the app's theme,
+// or palette, doesn't define a role, a default is used - red for thresholds, yellow for markers and dark
+// gray for regions.
+const (
+	ThresholdRole = "chart.threshold" // Horizontal lines
+	MarkerRole    = "chart.marker"    // Vertical lines
+	RegionRole    = "chart.region"    // Shaded regions
+)
+
+// Line is a reference line across a chart. A horizontal line is at Value on the chart's y axis, e.g. a
+// threshold; a vertical line is at Value on its x axis - for a bar graph, the index of a bar - e.g. a
+// deploy.
+type Line struct {
+	Value    float64
+	Vertical bool
+	Label    string            // Drawn at the right end of a horizontal line, or the top of a vertical one
+	Style    gowid.ICellStyler // If nil, ThresholdRole or MarkerRole
+}
+
+// Region shades the part of a chart from From to To, on the y axis or, if Vertical, the x axis.
+type Region struct {
+	From, To float64
+	Vertical bool
+	Label    string            // Drawn in the region's top left corner
+	Style    gowid.ICellStyler // Its background shades the region; if nil, RegionRole
+}
+
+// Annotations are the lines and regions a chart draws over its data.
+type Annotations struct {
+	Lines   []Line
+	Regions []Region
+}
+
+// IAnnotated is implemented by charts that draw annotations.
+type IAnnotated interface {
+	GetAnnotations() Annotations
+}
+
+// Axes describes where a chart drew its data, so that Draw can place annotations.
+type Axes struct {
+	Left, Top  int                     // The top left corner of the plot area, in the canvas
+	Cols, Rows int                     // The size of the plot area
+	Row        func(v float64) int     // The canvas row for v on the y axis, which may be outside the plot area
+	Col        func(v float64) int     // The canvas column for v on the x axis
+	Shade      func(c gowid.Cell) bool // If not nil, only cells for which this is true are shaded by regions
+}
+
+//======================================================================
+
+// Draw draws a's regions behind the data in c, and its lines over the data. Lines are drawn on cells
+// without a rune - so a line passes behind the points of a plot - and labels over everything.
+func Draw(c gowid.ICanvas, a Annotations, axes Axes, app gowid.IApp) {
+	for _, r := range a.Regions {
+		drawRegion(c, r, axes, app)
+	}
+	drawn := make(map[position]rune)
+	for _, l := range a.Lines {
+		drawLine(c, l, axes, drawn, app)
+	}
+}
+
+type position struct {
+	x, y int
+}
+
+// styleFor returns the foreground, background and attributes of styler, substituting defaults for
+// colors it doesn't set.
+func styleFor(styler gowid.ICellStyler, fg gowid.IColor, bg gowid.IColor, app gowid.IApp) (gowid.TCellColor, gowid.TCellColor, gowid.StyleAttrs) {
+	f, b, s := styler.GetStyle(app)
+	if _, ok := f.(gowid.NoColor); ok || f == nil {
+		f = fg
+	}
+	if _, ok := b.(gowid.NoColor); ok || b == nil {
+		b = bg
+	}
+	return gowid.IColorToTCell(f, gowid.ColorNone, app.GetColorMode()),
+		gowid.IColorToTCell(b, gowid.ColorNone, app.GetColorMode()),
+		s
+}
+
+func drawRegion(c gowid.ICanvas, r Region, axes Axes, app gowid.IApp) {
+	styler := r.Style
+	if styler == nil {
+		styler = gowid.MakeThemeRef(RegionRole)
+	}
+	fg, bg, _ := styleFor(styler, gowid.ColorNone, gowid.ColorDarkGray, app)
+
+	x1, x2 := axes.Left, axes.Left+axes.Cols-1
+	y1, y2 := axes.Top, axes.Top+axes.Rows-1
+	if r.Vertical {
+		from, to := axes.Col(r.From), axes.Col(r.To)
+		x1, x2 = gwutil.Max(x1, gwutil.Min(from, to)), gwutil.Min(x2, gwutil.Max(from, to))
+	} else {
+		from, to := axes.Row(r.From), axes.Row(r.To)
+		y1, y2 = gwutil.Max(y1, gwutil.Min(from, to)), gwutil.Min(y2, gwutil.Max(from, to))
+	}
+	if x1 > x2 || y1 > y2 {
+		return
+	}
+	for y := y1; y <= y2; y++ {
+		for x := x1; x <= x2; x++ {
+			cell := c.CellAt(x, y)
+			if axes.Shade == nil || axes.Shade(cell) {
+				c.SetCellAt(x, y, cell.WithBackgroundColor(bg))
+			}
+		}
+	}
+	drawLabel(c, r.Label, x1, y1, x2, fg, gowid.StyleNone)
+}
+
+// drawLine draws l on cells without a rune, or where another line has been drawn, as recorded in drawn.
+func drawLine(c gowid.ICanvas, l Line, axes Axes, drawn map[position]rune, app gowid.IApp) {
+	styler, def := l.Style, gowid.IColor(gowid.ColorRed)
+	if l.Vertical {
+		def = gowid.ColorYellow
+	}
+	if styler == nil {
+		if l.Vertical {
+			styler = gowid.MakeThemeRef(MarkerRole)
+		} else {
+			styler = gowid.MakeThemeRef(ThresholdRole)
+		}
+	}
+	fg, _, st := styleFor(styler, def, gowid.ColorNone, app)
+	style := gowid.MakeCell(0, fg, gowid.ColorNone, st)
+
+	draw := func(x, y int, r rune, cross rune) {
+		cell := c.CellAt(x, y)
+		if prev, ok := drawn[position{x, y}]; ok {
+			if prev != r {
+				r = cross
+			}
+		} else if cell.HasRune() && cell.Rune() != ' ' {
+			return
+		}
+		drawn[position{x, y}] = r
+		c.SetCellAt(x, y, cell.WithRune(r).MergeDisplayAttrsUnder(style))
+	}
+
+	if l.Vertical {
+		x := axes.Col(l.Value)
+		if x < axes.Left || x >= axes.Left+axes.Cols {
+			return
+		}
+		for y := axes.Top; y < axes.Top+axes.Rows; y++ {
+			draw(x, y, gowid.ASCIIRune(app, '│', '|'), gowid.ASCIIRune(app, '┼', '+'))
+		}
+		if x+1+runewidth.StringWidth(l.Label) <= axes.Left+axes.Cols {
+			drawLabel(c, l.Label, x+1, axes.Top, axes.Left+axes.Cols-1, fg, st)
+		} else {
+			drawLabel(c, l.Label, gwutil.Max(axes.Left, x-runewidth.StringWidth(l.Label)), axes.Top, x-1, fg, st)
+		}
+		return
+	}
+
+	y := axes.Row(l.Value)
+	if y < axes.Top || y >= axes.Top+axes.Rows {
+		return
+	}
+	for x := axes.Left; x < axes.Left+axes.Cols; x++ {
+		draw(x, y, gowid.ASCIIRune(app, '─', '-'), gowid.ASCIIRune(app, '┼', '+'))
+	}
+	right := axes.Left + axes.Cols - 1
+	drawLabel(c, l.Label, gwutil.Max(axes.Left, right+1-runewidth.StringWidth(l.Label)), y, right, fg, st)
+}
+
+// drawLabel writes label from column x1 to at most column x2 in row y.
+func drawLabel(c gowid.ICanvas, label string, x1, y, x2 int, fg gowid.TCellColor, st gowid.StyleAttrs) {
+	style := gowid.MakeCell(0, fg, gowid.ColorNone, st)
+	x := x1
+	for _, r := range label {
+		w := runewidth.RuneWidth(r)
+		if x+w-1 > x2 {
+			break
+		}
+		c.SetCellAt(x, y, c.CellAt(x, y).WithRune(r).MergeDisplayAttrsUnder(style))
+		x += w
+	}
+}
+
+// Scale returns a function that maps values from lo to hi onto n rows or columns, starting at first. If
+// reverse is true, hi is mapped to first - as for a y axis, whose values increase up the screen.
+func Scale(lo, hi float64, first, n int, reverse bool) func(v float64) int {
+	return func(v float64) int {
+		if hi == lo || n <= 1 {
+			return first
+		}
+		pos := int(math.Round((v - lo) / (hi - lo) * float64(n-1)))
+		if reverse {
+			pos = n - 1 - pos
+		}
+		return first + pos
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,126 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package annotation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+// axes6x4 is a plot area filling a 6x4 canvas, for x from 0 to 5 and y from 0 to 3.
+func axes6x4() Axes {
+	return Axes{
+		Cols: 6,
+		Rows: 4,
+		Row:  Scale(0, 3, 0, 4, true),
+		Col:  Scale(0, 5, 0, 6, false),
+	}
+}
+
+func draw(a Annotations, axes Axes, data ...string) gowid.ICanvas {
+	c := gowid.NewCanvasOfSize(6, 4)
+	for y, line := range data {
+		for x, r := range line {
+			if r != ' ' {
+				c.SetCellAt(x, y, gowid.MakeCell(r, gowid.ColorNone, gowid.ColorNone, gowid.StyleNone))
+			}
+		}
+	}
+	Draw(c, a, axes, gwtest.D)
+	return c
+}
+
+func TestThreshold1(t *testing.T) {
+	// The line is drawn behind the data, and labeled at its right end
+	c := draw(Annotations{Lines: []Line{{Value: 2, Label: "slo"}}}, axes6x4(), "", " x")
+	assert.Equal(t, strings.Join([]string{
+		"      ",
+		"─x─slo",
+		"      ",
+		"      ",
+	}, "\n"), c.String())
+	fg := gowid.IColorToTCell(gowid.ColorRed, gowid.ColorNone, gwtest.D.GetColorMode())
+	assert.Equal(t, fg, c.CellAt(0, 1).ForegroundColor())
+
+	// Off the chart, nothing is drawn
+	c = draw(Annotations{Lines: []Line{{Value: 9, Label: "slo"}}}, axes6x4())
+	assert.Equal(t, strings.Repeat("      \n", 3)+"      ", c.String())
+}
+
+func TestMarker1(t *testing.T) {
+	// The label is to the right of the line, at the top
+	c := draw(Annotations{Lines: []Line{{Value: 2, Vertical: true, Label: "v1"}}}, axes6x4())
+	assert.Equal(t, strings.Join([]string{
+		"  │v1 ",
+		"  │   ",
+		"  │   ",
+		"  │   ",
+	}, "\n"), c.String())
+
+	// Or to the left, if it doesn't fit
+	c = draw(Annotations{Lines: []Line{{Value: 5, Vertical: true, Label: "dep"}}}, axes6x4())
+	assert.Equal(t, strings.Join([]string{
+		"  dep│",
+		"     │",
+		"     │",
+		"     │",
+	}, "\n"), c.String())
+}
+
+func TestRegion1(t *testing.T) {
+	shade := gowid.IColorToTCell(gowid.ColorDarkGray, gowid.ColorNone, gwtest.D.GetColorMode())
+
+	// y from 0 to 1 is the bottom two rows
+	c := draw(Annotations{Regions: []Region{{From: 0, To: 1, Label: "ok"}}}, axes6x4())
+	assert.Equal(t, strings.Join([]string{
+		"      ",
+		"      ",
+		"ok    ",
+		"      ",
+	}, "\n"), c.String())
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 6; x++ {
+			assert.Equal(t, y >= 2, c.CellAt(x, y).BackgroundColor() == shade, "cell %d,%d", x, y)
+		}
+	}
+
+	// Only cells Shade allows are shaded
+	axes := axes6x4()
+	axes.Shade = func(c gowid.Cell) bool {
+		return !c.HasRune()
+	}
+	c = draw(Annotations{Regions: []Region{{From: 1, To: 2, Vertical: true}}}, axes, "", " x")
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 6; x++ {
+			shaded := (x == 1 || x == 2) && !(x == 1 && y == 1)
+			assert.Equal(t, shaded, c.CellAt(x, y).BackgroundColor() == shade, "cell %d,%d", x, y)
+		}
+	}
+}
+
+func TestCrossing1(t *testing.T) {
+	c := draw(Annotations{Lines: []Line{
+		{Value: 2},
+		{Value: 2, Vertical: true},
+		{Value: 2}, // The same line again doesn't cross the first
+	}}, axes6x4())
+	assert.Equal(t, strings.Join([]string{
+		"  │   ",
+		"──┼───",
+		"  │   ",
+		"  │   ",
+	}, "\n"), c.String())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
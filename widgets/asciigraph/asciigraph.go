@@ -6,11 +6,14 @@
 package asciigraph
 
 import (
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/gcla/gowid"
 	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/annotation"
 	"github.com/gcla/gowid/widgets/fill"
 	"github.com/guptarohit/asciigraph"
 )
@@ -22,14 +25,29 @@ type IAsciiGraph interface {
 	GetConf() []asciigraph.Option
 }
 
+// ISizedAsciiGraph is implemented by graphs whose data depends on the space they have to draw in, like
+// those that plot a downsampled TimeSeries.
+type ISizedAsciiGraph interface {
+	GetDataForWidth(cols int, app gowid.IApp) []float64
+}
+
 type IWidget interface {
 	gowid.IWidget
 	IAsciiGraph
 }
 
+// TimeSeriesOptions configures how a graph plots a TimeSeries.
+type TimeSeriesOptions struct {
+	Window time.Duration          // If > 0, only the points this close to the app's current time are plotted
+	Method gowid.DownsampleMethod // How the points are reduced to fit the width of the graph
+}
+
 type Widget struct {
-	Data []float64
-	Conf []asciigraph.Option
+	Data       []float64
+	Conf       []asciigraph.Option
+	Notes      annotation.Annotations
+	Series     *gowid.TimeSeries // If not nil, plotted instead of Data
+	SeriesOpts TimeSeriesOptions
 	gowid.RejectUserInput
 	gowid.NotSelectable
 }
@@ -42,14 +60,43 @@ func New(series []float64, config []asciigraph.Option) *Widget {
 	return res
 }
 
+// NewFromTimeSeries returns a graph that plots series as it fills, downsampled to the graph's width when
+// it has more points than columns to draw them in.
+func NewFromTimeSeries(series *gowid.TimeSeries, opts TimeSeriesOptions, config []asciigraph.Option) *Widget {
+	res := New(nil, config)
+	res.Series = series
+	res.SeriesOpts = opts
+	return res
+}
+
 func (w *Widget) String() string {
 	return "asciigraph"
 }
 
 func (w *Widget) GetData() []float64 {
+	if w.Series != nil {
+		return gowid.TimePointValues(w.Series.Points())
+	}
 	return w.Data
 }
 
+// GetDataForWidth returns the data to plot in cols columns - downsampled from the graph's TimeSeries, if it
+// has one.
+func (w *Widget) GetDataForWidth(cols int, app gowid.IApp) []float64 {
+	if w.Series == nil {
+		return w.Data
+	}
+	var from time.Time
+	if w.SeriesOpts.Window > 0 {
+		from = gowid.ClockFor(app).Now().Add(-w.SeriesOpts.Window)
+	}
+	n := cols
+	if w.SeriesOpts.Method == gowid.DownsampleMinMax {
+		n = cols / 2
+	}
+	return gowid.TimePointValues(w.Series.Downsampled(from, time.Time{}, n, w.SeriesOpts.Method))
+}
+
 func (w *Widget) SetData(data []float64, app gowid.IApp) {
 	w.Data = data
 }
@@ -62,6 +109,16 @@ func (w *Widget) SetConf(conf []asciigraph.Option, app gowid.IApp) {
 	w.Conf = conf
 }
 
+func (w *Widget) GetAnnotations() annotation.Annotations {
+	return w.Notes
+}
+
+// SetAnnotations sets the reference lines and regions drawn on the plot. Horizontal lines and regions are
+// placed by value; vertical ones by the index of a point in the data.
+func (w *Widget) SetAnnotations(notes annotation.Annotations, app gowid.IApp) {
+	w.Notes = notes
+}
+
 func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
 	return RenderSize(w, size, focus, app)
 }
@@ -79,7 +136,19 @@ func RenderSize(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gow
 
 func Render(w IAsciiGraph, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
 
-	grender := strings.Split(asciigraph.Plot(w.GetData(), w.GetConf()...), "\n")
+	data := w.GetData()
+	sw, sized := w.(ISizedAsciiGraph)
+	if box, ok := size.(gowid.IRenderBox); ok && sized {
+		data = sw.GetDataForWidth(box.BoxColumns(), app)
+	}
+	grender := plot(data, w.GetConf())
+	if box, ok := size.(gowid.IRenderBox); ok && sized && len(grender) > 0 {
+		// Leave room for the y axis labels
+		if width := utf8.RuneCountInString(grender[0]); width > box.BoxColumns() {
+			data = sw.GetDataForWidth(box.BoxColumns()-(width-len(data)), app)
+			grender = plot(data, w.GetConf())
+		}
+	}
 
 	rows := len(grender)
 	cols := 0
@@ -109,9 +178,61 @@ func Render(w IAsciiGraph, size gowid.IRenderSize, focus gowid.Selector, app gow
 		}
 	}
 
+	if aw, ok := w.(annotation.IAnnotated); ok {
+		if axes, ok := plotAxes(grender, len(data), res); ok {
+			annotation.Draw(res, aw.GetAnnotations(), axes, app)
+		}
+	}
+
 	return res
 }
 
+// plot returns the lines of asciigraph's plot of data. There are none if there's no data.
+func plot(data []float64, conf []asciigraph.Option) []string {
+	if len(data) == 0 {
+		return []string{}
+	}
+	return strings.Split(asciigraph.Plot(data, conf...), "\n")
+}
+
+// plotAxes works out where asciigraph drew the plot from its y axis - the rows with a label followed by
+// ┤ or ┼.
+func plotAxes(grender []string, points int, c gowid.ICanvas) (annotation.Axes, bool) {
+	left, top, bottom := -1, -1, -1
+	var hi, lo float64
+	for y, line := range grender {
+		if y >= c.BoxRows() {
+			break
+		}
+		i := strings.IndexAny(line, "┤┼")
+		if i == -1 {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(line[:i]), 64)
+		if err != nil {
+			continue
+		}
+		if top == -1 {
+			top, hi = y, v
+			left = utf8.RuneCountInString(line[:i]) + 1
+		}
+		bottom, lo = y, v
+	}
+	if top == -1 || left >= c.BoxColumns() {
+		return annotation.Axes{}, false
+	}
+	cols := gwutil.Min(utf8.RuneCountInString(grender[top]), c.BoxColumns()) - left
+	rows := bottom - top + 1
+	return annotation.Axes{
+		Left: left,
+		Top:  top,
+		Cols: cols,
+		Rows: rows,
+		Row:  annotation.Scale(lo, hi, top, rows, true),
+		Col:  annotation.Scale(0, float64(points-1), left, cols, false),
+	}, true
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
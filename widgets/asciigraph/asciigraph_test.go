@@ -5,10 +5,15 @@
 package asciigraph
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gcla/gowid"
 	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/annotation"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
 	asc "github.com/guptarohit/asciigraph"
 	"github.com/stretchr/testify/assert"
 )
@@ -52,6 +57,70 @@ func TestSolidAsciigraph1(t *testing.T) {
 	gwtest.RenderBoxManyTimes(t, w, 0, 20, 0, 20)
 }
 
+func TestAnnotatedAsciigraph1(t *testing.T) {
+	data := []float64{2, 1, 1, 2, -2, 5, 7, 11, 3, 7, 1}
+	w := New(data, []asc.Option{})
+	w.SetAnnotations(annotation.Annotations{
+		Lines: []annotation.Line{
+			{Value: 8, Label: "slo"},
+			{Value: 2, Vertical: true},
+		},
+		Regions: []annotation.Region{
+			{From: -2, To: -1},
+		},
+	}, gwtest.D)
+
+	c1 := w.Render(gowid.RenderBox{C: 19, R: 14}, gowid.Focused, gwtest.D)
+
+	res := ` 11.00 ┤  │   ╭╮   
+ 10.00 ┤  │   ││   
+  9.00 ┼  │   ││   
+  8.00 ┤──┼───││slo
+  7.00 ┤  │  ╭╯│╭╮ 
+  6.00 ┤  │  │ │││ 
+  5.00 ┤  │ ╭╯ │││ 
+  4.00 ┤  │ │  │││ 
+  3.00 ┤  │ │  ╰╯│ 
+  2.00 ┼╮ ╭╮│    │ 
+  1.00 ┤╰─╯││    ╰ 
+  0.00 ┤  │││      
+ -1.00 ┤  │││      
+ -2.00 ┤  │╰╯      `
+
+	t.Logf("Canvas is\n%v\n", c1.String())
+	assert.Equal(t, res, c1.String())
+
+	fg, _, _ := c1.CellAt(8, 3).GetDisplayAttrs()
+	assert.Equal(t, gowid.MakeTCellColorExt(tcell.ColorRed), fg)
+	_, bg, _ := c1.CellAt(10, 13).GetDisplayAttrs()
+	assert.Equal(t, gowid.MakeTCellColorExt(tcell.ColorDarkGray), bg)
+	_, bg, _ = c1.CellAt(10, 11).GetDisplayAttrs()
+	assert.Equal(t, gowid.ColorNone, bg)
+}
+
+func TestTimeSeriesAsciigraph1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Unix(1000, 0))
+	app := gwtest.NewSimT(t, text.New(""), gwtest.SimOptions{Cols: 1, Rows: 1, Clock: clock})
+	defer app.Close()
+
+	series := gowid.NewTimeSeries(1000)
+	for i := 0; i < 500; i++ {
+		series.AddNow(float64(i%50), app)
+		clock.Advance(time.Second)
+	}
+	w := NewFromTimeSeries(series, TimeSeriesOptions{}, []asc.Option{})
+
+	// The label is 6 columns, and the axis 1, leaving 13 for the data
+	assert.Equal(t, 13, len(w.GetDataForWidth(13, app)))
+	c1 := w.Render(gowid.RenderBox{C: 20, R: 10}, gowid.Focused, app)
+	assert.True(t, strings.HasPrefix(c1.String(), " 49.00 "))
+
+	// Only the last 100 seconds
+	w.SeriesOpts = TimeSeriesOptions{Window: 100 * time.Second, Method: gowid.DownsampleMinMax}
+	assert.Equal(t, 100, len(w.GetDataForWidth(500, app)))
+	assert.Equal(t, 20, len(w.GetDataForWidth(20, app)))
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
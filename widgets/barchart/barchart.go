@@ -0,0 +1,501 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package barchart provides a bar chart widget, with labeled categories, one or more series of bars for
+// each, and a value axis. Unlike the bargraph widget, it can be rendered as a flow widget.
+package barchart
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/annotation"
+	"github.com/gcla/gowid/widgets/legend"
+	"github.com/mattn/go-runewidth"
+)
+
+//======================================================================
+
+// DefaultHeight is the height of a vertical chart rendered as a flow or fixed widget, unless Options says
+// otherwise.
+var DefaultHeight = 10
+
+// DefaultLength is the length of the longest bar of a horizontal chart rendered as a fixed widget.
+var DefaultLength = 40
+
+// Series is a set of bars, one for each category of the chart.
+type Series struct {
+	Name   string
+	Values []float64           // Indexed by category
+	Style  gowid.ICellStyler   // The style of the bars; if nil, they are unstyled
+	Styles []gowid.ICellStyler // If not nil, the style of each bar, overriding Style where not nil
+}
+
+// Options is used to configure the widget.
+type Options struct {
+	Horizontal bool // If true, bars grow to the right from labels on the left; otherwise they grow upward
+	// Min and Max are the values at the ends of the value axis. If they are equal, the axis runs from 0,
+	// or the least value if it's negative, to a round number at or above the greatest value.
+	Min, Max float64
+	// BarWidth is the number of cells across each bar. If 0, bars are as wide as will fill the chart when
+	// it is rendered as a box, and 1 cell wide otherwise.
+	BarWidth int
+	Gap      int                    // The number of cells between categories; defaults to 1, and -1 means none
+	Height   int                    // The height of a vertical chart rendered as a flow widget; defaults to DefaultHeight
+	NoAxis   bool                   // If true, the value axis isn't drawn
+	Format   func(v float64) string // Formats the axis labels; defaults to the shortest decimal
+	// Legend, if not nil, hides and highlights series, and gives the color of their bars - see
+	// legend.ISeries. A hidden series leaves a gap, so the bars of the others don't move.
+	Legend legend.ISeries
+}
+
+// Widget is a bar chart. Values below the bottom of the value axis draw no bar, and those above the top
+// draw a full bar.
+type Widget struct {
+	categories []string
+	series     []Series
+	opts       Options
+	notes      annotation.Annotations
+	gowid.RejectUserInput
+	gowid.NotSelectable
+}
+
+func New(categories []string, series []Series, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	switch {
+	case opt.Gap == 0:
+		opt.Gap = 1
+	case opt.Gap < 0:
+		opt.Gap = 0
+	}
+	if opt.Height <= 0 {
+		opt.Height = DefaultHeight
+	}
+	if opt.Format == nil {
+		opt.Format = func(v float64) string {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	res := &Widget{
+		categories: categories,
+		series:     series,
+		opts:       opt,
+	}
+	var _ gowid.IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("barchart[%d]", len(w.categories))
+}
+
+// Categories returns the labels of the chart's categories.
+func (w *Widget) Categories() []string {
+	return w.categories
+}
+
+// Series returns the chart's series of bars.
+func (w *Widget) Series() []Series {
+	return w.series
+}
+
+// SetData replaces the chart's categories and series.
+func (w *Widget) SetData(categories []string, series []Series, app gowid.IApp) {
+	w.categories = categories
+	w.series = series
+}
+
+func (w *Widget) GetAnnotations() annotation.Annotations {
+	return w.notes
+}
+
+// SetAnnotations sets the reference lines and regions drawn over the bars. Lines and regions are placed
+// by value on the value axis, or, if Vertical, by the index of a category - so in a horizontal chart,
+// where the value axis runs across, a Line placed by value runs down the chart, and one that is Vertical
+// runs across it.
+func (w *Widget) SetAnnotations(notes annotation.Annotations, app gowid.IApp) {
+	w.notes = notes
+}
+
+// Range returns the values at the ends of the value axis - see Options. The values of series hidden by
+// the legend are left out.
+func (w *Widget) Range() (float64, float64) {
+	if w.opts.Min != w.opts.Max {
+		return w.opts.Min, w.opts.Max
+	}
+	min, max := 0.0, 0.0
+	for i, s := range w.series {
+		if w.hidden(i) {
+			continue
+		}
+		for _, v := range s.Values {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				min, max = math.Min(min, v), math.Max(max, v)
+			}
+		}
+	}
+	if min < 0 {
+		min = -niceCeil(-min)
+	}
+	return min, niceCeil(max)
+}
+
+// niceCeil returns the least of 1, 2 and 5 times a power of ten that is at least v.
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, f := range []float64{1, 2, 5, 10} {
+		if f*exp >= v {
+			return f * exp
+		}
+	}
+	return 10 * exp
+}
+
+func (w *Widget) hidden(s int) bool {
+	return legend.SeriesHidden(w.opts.Legend, s)
+}
+
+// style returns the style of the bar of series s for category i.
+func (w *Widget) style(s int, i int) gowid.ICellStyler {
+	series := w.series[s]
+	if i < len(series.Styles) && series.Styles[i] != nil {
+		return series.Styles[i]
+	}
+	return series.Style
+}
+
+// cell returns a blank cell styled for the bar of series s for category i. If the chart has a legend
+// describing series s, the bar is drawn in the legend's color for it.
+func (w *Widget) cell(s int, i int, app gowid.IApp) gowid.Cell {
+	res := baseCell(w.style(s, i), app)
+	if w.opts.Legend != nil && s < w.opts.Legend.SeriesCount() {
+		color := w.opts.Legend.SeriesColor(s)
+		res = res.WithForegroundColor(gowid.IColorToTCell(color, gowid.ColorNone, app.GetColorMode()))
+	}
+	return res
+}
+
+// value returns the value of the bar of series s for category i, and false if it has none, or if the
+// series is hidden.
+func (w *Widget) value(s int, i int) (float64, bool) {
+	values := w.series[s].Values
+	if i >= len(values) || math.IsNaN(values[i]) || w.hidden(s) {
+		return 0, false
+	}
+	return values[i], true
+}
+
+func (w *Widget) labelWidth() int {
+	res := 0
+	for _, c := range w.categories {
+		res = gwutil.Max(res, runewidth.StringWidth(c))
+	}
+	return res
+}
+
+// ticks returns the values labeled on the axis - the ends, and the middle if there is room.
+func (w *Widget) ticks(room int) []float64 {
+	min, max := w.Range()
+	if room >= 5 {
+		return []float64{min, (min + max) / 2, max}
+	}
+	return []float64{min, max}
+}
+
+func (w *Widget) tickWidth() int {
+	res := 0
+	for _, t := range w.ticks(5) {
+		res = gwutil.Max(res, runewidth.StringWidth(w.opts.Format(t)))
+	}
+	return res
+}
+
+// span returns the cells taken across the chart by every bar, and the gaps between categories.
+func (w *Widget) span(barWidth int) int {
+	n := len(w.categories)
+	return n*len(w.series)*barWidth + gwutil.Max(0, n-1)*w.opts.Gap
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	cols, rows := w.size(size)
+	return gowid.RenderBox{C: cols, R: rows}
+}
+
+func (w *Widget) size(size gowid.IRenderSize) (int, int) {
+	axis := 0
+	if !w.opts.NoAxis {
+		axis = 1
+	}
+	barWidth := gwutil.Max(1, w.opts.BarWidth)
+	switch sz := size.(type) {
+	case gowid.IRenderBox:
+		return sz.BoxColumns(), sz.BoxRows()
+	case gowid.IRenderFlowWith:
+		if w.opts.Horizontal {
+			return sz.FlowColumns(), w.span(barWidth) + axis
+		}
+		return sz.FlowColumns(), w.opts.Height
+	default:
+		if w.opts.Horizontal {
+			return w.labelWidth() + 1 + DefaultLength, w.span(barWidth) + axis
+		}
+		width := 0
+		if !w.opts.NoAxis {
+			width = w.tickWidth() + 1
+		}
+		return width + w.span(barWidth), w.opts.Height
+	}
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	cols, rows := w.size(size)
+	res := gowid.NewCanvasOfSize(cols, rows)
+	_, isBox := size.(gowid.IRenderBox)
+	if w.opts.Horizontal {
+		w.renderHorizontal(res, cols, rows, isBox, app)
+	} else {
+		w.renderVertical(res, cols, rows, isBox, app)
+	}
+	return res
+}
+
+// barWidth returns the width of each bar, given room for them all.
+func (w *Widget) barWidth(room int, fill bool) int {
+	if w.opts.BarWidth > 0 {
+		return w.opts.BarWidth
+	}
+	n := len(w.categories) * len(w.series)
+	if !fill || n == 0 {
+		return 1
+	}
+	return gwutil.Max(1, (room-gwutil.Max(0, len(w.categories)-1)*w.opts.Gap)/n)
+}
+
+// eighths returns the length of the bar for v, in eighths of a cell, on an axis cells long.
+func (w *Widget) eighths(v float64, cells int) int {
+	min, max := w.Range()
+	if max <= min {
+		return 0
+	}
+	frac := math.Max(0, math.Min(1, (v-min)/(max-min)))
+	return int(frac*float64(cells*8) + 0.5)
+}
+
+func (w *Widget) renderVertical(c *gowid.Canvas, cols, rows int, fill bool, app gowid.IApp) {
+	height := rows
+	if w.labelWidth() > 0 {
+		height--
+	}
+	if height <= 0 {
+		return
+	}
+	x0 := 0
+	if !w.opts.NoAxis {
+		x0 = w.tickWidth() + 1
+		min, max := w.Range()
+		tickRows := make(map[int]bool)
+		for _, t := range w.ticks(height) {
+			y := height - 1
+			if max > min {
+				y -= int((t-min)/(max-min)*float64(height-1) + 0.5)
+			}
+			tickRows[y] = true
+			s := w.opts.Format(t)
+			drawString(c, x0-1-runewidth.StringWidth(s), y, s, x0-1)
+		}
+		for y := 0; y < height; y++ {
+			r := gowid.ASCIIRune(app, '│', '|')
+			if tickRows[y] {
+				r = gowid.ASCIIRune(app, '┤', '+')
+			}
+			c.SetCellAt(x0-1, y, gowid.CellFromRune(r))
+		}
+	}
+
+	barWidth := w.barWidth(cols-x0, fill)
+	group := len(w.series) * barWidth
+	for i, label := range w.categories {
+		x := x0 + i*(group+w.opts.Gap)
+		if x >= cols {
+			break
+		}
+		drawString(c, x, height, label, gwutil.Min(group, cols-x))
+		for s := range w.series {
+			v, ok := w.value(s, i)
+			if !ok {
+				continue
+			}
+			e := w.eighths(v, height)
+			base := w.cell(s, i, app)
+			for y := 0; y < height; y++ {
+				r := blockRune(verticalBlocks, e-y*8, app)
+				if r == ' ' {
+					continue
+				}
+				for bx := x + s*barWidth; bx < x+(s+1)*barWidth && bx < cols; bx++ {
+					c.SetCellAt(bx, height-1-y, base.WithRune(r))
+				}
+			}
+		}
+	}
+
+	min, max := w.Range()
+	annotation.Draw(c, w.notes, annotation.Axes{
+		Left: x0,
+		Cols: cols - x0,
+		Rows: height,
+		Row:  annotation.Scale(min, max, 0, height, true),
+		Col: func(v float64) int {
+			return x0 + int(math.Round(v*float64(group+w.opts.Gap))) + group/2
+		},
+		Shade: unfilled,
+	}, app)
+}
+
+func (w *Widget) renderHorizontal(c *gowid.Canvas, cols, rows int, fill bool, app gowid.IApp) {
+	height := rows
+	if !w.opts.NoAxis {
+		height--
+	}
+	x0 := w.labelWidth() + 1
+	length := cols - x0 - 1
+	if length <= 0 || height < 0 {
+		return
+	}
+	for y := 0; y < height; y++ {
+		c.SetCellAt(x0, y, gowid.CellFromRune(gowid.ASCIIRune(app, '│', '|')))
+	}
+	if !w.opts.NoAxis && height < rows {
+		c.SetCellAt(x0, height, gowid.CellFromRune(gowid.ASCIIRune(app, '└', '+')))
+		min, max := w.Range()
+		next := x0 + 1
+		for _, t := range w.ticks(length / 8) {
+			s := w.opts.Format(t)
+			x := x0 + 1
+			if max > min {
+				x += int((t-min)/(max-min)*float64(length-1) + 0.5)
+			}
+			x = gwutil.Max(x0+1, gwutil.Min(x-runewidth.StringWidth(s)/2, cols-runewidth.StringWidth(s)))
+			if x < next {
+				continue
+			}
+			drawString(c, x, height, s, cols-x)
+			next = x + runewidth.StringWidth(s) + 1
+		}
+	}
+
+	barWidth := w.barWidth(height, fill)
+	group := len(w.series) * barWidth
+	for i, label := range w.categories {
+		y := i * (group + w.opts.Gap)
+		if y >= height {
+			break
+		}
+		drawString(c, 0, y, label, x0-1)
+		for s := range w.series {
+			v, ok := w.value(s, i)
+			if !ok {
+				continue
+			}
+			e := w.eighths(v, length)
+			base := w.cell(s, i, app)
+			for by := y + s*barWidth; by < y+(s+1)*barWidth && by < height; by++ {
+				for x := 0; x < length; x++ {
+					if r := blockRune(horizontalBlocks, e-x*8, app); r != ' ' {
+						c.SetCellAt(x0+1+x, by, base.WithRune(r))
+					}
+				}
+			}
+		}
+	}
+
+	// The value axis runs across, so lines and regions placed by value run down the chart
+	notes := annotation.Annotations{
+		Lines:   make([]annotation.Line, len(w.notes.Lines)),
+		Regions: make([]annotation.Region, len(w.notes.Regions)),
+	}
+	for i, l := range w.notes.Lines {
+		l.Vertical = !l.Vertical
+		notes.Lines[i] = l
+	}
+	for i, r := range w.notes.Regions {
+		r.Vertical = !r.Vertical
+		notes.Regions[i] = r
+	}
+	min, max := w.Range()
+	annotation.Draw(c, notes, annotation.Axes{
+		Left: x0 + 1,
+		Cols: length,
+		Rows: height,
+		Row: func(v float64) int {
+			return int(math.Round(v*float64(group+w.opts.Gap))) + group/2
+		},
+		Col:   annotation.Scale(min, max, x0+1, length, false),
+		Shade: unfilled,
+	}, app)
+}
+
+// unfilled returns true if no bar is drawn on c, so that annotation regions shade it.
+func unfilled(c gowid.Cell) bool {
+	return !c.HasRune() || c.Rune() == ' '
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// Partial blocks, from an eighth of a cell to a full one
+var (
+	horizontalBlocks = []rune(" ▏▎▍▌▋▊▉█")
+	verticalBlocks   = []rune(" ▁▂▃▄▅▆▇█")
+)
+
+// blockRune returns the block that fills e eighths of a cell, up to a full one. When drawing with ASCII
+// only, a cell is either full or empty.
+func blockRune(blocks []rune, e int, app gowid.IApp) rune {
+	e = gwutil.Max(0, gwutil.Min(8, e))
+	if gowid.ASCIIOnly(app) {
+		if e >= 4 {
+			return '#'
+		}
+		return ' '
+	}
+	return blocks[e]
+}
+
+// drawString draws s at x, y, cut off after width columns.
+func drawString(c *gowid.Canvas, x, y int, s string, width int) {
+	for _, r := range s {
+		rw := gwutil.Max(1, runewidth.RuneWidth(r))
+		if width < rw || x >= c.BoxColumns() || y >= c.BoxRows() || x < 0 {
+			return
+		}
+		c.SetCellAt(x, y, gowid.CellFromRune(r))
+		x += rw
+		width -= rw
+	}
+}
+
+func baseCell(styler gowid.ICellStyler, app gowid.IApp) gowid.Cell {
+	if styler == nil {
+		return gowid.CellFromRune(' ')
+	}
+	fgCol, bgCol, style := styler.GetStyle(app)
+	mode := app.GetColorMode()
+	return gowid.MakeCell(0, gowid.IColorToTCell(fgCol, gowid.ColorNone, mode),
+		gowid.IColorToTCell(bgCol, gowid.ColorNone, mode), style)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,97 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package barchart
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/annotation"
+	"github.com/gcla/gowid/widgets/legend"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestRange1(t *testing.T) {
+	w := New([]string{"a", "b"}, []Series{{Values: []float64{3, 17}}})
+	min, max := w.Range()
+	assert.Equal(t, 0.0, min)
+	assert.Equal(t, 20.0, max)
+	w = New(nil, []Series{{Values: []float64{-3, 0.7}}})
+	min, max = w.Range()
+	assert.Equal(t, -5.0, min)
+	assert.Equal(t, 1.0, max)
+}
+
+func TestVertical1(t *testing.T) {
+	w := New([]string{"ab", "cd"}, []Series{{Values: []float64{2, 4}}}, Options{Height: 3})
+	c := w.Render(gowid.RenderFlowWith{C: 7}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "  5┤  ▅\n  0┤▆ █\n    a c", c.String())
+}
+
+func TestHorizontal1(t *testing.T) {
+	w := New([]string{"ab", "c"}, []Series{{Values: []float64{5, 10}}, {Values: []float64{2.5}}},
+		Options{Horizontal: true})
+	c := w.Render(gowid.RenderFlowWith{C: 12}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, ""+
+		"ab │████    \n"+
+		"   │██      \n"+
+		"   │        \n"+
+		"c  │████████\n"+
+		"   │        \n"+
+		"   └0     10", c.String())
+}
+
+func TestLegend1(t *testing.T) {
+	l := legend.New([]legend.Entry{{Name: "x", Color: gowid.ColorRed}, {Name: "y", Color: gowid.ColorBlue}})
+	w := New([]string{"a", "b"}, []Series{{Values: []float64{2, 4}}, {Values: []float64{10, 10}}},
+		Options{Height: 4, Legend: l})
+	sz := gowid.RenderFlowWith{C: 9}
+	c := w.Render(sz, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "10┤ █  █ \n  │ █ ▂█ \n 0┤▅█ ██ \n   a  b  ", c.String())
+	mode := gwtest.D.GetColorMode()
+	red := gowid.IColorToTCell(gowid.ColorRed, gowid.ColorNone, mode)
+	blue := gowid.IColorToTCell(gowid.ColorBlue, gowid.ColorNone, mode)
+	assert.Equal(t, red, c.CellAt(3, 2).ForegroundColor())
+	assert.Equal(t, blue, c.CellAt(4, 2).ForegroundColor())
+
+	// Highlighting one series dims the others
+	l.SetHighlighted(0, gwtest.D)
+	c = w.Render(sz, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, red, c.CellAt(3, 2).ForegroundColor())
+	assert.Equal(t, gowid.IColorToTCell(legend.DimColor, gowid.ColorNone, mode), c.CellAt(4, 2).ForegroundColor())
+
+	// Hiding one leaves a gap, and the axis fits the others
+	l.SetHighlighted(-1, gwtest.D)
+	l.SetHidden(1, true, gwtest.D)
+	c = w.Render(sz, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "  5┤   ▃ \n   │▂  █ \n  0┤█  █ \n    a  b ", c.String())
+}
+
+func TestAnnotations1(t *testing.T) {
+	w := New([]string{"a", "b"}, []Series{{Values: []float64{2, 4}}}, Options{Height: 4})
+	w.SetAnnotations(annotation.Annotations{
+		Lines: []annotation.Line{{Value: 2.5}, {Value: 0.5, Vertical: true}},
+	}, gwtest.D)
+	c := w.Render(gowid.RenderFlowWith{C: 7}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "  5┤ │▃\n   │▂┼█\n  0┤█│█\n    a b", c.String())
+
+	// In a horizontal chart, a line placed by value runs down it
+	w = New([]string{"a", "b"}, []Series{{Values: []float64{2, 10}}}, Options{Horizontal: true})
+	w.SetAnnotations(annotation.Annotations{Lines: []annotation.Line{{Value: 5}}}, gwtest.D)
+	c = w.Render(gowid.RenderFlowWith{C: 12}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, ""+
+		"a │█▊  │    \n"+
+		"  │    │    \n"+
+		"b │█████████\n"+
+		"  └0      10", c.String())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
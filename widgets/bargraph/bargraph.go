@@ -6,9 +6,13 @@
 package bargraph
 
 import (
+	"math"
+
 	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/annotation"
 	"github.com/gcla/gowid/widgets/columns"
 	"github.com/gcla/gowid/widgets/fill"
+	"github.com/gcla/gowid/widgets/legend"
 	"github.com/gcla/gowid/widgets/overlay"
 	"github.com/gcla/gowid/widgets/pile"
 )
@@ -21,15 +25,23 @@ type IBarGraph interface {
 	GetMax() int
 }
 
+// ILegendBarGraph is implemented by a bar graph whose series - the values at each index of its data -
+// can be hidden or highlighted with a legend.
+type ILegendBarGraph interface {
+	GetLegend() legend.ISeries
+}
+
 type IWidget interface {
 	gowid.IWidget
 	IBarGraph
 }
 
 type Widget struct {
-	Data  [][]int
-	Max   int
-	Attrs []gowid.IColor
+	Data   [][]int
+	Max    int
+	Attrs  []gowid.IColor
+	Legend legend.ISeries // If not nil, the color of each series and whether it is shown
+	Notes  annotation.Annotations
 	gowid.RejectUserInput
 	gowid.NotSelectable
 }
@@ -64,6 +76,26 @@ func (w *Widget) GetMax() int {
 	return w.Max
 }
 
+func (w *Widget) GetLegend() legend.ISeries {
+	return w.Legend
+}
+
+// SetLegend links the bar graph to a legend, e.g. a legend.Widget, which then colors its series and hides
+// or highlights them.
+func (w *Widget) SetLegend(l legend.ISeries, app gowid.IApp) {
+	w.Legend = l
+}
+
+func (w *Widget) GetAnnotations() annotation.Annotations {
+	return w.Notes
+}
+
+// SetAnnotations sets the reference lines and regions drawn over the bars. Horizontal lines and regions
+// are placed by value, from 0 to the graph's max; vertical ones by the index of a bar.
+func (w *Widget) SetAnnotations(notes annotation.Annotations, app gowid.IApp) {
+	w.Notes = notes
+}
+
 func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
 	return RenderSize(w, size, focus, app)
 }
@@ -93,12 +125,21 @@ func Render(w IBarGraph, size gowid.IRenderSize, focus gowid.Selector, app gowid
 		dataIdxLimit = len(w.GetData()[0])
 	}
 
-	dataWidgets := make([]*columns.Widget, dataIdxLimit)
+	var series legend.ISeries
+	if lw, ok := w.(ILegendBarGraph); ok {
+		series = lw.GetLegend()
+	}
+
+	dataWidgets := make([]*columns.Widget, 0, dataIdxLimit)
 	for dataIdx := 0; dataIdx < dataIdxLimit; dataIdx++ {
+		if legend.SeriesHidden(series, dataIdx) {
+			continue
+		}
 		cols := make([]gowid.IContainerWidget, len(w.GetData()))
 		for i, d := range w.GetData() {
 			datum := d[dataIdx]
-			dataTCellColor := gowid.IColorToTCell(w.GetAttrs()[(i%(len(w.GetAttrs())-1))+1], gowid.ColorDefault, app.GetColorMode())
+			dataColor := legend.SeriesColor(series, dataIdx, w.GetAttrs()[(i%(len(w.GetAttrs())-1))+1])
+			dataTCellColor := gowid.IColorToTCell(dataColor, gowid.ColorDefault, app.GetColorMode())
 
 			bar := pile.New([]gowid.IContainerWidget{
 				&gowid.ContainerWidget{
@@ -121,7 +162,7 @@ func Render(w IBarGraph, size gowid.IRenderSize, focus gowid.Selector, app gowid
 
 			cols[i] = &gowid.ContainerWidget{bar, weight1}
 		}
-		dataWidgets[dataIdx] = columns.New(cols)
+		dataWidgets = append(dataWidgets, columns.New(cols))
 	}
 
 	var res gowid.IWidget = fill.NewSolidFromCell(
@@ -141,7 +182,30 @@ func Render(w IBarGraph, size gowid.IRenderSize, focus gowid.Selector, app gowid
 		)
 	}
 
-	return res.Render(size, focus, app)
+	canvas := res.Render(size, focus, app)
+	if aw, ok := w.(annotation.IAnnotated); ok {
+		cols, rows := canvas.BoxColumns(), canvas.BoxRows()
+		annotation.Draw(canvas, aw.GetAnnotations(), annotation.Axes{
+			Cols: cols,
+			Rows: rows,
+			Row: func(v float64) int {
+				if w.GetMax() == 0 {
+					return rows
+				}
+				return rows - int(math.Round(v*float64(rows)/float64(w.GetMax())))
+			},
+			Col: func(v float64) int {
+				if len(w.GetData()) == 0 {
+					return 0
+				}
+				return int(math.Round(v * float64(cols) / float64(len(w.GetData()))))
+			},
+			Shade: func(c gowid.Cell) bool {
+				return c.BackgroundColor() == bgTCellColor
+			},
+		}, app)
+	}
+	return canvas
 }
 
 //======================================================================
@@ -90,6 +90,7 @@ type Widget struct {
 	*Decoration
 	gowid.AddressProvidesID
 	gowid.IsSelectable
+	gowid.Hoverable
 }
 
 func New(inner gowid.IWidget, opts ...Options) *Widget {
@@ -141,6 +142,15 @@ func (w *Widget) String() string {
 	return fmt.Sprintf("button[%v]", w.SubWidget())
 }
 
+func (w *Widget) AccessibleRole() gowid.Role {
+	return gowid.RoleButton
+}
+
+// AccessibleLabel returns "", so the button is labelled with the text it displays.
+func (w *Widget) AccessibleLabel(app gowid.IApp) string {
+	return ""
+}
+
 func (w *Widget) Click(app gowid.IApp) {
 	// No button clicked means a key was pressed
 	if app.GetMouseState().NoButtonClicked() || app.GetMouseState().LeftIsClicked() {
@@ -223,6 +233,9 @@ type IClickableIdentityWidget interface {
 }
 
 func UserInput(w IClickableIdentityWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if hw, ok := w.(gowid.IHoverable); ok {
+		gowid.TrackHover(hw, ev, app)
+	}
 	res := false
 	switch ev := ev.(type) {
 	case *tcell.EventMouse:
@@ -270,7 +283,7 @@ func UserInput(w IClickableIdentityWidget, ev interface{}, size gowid.IRenderSiz
 func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
 	newSize := w.SubWidgetSize(size, focus, app)
 
-	res := w.SubWidget().Render(newSize, focus, app)
+	res := gowid.Render(w.SubWidget(), newSize, focus, app)
 	leftClicker := gowid.CellsFromString(w.LeftDec())
 	rightClicker := gowid.CellsFromString(w.RightDec())
 	res.ExtendLeft(leftClicker)
@@ -0,0 +1,344 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package calendar provides a month-view calendar for choosing a date, or a range of dates, and a
+// compact date picker that opens one as a popup.
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// Width and Height are the size of the calendar, as drawn.
+const (
+	Width  = 20
+	Height = 8
+)
+
+var (
+	// DefaultDayNames head the columns of days, unless Options says otherwise. Sunday is first.
+	DefaultDayNames = [7]string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"}
+	// DefaultMonthNames head each month, unless Options says otherwise. January is first.
+	DefaultMonthNames = [12]string{"January", "February", "March", "April", "May", "June", "July",
+		"August", "September", "October", "November", "December"}
+)
+
+// SelectCB is the callback name used when a date, or a range of dates, is chosen.
+type SelectCB struct{}
+
+// Options is used to configure the calendar.
+type Options struct {
+	FirstDay      time.Weekday      // The day of the week in the first column; Sunday unless set
+	DayNames      [7]string         // Heads the columns, Sunday first, cut to two columns; defaults to DefaultDayNames
+	MonthNames    [12]string        // January first; defaults to DefaultMonthNames
+	Range         bool              // If true, two dates are chosen - the first and last of a range
+	CursorStyle   gowid.ICellStyler // The day with the cursor, if the calendar has the focus; defaults to reverse video
+	SelectedStyle gowid.ICellStyler // The chosen days; defaults to bold and underlined
+	TodayStyle    gowid.ICellStyler // Today; defaults to bold
+}
+
+func (o Options) withDefaults() Options {
+	if o.DayNames == ([7]string{}) {
+		o.DayNames = DefaultDayNames
+	}
+	if o.MonthNames == ([12]string{}) {
+		o.MonthNames = DefaultMonthNames
+	}
+	if o.CursorStyle == nil {
+		o.CursorStyle = gowid.MakeStyledAs(gowid.StyleReverse)
+	}
+	if o.SelectedStyle == nil {
+		o.SelectedStyle = gowid.MakeStyledAs(gowid.StyleBold.MergeUnder(gowid.StyleUnderline))
+	}
+	if o.TodayStyle == nil {
+		o.TodayStyle = gowid.MakeStyledAs(gowid.StyleBold)
+	}
+	return o
+}
+
+// Widget shows the month of the day with the cursor: a header with the month's name between arrows, the
+// names of the days, then six rows of weeks. The arrow keys move the cursor by a day or a week, PgUp and
+// PgDn by a month, and Home and End to the first and last days of the month; Enter or Space chooses the
+// day with the cursor. A click on a day chooses it, and a click on an arrow shows the month before or
+// after. With Options.Range, the first day chosen starts a range and the second ends it - until then,
+// the range is shown running to the cursor.
+type Widget struct {
+	cursor   time.Time // The day with the cursor, at midnight
+	from, to time.Time // The days chosen, in order; zero if none are
+	pending  bool      // True if the first day of a range has been chosen, but not the last
+	opts     Options
+	*gowid.Callbacks
+	gowid.IsSelectable
+}
+
+// New returns a calendar with the cursor on the day of t, and no day chosen.
+func New(t time.Time, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	res := &Widget{
+		cursor:    day(t),
+		opts:      opt.withDefaults(),
+		Callbacks: gowid.NewCallbacks(),
+	}
+	var _ gowid.IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("calendar[%s]", w.cursor.Format("2006-01-02"))
+}
+
+// Cursor returns the day with the cursor, at midnight.
+func (w *Widget) Cursor() time.Time {
+	return w.cursor
+}
+
+// SetCursor moves the cursor to the day of t, showing its month.
+func (w *Widget) SetCursor(t time.Time, app gowid.IApp) {
+	w.cursor = day(t)
+}
+
+// Selected returns the day chosen, or the first day of the range chosen, and false if none is.
+func (w *Widget) Selected() (time.Time, bool) {
+	if w.from.IsZero() || w.pending {
+		return time.Time{}, false
+	}
+	return w.from, true
+}
+
+// SetSelected chooses the day of t, moving the cursor to it, without running the callbacks.
+func (w *Widget) SetSelected(t time.Time, app gowid.IApp) {
+	w.SetRange(t, t, app)
+}
+
+// Range returns the first and last days of the range chosen - the same day, if only one is - and false
+// if none is.
+func (w *Widget) Range() (time.Time, time.Time, bool) {
+	if w.from.IsZero() || w.pending {
+		return time.Time{}, time.Time{}, false
+	}
+	return w.from, w.to, true
+}
+
+// SetRange chooses the days from from to to, moving the cursor to to, without running the callbacks.
+func (w *Widget) SetRange(from, to time.Time, app gowid.IApp) {
+	from, to = day(from), day(to)
+	if to.Before(from) {
+		from, to = to, from
+	}
+	w.from, w.to, w.pending = from, to, false
+	w.cursor = to
+}
+
+// ClearSelection leaves no day chosen.
+func (w *Widget) ClearSelection(app gowid.IApp) {
+	w.from, w.to, w.pending = time.Time{}, time.Time{}, false
+}
+
+// OnSelect registers a callback run when the user chooses a day - or, with Options.Range, the last day of
+// a range.
+func (w *Widget) OnSelect(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w.Callbacks, SelectCB{}, f)
+}
+
+func (w *Widget) RemoveOnSelect(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w.Callbacks, SelectCB{}, f)
+}
+
+// choose chooses the day with the cursor, as the user would.
+func (w *Widget) choose(app gowid.IApp) {
+	switch {
+	case !w.opts.Range:
+		w.SetSelected(w.cursor, app)
+	case !w.pending:
+		w.from, w.to, w.pending = w.cursor, w.cursor, true
+		return
+	default:
+		w.SetRange(w.from, w.cursor, app)
+	}
+	gowid.RunWidgetCallbacks(w.Callbacks, SelectCB{}, app, w)
+}
+
+// moveMonths moves the cursor by n months, keeping its day of the month where it can.
+func (w *Widget) moveMonths(n int) {
+	y, m, d := w.cursor.Date()
+	first := time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, w.cursor.Location())
+	w.cursor = first.AddDate(0, 0, gwutil.Min(d, daysIn(first))-1)
+}
+
+// firstShown returns the day drawn in the first column of the first week of the month shown.
+func (w *Widget) firstShown() time.Time {
+	y, m, _ := w.cursor.Date()
+	first := time.Date(y, m, 1, 0, 0, 0, 0, w.cursor.Location())
+	return first.AddDate(0, 0, -((int(first.Weekday()) - int(w.opts.FirstDay) + 7) % 7))
+}
+
+// dayAt returns the day of the month shown drawn at x, y, and false if there is none.
+func (w *Widget) dayAt(x, y int) (time.Time, bool) {
+	if y < 2 || y >= Height || x < 0 || x >= Width || x%3 == 2 {
+		return time.Time{}, false
+	}
+	d := w.firstShown().AddDate(0, 0, (y-2)*7+x/3)
+	if d.Month() != w.cursor.Month() {
+		return time.Time{}, false
+	}
+	return d, true
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	switch sz := size.(type) {
+	case gowid.IRenderBox:
+		return gowid.RenderBox{C: sz.BoxColumns(), R: sz.BoxRows()}
+	case gowid.IRenderFlowWith:
+		return gowid.RenderBox{C: sz.FlowColumns(), R: Height}
+	default:
+		return gowid.RenderBox{C: Width, R: Height}
+	}
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	box := w.RenderSize(size, focus, app)
+	res := gowid.NewCanvasOfSize(box.BoxColumns(), box.BoxRows())
+	plain := gowid.CellFromRune(' ')
+
+	title := fmt.Sprintf("%s %d", w.opts.MonthNames[w.cursor.Month()-1], w.cursor.Year())
+	drawString(res, 0, 0, "<", plain)
+	drawString(res, (Width-len([]rune(title)))/2, 0, title, plain)
+	drawString(res, Width-1, 0, ">", plain)
+
+	for i := 0; i < 7; i++ {
+		name := []rune(w.opts.DayNames[(int(w.opts.FirstDay)+i)%7])
+		drawString(res, i*3, 1, string(name[:gwutil.Min(2, len(name))]), plain)
+	}
+
+	from, to := w.from, w.to
+	if w.pending {
+		from, to = w.from, w.cursor
+		if to.Before(from) {
+			from, to = to, from
+		}
+	}
+	today := day(gowid.ClockFor(app).Now().In(w.cursor.Location()))
+	d := w.firstShown()
+	for i := 0; i < 42; i++ {
+		if d.Month() == w.cursor.Month() {
+			cell := plain
+			if d.Equal(today) {
+				cell = styleCell(w.opts.TodayStyle, app)
+			}
+			if !from.IsZero() && !d.Before(from) && !d.After(to) {
+				cell = cell.MergeDisplayAttrsUnder(styleCell(w.opts.SelectedStyle, app))
+			}
+			if focus.Focus && d.Equal(w.cursor) {
+				cell = cell.MergeDisplayAttrsUnder(styleCell(w.opts.CursorStyle, app))
+			}
+			drawString(res, (i%7)*3, 2+i/7, fmt.Sprintf("%2d", d.Day()), cell)
+		}
+		d = d.AddDate(0, 0, 1)
+	}
+
+	return res
+}
+
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		switch ev.Key() {
+		case tcell.KeyLeft:
+			w.cursor = w.cursor.AddDate(0, 0, -1)
+		case tcell.KeyRight:
+			w.cursor = w.cursor.AddDate(0, 0, 1)
+		case tcell.KeyUp:
+			w.cursor = w.cursor.AddDate(0, 0, -7)
+		case tcell.KeyDown:
+			w.cursor = w.cursor.AddDate(0, 0, 7)
+		case tcell.KeyPgUp:
+			w.moveMonths(-1)
+		case tcell.KeyPgDn:
+			w.moveMonths(1)
+		case tcell.KeyHome:
+			w.cursor = w.cursor.AddDate(0, 0, 1-w.cursor.Day())
+		case tcell.KeyEnd:
+			w.cursor = w.cursor.AddDate(0, 0, daysIn(w.cursor)-w.cursor.Day())
+		case tcell.KeyEnter:
+			w.choose(app)
+		case tcell.KeyRune:
+			if ev.Rune() != ' ' {
+				return false
+			}
+			w.choose(app)
+		default:
+			return false
+		}
+		return true
+	case *tcell.EventMouse:
+		x, y := ev.Position()
+		if x < 0 || x >= Width || y < 0 || y >= Height {
+			return false
+		}
+		if ev.Buttons() == tcell.ButtonNone && app.GetLastMouseState().LeftIsClicked() {
+			switch {
+			case y == 0 && x < 2:
+				w.moveMonths(-1)
+			case y == 0 && x >= Width-2:
+				w.moveMonths(1)
+			default:
+				if d, ok := w.dayAt(x, y); ok {
+					w.cursor = d
+					w.choose(app)
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// day returns midnight at the start of t's day.
+func day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// daysIn returns the number of days in t's month.
+func daysIn(t time.Time) int {
+	y, m, _ := t.Date()
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
+
+func drawString(c gowid.ICanvas, x, y int, s string, base gowid.Cell) {
+	if y >= c.BoxRows() {
+		return
+	}
+	for _, r := range s {
+		if x >= 0 && x < c.BoxColumns() {
+			c.SetCellAt(x, y, base.WithRune(r))
+		}
+		x++
+	}
+}
+
+func styleCell(styler gowid.ICellStyler, app gowid.IApp) gowid.Cell {
+	fgCol, bgCol, style := styler.GetStyle(app)
+	mode := app.GetColorMode()
+	return gowid.MakeCell(' ', gowid.IColorToTCell(fgCol, gowid.ColorNone, mode),
+		gowid.IColorToTCell(bgCol, gowid.ColorNone, mode), style)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,135 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+var today = time.Date(2026, time.October, 16, 15, 4, 5, 0, time.UTC)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCalendar1(t *testing.T) {
+	w := New(today, Options{FirstDay: time.Monday})
+	chosen := 0
+	w.OnSelect(gowid.WidgetCallback{Name: "test", WidgetChangedFunction: func(app gowid.IApp, w gowid.IWidget) {
+		chosen++
+	}})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 20, Rows: 8, Clock: gowid.NewFakeClock(today)})
+	defer sim.Close()
+
+	sim.AssertLine(t, 0, "<   October 2026   >")
+	sim.AssertLine(t, 1, "Mo Tu We Th Fr Sa Su")
+	sim.AssertLine(t, 2, "          1  2  3  4")
+	sim.AssertLine(t, 3, " 5  6  7  8  9 10 11")
+	sim.AssertLine(t, 6, "26 27 28 29 30 31   ")
+	assert.Equal(t, date(2026, time.October, 16), w.Cursor())
+
+	sim.Key(tcell.KeyDown)
+	sim.Key(tcell.KeyPgDn)
+	assert.Equal(t, date(2026, time.November, 23), w.Cursor())
+	sim.AssertLine(t, 0, "<  November 2026   >")
+	sim.Key(tcell.KeyEnter)
+	d, ok := w.Selected()
+	assert.True(t, ok)
+	assert.Equal(t, date(2026, time.November, 23), d)
+	assert.Equal(t, 1, chosen)
+
+	sim.Key(tcell.KeyEnd)
+	assert.Equal(t, date(2026, time.November, 30), w.Cursor())
+	sim.Key(tcell.KeyHome)
+	assert.Equal(t, date(2026, time.November, 1), w.Cursor())
+
+	// The day of the month is kept where it can be
+	w.SetCursor(date(2026, time.March, 31), sim.App)
+	sim.Key(tcell.KeyPgUp)
+	assert.Equal(t, date(2026, time.February, 28), w.Cursor())
+
+	// Clicks on the arrows change the month; a click on a day chooses it
+	sim.Click(19, 0, tcell.Button1)
+	sim.AssertLine(t, 0, "<    March 2026    >")
+	sim.Click(3, 3, tcell.Button1)
+	d, _ = w.Selected()
+	assert.Equal(t, date(2026, time.March, 3), d)
+	assert.Equal(t, 2, chosen)
+}
+
+func TestRange1(t *testing.T) {
+	w := New(today, Options{Range: true})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 20, Rows: 8, Clock: gowid.NewFakeClock(today)})
+	defer sim.Close()
+
+	sim.AssertLine(t, 1, "Su Mo Tu We Th Fr Sa")
+	sim.Key(tcell.KeyEnter)
+	_, _, ok := w.Range()
+	assert.False(t, ok)
+	sim.Key(tcell.KeyLeft)
+	sim.Key(tcell.KeyLeft)
+	sim.Rune(' ')
+	from, to, ok := w.Range()
+	assert.True(t, ok)
+	assert.Equal(t, date(2026, time.October, 14), from)
+	assert.Equal(t, date(2026, time.October, 16), to)
+}
+
+func TestPicker1(t *testing.T) {
+	p := NewPicker(PickerOptions{Placeholder: "none"})
+	changed := 0
+	p.OnChange(gowid.WidgetCallback{Name: "test", WidgetChangedFunction: func(app gowid.IApp, w gowid.IWidget) {
+		changed++
+	}})
+	root := pile.New([]gowid.IContainerWidget{
+		&gowid.ContainerWidget{IWidget: p, D: gowid.RenderFlow{}},
+		&gowid.ContainerWidget{IWidget: text.New("below"), D: gowid.RenderWithWeight{W: 1}},
+	})
+	sim := gwtest.NewSimT(t, root, gwtest.SimOptions{Cols: 30, Rows: 12, Clock: gowid.NewFakeClock(today)})
+	defer sim.Close()
+
+	sim.AssertLine(t, 0, "none ▾                        ")
+	assert.Equal(t, "", p.Value())
+
+	// Enter pops up the calendar beneath the picker, on today
+	sim.Key(tcell.KeyEnter)
+	assert.True(t, p.IsOpen())
+	sim.AssertLine(t, 1, "┏━━━━━━━━━━━━━━━━━━━━┓        ")
+	sim.AssertLine(t, 2, "┃<   October 2026   >┃        ")
+	sim.Key(tcell.KeyRight)
+	sim.Key(tcell.KeyEnter)
+	assert.False(t, p.IsOpen())
+	assert.Equal(t, "2026-10-17", p.Value())
+	assert.Equal(t, 1, changed)
+	sim.AssertLine(t, 0, "2026-10-17 ▾                  ")
+	sim.AssertLine(t, 1, "below                         ")
+
+	// Esc, or a click outside, closes it without changing the date
+	sim.Key(tcell.KeyEnter)
+	sim.Key(tcell.KeyRight)
+	sim.Key(tcell.KeyEsc)
+	assert.False(t, p.IsOpen())
+	sim.Click(0, 0, tcell.Button1)
+	assert.True(t, p.IsOpen())
+	sim.Click(28, 11, tcell.Button1)
+	assert.False(t, p.IsOpen())
+	assert.Equal(t, "2026-10-17", p.Value())
+	assert.Equal(t, 1, changed)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,220 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/framed"
+	"github.com/gcla/gowid/widgets/menu"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// DefaultFormat is how a picker shows its date, unless PickerOptions says otherwise.
+var DefaultFormat = "2006-01-02"
+
+// ChangeCB is the callback name used when a picker's date is changed by the user.
+type ChangeCB struct{}
+
+// PickerOptions is used to configure a picker.
+type PickerOptions struct {
+	Calendar    Options // Configures the calendar that pops up; Range is ignored
+	Format      string  // The layout of the date shown, as for time.Format; defaults to DefaultFormat
+	Placeholder string  // Shown while no date is chosen
+}
+
+// Picker is a one-line field showing a date, for forms. Enter, Space, Down or a click pops up a
+// calendar beneath it, which grabs the input until a day is chosen, or Esc or a click outside it closes
+// it. The popup is drawn as a menu over the whole app - see package menu - so the picker can be anywhere.
+// Its Value is the date shown, so a form understands it.
+type Picker struct {
+	gowid.IWidget
+	cal   *Widget
+	popup *menu.Widget
+	site  *menu.SiteWidget
+	label *text.Widget
+	date  time.Time // Zero if no date is chosen
+	opts  PickerOptions
+	*gowid.Callbacks
+}
+
+// NewPicker returns a picker with no date chosen.
+func NewPicker(opts ...PickerOptions) *Picker {
+	var opt PickerOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Format == "" {
+		opt.Format = DefaultFormat
+	}
+	opt.Calendar.Range = false
+
+	res := &Picker{
+		label:     text.New(""),
+		site:      menu.NewSite(menu.SiteOptions{YOffset: 1}),
+		opts:      opt,
+		Callbacks: gowid.NewCallbacks(),
+	}
+	res.cal = New(time.Time{}, opt.Calendar)
+	res.cal.OnSelect(gowid.WidgetCallback{Name: "picker", WidgetChangedFunction: func(app gowid.IApp, w gowid.IWidget) {
+		res.chosen(app)
+	}})
+	res.popup = menu.New(fmt.Sprintf("datepicker-%p", res), framed.NewUnicode(res.cal),
+		gowid.RenderWithUnits{U: Width + 2})
+	res.IWidget = columns.New([]gowid.IContainerWidget{
+		&gowid.ContainerWidget{IWidget: res.site, D: gowid.RenderFixed{}},
+		&gowid.ContainerWidget{IWidget: res.label, D: gowid.RenderWithWeight{W: 1}},
+	})
+	res.setLabel(nil)
+	return res
+}
+
+func (w *Picker) String() string {
+	return fmt.Sprintf("datepicker[%s]", w.Value())
+}
+
+// Date returns the date chosen, and false if none is.
+func (w *Picker) Date() (time.Time, bool) {
+	return w.date, !w.date.IsZero()
+}
+
+// SetDate chooses the day of t, or no date if t is zero, without running the callbacks.
+func (w *Picker) SetDate(t time.Time, app gowid.IApp) {
+	if t.IsZero() {
+		w.date = time.Time{}
+	} else {
+		w.date = day(t)
+	}
+	w.setLabel(app)
+}
+
+// Value returns the date chosen, as shown, or "" if none is.
+func (w *Picker) Value() string {
+	if w.date.IsZero() {
+		return ""
+	}
+	return w.date.Format(w.opts.Format)
+}
+
+// Calendar returns the calendar that pops up.
+func (w *Picker) Calendar() *Widget {
+	return w.cal
+}
+
+// OnChange registers a callback run when the user chooses a date.
+func (w *Picker) OnChange(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w.Callbacks, ChangeCB{}, f)
+}
+
+func (w *Picker) RemoveOnChange(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w.Callbacks, ChangeCB{}, f)
+}
+
+// IsOpen returns true if the calendar has popped up.
+func (w *Picker) IsOpen() bool {
+	return w.popup.IsOpen()
+}
+
+// Open pops up the calendar, with the cursor on the date chosen, or today.
+func (w *Picker) Open(app gowid.IApp) {
+	if w.IsOpen() {
+		return
+	}
+	if w.date.IsZero() {
+		w.cal.SetCursor(gowid.ClockFor(app).Now(), app)
+		w.cal.ClearSelection(app)
+	} else {
+		w.cal.SetSelected(w.date, app)
+	}
+	app.RegisterMenu(w.popup)
+	w.popup.Open(w.site, app)
+	gowid.GrabInput(app, w.cal, gowid.GrabOptions{
+		OnRelease: func(app gowid.IApp) {
+			w.closed(app)
+		},
+	})
+}
+
+// Close closes the calendar, if it has popped up, leaving the date as it was.
+func (w *Picker) Close(app gowid.IApp) {
+	if !w.IsOpen() {
+		return
+	}
+	if g, ok := app.(iGrabbedBy); ok && g.InputGrabbedBy() == gowid.IWidget(w.cal) {
+		// Closes the popup, from the grab's OnRelease
+		gowid.ReleaseInput(app)
+	}
+	w.closed(app)
+}
+
+// closed takes the popup down, when its grab is released.
+func (w *Picker) closed(app gowid.IApp) {
+	if !w.IsOpen() {
+		return
+	}
+	w.popup.Close(app)
+	app.UnregisterMenu(w.popup)
+}
+
+// chosen takes the day chosen in the calendar.
+func (w *Picker) chosen(app gowid.IApp) {
+	if d, ok := w.cal.Selected(); ok {
+		w.date = d
+		w.setLabel(app)
+	}
+	w.Close(app)
+	gowid.RunWidgetCallbacks(w.Callbacks, ChangeCB{}, app, w)
+}
+
+func (w *Picker) setLabel(app gowid.IApp) {
+	s := w.Value()
+	if s == "" {
+		s = w.opts.Placeholder
+	}
+	w.label.SetText(s+" ▾", app)
+}
+
+func (w *Picker) Selectable() bool {
+	return true
+}
+
+func (w *Picker) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		switch ev.Key() {
+		case tcell.KeyEnter, tcell.KeyDown:
+			w.Open(app)
+			return true
+		case tcell.KeyRune:
+			if ev.Rune() == ' ' {
+				w.Open(app)
+				return true
+			}
+		}
+	case *tcell.EventMouse:
+		if ev.Buttons() == tcell.ButtonNone && app.GetLastMouseState().LeftIsClicked() {
+			w.Open(app)
+		}
+		return true
+	}
+	return false
+}
+
+// iGrabbedBy is implemented by an app that tells which widget has grabbed input, like gowid.App.
+type iGrabbedBy interface {
+	InputGrabbedBy() gowid.IWidget
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -93,6 +93,22 @@ func (w *Widget) setChecked(app gowid.IApp, val bool) {
 	gowid.RunWidgetCallbacks(*w.CB, gowid.ClickCB{}, app, w)
 }
 
+func (w *Widget) AccessibleRole() gowid.Role {
+	return gowid.RoleCheckBox
+}
+
+func (w *Widget) AccessibleLabel(app gowid.IApp) string {
+	return ""
+}
+
+// AccessibleState returns "checked" or "unchecked".
+func (w *Widget) AccessibleState(app gowid.IApp) string {
+	if w.IsChecked() {
+		return "checked"
+	}
+	return "unchecked"
+}
+
 func (w *Widget) Click(app gowid.IApp) {
 	if app.GetMouseState().NoButtonClicked() || app.GetMouseState().LeftIsClicked() {
 		w.setChecked(app, !w.IsChecked())
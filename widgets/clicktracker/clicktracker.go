@@ -47,6 +47,7 @@ func New(inner gowid.IWidget) *Widget {
 
 	var _ gowid.IWidget = res
 	var _ IWidget = res
+	var _ gowid.IStyleStater = res
 
 	return res
 }
@@ -63,6 +64,14 @@ func (w *Widget) SetClickPending(pending bool) {
 	w.clickDown = pending
 }
 
+// StyleState lets an enclosing styled widget use its active style while the click is pending.
+func (w *Widget) StyleState() gowid.StyleState {
+	if w.clickDown {
+		return gowid.StateActive
+	}
+	return 0
+}
+
 func (w *Widget) SubWidget() gowid.IWidget {
 	return w.inner
 }
@@ -114,7 +123,7 @@ func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Se
 }
 
 func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
-	res := w.SubWidget().Render(gowid.SubWidgetSize(w, size, focus, app), focus, app)
+	res := gowid.Render(w.SubWidget(), gowid.SubWidgetSize(w, size, focus, app), focus, app)
 
 	if w.ClickPending() {
 		gowid.RangeOverCanvas(res, gowid.CellRangeFunc(func(c gowid.Cell) gowid.Cell {
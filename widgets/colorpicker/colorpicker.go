@@ -0,0 +1,469 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package colorpicker provides a widget for choosing a color from the terminal's 16 or 256 color
+// palette, or, on a truecolor terminal, by its red, green and blue components.
+package colorpicker
+
+import (
+	"fmt"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// DefaultModeKey switches the picker to its next mode, unless Options says otherwise.
+var DefaultModeKey gowid.IKey = gowid.MakeKey('m')
+
+// Width is the number of columns the picker needs.
+const Width = 32
+
+// Mode is how the picker offers colors.
+type Mode int
+
+const (
+	Palette16  Mode = iota // The 16 ANSI colors
+	Palette256             // The 256 colors of the xterm palette
+	RGB                    // Sliders for red, green and blue, for truecolor terminals
+)
+
+var modeNames = []string{"16", "256", "RGB"}
+
+func (m Mode) String() string {
+	if m < 0 || int(m) >= len(modeNames) {
+		return fmt.Sprintf("mode(%d)", int(m))
+	}
+	return modeNames[m]
+}
+
+// ChangeCB is the callback name used when the color under the cursor changes, e.g. for a live preview.
+type ChangeCB struct{}
+
+// ChooseCB is the callback name used when a color is chosen, with Enter, Space or a click.
+type ChooseCB struct{}
+
+// Options is used to configure the picker.
+type Options struct {
+	Mode    Mode       // The mode to start in
+	ModeKey gowid.IKey // Switches to the next mode; defaults to DefaultModeKey
+}
+
+// Widget is the color picker. Its first row shows the modes, and its last a swatch of the color with
+// the cursor, with the color's palette index or RGB value. In the palette modes, the arrow keys move the
+// cursor around a grid of swatches; in RGB mode, Up and Down choose a component, Left and Right change it
+// by 1, and PgUp and PgDn by 16. The mode key, or a click on a mode's name, changes mode - RGB mode is
+// only offered if the app is drawing with 24-bit color. Enter, Space or a click on a swatch chooses the
+// color, running the ChooseCB callbacks; ChangeCB callbacks run whenever the color with the cursor
+// changes.
+type Widget struct {
+	mode    Mode
+	index   int    // The palette index with the cursor
+	rgb     [3]int // The red, green and blue components, in RGB mode
+	channel int    // The component being changed, in RGB mode
+	opts    Options
+	*gowid.Callbacks
+	gowid.IsSelectable
+}
+
+func New(opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.ModeKey == nil {
+		opt.ModeKey = DefaultModeKey
+	}
+	res := &Widget{
+		mode:      opt.Mode,
+		opts:      opt,
+		Callbacks: gowid.NewCallbacks(),
+	}
+	var _ gowid.IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("colorpicker[%v]", w.Color())
+}
+
+// Mode returns the picker's mode.
+func (w *Widget) Mode() Mode {
+	return w.mode
+}
+
+// SetMode changes the picker's mode. Moving to RGB mode starts from the palette color with the cursor.
+func (w *Widget) SetMode(mode Mode, app gowid.IApp) {
+	if mode == RGB && w.mode != RGB {
+		w.rgb = PaletteRGB(w.index)
+	}
+	if mode == Palette16 && w.index >= 16 {
+		w.index = 0
+	}
+	w.mode = mode
+}
+
+// Color returns the color with the cursor - a gowid.TCellColor for a palette color, or a gowid.RGBColor.
+func (w *Widget) Color() gowid.IColor {
+	if w.mode == RGB {
+		return gowid.MakeRGBColorExt(w.rgb[0], w.rgb[1], w.rgb[2])
+	}
+	return gowid.MakeTCellColorExt(tcell.Color(w.index))
+}
+
+// SetColor moves the cursor to c, changing mode to suit it. An RGBColor puts the picker in RGB mode;
+// anything else is shown as the closest color of the 256 color palette.
+func (w *Widget) SetColor(c gowid.IColor, app gowid.IApp) {
+	if rgb, ok := c.(gowid.RGBColor); ok {
+		w.mode = RGB
+		w.rgb = [3]int{rgb.Red, rgb.Green, rgb.Blue}
+		return
+	}
+	tc, ok := c.ToTCellColor(gowid.Mode256Colors)
+	if !ok || tc.ToTCell() < 0 || tc.ToTCell() > 255 {
+		return
+	}
+	w.index = int(tc.ToTCell())
+	if w.index < 16 {
+		w.mode = Palette16
+	} else {
+		w.mode = Palette256
+	}
+}
+
+// OnChange registers a callback run when the color with the cursor changes.
+func (w *Widget) OnChange(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w.Callbacks, ChangeCB{}, f)
+}
+
+func (w *Widget) RemoveOnChange(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w.Callbacks, ChangeCB{}, f)
+}
+
+// OnChoose registers a callback run when the user chooses a color.
+func (w *Widget) OnChoose(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w.Callbacks, ChooseCB{}, f)
+}
+
+func (w *Widget) RemoveOnChoose(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w.Callbacks, ChooseCB{}, f)
+}
+
+// modes returns the modes offered in app.
+func (w *Widget) modes(app gowid.IApp) []Mode {
+	if app.GetColorMode() == gowid.Mode24BitColors {
+		return []Mode{Palette16, Palette256, RGB}
+	}
+	return []Mode{Palette16, Palette256}
+}
+
+// gridSize returns the number of swatches across and down in a palette mode, and how wide each is.
+func (w *Widget) gridSize() (int, int, int) {
+	if w.mode == Palette16 {
+		return 8, 2, 4
+	}
+	return 16, 16, 2
+}
+
+// bodyRows returns the number of rows between the modes and the preview.
+func (w *Widget) bodyRows() int {
+	if w.mode == RGB {
+		return 3
+	}
+	_, rows, _ := w.gridSize()
+	return rows
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	rows := w.bodyRows() + 3
+	switch sz := size.(type) {
+	case gowid.IRenderBox:
+		return gowid.RenderBox{C: sz.BoxColumns(), R: sz.BoxRows()}
+	case gowid.IRenderFlowWith:
+		return gowid.RenderBox{C: sz.FlowColumns(), R: rows}
+	default:
+		return gowid.RenderBox{C: Width, R: rows}
+	}
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	box := w.RenderSize(size, focus, app)
+	res := gowid.NewCanvasOfSize(box.BoxColumns(), box.BoxRows())
+	plain := gowid.CellFromRune(' ')
+	chosen := plain.WithStyle(gowid.StyleReverse)
+
+	x := 0
+	for _, m := range w.modes(app) {
+		cell := plain
+		if m == w.mode {
+			cell = chosen
+		}
+		x = drawString(res, x, 0, " "+m.String()+" ", cell) + 1
+	}
+
+	if w.mode == RGB {
+		for i, name := range []string{"R", "G", "B"} {
+			y := 1 + i
+			cell := plain
+			if i == w.channel && focus.Focus {
+				cell = chosen
+			}
+			drawString(res, 0, y, name, cell)
+			filled := (w.rgb[i]*barWidth + 127) / 255
+			for j := 0; j < barWidth; j++ {
+				r := '░'
+				if j < filled {
+					r = '█'
+				}
+				drawString(res, 2+j, y, string(r), plain)
+			}
+			drawString(res, 3+barWidth, y, fmt.Sprintf("%3d", w.rgb[i]), plain)
+		}
+	} else {
+		cols, rows, sw := w.gridSize()
+		for i := 0; i < cols*rows; i++ {
+			cell := swatch(i)
+			if i == w.index && focus.Focus {
+				cell = cell.WithForegroundColor(contrast(PaletteRGB(i)))
+				drawString(res, (i%cols)*sw, 1+i/cols, "["+spaces(sw-2)+"]", cell)
+			} else {
+				drawString(res, (i%cols)*sw, 1+i/cols, spaces(sw), cell)
+			}
+		}
+	}
+
+	y := w.bodyRows() + 2
+	if y < res.BoxRows() {
+		var rgb [3]int
+		if w.mode == RGB {
+			rgb = w.rgb
+		} else {
+			rgb = PaletteRGB(w.index)
+		}
+		fg, _ := w.Color().ToTCellColor(app.GetColorMode())
+		drawString(res, 0, y, spaces(8), plain.WithBackgroundColor(fg))
+		desc := fmt.Sprintf(" #%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+		if w.mode != RGB {
+			desc = fmt.Sprintf(" %d", w.index) + desc
+		}
+		drawString(res, 8, y, desc, plain)
+	}
+
+	return res
+}
+
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	before := w.Color()
+	res := w.handleInput(ev, app)
+	if res && w.Color() != before {
+		gowid.RunWidgetCallbacks(w.Callbacks, ChangeCB{}, app, w)
+	}
+	return res
+}
+
+func (w *Widget) handleInput(ev interface{}, app gowid.IApp) bool {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		if gowid.KeysEqual(ev, w.opts.ModeKey) {
+			w.nextMode(app)
+			return true
+		}
+		switch ev.Key() {
+		case tcell.KeyEnter:
+			w.choose(app)
+			return true
+		case tcell.KeyRune:
+			if ev.Rune() == ' ' {
+				w.choose(app)
+				return true
+			}
+			return false
+		}
+		if w.mode == RGB {
+			return w.rgbKey(ev)
+		}
+		return w.gridKey(ev)
+	case *tcell.EventMouse:
+		x, y := ev.Position()
+		if ev.Buttons() != tcell.ButtonNone || !app.GetLastMouseState().LeftIsClicked() {
+			return ev.Buttons() == tcell.Button1
+		}
+		switch {
+		case y == 0:
+			pos := 0
+			for _, m := range w.modes(app) {
+				end := pos + len(m.String()) + 2
+				if x >= pos && x < end {
+					w.SetMode(m, app)
+					return true
+				}
+				pos = end + 1
+			}
+			return false
+		case y > w.bodyRows():
+			return false
+		case w.mode == RGB:
+			if x < 2 || x >= 2+barWidth {
+				return false
+			}
+			w.channel = y - 1
+			w.rgb[w.channel] = gwutil.Min(255, ((x-2)*255+barWidth/2)/(barWidth-1))
+			return true
+		default:
+			cols, _, sw := w.gridSize()
+			if x < 0 || x >= cols*sw {
+				return false
+			}
+			w.index = (y-1)*cols + x/sw
+			w.choose(app)
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Widget) nextMode(app gowid.IApp) {
+	modes := w.modes(app)
+	next := modes[0]
+	for i, m := range modes {
+		if m == w.mode && i+1 < len(modes) {
+			next = modes[i+1]
+		}
+	}
+	w.SetMode(next, app)
+}
+
+func (w *Widget) choose(app gowid.IApp) {
+	gowid.RunWidgetCallbacks(w.Callbacks, ChooseCB{}, app, w)
+}
+
+func (w *Widget) gridKey(ev *tcell.EventKey) bool {
+	cols, rows, _ := w.gridSize()
+	x, y := w.index%cols, w.index/cols
+	switch ev.Key() {
+	case tcell.KeyLeft:
+		x--
+	case tcell.KeyRight:
+		x++
+	case tcell.KeyUp:
+		y--
+	case tcell.KeyDown:
+		y++
+	case tcell.KeyHome:
+		x, y = 0, 0
+	case tcell.KeyEnd:
+		x, y = cols-1, rows-1
+	default:
+		return false
+	}
+	if x < 0 || x >= cols || y < 0 || y >= rows {
+		// At the edge - let the keys move the focus elsewhere
+		return false
+	}
+	w.index = y*cols + x
+	return true
+}
+
+func (w *Widget) rgbKey(ev *tcell.EventKey) bool {
+	delta := 0
+	switch ev.Key() {
+	case tcell.KeyUp:
+		if w.channel == 0 {
+			return false
+		}
+		w.channel--
+		return true
+	case tcell.KeyDown:
+		if w.channel == 2 {
+			return false
+		}
+		w.channel++
+		return true
+	case tcell.KeyLeft:
+		delta = -1
+	case tcell.KeyRight:
+		delta = 1
+	case tcell.KeyPgDn:
+		delta = -16
+	case tcell.KeyPgUp:
+		delta = 16
+	case tcell.KeyHome:
+		delta = -255
+	case tcell.KeyEnd:
+		delta = 255
+	default:
+		return false
+	}
+	w.rgb[w.channel] = gwutil.Max(0, gwutil.Min(255, w.rgb[w.channel]+delta))
+	return true
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// barWidth is the width of an RGB slider.
+const barWidth = 24
+
+// ansi16 are the RGB values xterm uses for the 16 ANSI colors.
+var ansi16 = [16][3]int{
+	{0x00, 0x00, 0x00}, {0xcd, 0x00, 0x00}, {0x00, 0xcd, 0x00}, {0xcd, 0xcd, 0x00},
+	{0x00, 0x00, 0xee}, {0xcd, 0x00, 0xcd}, {0x00, 0xcd, 0xcd}, {0xe5, 0xe5, 0xe5},
+	{0x7f, 0x7f, 0x7f}, {0xff, 0x00, 0x00}, {0x00, 0xff, 0x00}, {0xff, 0xff, 0x00},
+	{0x5c, 0x5c, 0xff}, {0xff, 0x00, 0xff}, {0x00, 0xff, 0xff}, {0xff, 0xff, 0xff},
+}
+
+var cubeLevels = [6]int{0x00, 0x5f, 0x87, 0xaf, 0xd7, 0xff}
+
+// PaletteRGB returns the red, green and blue components of color i of the xterm 256 color palette.
+// Terminals may show the first 16 differently.
+func PaletteRGB(i int) [3]int {
+	switch {
+	case i < 16:
+		return ansi16[gwutil.Max(0, i)]
+	case i < 232:
+		i -= 16
+		return [3]int{cubeLevels[i/36], cubeLevels[(i/6)%6], cubeLevels[i%6]}
+	default:
+		g := 8 + 10*(gwutil.Min(i, 255)-232)
+		return [3]int{g, g, g}
+	}
+}
+
+// contrast returns black or white, whichever is easier to read on rgb.
+func contrast(rgb [3]int) gowid.TCellColor {
+	if rgb[0]*299+rgb[1]*587+rgb[2]*114 > 128*1000 {
+		return gowid.MakeTCellColorExt(tcell.ColorBlack)
+	}
+	return gowid.MakeTCellColorExt(tcell.ColorWhite)
+}
+
+func swatch(i int) gowid.Cell {
+	return gowid.CellFromRune(' ').WithBackgroundColor(gowid.MakeTCellColorExt(tcell.Color(i)))
+}
+
+func spaces(n int) string {
+	res := make([]rune, gwutil.Max(0, n))
+	for i := range res {
+		res[i] = ' '
+	}
+	return string(res)
+}
+
+// drawString draws s at x, y, and returns the column after it.
+func drawString(c gowid.ICanvas, x, y int, s string, base gowid.Cell) int {
+	for _, r := range s {
+		if x >= 0 && x < c.BoxColumns() && y < c.BoxRows() {
+			c.SetCellAt(x, y, base.WithRune(r))
+		}
+		x++
+	}
+	return x
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
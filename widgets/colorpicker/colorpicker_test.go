@@ -0,0 +1,119 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package colorpicker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+// pad fills s out to the width of the picker.
+func pad(s string) string {
+	return fmt.Sprintf("%-*s", Width, s)
+}
+
+func TestPalette1(t *testing.T) {
+	w := New()
+	changed, chosen := 0, 0
+	w.OnChange(gowid.WidgetCallback{Name: "test", WidgetChangedFunction: func(app gowid.IApp, w gowid.IWidget) {
+		changed++
+	}})
+	w.OnChoose(gowid.WidgetCallback{Name: "test", WidgetChangedFunction: func(app gowid.IApp, w gowid.IWidget) {
+		chosen++
+	}})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: Width, Rows: 5})
+	defer sim.Close()
+
+	sim.AssertLine(t, 0, pad(" 16   256 "))
+	sim.AssertLine(t, 1, pad("[  ]"))
+	sim.AssertLine(t, 4, pad("         0 #000000"))
+
+	sim.Key(tcell.KeyRight)
+	sim.Key(tcell.KeyDown)
+	assert.Equal(t, gowid.MakeTCellColorExt(tcell.Color(9)), w.Color())
+	assert.Equal(t, 2, changed)
+	sim.AssertLine(t, 2, pad("    [  ]"))
+	sim.AssertLine(t, 4, pad("         9 #ff0000"))
+
+	// At the edge of the grid, the key isn't used
+	sim.Key(tcell.KeyDown)
+	assert.Equal(t, 2, changed)
+	sim.Key(tcell.KeyEnter)
+	assert.Equal(t, 1, chosen)
+
+	// Without 24-bit color, the modes are just the palettes
+	sim.Rune('m')
+	assert.Equal(t, Palette256, w.Mode())
+	sim.Rune('m')
+	assert.Equal(t, Palette16, w.Mode())
+
+	// A click on a swatch chooses it
+	sim.Click(5, 1, tcell.Button1)
+	assert.Equal(t, gowid.MakeTCellColorExt(tcell.Color(1)), w.Color())
+	assert.Equal(t, 2, chosen)
+	assert.Equal(t, 3, changed)
+
+	w.SetColor(gowid.MakeTCellColorExt(tcell.Color(200)), sim.App)
+	assert.Equal(t, Palette256, w.Mode())
+	assert.Equal(t, gowid.MakeTCellColorExt(tcell.Color(200)), w.Color())
+	w.SetColor(gowid.MakeRGBColorExt(1, 2, 3), sim.App)
+	assert.Equal(t, RGB, w.Mode())
+	assert.Equal(t, gowid.MakeRGBColorExt(1, 2, 3), w.Color())
+}
+
+func TestRGB1(t *testing.T) {
+	w := New(Options{Mode: Palette16})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: Width, Rows: 6})
+	defer sim.Close()
+	sim.SetColorMode(gowid.Mode24BitColors)
+
+	w.SetColor(gowid.MakeTCellColorExt(tcell.Color(9)), sim.App)
+	sim.Rune('m')
+	sim.Rune('m')
+	assert.Equal(t, RGB, w.Mode())
+	assert.Equal(t, gowid.MakeRGBColorExt(255, 0, 0), w.Color())
+	sim.AssertLine(t, 0, pad(" 16   256   RGB "))
+	sim.AssertLine(t, 1, pad("R ████████████████████████ 255"))
+	sim.AssertLine(t, 2, pad("G ░░░░░░░░░░░░░░░░░░░░░░░░   0"))
+	sim.AssertLine(t, 5, pad("         #ff0000"))
+
+	sim.Key(tcell.KeyDown)
+	sim.Key(tcell.KeyEnd)
+	sim.Key(tcell.KeyPgDn)
+	sim.Key(tcell.KeyLeft)
+	assert.Equal(t, gowid.MakeRGBColorExt(255, 238, 0), w.Color())
+	sim.AssertLine(t, 5, pad("         #ffee00"))
+
+	// A click on a slider sets its component
+	sim.Click(2+barWidth-1, 3, tcell.Button1)
+	assert.Equal(t, gowid.MakeRGBColorExt(255, 238, 255), w.Color())
+	sim.Click(2, 1, tcell.Button1)
+	assert.Equal(t, gowid.MakeRGBColorExt(0, 238, 255), w.Color())
+
+	// Back to a palette, where the cursor was
+	sim.Click(1, 0, tcell.Button1)
+	assert.Equal(t, Palette16, w.Mode())
+	assert.Equal(t, gowid.MakeTCellColorExt(tcell.Color(9)), w.Color())
+}
+
+func TestPaletteRGB1(t *testing.T) {
+	assert.Equal(t, [3]int{0xff, 0x00, 0x00}, PaletteRGB(9))
+	assert.Equal(t, [3]int{0x00, 0x00, 0x00}, PaletteRGB(16))
+	assert.Equal(t, [3]int{0xff, 0x87, 0x00}, PaletteRGB(208))
+	assert.Equal(t, [3]int{0x08, 0x08, 0x08}, PaletteRGB(232))
+	assert.Equal(t, [3]int{0xee, 0xee, 0xee}, PaletteRGB(255))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
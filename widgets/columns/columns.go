@@ -49,6 +49,8 @@ type Options struct {
 	DoNotSetSelected bool // Whether or not to set the focus.Selected field for the selected child
 	LeftKeys         []vim.KeyPress
 	RightKeys        []vim.KeyPress
+	// Justify places the columns left over if the widgets don't use them all - by default, on the right
+	Justify gowid.Justify
 }
 
 func New(widgets []gowid.IContainerWidget, opts ...Options) *Widget {
@@ -134,6 +136,10 @@ func (w *Widget) SetFocus(app gowid.IApp, i int) {
 	}
 }
 
+func (w *Widget) Justify() gowid.Justify {
+	return w.opt.Justify
+}
+
 func (w *Widget) Wrap() bool {
 	return w.opt.Wrap
 }
@@ -159,6 +165,7 @@ func (w *Widget) SetSubWidgets(widgets []gowid.IWidget, app gowid.IApp) {
 			ws[i] = &gowid.ContainerWidget{IWidget: iw, D: gowid.RenderFlow{}}
 		}
 	}
+	gowid.LayoutChanging(app, w)
 	oldFocus := w.Focus()
 	w.widgets = ws
 	w.SetFocus(app, oldFocus)
@@ -174,6 +181,7 @@ func (w *Widget) Dimensions() []gowid.IWidgetDimension {
 }
 
 func (w *Widget) SetDimensions(dimensions []gowid.IWidgetDimension, app gowid.IApp) {
+	gowid.LayoutChanging(app, w)
 	for i, id := range dimensions {
 		w.widgets[i].SetDimension(id)
 	}
@@ -275,6 +283,15 @@ func (w *Widget) KeyIsRight(evk *tcell.EventKey) bool {
 func SubWidgetSize(size gowid.IRenderSize, newX int, dim gowid.IWidgetDimension) gowid.IRenderSize {
 	var subSize gowid.IRenderSize
 
+	// A fixed widget that can be shrunk is rendered with the columns it was given, shrunk or not
+	if _, ok := dim.(gowid.IRenderMinUnits); ok {
+		if _, ok := dim.(gowid.IRenderFixed); ok {
+			if _, ok := size.(gowid.IRenderFixed); !ok {
+				return gowid.RenderFlowWith{C: newX}
+			}
+		}
+	}
+
 	switch sz := size.(type) {
 	case gowid.IRenderFixed:
 		switch dim.(type) {
@@ -325,8 +342,10 @@ func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Se
 		if evm, ok := ev.(*tcell.EventMouse); ok {
 			curX := 0
 			mx, _ := evm.Position()
+			gaps := justifyGaps(w, size, subSizes)
 		Loop:
 			for i, c := range subSizes {
+				curX += gaps[i]
 				if mx < curX+c && mx >= curX {
 					subSize := w.SubWidgetSize(size, c, subs[i], dims[i])
 					forChild = subs[i].UserInput(gowid.TranslatedMouseEvent(ev, -curX, 0), subSize, focus.SelectIf(w.SelectChild(focus) && i == subfocus), app)
@@ -434,6 +453,13 @@ func widgetWidthsExt(w gowid.ISelectChild, subs []gowid.IWidget, dims []gowid.IW
 	colsUsed := 0
 	totalWeight := 0
 
+	// If there isn't room for every widget at its natural width, the fixed widgets that can be shrunk are
+	// shrunk to fit, as far as their minimums allow - see negotiateWidths.
+	var shrink []int
+	if haveColsTotal {
+		shrink = negotiateWidths(w, subs, dims, size, colsTotal, focus, focusIdx, app)
+	}
+
 	trunc := func(x *int) {
 		if haveColsTotal && colsUsed+*x > colsTotal {
 			*x = colsTotal - colsUsed
@@ -449,6 +475,9 @@ func widgetWidthsExt(w gowid.ISelectChild, subs []gowid.IWidget, dims []gowid.IW
 		case gowid.IRenderFixed:
 			c := gowid.RenderSize(subs[i], gowid.RenderFixed{}, focus.SelectIf(w.SelectChild(focus) && i == focusIdx), app)
 			res[i] = c.BoxColumns()
+			if shrink != nil {
+				res[i] -= shrink[i]
+			}
 			trunc(&res[i])
 			colsUsed += res[i]
 			helper[i] = true
@@ -492,6 +521,14 @@ func widgetWidthsExt(w gowid.ISelectChild, subs []gowid.IWidget, dims []gowid.IW
 		colsLeft = colsToDivideUp
 	}
 
+	// Weighted widgets with a minimum get it first, then a share of the rest
+	for i := 0; i < lenw; i++ {
+		if min, ok := dims[i].(gowid.IRenderMinUnits); ok && !helper[i] {
+			res[i] = gwutil.Min(min.MinUnits(), colsLeft)
+			colsLeft -= res[i]
+		}
+	}
+
 	// Now, divide up the remaining space among the weight columns
 	lasti := -1
 	for {
@@ -541,6 +578,64 @@ func widgetWidthsExt(w gowid.ISelectChild, subs []gowid.IWidget, dims []gowid.IW
 	return res
 }
 
+// negotiateWidths returns how many columns to take from each fixed widget's natural width so that the
+// widgets fit in colsTotal, or nil if nothing need be taken. Only fixed widgets whose dimension has a
+// minimum, like gowid.RenderNatural, are shrunk, each in proportion to how far it can shrink. The
+// minimums of weighted widgets are kept free. If the widgets still don't fit, those at the end are cut
+// off, as usual.
+func negotiateWidths(w gowid.ISelectChild, subs []gowid.IWidget, dims []gowid.IWidgetDimension, size gowid.IRenderSize, colsTotal int, focus gowid.Selector, focusIdx int, app gowid.IApp) []int {
+	lenw := len(subs)
+	slack := make([]int, lenw)
+	totalSlack := 0
+	need := 0
+	for i := 0; i < lenw; i++ {
+		switch d := dims[i].(type) {
+		case gowid.IRenderFixed:
+			natural := gowid.RenderSize(subs[i], gowid.RenderFixed{}, focus.SelectIf(w.SelectChild(focus) && i == focusIdx), app).BoxColumns()
+			need += natural
+			if min, ok := d.(gowid.IRenderMinUnits); ok && natural > min.MinUnits() {
+				slack[i] = natural - gwutil.Max(0, min.MinUnits())
+				totalSlack += slack[i]
+			}
+		case gowid.IRenderBox:
+			need += d.BoxColumns()
+		case gowid.IRenderFlowWith:
+			need += d.FlowColumns()
+		case gowid.IRenderWithUnits:
+			need += d.Units()
+		case gowid.IRenderRelative:
+			need += int((d.Relative() * float64(colsTotal)) + 0.5)
+		case gowid.IRenderWithWeight:
+			if min, ok := d.(gowid.IRenderMinUnits); ok {
+				need += min.MinUnits()
+			}
+		}
+	}
+	excess := need - colsTotal
+	if excess <= 0 || totalSlack == 0 {
+		return nil
+	}
+	excess = gwutil.Min(excess, totalSlack)
+
+	res := make([]int, lenw)
+	taken := 0
+	last := -1
+	for i := 0; i < lenw; i++ {
+		if slack[i] > 0 {
+			res[i] = gwutil.Min(slack[i], (excess*slack[i])/totalSlack)
+			taken += res[i]
+			last = i
+		}
+	}
+	// Take the rounding error from the last widgets that can still give
+	for i := last; i >= 0 && taken < excess; i-- {
+		more := gwutil.Min(slack[i]-res[i], excess-taken)
+		res[i] += more
+		taken += more
+	}
+	return res
+}
+
 func RenderSize(w gowid.ICompositeMultipleWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
 	subfocus := w.Focus()
 	sizes := w.RenderedSubWidgetsSizes(size, focus, subfocus, app)
@@ -569,6 +664,12 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 
 	subs := w.SubWidgets()
 
+	widths := make([]int, len(canvases))
+	for i, c := range canvases {
+		widths[i] = c.BoxColumns()
+	}
+	gaps := justifyGaps(w, size, widths)
+
 	// Assemble subcanvases into final canvas
 	for i := 0; i < len(subs); i++ {
 		diff := res.BoxRows() - canvases[i].BoxRows()
@@ -581,6 +682,9 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 			fc := fill.Render(gowid.RenderBox{res.BoxColumns(), -diff}, gowid.NotSelected, app)
 			res.AppendBelow(fc, false, false)
 		}
+		if gaps[i] > 0 {
+			res.AppendRight(gowid.NewCanvasOfSize(gaps[i], res.BoxRows()), false)
+		}
 		res.AppendRight(canvases[i], i == subfocus)
 	}
 
@@ -596,6 +700,19 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	return res
 }
 
+// justifyGaps returns the columns to leave before each subwidget, given the widths it will have - see
+// gowid.JustifyGaps.
+func justifyGaps(w interface{}, size gowid.IRenderSize, widths []int) []int {
+	leftover := 0
+	if cols, ok := size.(gowid.IColumns); ok {
+		leftover = cols.Columns()
+		for _, c := range widths {
+			leftover -= c
+		}
+	}
+	return gowid.JustifyGaps(gowid.JustifyOf(w), leftover, len(widths))
+}
+
 var AllChildrenMaxDimension = fmt.Errorf("All columns widgets were rendered Max, so there is no max height to use.")
 
 // RenderSubWidgets returns an array of canvases for each of the subwidgets, rendering them
@@ -622,7 +739,7 @@ func RenderSubWidgets(w IWidget, size gowid.IRenderSize, focus gowid.Selector, f
 			maxes = append(maxes, i)
 			ssizes = append(ssizes, subSize)
 		} else {
-			canvases[i] = subs[i].Render(subSize, focus.SelectIf(w.SelectChild(focus) && i == focusIdx), app)
+			canvases[i] = gowid.Render(subs[i], subSize, focus.SelectIf(w.SelectChild(focus) && i == focusIdx), app)
 			if canvases[i].BoxRows() > curMax {
 				curMax = canvases[i].BoxRows()
 			}
@@ -643,7 +760,7 @@ func RenderSubWidgets(w IWidget, size gowid.IRenderSize, focus gowid.Selector, f
 			mss = gowid.MakeRenderBox(css.BoxColumns(), curMax)
 		default:
 		}
-		canvases[i] = subs[i].Render(mss, focus.SelectIf(w.SelectChild(focus) && i == focusIdx), app)
+		canvases[i] = gowid.Render(subs[i], mss, focus.SelectIf(w.SelectChild(focus) && i == focusIdx), app)
 	}
 
 	return canvases
@@ -9,6 +9,7 @@ import (
 
 	"github.com/gcla/gowid"
 	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/button"
 	"github.com/gcla/gowid/widgets/checkbox"
 	"github.com/gcla/gowid/widgets/fill"
 	"github.com/gcla/gowid/widgets/selectable"
@@ -171,6 +172,72 @@ func TestColumns4(t *testing.T) {
 	assert.Equal(t, "xxxxxyyyyyzz", c.String())
 }
 
+type renderWeightAtLeast struct {
+	gowid.RenderWithWeight
+	min int
+}
+
+func (s renderWeightAtLeast) MinUnits() int {
+	return s.min
+}
+
+func TestNatural1(t *testing.T) {
+	w := New([]gowid.IContainerWidget{
+		&gowid.ContainerWidget{text.New("abcdef"), gowid.RenderNatural{Min: 2}},
+		&gowid.ContainerWidget{text.New("xyz"), gowid.RenderFixed{}},
+		&gowid.ContainerWidget{fill.New('-'), renderWeightAtLeast{gowid.RenderWithWeight{W: 1}, 2}},
+	})
+	c := w.Render(gowid.RenderBox{C: 12, R: 1}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "abcdefxyz---", c.String())
+
+	// The natural widget shrinks so that the weighted widget keeps its minimum
+	c = w.Render(gowid.RenderBox{C: 9, R: 2}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "abcdxyz--\nef     --", c.String())
+
+	// But no further than its own minimum - then the widgets at the end are cut off, as usual
+	c = w.Render(gowid.RenderBox{C: 6, R: 3}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "abxyz-\ncd   -\nef   -", c.String())
+
+	gwtest.RenderBoxManyTimes(t, w, 0, 10, 0, 10)
+}
+
+func TestJustify1(t *testing.T) {
+	clicks := []string{}
+	clickable := func(label string) gowid.IWidget {
+		b := button.NewBare(text.New(label))
+		b.OnClick(gowid.MakeWidgetCallback("test", func(app gowid.IApp, w gowid.IWidget) {
+			clicks = append(clicks, label)
+		}))
+		return b
+	}
+	subs := []gowid.IContainerWidget{
+		&gowid.ContainerWidget{clickable("a"), gowid.RenderFixed{}},
+		&gowid.ContainerWidget{clickable("b"), gowid.RenderFixed{}},
+		&gowid.ContainerWidget{clickable("c"), gowid.RenderFixed{}},
+	}
+	for _, tc := range []struct {
+		justify gowid.Justify
+		line    string
+	}{
+		{gowid.JustifyStart, "abc    "},
+		{gowid.JustifyEnd, "    abc"},
+		{gowid.JustifyCenter, "  abc  "},
+		{gowid.JustifySpaceBetween, "a  b  c"},
+	} {
+		w := New(subs, Options{Justify: tc.justify})
+		c := w.Render(gowid.RenderFlowWith{C: 7}, gowid.Focused, gwtest.D)
+		assert.Equal(t, tc.line, c.String(), "%v", tc.justify)
+	}
+
+	w := New(subs, Options{Justify: gowid.JustifySpaceBetween})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 7, Rows: 1})
+	defer sim.Close()
+	sim.Click(3, 0, tcell.Button1)
+	sim.Click(6, 0, tcell.Button1)
+	assert.Equal(t, []string{"b", "c"}, clicks)
+	assert.Equal(t, 2, w.Focus())
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
@@ -0,0 +1,341 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package contextmenu provides a widget that opens a menu of actions where its inner widget is
+// right-clicked. The menu looks like the menus of package menubar, and takes its items.
+package contextmenu
+
+import (
+	"fmt"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/layers"
+	"github.com/gcla/gowid/widgets/menubar"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// OpenCB is the callback name used when the menu is opened.
+type OpenCB struct{}
+
+// CloseCB is the callback name used when the menu is closed, whether or not an item was chosen.
+type CloseCB struct{}
+
+// Options is used to configure the widget.
+type Options struct {
+	// OpenKey, if not nil, opens the menu at the top-left of the widget, for keyboard users - e.g. if the
+	// inner widget doesn't handle it, Shift-F10.
+	OpenKey       gowid.IKey
+	Style         gowid.ICellStyler // The style of the menu; if nil, it is unstyled
+	FocusStyle    gowid.ICellStyler // The style of the chosen item; defaults to reverse video
+	DisabledStyle gowid.ICellStyler // The style of disabled items; defaults to dim
+}
+
+// Widget draws its inner widget, and opens a menu of items over it when it is right-clicked, at the
+// mouse pointer. The menu is drawn within the widget, moved if it would stick out - so wrap a widget as
+// large as the area the menu may cover, e.g. a whole list rather than one of its rows. While the menu is
+// open, it grabs the input: the arrow keys move between items, Enter or an item's mnemonic chooses it,
+// and a click chooses the item under the pointer. Esc, or a click outside the menu, closes it. Submenus
+// are not opened - items with them are treated as disabled.
+type Widget struct {
+	gowid.IWidget
+	items []menubar.Item
+	opts  Options
+	stack *layers.Widget
+	menu  *menu         // Nil unless the menu is open
+	layer *layers.Layer // Where the menu is drawn
+	cols  int           // The size the widget was last rendered at, so the menu can be kept inside it
+	rows  int
+	*gowid.Callbacks
+	gowid.SubWidgetCallbacks
+}
+
+func New(inner gowid.IWidget, items []menubar.Item, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	res := &Widget{
+		IWidget: inner,
+		items:   append([]menubar.Item(nil), items...),
+		opts:    opt,
+	}
+	res.stack = layers.New(inner)
+	res.SubWidgetCallbacks = gowid.SubWidgetCallbacks{CB: &res.Callbacks}
+	var _ gowid.ICompositeWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("contextmenu[%v]", w.SubWidget())
+}
+
+func (w *Widget) SubWidget() gowid.IWidget {
+	return w.IWidget
+}
+
+func (w *Widget) SetSubWidget(wi gowid.IWidget, app gowid.IApp) {
+	w.IWidget = wi
+	w.stack.SetSubWidget(wi, app)
+	gowid.RunWidgetCallbacks(w.Callbacks, gowid.SubWidgetCB{}, app, w)
+}
+
+func (w *Widget) SubWidgetSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	return size
+}
+
+// Items returns a copy of the menu's items.
+func (w *Widget) Items() []menubar.Item {
+	return append([]menubar.Item(nil), w.items...)
+}
+
+// SetItems replaces the menu's items, closing it if it is open - e.g. to offer actions that depend on
+// what was clicked, from an OnOpen callback.
+func (w *Widget) SetItems(items []menubar.Item, app gowid.IApp) {
+	w.Close(app)
+	w.items = append([]menubar.Item(nil), items...)
+}
+
+// IsOpen returns true if the menu is open.
+func (w *Widget) IsOpen() bool {
+	return w.menu != nil
+}
+
+// Open opens the menu with its top-left corner at x, y in the widget, moved if it would stick out of
+// the widget, and grabs the input for it.
+func (w *Widget) Open(x, y int, app gowid.IApp) {
+	w.Close(app)
+	m := &menu{cm: w}
+	m.width, m.height = menubar.MenuSize(w.items)
+	if w.cols > 0 {
+		x = gwutil.Max(0, gwutil.Min(x, w.cols-m.width))
+	}
+	if w.rows > 0 {
+		y = gwutil.Max(0, gwutil.Min(y, w.rows-m.height))
+	}
+	m.focus = step(w.items, -1, 1)
+	w.menu = m
+	w.layer = w.stack.Add(m, app, layers.Options{
+		VAlign: gowid.VAlignTop{Margin: y},
+		Height: gowid.RenderWithUnits{U: m.height},
+		HAlign: gowid.HAlignLeft{Margin: x},
+		Width:  gowid.RenderWithUnits{U: m.width},
+	})
+	gowid.GrabInput(app, m, gowid.GrabOptions{
+		OnRelease: func(app gowid.IApp) {
+			w.closed(m, app)
+		},
+	})
+	gowid.RunWidgetCallbacks(w.Callbacks, OpenCB{}, app, w)
+}
+
+// Close closes the menu, if it is open, without choosing an item.
+func (w *Widget) Close(app gowid.IApp) {
+	if w.menu == nil {
+		return
+	}
+	m := w.menu
+	if g, ok := app.(iGrabbedBy); ok && g.InputGrabbedBy() == gowid.IWidget(m) {
+		// Closes the menu, from the grab's OnRelease
+		gowid.ReleaseInput(app)
+	}
+	w.closed(m, app)
+}
+
+// closed removes the menu m, if it is still open, when its grab is released.
+func (w *Widget) closed(m *menu, app gowid.IApp) {
+	if w.menu != m {
+		return
+	}
+	w.menu = nil
+	w.layer.Remove(app)
+	w.layer = nil
+	gowid.RunWidgetCallbacks(w.Callbacks, CloseCB{}, app, w)
+}
+
+// choose closes the menu, and runs the action of the item at index i.
+func (w *Widget) choose(i int, app gowid.IApp) {
+	item := w.items[i]
+	w.Close(app)
+	if item.Action != nil {
+		item.Action(app)
+	}
+}
+
+// OnOpen registers a callback run when the menu is opened.
+func (w *Widget) OnOpen(f gowid.IWidgetChangedCallback) {
+	if w.Callbacks == nil {
+		w.Callbacks = gowid.NewCallbacks()
+	}
+	gowid.AddWidgetCallback(w.Callbacks, OpenCB{}, f)
+}
+
+func (w *Widget) RemoveOnOpen(f gowid.IIdentity) {
+	if w.Callbacks != nil {
+		gowid.RemoveWidgetCallback(w.Callbacks, OpenCB{}, f)
+	}
+}
+
+// OnClose registers a callback run when the menu is closed.
+func (w *Widget) OnClose(f gowid.IWidgetChangedCallback) {
+	if w.Callbacks == nil {
+		w.Callbacks = gowid.NewCallbacks()
+	}
+	gowid.AddWidgetCallback(w.Callbacks, CloseCB{}, f)
+}
+
+func (w *Widget) RemoveOnClose(f gowid.IIdentity) {
+	if w.Callbacks != nil {
+		gowid.RemoveWidgetCallback(w.Callbacks, CloseCB{}, f)
+	}
+}
+
+// Selectable returns true if the widget has a menu to open, so it is offered right-clicks, even if its
+// inner widget isn't selectable.
+func (w *Widget) Selectable() bool {
+	return len(w.items) > 0 || w.stack.Selectable()
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	return w.stack.RenderSize(size, focus, app)
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	res := w.stack.Render(size, focus, app)
+	w.cols, w.rows = res.BoxColumns(), res.BoxRows()
+	return res
+}
+
+// UserInput opens the menu on a right-click, or the open key if the inner widget doesn't handle it.
+// Everything else goes to the inner widget. While the menu is open, it has grabbed the input.
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if evm, ok := ev.(*tcell.EventMouse); ok && evm.Buttons() == tcell.Button3 && len(w.items) > 0 {
+		if !app.GetLastMouseState().RightIsClicked() {
+			x, y := evm.Position()
+			w.Open(x, y, app)
+		}
+		return true
+	}
+	if w.stack.UserInput(ev, size, focus, app) {
+		return true
+	}
+	if evk, ok := ev.(*tcell.EventKey); ok && w.opts.OpenKey != nil && len(w.items) > 0 &&
+		gowid.KeysEqual(evk, w.opts.OpenKey) {
+		w.Open(0, 0, app)
+		return true
+	}
+	return false
+}
+
+// iGrabbedBy is implemented by an app that tells which widget has grabbed input, like gowid.App.
+type iGrabbedBy interface {
+	InputGrabbedBy() gowid.IWidget
+}
+
+//======================================================================
+
+// menu draws the open menu, and handles the input it has grabbed.
+type menu struct {
+	cm            *Widget
+	focus         int  // The chosen item, or -1 if none can be chosen
+	pressed       bool // True if a button was pressed over the menu - not the release of the right-click opening it
+	width, height int
+}
+
+func (m *menu) Selectable() bool {
+	return true
+}
+
+func (m *menu) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	if box, ok := size.(gowid.IRenderBox); ok {
+		return gowid.RenderBox{C: box.BoxColumns(), R: box.BoxRows()}
+	}
+	return gowid.RenderBox{C: m.width, R: m.height}
+}
+
+func (m *menu) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	box := m.RenderSize(size, focus, app)
+	return menubar.DrawMenu(m.cm.items, m.focus, box.BoxColumns(), box.BoxRows(), menubar.Options{
+		Style:         m.cm.opts.Style,
+		FocusStyle:    m.cm.opts.FocusStyle,
+		DisabledStyle: m.cm.opts.DisabledStyle,
+	}, app)
+}
+
+func (m *menu) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	items := m.cm.items
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		switch ev.Key() {
+		case tcell.KeyUp:
+			m.focus = step(items, m.focus, -1)
+		case tcell.KeyDown:
+			m.focus = step(items, m.focus, 1)
+		case tcell.KeyHome:
+			m.focus = step(items, -1, 1)
+		case tcell.KeyEnd:
+			m.focus = step(items, len(items), -1)
+		case tcell.KeyEnter:
+			if m.focus != -1 {
+				m.cm.choose(m.focus, app)
+			}
+		case tcell.KeyRune:
+			if ev.Rune() == ' ' && m.focus != -1 {
+				m.cm.choose(m.focus, app)
+				return true
+			}
+			for i, item := range items {
+				if mn := menubar.Mnemonic(item.Label); selectable(item) && mn != 0 && menubar.SameLetter(mn, ev.Rune()) {
+					m.cm.choose(i, app)
+					break
+				}
+			}
+		}
+		return true
+	case *tcell.EventMouse:
+		x, y := ev.Position()
+		i := y - 1
+		over := x > 0 && x < m.width-1 && i >= 0 && i < len(items) && selectable(items[i])
+		switch ev.Buttons() {
+		case tcell.Button1, tcell.Button3:
+			m.pressed = true
+			if over {
+				m.focus = i
+			}
+		case tcell.ButtonNone:
+			if m.pressed && !app.GetLastMouseState().NoButtonClicked() {
+				m.pressed = false
+				if over && m.focus == i {
+					m.cm.choose(i, app)
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// step is like menubar.Step, but passes over items with submenus, which aren't opened.
+func step(items []menubar.Item, from int, dir int) int {
+	n := len(items)
+	for i := 1; i <= n; i++ {
+		j := ((from+dir*i)%n + n) % n
+		if selectable(items[j]) {
+			return j
+		}
+	}
+	return -1
+}
+
+func selectable(item menubar.Item) bool {
+	return menubar.Selectable(item) && len(item.Items) == 0
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
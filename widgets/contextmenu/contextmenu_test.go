@@ -0,0 +1,96 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package contextmenu
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/menubar"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func testItems(chosen *[]string) []menubar.Item {
+	item := func(label string) menubar.Item {
+		return menubar.Item{Label: label, Action: func(app gowid.IApp) {
+			*chosen = append(*chosen, label)
+		}}
+	}
+	return []menubar.Item{
+		item("&Copy"),
+		{Label: "&Paste", Disabled: true},
+		menubar.Separator,
+		item("&Delete"),
+	}
+}
+
+func TestKeys1(t *testing.T) {
+	chosen := []string{}
+	w := New(text.New("content"), testItems(&chosen))
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 20, Rows: 8})
+	defer sim.Close()
+
+	// A right-click opens the menu at the pointer
+	sim.Click(2, 1, tcell.Button3)
+	assert.True(t, w.IsOpen())
+	sim.AssertLine(t, 0, "content             ")
+	sim.AssertLine(t, 1, "  ┌────────┐        ")
+	sim.AssertLine(t, 2, "  │ Copy   │        ")
+	sim.AssertLine(t, 3, "  │ Paste  │        ")
+	sim.AssertLine(t, 4, "  ├────────┤        ")
+	sim.AssertLine(t, 5, "  │ Delete │        ")
+	sim.AssertLine(t, 6, "  └────────┘        ")
+
+	// Down skips the disabled item and the separator
+	sim.Key(tcell.KeyDown)
+	sim.Key(tcell.KeyEnter)
+	assert.Equal(t, []string{"&Delete"}, chosen)
+	assert.False(t, w.IsOpen())
+	sim.AssertLine(t, 1, "                    ")
+
+	// The menu is kept inside the widget; a mnemonic chooses an item
+	sim.Click(15, 6, tcell.Button3)
+	sim.AssertLine(t, 2, "          ┌────────┐")
+	sim.Rune('c')
+	assert.Equal(t, []string{"&Delete", "&Copy"}, chosen)
+
+	// Esc closes the menu without choosing
+	sim.Click(0, 0, tcell.Button3)
+	assert.True(t, w.IsOpen())
+	sim.Key(tcell.KeyEsc)
+	assert.False(t, w.IsOpen())
+	assert.Equal(t, []string{"&Delete", "&Copy"}, chosen)
+}
+
+func TestMouse1(t *testing.T) {
+	chosen := []string{}
+	w := New(text.New("content"), testItems(&chosen))
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 20, Rows: 8})
+	defer sim.Close()
+
+	// A click on a disabled item does nothing; a click on an item chooses it
+	sim.Click(0, 0, tcell.Button3)
+	sim.Click(3, 2, tcell.Button1)
+	assert.True(t, w.IsOpen())
+	sim.Click(3, 4, tcell.Button1)
+	assert.Equal(t, []string{"&Delete"}, chosen)
+	assert.False(t, w.IsOpen())
+
+	// A click outside the menu closes it
+	sim.Click(0, 0, tcell.Button3)
+	sim.Click(15, 7, tcell.Button1)
+	assert.False(t, w.IsOpen())
+	assert.Equal(t, []string{"&Delete"}, chosen)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
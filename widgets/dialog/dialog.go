@@ -7,12 +7,15 @@ package dialog
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gcla/gowid"
 	"github.com/gcla/gowid/widgets/button"
 	"github.com/gcla/gowid/widgets/cellmod"
 	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/disable"
 	"github.com/gcla/gowid/widgets/divider"
+	"github.com/gcla/gowid/widgets/edit"
 	"github.com/gcla/gowid/widgets/framed"
 	"github.com/gcla/gowid/widgets/hpadding"
 	"github.com/gcla/gowid/widgets/overlay"
@@ -60,6 +63,7 @@ type Widget struct {
 	contentWrapper       *gowid.ContainerWidget
 	open                 bool
 	maxer                Maximizer
+	confirm              *confirmer
 	NoFunction           gowid.IWidgetChangedCallback
 	Callbacks            *gowid.Callbacks
 }
@@ -68,6 +72,10 @@ var _ gowid.IWidget = (*Widget)(nil)
 var _ IWidget = (*Widget)(nil)
 var _ IMaximizer = (*Widget)(nil)
 
+// DefaultShadowOpacity is how much a dialog's shadow hides what is beneath it, unless Options says
+// otherwise.
+var DefaultShadowOpacity = 0.5
+
 type Options struct {
 	Buttons         []Button
 	NoShadow        bool
@@ -77,6 +85,21 @@ type Options struct {
 	BorderStyle     gowid.ICellStyler
 	FocusOnWidget   bool
 	NoFrame         bool
+	// ConfirmDelay, if non-zero, disables the dialog's first button - the affirmative one -
+	// for this long after the dialog is opened. The seconds remaining are shown in the
+	// button's label. Use this to guard destructive operations.
+	ConfirmDelay time.Duration
+	// ConfirmText, if not empty, adds an edit field below the dialog content. The first
+	// button stays disabled until the user has typed exactly this string.
+	ConfirmText string
+	// ConfirmCaption is displayed as the caption of the confirmation edit field.
+	ConfirmCaption string
+	// ShadowOpacity is how much the shadow hides what is beneath it, from 0 to 1 - see
+	// shadow.Options. 0 means DefaultShadowOpacity; 1 means the shadow is solid.
+	ShadowOpacity float64
+	// DimBackground, if greater than 0, is the opacity of a black shade laid over the widgets
+	// behind the dialog while it is open - see overlay.Options.Dim.
+	DimBackground float64
 }
 
 type Button struct {
@@ -157,9 +180,28 @@ func New(content gowid.IWidget, opts ...Options) *Widget {
 	wrapper := &gowid.ContainerWidget{content, gowid.RenderWithWeight{W: 1}}
 	pileW = append(pileW, wrapper)
 
+	var confirm *confirmer
+	if opt.ConfirmText != "" {
+		confirm = &confirmer{
+			text: opt.ConfirmText,
+			edit: edit.New(edit.Options{Caption: opt.ConfirmCaption}),
+		}
+		confirm.edit.OnTextSet(gowid.WidgetCallback{"confirm", func(app gowid.IApp, widget gowid.IWidget) {
+			confirm.update(app)
+		}})
+		pileW = append(pileW, confirm.edit)
+	}
+	if opt.ConfirmDelay > 0 {
+		if confirm == nil {
+			confirm = &confirmer{}
+		}
+		confirm.delay = opt.ConfirmDelay
+	}
+
 	if len(opts) > 0 {
 		for i, b := range opts[0].Buttons {
-			bw := button.New(text.New(b.Msg))
+			label := text.New(b.Msg)
+			bw := button.New(label)
 			if b.Action == nil {
 				bw.OnClick(gowid.WidgetCallback{fmt.Sprintf("cb-%d", i),
 					func(app gowid.IApp, widget gowid.IWidget) {
@@ -168,13 +210,20 @@ func New(content gowid.IWidget, opts ...Options) *Widget {
 			} else {
 				bw.OnClick(gowid.WidgetCallback{fmt.Sprintf("cb-%d", i), b.Action})
 			}
+			var bwp gowid.IWidget = hpadding.New(
+				styled.NewExt(bw, backgroundStyle, buttonStyle),
+				gowid.HAlignMiddle{},
+				gowid.RenderFixed{},
+			)
+			if i == 0 && confirm != nil {
+				confirm.msg = b.Msg
+				confirm.label = label
+				confirm.button = disable.NewDisabled(bwp)
+				bwp = confirm.button
+			}
 			colsW = append(colsW,
 				&gowid.ContainerWidget{
-					hpadding.New(
-						styled.NewExt(bw, backgroundStyle, buttonStyle),
-						gowid.HAlignMiddle{},
-						gowid.RenderFixed{},
-					),
+					bwp,
 					gowid.RenderWithWeight{W: 1},
 				},
 			)
@@ -193,7 +242,9 @@ func New(content gowid.IWidget, opts ...Options) *Widget {
 	}
 
 	dialogContent := pile.NewFlow(pileW...)
-	if !opt.FocusOnWidget {
+	if confirm != nil && confirm.edit != nil {
+		dialogContent.SetFocus(nil, 1)
+	} else if !opt.FocusOnWidget {
 		dialogContent.SetFocus(nil, len(pileW)-1)
 	}
 
@@ -214,13 +265,17 @@ func New(content gowid.IWidget, opts ...Options) *Widget {
 	)
 
 	if !opt.NoShadow {
-		d = shadow.New(d, 1)
+		if opt.ShadowOpacity == 0 {
+			opt.ShadowOpacity = DefaultShadowOpacity
+		}
+		d = shadow.New(d, 1, shadow.Options{Opacity: opt.ShadowOpacity})
 	}
 
 	res = &Widget{
 		IWidget:        d,
 		contentWrapper: wrapper,
 		Options:        opt,
+		confirm:        confirm,
 		Callbacks:      gowid.NewCallbacks(),
 	}
 
@@ -231,6 +286,14 @@ func (w *Widget) String() string {
 	return fmt.Sprintf("dialog")
 }
 
+func (w *Widget) AccessibleRole() gowid.Role {
+	return gowid.RoleDialog
+}
+
+func (w *Widget) AccessibleLabel(app gowid.IApp) string {
+	return ""
+}
+
 func (w *Widget) SubWidget() gowid.IWidget {
 	return w.IWidget
 }
@@ -258,10 +321,27 @@ func (w *Widget) SetOpen(open bool, app gowid.IApp) {
 	prev := w.open
 	w.open = open
 	if prev != w.open {
+		if w.confirm != nil {
+			if open {
+				w.confirm.start(app)
+			} else {
+				w.confirm.stop()
+			}
+		}
 		gowid.RunWidgetCallbacks(w.Callbacks, OpenCloseCB{}, app, w)
 	}
 }
 
+// ConfirmEnabled returns true if the dialog's affirmative button can be
+// activated i.e. any countdown has expired and any confirmation string has
+// been typed. It returns true for dialogs configured without these guards.
+func (w *Widget) ConfirmEnabled() bool {
+	if w.confirm == nil {
+		return true
+	}
+	return w.confirm.enabled()
+}
+
 func (w *Widget) IsMaxed() bool {
 	return w.maxer.Maxed
 }
@@ -335,6 +415,10 @@ func (w *Widget) SetHeight(d gowid.IWidgetDimension, app gowid.IApp) {
 	w.SavedContainer().SubWidget().(*overlay.Widget).SetHeight(d, app)
 }
 
+func (w *Widget) DimBackground() float64 {
+	return w.Options.DimBackground
+}
+
 func (w *Widget) SetContentWidth(d gowid.IWidgetDimension, app gowid.IApp) {
 	w.contentWrapper.D = d
 }
@@ -358,10 +442,19 @@ type IOpenExt interface {
 	SetContentWidth(w gowid.IWidgetDimension, app gowid.IApp)
 }
 
+// IDimBackground is implemented by a dialog that dims the widgets behind it - see Options.DimBackground.
+type IDimBackground interface {
+	DimBackground() float64
+}
+
 func OpenExt(w IOpenExt, container gowid.ISettableComposite, width gowid.IWidgetDimension, height gowid.IWidgetDimension, app gowid.IApp) {
+	var ovOpts overlay.Options
+	if dw, ok := w.(IDimBackground); ok {
+		ovOpts.Dim = dw.DimBackground()
+	}
 	ov := overlay.New(w, container.SubWidget(),
 		gowid.VAlignMiddle{}, height, // Intended to mean use as much vertical space as you need
-		gowid.HAlignMiddle{}, width)
+		gowid.HAlignMiddle{}, width, ovOpts)
 
 	if _, ok := width.(gowid.IRenderFixed); ok {
 		w.SetContentWidth(gowid.RenderFixed{}, app) // fixed or weight:1, ratio:0.5
@@ -409,6 +502,67 @@ func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Se
 
 //======================================================================
 
+// confirmer guards a dialog's affirmative button. The button is disabled until
+// a countdown started when the dialog opens has expired, and until the user
+// has typed the confirmation string, if one is configured.
+type confirmer struct {
+	msg       string
+	label     *text.Widget
+	button    *disable.Widget
+	edit      *edit.Widget
+	text      string
+	delay     time.Duration
+	remaining int
+	timer     *gowid.Timer
+}
+
+func (c *confirmer) enabled() bool {
+	return c.remaining == 0 && (c.edit == nil || c.edit.Text() == c.text)
+}
+
+func (c *confirmer) update(app gowid.IApp) {
+	if c.button != nil {
+		c.button.Set(!c.enabled())
+	}
+	if c.label != nil {
+		if c.remaining > 0 {
+			c.label.SetText(fmt.Sprintf("%s (%d)", c.msg, c.remaining), app)
+		} else {
+			c.label.SetText(c.msg, app)
+		}
+	}
+}
+
+// start resets the guard and, if a delay is configured, begins counting down
+// once per second using the app's clock. Each tick is run on the app goroutine;
+// the countdown from a previous opening of the dialog is stopped first.
+func (c *confirmer) start(app gowid.IApp) {
+	c.stop()
+	if c.edit != nil {
+		c.edit.SetText("", app)
+	}
+	c.remaining = int((c.delay + time.Second - 1) / time.Second)
+	c.update(app)
+	if c.remaining > 0 {
+		c.timer = gowid.Every(app, time.Second, func(app gowid.IApp) {
+			c.remaining--
+			c.update(app)
+			if c.remaining == 0 {
+				c.stop()
+			}
+		})
+	}
+}
+
+func (c *confirmer) stop() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+}
+
+//======================================================================
+
 type Maximizer struct {
 	Maxed  bool
 	Width  gowid.IWidgetDimension
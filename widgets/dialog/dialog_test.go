@@ -0,0 +1,127 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package dialog
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/holder"
+	"github.com/gcla/gowid/widgets/styled"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestConfirmText1(t *testing.T) {
+	d := New(text.New("Delete everything?"), Options{
+		Buttons:     OkCancel,
+		ConfirmText: "yes",
+	})
+	assert.True(t, d.confirm != nil)
+
+	d.confirm.start(gwtest.D)
+	assert.False(t, d.ConfirmEnabled())
+	assert.False(t, d.confirm.button.Selectable())
+
+	d.confirm.edit.SetText("ye", gwtest.D)
+	assert.False(t, d.ConfirmEnabled())
+
+	d.confirm.edit.SetText("yes", gwtest.D)
+	assert.True(t, d.ConfirmEnabled())
+	assert.True(t, d.confirm.button.Selectable())
+
+	// Reopening resets the confirmation string
+	d.confirm.stop()
+	d.confirm.start(gwtest.D)
+	assert.False(t, d.ConfirmEnabled())
+}
+
+func TestConfirmDelay1(t *testing.T) {
+	d := New(text.New("Delete everything?"), Options{
+		Buttons:      OkCancel,
+		ConfirmDelay: 3 * time.Second,
+	})
+
+	clock := gowid.NewFakeClock(time.Unix(0, 0))
+	app := gwtest.NewTestAppWithClock(clock)
+
+	d.confirm.start(app)
+	assert.False(t, d.ConfirmEnabled())
+	assert.Equal(t, "Ok (3)", d.confirm.label.Content().String())
+
+	clock.Advance(1500 * time.Millisecond)
+	assert.False(t, d.ConfirmEnabled())
+	assert.Equal(t, "Ok (2)", d.confirm.label.Content().String())
+
+	clock.Advance(1500 * time.Millisecond)
+	assert.True(t, d.ConfirmEnabled())
+	assert.Equal(t, "Ok", d.confirm.label.Content().String())
+	assert.Equal(t, 0, clock.Pending())
+
+	// Closing the dialog abandons the countdown
+	d.confirm.start(app)
+	clock.Advance(time.Second)
+	d.confirm.stop()
+	clock.Advance(5 * time.Second)
+	assert.Equal(t, "Ok (2)", d.confirm.label.Content().String())
+}
+
+func TestConfirmNone1(t *testing.T) {
+	d := New(text.New("Hello"), Options{
+		Buttons: OkCancel,
+	})
+	assert.True(t, d.confirm == nil)
+	assert.True(t, d.ConfirmEnabled())
+	c := d.Render(gowid.RenderFlowWith{C: 20}, gowid.Focused, gwtest.D)
+	assert.True(t, c.BoxRows() > 0)
+}
+
+func TestTranslucent1(t *testing.T) {
+	bg := styled.New(text.New(strings.Repeat("behind ", 30)), gowid.MakePaletteEntry(gowid.NewUrwidColor("white"), gowid.NewUrwidColor("dark blue")))
+	h := holder.New(bg)
+	sim := gwtest.NewSimT(t, h, gwtest.SimOptions{Cols: 30, Rows: 7})
+	defer sim.Close()
+
+	d := New(text.New("Hello"), Options{
+		Buttons:       CloseOnly,
+		DimBackground: 0.5,
+	})
+	d.Open(h, gowid.RenderWithUnits{U: 12}, sim)
+	sim.Redraw()
+	sim.Frame()
+
+	// The text behind the dialog shows through the shade, darkened
+	r, st := sim.Cell(0, 0)
+	assert.Equal(t, 'b', r)
+	fg, bg1, _ := st.Decompose()
+	assert.True(t, fg.Hex() >= 0 && fg.Hex() < 0xe5e5e5)
+	assert.True(t, bg1.Hex() >= 0 && bg1.Hex() < 0x0000ee)
+
+	// And through the shadow, to the right of the frame, darkened again
+	x, y, ok := sim.Find("Hello")
+	assert.True(t, ok)
+	r, st2 := sim.Cell(x+10, y+1)
+	assert.NotEqual(t, ' ', r)
+	_, bg2, _ := st2.Decompose()
+	assert.True(t, bg2.Hex() < bg1.Hex())
+
+	d.Close(sim)
+	sim.Redraw()
+	sim.Frame()
+	_, st = sim.Cell(0, 0)
+	_, bg1, _ = st.Decompose()
+	assert.Equal(t, tcell.ColorNavy, bg1)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
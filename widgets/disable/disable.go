@@ -42,6 +42,7 @@ func NewWith(w gowid.IWidget, isDisabled bool) *Widget {
 	}
 	res.SubWidgetCallbacks = gowid.SubWidgetCallbacks{CB: &res.Callbacks}
 	var _ gowid.ICompositeWidget = res
+	var _ gowid.IStyleStater = res
 	return res
 }
 
@@ -57,6 +58,18 @@ func (w *Widget) Set(val bool) {
 	w.isDisabled = val
 }
 
+func (w *Widget) IsDisabled() bool {
+	return w.isDisabled
+}
+
+// StyleState lets an enclosing styled widget use its disabled style.
+func (w *Widget) StyleState() gowid.StyleState {
+	if w.isDisabled {
+		return gowid.StateDisabled
+	}
+	return 0
+}
+
 func (w *Widget) String() string {
 	return fmt.Sprintf("disabled[d=%v,%v]", w.isDisabled, w.SubWidget())
 }
@@ -121,7 +121,7 @@ func Render(w IDivider, size gowid.IRenderSize, focus gowid.Selector, app gowid.
 		panic(gowid.WidgetSizeError{Widget: w, Size: size, Required: "gowid.IRenderFlowWith"})
 	}
 
-	div := gowid.CellFromRune(w.Opts().Chr)
+	div := gowid.CellFromRune(gowid.ASCIIRune(app, w.Opts().Chr, '-'))
 	divArr := make([]gowid.Cell, flow.FlowColumns())
 	for i := 0; i < flow.FlowColumns(); i++ {
 		divArr[i] = div
@@ -0,0 +1,363 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package edit
+
+import (
+	"fmt"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+)
+
+//======================================================================
+
+var (
+	// DefaultInsertStyle draws text added since the baseline, unless DiffOptions says otherwise.
+	DefaultInsertStyle gowid.ICellStyler = gowid.MakeStyledPaletteEntry(gowid.ColorGreen, gowid.ColorNone, gowid.StyleUnderline)
+	// DefaultDeleteStyle draws text removed since the baseline, unless DiffOptions says otherwise.
+	DefaultDeleteStyle gowid.ICellStyler = gowid.MakeStyledPaletteEntry(gowid.ColorRed, gowid.ColorNone, gowid.StyleDim)
+	// DefaultRevertKey puts the baseline back, unless DiffOptions says otherwise.
+	DefaultRevertKey gowid.IKey = gowid.MakeKeyExt(tcell.KeyCtrlR)
+)
+
+// Texts longer than this, in runes multiplied together, aren't compared closely - the part that differs
+// is shown as deleted, then inserted, in one piece.
+const maxDiffCells = 1 << 20
+
+// ChangeKind says how a piece of text differs from the baseline.
+type ChangeKind int
+
+const (
+	Unchanged ChangeKind = iota
+	Inserted
+	Deleted
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Unchanged:
+		return "unchanged"
+	case Inserted:
+		return "inserted"
+	case Deleted:
+		return "deleted"
+	default:
+		return fmt.Sprintf("change(%d)", int(k))
+	}
+}
+
+// Change is a piece of the text, or of the baseline, and whether it has been inserted or deleted.
+type Change struct {
+	Kind ChangeKind
+	Text string
+}
+
+// DiffOptions is used to configure a DiffWidget.
+type DiffOptions struct {
+	Caption     string
+	Direction   gowid.TextDirection
+	InsertStyle gowid.ICellStyler // Defaults to DefaultInsertStyle
+	DeleteStyle gowid.ICellStyler // Defaults to DefaultDeleteStyle
+	RevertKey   gowid.IKey        // Defaults to DefaultRevertKey
+}
+
+// DiffWidget is an edit that compares its text with a baseline - e.g. the value a setting has now - and
+// shows what has changed: text inserted since the baseline in one style, and text deleted, which is
+// displayed where it was but can't be edited, in another. The revert key puts the baseline back.
+type DiffWidget struct {
+	*Widget
+	baseline string
+	opts     DiffOptions
+	diff     *diffLayout // For the text and baseline it was made from
+}
+
+var _ IWidget = (*DiffWidget)(nil)
+var _ IDisplayPositions = (*DiffWidget)(nil)
+
+// NewDiff returns an edit whose text starts as the baseline.
+func NewDiff(baseline string, opts ...DiffOptions) *DiffWidget {
+	var opt DiffOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.InsertStyle == nil {
+		opt.InsertStyle = DefaultInsertStyle
+	}
+	if opt.DeleteStyle == nil {
+		opt.DeleteStyle = DefaultDeleteStyle
+	}
+	if opt.RevertKey == nil {
+		opt.RevertKey = DefaultRevertKey
+	}
+	return &DiffWidget{
+		Widget:   New(Options{Caption: opt.Caption, Text: baseline, Direction: opt.Direction}),
+		baseline: baseline,
+		opts:     opt,
+	}
+}
+
+func (w *DiffWidget) String() string {
+	return fmt.Sprintf("diffedit")
+}
+
+// Baseline returns the text the edit's text is compared with.
+func (w *DiffWidget) Baseline() string {
+	return w.baseline
+}
+
+// SetBaseline changes the text the edit's text is compared with - e.g. to the edit's text, once the
+// changes have been applied.
+func (w *DiffWidget) SetBaseline(baseline string, app gowid.IApp) {
+	w.baseline = baseline
+}
+
+// Changed returns true if the text differs from the baseline.
+func (w *DiffWidget) Changed() bool {
+	return w.Text() != w.baseline
+}
+
+// Revert sets the text back to the baseline, with the cursor at its end.
+func (w *DiffWidget) Revert(app gowid.IApp) {
+	w.SetText(w.baseline, app)
+	w.SetCursorPos(len([]rune(w.baseline)), app)
+}
+
+// Changes returns the differences between the baseline and the text, in order - the text with the
+// deleted pieces of the baseline among it.
+func (w *DiffWidget) Changes() []Change {
+	return append([]Change(nil), w.layout().changes...)
+}
+
+// layout returns the comparison of the text with the baseline, made again if either has changed.
+func (w *DiffWidget) layout() *diffLayout {
+	if w.diff == nil || w.diff.text != w.Text() || w.diff.baseline != w.baseline {
+		w.diff = makeDiffLayout(w.baseline, w.Text())
+	}
+	return w.diff
+}
+
+// DisplayPos returns the position among the runes displayed of position pos in the text, counting the
+// deleted text displayed before it.
+func (w *DiffWidget) DisplayPos(pos int) int {
+	d := w.layout()
+	if pos < 0 || pos >= len(d.toDisplay) {
+		return pos
+	}
+	return d.toDisplay[pos]
+}
+
+// TextPos returns the position in the text of position dpos among the runes displayed. Deleted text is
+// mapped to the place it was deleted from.
+func (w *DiffWidget) TextPos(dpos int) int {
+	d := w.layout()
+	if dpos < 0 {
+		return dpos
+	}
+	if dpos >= len(d.toText) {
+		dpos = len(d.toText) - 1
+	}
+	return d.toText[dpos]
+}
+
+// ChrAt returns the rune displayed at index i, counting the caption, and the deleted text displayed.
+func (w *DiffWidget) ChrAt(i int) rune {
+	caption := []rune(w.Caption())
+	if i >= 0 && i < len(caption) {
+		return caption[i]
+	}
+	d := w.layout()
+	if j := i - len(caption); j >= 0 && j < len(d.display) {
+		return d.display[j]
+	}
+	panic(errors.WithStack(gowid.WithKVs(InvalidRuneIndex, map[string]interface{}{"index": i, "text": w.Text()})))
+}
+
+func (w *DiffWidget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	return gowid.CalculateRenderSizeFallback(w, size, focus, app)
+}
+
+func (w *DiffWidget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	return Render(w, size, focus, app)
+}
+
+// MakeText returns the caption, then the text with the deleted text among it, each piece styled by how
+// it has changed.
+func (w *DiffWidget) MakeText() text.IWidget {
+	d := w.layout()
+	start, end, sel := w.Selection()
+	selected := gowid.MakeStyledAs(gowid.StyleReverse)
+
+	segs := make([]text.ContentSegment, 0, len(d.changes)+2)
+	if w.Caption() != "" {
+		segs = append(segs, text.StringContent(w.Caption()))
+	}
+	var style gowid.ICellStyler
+	from := 0
+	for i := 0; i <= len(d.display); i++ {
+		var s gowid.ICellStyler
+		if i < len(d.display) {
+			switch d.kinds[i] {
+			case Inserted:
+				s = w.opts.InsertStyle
+			case Deleted:
+				s = w.opts.DeleteStyle
+			}
+			if sel && d.kinds[i] != Deleted && d.toText[i] >= start && d.toText[i] < end {
+				s = selected
+			}
+		}
+		if i == len(d.display) || (i > from && s != style) {
+			if i > from {
+				if style == nil {
+					segs = append(segs, text.StringContent(string(d.display[from:i])))
+				} else {
+					segs = append(segs, text.StyledContent(string(d.display[from:i]), style))
+				}
+			}
+			from = i
+		}
+		style = s
+	}
+
+	tw := text.NewFromContentExt(text.NewContent(segs), text.Options{Direction: text.DirectionOf(w)})
+	tw.SetLinesFromTop(w.LinesFromTop(), nil)
+	cu := &text.SimpleCursor{-1}
+	cu.SetCursorPos(displayPos(w, w.CursorPos()), nil)
+	return &text.WidgetWithCursor{tw, cu}
+}
+
+// UserInput puts the baseline back on the revert key, and otherwise edits the text like a Widget.
+func (w *DiffWidget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if evk, ok := ev.(*tcell.EventKey); ok && gowid.KeysEqual(evk, w.opts.RevertKey) {
+		if !w.Changed() {
+			return false
+		}
+		w.Revert(app)
+		return true
+	}
+	return UserInput(w, ev, size, focus, app)
+}
+
+func (w *DiffWidget) DownLines(size gowid.IRenderSize, doPage bool, app gowid.IApp) bool {
+	return DownLines(w, size, doPage, app)
+}
+
+func (w *DiffWidget) UpLines(size gowid.IRenderSize, doPage bool, app gowid.IApp) bool {
+	return UpLines(w, size, doPage, app)
+}
+
+func (w *DiffWidget) CalculateTopMiddleBottom(size gowid.IRenderSize) (int, int, int) {
+	return CalculateTopMiddleBottom(w, size)
+}
+
+//======================================================================
+
+// diffLayout is the text with the text deleted from the baseline among it, as displayed.
+type diffLayout struct {
+	baseline  string
+	text      string
+	changes   []Change
+	display   []rune
+	kinds     []ChangeKind // Of each rune displayed
+	toDisplay []int        // The position displayed of each position in the text, and of its end
+	toText    []int        // The position in the text of each position displayed, and of its end
+}
+
+func makeDiffLayout(baseline, txt string) *diffLayout {
+	res := &diffLayout{
+		baseline: baseline,
+		text:     txt,
+		changes:  diffRunes([]rune(baseline), []rune(txt)),
+	}
+	pos := 0
+	for _, c := range res.changes {
+		for _, r := range c.Text {
+			if c.Kind != Deleted {
+				res.toDisplay = append(res.toDisplay, len(res.display))
+			}
+			res.toText = append(res.toText, pos)
+			res.display = append(res.display, r)
+			res.kinds = append(res.kinds, c.Kind)
+			if c.Kind != Deleted {
+				pos++
+			}
+		}
+	}
+	res.toDisplay = append(res.toDisplay, len(res.display))
+	res.toText = append(res.toText, pos)
+	return res
+}
+
+// diffRunes returns the changes that turn a into b, found from their longest common subsequence of
+// runes. Where a piece is replaced, the deletion comes before the insertion.
+func diffRunes(a, b []rune) []Change {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	am, bm := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	var res []Change
+	add := func(kind ChangeKind, r ...rune) {
+		if len(r) == 0 {
+			return
+		}
+		if n := len(res); n > 0 && res[n-1].Kind == kind {
+			res[n-1].Text += string(r)
+			return
+		}
+		res = append(res, Change{Kind: kind, Text: string(r)})
+	}
+	add(Unchanged, a[:prefix]...)
+
+	if len(am)*len(bm) > maxDiffCells {
+		add(Deleted, am...)
+		add(Inserted, bm...)
+	} else {
+		// lcs[i][j] is the length of the longest common subsequence of am[i:] and bm[j:]
+		cols := len(bm) + 1
+		lcs := make([]int, (len(am)+1)*cols)
+		for i := len(am) - 1; i >= 0; i-- {
+			for j := len(bm) - 1; j >= 0; j-- {
+				if am[i] == bm[j] {
+					lcs[i*cols+j] = lcs[(i+1)*cols+j+1] + 1
+				} else if lcs[(i+1)*cols+j] >= lcs[i*cols+j+1] {
+					lcs[i*cols+j] = lcs[(i+1)*cols+j]
+				} else {
+					lcs[i*cols+j] = lcs[i*cols+j+1]
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(am) || j < len(bm) {
+			switch {
+			case i < len(am) && j < len(bm) && am[i] == bm[j]:
+				add(Unchanged, am[i])
+				i, j = i+1, j+1
+			case j == len(bm) || (i < len(am) && lcs[(i+1)*cols+j] >= lcs[i*cols+j+1]):
+				add(Deleted, am[i])
+				i++
+			default:
+				add(Inserted, bm[j])
+				j++
+			}
+		}
+	}
+
+	add(Unchanged, a[len(a)-suffix:]...)
+	return res
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -2,12 +2,14 @@
 // code is governed by the MIT license that can be found in the LICENSE
 // file.
 
-// Package edit provides an editable text field widget with support for password hiding.
+// Package edit provides an editable text field widget with support for password hiding, and one that
+// shows how its text differs from a baseline.
 package edit
 
 import (
 	"fmt"
 	"io"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 
@@ -40,6 +42,15 @@ type Mask struct {
 	Enable bool
 }
 
+// MaskedCopyError is returned if CopyToClipboard is called on a masked widget.
+type MaskedCopyError struct{}
+
+var _ error = MaskedCopyError{}
+
+func (e MaskedCopyError) Error() string {
+	return "The text is masked and cannot be copied"
+}
+
 // For callback registration
 type Text struct{}
 type Caption struct{}
@@ -70,12 +81,39 @@ type IWidget interface {
 	DownLines(size gowid.IRenderSize, doPage bool, app gowid.IApp) bool
 }
 
+// ISelection is implemented by edits whose text can be selected - e.g. a word by double-clicking it, or a
+// line by triple-clicking it. Typing replaces the selection, and backspace or delete removes it.
+type ISelection interface {
+	Selection() (int, int, bool)
+	SetSelection(start, end int, app gowid.IApp)
+	ClearSelection(app gowid.IApp)
+}
+
+// IDisplayPositions is implemented by edits that display more than their caption and text - e.g. text
+// deleted since a baseline, see DiffWidget. DisplayPos maps a position in the text to one in what is
+// displayed after the caption, and TextPos maps it back.
+type IDisplayPositions interface {
+	DisplayPos(pos int) int
+	TextPos(dpos int) int
+}
+
+// IOverwrite is implemented by edits that can overwrite text as it is typed, rather than insert it. The
+// Insert key switches between the two.
+type IOverwrite interface {
+	Overwrite() bool
+	SetOverwrite(overwrite bool, app gowid.IApp)
+}
+
 type Widget struct {
 	IMask
 	caption      string
 	text         string
 	cursorPos    int
 	linesFromTop int
+	selStart     int
+	selEnd       int
+	direction    gowid.TextDirection
+	overwrite    bool
 	Callbacks    *gowid.Callbacks
 	gowid.IsSelectable
 }
@@ -83,6 +121,10 @@ type Widget struct {
 var _ fmt.Stringer = (*Widget)(nil)
 var _ io.Reader = (*Widget)(nil)
 var _ gowid.IWidget = (*Widget)(nil)
+var _ ISelection = (*Widget)(nil)
+var _ text.IDirection = (*Widget)(nil)
+var _ IOverwrite = (*Widget)(nil)
+var _ gowid.ICursorStyler = (*Widget)(nil)
 
 // Writer embeds an EditWidget and provides the io.Writer interface. An gowid.IApp needs to
 // be provided too because the widget's SetText() function requires it in order to issue
@@ -93,9 +135,11 @@ type Writer struct {
 }
 
 type Options struct {
-	Caption string
-	Text    string
-	Mask    IMask
+	Caption   string
+	Text      string
+	Mask      IMask
+	Direction gowid.TextDirection // With DirectionRTL, the text is aligned right
+	Overwrite bool                // If true, typing overwrites text rather than inserting it
 }
 
 func New(args ...Options) *Widget {
@@ -112,6 +156,8 @@ func New(args ...Options) *Widget {
 		text:         opt.Text,
 		cursorPos:    len(opt.Text),
 		linesFromTop: 0,
+		direction:    opt.Direction,
+		overwrite:    opt.Overwrite,
 		Callbacks:    gowid.NewCallbacks(),
 	}
 	return res
@@ -130,6 +176,23 @@ func (w *Writer) Write(p []byte) (n int, err error) {
 }
 
 // Set array from widget content
+func (w *Widget) AccessibleRole() gowid.Role {
+	return gowid.RoleEdit
+}
+
+// AccessibleLabel returns the caption, with whitespace at either end removed.
+func (w *Widget) AccessibleLabel(app gowid.IApp) string {
+	return strings.TrimSpace(w.caption)
+}
+
+// AccessibleState returns the text being edited, unless it is masked.
+func (w *Widget) AccessibleState(app gowid.IApp) string {
+	if w.UseMask() {
+		return ""
+	}
+	return w.text
+}
+
 func (w *Widget) Read(p []byte) (n int, err error) {
 	pl := len(p)
 	num := copy(p, w.text[:])
@@ -166,6 +229,7 @@ func (w *Widget) ChrAt(i int) rune {
 
 func (w *Widget) SetText(text string, app gowid.IApp) {
 	w.text = text
+	w.selStart, w.selEnd = 0, 0
 	wid := utf8.RuneCountInString(w.text)
 	if w.cursorPos > wid {
 		w.SetCursorPos(wid, app)
@@ -173,6 +237,42 @@ func (w *Widget) SetText(text string, app gowid.IApp) {
 	gowid.RunWidgetCallbacks(w.Callbacks, Text{}, app, w)
 }
 
+// CopyToClipboard sends the widget's text to the system clipboard with app.CopyToClipboard(). If the
+// widget masks its text, e.g. because it's a password, nothing is copied and a MaskedCopyError is
+// returned.
+func (w *Widget) CopyToClipboard(app gowid.IApp) error {
+	if w.UseMask() {
+		return MaskedCopyError{}
+	}
+	return app.CopyToClipboard(w.Text())
+}
+
+// Selection returns the start and end, as rune indices into the text, of the selected text. The end is
+// exclusive. The last value is false if nothing is selected.
+func (w *Widget) Selection() (int, int, bool) {
+	return w.selStart, w.selEnd, w.selEnd > w.selStart
+}
+
+// SetSelection selects the text from rune index start up to end, and moves the cursor to end.
+func (w *Widget) SetSelection(start, end int, app gowid.IApp) {
+	l := utf8.RuneCountInString(w.Text())
+	w.selStart = gwutil.Max(0, gwutil.Min(start, l))
+	w.selEnd = gwutil.Max(w.selStart, gwutil.Min(end, l))
+	w.SetCursorPos(w.selEnd, app)
+}
+
+func (w *Widget) ClearSelection(app gowid.IApp) {
+	w.selStart, w.selEnd = 0, 0
+}
+
+// SelectedText returns the selected text, or "" if nothing is selected.
+func (w *Widget) SelectedText() string {
+	if start, end, ok := w.Selection(); ok {
+		return string([]rune(w.Text())[start:end])
+	}
+	return ""
+}
+
 func (w *Widget) LinesFromTop() int {
 	return w.linesFromTop
 }
@@ -190,6 +290,35 @@ func (w *Widget) SetCaption(text string, app gowid.IApp) {
 	gowid.RunWidgetCallbacks(w.Callbacks, Caption{}, app, w)
 }
 
+// Direction returns the direction of the edit's paragraphs - by default, gowid.DirectionAuto. In a
+// right-to-left paragraph, the left and right arrow keys move the cursor forward and back through the
+// text respectively.
+func (w *Widget) Direction() gowid.TextDirection {
+	return w.direction
+}
+
+func (w *Widget) SetDirection(dir gowid.TextDirection, app gowid.IApp) {
+	w.direction = dir
+}
+
+// Overwrite returns true if typing overwrites text rather than inserting it.
+func (w *Widget) Overwrite() bool {
+	return w.overwrite
+}
+
+func (w *Widget) SetOverwrite(overwrite bool, app gowid.IApp) {
+	w.overwrite = overwrite
+}
+
+// CursorStyle asks for a bar cursor while typing inserts text, and a block while it overwrites text. It
+// lets the widget conform to gowid.ICursorStyler.
+func (w *Widget) CursorStyle() gowid.CursorStyle {
+	if w.overwrite {
+		return gowid.CursorStyle{Shape: gowid.CursorBlock}
+	}
+	return gowid.CursorStyle{Shape: gowid.CursorBar}
+}
+
 func (w *Widget) CursorEnabled() bool {
 	return w.cursorPos != -1
 }
@@ -284,14 +413,26 @@ func MakeText(w IWidget) text.IWidget {
 		txt = w.Text()
 	}
 
-	//txt = w.Caption() + "\u00A0" + txt
-	txt = w.Caption() + txt
-
-	tw := text.New(txt)
+	var tw *text.Widget
+	opts := text.Options{Direction: text.DirectionOf(w)}
+	if sw, ok := w.(ISelection); ok {
+		if start, end, ok := sw.Selection(); ok {
+			r := []rune(txt)
+			tw = text.NewFromContentExt(text.NewContent([]text.ContentSegment{
+				text.StringContent(w.Caption() + string(r[:start])),
+				text.StyledContent(string(r[start:end]), gowid.MakeStyledAs(gowid.StyleReverse)),
+				text.StringContent(string(r[end:])),
+			}), opts)
+		}
+	}
+	if tw == nil {
+		//txt = w.Caption() + "\u00A0" + txt
+		tw = text.New(w.Caption()+txt, opts)
+	}
 	tw.SetLinesFromTop(w.LinesFromTop(), nil)
 
 	cu := &text.SimpleCursor{-1}
-	cu.SetCursorPos(w.CursorPos()+utf8.RuneCountInString(w.Caption()), nil)
+	cu.SetCursorPos(displayPos(w, w.CursorPos()), nil)
 	twc := &text.WidgetWithCursor{tw, cu}
 
 	return twc
@@ -302,19 +443,44 @@ func CalculateTopMiddleBottom(w IWidget, size gowid.IRenderSize) (int, int, int)
 	return text.CalculateTopMiddleBottom(twc, size)
 }
 
+// makeLayout lays out twc, the text made by MakeText, in cols columns.
+func makeLayout(twc text.IWidget, cols int) *text.TextLayout {
+	res := text.MakeTextLayout(twc.Content(), cols, text.WrapAny, twc.Align())
+	res.Direction = text.DirectionOf(twc)
+	return res
+}
+
+// displayPos returns the position, among the runes displayed, of position pos in the text - after the
+// caption, and anything else displayed before it.
+func displayPos(w IWidget, pos int) int {
+	if dw, ok := w.(IDisplayPositions); ok {
+		pos = dw.DisplayPos(pos)
+	}
+	return pos + utf8.RuneCountInString(w.Caption())
+}
+
+// textPos returns the position in the text of position dpos among the runes displayed, or a negative
+// number if it is in the caption.
+func textPos(w IWidget, dpos int) int {
+	dpos -= utf8.RuneCountInString(w.Caption())
+	if dw, ok := w.(IDisplayPositions); ok && dpos >= 0 {
+		return dw.TextPos(dpos)
+	}
+	return dpos
+}
+
 // Return true if done
 func DownLines(w IWidget, size gowid.IRenderSize, doPage bool, app gowid.IApp) bool {
 	prev := w.CursorPos()
 
 	twc := w.MakeText()
-	caplen := utf8.RuneCountInString(w.Caption())
 	// This incorporates the caption too
 	cols, ok := size.(gowid.IColumns)
 	if !ok {
 		panic(gowid.WidgetSizeError{Widget: w, Size: size, Required: "gowid.IColumns"})
 	}
-	layout := text.MakeTextLayout(twc.Content(), cols.Columns(), text.WrapAny, gowid.HAlignLeft{})
-	ccol, crow := text.GetCoordsFromCursorPos(w.CursorPos()+caplen, cols.Columns(), layout, w)
+	layout := makeLayout(twc, cols.Columns())
+	ccol, crow := text.GetCoordsFromCursorPos(displayPos(w, w.CursorPos()), cols.Columns(), layout, w)
 	offset := 1
 	if rows, ok := size.(gowid.IRows); ok && doPage {
 		if crow < w.LinesFromTop()+rows.Rows()-1 {
@@ -327,7 +493,7 @@ func DownLines(w IWidget, size gowid.IRenderSize, doPage bool, app gowid.IApp) b
 	}
 
 	targetRow := crow + offset
-	newCursorPos := text.GetCursorPosFromCoords(ccol, targetRow, layout, w) - caplen
+	newCursorPos := textPos(w, text.GetCursorPosFromCoords(ccol, targetRow, layout, w))
 	if newCursorPos < 0 {
 		return false
 	} else {
@@ -344,15 +510,14 @@ func DownLines(w IWidget, size gowid.IRenderSize, doPage bool, app gowid.IApp) b
 
 // Return true if done
 func UpLines(w IWidget, size gowid.IRenderSize, doPage bool, app gowid.IApp) bool {
-	caplen := utf8.RuneCountInString(w.Caption())
 	prev := w.CursorPos()
 	twc := w.MakeText()
 	cols, isColumns := size.(gowid.IColumns)
 	if !isColumns {
 		panic(gowid.WidgetSizeError{Widget: w, Size: size, Required: "gowid.IColumns"})
 	}
-	layout := text.MakeTextLayout(twc.Content(), cols.Columns(), text.WrapAny, gowid.HAlignLeft{})
-	ccol, crow := text.GetCoordsFromCursorPos(w.CursorPos()+caplen, cols.Columns(), layout, w)
+	layout := makeLayout(twc, cols.Columns())
+	ccol, crow := text.GetCoordsFromCursorPos(displayPos(w, w.CursorPos()), cols.Columns(), layout, w)
 
 	if crow <= 0 {
 		return false
@@ -367,7 +532,7 @@ func UpLines(w IWidget, size gowid.IRenderSize, doPage bool, app gowid.IApp) boo
 		}
 		targetCol := gwutil.Max(crow-offset, 0)
 
-		newCursorPos := text.GetCursorPosFromCoords(ccol, targetCol, layout, w) - caplen
+		newCursorPos := textPos(w, text.GetCursorPosFromCoords(ccol, targetCol, layout, w))
 		if newCursorPos < 0 {
 			return false
 		} else {
@@ -382,12 +547,170 @@ func UpLines(w IWidget, size gowid.IRenderSize, doPage bool, app gowid.IApp) boo
 	}
 }
 
+// selectByClick selects the word at pos on a double click, and the line on a triple click. A single click
+// clears the selection.
+func selectByClick(w IWidget, sw ISelection, pos int, ev interface{}, app gowid.IApp) {
+	cev, ok := gowid.ClickEventFor(ev, app)
+	if !ok || cev.Released {
+		return
+	}
+	txt := []rune(w.Text())
+	inRange := func(i int, word bool) bool {
+		if i < 0 || i >= len(txt) || txt[i] == '\n' {
+			return false
+		}
+		// Combining marks, like accents, belong to the letter before them
+		return !word || unicode.IsLetter(txt[i]) || unicode.IsDigit(txt[i]) || txt[i] == '_' ||
+			unicode.In(txt[i], unicode.Mn, unicode.Me)
+	}
+	switch {
+	case cev.Count == 2 && !w.UseMask():
+		start, end := pos, pos
+		for inRange(start-1, true) {
+			start--
+		}
+		for inRange(end, true) {
+			end++
+		}
+		sw.SetSelection(start, end, app)
+	case cev.Count >= 3:
+		start, end := pos, pos
+		for inRange(start-1, false) {
+			start--
+		}
+		for inRange(end, false) {
+			end++
+		}
+		sw.SetSelection(start, end, app)
+	default:
+		sw.ClearSelection(app)
+	}
+}
+
+// prevGrapheme returns the position of the start of the grapheme cluster before pos in txt, so the cursor
+// moves over an accented letter or an emoji sequence as one character.
+func prevGrapheme(txt []rune, pos int) int {
+	starts := gowid.GraphemeStarts(txt)
+	for pos > 0 {
+		pos--
+		if starts[pos] {
+			break
+		}
+	}
+	return pos
+}
+
+// nextGrapheme returns the position of the start of the grapheme cluster after the one at pos in txt.
+func nextGrapheme(txt []rune, pos int) int {
+	starts := gowid.GraphemeStarts(txt)
+	for pos < len(txt) {
+		pos++
+		if pos == len(txt) || starts[pos] {
+			break
+		}
+	}
+	return pos
+}
+
+// cursorBack moves the cursor to the previous grapheme cluster, returning false if it is at the start.
+func cursorBack(w IWidget, app gowid.IApp) bool {
+	if w.CursorPos() == 0 {
+		return false
+	}
+	w.SetCursorPos(prevGrapheme([]rune(w.Text()), w.CursorPos()), app)
+	return true
+}
+
+// cursorForward moves the cursor to the next grapheme cluster, returning false if it is at the end.
+func cursorForward(w IWidget, app gowid.IApp) bool {
+	if w.CursorPos() >= utf8.RuneCountInString(w.Text()) {
+		return false
+	}
+	w.SetCursorPos(nextGrapheme([]rune(w.Text()), w.CursorPos()), app)
+	return true
+}
+
+// rightToLeft returns true if the cursor is in a paragraph that runs right-to-left, given the edit's
+// direction. The caption counts as part of the first paragraph, as it does when the edit is rendered.
+func rightToLeft(w IWidget) bool {
+	caption := []rune(w.Caption())
+	txt := []rune(w.Text())
+	if w.UseMask() {
+		for i := range txt {
+			txt[i] = w.MaskChr()
+		}
+	}
+	bidi := gowid.NewBidiText(append(caption, txt...), text.DirectionOf(w))
+	return bidi.BaseLevel(len(caption)+w.CursorPos())%2 == 1
+}
+
+// replaceSelection removes the selected text, if any, and inserts ins in its place. It returns false if
+// nothing was selected.
+func replaceSelection(w IWidget, ins string, app gowid.IApp) bool {
+	sw, ok := w.(ISelection)
+	if !ok {
+		return false
+	}
+	start, end, ok := sw.Selection()
+	if !ok {
+		return false
+	}
+	r := []rune(w.Text())
+	w.SetText(string(r[:start])+ins+string(r[end:]), app)
+	w.SetCursorPos(start+utf8.RuneCountInString(ins), app)
+	return true
+}
+
+// typeRune inserts ch at the cursor - or, if w is in overwrite mode, replaces the character at the
+// cursor, unless that ends a line.
+func typeRune(w IWidget, ch rune, app gowid.IApp) {
+	// TODO: this is lame. Inserting a character is O(n) where n is length
+	// of text. I should switch this to use the two stack model for edited
+	// text.
+	r := []rune(w.Text())
+	cpos := w.CursorPos()
+	end := cpos
+	if ow, ok := w.(IOverwrite); ok && ow.Overwrite() && cpos < len(r) && r[cpos] != '\n' {
+		end = cpos + 1
+	}
+	rhs := make([]rune, len(r)-end)
+	copy(rhs, r[end:])
+	w.SetText(string(append(append(r[:cpos], ch), rhs...)), app)
+	w.SetCursorPos(cpos+1, app)
+}
+
 func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if sw, ok := w.(ISelection); ok {
+		if _, _, ok := sw.Selection(); ok {
+			switch ev := ev.(type) {
+			case *gowid.PasteEvent:
+				return replaceSelection(w, ev.Text, app)
+			case *tcell.EventKey:
+				switch ev.Key() {
+				case tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyDelete, tcell.KeyCtrlD:
+					return replaceSelection(w, "", app)
+				case tcell.KeyRune:
+					return replaceSelection(w, string(ev.Rune()), app)
+				default:
+					sw.ClearSelection(app)
+				}
+			}
+		}
+	}
+
 	handled := true
 	doup := false
 	dodown := false
 	recalcLinesFromTop := false
 	switch ev := ev.(type) {
+	case *gowid.PasteEvent:
+		// Insert the whole paste at once - newlines in it are text, not Enter keypresses
+		r := []rune(w.Text())
+		cpos := w.CursorPos()
+		w.SetText(string(r[0:cpos])+ev.Text+string(r[cpos:]), app)
+		w.SetCursorPos(cpos+utf8.RuneCountInString(ev.Text), app)
+		recalcLinesFromTop = true
+
 	case *tcell.EventMouse:
 		switch ev.Buttons() {
 		case tcell.WheelUp:
@@ -400,13 +723,16 @@ func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Se
 			if !isColumns {
 				panic(gowid.WidgetSizeError{Widget: w, Size: size, Required: "gowid.IColumns"})
 			}
-			layout := text.MakeTextLayout(twc.Content(), cols.Columns(), text.WrapAny, gowid.HAlignLeft{})
+			layout := makeLayout(twc, cols.Columns())
 			mx, my := ev.Position()
-			cursorPos := text.GetCursorPosFromCoords(mx, my+w.LinesFromTop(), layout, w) - (utf8.RuneCountInString(w.Caption()))
+			cursorPos := textPos(w, text.GetCursorPosFromCoords(mx, my+w.LinesFromTop(), layout, w))
 			if cursorPos < 0 {
 				handled = false
 			} else {
 				w.SetCursorPos(cursorPos, app)
+				if sw, ok := w.(ISelection); ok {
+					selectByClick(w, sw, cursorPos, ev, app)
+				}
 				handled = true
 			}
 		default:
@@ -424,37 +750,42 @@ func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Se
 		case tcell.KeyPgDn:
 			handled = w.DownLines(size, true, app)
 		case tcell.KeyLeft, tcell.KeyCtrlB:
-			if w.CursorPos() > 0 {
-				w.SetCursorPos(w.CursorPos()-1, app)
+			if ev.Key() == tcell.KeyLeft && rightToLeft(w) {
+				handled = cursorForward(w, app)
 			} else {
-				handled = false
+				handled = cursorBack(w, app)
 			}
 		case tcell.KeyRight, tcell.KeyCtrlF:
-			if w.CursorPos() < utf8.RuneCountInString(w.Text()) {
-				w.SetCursorPos(w.CursorPos()+1, app)
+			if ev.Key() == tcell.KeyRight && rightToLeft(w) {
+				handled = cursorBack(w, app)
 			} else {
-				handled = false
+				handled = cursorForward(w, app)
 			}
 		case tcell.KeyBackspace, tcell.KeyBackspace2:
 			if w.CursorPos() > 0 {
 				pos := w.CursorPos()
-				w.SetCursorPos(w.CursorPos()-1, app)
 				r := []rune(w.Text())
-				w.SetText(string(r[0:pos-1])+string(r[pos:]), app)
+				prev := prevGrapheme(r, pos)
+				w.SetCursorPos(prev, app)
+				w.SetText(string(r[0:prev])+string(r[pos:]), app)
 			}
 		case tcell.KeyDelete, tcell.KeyCtrlD:
 			if w.CursorPos() < utf8.RuneCountInString(w.Text()) {
 				r := []rune(w.Text())
-				w.SetText(string(r[0:w.CursorPos()])+string(r[w.CursorPos()+1:]), app)
+				w.SetText(string(r[0:w.CursorPos()])+string(r[nextGrapheme(r, w.CursorPos()):]), app)
 			}
 		case tcell.KeyEnter:
 			r := []rune(w.Text())
 			w.SetText(string(r[0:w.CursorPos()])+string('\n')+string(r[w.CursorPos():]), app)
 			w.SetCursorPos(w.CursorPos()+1, app)
 		case tcell.Key(' '):
-			r := []rune(w.Text())
-			w.SetText(string(r[0:w.CursorPos()])+" "+string(r[w.CursorPos():]), app)
-			w.SetCursorPos(w.CursorPos()+1, app)
+			typeRune(w, ' ', app)
+		case tcell.KeyInsert:
+			if ow, ok := w.(IOverwrite); ok {
+				ow.SetOverwrite(!ow.Overwrite(), app)
+			} else {
+				handled = false
+			}
 		case tcell.KeyCtrlK:
 			r := []rune(w.Text())
 			w.SetText(string(r[0:w.CursorPos()]), app)
@@ -530,16 +861,7 @@ func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Se
 			recalcLinesFromTop = true
 
 		case tcell.KeyRune:
-			// TODO: this is lame. Inserting a character is O(n) where n is length
-			// of text. I should switch this to use the two stack model for edited
-			// text.
-			txt := w.Text()
-			r := []rune(txt)
-			cpos := w.CursorPos()
-			rhs := make([]rune, len(r)-cpos)
-			copy(rhs, r[cpos:])
-			w.SetText(string(append(append(r[:cpos], ev.Rune()), rhs...)), app)
-			w.SetCursorPos(w.CursorPos()+1, app)
+			typeRune(w, ev.Rune(), app)
 
 		default:
 			handled = false
@@ -556,9 +878,8 @@ func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Se
 	box, ok := size.(gowid.IRenderBox)
 	if recalcLinesFromTop && ok {
 		twc := w.MakeText()
-		caplen := utf8.RuneCountInString(w.Caption())
-		layout := text.MakeTextLayout(twc.Content(), box.BoxColumns(), text.WrapAny, gowid.HAlignLeft{})
-		_, crow := text.GetCoordsFromCursorPos(w.CursorPos()+caplen, box.BoxColumns(), layout, w)
+		layout := makeLayout(twc, box.BoxColumns())
+		_, crow := text.GetCoordsFromCursorPos(displayPos(w, w.CursorPos()), box.BoxColumns(), layout, w)
 		w.SetLinesFromTop(gwutil.Max(0, crow-(box.BoxRows()-1)), app)
 	}
 
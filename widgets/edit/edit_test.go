@@ -169,6 +169,131 @@ func TestEdit1(t *testing.T) {
 
 }
 
+func TestGraphemes1(t *testing.T) {
+	// The cursor moves over an accented letter and an emoji sequence as one character
+	w := New(Options{Text: "ae\u0301\U0001F44D\U0001F3FDb"})
+	sz := gowid.RenderFlowWith{C: 10}
+	c1 := w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "ae\u0301\U0001F44D\U0001F3FDb     ", c1.String())
+
+	w.SetCursorPos(1, gwtest.D)
+	w.UserInput(gwtest.CursorRight(), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, 3, w.CursorPos())
+	w.UserInput(gwtest.CursorRight(), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, 5, w.CursorPos())
+	c1 = w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, gowid.CanvasPos{X: 4, Y: 0}, c1.CursorCoords())
+	w.UserInput(gwtest.CursorLeft(), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, 3, w.CursorPos())
+
+	// Deleting removes the whole cluster
+	w.UserInput(tcell.NewEventKey(tcell.KeyDelete, ' ', tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "ae\u0301b", w.Text())
+	w.UserInput(tcell.NewEventKey(tcell.KeyBackspace, ' ', tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "ab", w.Text())
+	assert.Equal(t, 1, w.CursorPos())
+}
+
+func TestBidi1(t *testing.T) {
+	w := New(Options{Text: "\u05d0\u05d1\u05d2", Direction: gowid.DirectionRTL})
+	w.SetCursorPos(0, gwtest.D)
+	sz := gowid.RenderFlowWith{C: 6}
+	c1 := w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "   \u05d2\u05d1\u05d0", c1.String())
+	assert.Equal(t, gowid.CanvasPos{X: 5, Y: 0}, c1.CursorCoords())
+
+	// The arrow keys move the cursor the way they point
+	w.UserInput(gwtest.CursorLeft(), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, 1, w.CursorPos())
+	c1 = w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, gowid.CanvasPos{X: 4, Y: 0}, c1.CursorCoords())
+	w.UserInput(gwtest.CursorRight(), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, 0, w.CursorPos())
+
+	// At the end of the text, the cursor is to its left
+	w.SetCursorPos(3, gwtest.D)
+	c1 = w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, gowid.CanvasPos{X: 2, Y: 0}, c1.CursorCoords())
+
+	w.UserInput(evclick(4, 0), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, 1, w.CursorPos())
+	w.UserInput(evclick(0, 0), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, 3, w.CursorPos())
+}
+
+func TestOverwrite1(t *testing.T) {
+	w := New(Options{Text: "ab\ncd"})
+	sz := gowid.RenderFlowWith{C: 5}
+	evx := tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone)
+	evins := tcell.NewEventKey(tcell.KeyInsert, ' ', tcell.ModNone)
+
+	assert.Equal(t, gowid.CursorBar, w.CursorStyle().Shape)
+	assert.True(t, w.UserInput(evins, sz, gowid.Focused, gwtest.D))
+	assert.True(t, w.Overwrite())
+	assert.Equal(t, gowid.CursorBlock, w.CursorStyle().Shape)
+
+	w.SetCursorPos(1, gwtest.D)
+	w.UserInput(evx, sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "ax\ncd", w.Text())
+	// The end of a line isn't overwritten
+	w.UserInput(evx, sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "axx\ncd", w.Text())
+
+	w.UserInput(evins, sz, gowid.Focused, gwtest.D)
+	w.SetCursorPos(0, gwtest.D)
+	w.UserInput(evx, sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "xaxx\ncd", w.Text())
+}
+
+func TestDiff1(t *testing.T) {
+	assert.Equal(t, []Change{
+		{Unchanged, "port="},
+		{Deleted, "80"},
+		{Inserted, "443"},
+		{Unchanged, " on"},
+	}, diffRunes([]rune("port=80 on"), []rune("port=443 on")))
+	assert.Equal(t, []Change{{Inserted, "new"}}, diffRunes(nil, []rune("new")))
+	assert.Equal(t, []Change(nil), diffRunes(nil, nil))
+
+	w := NewDiff("a=1", DiffOptions{Caption: "> "})
+	sz := gowid.RenderFlowWith{C: 10}
+	assert.False(t, w.Changed())
+	assert.Equal(t, "> a=1     ", w.Render(sz, gowid.Focused, gwtest.D).String())
+
+	// Deleted text is displayed where it was, and the cursor steps over it
+	bs := tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone)
+	w.UserInput(bs, sz, gowid.Focused, gwtest.D)
+	w.UserInput(tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "a=2", w.Text())
+	assert.True(t, w.Changed())
+	c := w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "> a=12    ", c.String())
+	assert.Equal(t, 4, w.DisplayPos(w.CursorPos()))
+	w.UserInput(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, 2, w.CursorPos())
+	assert.Equal(t, 3, w.DisplayPos(w.CursorPos()))
+
+	// Inserted and deleted text are styled differently
+	_, _, ins := c.CellAt(5, 0).GetDisplayAttrs()
+	_, _, del := c.CellAt(4, 0).GetDisplayAttrs()
+	assert.NotEqual(t, ins, del)
+
+	// A click on deleted text puts the cursor where it was deleted from
+	w.UserInput(evclick(4, 0), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, 2, w.CursorPos())
+
+	// The revert key puts the baseline back, and isn't handled if there is nothing to revert
+	revert := tcell.NewEventKey(tcell.KeyCtrlR, 0, tcell.ModCtrl)
+	assert.True(t, w.UserInput(revert, sz, gowid.Focused, gwtest.D))
+	assert.Equal(t, "a=1", w.Text())
+	assert.False(t, w.UserInput(revert, sz, gowid.Focused, gwtest.D))
+
+	w.SetText("a=3", gwtest.D)
+	w.SetBaseline("a=3", gwtest.D)
+	assert.False(t, w.Changed())
+	assert.Equal(t, []Change{{Unchanged, "a=3"}}, w.Changes())
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
@@ -0,0 +1,341 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package effects provides brief visual effects drawn over a widget - a flash, a pulsing border, or a
+// shower of confetti - e.g. to show that an action succeeded, or to draw the eye to a part of the screen.
+package effects
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+)
+
+//======================================================================
+
+// EffectDoneCB is the callback name used when an effect plays to its end.
+type EffectDoneCB struct{}
+
+// Frame describes the moment of an effect to draw.
+type Frame struct {
+	Progress float64 // The fraction of the effect's duration that has passed, from 0 to 1
+	// Still, if true, means motion should be reduced - see App.SetReduceMotion. The effect should be
+	// drawn without movement, and is drawn the same way for its whole duration.
+	Still bool
+	Seed  int64 // Different each time an effect is played, for effects that scatter things at random
+}
+
+// IEffect is an effect that can be played over a widget.
+type IEffect interface {
+	Length() time.Duration // How long the effect plays for
+	// Draw changes the canvas rendered by the widget to show a frame of the effect.
+	Draw(canvas gowid.ICanvas, frame Frame, app gowid.IApp)
+}
+
+// Widget draws its inner widget, and the effect playing over it, if any. Effects are timed with the app's
+// clock, like gowid.Tween, so tests can step through them with a gowid.FakeClock.
+type Widget struct {
+	gowid.IWidget
+	effect IEffect
+	frame  Frame
+	tween  *gowid.Tween
+	hold   *gowid.Timer // Ends an effect drawn without motion
+	*gowid.Callbacks
+	gowid.SubWidgetCallbacks
+}
+
+func New(inner gowid.IWidget) *Widget {
+	res := &Widget{
+		IWidget: inner,
+	}
+	res.SubWidgetCallbacks = gowid.SubWidgetCallbacks{CB: &res.Callbacks}
+	var _ gowid.ICompositeWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("effects[%v]", w.SubWidget())
+}
+
+func (w *Widget) SubWidget() gowid.IWidget {
+	return w.IWidget
+}
+
+func (w *Widget) SetSubWidget(wi gowid.IWidget, app gowid.IApp) {
+	w.IWidget = wi
+	gowid.RunWidgetCallbacks(w.Callbacks, gowid.SubWidgetCB{}, app, w)
+}
+
+func (w *Widget) SubWidgetSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	return size
+}
+
+// Effect returns the effect playing, or nil if there is none.
+func (w *Widget) Effect() IEffect {
+	return w.effect
+}
+
+// Running returns true if an effect is playing.
+func (w *Widget) Running() bool {
+	return w.effect != nil
+}
+
+// Play starts an effect over the widget, replacing any that is playing. It must be called on the app
+// goroutine. If motion should be reduced in app - see gowid.ReduceMotion - the effect is drawn still for its
+// duration.
+func (w *Widget) Play(e IEffect, app gowid.IApp) {
+	w.stop()
+	clock := gowid.ClockFor(app)
+	w.effect = e
+	w.frame = Frame{Seed: clock.Now().UnixNano()}
+	if gowid.ReduceMotion(app) {
+		w.frame.Still = true
+		w.hold = gowid.After(app, e.Length(), func(app gowid.IApp) {
+			w.hold = nil
+			w.done(app)
+		})
+		return
+	}
+	w.tween = gowid.NewTween(gowid.TweenOptions{
+		From:     0,
+		To:       1,
+		Duration: e.Length(),
+		Easing:   gowid.Linear,
+		OnUpdate: func(v float64, app gowid.IApp) {
+			w.frame.Progress = v
+		},
+		OnDone: func(app gowid.IApp) {
+			w.done(app)
+		},
+	})
+	w.tween.Start(app)
+}
+
+// Stop ends the effect playing, if any, at once. Callbacks registered with OnEffectDone are not run.
+func (w *Widget) Stop(app gowid.IApp) {
+	w.stop()
+}
+
+func (w *Widget) stop() {
+	if w.tween != nil {
+		w.tween.Stop()
+		w.tween = nil
+	}
+	if w.hold != nil {
+		w.hold.Stop()
+		w.hold = nil
+	}
+	w.effect = nil
+}
+
+func (w *Widget) done(app gowid.IApp) {
+	w.stop()
+	gowid.RunWidgetCallbacks(w.Callbacks, EffectDoneCB{}, app, w)
+}
+
+// OnEffectDone registers a callback run when an effect plays to its end.
+func (w *Widget) OnEffectDone(f gowid.IWidgetChangedCallback) {
+	if w.Callbacks == nil {
+		w.Callbacks = gowid.NewCallbacks()
+	}
+	gowid.AddWidgetCallback(w.Callbacks, EffectDoneCB{}, f)
+}
+
+func (w *Widget) RemoveOnEffectDone(f gowid.IIdentity) {
+	if w.Callbacks != nil {
+		gowid.RemoveWidgetCallback(w.Callbacks, EffectDoneCB{}, f)
+	}
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	res := gowid.Render(w.SubWidget(), size, focus, app)
+	if w.effect != nil {
+		// The inner widget may have returned a canvas it keeps, so draw on a copy
+		res = res.Duplicate()
+		w.effect.Draw(res, w.frame, app)
+	}
+	return res
+}
+
+//======================================================================
+
+var (
+	DefaultFlashDuration    = 400 * time.Millisecond
+	DefaultPulseDuration    = 1200 * time.Millisecond
+	DefaultConfettiDuration = 1500 * time.Millisecond
+)
+
+// Flash tints the widget with a color, which fades away. Drawn still, the tint stays until the end.
+type Flash struct {
+	Color    gowid.IColor
+	Opacity  float64       // How much the tint hides the widget at first, from 0 to 1; defaults to 0.5
+	Duration time.Duration // Defaults to DefaultFlashDuration
+}
+
+var _ IEffect = Flash{}
+
+func (f Flash) Length() time.Duration {
+	if f.Duration > 0 {
+		return f.Duration
+	}
+	return DefaultFlashDuration
+}
+
+func (f Flash) Draw(canvas gowid.ICanvas, frame Frame, app gowid.IApp) {
+	opacity := f.Opacity
+	if opacity <= 0 {
+		opacity = 0.5
+	}
+	if !frame.Still {
+		opacity *= 1 - frame.Progress
+	}
+	gowid.ShadeCanvas(canvas, tcellColor(f.Color, app), opacity)
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// Pulse brightens the cells around the edge of the widget - e.g. its frame - with a color, then lets them
+// fade, a number of times. Drawn still, the edge keeps the color until the end.
+type Pulse struct {
+	Color    gowid.IColor
+	Pulses   int           // Defaults to 2
+	Duration time.Duration // Defaults to DefaultPulseDuration
+}
+
+var _ IEffect = Pulse{}
+
+func (p Pulse) Length() time.Duration {
+	if p.Duration > 0 {
+		return p.Duration
+	}
+	return DefaultPulseDuration
+}
+
+func (p Pulse) Draw(canvas gowid.ICanvas, frame Frame, app gowid.IApp) {
+	pulses := p.Pulses
+	if pulses <= 0 {
+		pulses = 2
+	}
+	amount := 1.0
+	if !frame.Still {
+		s := math.Sin(math.Pi * float64(pulses) * frame.Progress)
+		amount = s * s
+	}
+	color := tcellColor(p.Color, app)
+	cols, rows := canvas.BoxColumns(), canvas.BoxRows()
+	for y := 0; y < rows; y++ {
+		step := 1
+		if y > 0 && y < rows-1 && cols > 1 {
+			step = cols - 1 // Only the left and right edges
+		}
+		for x := 0; x < cols; x += step {
+			canvas.SetCellAt(x, y, tint(canvas.CellAt(x, y), color, amount))
+		}
+	}
+}
+
+// tint returns the cell with its rune drawn in a color blended with the one given, or, if it draws no
+// rune, its background.
+func tint(c gowid.Cell, color gowid.TCellColor, amount float64) gowid.Cell {
+	if c.Rune() != ' ' {
+		fg, _ := gowid.BlendColors(c.ForegroundColor(), color, amount)
+		return c.WithForegroundColor(fg)
+	}
+	bg, _ := gowid.BlendColors(c.BackgroundColor(), color, amount)
+	return c.WithBackgroundColor(bg)
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+var (
+	// The pieces of confetti, which twinkle through these as they fall
+	confettiRunes = []rune{'*', '+', '•', '✦', '·'}
+	// Drawn instead if widgets should draw with ASCII only - see App.SetASCIIOnly
+	confettiASCII = []rune{'*', '+', 'o', '*', '.'}
+)
+
+// Confetti scatters pieces of confetti over the widget, which twinkle as they fall. Drawn still, the
+// pieces are scattered, but don't move.
+type Confetti struct {
+	Colors   []gowid.IColor // The pieces take each in turn; if empty, they take the colors of the widget
+	Density  float64        // The fraction of the widget's cells with a piece at once; defaults to 0.1
+	Seed     int64          // If not 0, the pieces are scattered the same way each time, e.g. for tests
+	Duration time.Duration  // Defaults to DefaultConfettiDuration
+}
+
+var _ IEffect = Confetti{}
+
+func (c Confetti) Length() time.Duration {
+	if c.Duration > 0 {
+		return c.Duration
+	}
+	return DefaultConfettiDuration
+}
+
+func (c Confetti) Draw(canvas gowid.ICanvas, frame Frame, app gowid.IApp) {
+	cols, rows := canvas.BoxColumns(), canvas.BoxRows()
+	if cols <= 0 || rows <= 0 {
+		return
+	}
+	density := c.Density
+	if density <= 0 {
+		density = 0.1
+	}
+	seed := c.Seed
+	if seed == 0 {
+		seed = frame.Seed
+	}
+	t, twinkle := 0.5, 0
+	if !frame.Still {
+		t, twinkle = frame.Progress, int(frame.Progress*8)
+	}
+	runes := confettiRunes
+	if gowid.ASCIIOnly(app) {
+		runes = confettiASCII
+	}
+
+	// The same seed places each piece in the same way every frame, so it falls steadily
+	r := rand.New(rand.NewSource(seed))
+	n := gwutil.Max(1, int(density*float64(cols*rows)+0.5))
+	for i := 0; i < n; i++ {
+		x := r.Intn(cols)
+		top := (r.Float64() - 1) * float64(rows)  // Pieces start above the widget...
+		fall := (1 + r.Float64()) * float64(rows) // ...and fall past its bottom by the end
+		drift := float64(r.Intn(3)-1) * 2
+		kind := r.Intn(len(runes))
+
+		px, py := x+int(gwutil.Round(drift*t)), int(math.Floor(top+fall*t))
+		if px < 0 || px >= cols || py < 0 || py >= rows {
+			continue
+		}
+		cell := canvas.CellAt(px, py)
+		if cell.Width() != 1 {
+			continue
+		}
+		cell = cell.WithRune(runes[(kind+twinkle)%len(runes)])
+		if len(c.Colors) > 0 {
+			cell = cell.WithForegroundColor(tcellColor(c.Colors[i%len(c.Colors)], app))
+		}
+		canvas.SetCellAt(px, py, cell)
+	}
+}
+
+//======================================================================
+
+func tcellColor(color gowid.IColor, app gowid.IApp) gowid.TCellColor {
+	if color == nil {
+		return gowid.ColorNone
+	}
+	return gowid.IColorToTCell(color, gowid.ColorNone, app.GetColorMode())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
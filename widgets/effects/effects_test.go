@@ -0,0 +1,80 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package effects
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestPlay1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := New(text.New("hello"))
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 5, Rows: 1, Clock: clock})
+	defer sim.Close()
+
+	done := 0
+	w.OnEffectDone(gowid.WidgetCallback{Name: "test", WidgetChangedFunction: func(app gowid.IApp, _ gowid.IWidget) {
+		done++
+	}})
+
+	red := gowid.MakeRGBColor("#f00")
+	w.Play(Pulse{Color: red, Pulses: 1, Duration: 100 * time.Millisecond}, sim)
+	assert.True(t, w.Running())
+	clock.Advance(50 * time.Millisecond)
+	sim.Frame()
+	c := w.Render(gowid.RenderFlowWith{C: 5}, gowid.NotSelected, sim)
+	assert.Equal(t, "hello", c.String())
+	// The text has the terminal's default color, which can't be blended, so the edge takes the pulse's
+	assert.Equal(t, gowid.IColorToTCell(red, gowid.ColorNone, sim.GetColorMode()), c.CellAt(0, 0).ForegroundColor())
+
+	clock.Advance(60 * time.Millisecond)
+	sim.Frame()
+	assert.False(t, w.Running())
+	assert.Equal(t, 1, done)
+	assert.Equal(t, 0, clock.Pending())
+
+	w.Play(Flash{Color: red}, sim)
+	w.Stop(sim)
+	assert.False(t, w.Running())
+	assert.Equal(t, 0, clock.Pending())
+	assert.Equal(t, 1, done)
+}
+
+func TestReduceMotion1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := New(text.New("          \n          \n          "))
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 10, Rows: 3, Clock: clock})
+	defer sim.Close()
+	sim.SetReduceMotion(true)
+
+	sz := gowid.RenderFlowWith{C: 10}
+	w.Play(Confetti{Seed: 1, Density: 0.3, Duration: 100 * time.Millisecond}, sim)
+	assert.True(t, w.Running())
+	first := w.Render(sz, gowid.NotSelected, sim).String()
+	assert.NotEqual(t, "          \n          \n          ", first)
+
+	// The pieces don't move
+	clock.Advance(50 * time.Millisecond)
+	sim.Frame()
+	assert.Equal(t, first, w.Render(sz, gowid.NotSelected, sim).String())
+
+	clock.Advance(50 * time.Millisecond)
+	sim.Frame()
+	assert.False(t, w.Running())
+	assert.Equal(t, "          \n          \n          ", w.Render(sz, gowid.NotSelected, sim).String())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,348 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package facets provides a sidebar for exploring data by its facets - the values of each of its fields,
+// with how many records have them. Checking values builds a filter, which can be applied to a table or
+// list showing the data.
+package facets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/checkbox"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/list"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/styled"
+	"github.com/gcla/gowid/widgets/text"
+)
+
+//======================================================================
+
+// FilterChangedCB is the callback name used when values are checked or unchecked.
+type FilterChangedCB struct{}
+
+// DefaultSearchCaption is shown before the search box, unless Options says otherwise.
+var DefaultSearchCaption = "Search: "
+
+// Options is used to configure the widget.
+type Options struct {
+	// Separator, if set, makes values hierarchical - "Europe/France" is listed beneath "Europe", which
+	// counts the records of all the values beneath it, and checking it selects them all.
+	Separator     string
+	SortByCount   bool              // If true, values are listed with the most records first, not by name
+	NoSearch      bool              // If true, there is no search box
+	SearchCaption string            // Defaults to DefaultSearchCaption
+	HeaderStyle   gowid.ICellStyler // The style of the field names; if nil, they are unstyled
+}
+
+// Widget is a sidebar listing the facets of some data. Each field is a heading, which can be collapsed,
+// over its values, each with a checkbox and the number of records that have it. Checking values filters
+// the data - within a field, a record can have any of the values checked, and it must match the values
+// checked for every field. Each field's counts are of the records matched by the values checked for the
+// other fields. Typing in the search box lists only the values containing the text typed.
+type Widget struct {
+	*pile.Widget
+	data      IData
+	opts      Options
+	search    *edit.Widget
+	listw     *list.Widget
+	selected  map[string]map[string]bool // The values checked, by field
+	collapsed map[string]bool
+	keys      []string // Identifies each row of the list, so the focus can be kept as the list changes
+	ascii     bool     // True if the headings were made to be drawn with ASCII only
+	*gowid.Callbacks
+}
+
+func New(data IData, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.SearchCaption == "" {
+		opt.SearchCaption = DefaultSearchCaption
+	}
+	res := &Widget{
+		data:      data,
+		opts:      opt,
+		selected:  make(map[string]map[string]bool),
+		collapsed: make(map[string]bool),
+	}
+	res.listw = list.New(list.NewSimpleListWalker(nil))
+	cws := []gowid.IContainerWidget{
+		&gowid.ContainerWidget{IWidget: res.listw, D: gowid.RenderWithWeight{W: 1}},
+	}
+	if !opt.NoSearch {
+		res.search = edit.New(edit.Options{Caption: opt.SearchCaption})
+		res.search.OnTextSet(gowid.WidgetCallback{Name: "facets", WidgetChangedFunction: func(app gowid.IApp, w gowid.IWidget) {
+			res.Refresh(app)
+		}})
+		cws = append([]gowid.IContainerWidget{&gowid.ContainerWidget{IWidget: res.search, D: gowid.RenderFlow{}}}, cws...)
+	}
+	res.Widget = pile.New(cws)
+	res.Refresh(nil)
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("facets[%d]", len(w.data.Fields()))
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	// The headings are made before the app is known
+	if gowid.ASCIIOnly(app) != w.ascii {
+		w.Refresh(app)
+	}
+	return w.Widget.Render(size, focus, app)
+}
+
+// Data returns the data the sidebar lists the facets of.
+func (w *Widget) Data() IData {
+	return w.data
+}
+
+// SetData lists the facets of new data, keeping the values checked.
+func (w *Widget) SetData(data IData, app gowid.IApp) {
+	w.data = data
+	w.Refresh(app)
+}
+
+// Filter returns the values checked, with a clause for each field that has any, in the order of the
+// data's fields.
+func (w *Widget) Filter() Filter {
+	res := Filter{Separator: w.opts.Separator}
+	for _, field := range w.data.Fields() {
+		values := make([]string, 0, len(w.selected[field]))
+		for _, n := range w.flatten(field, w.tree(field, Filter{Separator: w.opts.Separator})) {
+			if w.selected[field][n.path] {
+				values = append(values, n.path)
+			}
+		}
+		if len(values) > 0 {
+			res.Clauses = append(res.Clauses, Clause{Field: field, Values: values})
+		}
+	}
+	return res
+}
+
+// SetFilter checks the values in filter, and unchecks all others. Callbacks registered with
+// OnFilterChanged are run.
+func (w *Widget) SetFilter(filter Filter, app gowid.IApp) {
+	w.selected = make(map[string]map[string]bool)
+	for _, c := range filter.Clauses {
+		for _, v := range c.Values {
+			w.setSelected(c.Field, v, true)
+		}
+	}
+	w.changed(app)
+}
+
+// Clear unchecks every value.
+func (w *Widget) Clear(app gowid.IApp) {
+	w.SetFilter(Filter{}, app)
+}
+
+// Selected returns true if value of field is checked.
+func (w *Widget) Selected(field string, value string) bool {
+	return w.selected[field][value]
+}
+
+// SetSelected checks or unchecks value of field. Callbacks registered with OnFilterChanged are run.
+func (w *Widget) SetSelected(field string, value string, selected bool, app gowid.IApp) {
+	if w.Selected(field, value) == selected {
+		return
+	}
+	w.setSelected(field, value, selected)
+	w.changed(app)
+}
+
+func (w *Widget) setSelected(field string, value string, selected bool) {
+	if !selected {
+		delete(w.selected[field], value)
+		return
+	}
+	if w.selected[field] == nil {
+		w.selected[field] = make(map[string]bool)
+	}
+	w.selected[field][value] = true
+}
+
+// Collapsed returns true if the values of field are hidden beneath its heading.
+func (w *Widget) Collapsed(field string) bool {
+	return w.collapsed[field]
+}
+
+// SetCollapsed hides or shows the values of field. They are shown while searching, whatever this says.
+func (w *Widget) SetCollapsed(field string, collapsed bool, app gowid.IApp) {
+	w.collapsed[field] = collapsed
+	w.Refresh(app)
+}
+
+// Counts returns the number of records with each value of field, among those matched by the values checked
+// for the other fields. If values are hierarchical, a value counts the records of the values beneath it.
+func (w *Widget) Counts(field string) map[string]int {
+	res := make(map[string]int)
+	for _, n := range w.flatten(field, w.tree(field, w.Filter())) {
+		res[n.path] = n.count
+	}
+	return res
+}
+
+// Search returns the text typed in the search box.
+func (w *Widget) Search() string {
+	if w.search == nil {
+		return ""
+	}
+	return w.search.Text()
+}
+
+// SetSearch lists only the values containing s, ignoring case, and those checked.
+func (w *Widget) SetSearch(s string, app gowid.IApp) {
+	if w.search != nil {
+		w.search.SetText(s, app)
+	}
+}
+
+// OnFilterChanged registers a callback run when values are checked or unchecked.
+func (w *Widget) OnFilterChanged(f gowid.IWidgetChangedCallback) {
+	if w.Callbacks == nil {
+		w.Callbacks = gowid.NewCallbacks()
+	}
+	gowid.AddWidgetCallback(w.Callbacks, FilterChangedCB{}, f)
+}
+
+func (w *Widget) RemoveOnFilterChanged(f gowid.IIdentity) {
+	if w.Callbacks != nil {
+		gowid.RemoveWidgetCallback(w.Callbacks, FilterChangedCB{}, f)
+	}
+}
+
+func (w *Widget) changed(app gowid.IApp) {
+	w.Refresh(app)
+	gowid.RunWidgetCallbacks(w.Callbacks, FilterChangedCB{}, app, w)
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+func (w *Widget) tree(field string, filter Filter) []*node {
+	return facetTree(w.data, field, filter, w.opts.SortByCount)
+}
+
+// flatten returns the nodes of a facet's tree, each before those beneath it.
+func (w *Widget) flatten(field string, nodes []*node) []*node {
+	res := make([]*node, 0, len(nodes))
+	for _, n := range nodes {
+		res = append(res, n)
+		res = append(res, w.flatten(field, n.children)...)
+	}
+	return res
+}
+
+// shown returns true if n should be listed while searching for s - if it or a value beneath it contains
+// s, or is checked.
+func (w *Widget) shown(field string, n *node, s string) bool {
+	if s == "" || w.selected[field][n.path] || strings.Contains(strings.ToLower(n.label), s) {
+		return true
+	}
+	for _, c := range n.children {
+		if w.shown(field, c, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Refresh lists the facets again - e.g. after the data has changed. The focus stays on the same
+// heading or value, if it is still listed.
+func (w *Widget) Refresh(app gowid.IApp) {
+	focus := ""
+	if pos, ok := w.listw.Walker().Focus().(list.ListPos); ok && pos >= 0 && int(pos) < len(w.keys) {
+		focus = w.keys[pos]
+	}
+
+	s := strings.ToLower(strings.TrimSpace(w.Search()))
+	filter := w.Filter()
+	rows := make([]gowid.IWidget, 0)
+	keys := make([]string, 0)
+	for _, field := range w.data.Fields() {
+		field := field
+		roots := w.tree(field, filter)
+		matches := s == "" || strings.Contains(strings.ToLower(field), s)
+		var values []gowid.IWidget
+		var valueKeys []string
+		var addNodes func(nodes []*node, depth int)
+		addNodes = func(nodes []*node, depth int) {
+			for _, n := range nodes {
+				if !matches && !w.shown(field, n, s) {
+					continue
+				}
+				values = append(values, w.valueRow(field, n, depth))
+				valueKeys = append(valueKeys, field+"\x00"+n.path)
+				addNodes(n.children, depth+1)
+			}
+		}
+		if s != "" || !w.collapsed[field] {
+			addNodes(roots, 0)
+		}
+		if !matches && len(values) == 0 {
+			continue
+		}
+		rows = append(rows, w.headerRow(field, s != "", app))
+		keys = append(keys, field)
+		rows = append(rows, values...)
+		keys = append(keys, valueKeys...)
+	}
+
+	w.keys = keys
+	w.ascii = gowid.ASCIIOnly(app)
+	walker := list.NewSimpleListWalker(rows)
+	for i, k := range keys {
+		if k == focus {
+			walker.SetFocus(list.ListPos(i), app)
+			break
+		}
+	}
+	w.listw.SetWalker(walker, app)
+}
+
+// headerRow returns the heading of a field, which collapses and expands its values when clicked.
+func (w *Widget) headerRow(field string, searching bool, app gowid.IApp) gowid.IWidget {
+	glyph := gowid.ASCIIRune(app, '▾', 'v')
+	if w.collapsed[field] && !searching {
+		glyph = gowid.ASCIIRune(app, '▸', '>')
+	}
+	var label gowid.IWidget = text.New(string(glyph) + " " + field)
+	if w.opts.HeaderStyle != nil {
+		label = styled.New(label, w.opts.HeaderStyle)
+	}
+	res := button.NewBare(label)
+	res.OnClick(gowid.WidgetCallback{Name: "facets", WidgetChangedFunction: func(app gowid.IApp, _ gowid.IWidget) {
+		w.SetCollapsed(field, !w.collapsed[field], app)
+	}})
+	return res
+}
+
+// valueRow returns a value of a field, indented by its depth, with a checkbox and its count.
+func (w *Widget) valueRow(field string, n *node, depth int) gowid.IWidget {
+	path := n.path
+	cb := checkbox.New(w.selected[field][path])
+	cb.OnClick(gowid.WidgetCallback{Name: "facets", WidgetChangedFunction: func(app gowid.IApp, _ gowid.IWidget) {
+		w.SetSelected(field, path, cb.IsChecked(), app)
+	}})
+	return columns.New([]gowid.IContainerWidget{
+		&gowid.ContainerWidget{IWidget: text.New(strings.Repeat("  ", depth+1)), D: gowid.RenderFixed{}},
+		&gowid.ContainerWidget{IWidget: cb, D: gowid.RenderFixed{}},
+		&gowid.ContainerWidget{IWidget: text.New(fmt.Sprintf(" %s (%d)", n.label, n.count)), D: gowid.RenderWithWeight{W: 1}},
+	})
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,111 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package facets
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+type records [][2]string
+
+func (r records) Fields() []string {
+	return []string{"kind", "region"}
+}
+
+func (r records) Records() int {
+	return len(r)
+}
+
+func (r records) Value(record int, field string) string {
+	if field == "kind" {
+		return r[record][0]
+	}
+	return r[record][1]
+}
+
+var data = records{
+	{"bug", "Europe/France"},
+	{"bug", "Europe/Spain"},
+	{"feature", "Europe/France"},
+	{"feature", "Asia"},
+	{"bug", ""},
+}
+
+func TestFilter1(t *testing.T) {
+	f := Filter{
+		Clauses:   []Clause{{Field: "kind", Values: []string{"bug"}}, {Field: "region", Values: []string{"Europe"}}},
+		Separator: "/",
+	}
+	assert.Equal(t, `kind in ("bug") and region in ("Europe")`, f.String())
+	matched := []int{}
+	for r := 0; r < data.Records(); r++ {
+		if f.Matches(data, r) {
+			matched = append(matched, r)
+		}
+	}
+	assert.Equal(t, []int{0, 1}, matched)
+	assert.Equal(t, "", Filter{}.String())
+}
+
+func TestFacets1(t *testing.T) {
+	w := New(data, Options{Separator: "/", NoSearch: true})
+	changes := 0
+	w.OnFilterChanged(gowid.WidgetCallback{Name: "test", WidgetChangedFunction: func(app gowid.IApp, w gowid.IWidget) {
+		changes++
+	}})
+	sz := gowid.RenderBox{C: 18, R: 8}
+	c := w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "▾ kind            \n"+
+		"  [ ] bug (3)     \n"+
+		"  [ ] feature (2) \n"+
+		"▾ region          \n"+
+		"  [ ] Asia (1)    \n"+
+		"  [ ] Europe (3)  \n"+
+		"    [ ] France (2)\n"+
+		"    [ ] Spain (1) ", c.String())
+
+	// Check "bug" - the regions are counted over bugs only
+	w.UserInput(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	w.UserInput(tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, 1, changes)
+	assert.True(t, w.Selected("kind", "bug"))
+	assert.Equal(t, map[string]int{"Asia": 0, "Europe": 2, "Europe/France": 1, "Europe/Spain": 1}, w.Counts("region"))
+	assert.Equal(t, map[string]int{"bug": 3, "feature": 2}, w.Counts("kind"))
+
+	w.SetSelected("region", "Europe/France", true, gwtest.D)
+	assert.Equal(t, `kind in ("bug") and region in ("Europe/France")`, w.Filter().String())
+
+	w.SetCollapsed("region", true, gwtest.D)
+	c = w.Render(gowid.RenderBox{C: 18, R: 4}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "▾ kind            \n"+
+		"  [X] bug (1)     \n"+
+		"  [ ] feature (1) \n"+
+		"▸ region          ", c.String())
+
+	w.Clear(gwtest.D)
+	assert.True(t, w.Filter().IsEmpty())
+}
+
+func TestSearch1(t *testing.T) {
+	w := New(data, Options{Separator: "/"})
+	w.SetSearch("fra", gwtest.D)
+	c := w.Render(gowid.RenderBox{C: 18, R: 4}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "Search: fra       \n"+
+		"▾ region          \n"+
+		"  [ ] Europe (3)  \n"+
+		"    [ ] France (2)", c.String())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,165 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package facets
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//======================================================================
+
+// IData is the data a sidebar finds its facets in - records with a value for each of a set of fields,
+// e.g. the rows of a table. An empty value means the record has none for that field.
+type IData interface {
+	Fields() []string // The fields to facet by, in the order they are listed
+	Records() int
+	Value(record int, field string) string
+}
+
+// Clause selects the records whose value for Field is one of Values. If the filter has a separator, a
+// value also selects the records with values beneath it - "Europe" selects "Europe/France".
+type Clause struct {
+	Field  string
+	Values []string
+}
+
+// Filter is the selection made in a sidebar - a record matches if it matches every clause. It can be
+// applied to the sidebar's data with Matches, or turned into an expression for a query with String.
+type Filter struct {
+	Clauses   []Clause
+	Separator string // Separates the levels of hierarchical values; empty if values are flat
+}
+
+// IsEmpty returns true if the filter selects every record.
+func (f Filter) IsEmpty() bool {
+	return len(f.Clauses) == 0
+}
+
+// Values returns the values selected for field, or nil if every value is.
+func (f Filter) Values(field string) []string {
+	for _, c := range f.Clauses {
+		if c.Field == field {
+			return c.Values
+		}
+	}
+	return nil
+}
+
+// Matches returns true if record of data is selected by the filter.
+func (f Filter) Matches(data IData, record int) bool {
+	return f.matchesExcept(data, record, "")
+}
+
+// matchesExcept returns true if record is selected by every clause but the one for field - so that a
+// facet's counts show what selecting another of its values would add.
+func (f Filter) matchesExcept(data IData, record int, field string) bool {
+	for _, c := range f.Clauses {
+		if c.Field == field {
+			continue
+		}
+		v := data.Value(record, c.Field)
+		found := false
+		for _, want := range c.Values {
+			if v == want || (f.Separator != "" && strings.HasPrefix(v, want+f.Separator)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the filter as an expression, with a clause per field joined by "and" - e.g.
+// `kind in ("bug", "feature") and region in ("Europe")`. It is empty if the filter selects every record.
+func (f Filter) String() string {
+	clauses := make([]string, 0, len(f.Clauses))
+	for _, c := range f.Clauses {
+		values := make([]string, len(c.Values))
+		for i, v := range c.Values {
+			values[i] = strconv.Quote(v)
+		}
+		clauses = append(clauses, c.Field+" in ("+strings.Join(values, ", ")+")")
+	}
+	return strings.Join(clauses, " and ")
+}
+
+//======================================================================
+
+// node is a value of a facet, and the values beneath it if values are hierarchical.
+type node struct {
+	path     string // The whole value, e.g. "Europe/France"
+	label    string // The last level of the value, e.g. "France"
+	count    int    // Records matching the rest of the filter with this value, or one beneath it
+	children []*node
+}
+
+// facetTree returns the values of field found in data, as a tree if sep isn't empty, counted over the
+// records that match every clause of filter but field's.
+func facetTree(data IData, field string, filter Filter, byCount bool) []*node {
+	sep := filter.Separator
+	nodes := make(map[string]*node)
+	roots := make([]*node, 0)
+	var add func(path string) *node
+	add = func(path string) *node {
+		if n, ok := nodes[path]; ok {
+			return n
+		}
+		n := &node{path: path, label: path}
+		nodes[path] = n
+		if i := strings.LastIndex(path, sep); sep != "" && i > 0 {
+			n.label = path[i+len(sep):]
+			parent := add(path[:i])
+			parent.children = append(parent.children, n)
+		} else {
+			roots = append(roots, n)
+		}
+		return n
+	}
+
+	for r := 0; r < data.Records(); r++ {
+		v := data.Value(r, field)
+		if v == "" {
+			continue
+		}
+		n := add(v)
+		if !filter.matchesExcept(data, r, field) {
+			continue
+		}
+		for path := n.path; ; {
+			nodes[path].count++
+			i := strings.LastIndex(path, sep)
+			if sep == "" || i <= 0 {
+				break
+			}
+			path = path[:i]
+		}
+	}
+
+	sortNodes(roots, byCount)
+	return roots
+}
+
+func sortNodes(nodes []*node, byCount bool) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if byCount && nodes[i].count != nodes[j].count {
+			return nodes[i].count > nodes[j].count
+		}
+		return nodes[i].label < nodes[j].label
+	})
+	for _, n := range nodes {
+		sortNodes(n.children, byCount)
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
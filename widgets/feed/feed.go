@@ -0,0 +1,540 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package feed provides a widget that displays an activity feed or audit log - timestamped events, newest
+// first, grouped under a heading for each day. Bursts of similar events are collapsed into one row, older
+// events are fetched as the user scrolls back through history, and new events can be added at the top
+// while the user reads further down.
+package feed
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/list"
+	"github.com/gcla/gowid/widgets/selectable"
+	"github.com/gcla/gowid/widgets/styled"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// DefaultFetchSize is the number of older events asked for at a time, unless Options says otherwise.
+var DefaultFetchSize = 50
+
+// DefaultBurstWindow is the longest gap between similar events that are collapsed together, unless
+// Options says otherwise.
+var DefaultBurstWindow = time.Minute
+
+// DefaultMinBurst is the fewest similar events that are collapsed into one row, unless Options says
+// otherwise.
+var DefaultMinBurst = 3
+
+// Event is one entry in the feed.
+type Event struct {
+	Time  time.Time
+	Text  string
+	Kind  string            // By default, events of the same kind that happen close together are collapsed
+	Style gowid.ICellStyler // If not nil, the style of the event's text
+}
+
+// FetchFunc returns up to limit events older than before, newest first, and whether there are more to
+// come. If before is zero, the newest events are wanted. It is called on a goroutine of its own, so may
+// take its time - e.g. to query a server - but should give up when ctx is done.
+type FetchFunc func(ctx context.Context, before time.Time, limit int) ([]Event, bool, error)
+
+// Options is used to configure the widget.
+type Options struct {
+	Fetch        FetchFunc             // If not nil, used to load older events as the user scrolls back
+	FetchSize    int                   // Events asked for at a time; defaults to DefaultFetchSize
+	BurstWindow  time.Duration         // Defaults to DefaultBurstWindow; negative to never collapse events
+	MinBurst     int                   // Defaults to DefaultMinBurst
+	Similar      func(a, b Event) bool // Defaults to comparing the events' non-empty Kinds
+	Location     *time.Location        // Where days begin and end; defaults to time.Local
+	HeadingStyle gowid.ICellStyler     // The style of the day headings; defaults to bold
+	TimeStyle    gowid.ICellStyler     // If not nil, the style of each event's time
+	FocusStyle   gowid.ICellStyler     // If not nil, the focused row is styled with this
+}
+
+//======================================================================
+
+type IWidget interface {
+	list.IWidget
+	Events() []Event
+}
+
+// Widget displays its events, newest first. Times are shown relative to the app's clock - e.g. "5m ago" -
+// for today's events, and the day headings say "Today" and "Yesterday"; these are brought up to date
+// each minute, whenever the feed is drawn. While the newest event has the focus, new events are shown
+// as they arrive; otherwise the focus stays where it is, so the user can read back undisturbed.
+type Widget struct {
+	*list.Widget
+	opts     Options
+	walker   *walker
+	events   []*entry // Newest first
+	more     bool     // True if Fetch may have older events
+	fetching bool
+	fetchErr error
+	gen      int       // Incremented by Clear, so that events fetched before are discarded
+	builtAt  time.Time // The minute the rows were built in, by the app's clock
+}
+
+// entry is an event the widget holds.
+type entry struct {
+	Event
+	expanded bool // True if the burst the event is part of has been expanded
+}
+
+func New(opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.FetchSize <= 0 {
+		opt.FetchSize = DefaultFetchSize
+	}
+	if opt.BurstWindow == 0 {
+		opt.BurstWindow = DefaultBurstWindow
+	}
+	if opt.MinBurst <= 0 {
+		opt.MinBurst = DefaultMinBurst
+	}
+	if opt.Similar == nil {
+		opt.Similar = func(a, b Event) bool {
+			return a.Kind != "" && a.Kind == b.Kind
+		}
+	}
+	if opt.Location == nil {
+		opt.Location = time.Local
+	}
+	if opt.HeadingStyle == nil {
+		opt.HeadingStyle = gowid.MakeStyledAs(gowid.StyleBold)
+	}
+	res := &Widget{
+		opts: opt,
+		more: opt.Fetch != nil,
+	}
+	res.walker = &walker{w: res}
+	res.Widget = list.New(res.walker)
+	var _ IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("feed[%d events]", len(w.events))
+}
+
+// Events returns the events the widget holds, newest first.
+func (w *Widget) Events() []Event {
+	res := make([]Event, 0, len(w.events))
+	for _, e := range w.events {
+		res = append(res, e.Event)
+	}
+	return res
+}
+
+// Fetching returns true while older events are being fetched.
+func (w *Widget) Fetching() bool {
+	return w.fetching
+}
+
+// More returns true if there may be older events to fetch.
+func (w *Widget) More() bool {
+	return w.more
+}
+
+// Add adds new events to the feed, in time order. Like the other methods of the widget, it must be
+// called from the app's goroutine - from another, use app.Run.
+func (w *Widget) Add(app gowid.IApp, events ...Event) {
+	for _, ev := range events {
+		w.insert(&entry{Event: ev})
+	}
+	w.refresh(app)
+}
+
+// Clear discards the events displayed so far. If the feed fetches events, it starts again from the newest.
+func (w *Widget) Clear(app gowid.IApp) {
+	w.events = nil
+	w.more = w.opts.Fetch != nil
+	w.fetching = false
+	w.fetchErr = nil
+	w.gen++
+	w.refresh(app)
+}
+
+// insert adds e in time order. New events usually arrive in order, so the search starts from the front.
+func (w *Widget) insert(e *entry) {
+	i := 0
+	for i < len(w.events) && w.events[i].Time.After(e.Time) {
+		i++
+	}
+	w.events = append(w.events, nil)
+	copy(w.events[i+1:], w.events[i:])
+	w.events[i] = e
+}
+
+// fetch asks for the events older than those held, on a goroutine of its own.
+func (w *Widget) fetch(app gowid.IApp) {
+	if w.fetching || !w.more || w.fetchErr != nil {
+		return
+	}
+	w.fetching = true
+	var before time.Time
+	if len(w.events) > 0 {
+		before = w.events[len(w.events)-1].Time
+	}
+	ctx, gen := gowid.ContextFor(app), w.gen
+	go func() {
+		events, more, err := w.opts.Fetch(ctx, before, w.opts.FetchSize)
+		app.Run(gowid.RunFunction(func(app gowid.IApp) {
+			if w.gen != gen {
+				return
+			}
+			w.fetching = false
+			w.fetchErr = err
+			if err == nil {
+				w.more = more
+				for _, ev := range events {
+					w.insert(&entry{Event: ev})
+				}
+			}
+			w.refresh(app)
+		}))
+	}()
+}
+
+// retry fetches again after an error.
+func (w *Widget) retry(app gowid.IApp) {
+	w.fetchErr = nil
+	w.refresh(app)
+	w.fetch(app)
+}
+
+func (w *Widget) toggle(entries []*entry, app gowid.IApp) {
+	expand := !entries[0].expanded
+	for _, e := range entries {
+		e.expanded = expand
+	}
+	w.refresh(app)
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	if !gowid.ClockFor(app).Now().Truncate(time.Minute).Equal(w.builtAt) {
+		w.refresh(app)
+	}
+	w.walker.tailShown = false
+	res := w.Widget.Render(size, focus, app)
+	// Scrolling back to the end of the events fetches more
+	if w.walker.tailShown {
+		w.fetch(app)
+	}
+	return res
+}
+
+//======================================================================
+
+type rowKind int
+
+const (
+	headingRow rowKind = iota
+	eventRow
+	burstRow
+	tailRow // Loading, or an error fetching
+)
+
+// row is one row of the feed.
+type row struct {
+	kind    rowKind
+	entries []*entry // The event, or those in a burst
+	widget  gowid.IWidget
+}
+
+func (r *row) contains(e *entry) bool {
+	for _, re := range r.entries {
+		if re == e {
+			return true
+		}
+	}
+	return false
+}
+
+// UserInput passes the event to the list. Moving up from the newest event, which the list can't do because
+// the heading above it isn't selectable, scrolls to the top so the heading is shown.
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if w.Widget.UserInput(ev, size, focus, app) {
+		return true
+	}
+	if evk, ok := ev.(*tcell.EventKey); ok && evk.Key() == tcell.KeyUp {
+		if top := firstSelectable(w.walker.rows); top > 0 && w.walker.focus == top {
+			w.walker.focus = 0
+			w.GoToTop(app)
+			return true
+		}
+	}
+	return false
+}
+
+// firstSelectable returns the index of the first row that can take the focus, or -1.
+func firstSelectable(rows []*row) int {
+	for i, r := range rows {
+		if r.widget.Selectable() {
+			return i
+		}
+	}
+	return -1
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// Heading returns the heading of the day t is in, relative to now - "Today", "Yesterday", or the date.
+func Heading(t, now time.Time) string {
+	switch {
+	case sameDay(t, now):
+		return "Today"
+	case sameDay(t, now.AddDate(0, 0, -1)):
+		return "Yesterday"
+	case t.Year() == now.Year():
+		return t.Format("Monday 2 January")
+	default:
+		return t.Format("Monday 2 January 2006")
+	}
+}
+
+// RelativeTime returns the time of an event relative to now - e.g. "just now", "5m ago" or "3h ago" for
+// an event today, otherwise the time of day.
+func RelativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case !sameDay(t, now) || d < 0:
+		return t.Format("15:04")
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	default:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	}
+}
+
+// burstLen returns the number of similar events, starting at i, that happened close together on the same day.
+func (w *Widget) burstLen(i int) int {
+	if w.opts.BurstWindow < 0 {
+		return 1
+	}
+	j := i + 1
+	for j < len(w.events) {
+		prev, cur := w.events[j-1], w.events[j]
+		if !w.opts.Similar(prev.Event, cur.Event) || prev.Time.Sub(cur.Time) > w.opts.BurstWindow ||
+			!sameDay(prev.Time.In(w.opts.Location), cur.Time.In(w.opts.Location)) {
+			break
+		}
+		j++
+	}
+	return j - i
+}
+
+func (w *Widget) eventWidget(e *entry, indent string, now time.Time) gowid.IWidget {
+	when := fmt.Sprintf("%s%-9s", indent, RelativeTime(e.Time.In(w.opts.Location), now))
+	return text.NewFromContent(text.NewContent([]text.ContentSegment{
+		w.segment(when, w.opts.TimeStyle),
+		w.segment(e.Text, e.Style),
+	}))
+}
+
+func (w *Widget) segment(s string, style gowid.ICellStyler) text.ContentSegment {
+	if style == nil {
+		return text.StringContent(s)
+	}
+	return text.StyledContent(s, style)
+}
+
+func (w *Widget) focusable(inner gowid.IWidget) gowid.IWidget {
+	if w.opts.FocusStyle != nil {
+		return styled.NewFocus(inner, w.opts.FocusStyle)
+	}
+	return inner
+}
+
+// buildRows lays out the events, newest first, under day headings.
+func (w *Widget) buildRows(now time.Time) []*row {
+	res := []*row{}
+	var day time.Time
+	for i := 0; i < len(w.events); {
+		e := w.events[i]
+		t := e.Time.In(w.opts.Location)
+		if i == 0 || !sameDay(t, day) {
+			day = t
+			res = append(res, &row{
+				kind:   headingRow,
+				widget: styled.New(text.New(Heading(t, now)), w.opts.HeadingStyle),
+			})
+		}
+		n := w.burstLen(i)
+		if n < w.opts.MinBurst {
+			n = 1
+		}
+		if n == 1 {
+			res = append(res, &row{
+				kind:    eventRow,
+				entries: []*entry{e},
+				widget:  selectable.New(w.focusable(w.eventWidget(e, "", now))),
+			})
+			i++
+			continue
+		}
+
+		burst := append([]*entry(nil), w.events[i:i+n]...)
+		// The newest event of a collapsed burst stands for the rest
+		content := []text.ContentSegment{
+			w.segment(fmt.Sprintf("%-9s", RelativeTime(t, now)), w.opts.TimeStyle),
+			text.StringContent(fmt.Sprintf("[-] %d similar events", n)),
+		}
+		if !e.expanded {
+			content[1] = text.StringContent(fmt.Sprintf("[+] %d similar events: ", n))
+			content = append(content, w.segment(e.Text, e.Style))
+		}
+		btn := button.NewBare(w.focusable(text.NewFromContent(text.NewContent(content))))
+		btn.OnClick(gowid.WidgetCallback{Name: "feed", WidgetChangedFunction: func(app gowid.IApp, _ gowid.IWidget) {
+			w.toggle(burst, app)
+		}})
+		res = append(res, &row{kind: burstRow, entries: burst, widget: btn})
+		if e.expanded {
+			for _, be := range burst {
+				res = append(res, &row{
+					kind:    eventRow,
+					entries: []*entry{be},
+					widget:  selectable.New(w.focusable(w.eventWidget(be, "  ", now))),
+				})
+			}
+		}
+		i += n
+	}
+
+	switch {
+	case w.fetchErr != nil:
+		msg := fmt.Sprintf("Could not load older events: %v - press Enter to retry", w.fetchErr)
+		btn := button.NewBare(w.focusable(text.New(msg)))
+		btn.OnClick(gowid.WidgetCallback{Name: "feed", WidgetChangedFunction: func(app gowid.IApp, _ gowid.IWidget) {
+			w.retry(app)
+		}})
+		res = append(res, &row{kind: tailRow, widget: btn})
+	case w.more:
+		res = append(res, &row{kind: tailRow, widget: text.New("Loading older events...")})
+	}
+	return res
+}
+
+// refresh rebuilds the rows, keeping the focus on the same event - or at the top, if it was on the newest
+// event before.
+func (w *Widget) refresh(app gowid.IApp) {
+	wk := w.walker
+	now := gowid.ClockFor(app).Now()
+	w.builtAt = now.Truncate(time.Minute)
+	top := firstSelectable(wk.rows)
+	following := top == -1 || wk.focus <= top
+	var focused *entry
+	tail := false
+	if !following && wk.focus < len(wk.rows) {
+		if r := wk.rows[wk.focus]; len(r.entries) > 0 {
+			focused = r.entries[0]
+		} else {
+			tail = r.kind == tailRow
+		}
+	}
+
+	wk.rows = w.buildRows(now.In(w.opts.Location))
+	wk.focus = -1
+	for i, r := range wk.rows {
+		if (focused != nil && r.contains(focused)) || (tail && r.kind == tailRow) {
+			wk.focus = i
+			break
+		}
+	}
+	switch {
+	case following:
+		// The top row, so the heading above the newest event is shown
+		wk.focus = 0
+		w.GoToTop(app)
+	case wk.focus == -1:
+		wk.focus = gwutil.Max(0, firstSelectable(wk.rows))
+	}
+}
+
+//======================================================================
+
+// walker presents the rows to the list.
+type walker struct {
+	w         *Widget
+	rows      []*row
+	focus     int
+	tailShown bool // Set if the tail row is asked for while rendering
+}
+
+var _ list.IBoundedWalker = (*walker)(nil)
+var _ list.IWalkerHome = (*walker)(nil)
+var _ list.IWalkerEnd = (*walker)(nil)
+
+func (wk *walker) At(pos list.IWalkerPosition) gowid.IWidget {
+	i := int(pos.(list.ListPos))
+	if i < 0 || i >= len(wk.rows) {
+		return nil
+	}
+	if wk.rows[i].kind == tailRow {
+		wk.tailShown = true
+	}
+	return wk.rows[i].widget
+}
+
+func (wk *walker) Focus() list.IWalkerPosition {
+	return list.ListPos(wk.focus)
+}
+
+func (wk *walker) SetFocus(pos list.IWalkerPosition, app gowid.IApp) {
+	wk.focus = int(pos.(list.ListPos))
+}
+
+func (wk *walker) Next(pos list.IWalkerPosition) list.IWalkerPosition {
+	i := int(pos.(list.ListPos))
+	if i+1 >= len(wk.rows) {
+		return list.ListPos(-1)
+	}
+	return list.ListPos(i + 1)
+}
+
+func (wk *walker) Previous(pos list.IWalkerPosition) list.IWalkerPosition {
+	return list.ListPos(int(pos.(list.ListPos)) - 1)
+}
+
+func (wk *walker) Length() int {
+	return len(wk.rows)
+}
+
+func (wk *walker) First() list.IWalkerPosition {
+	if len(wk.rows) == 0 {
+		return nil
+	}
+	return list.ListPos(0)
+}
+
+func (wk *walker) Last() list.IWalkerPosition {
+	if len(wk.rows) == 0 {
+		return nil
+	}
+	return list.ListPos(len(wk.rows) - 1)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,161 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package feed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+var now = time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func TestRelativeTime1(t *testing.T) {
+	assert.Equal(t, "just now", RelativeTime(now.Add(-30*time.Second), now))
+	assert.Equal(t, "5m ago", RelativeTime(now.Add(-5*time.Minute), now))
+	assert.Equal(t, "3h ago", RelativeTime(now.Add(-3*time.Hour-time.Minute), now))
+	assert.Equal(t, "23:15", RelativeTime(now.Add(-13*time.Hour+15*time.Minute), now))
+	assert.Equal(t, "Today", Heading(now.Add(-time.Hour), now))
+	assert.Equal(t, "Yesterday", Heading(now.Add(-24*time.Hour), now))
+	assert.Equal(t, "Monday 6 January", Heading(now.AddDate(0, 0, -4), now))
+	assert.Equal(t, "Sunday 29 December 2019", Heading(now.AddDate(0, 0, -12), now))
+}
+
+func TestFeed1(t *testing.T) {
+	clock := gowid.NewFakeClock(now)
+	w := New(Options{Location: time.UTC})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 50, Rows: 8, Clock: clock})
+	defer sim.Close()
+
+	w.Add(sim,
+		Event{Time: now.Add(-2 * time.Minute), Text: "alice logged in", Kind: "login"},
+		Event{Time: now.Add(-3 * time.Hour), Text: "bob edited a", Kind: "edit"},
+		Event{Time: now.Add(-3*time.Hour + 30*time.Second), Text: "bob edited b", Kind: "edit"},
+		Event{Time: now.Add(-3*time.Hour + time.Minute), Text: "bob edited c", Kind: "edit"},
+		Event{Time: now.Add(-21 * time.Hour), Text: "deployed", Kind: "deploy"},
+	)
+	sim.Redraw()
+	sim.Frame()
+	assertLine(t, sim, 0, "Today")
+	assertLine(t, sim, 1, "2m ago   alice logged in")
+	assertLine(t, sim, 2, "2h ago   [+] 3 similar events: bob edited c")
+	assertLine(t, sim, 3, "Yesterday")
+	assertLine(t, sim, 4, "15:00    deployed")
+
+	// Expand the burst
+	sim.Key(tcell.KeyDown)
+	sim.Key(tcell.KeyDown)
+	sim.Key(tcell.KeyEnter)
+	assertLine(t, sim, 2, "2h ago   [-] 3 similar events")
+	assertLine(t, sim, 3, "  2h ago   bob edited c")
+	assertLine(t, sim, 5, "  3h ago   bob edited a")
+	assertLine(t, sim, 6, "Yesterday")
+
+	// A new event doesn't move the focused row, which is away from the top
+	w.Add(sim, Event{Time: now.Add(-30 * time.Second), Text: "carol logged in", Kind: "login"})
+	sim.Redraw()
+	sim.Frame()
+	assertLine(t, sim, 2, "2h ago   [-] 3 similar events")
+	sim.Key(tcell.KeyEnter)
+	assertLine(t, sim, 2, "2h ago   [+] 3 similar events: bob edited c")
+	sim.Key(tcell.KeyUp)
+	sim.Key(tcell.KeyUp)
+	assertLine(t, sim, 0, "just now carol logged in")
+	// Up again, from the newest event, shows its heading
+	sim.Key(tcell.KeyUp)
+	assertLine(t, sim, 0, "Today")
+	assertLine(t, sim, 1, "just now carol logged in")
+
+	// Times move on with the clock
+	clock.Advance(time.Hour)
+	sim.Redraw()
+	sim.Frame()
+	assertLine(t, sim, 1, "1h ago   carol logged in")
+}
+
+func TestFeedFetch1(t *testing.T) {
+	type request struct {
+		before time.Time
+		res    chan error
+	}
+	requests := make(chan request)
+	w := New(Options{
+		Location:  time.UTC,
+		FetchSize: 2,
+		Fetch: func(ctx context.Context, before time.Time, limit int) ([]Event, bool, error) {
+			req := request{before: before, res: make(chan error)}
+			requests <- req
+			if err := <-req.res; err != nil {
+				return nil, true, err
+			}
+			if before.IsZero() {
+				before = now
+			}
+			return []Event{
+				{Time: before.Add(-time.Hour), Text: "older", Kind: "a"},
+				{Time: before.Add(-2 * time.Hour), Text: "oldest", Kind: "b"},
+			}, false, nil
+		},
+	})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 80, Rows: 6, Clock: gowid.NewFakeClock(now)})
+	defer sim.Close()
+
+	sim.Redraw()
+	sim.Frame()
+	assertLine(t, sim, 0, "Loading older events...")
+	assert.True(t, w.Fetching())
+
+	req := <-requests
+	assert.True(t, req.before.IsZero())
+	req.res <- fmt.Errorf("offline")
+	waitFor(t, sim, func() bool { return !w.Fetching() })
+	sim.Redraw()
+	sim.Frame()
+	assertLine(t, sim, 0, "Could not load older events: offline - press Enter to retry")
+
+	sim.Key(tcell.KeyEnter)
+	req = <-requests
+	req.res <- nil
+	waitFor(t, sim, func() bool { return len(w.Events()) == 2 })
+	sim.Redraw()
+	sim.Frame()
+	assert.False(t, w.More())
+	assertLine(t, sim, 0, "Today")
+	assertLine(t, sim, 1, "1h ago   older")
+	assertLine(t, sim, 2, "2h ago   oldest")
+	assertLine(t, sim, 3, "")
+}
+
+// assertLine checks line y of the screen, ignoring trailing spaces.
+func assertLine(t *testing.T, sim *gwtest.Sim, y int, expected string) {
+	t.Helper()
+	assert.Equal(t, expected, strings.TrimRight(sim.Line(y), " "), "Unexpected contents of line %d", y)
+}
+
+// waitFor runs the sim's queued functions until cond holds, or fails the test after a second.
+func waitFor(t *testing.T, sim *gwtest.Sim, cond func() bool) {
+	for i := 0; i < 200; i++ {
+		sim.Frame()
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for the feed")
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,178 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package form
+
+import (
+	"fmt"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/radio"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// Choices is a field for picking one of a few options, with a radio button for each, one per row. Its
+// value is the option chosen; the first is chosen to begin with.
+type Choices struct {
+	*pile.Widget
+	options []string
+	group   []radio.IWidget
+}
+
+var _ IValue = (*Choices)(nil)
+
+func NewChoices(options []string) *Choices {
+	res := &Choices{
+		options: append([]string(nil), options...),
+	}
+	rows := make([]gowid.IContainerWidget, 0, len(options))
+	for _, opt := range options {
+		rows = append(rows, &gowid.ContainerWidget{
+			IWidget: columns.New([]gowid.IContainerWidget{
+				&gowid.ContainerWidget{IWidget: radio.New(&res.group), D: gowid.RenderFixed{}},
+				&gowid.ContainerWidget{IWidget: text.New(" " + opt), D: gowid.RenderWithWeight{W: 1}},
+			}),
+			D: gowid.RenderFlow{},
+		})
+	}
+	res.Widget = pile.New(rows)
+	return res
+}
+
+func (w *Choices) String() string {
+	return fmt.Sprintf("choices[%s]", w.Value())
+}
+
+// Options returns the options to choose from.
+func (w *Choices) Options() []string {
+	return append([]string(nil), w.options...)
+}
+
+// Value returns the option chosen, or "" if there are none.
+func (w *Choices) Value() string {
+	for i, rb := range w.group {
+		if rb.IsChecked() {
+			return w.options[i]
+		}
+	}
+	return ""
+}
+
+// SetValue chooses the option given, and returns false if there is no such option.
+func (w *Choices) SetValue(value string, app gowid.IApp) bool {
+	for i, opt := range w.options {
+		if opt == value {
+			radio.Select(w.group[i], app)
+			return true
+		}
+	}
+	return false
+}
+
+//======================================================================
+
+// Select is a one-line field for picking one of a list of options, for where a dropdown might be used.
+// It shows the option chosen between arrows; Space, Enter, Right or a click moves to the next option, and
+// Left to the previous one. The first option is chosen to begin with.
+type Select struct {
+	options []string
+	chosen  int
+	gowid.IsSelectable
+}
+
+var _ IValue = (*Select)(nil)
+
+func NewSelect(options []string) *Select {
+	return &Select{
+		options: append([]string(nil), options...),
+	}
+}
+
+func (w *Select) String() string {
+	return fmt.Sprintf("select[%s]", w.Value())
+}
+
+// Options returns the options to choose from.
+func (w *Select) Options() []string {
+	return append([]string(nil), w.options...)
+}
+
+// Value returns the option chosen, or "" if there are none.
+func (w *Select) Value() string {
+	if len(w.options) == 0 {
+		return ""
+	}
+	return w.options[w.chosen]
+}
+
+// SetValue chooses the option given, and returns false if there is no such option.
+func (w *Select) SetValue(value string, app gowid.IApp) bool {
+	for i, opt := range w.options {
+		if opt == value {
+			w.chosen = i
+			return true
+		}
+	}
+	return false
+}
+
+// step moves to the next option, or the previous one, wrapping at the ends.
+func (w *Select) step(dir int) {
+	if n := len(w.options); n > 0 {
+		w.chosen = ((w.chosen+dir)%n + n) % n
+	}
+}
+
+func (w *Select) view() *text.Widget {
+	return text.New("< " + w.Value() + " >")
+}
+
+func (w *Select) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	return w.view().RenderSize(size, focus, app)
+}
+
+func (w *Select) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	return w.view().Render(size, focus, app)
+}
+
+func (w *Select) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		switch ev.Key() {
+		case tcell.KeyRight, tcell.KeyEnter:
+			w.step(1)
+			return true
+		case tcell.KeyLeft:
+			w.step(-1)
+			return true
+		case tcell.KeyRune:
+			if ev.Rune() == ' ' {
+				w.step(1)
+				return true
+			}
+		}
+	case *tcell.EventMouse:
+		switch ev.Buttons() {
+		case tcell.Button1:
+			return true
+		case tcell.ButtonNone:
+			if app.GetLastMouseState().LeftIsClicked() {
+				w.step(1)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
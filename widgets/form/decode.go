@@ -0,0 +1,119 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package form
+
+import (
+	"encoding"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//======================================================================
+
+// ErrNotStructPointer is returned by Decode when it isn't given a pointer to a struct.
+var ErrNotStructPointer = errors.New("Decode needs a non-nil pointer to a struct")
+
+// Decode sets the fields of the struct dst points to from the values of the form's fields that are
+// shown - see Values. A struct field takes the value of the form field named by its "form" tag, or else
+// of the form field with the same name; fields tagged "-", unexported fields, and fields the form has
+// no value for are left alone. Strings, bools, numbers, and types implementing encoding.TextUnmarshaler
+// are understood. If a value can't be converted, Decode returns a FieldError, having set the fields
+// before it.
+//
+//	var user struct {
+//		Name  string `form:"name"`
+//		Age   int    `form:"age"`
+//		Admin bool   `form:"admin"`
+//	}
+//	err := f.Decode(&user)
+func (w *Widget) Decode(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrNotStructPointer
+	}
+	v = v.Elem()
+	values := w.Values()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup("form"); ok {
+			name = strings.Split(tag, ",")[0]
+		}
+		if name == "-" {
+			continue
+		}
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		if err := setValue(v.Field(i), value); err != nil {
+			return FieldError{Name: name, Err: err}
+		}
+	}
+	return nil
+}
+
+// setValue sets fv from the string s, converting it to fv's type.
+func setValue(fv reflect.Value, s string) error {
+	if fv.CanAddr() {
+		if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(strings.TrimSpace(s), 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(strings.TrimSpace(s), 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	default:
+		return UnsupportedTypeError{Type: fv.Type()}
+	}
+	return nil
+}
+
+// UnsupportedTypeError is returned, within a FieldError, when Decode can't set a struct field of a type.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+var _ error = UnsupportedTypeError{}
+
+func (e UnsupportedTypeError) Error() string {
+	return "Cannot decode a form value into a " + e.Type.String()
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,409 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package form provides a widget that lays out labeled fields - edits, checkboxes, choices, buttons - one
+// per row, with declarative rules that show, hide, enable and disable fields as the values of others
+// change, and validators whose errors are shown beneath the fields.
+package form
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/disable"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/styled"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+//======================================================================
+
+// DefaultRevealDuration is how long a field takes to appear or disappear, unless Options says otherwise.
+var DefaultRevealDuration = 150 * time.Millisecond
+
+// FieldNotFoundError is returned when a form has no field of the name given.
+type FieldNotFoundError struct {
+	Name string
+}
+
+var _ error = FieldNotFoundError{}
+
+func (e FieldNotFoundError) Error() string {
+	return fmt.Sprintf("The form has no field named %q", e.Name)
+}
+
+// IValue is implemented by widgets that can give a form the value they hold, as a string. Edits, text
+// widgets, checkboxes and radio buttons are understood without it - a checkbox's value is "true" or
+// "false".
+type IValue interface {
+	Value() string
+}
+
+// Field is one row of a form.
+type Field struct {
+	Name     string        // Identifies the field to rules; must be unique within the form
+	Label    string        // Shown to the left of the widget; if empty, the widget spans the row
+	Widget   gowid.IWidget // The widget holding the field's value
+	Required bool          // If true, the form isn't Valid until the field has a value, if it is shown
+	// Validators check the field's value, when it has one, in order; the first error is shown beneath
+	// the field.
+	Validators []Validator
+}
+
+// Options is used to configure the form.
+type Options struct {
+	// LabelWidth is the width of the column of labels. Defaults to the widest label, plus a space.
+	LabelWidth int
+	// RevealDuration is how long a field takes to slide open or shut when a rule shows or hides it.
+	// Defaults to DefaultRevealDuration; a negative duration shows and hides fields at once.
+	RevealDuration time.Duration
+	// DisabledStyle, if not nil, is used to draw fields that are disabled.
+	DisabledStyle gowid.ICellStyler
+	// ErrorStyle is used to draw the error message beneath a field. Defaults to DefaultErrorStyle.
+	ErrorStyle gowid.ICellStyler
+}
+
+// Widget is a form. Each time it handles input, it validates its fields and evaluates its rules, in the
+// order given, so fields appear and disappear as the user fills the form in. Call Evaluate after
+// changing a field's value in code. A field's error is shown beneath it once the user has used the field,
+// or Validate has been called. A field that is hidden, or disabled, can't take the focus; if the field in
+// focus is hidden, the focus moves to the next field that can take it. Tab and Shift-Tab move the focus
+// through the fields, if the field in focus doesn't use them, leaving the form after the last field.
+type Widget struct {
+	*pile.Widget
+	rows   []*row
+	byName map[string]*row
+	rules  []IRule
+	opts   Options
+}
+
+// New returns a form of the fields given, governed by rules. The rules are evaluated once straight away,
+// with a nil app, so the form starts in the right state without any animation.
+func New(fields []Field, rules []IRule, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.RevealDuration == 0 {
+		opt.RevealDuration = DefaultRevealDuration
+	}
+	if opt.ErrorStyle == nil {
+		opt.ErrorStyle = DefaultErrorStyle
+	}
+	if opt.LabelWidth <= 0 {
+		for _, f := range fields {
+			if f.Label != "" {
+				opt.LabelWidth = gwutil.Max(opt.LabelWidth, runewidth.StringWidth(f.Label)+1)
+			}
+		}
+	}
+
+	res := &Widget{
+		rows:   make([]*row, 0, len(fields)),
+		byName: make(map[string]*row),
+		rules:  append([]IRule(nil), rules...),
+		opts:   opt,
+	}
+	ws := make([]gowid.IContainerWidget, 0, len(fields))
+	for _, f := range fields {
+		r := newRow(f, opt)
+		res.rows = append(res.rows, r)
+		if f.Name != "" {
+			res.byName[f.Name] = r
+		}
+		ws = append(ws, &gowid.ContainerWidget{IWidget: r, D: gowid.RenderFlow{}})
+	}
+	res.Evaluate(nil)
+	res.Widget = pile.New(ws)
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("form[%d fields]", len(w.rows))
+}
+
+// Fields returns the form's fields, in order.
+func (w *Widget) Fields() []Field {
+	res := make([]Field, 0, len(w.rows))
+	for _, r := range w.rows {
+		res = append(res, r.field)
+	}
+	return res
+}
+
+// Field returns the field with the name given.
+func (w *Widget) Field(name string) (Field, bool) {
+	r, ok := w.byName[name]
+	if !ok {
+		return Field{}, false
+	}
+	return r.field, true
+}
+
+// Value returns the value of the named field's widget - see IValue - or "" if there is no such field.
+func (w *Widget) Value(name string) string {
+	if r, ok := w.byName[name]; ok {
+		return ValueOf(r.field.Widget)
+	}
+	return ""
+}
+
+// Values returns the values of the fields that are shown, by name.
+func (w *Widget) Values() map[string]string {
+	res := make(map[string]string)
+	for _, r := range w.rows {
+		if r.field.Name != "" && r.visible {
+			res[r.field.Name] = ValueOf(r.field.Widget)
+		}
+	}
+	return res
+}
+
+// Visible returns true if the named field is shown.
+func (w *Widget) Visible(name string) bool {
+	r, ok := w.byName[name]
+	return ok && r.visible
+}
+
+// SetVisible shows or hides the named field, sliding it open or shut - see Options.RevealDuration. If
+// app is nil, it happens at once.
+func (w *Widget) SetVisible(name string, visible bool, app gowid.IApp) error {
+	r, ok := w.byName[name]
+	if !ok {
+		return FieldNotFoundError{Name: name}
+	}
+	r.setVisible(visible, w.opts.RevealDuration, app)
+	return nil
+}
+
+// Enabled returns true if the named field can be used.
+func (w *Widget) Enabled(name string) bool {
+	r, ok := w.byName[name]
+	return ok && !r.disable.IsDisabled()
+}
+
+// SetEnabled enables or disables the named field.
+func (w *Widget) SetEnabled(name string, enabled bool) error {
+	r, ok := w.byName[name]
+	if !ok {
+		return FieldNotFoundError{Name: name}
+	}
+	r.disable.Set(!enabled)
+	return nil
+}
+
+// Rules returns the form's rules, in the order they are evaluated.
+func (w *Widget) Rules() []IRule {
+	return append([]IRule(nil), w.rules...)
+}
+
+// AddRule adds a rule, evaluated after the others, and evaluates the rules.
+func (w *Widget) AddRule(rule IRule, app gowid.IApp) {
+	w.rules = append(w.rules, rule)
+	w.Evaluate(app)
+}
+
+// Evaluate validates each field, applies the form's rules, in order, then moves the focus off a field that
+// can no longer take it.
+func (w *Widget) Evaluate(app gowid.IApp) {
+	for _, r := range w.rows {
+		r.err = r.validate()
+	}
+	for _, rule := range w.rules {
+		rule.Apply(w, app)
+	}
+	if w.Widget == nil || w.Widget.Focus() < 0 || w.rows[w.Widget.Focus()].Selectable() {
+		return
+	}
+	if next, ok := w.Widget.FindNextSelectable(1, false); ok {
+		w.Widget.SetFocus(app, next)
+	} else if prev, ok := w.Widget.FindNextSelectable(-1, false); ok {
+		w.Widget.SetFocus(app, prev)
+	}
+}
+
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	res := w.Widget.UserInput(ev, size, focus, app)
+	if !res {
+		if evk, ok := ev.(*tcell.EventKey); ok {
+			switch evk.Key() {
+			case tcell.KeyTab:
+				res = gowid.NextFocus(w, app, false)
+			case tcell.KeyBacktab:
+				res = gowid.PrevFocus(w, app, false)
+			}
+		}
+	}
+	if res {
+		w.Evaluate(app)
+	}
+	return res
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// ValueOf returns the value held by a widget, as a form sees it - see IValue. Widgets that wrap another,
+// like styled or disable, are looked through.
+func ValueOf(w gowid.IWidget) string {
+	switch w := w.(type) {
+	case IValue:
+		return w.Value()
+	case interface{ IsChecked() bool }:
+		return strconv.FormatBool(w.IsChecked())
+	case interface{ Text() string }:
+		return w.Text()
+	case gowid.IComposite:
+		return ValueOf(w.SubWidget())
+	}
+	return ""
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// row draws a field, with its error beneath it, or as many of its rows as are shown while it slides open
+// or shut.
+type row struct {
+	gowid.IWidget
+	field    Field
+	disable  *disable.Widget
+	disabled gowid.IWidget // The row drawn with Options.DisabledStyle, or nil
+	msg      *text.Widget  // The field's error message
+	errRow   gowid.IWidget // Draws msg beneath the field's widget
+	err      error         // Why the field isn't valid, or nil
+	touched  bool          // True once the user has used the field, so its error is shown
+	visible  bool
+	shown    float64 // The fraction of the row's height drawn, from 0 to 1
+	tween    *gowid.Tween
+}
+
+func newRow(f Field, opts Options) *row {
+	res := &row{
+		field:   f,
+		disable: disable.NewEnabled(f.Widget),
+		msg:     text.New(""),
+		visible: true,
+		shown:   1,
+	}
+	res.errRow = styled.New(res.msg, opts.ErrorStyle)
+	if f.Label == "" {
+		res.IWidget = res.disable
+	} else {
+		res.IWidget = columns.New([]gowid.IContainerWidget{
+			&gowid.ContainerWidget{IWidget: text.New(f.Label), D: gowid.RenderWithUnits{U: opts.LabelWidth}},
+			&gowid.ContainerWidget{IWidget: res.disable, D: gowid.RenderWithWeight{W: 1}},
+		})
+		res.errRow = columns.New([]gowid.IContainerWidget{
+			&gowid.ContainerWidget{IWidget: text.New(""), D: gowid.RenderWithUnits{U: opts.LabelWidth}},
+			&gowid.ContainerWidget{IWidget: res.errRow, D: gowid.RenderWithWeight{W: 1}},
+		})
+	}
+	if opts.DisabledStyle != nil {
+		res.disabled = styled.New(res.IWidget, opts.DisabledStyle)
+	}
+	return res
+}
+
+func (r *row) String() string {
+	return fmt.Sprintf("field[%s]", r.field.Name)
+}
+
+func (r *row) setVisible(visible bool, d time.Duration, app gowid.IApp) {
+	if visible == r.visible {
+		return
+	}
+	r.visible = visible
+	if r.tween != nil {
+		r.tween.Stop()
+		r.tween = nil
+	}
+	to := 0.0
+	if visible {
+		to = 1
+	}
+	if app == nil || d < 0 {
+		r.shown = to
+		return
+	}
+	r.tween = gowid.AnimateFloat(app, r.shown, to, func(v float64, app gowid.IApp) {
+		r.shown = v
+	}, gowid.AnimateOptions{Duration: d})
+}
+
+// active returns true if the field is shown and enabled, so its value counts.
+func (r *row) active() bool {
+	return r.visible && !r.disable.IsDisabled()
+}
+
+// errorShown returns true if the field's error is drawn beneath it.
+func (r *row) errorShown() bool {
+	return r.err != nil && r.touched && r.active()
+}
+
+// Selectable returns false while the field is hidden, even as it slides shut.
+func (r *row) Selectable() bool {
+	return r.visible && r.IWidget.Selectable()
+}
+
+func (r *row) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if !r.visible {
+		return false
+	}
+	if r.IWidget.UserInput(ev, size, focus, app) {
+		r.touched = true
+		return true
+	}
+	return false
+}
+
+// rowsShown returns how many of the field's rows are drawn, out of all of them.
+func (r *row) rowsShown(rows int) int {
+	return gwutil.Max(0, gwutil.Min(rows, int(math.Ceil(r.shown*float64(rows)-1e-9))))
+}
+
+func (r *row) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	box := r.IWidget.RenderSize(size, focus, app)
+	if _, ok := size.(gowid.IRenderBox); ok {
+		return box
+	}
+	rows := box.BoxRows()
+	if r.errorShown() {
+		r.msg.SetText(r.err.Error(), app)
+		rows += r.errRow.RenderSize(size, gowid.NotSelected, app).BoxRows()
+	}
+	return gowid.RenderBox{C: box.BoxColumns(), R: r.rowsShown(rows)}
+}
+
+func (r *row) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	w := r.IWidget
+	if r.disabled != nil && r.disable.IsDisabled() {
+		w = r.disabled
+	}
+	if r.shown <= 0 && !r.visible {
+		if _, ok := size.(gowid.IRenderBox); !ok {
+			return gowid.NewCanvasOfSize(w.RenderSize(size, focus, app).BoxColumns(), 0)
+		}
+	}
+	res := gowid.Render(w, size, focus, app)
+	if _, ok := size.(gowid.IRenderBox); !ok {
+		if r.errorShown() {
+			r.msg.SetText(r.err.Error(), app)
+			res.AppendBelow(gowid.Render(r.errRow, size, gowid.NotSelected, app), false, false)
+		}
+		res.Truncate(0, res.BoxRows()-r.rowsShown(res.BoxRows()))
+	}
+	return res
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,188 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package form
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/checkbox"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestForm1(t *testing.T) {
+	kind := edit.New()
+	custom := edit.New()
+	ok := button.New(text.New("OK"))
+	f := New([]Field{
+		{Name: "kind", Label: "Kind", Widget: kind},
+		{Name: "custom", Label: "Custom", Widget: custom, Required: true},
+		{Name: "ok", Widget: ok},
+	}, []IRule{
+		ShowWhen{Field: "custom", When: Equals("kind", "custom")},
+		EnableWhen{Field: "ok", When: Valid()},
+	}, Options{RevealDuration: -1})
+	sz := gowid.RenderFlowWith{C: 14}
+
+	assert.False(t, f.Visible("custom"))
+	assert.True(t, f.Enabled("ok"))
+	c := f.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "Kind          \n<OK          >", c.String())
+
+	for _, r := range "custom" {
+		assert.True(t, f.UserInput(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), sz, gowid.Focused, gwtest.D))
+	}
+	assert.True(t, f.Visible("custom"))
+	assert.False(t, f.Enabled("ok"))
+	assert.False(t, f.Valid())
+	c = f.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "Kind   custom \nCustom        \n<OK          >", c.String())
+
+	custom.SetText("x", gwtest.D)
+	f.Evaluate(gwtest.D)
+	assert.True(t, f.Enabled("ok"))
+	assert.Equal(t, map[string]string{"kind": "custom", "custom": "x", "ok": ""}, f.Values())
+
+	// A hidden field gives up the focus
+	f.SetFocus(gwtest.D, 1)
+	kind.SetText("", gwtest.D)
+	f.Evaluate(gwtest.D)
+	assert.False(t, f.Visible("custom"))
+	assert.Equal(t, 2, f.Focus())
+
+	assert.Equal(t, FieldNotFoundError{Name: "nope"}, f.SetEnabled("nope", true))
+}
+
+func TestReveal1(t *testing.T) {
+	r := newRow(Field{Name: "a", Widget: text.New("1\n2\n3\n4")}, Options{})
+	sz := gowid.RenderFlowWith{C: 1}
+	r.shown = 0.5
+	assert.Equal(t, "1\n2", gowid.Render(r, sz, gowid.Focused, gwtest.D).String())
+	assert.Equal(t, 2, r.RenderSize(sz, gowid.Focused, gwtest.D).BoxRows())
+
+	r.setVisible(false, -1, gwtest.D)
+	assert.Equal(t, 0, r.RenderSize(sz, gowid.Focused, gwtest.D).BoxRows())
+	assert.False(t, r.Selectable())
+}
+
+func TestValidate1(t *testing.T) {
+	name := edit.New()
+	pw := edit.New()
+	again := edit.New()
+	age := edit.New()
+	f := New([]Field{
+		{Name: "name", Label: "Name", Widget: name, Required: true, Validators: []Validator{MinLength(3)}},
+		{Name: "pw", Label: "Pass", Widget: pw},
+		{Name: "again", Label: "Again", Widget: again},
+		{Name: "age", Label: "Age", Widget: age, Validators: []Validator{Integer(0, 150)}},
+	}, []IRule{
+		Check{Field: "again", Check: func(f *Widget) error {
+			if f.Value("pw") != f.Value("again") {
+				return errors.New("No match")
+			}
+			return nil
+		}},
+	}, Options{RevealDuration: -1})
+	sz := gowid.RenderFlowWith{C: 40}
+
+	// Errors aren't shown until the field is used, or the form validated
+	assert.False(t, f.Valid())
+	assert.Equal(t, ValidationErrors{{Name: "name", Err: ErrRequired}}, f.Errors())
+	assert.Equal(t, "Name                                    \nPass                                    \nAgain                                   \nAge                                     ",
+		f.Render(sz, gowid.Focused, gwtest.D).String())
+
+	f.UserInput(tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "Name  x                                 \n      Must be at least 3 characters     \nPass                                    \nAgain                                   \nAge                                     ",
+		f.Render(sz, gowid.Focused, gwtest.D).String())
+
+	name.SetText("xyz", gwtest.D)
+	pw.SetText("secret", gwtest.D)
+	age.SetText("200", gwtest.D)
+	err := f.Validate(gwtest.D)
+	assert.Equal(t, ValidationErrors{
+		{Name: "again", Err: errors.New("No match")},
+		{Name: "age", Err: errors.New("Must be from 0 to 150")},
+	}, err)
+	assert.Equal(t, "Name  xyz                               \nPass  secret                            \nAgain                                   \n      No match                          \nAge   200                               \n      Must be from 0 to 150             ",
+		f.Render(sz, gowid.Focused, gwtest.D).String())
+
+	again.SetText("secret", gwtest.D)
+	age.SetText("", gwtest.D)
+	assert.NoError(t, f.Validate(gwtest.D))
+	assert.True(t, f.Valid())
+	assert.Equal(t, 4, f.Render(sz, gowid.Focused, gwtest.D).BoxRows())
+}
+
+func TestTab1(t *testing.T) {
+	f := New([]Field{
+		{Name: "a", Label: "A", Widget: edit.New()},
+		{Name: "note", Widget: text.New("note")},
+		{Name: "b", Label: "B", Widget: edit.New()},
+	}, nil)
+	sz := gowid.RenderFlowWith{C: 10}
+	tab := tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone)
+
+	assert.Equal(t, 0, f.Focus())
+	assert.True(t, f.UserInput(tab, sz, gowid.Focused, gwtest.D))
+	assert.Equal(t, 2, f.Focus())
+	// The last field passes Tab on, so the focus can leave the form
+	assert.False(t, f.UserInput(tab, sz, gowid.Focused, gwtest.D))
+	assert.True(t, f.UserInput(tcell.NewEventKey(tcell.KeyBacktab, 0, tcell.ModNone), sz, gowid.Focused, gwtest.D))
+	assert.Equal(t, 0, f.Focus())
+}
+
+func TestDecode1(t *testing.T) {
+	kind := NewChoices([]string{"user", "admin"})
+	plan := NewSelect([]string{"free", "pro"})
+	f := New([]Field{
+		{Name: "name", Widget: edit.New(edit.Options{Text: "bob"})},
+		{Name: "age", Widget: edit.New(edit.Options{Text: "42"})},
+		{Name: "kind", Widget: kind},
+		{Name: "plan", Widget: plan},
+		{Name: "news", Widget: checkbox.New(true)},
+	}, nil)
+
+	assert.True(t, kind.SetValue("admin", gwtest.D))
+	assert.False(t, kind.SetValue("root", gwtest.D))
+	plan.UserInput(tcell.NewEventKey(tcell.KeyRight, 0, tcell.ModNone), gowid.RenderFixed{}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "< pro >", plan.Render(gowid.RenderFixed{}, gowid.Focused, gwtest.D).String())
+
+	var v struct {
+		Name    string `form:"name"`
+		Age     uint8  `form:"age"`
+		Kind    string `form:"kind"`
+		Plan    string `form:"plan"`
+		News    bool   `form:"news"`
+		Skipped string `form:"-"`
+		Missing int    `form:"missing"`
+	}
+	assert.NoError(t, f.Decode(&v))
+	assert.Equal(t, "bob", v.Name)
+	assert.Equal(t, uint8(42), v.Age)
+	assert.Equal(t, "admin", v.Kind)
+	assert.Equal(t, "pro", v.Plan)
+	assert.True(t, v.News)
+
+	var bad struct {
+		Name int `form:"name"`
+	}
+	err := f.Decode(&bad)
+	assert.IsType(t, FieldError{}, err)
+	assert.Equal(t, "name", err.(FieldError).Name)
+	assert.Equal(t, ErrNotStructPointer, f.Decode(v))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,148 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package form
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gcla/gowid"
+)
+
+//======================================================================
+
+// IRule is a rule a form evaluates each time its fields change - see Widget.Evaluate. Apply should bring
+// the form into line with the rule, e.g. by calling SetVisible or SetEnabled. Implement it to extend the
+// rules a form understands. The app is nil when the form is first built.
+type IRule interface {
+	Apply(f *Widget, app gowid.IApp)
+}
+
+// RuleFunc adapts a function to an IRule.
+type RuleFunc func(f *Widget, app gowid.IApp)
+
+func (r RuleFunc) Apply(f *Widget, app gowid.IApp) {
+	r(f, app)
+}
+
+// Condition is a test of the state of a form, on which a rule depends.
+type Condition func(f *Widget) bool
+
+// ShowWhen shows Field while When holds, and hides it otherwise.
+type ShowWhen struct {
+	Field string
+	When  Condition
+}
+
+var _ IRule = ShowWhen{}
+
+func (r ShowWhen) Apply(f *Widget, app gowid.IApp) {
+	f.SetVisible(r.Field, r.When(f), app)
+}
+
+// EnableWhen enables Field while When holds, and disables it otherwise - e.g. to disable a form's submit
+// button until the form is Valid.
+type EnableWhen struct {
+	Field string
+	When  Condition
+}
+
+var _ IRule = EnableWhen{}
+
+func (r EnableWhen) Apply(f *Widget, app gowid.IApp) {
+	f.SetEnabled(r.Field, r.When(f))
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// Equals holds if the named field's value is value.
+func Equals(name string, value string) Condition {
+	return func(f *Widget) bool {
+		return f.Value(name) == value
+	}
+}
+
+// OneOf holds if the named field's value is any of values.
+func OneOf(name string, values ...string) Condition {
+	return func(f *Widget) bool {
+		v := f.Value(name)
+		for _, value := range values {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Matches holds if the named field's value matches re.
+func Matches(name string, re *regexp.Regexp) Condition {
+	return func(f *Widget) bool {
+		return re.MatchString(f.Value(name))
+	}
+}
+
+// Checked holds if the named field is a checkbox or radio button that is checked.
+func Checked(name string) Condition {
+	return Equals(name, "true")
+}
+
+// NotEmpty holds if the named field has a value other than spaces.
+func NotEmpty(name string) Condition {
+	return func(f *Widget) bool {
+		return strings.TrimSpace(f.Value(name)) != ""
+	}
+}
+
+// Shown holds if the named field is shown - e.g. to show a field only along with another.
+func Shown(name string) Condition {
+	return func(f *Widget) bool {
+		return f.Visible(name)
+	}
+}
+
+// Valid holds if the form is Valid - no field that is shown and enabled has an error.
+func Valid() Condition {
+	return func(f *Widget) bool {
+		return f.Valid()
+	}
+}
+
+// All holds if each of conds does.
+func All(conds ...Condition) Condition {
+	return func(f *Widget) bool {
+		for _, c := range conds {
+			if !c(f) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Any holds if one of conds does.
+func Any(conds ...Condition) Condition {
+	return func(f *Widget) bool {
+		for _, c := range conds {
+			if c(f) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not holds if cond doesn't.
+func Not(cond Condition) Condition {
+	return func(f *Widget) bool {
+		return !cond(f)
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,207 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package form
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gcla/gowid"
+)
+
+//======================================================================
+
+// DefaultErrorStyle draws a field's error message, unless Options says otherwise.
+var DefaultErrorStyle gowid.ICellStyler = gowid.MakeForeground(gowid.ColorRed)
+
+// ErrRequired is the error of a Required field with no value.
+var ErrRequired = errors.New("A value is required")
+
+// FieldError is the error of one field of a form.
+type FieldError struct {
+	Name string // The field's name
+	Err  error  // Why its value isn't valid
+}
+
+var _ error = FieldError{}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Name, e.Err)
+}
+
+func (e FieldError) Cause() error {
+	return e.Err
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors is returned by Validate when fields of a form aren't valid, in the order of the fields.
+type ValidationErrors []FieldError
+
+var _ error = ValidationErrors{}
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// Validator checks the value of one field, returning why it isn't valid, or nil. A field's validators
+// are only run when it has a value - make the field Required to insist on one. Its error is shown
+// beneath it, so should be short.
+type Validator func(value string) error
+
+// MinLength is satisfied by values of at least n characters.
+func MinLength(n int) Validator {
+	return func(value string) error {
+		if utf8.RuneCountInString(value) < n {
+			return fmt.Errorf("Must be at least %d characters", n)
+		}
+		return nil
+	}
+}
+
+// MaxLength is satisfied by values of at most n characters.
+func MaxLength(n int) Validator {
+	return func(value string) error {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Errorf("Must be at most %d characters", n)
+		}
+		return nil
+	}
+}
+
+// Pattern is satisfied by values matching re; the error is msg.
+func Pattern(re *regexp.Regexp, msg string) Validator {
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return errors.New(msg)
+		}
+		return nil
+	}
+}
+
+// Integer is satisfied by whole numbers from min to max, inclusive.
+func Integer(min, max int) Validator {
+	return func(value string) error {
+		i, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return errors.New("Must be a whole number")
+		}
+		if i < min || i > max {
+			return fmt.Errorf("Must be from %d to %d", min, max)
+		}
+		return nil
+	}
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// Check is a rule that checks the form as a whole, e.g. that two password fields agree, and shows the
+// error it returns beneath Field. It isn't run while Field is in error already. Put it before rules
+// that depend on the form being Valid, since rules are evaluated in order.
+type Check struct {
+	Field string
+	Check func(f *Widget) error
+}
+
+var _ IRule = Check{}
+
+func (r Check) Apply(f *Widget, app gowid.IApp) {
+	if f.Error(r.Field) != nil {
+		return
+	}
+	if err := r.Check(f); err != nil {
+		f.SetError(r.Field, err)
+	}
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// Error returns why the named field isn't valid, or nil if it is, or there is no such field. Hidden and
+// disabled fields are always valid.
+func (w *Widget) Error(name string) error {
+	r, ok := w.byName[name]
+	if !ok || !r.active() {
+		return nil
+	}
+	return r.err
+}
+
+// SetError marks the named field as not valid, until the rules are next evaluated - e.g. from a rule
+// that checks several fields together. See Check.
+func (w *Widget) SetError(name string, err error) error {
+	r, ok := w.byName[name]
+	if !ok {
+		return FieldNotFoundError{Name: name}
+	}
+	r.err = err
+	return nil
+}
+
+// Errors returns the errors of the fields that are shown and enabled, in order, or nil if there are none.
+func (w *Widget) Errors() ValidationErrors {
+	var res ValidationErrors
+	for _, r := range w.rows {
+		if r.active() && r.err != nil {
+			res = append(res, FieldError{Name: r.field.Name, Err: r.err})
+		}
+	}
+	return res
+}
+
+// Valid returns true if no field that is shown and enabled has an error - because it is Required and
+// has no value other than spaces, because one of its Validators failed, or because a rule, like Check,
+// said so.
+func (w *Widget) Valid() bool {
+	return len(w.Errors()) == 0
+}
+
+// Validate evaluates the rules, then shows the error of every field, even those the user hasn't used
+// yet - e.g. when the form is submitted. It returns the errors as ValidationErrors, or nil if the form
+// is valid.
+func (w *Widget) Validate(app gowid.IApp) error {
+	for _, r := range w.rows {
+		r.touched = true
+	}
+	w.Evaluate(app)
+	if errs := w.Errors(); errs != nil {
+		return errs
+	}
+	return nil
+}
+
+// validate returns the error of the field's own value - see Field.Validators.
+func (r *row) validate() error {
+	v := ValueOf(r.field.Widget)
+	if strings.TrimSpace(v) == "" {
+		if r.field.Required {
+			return ErrRequired
+		}
+		return nil
+	}
+	for _, check := range r.field.Validators {
+		if err := check(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -23,6 +23,21 @@ type FrameRunes struct {
 	T, B, L, R     rune
 }
 
+// ASCII returns the frame, unless widgets should draw with ASCII only - see App.SetASCIIOnly - in which
+// case each rune that isn't ASCII is replaced with its counterpart in AsciiFrame.
+func (f FrameRunes) ASCII(app gowid.IApp) FrameRunes {
+	return FrameRunes{
+		Tl: gowid.ASCIIRune(app, f.Tl, AsciiFrame.Tl),
+		Tr: gowid.ASCIIRune(app, f.Tr, AsciiFrame.Tr),
+		Bl: gowid.ASCIIRune(app, f.Bl, AsciiFrame.Bl),
+		Br: gowid.ASCIIRune(app, f.Br, AsciiFrame.Br),
+		T:  gowid.ASCIIRune(app, f.T, AsciiFrame.T),
+		B:  gowid.ASCIIRune(app, f.B, AsciiFrame.B),
+		L:  gowid.ASCIIRune(app, f.L, AsciiFrame.L),
+		R:  gowid.ASCIIRune(app, f.R, AsciiFrame.R),
+	}
+}
+
 var (
 	AsciiFrame       = FrameRunes{'-', '-', '-', '-', '-', '-', '|', '|'}
 	UnicodeFrame     = FrameRunes{'┏', '┓', '┗', '┛', '━', '━', '┃', '┃'}
@@ -178,8 +193,9 @@ func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.
 
 //======================================================================
 
-func frameWidth(w IFramed) int {
-	return runewidth.RuneWidth(w.Opts().Frame.L) + runewidth.RuneWidth(w.Opts().Frame.R)
+func frameWidth(w IFramed, app gowid.IApp) int {
+	frame := w.Opts().Frame.ASCII(app)
+	return runewidth.RuneWidth(frame.L) + runewidth.RuneWidth(frame.R)
 }
 
 func RenderSize(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
@@ -192,7 +208,7 @@ func RenderSize(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gow
 	if w.Opts().Frame.B != 0 {
 		extraRows++
 	}
-	return gowid.RenderBox{C: sdim.BoxColumns() + frameWidth(w), R: sdim.BoxRows() + extraRows}
+	return gowid.RenderBox{C: sdim.BoxColumns() + frameWidth(w, app), R: sdim.BoxRows() + extraRows}
 }
 
 func SubWidgetSize(w IFramed, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
@@ -209,9 +225,9 @@ func SubWidgetSize(w IFramed, size gowid.IRenderSize, focus gowid.Selector, app
 		if w.Opts().Frame.B != 0 {
 			extraRows++
 		}
-		newSize = gowid.RenderBox{C: gwutil.Max(sz.BoxColumns()-frameWidth(w), 0), R: gwutil.Max(sz.BoxRows()-extraRows, 0)}
+		newSize = gowid.RenderBox{C: gwutil.Max(sz.BoxColumns()-frameWidth(w, app), 0), R: gwutil.Max(sz.BoxRows()-extraRows, 0)}
 	case gowid.IRenderFlowWith:
-		newSize = gowid.RenderFlowWith{C: gwutil.Max(sz.FlowColumns()-frameWidth(w), 0)}
+		newSize = gowid.RenderFlowWith{C: gwutil.Max(sz.FlowColumns()-frameWidth(w, app), 0)}
 	default:
 		panic(gowid.WidgetSizeError{Widget: w, Size: size})
 	}
@@ -223,11 +239,11 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	tmp := gowid.NewCanvas()
 	newSize := w.SubWidgetSize(size, focus, app)
 
-	innerCanvas := w.SubWidget().Render(newSize, focus, app)
+	innerCanvas := gowid.Render(w.SubWidget(), newSize, focus, app)
 	innerLines := innerCanvas.BoxRows()
 	maxCol := innerCanvas.BoxColumns()
 
-	frame := w.Opts().Frame
+	frame := w.Opts().Frame.ASCII(app)
 	empty := FrameRunes{}
 	if frame == empty {
 		frame = AsciiFrame
@@ -275,7 +291,7 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 
 	tophorArr := make([]gowid.Cell, 0)
 	bottomhorArr := make([]gowid.Cell, 0)
-	for i := 0; i < maxCol+frameWidth(w); i++ {
+	for i := 0; i < maxCol+frameWidth(w, app); i++ {
 		tophorArr = append(tophorArr, tophor)
 		bottomhorArr = append(bottomhorArr, bottomhor)
 	}
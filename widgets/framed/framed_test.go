@@ -47,6 +47,37 @@ func TestCanvas10(t *testing.T) {
 	assert.Equal(t, res, canvas1.String())
 }
 
+func TestASCIIOnly1(t *testing.T) {
+	app := gowid.App{}
+	app.SetASCIIOnly(true)
+
+	fwidget1 := NewUnicodeAlt2(text.New("hello"))
+	canvas1 := fwidget1.Render(gowid.RenderFixed{}, gowid.NotSelected, &app)
+	res := strings.Join([]string{"-------", "|hello|", "-------"}, "\n")
+	assert.Equal(t, res, canvas1.String())
+
+	// Wide runes are replaced too, so the frame is narrower
+	fwidget2 := New(text.New("hello"), Options{Frame: FrameRunes{'你', '你', '你', '你', '=', '=', '你', '你'}})
+	canvas2 := fwidget2.Render(gowid.RenderFlowWith{C: 7}, gowid.NotSelected, &app)
+	res = strings.Join([]string{"-=====-", "|hello|", "-=====-"}, "\n")
+	assert.Equal(t, res, canvas2.String())
+}
+
+func TestASCIIOnly2(t *testing.T) {
+	// Set for one app, the others draw as before
+	sim1 := gwtest.NewSimT(t, NewUnicode(text.New("hi")), gwtest.SimOptions{Cols: 4, Rows: 3})
+	defer sim1.Close()
+	sim2 := gwtest.NewSimT(t, NewUnicode(text.New("hi")), gwtest.SimOptions{Cols: 4, Rows: 3})
+	defer sim2.Close()
+
+	sim1.App.SetASCIIOnly(true)
+	sim1.App.Redraw()
+	sim1.Frame()
+	sim1.AssertLine(t, 0, "----")
+	sim1.AssertLine(t, 1, "|hi|")
+	sim2.AssertLine(t, 0, "┏━━┓")
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
@@ -0,0 +1,374 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package frozen provides a viewport onto a widget too big for the screen, like a spreadsheet's frozen
+// panes - the top rows and left columns stay in place while the rest scrolls in both directions, so a
+// table's header and first column stay in view.
+package frozen
+
+import (
+	"fmt"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// OffsetCB is the callback name used when the viewport scrolls.
+type OffsetCB struct{}
+
+// Options is used to configure the viewport.
+type Options struct {
+	Rows    int // How many rows at the top stay in place, e.g. a table's header
+	Columns int // How many columns at the left stay in place
+
+	// Width and Height are the size the inner widget is rendered at. If both are set, it is rendered as a
+	// box; if only Width is, as a flow widget that many columns wide; otherwise as a fixed widget, at its
+	// natural size.
+	Width  int
+	Height int
+}
+
+type IFrozen interface {
+	Options() Options
+	Offset() (col int, row int)
+	SetOffset(col int, row int, app gowid.IApp)
+	// FollowCursor returns true if the cursor of the inner widget has moved since the last render - the
+	// viewport then scrolls to keep it in view - and remembers where it is now.
+	FollowCursor(pos gowid.CanvasPos, enabled bool) bool
+}
+
+type IWidget interface {
+	gowid.ICompositeWidget
+	IFrozen
+}
+
+// Widget pins the top rows and left columns of the widget inside it, and scrolls the rest. Both regions
+// share one offset, so the pinned rows scroll sideways with the body, and the pinned columns up and down
+// with it. Input the inner widget doesn't handle scrolls the viewport - the arrow keys, page up and down,
+// home and end, and the mouse wheel.
+type Widget struct {
+	gowid.IWidget
+	opts      Options
+	col, row  int             // How far the unpinned region is scrolled
+	cursor    gowid.CanvasPos // Where the inner widget's cursor was at the last render
+	hasCursor bool            // True if the inner widget had a cursor at the last render
+	*gowid.Callbacks
+	gowid.SubWidgetCallbacks
+}
+
+func New(inner gowid.IWidget, opts Options) *Widget {
+	res := &Widget{
+		IWidget: inner,
+		opts:    opts,
+	}
+	res.SubWidgetCallbacks = gowid.SubWidgetCallbacks{CB: &res.Callbacks}
+	var _ IWidget = res
+	var _ gowid.IScrollValues = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("frozen[%v]", w.SubWidget())
+}
+
+func (w *Widget) SubWidget() gowid.IWidget {
+	return w.IWidget
+}
+
+func (w *Widget) SetSubWidget(wi gowid.IWidget, app gowid.IApp) {
+	w.IWidget = wi
+	gowid.RunWidgetCallbacks(w, gowid.SubWidgetCB{}, app, w)
+}
+
+func (w *Widget) Options() Options {
+	return w.opts
+}
+
+// Offset returns how many columns and rows the unpinned region is scrolled by.
+func (w *Widget) Offset() (int, int) {
+	return w.col, w.row
+}
+
+// SetOffset scrolls the unpinned region. The offset is limited when the viewport is rendered, so that
+// the inner widget's last column and row are no further in than the viewport's edge.
+func (w *Widget) SetOffset(col int, row int, app gowid.IApp) {
+	col, row = gwutil.Max(0, col), gwutil.Max(0, row)
+	if col != w.col || row != w.row {
+		w.col, w.row = col, row
+		gowid.RunWidgetCallbacks(w, OffsetCB{}, app, w)
+	}
+}
+
+// OnOffsetChanged adds a callback run when the viewport scrolls - e.g. to scroll another widget with it.
+func (w *Widget) OnOffsetChanged(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w, OffsetCB{}, f)
+}
+
+func (w *Widget) RemoveOnOffsetChanged(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w, OffsetCB{}, f)
+}
+
+func (w *Widget) FollowCursor(pos gowid.CanvasPos, enabled bool) bool {
+	res := enabled && (!w.hasCursor || pos != w.cursor)
+	w.cursor, w.hasCursor = pos, enabled
+	return res
+}
+
+// Selectable returns true, so that the viewport can be scrolled even if the widget inside can't take
+// the focus.
+func (w *Widget) Selectable() bool {
+	return true
+}
+
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	return UserInput(w, ev, size, focus, app)
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	return Render(w, size, focus, app)
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	return RenderSize(w, size, focus, app)
+}
+
+func (w *Widget) SubWidgetSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	return SubWidgetSize(w, size, focus, app)
+}
+
+// ScrollValues returns the rows, or columns, of the unpinned region above, in and below the viewport.
+func (w *Widget) ScrollValues(o gowid.Orientation, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) (gowid.ScrollValues, bool) {
+	v, content := viewAt(w, size, focus, app)
+	if o == gowid.Horizontal {
+		return gowid.ScrollValues{Total: content.BoxColumns() - v.pinCols, Offset: v.col, Visible: v.cols - v.pinCols}, true
+	}
+	return gowid.ScrollValues{Total: content.BoxRows() - v.pinRows, Offset: v.row, Visible: v.rows - v.pinRows}, true
+}
+
+// ScrollTo scrolls the unpinned region so that row, or column, offset of it is the first in view.
+func (w *Widget) ScrollTo(o gowid.Orientation, offset int, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) {
+	v, content := viewAt(w, size, focus, app)
+	if o == gowid.Horizontal {
+		w.SetOffset(gwutil.Min(offset, content.BoxColumns()-v.cols), v.row, app)
+	} else {
+		w.SetOffset(v.col, gwutil.Min(offset, content.BoxRows()-v.rows), app)
+	}
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// view maps between the cells of the viewport and those of the inner widget's canvas.
+type view struct {
+	cols, rows       int // The size of the viewport
+	pinCols, pinRows int // The pinned region, no bigger than the viewport or the content
+	col, row         int // The offset of the unpinned region, limited to the content
+}
+
+func makeView(w IFrozen, cols, rows, contentCols, contentRows int) view {
+	opts := w.Options()
+	res := view{
+		cols:    cols,
+		rows:    rows,
+		pinCols: gwutil.Max(0, gwutil.Min(opts.Columns, gwutil.Min(cols, contentCols))),
+		pinRows: gwutil.Max(0, gwutil.Min(opts.Rows, gwutil.Min(rows, contentRows))),
+	}
+	col, row := w.Offset()
+	res.col = gwutil.Max(0, gwutil.Min(col, contentCols-cols))
+	res.row = gwutil.Max(0, gwutil.Min(row, contentRows-rows))
+	return res
+}
+
+// toContent returns the position in the content shown at x, y in the viewport.
+func (v view) toContent(x, y int) (int, int) {
+	if x >= v.pinCols {
+		x += v.col
+	}
+	if y >= v.pinRows {
+		y += v.row
+	}
+	return x, y
+}
+
+// fromContent returns the position in the viewport at which x, y in the content is shown, and false if
+// it is scrolled out of view.
+func (v view) fromContent(x, y int) (int, int, bool) {
+	if x >= v.pinCols {
+		x -= v.col
+		if x < v.pinCols {
+			return 0, 0, false
+		}
+	}
+	if y >= v.pinRows {
+		y -= v.row
+		if y < v.pinRows {
+			return 0, 0, false
+		}
+	}
+	return x, y, x < v.cols && y < v.rows
+}
+
+// scrolledTo returns the offset at which x, y in the content is in view, changing the current offset as
+// little as possible.
+func (v view) scrolledTo(x, y int) (int, int) {
+	col, row := v.col, v.row
+	if x >= v.pinCols {
+		width := gwutil.Max(1, v.cols-v.pinCols)
+		col = gwutil.Max(gwutil.Min(col, x-v.pinCols), x-v.pinCols-width+1)
+	}
+	if y >= v.pinRows {
+		height := gwutil.Max(1, v.rows-v.pinRows)
+		row = gwutil.Max(gwutil.Min(row, y-v.pinRows), y-v.pinRows-height+1)
+	}
+	return col, row
+}
+
+// viewAt returns the view of w rendered at size, and the size of its content.
+func viewAt(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) (view, gowid.IRenderBox) {
+	content := w.SubWidget().RenderSize(w.SubWidgetSize(size, focus, app), focus, app)
+	box := RenderSize(w, size, focus, app)
+	return makeView(w, box.BoxColumns(), box.BoxRows(), content.BoxColumns(), content.BoxRows()), content
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// SubWidgetSize returns the size at which the inner widget is rendered - see Options.
+func SubWidgetSize(w IFrozen, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	opts := w.Options()
+	switch {
+	case opts.Width > 0 && opts.Height > 0:
+		return gowid.RenderBox{C: opts.Width, R: opts.Height}
+	case opts.Width > 0:
+		return gowid.RenderFlowWith{C: opts.Width}
+	default:
+		return gowid.RenderFixed{}
+	}
+}
+
+// RenderSize returns size if it is a box. Rendered as a flow widget, the viewport is as tall as the
+// inner widget, so only scrolls sideways; rendered as a fixed widget, it is the inner widget's size.
+func RenderSize(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	switch sz := size.(type) {
+	case gowid.IRenderBox:
+		return gowid.RenderBox{C: sz.BoxColumns(), R: sz.BoxRows()}
+	case gowid.IRenderFlowWith:
+		content := w.SubWidget().RenderSize(w.SubWidgetSize(size, focus, app), focus, app)
+		return gowid.RenderBox{C: sz.FlowColumns(), R: content.BoxRows()}
+	case gowid.IRenderFixed:
+		return w.SubWidget().RenderSize(w.SubWidgetSize(size, focus, app), focus, app)
+	default:
+		panic(gowid.WidgetSizeError{Widget: w, Size: size})
+	}
+}
+
+func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	content := gowid.Render(w.SubWidget(), w.SubWidgetSize(size, focus, app), focus, app)
+	box := RenderSize(w, size, focus, app)
+	cols, rows := box.BoxColumns(), box.BoxRows()
+	contentCols, contentRows := content.BoxColumns(), content.BoxRows()
+
+	v := makeView(w, cols, rows, contentCols, contentRows)
+	if content.CursorEnabled() {
+		pos := content.CursorCoords()
+		if w.FollowCursor(pos, true) {
+			col, row := v.scrolledTo(pos.X, pos.Y)
+			w.SetOffset(col, row, app)
+			v = makeView(w, cols, rows, contentCols, contentRows)
+		}
+	} else {
+		w.FollowCursor(gowid.CanvasPos{}, false)
+	}
+
+	res := gowid.NewCanvasOfSize(cols, rows)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			if sx, sy := v.toContent(x, y); sx < contentCols && sy < contentRows {
+				res.SetCellAt(x, y, content.CellAt(sx, sy))
+			}
+		}
+	}
+	if content.CursorEnabled() {
+		pos := content.CursorCoords()
+		if x, y, ok := v.fromContent(pos.X, pos.Y); ok {
+			res.SetCursorCoords(x, y)
+		}
+	}
+
+	return res
+}
+
+func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	ss := w.SubWidgetSize(size, focus, app)
+	content := w.SubWidget().RenderSize(ss, focus, app)
+	box := RenderSize(w, size, focus, app)
+	v := makeView(w, box.BoxColumns(), box.BoxRows(), content.BoxColumns(), content.BoxRows())
+
+	if evm, ok := ev.(*tcell.EventMouse); ok {
+		mx, my := evm.Position()
+		sx, sy := v.toContent(mx, my)
+		if gowid.UserInputIfSelectable(w.SubWidget(), gowid.TranslatedMouseEvent(ev, sx-mx, sy-my), ss, focus, app) {
+			return true
+		}
+	} else if gowid.UserInputIfSelectable(w.SubWidget(), ev, ss, focus, app) {
+		return true
+	}
+
+	col, row := v.col, v.row
+	page := gwutil.Max(1, v.rows-v.pinRows-1)
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		switch ev.Key() {
+		case tcell.KeyUp:
+			row--
+		case tcell.KeyDown:
+			row++
+		case tcell.KeyLeft:
+			col--
+		case tcell.KeyRight:
+			col++
+		case tcell.KeyPgUp:
+			row -= page
+		case tcell.KeyPgDn:
+			row += page
+		case tcell.KeyHome:
+			col = 0
+		case tcell.KeyEnd:
+			col = content.BoxColumns()
+		default:
+			return false
+		}
+	case *tcell.EventMouse:
+		switch ev.Buttons() {
+		case tcell.WheelUp:
+			row--
+		case tcell.WheelDown:
+			row++
+		case tcell.WheelLeft:
+			col--
+		case tcell.WheelRight:
+			col++
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+
+	// Limit the offset now, so that scrolling back from past the end takes effect straight away
+	col = gwutil.Max(0, gwutil.Min(col, content.BoxColumns()-v.cols))
+	row = gwutil.Max(0, gwutil.Min(row, content.BoxRows()-v.rows))
+	if col == v.col && row == v.row {
+		return false
+	}
+	w.SetOffset(col, row, app)
+	return true
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,58 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package frozen
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestFrozen1(t *testing.T) {
+	grid := text.New("#abcdef\n1ABCDEF\n2GHIJKL\n3MNOPQR\n4STUVWX")
+	w := New(grid, Options{Rows: 1, Columns: 1})
+	sz := gowid.RenderBox{C: 4, R: 3}
+
+	c := w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "#abc\n1ABC\n2GHI", c.String())
+
+	// The header scrolls sideways with the body, and the first column up and down with it
+	assert.True(t, w.UserInput(tcell.NewEventKey(tcell.KeyRight, ' ', tcell.ModNone), sz, gowid.Focused, gwtest.D))
+	assert.True(t, w.UserInput(tcell.NewEventMouse(2, 2, tcell.WheelDown, 0), sz, gowid.Focused, gwtest.D))
+	c = w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "#bcd\n2HIJ\n3NOP", c.String())
+
+	// Scrolling stops at the edge of the content
+	assert.True(t, w.UserInput(tcell.NewEventKey(tcell.KeyEnd, ' ', tcell.ModNone), sz, gowid.Focused, gwtest.D))
+	assert.True(t, w.UserInput(tcell.NewEventKey(tcell.KeyPgDn, ' ', tcell.ModNone), sz, gowid.Focused, gwtest.D))
+	col, row := w.Offset()
+	assert.Equal(t, 3, col)
+	assert.Equal(t, 2, row)
+	c = w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "#def\n3PQR\n4VWX", c.String())
+	assert.False(t, w.UserInput(tcell.NewEventKey(tcell.KeyDown, ' ', tcell.ModNone), sz, gowid.Focused, gwtest.D))
+
+	v := makeView(w, 4, 3, 7, 5)
+	x, y, ok := v.fromContent(6, 4)
+	assert.True(t, ok)
+	assert.Equal(t, 3, x)
+	assert.Equal(t, 2, y)
+	_, _, ok = v.fromContent(2, 4)
+	assert.False(t, ok)
+	col, row = v.scrolledTo(2, 1)
+	assert.Equal(t, 1, col)
+	assert.Equal(t, 0, row)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
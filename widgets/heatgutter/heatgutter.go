@@ -0,0 +1,434 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package heatgutter provides a narrow gutter beside each row of a list or table that shows how active
+// the row is - e.g. how recently it changed - as a color, like the minimap of a code editor.
+package heatgutter
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/list"
+	"github.com/gcla/gowid/widgets/table"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// HeatFunc returns the heat of the row identified by key, from 0 (cold) to 1 (hot). Rows of a list are
+// identified by their list.IWalkerPosition, and rows of a table by their table.RowId.
+type HeatFunc func(key interface{}, app gowid.IApp) float64
+
+// DefaultGradient colors the gutter from a dim blue for the coldest rows to red for the hottest.
+var DefaultGradient = gowid.MakeGradient(gowid.HSLSpace,
+	gowid.MakeRGBColor("#1c2c5c"),
+	gowid.MakeRGBColor("#2e8b57"),
+	gowid.MakeRGBColor("#ffd700"),
+	gowid.MakeRGBColor("#ff4500"),
+)
+
+// Options is used to configure the gutter.
+type Options struct {
+	Heat     HeatFunc        // Called for each row as it's rendered; if nil, the gutter is blank
+	Gradient *gowid.Gradient // Maps heat to color; defaults to DefaultGradient
+	ColorMap *gowid.ColorMap // If not nil, maps heat to color instead of Gradient - e.g. for a standard palette
+	Width    int             // Defaults to 1
+	Rune     rune            // If not 0, the gutter is drawn with this in the heat's color; otherwise its background is colored
+}
+
+func (o Options) width() int {
+	if o.Width <= 0 {
+		return 1
+	}
+	return o.Width
+}
+
+func (o Options) gradient() gowid.Gradient {
+	if o.Gradient == nil {
+		return DefaultGradient
+	}
+	return *o.Gradient
+}
+
+// cell returns the cell with which to draw the gutter for a row with key. A row with no heat, or with a
+// nil key, like a table's header, is left blank.
+func (o Options) cell(key interface{}, app gowid.IApp) gowid.Cell {
+	heat := 0.0
+	if key != nil && o.Heat != nil {
+		heat = o.Heat(key, app)
+	}
+	if heat <= 0 {
+		return gowid.CellFromRune(' ')
+	}
+	var c gowid.IColor
+	if o.ColorMap != nil {
+		c = o.ColorMap.At(heat)
+	} else {
+		c = o.gradient().At(heat)
+	}
+	col := gowid.IColorToTCell(c, gowid.ColorNone, app.GetColorMode())
+	if o.Rune == 0 {
+		return gowid.CellFromRune(' ').WithBackgroundColor(col)
+	}
+	return gowid.CellFromRune(o.Rune).WithForegroundColor(col)
+}
+
+//======================================================================
+
+type IGutter interface {
+	Key() interface{}
+	Options() Options
+}
+
+type IWidget interface {
+	gowid.ICompositeWidget
+	IGutter
+}
+
+// Widget draws the gutter to the left of a row, colored by the row's heat. The heat is looked up each
+// time the row is rendered, so the gutter follows the data without the row being rebuilt.
+type Widget struct {
+	gowid.IWidget
+	key  interface{}
+	opts Options
+	*gowid.Callbacks
+	gowid.SubWidgetCallbacks
+}
+
+func New(inner gowid.IWidget, key interface{}, opts Options) *Widget {
+	res := &Widget{
+		IWidget: inner,
+		key:     key,
+		opts:    opts,
+	}
+	res.SubWidgetCallbacks = gowid.SubWidgetCallbacks{CB: &res.Callbacks}
+	var _ IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("heatgutter[%v]", w.SubWidget())
+}
+
+func (w *Widget) SubWidget() gowid.IWidget {
+	return w.IWidget
+}
+
+func (w *Widget) SetSubWidget(wi gowid.IWidget, app gowid.IApp) {
+	w.IWidget = wi
+	gowid.RunWidgetCallbacks(w, gowid.SubWidgetCB{}, app, w)
+}
+
+func (w *Widget) Key() interface{} {
+	return w.key
+}
+
+func (w *Widget) Options() Options {
+	return w.opts
+}
+
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	return UserInput(w, ev, size, focus, app)
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	return Render(w, size, focus, app)
+}
+
+func (w *Widget) SubWidgetSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	return SubWidgetSize(w, size, focus, app)
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	return RenderSize(w, size, focus, app)
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	width := w.Options().width()
+	if evm, ok := ev.(*tcell.EventMouse); ok {
+		mx, _ := evm.Position()
+		if mx < width {
+			return false
+		}
+		ev = gowid.TranslatedMouseEvent(ev, -width, 0)
+	}
+	return gowid.UserInputIfSelectable(w.SubWidget(), ev, w.SubWidgetSize(size, focus, app), focus, app)
+}
+
+func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	innerCanvas := gowid.Render(w.SubWidget(), w.SubWidgetSize(size, focus, app), focus, app)
+
+	width := w.Options().width()
+	if sz, ok := size.(gowid.IColumns); ok {
+		width = gwutil.Min(width, sz.Columns())
+	}
+	res := gowid.NewCanvasOfSizeExt(width, innerCanvas.BoxRows(), w.Options().cell(w.Key(), app))
+	res.AppendRight(innerCanvas, true)
+
+	return res
+}
+
+func SubWidgetSize(w IGutter, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	width := w.Options().width()
+	var newSize gowid.IRenderSize
+	switch sz := size.(type) {
+	case gowid.IRenderFixed:
+		newSize = gowid.RenderFixed{}
+	case gowid.IRenderBox:
+		newSize = gowid.RenderBox{C: gwutil.Max(0, sz.BoxColumns()-width), R: sz.BoxRows()}
+	case gowid.IRenderFlowWith:
+		newSize = gowid.RenderFlowWith{C: gwutil.Max(0, sz.FlowColumns()-width)}
+	default:
+		panic(gowid.WidgetSizeError{Widget: w, Size: size})
+	}
+	return newSize
+}
+
+func RenderSize(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	ss := w.SubWidgetSize(size, focus, app)
+	sdim := w.SubWidget().RenderSize(ss, focus, app)
+	return gowid.RenderBox{C: sdim.BoxColumns() + w.Options().width(), R: sdim.BoxRows()}
+}
+
+//======================================================================
+
+// Walker decorates a list.IWalker, adding a gutter to each of its rows. The rows are keyed by their
+// list.IWalkerPosition.
+type Walker struct {
+	list.IWalker
+	opts Options
+}
+
+var _ list.IWalker = (*Walker)(nil)
+var _ list.IWalkerHome = (*Walker)(nil)
+var _ list.IWalkerEnd = (*Walker)(nil)
+
+func NewWalker(walker list.IWalker, opts Options) *Walker {
+	return &Walker{
+		IWalker: walker,
+		opts:    opts,
+	}
+}
+
+func (w *Walker) At(pos list.IWalkerPosition) gowid.IWidget {
+	res := w.IWalker.At(pos)
+	if res == nil {
+		return nil
+	}
+	return New(res, pos, w.opts)
+}
+
+func (w *Walker) First() list.IWalkerPosition {
+	if h, ok := w.IWalker.(list.IWalkerHome); ok {
+		return h.First()
+	}
+	return nil
+}
+
+func (w *Walker) Last() list.IWalkerPosition {
+	if e, ok := w.IWalker.(list.IWalkerEnd); ok {
+		return e.Last()
+	}
+	return nil
+}
+
+// BoundedWalker decorates a list.IBoundedWalker, for use with list.NewBounded.
+type BoundedWalker struct {
+	*Walker
+}
+
+var _ list.IBoundedWalker = (*BoundedWalker)(nil)
+
+func NewBoundedWalker(walker list.IBoundedWalker, opts Options) *BoundedWalker {
+	return &BoundedWalker{NewWalker(walker, opts)}
+}
+
+func (w *BoundedWalker) Length() int {
+	return w.IWalker.(list.IBoundedWalker).Length()
+}
+
+//======================================================================
+
+// Model decorates a table.IModel, adding a gutter to the first cell of each row. The rows are keyed by
+// their table.RowId. The first header cell is given a blank gutter, so the columns stay aligned.
+type Model struct {
+	table.IModel
+	opts Options
+}
+
+var _ table.IModel = (*Model)(nil)
+var _ table.IMakeHeader = (*Model)(nil)
+
+// BoundedModel decorates a table.IBoundedModel.
+type BoundedModel struct {
+	*Model
+}
+
+var _ table.IBoundedModel = (*BoundedModel)(nil)
+
+// NewModel returns a decorated model - a *BoundedModel if model is a table.IBoundedModel, otherwise a
+// *Model.
+func NewModel(model table.IModel, opts Options) table.IModel {
+	res := &Model{
+		IModel: model,
+		opts:   opts,
+	}
+	if _, ok := model.(table.IBoundedModel); ok {
+		return &BoundedModel{res}
+	}
+	return res
+}
+
+func (m *Model) CellWidgets(row table.RowId) []gowid.IWidget {
+	return m.withGutter(m.IModel.CellWidgets(row), row)
+}
+
+func (m *Model) HeaderWidgets() []gowid.IWidget {
+	return m.withGutter(m.IModel.HeaderWidgets(), nil)
+}
+
+func (m *Model) withGutter(ws []gowid.IWidget, key interface{}) []gowid.IWidget {
+	if len(ws) == 0 {
+		return ws
+	}
+	res := make([]gowid.IWidget, len(ws))
+	copy(res, ws)
+	res[0] = New(ws[0], key, m.opts)
+	return res
+}
+
+// HeaderWidget lays out the header cells with the decorated model's HeaderWidget, if it has one;
+// otherwise they are laid out as table.Widget would.
+func (m *Model) HeaderWidget(ws []gowid.IWidget, focus int) gowid.IWidget {
+	if mh, ok := m.IModel.(table.IMakeHeader); ok {
+		return mh.HeaderWidget(ws, focus)
+	}
+	var flowVertDivider *gowid.ContainerWidget
+	if m.VerticalSeparator() != nil {
+		flowVertDivider = &gowid.ContainerWidget{
+			IWidget: m.VerticalSeparator(),
+			D:       table.RenderWithUnitsMax{RenderWithUnits: gowid.RenderWithUnits{U: 1}},
+		}
+	}
+	cws := make([]gowid.IContainerWidget, 0)
+	if flowVertDivider != nil {
+		cws = append(cws, flowVertDivider)
+	}
+	for i, w := range ws {
+		var dim gowid.IWidgetDimension = gowid.RenderWithWeight{W: 1}
+		if m.Widths() != nil && i < len(m.Widths()) {
+			dim = m.Widths()[i]
+		}
+		cws = append(cws, &gowid.ContainerWidget{IWidget: w, D: dim})
+		if flowVertDivider != nil {
+			cws = append(cws, flowVertDivider)
+		}
+	}
+	return columns.New(cws, columns.Options{
+		StartColumn: focus,
+	})
+}
+
+func (m *BoundedModel) Rows() int {
+	return m.IModel.(table.IBoundedModel).Rows()
+}
+
+//======================================================================
+
+// DefaultActivityFrameInterval is the time between redraws while an Activity's rows cool down, unless
+// it's configured otherwise.
+var DefaultActivityFrameInterval = time.Second / 10
+
+// Activity tracks when rows last changed, so it can provide the heat for a gutter - pass its Heat method
+// as Options.Heat. A row's heat is 1 when it's touched, then halves every HalfLife. While any row is
+// warm, the app is redrawn every FrameInterval so the gutter fades smoothly. Timing uses the app's
+// clock - see gowid.ClockFor. Activity's methods must be called on the app goroutine.
+type Activity struct {
+	HalfLife      time.Duration
+	FrameInterval time.Duration
+	touched       map[interface{}]time.Time
+	timer         *gowid.Timer // Redraws while any row is warm
+}
+
+func NewActivity(halfLife time.Duration) *Activity {
+	return &Activity{
+		HalfLife:      halfLife,
+		FrameInterval: DefaultActivityFrameInterval,
+		touched:       make(map[interface{}]time.Time),
+	}
+}
+
+// coldHeat is the heat below which a row is forgotten.
+const coldHeat = 0.01
+
+// Touch records that the row with key has changed now, making it as hot as it can be.
+func (a *Activity) Touch(key interface{}, app gowid.IApp) {
+	clock := gowid.ClockFor(app)
+	a.touched[key] = clock.Now()
+	if a.timer == nil {
+		a.schedule(app)
+	}
+}
+
+// Heat returns the heat of the row with key - 1 when it has just been touched, falling towards 0.
+func (a *Activity) Heat(key interface{}, app gowid.IApp) float64 {
+	t, ok := a.touched[key]
+	if !ok {
+		return 0
+	}
+	return a.heat(gowid.ClockFor(app).Since(t))
+}
+
+func (a *Activity) heat(age time.Duration) float64 {
+	if a.HalfLife <= 0 {
+		return 0
+	}
+	res := math.Pow(0.5, float64(age)/float64(a.HalfLife))
+	if res < coldHeat {
+		return 0
+	}
+	return res
+}
+
+// Reset forgets all activity.
+func (a *Activity) Reset() {
+	a.touched = make(map[interface{}]time.Time)
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+}
+
+// schedule redraws the app every frame, forgetting rows as they go cold, until none are left.
+func (a *Activity) schedule(app gowid.IApp) {
+	interval := a.FrameInterval
+	if interval <= 0 {
+		interval = DefaultActivityFrameInterval
+	}
+	a.timer = gowid.Every(app, interval, func(app gowid.IApp) {
+		clock := gowid.ClockFor(app)
+		for k, t := range a.touched {
+			if a.heat(clock.Since(t)) == 0 {
+				delete(a.touched, k)
+			}
+		}
+		if len(a.touched) == 0 {
+			a.timer.Stop()
+			a.timer = nil
+		}
+	})
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
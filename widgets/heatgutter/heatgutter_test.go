@@ -0,0 +1,135 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package heatgutter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/list"
+	"github.com/gcla/gowid/widgets/table"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestCanvas1(t *testing.T) {
+	heat := func(key interface{}, app gowid.IApp) float64 {
+		return key.(float64)
+	}
+	w := New(text.New("ab\ncd"), 1.0, Options{Heat: heat, Width: 2})
+	c := w.Render(gowid.RenderFlowWith{C: 4}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "  ab\n  cd", c.String())
+	assert.Equal(t, gowid.RenderBox{C: 4, R: 2}, w.RenderSize(gowid.RenderFlowWith{C: 4}, gowid.NotSelected, gwtest.D))
+
+	hot := gowid.IColorToTCell(DefaultGradient.At(1), gowid.ColorNone, gwtest.D.GetColorMode())
+	assert.Equal(t, hot, c.CellAt(1, 1).BackgroundColor())
+	assert.Equal(t, gowid.ColorNone, c.CellAt(2, 1).BackgroundColor())
+
+	// No heat, no color
+	w = New(text.New("ab"), 0.0, Options{Heat: heat, Rune: '▌'})
+	c = w.Render(gowid.RenderFixed{}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, " ab", c.String())
+
+	w = New(text.New("ab"), 0.5, Options{Heat: heat, Rune: '▌'})
+	c = w.Render(gowid.RenderFixed{}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "▌ab", c.String())
+	assert.Equal(t, gowid.IColorToTCell(DefaultGradient.At(0.5), gowid.ColorNone, gwtest.D.GetColorMode()),
+		c.CellAt(0, 0).ForegroundColor())
+}
+
+func TestColorMap1(t *testing.T) {
+	heat := func(key interface{}, app gowid.IApp) float64 {
+		return key.(float64)
+	}
+	cm := gowid.NewColorMap(gowid.ViridisPalette, gowid.LinearScale)
+	w := New(text.New("ab"), 1.0, Options{Heat: heat, ColorMap: cm})
+	c := w.Render(gowid.RenderFixed{}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, gowid.IColorToTCell(cm.At(1), gowid.ColorNone, gwtest.D.GetColorMode()),
+		c.CellAt(0, 0).BackgroundColor())
+}
+
+func TestActivity1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	act := NewActivity(time.Second)
+	act.FrameInterval = 500 * time.Millisecond
+
+	walker := list.NewSimpleListWalker([]gowid.IWidget{text.New("one"), text.New("two")})
+	lb := list.New(NewWalker(walker, Options{Heat: act.Heat}))
+
+	sim := gwtest.NewSimT(t, lb, gwtest.SimOptions{Cols: 5, Rows: 2, Clock: clock})
+	defer sim.Close()
+	sim.AssertLine(t, 1, " two ")
+
+	bg := func(y int) tcell.Color {
+		_, st := sim.Cell(0, y)
+		_, res, _ := st.Decompose()
+		return res
+	}
+	assert.Equal(t, tcell.ColorDefault, bg(1))
+
+	act.Touch(list.ListPos(1), sim)
+	sim.Redraw()
+	sim.Frame()
+	assert.Equal(t, 1.0, act.Heat(list.ListPos(1), sim))
+	assert.NotEqual(t, tcell.ColorDefault, bg(1))
+	assert.Equal(t, tcell.ColorDefault, bg(0))
+	hot := bg(1)
+
+	// The row cools, and the gutter is redrawn without any prompting
+	clock.Advance(time.Second)
+	sim.Frame()
+	assert.InDelta(t, 0.5, act.Heat(list.ListPos(1), sim), 1e-9)
+	assert.NotEqual(t, hot, bg(1))
+
+	clock.Advance(10 * time.Second)
+	sim.Frame()
+	assert.Equal(t, 0.0, act.Heat(list.ListPos(1), sim))
+	assert.Equal(t, tcell.ColorDefault, bg(1))
+	assert.Equal(t, 0, clock.Pending())
+}
+
+func TestTable1(t *testing.T) {
+	heat := func(key interface{}, app gowid.IApp) float64 {
+		if key.(table.RowId) == 1 {
+			return 1
+		}
+		return 0
+	}
+	model := NewModel(table.NewSimpleModel([]string{"h"}, [][]string{{"a"}, {"b"}}), Options{Heat: heat})
+	_, ok := model.(table.IBoundedModel)
+	assert.True(t, ok)
+
+	tb := table.New(model)
+	sim := gwtest.NewSimT(t, tb, gwtest.SimOptions{Cols: 9, Rows: 6})
+	defer sim.Close()
+
+	x, y, ok := sim.Find("b")
+	assert.True(t, ok)
+	_, st := sim.Cell(x-1, y)
+	_, bg, _ := st.Decompose()
+	assert.NotEqual(t, tcell.ColorDefault, bg)
+
+	x2, y2, ok := sim.Find("a")
+	assert.True(t, ok)
+	assert.Equal(t, x, x2)
+
+	// The header stays aligned with the rows
+	x3, _, ok := sim.Find("h")
+	assert.True(t, ok)
+	assert.Equal(t, x, x3)
+	_, st = sim.Cell(x2-1, y2)
+	_, bg, _ = st.Decompose()
+	assert.Equal(t, tcell.ColorDefault, bg)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
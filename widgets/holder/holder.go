@@ -39,6 +39,7 @@ func (w *Widget) SubWidget() gowid.IWidget {
 }
 
 func (w *Widget) SetSubWidget(wi gowid.IWidget, app gowid.IApp) {
+	gowid.LayoutChanging(app, w)
 	w.IWidget = wi
 	gowid.RunWidgetCallbacks(w, gowid.SubWidgetCB{}, app, w)
 }
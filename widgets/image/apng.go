@@ -0,0 +1,210 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/png"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//======================================================================
+
+// pngSignature starts every PNG file.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// The ways an APNG frame is disposed of, and drawn over the frames before it
+const (
+	apngDisposeNone       = 0
+	apngDisposeBackground = 1
+	apngDisposePrevious   = 2
+	apngBlendOver         = 1
+)
+
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+// apngFrame is a frame of an animated PNG, as described by its fcTL chunk.
+type apngFrame struct {
+	rect           image.Rectangle
+	delay          time.Duration
+	dispose, blend byte
+	data           [][]byte // The frame's image data, from IDAT or fdAT chunks
+}
+
+// isPNG returns true if data starts with the PNG signature.
+func isPNG(data []byte) bool {
+	return bytes.HasPrefix(data, pngSignature)
+}
+
+// pngChunks splits the PNG in data into its chunks. Their checksums aren't checked - the image decoder
+// checks those of the chunks it reads.
+func pngChunks(data []byte) ([]pngChunk, error) {
+	if !isPNG(data) {
+		return nil, errors.New("Not a PNG")
+	}
+	data = data[len(pngSignature):]
+	res := make([]pngChunk, 0)
+	for len(data) >= 12 {
+		n := binary.BigEndian.Uint32(data)
+		if uint64(n)+12 > uint64(len(data)) {
+			return nil, errors.New("Truncated PNG chunk")
+		}
+		res = append(res, pngChunk{typ: string(data[4:8]), data: data[8 : 8+n]})
+		data = data[12+n:]
+	}
+	return res, nil
+}
+
+// apngFrames returns the frames of the animated PNG in data as they are displayed, each drawn over the
+// ones before it, how long to show each, and the number of times to play the animation, or 0 to play it
+// forever. If data is a PNG that isn't animated, no frames are returned.
+func apngFrames(data []byte) ([]image.Image, []time.Duration, int, error) {
+	chunks, err := pngChunks(data)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	var ihdr []byte
+	var header []pngChunk // Chunks before the image data, e.g. the palette, that each frame needs
+	var frames []*apngFrame
+	animated, inData := false, false
+	loops := 0
+	for _, c := range chunks {
+		switch c.typ {
+		case "IHDR":
+			if len(c.data) < 13 {
+				return nil, nil, 0, errors.New("Invalid PNG header")
+			}
+			ihdr = c.data
+		case "acTL":
+			if len(c.data) < 8 {
+				return nil, nil, 0, errors.New("Invalid APNG animation control")
+			}
+			animated = true
+			loops = int(binary.BigEndian.Uint32(c.data[4:]))
+		case "fcTL":
+			if len(c.data) < 26 {
+				return nil, nil, 0, errors.New("Invalid APNG frame control")
+			}
+			d := c.data
+			w, h := int(binary.BigEndian.Uint32(d[4:])), int(binary.BigEndian.Uint32(d[8:]))
+			x, y := int(binary.BigEndian.Uint32(d[12:])), int(binary.BigEndian.Uint32(d[16:]))
+			num, den := time.Duration(binary.BigEndian.Uint16(d[20:])), time.Duration(binary.BigEndian.Uint16(d[22:]))
+			if den == 0 {
+				den = 100
+			}
+			frames = append(frames, &apngFrame{
+				rect:    image.Rect(x, y, x+w, y+h),
+				delay:   num * time.Second / den,
+				dispose: d[24],
+				blend:   d[25],
+			})
+		case "IDAT":
+			inData = true
+			// The default image is the first frame only if a frame control comes before it
+			if len(frames) == 1 {
+				frames[0].data = append(frames[0].data, c.data)
+			}
+		case "fdAT":
+			if len(frames) > 0 && len(c.data) >= 4 {
+				f := frames[len(frames)-1]
+				f.data = append(f.data, c.data[4:])
+			}
+		case "IEND":
+		default:
+			if !inData {
+				header = append(header, c)
+			}
+		}
+	}
+	if !animated || ihdr == nil {
+		return nil, nil, 0, nil
+	}
+
+	width, height := int(binary.BigEndian.Uint32(ihdr)), int(binary.BigEndian.Uint32(ihdr[4:]))
+	bounds := image.Rect(0, 0, width, height)
+	canvas := image.NewRGBA(bounds)
+	res := make([]image.Image, 0, len(frames))
+	delays := make([]time.Duration, 0, len(frames))
+	for i, f := range frames {
+		if len(f.data) == 0 {
+			continue
+		}
+		img, err := png.Decode(bytes.NewReader(apngFramePNG(ihdr, header, f)))
+		if err != nil {
+			return nil, nil, 0, errors.WithStack(err)
+		}
+		var previous *image.RGBA
+		if f.dispose == apngDisposePrevious && i > 0 {
+			previous = image.NewRGBA(bounds)
+			draw.Draw(previous, bounds, canvas, bounds.Min, draw.Src)
+		}
+
+		op := draw.Src
+		if f.blend == apngBlendOver {
+			op = draw.Over
+		}
+		draw.Draw(canvas, f.rect, img, img.Bounds().Min, op)
+		shown := image.NewRGBA(bounds)
+		draw.Draw(shown, bounds, canvas, bounds.Min, draw.Src)
+		res = append(res, shown)
+		delays = append(delays, f.delay)
+
+		switch {
+		case previous != nil:
+			canvas = previous
+		case f.dispose != apngDisposeNone:
+			// Disposing of the first frame to the previous one clears it, like disposing to the background
+			draw.Draw(canvas, f.rect, image.Transparent, image.Point{}, draw.Src)
+		}
+	}
+	return res, delays, loops, nil
+}
+
+// apngFramePNG returns a PNG of frame f alone, with the header of the animation, resized to the frame.
+func apngFramePNG(ihdr []byte, header []pngChunk, f *apngFrame) []byte {
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	hdr := append([]byte{}, ihdr...)
+	binary.BigEndian.PutUint32(hdr, uint32(f.rect.Dx()))
+	binary.BigEndian.PutUint32(hdr[4:], uint32(f.rect.Dy()))
+	writePNGChunk(&buf, "IHDR", hdr)
+	for _, c := range header {
+		writePNGChunk(&buf, c.typ, c.data)
+	}
+	for _, d := range f.data {
+		writePNGChunk(&buf, "IDAT", d)
+	}
+	writePNGChunk(&buf, "IEND", nil)
+	return buf.Bytes()
+}
+
+func writePNGChunk(buf *bytes.Buffer, typ string, data []byte) {
+	var n [4]byte
+	binary.BigEndian.PutUint32(n[:], uint32(len(data)))
+	buf.Write(n[:])
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	crc.Write(data)
+	buf.WriteString(typ)
+	buf.Write(data)
+	binary.BigEndian.PutUint32(n[:], crc.Sum32())
+	buf.Write(n[:])
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
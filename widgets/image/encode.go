@@ -0,0 +1,316 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package image
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/png"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+)
+
+//======================================================================
+
+// CacheTTL is how long a picture encoded for a graphics protocol is kept in the app's cache - see
+// gowid.CacheFor - so that e.g. the frames of an animation are only encoded the first time round.
+var CacheTTL = time.Hour
+
+// sixelPalette is the palette to which pictures are reduced for Sixel - the 216 web-safe colors, after
+// a transparent entry for pixels that are left as they are.
+var sixelPalette = append(color.Palette{color.Transparent}, palette.WebSafe...)
+
+// Fit returns img scaled to fit within width by height pixels, keeping its shape, and centered on a
+// transparent background of that size.
+func Fit(img image.Image, width, height int) *image.RGBA {
+	res := image.NewRGBA(image.Rect(0, 0, width, height))
+	bounds := img.Bounds()
+	iw, ih := bounds.Dx(), bounds.Dy()
+	if iw == 0 || ih == 0 || width == 0 || height == 0 {
+		return res
+	}
+	pw, ph := width, height
+	if iw*ph > ih*pw {
+		ph = gwutil.Max(1, ih*pw/iw)
+	} else {
+		pw = gwutil.Max(1, iw*ph/ih)
+	}
+	x0, y0 := (width-pw)/2, (height-ph)/2
+	for y := 0; y < ph; y++ {
+		for x := 0; x < pw; x++ {
+			res.Set(x0+x, y0+y, img.At(bounds.Min.X+x*iw/pw, bounds.Min.Y+y*ih/ph))
+		}
+	}
+	return res
+}
+
+// Sixel returns the DEC Sixel sequence to draw img at its size in pixels. Colors are reduced to the 216
+// web-safe colors, with dithering; transparent pixels are left as they are.
+func Sixel(img image.Image) string {
+	bounds := img.Bounds()
+	pal := image.NewPaletted(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), sixelPalette)
+	draw.FloydSteinberg.Draw(pal, pal.Bounds(), img, bounds.Min)
+	width, height := pal.Bounds().Dx(), pal.Bounds().Dy()
+
+	var b strings.Builder
+	// P2 = 1 leaves pixels of color 0 unchanged; the raster attributes give square pixels
+	fmt.Fprintf(&b, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	used := make([]bool, len(sixelPalette))
+	for _, i := range pal.Pix {
+		used[i] = true
+	}
+	for i := 1; i < len(sixelPalette); i++ {
+		if used[i] {
+			r, g, bl, _ := sixelPalette[i].RGBA()
+			fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff)
+		}
+	}
+
+	// Each band of six rows is drawn once for each color in it, returning to the start of the band in
+	// between
+	sixels := make([]byte, width)
+	for y0 := 0; y0 < height; y0 += 6 {
+		inBand := make([]bool, len(sixelPalette))
+		for y := y0; y < gwutil.Min(y0+6, height); y++ {
+			for _, i := range pal.Pix[y*pal.Stride : y*pal.Stride+width] {
+				inBand[i] = true
+			}
+		}
+		first := true
+		for c := 1; c < len(sixelPalette); c++ {
+			if !inBand[c] {
+				continue
+			}
+			for x := 0; x < width; x++ {
+				bits := byte(0)
+				for dy := 0; dy < 6 && y0+dy < height; dy++ {
+					if int(pal.Pix[(y0+dy)*pal.Stride+x]) == c {
+						bits |= 1 << uint(dy)
+					}
+				}
+				sixels[x] = '?' + bits
+			}
+			if !first {
+				b.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(&b, "#%d", c)
+			writeSixelRuns(&b, sixels)
+		}
+		b.WriteByte('-')
+	}
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// writeSixelRuns writes sixels, compressing runs of the same sixel.
+func writeSixelRuns(b *strings.Builder, sixels []byte) {
+	for i := 0; i < len(sixels); {
+		j := i
+		for j < len(sixels) && sixels[j] == sixels[i] {
+			j++
+		}
+		if j-i > 3 {
+			fmt.Fprintf(b, "!%d%c", j-i, sixels[i])
+		} else {
+			for k := i; k < j; k++ {
+				b.WriteByte(sixels[k])
+			}
+		}
+		i = j
+	}
+}
+
+// ITerm2 returns iTerm2's inline image sequence to draw img stretched over cols by rows cells.
+func ITerm2(img image.Image, cols, rows int) string {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=0:%s\a",
+		buf.Len(), cols, rows, base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+// kittyChunk is the most base64 data the Kitty protocol allows in one sequence.
+const kittyChunk = 4096
+
+// kittyIDs is the last Kitty image id handed out.
+var kittyIDs uint32
+
+func nextKittyID() uint32 {
+	for {
+		// 0 isn't a valid id
+		if id := atomic.AddUint32(&kittyIDs, 1); id != 0 {
+			return id
+		}
+	}
+}
+
+// Kitty returns the Kitty graphics protocol sequences to draw img stretched over cols by rows cells, as
+// the image with the given id. Drawing another picture with the same id replaces it. The cursor is left
+// where it was, and the terminal is asked not to reply.
+func Kitty(img image.Image, cols, rows int, id uint32) string {
+	return kittyPNG(encodePNG(img), cols, rows, id)
+}
+
+// encodePNG returns img as a PNG, or nil if it can't be encoded.
+func encodePNG(img image.Image) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// kittyPNG is Kitty, for a picture already encoded as a PNG.
+func kittyPNG(picture []byte, cols, rows int, id uint32) string {
+	if picture == nil {
+		return ""
+	}
+	data := base64.StdEncoding.EncodeToString(picture)
+	var b strings.Builder
+	for i := 0; i == 0 || i < len(data); i += kittyChunk {
+		more := 0
+		if i+kittyChunk < len(data) {
+			more = 1
+		}
+		chunk := data[i:gwutil.Min(i+kittyChunk, len(data))]
+		if i == 0 {
+			fmt.Fprintf(&b, "\x1b_Ga=T,f=100,i=%d,p=1,c=%d,r=%d,C=1,q=2,m=%d;%s\x1b\\", id, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return b.String()
+}
+
+// KittyClear returns the Kitty graphics protocol sequence to delete the image with the given id.
+func KittyClear(id uint32) string {
+	return fmt.Sprintf("\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", id)
+}
+
+// Region returns a raw region that draws img over cols by rows cells with protocol, fitted as Render
+// fits it, or nil if the protocol draws with blocks. Mark the canvas drawn by Render with it, using
+// gowid.MarkRawRegion, for a sharper picture on terminals that support the protocol.
+func Region(img image.Image, cols, rows int, protocol gowid.GraphicsProtocol, app gowid.IApp) *gowid.RawRegion {
+	return region(img, cols, rows, protocol, 0, app)
+}
+
+// region is Region, drawing a Kitty picture as the image with the given id - or a new one if it is 0. The
+// picture, fitted and encoded, is kept in the app's cache.
+func region(img image.Image, cols, rows int, protocol gowid.GraphicsProtocol, id uint32, app gowid.IApp) *gowid.RawRegion {
+	cw, ch := gowid.CellPixelSizeFor(app)
+	encoded := func(encode func(fitted image.Image) []byte) []byte {
+		key := fmt.Sprintf("image/%d/%s/%dx%d/%dx%d", protocol, pictureKey(img), cols, rows, cw, ch)
+		res, _ := gowid.Cached(gowid.CacheFor(app), key, CacheTTL, func() ([]byte, error) {
+			return encode(Fit(img, cols*cw, rows*ch)), nil
+		})
+		return res
+	}
+	switch protocol {
+	case gowid.GraphicsSixel:
+		return gowid.NewRawRegion(cols, rows, string(encoded(func(fitted image.Image) []byte {
+			return []byte(Sixel(fitted))
+		})))
+	case gowid.GraphicsITerm2:
+		return gowid.NewRawRegion(cols, rows, string(encoded(func(fitted image.Image) []byte {
+			return []byte(ITerm2(fitted, cols, rows))
+		})))
+	case gowid.GraphicsKitty:
+		if id == 0 {
+			id = nextKittyID()
+		}
+		// The image id changes with the widget, so just the PNG is cached
+		res := gowid.NewRawRegion(cols, rows, kittyPNG(encoded(encodePNG), cols, rows, id))
+		res.Clear = KittyClear(id)
+		return res
+	default:
+		return nil
+	}
+}
+
+// pictureKey returns a hash of img's size and pixels, to identify it in the app's cache.
+func pictureKey(img image.Image) string {
+	h := sha256.New()
+	bounds := img.Bounds()
+	fmt.Fprintf(h, "%dx%d:", bounds.Dx(), bounds.Dy())
+	if rgba, ok := img.(*image.RGBA); ok {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			i := rgba.PixOffset(bounds.Min.X, y)
+			h.Write(rgba.Pix[i : i+bounds.Dx()*4])
+		}
+	} else {
+		var px [16]byte
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				r, g, b, a := img.At(x, y).RGBA()
+				binary.BigEndian.PutUint32(px[0:], r)
+				binary.BigEndian.PutUint32(px[4:], g)
+				binary.BigEndian.PutUint32(px[8:], b)
+				binary.BigEndian.PutUint32(px[12:], a)
+				h.Write(px[:])
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RegionCache marks the canvases of a widget that draws pictures with Render with raw regions for a
+// graphics protocol. Encoding a picture is slow, so the last region is kept and reused while the picture
+// and the canvas size stay the same, and encoded pictures are kept in the app's cache, for the next time
+// the picture is shown at that size; with Kitty, each new picture replaces the last in the terminal. The
+// zero value is ready to use.
+type RegionCache struct {
+	region  *gowid.RawRegion
+	key     regionKey
+	kittyID uint32
+}
+
+type regionKey struct {
+	id, cols, rows        int
+	cellWidth, cellHeight int
+	protocol              gowid.GraphicsProtocol
+}
+
+// Mark marks canvas, drawn by Render for img, with a region drawing img with protocol - or the app's
+// protocol if that is gowid.GraphicsDefault. id identifies img; a new picture needs a new id.
+func (c *RegionCache) Mark(canvas gowid.ICanvas, img image.Image, id int, protocol gowid.GraphicsProtocol, app gowid.IApp) {
+	if protocol == gowid.GraphicsDefault {
+		protocol = gowid.GraphicsProtocolFor(app)
+	}
+	if protocol == gowid.GraphicsBlocks || canvas.BoxColumns() == 0 || canvas.BoxRows() == 0 {
+		return
+	}
+	cw, ch := gowid.CellPixelSizeFor(app)
+	key := regionKey{id, canvas.BoxColumns(), canvas.BoxRows(), cw, ch, protocol}
+	if c.region == nil || key != c.key {
+		if c.kittyID == 0 && protocol == gowid.GraphicsKitty {
+			c.kittyID = nextKittyID()
+		}
+		c.region = region(img, key.cols, key.rows, protocol, c.kittyID, app)
+		c.key = key
+	}
+	if c.region != nil {
+		gowid.MarkRawRegion(canvas, c.region)
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,398 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package image provides a widget that displays a picture, or plays an animated GIF or PNG. Pictures are drawn
+// with the Sixel or iTerm2 inline image protocols on terminals that support them, and otherwise with
+// colored half-block characters - each cell showing two pixels, one above the other.
+package image
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	_ "image/jpeg" // Decode JPEGs in NewFromFile
+	_ "image/png"  // Decode PNGs in NewFromFile
+	"io/ioutil"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/pkg/errors"
+)
+
+//======================================================================
+
+// DefaultFrameDelay is used for frames of an animation that don't say how long to show them. Browsers do
+// the same for delays too short to be meant literally.
+var DefaultFrameDelay = 100 * time.Millisecond
+
+// minFrameDelay is the shortest delay honored; shorter ones get DefaultFrameDelay.
+const minFrameDelay = 20 * time.Millisecond
+
+type IWidget interface {
+	gowid.IWidget
+	// Image returns the picture to display - for an animation, the current frame.
+	Image() image.Image
+}
+
+// Options is used to configure the widget.
+type Options struct {
+	Paused   bool                   // If true, an animation doesn't play until Play is called
+	Protocol gowid.GraphicsProtocol // Defaults to the app's - see gowid.GraphicsProtocolFor
+}
+
+// For callback registration
+type FrameCB struct{}
+
+// Widget displays an image, or an animation of several frames. An animation plays on the app goroutine,
+// timed with the app's clock (see gowid.ClockFor). It only runs while the widget is displayed: if a frame
+// is due and the widget hasn't been rendered since the last one - e.g. it has scrolled out of view - the
+// animation is suspended, and resumes when the widget is next rendered.
+type Widget struct {
+	frames    []image.Image
+	delays    []time.Duration
+	loops     int // Times to play the animation, or 0 to play it forever
+	frame     int
+	played    int // Times the animation has played to the end
+	playing   bool
+	rendered  bool // True if the widget has been rendered since the frame was shown
+	timer     *gowid.Timer
+	protocol  gowid.GraphicsProtocol
+	regions   RegionCache
+	Callbacks *gowid.Callbacks
+	gowid.RejectUserInput
+	gowid.NotSelectable
+}
+
+var _ IWidget = (*Widget)(nil)
+
+// New returns a widget displaying img.
+func New(img image.Image, opts ...Options) *Widget {
+	return newWidget([]image.Image{img}, []time.Duration{0}, 0, opts...)
+}
+
+// NewAnimated returns a widget that plays frames, showing each for the corresponding delay - one of 0
+// gets DefaultFrameDelay. loops is the number of times to play the animation, or 0 to play it forever.
+func NewAnimated(frames []image.Image, delays []time.Duration, loops int, opts ...Options) *Widget {
+	if len(frames) == 0 {
+		panic(errors.New("An animation needs at least one frame"))
+	}
+	return newWidget(frames, delays, loops, opts...)
+}
+
+// NewGIF returns a widget that plays g, honoring its frame delays, how each frame is disposed of and
+// how many times it loops.
+func NewGIF(g *gif.GIF, opts ...Options) *Widget {
+	frames, delays := gifFrames(g)
+	loops := 0
+	switch {
+	case g.LoopCount < 0:
+		loops = 1
+	case g.LoopCount > 0:
+		loops = g.LoopCount + 1
+	}
+	return NewAnimated(frames, delays, loops, opts...)
+}
+
+// NewFromFile returns a widget displaying the image in the file at path - a PNG, a JPEG, or a GIF. An
+// animated GIF or PNG is played, honoring its frame delays, how each frame is drawn over the last, and
+// how many times it loops.
+func NewFromFile(path string, opts ...Options) (*Widget, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if g, err := gif.DecodeAll(bytes.NewReader(data)); err == nil {
+		return NewGIF(g, opts...), nil
+	}
+	if isPNG(data) {
+		frames, delays, loops, err := apngFrames(data)
+		if err != nil {
+			return nil, err
+		}
+		if len(frames) > 0 {
+			return NewAnimated(frames, delays, loops, opts...), nil
+		}
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return New(img, opts...), nil
+}
+
+func newWidget(frames []image.Image, delays []time.Duration, loops int, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return &Widget{
+		frames:    frames,
+		delays:    delays,
+		loops:     loops,
+		playing:   len(frames) > 1 && !opt.Paused,
+		protocol:  opt.Protocol,
+		Callbacks: gowid.NewCallbacks(),
+	}
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("image[%d frames]", len(w.frames))
+}
+
+func (w *Widget) Image() image.Image {
+	return w.frames[w.frame]
+}
+
+// Protocol returns the protocol with which the widget draws pictures - gowid.GraphicsDefault for the
+// app's.
+func (w *Widget) Protocol() gowid.GraphicsProtocol {
+	return w.protocol
+}
+
+func (w *Widget) SetProtocol(p gowid.GraphicsProtocol, app gowid.IApp) {
+	w.protocol = p
+}
+
+// Frames returns the number of frames - 1 for a still image.
+func (w *Widget) Frames() int {
+	return len(w.frames)
+}
+
+func (w *Widget) Frame() int {
+	return w.frame
+}
+
+// SetFrame shows frame i of the animation. If the animation is playing, it continues from there.
+func (w *Widget) SetFrame(i int, app gowid.IApp) {
+	w.frame = gwutil.Min(gwutil.Max(i, 0), len(w.frames)-1)
+	if w.playing {
+		w.schedule(app)
+	}
+	gowid.RunWidgetCallbacks(w.Callbacks, FrameCB{}, app, w)
+}
+
+func (w *Widget) OnFrame(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w.Callbacks, FrameCB{}, f)
+}
+
+func (w *Widget) RemoveOnFrame(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w.Callbacks, FrameCB{}, f)
+}
+
+// Playing returns true if the animation is playing, even if it's suspended because it isn't displayed.
+func (w *Widget) Playing() bool {
+	return w.playing
+}
+
+// Play starts the animation from the current frame - from the start if it has finished. It must be
+// called on the app goroutine.
+func (w *Widget) Play(app gowid.IApp) {
+	if len(w.frames) < 2 {
+		return
+	}
+	if w.loops > 0 && w.played >= w.loops {
+		w.played = 0
+		w.frame = 0
+	}
+	w.playing = true
+	w.schedule(app)
+}
+
+// Pause stops the animation at the current frame.
+func (w *Widget) Pause(app gowid.IApp) {
+	w.playing = false
+	w.stop()
+}
+
+func (w *Widget) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+}
+
+func (w *Widget) delay() time.Duration {
+	if w.frame < len(w.delays) && w.delays[w.frame] >= minFrameDelay {
+		return w.delays[w.frame]
+	}
+	return DefaultFrameDelay
+}
+
+// schedule arranges for the next frame to be shown once the current one's delay has passed.
+func (w *Widget) schedule(app gowid.IApp) {
+	w.stop()
+	w.timer = gowid.After(app, w.delay(), func(app gowid.IApp) {
+		w.timer = nil
+		w.next(app)
+	})
+}
+
+func (w *Widget) next(app gowid.IApp) {
+	if !w.rendered {
+		// Not displayed since the last frame - wait to be rendered again
+		return
+	}
+	if w.frame == len(w.frames)-1 {
+		w.played++
+		if w.loops > 0 && w.played >= w.loops {
+			w.playing = false
+			return
+		}
+	}
+	w.rendered = false
+	w.frame = (w.frame + 1) % len(w.frames)
+	w.schedule(app)
+	gowid.RunWidgetCallbacks(w.Callbacks, FrameCB{}, app, w)
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	cols, rows := cellSize(w.Image(), size)
+	return gowid.RenderBox{C: cols, R: rows}
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	w.rendered = true
+	if w.playing && w.timer == nil {
+		// Resume a suspended animation
+		w.schedule(app)
+	}
+	res := Render(w, size, focus, app)
+	w.regions.Mark(res, w.Image(), w.frame, w.protocol, app)
+	return res
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// Render draws w's image with half-block characters, scaled to fit the size while keeping its shape -
+// its width for a flow size, and its own size for a fixed one. Transparent pixels are left blank.
+func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	img := w.Image()
+	cols, rows := cellSize(img, size)
+	res := gowid.NewCanvasOfSize(cols, rows)
+
+	bounds := img.Bounds()
+	iw, ih := bounds.Dx(), bounds.Dy()
+	if iw == 0 || ih == 0 || cols == 0 || rows == 0 {
+		return res
+	}
+
+	// Fit the image in the space, two pixels to a cell
+	pw, ph := cols, rows*2
+	if iw*ph > ih*pw {
+		ph = gwutil.Max(1, ih*pw/iw)
+	} else {
+		pw = gwutil.Max(1, iw*ph/ih)
+	}
+	x0, y0 := (cols-pw)/2, (rows*2-ph)/2
+
+	mode := app.GetColorMode()
+	pixel := func(x, y int) (gowid.TCellColor, bool) {
+		x, y = x-x0, y-y0
+		if x < 0 || x >= pw || y < 0 || y >= ph {
+			return gowid.ColorNone, false
+		}
+		c := img.At(bounds.Min.X+x*iw/pw, bounds.Min.Y+y*ih/ph)
+		return pixelColor(c, mode)
+	}
+
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			top, hasTop := pixel(x, y*2)
+			bottom, hasBottom := pixel(x, y*2+1)
+			var cell gowid.Cell
+			switch {
+			case hasTop && hasBottom:
+				cell = gowid.MakeCell('▀', top, bottom, gowid.StyleNone)
+			case hasTop:
+				cell = gowid.MakeCell('▀', top, gowid.ColorNone, gowid.StyleNone)
+			case hasBottom:
+				cell = gowid.MakeCell('▄', bottom, gowid.ColorNone, gowid.StyleNone)
+			default:
+				cell = gowid.CellFromRune(' ')
+			}
+			res.SetCellAt(x, y, cell)
+		}
+	}
+	return res
+}
+
+// cellSize returns the number of columns and rows in which to draw img - its width and half its height in
+// pixels, unless the size says otherwise.
+func cellSize(img image.Image, size gowid.IRenderSize) (int, int) {
+	bounds := img.Bounds()
+	iw, ih := bounds.Dx(), bounds.Dy()
+	switch sz := size.(type) {
+	case gowid.IRenderBox:
+		return sz.BoxColumns(), sz.BoxRows()
+	case gowid.IRenderFlowWith:
+		if iw == 0 {
+			return sz.FlowColumns(), 0
+		}
+		return sz.FlowColumns(), (sz.FlowColumns()*ih/iw + 1) / 2
+	default:
+		return iw, (ih + 1) / 2
+	}
+}
+
+// pixelColor returns the color with which to draw c, or false if c is more transparent than not.
+func pixelColor(c color.Color, mode gowid.ColorMode) (gowid.TCellColor, bool) {
+	r, g, b, a := c.RGBA()
+	if a < 0x8000 {
+		return gowid.ColorNone, false
+	}
+	// Undo the premultiplication by alpha
+	r, g, b = r*0xffff/a, g*0xffff/a, b*0xffff/a
+	col := gowid.MakeRGBColorExt(int(r>>8), int(g>>8), int(b>>8))
+	return gowid.IColorToTCell(col, gowid.ColorNone, mode), true
+}
+
+// gifFrames returns the frames of g as they are displayed, each drawn over the ones before it according to
+// their disposal methods, and how long to show each.
+func gifFrames(g *gif.GIF) ([]image.Image, []time.Duration) {
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() && len(g.Image) > 0 {
+		bounds = g.Image[0].Bounds()
+	}
+	canvas := image.NewRGBA(bounds)
+	frames := make([]image.Image, len(g.Image))
+	delays := make([]time.Duration, len(g.Image))
+	for i, frame := range g.Image {
+		var previous *image.RGBA
+		disposal := byte(gif.DisposalNone)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(bounds)
+			draw.Draw(previous, bounds, canvas, bounds.Min, draw.Src)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		shown := image.NewRGBA(bounds)
+		draw.Draw(shown, bounds, canvas, bounds.Min, draw.Src)
+		frames[i] = shown
+		if i < len(g.Delay) {
+			delays[i] = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		}
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return frames, delays
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,364 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/holder"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+var (
+	red  = color.RGBA{0xff, 0, 0, 0xff}
+	blue = color.RGBA{0, 0, 0xff, 0xff}
+)
+
+// solid returns a w x h image filled with c.
+func solid(w, h int, c color.Color) *image.RGBA {
+	res := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			res.Set(x, y, c)
+		}
+	}
+	return res
+}
+
+func tcellColor(c color.Color, app gowid.IApp) gowid.TCellColor {
+	res, _ := pixelColor(c, app.GetColorMode())
+	return res
+}
+
+func TestRender1(t *testing.T) {
+	img := solid(2, 2, red)
+	img.Set(0, 1, blue)
+	img.Set(1, 1, color.Transparent)
+	img.Set(1, 0, color.Transparent)
+
+	w := New(img)
+	c := w.Render(gowid.RenderFixed{}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, 2, c.BoxColumns())
+	assert.Equal(t, 1, c.BoxRows())
+	assert.Equal(t, '▀', c.CellAt(0, 0).Rune())
+	assert.Equal(t, tcellColor(red, gwtest.D), c.CellAt(0, 0).ForegroundColor())
+	assert.Equal(t, tcellColor(blue, gwtest.D), c.CellAt(0, 0).BackgroundColor())
+	assert.Equal(t, ' ', c.CellAt(1, 0).Rune())
+
+	// Scaled to the width, keeping its shape
+	w = New(solid(8, 8, red))
+	assert.Equal(t, gowid.RenderBox{C: 4, R: 2}, w.RenderSize(gowid.RenderFlowWith{C: 4}, gowid.NotSelected, gwtest.D))
+
+	// Centered in a box
+	c = w.Render(gowid.RenderBox{C: 4, R: 1}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, " ▀▀ ", c.String())
+}
+
+func TestAnimation1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := NewAnimated([]image.Image{solid(1, 2, red), solid(1, 2, blue)},
+		[]time.Duration{50 * time.Millisecond, 0}, 0)
+	h := holder.New(w)
+	sim := gwtest.NewSimT(t, h, gwtest.SimOptions{Cols: 1, Rows: 1, Clock: clock})
+	defer sim.Close()
+	fg := func() gowid.TCellColor {
+		return tcellColor(w.Image().At(0, 0), sim)
+	}
+
+	assert.True(t, w.Playing())
+	assert.Equal(t, 0, w.Frame())
+	assert.Equal(t, tcellColor(red, sim), fg())
+
+	clock.Advance(50 * time.Millisecond)
+	sim.Frame()
+	assert.Equal(t, 1, w.Frame())
+	assert.Equal(t, tcellColor(blue, sim), fg())
+
+	// The second frame has no delay, so gets the default
+	clock.Advance(DefaultFrameDelay - time.Millisecond)
+	sim.Frame()
+	assert.Equal(t, 1, w.Frame())
+	clock.Advance(time.Millisecond)
+	sim.Frame()
+	assert.Equal(t, 0, w.Frame())
+
+	w.Pause(sim)
+	clock.Advance(time.Second)
+	sim.Frame()
+	assert.Equal(t, 0, w.Frame())
+	assert.False(t, w.Playing())
+
+	// Out of view, the animation is suspended
+	w.Play(sim)
+	h.SetSubWidget(text.New("x"), sim)
+	sim.Redraw()
+	sim.Frame()
+	clock.Advance(50 * time.Millisecond)
+	sim.Frame()
+	assert.Equal(t, 1, w.Frame())
+	clock.Advance(time.Second)
+	sim.Frame()
+	assert.Equal(t, 1, w.Frame())
+	assert.Equal(t, 0, clock.Pending())
+	assert.True(t, w.Playing())
+
+	h.SetSubWidget(w, sim)
+	sim.Redraw()
+	sim.Frame()
+	assert.Equal(t, 1, clock.Pending())
+	clock.Advance(DefaultFrameDelay)
+	sim.Frame()
+	assert.Equal(t, 0, w.Frame())
+}
+
+func TestGIF1(t *testing.T) {
+	pal := color.Palette{color.Transparent, red, blue}
+	f1 := image.NewPaletted(image.Rect(0, 0, 2, 2), pal)
+	for i := range f1.Pix {
+		f1.Pix[i] = 1
+	}
+	// The second frame only covers the top left pixel
+	f2 := image.NewPaletted(image.Rect(0, 0, 1, 1), pal)
+	f2.Pix[0] = 2
+	g := &gif.GIF{
+		Image:     []*image.Paletted{f1, f2},
+		Delay:     []int{10, 20},
+		Disposal:  []byte{gif.DisposalNone, gif.DisposalNone},
+		LoopCount: -1,
+		Config:    image.Config{Width: 2, Height: 2},
+	}
+	frames, delays := gifFrames(g)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, delays)
+	r, _, b, _ := frames[1].At(0, 0).RGBA()
+	assert.Equal(t, uint32(0), r)
+	assert.Equal(t, uint32(0xffff), b)
+	r, _, _, _ = frames[1].At(1, 1).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+
+	// Played once, then stops on the last frame
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := NewGIF(g)
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 2, Rows: 1, Clock: clock})
+	defer sim.Close()
+	clock.Advance(100 * time.Millisecond)
+	sim.Frame()
+	assert.Equal(t, 1, w.Frame())
+	clock.Advance(200 * time.Millisecond)
+	sim.Frame()
+	assert.Equal(t, 1, w.Frame())
+	assert.False(t, w.Playing())
+
+	w.Play(sim)
+	assert.Equal(t, 0, w.Frame())
+	assert.True(t, w.Playing())
+}
+
+func TestSixel1(t *testing.T) {
+	img := solid(5, 2, red)
+	img.Set(4, 1, color.Transparent)
+	s := Sixel(img)
+	// Red is entry 180 of the palette: the transparent entry, then the web-safe colors
+	assert.True(t, strings.HasPrefix(s, "\x1bP0;1;0q\"1;1;5;2#181;2;100;0;0"), s)
+	assert.True(t, strings.HasSuffix(s, "#181!4B@-\x1b\\"), s)
+
+	it := ITerm2(img, 3, 1)
+	assert.True(t, strings.HasPrefix(it, "\x1b]1337;File=inline=1;size="), it)
+	assert.True(t, strings.Contains(it, ";width=3;height=1;preserveAspectRatio=0:"), it)
+
+	// Fitted and centered
+	f := Fit(solid(2, 1, red), 4, 4)
+	assert.Equal(t, image.Rect(0, 0, 4, 4), f.Bounds())
+	assert.Equal(t, uint8(0), f.RGBAAt(0, 0).A)
+	assert.Equal(t, red, f.RGBAAt(0, 1))
+	assert.Equal(t, red, f.RGBAAt(3, 2))
+	assert.Equal(t, uint8(0), f.RGBAAt(3, 3).A)
+}
+
+func TestRegion1(t *testing.T) {
+	gowid.DefaultCache = gowid.NewMemoryCache(10)
+
+	w := New(solid(4, 4, red), Options{Protocol: gowid.GraphicsSixel})
+	c := w.Render(gowid.RenderBox{C: 2, R: 1}, gowid.NotSelected, gwtest.D)
+	r := c.CellAt(0, 0).RawRegion()
+	assert.NotNil(t, r)
+	assert.Equal(t, 2, r.Cols)
+	assert.Equal(t, 1, r.Rows)
+	assert.True(t, r == c.CellAt(1, 0).RawRegion())
+	// The half-blocks are still drawn for terminals that can't show the region
+	assert.Equal(t, '▀', c.CellAt(0, 0).Rune())
+
+	// The encoding is reused until the picture or its size changes
+	c = w.Render(gowid.RenderBox{C: 2, R: 1}, gowid.NotSelected, gwtest.D)
+	assert.True(t, r == c.CellAt(0, 0).RawRegion())
+	c = w.Render(gowid.RenderBox{C: 3, R: 1}, gowid.NotSelected, gwtest.D)
+	assert.False(t, r == c.CellAt(0, 0).RawRegion())
+
+	w = New(solid(4, 4, red))
+	c = w.Render(gowid.RenderBox{C: 2, R: 1}, gowid.NotSelected, gwtest.D)
+	assert.Nil(t, c.CellAt(0, 0).RawRegion())
+}
+
+func TestKitty1(t *testing.T) {
+	gowid.DefaultCache = gowid.NewMemoryCache(10)
+
+	img := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	// Noise, so the PNG needs several chunks
+	rand.New(rand.NewSource(1)).Read(img.Pix)
+	s := Kitty(img, 4, 2, 7)
+	assert.True(t, strings.HasPrefix(s, "\x1b_Ga=T,f=100,i=7,p=1,c=4,r=2,C=1,q=2,m=1;"), s[:60])
+	assert.True(t, strings.Contains(s, "\x1b\\\x1b_Gm=1;"))
+	assert.True(t, strings.HasSuffix(s, "\x1b\\") && strings.Contains(s, "\x1b_Gm=0;"))
+	assert.Equal(t, "\x1b_Ga=d,d=I,i=7,q=2\x1b\\", KittyClear(7))
+
+	// A widget keeps its image id, so each picture replaces the last
+	w := NewAnimated([]image.Image{solid(2, 2, red), solid(2, 2, blue)}, nil, 0,
+		Options{Protocol: gowid.GraphicsKitty, Paused: true})
+	r1 := w.Render(gowid.RenderBox{C: 2, R: 1}, gowid.NotSelected, gwtest.D).CellAt(0, 0).RawRegion()
+	w.SetFrame(1, gwtest.D)
+	r2 := w.Render(gowid.RenderBox{C: 2, R: 1}, gowid.NotSelected, gwtest.D).CellAt(0, 0).RawRegion()
+	assert.NotEqual(t, r1.Sequence, r2.Sequence)
+	assert.NotEqual(t, "", r1.Clear)
+	assert.Equal(t, r1.Clear, r2.Clear)
+	assert.NotEqual(t, r1.Clear, Region(solid(2, 2, red), 2, 1, gowid.GraphicsKitty, gwtest.D).Clear)
+}
+
+// countingCache is a cache that counts the values stored in it.
+type countingCache struct {
+	gowid.ICache
+	puts int
+}
+
+func (c *countingCache) Put(key string, value []byte, ttl time.Duration) error {
+	c.puts++
+	return c.ICache.Put(key, value, ttl)
+}
+
+func TestRegionCache1(t *testing.T) {
+	cache := &countingCache{ICache: gowid.NewMemoryCache(10)}
+	gowid.DefaultCache = cache
+
+	// Each frame is encoded the first time it's shown, and taken from the cache after that
+	w := NewAnimated([]image.Image{solid(2, 2, red), solid(2, 2, blue)}, nil, 0,
+		Options{Protocol: gowid.GraphicsSixel, Paused: true})
+	sz := gowid.RenderBox{C: 2, R: 1}
+	r1 := w.Render(sz, gowid.NotSelected, gwtest.D).CellAt(0, 0).RawRegion()
+	w.SetFrame(1, gwtest.D)
+	w.Render(sz, gowid.NotSelected, gwtest.D)
+	w.SetFrame(0, gwtest.D)
+	r3 := w.Render(sz, gowid.NotSelected, gwtest.D).CellAt(0, 0).RawRegion()
+	assert.Equal(t, 2, cache.puts)
+	assert.Equal(t, r1.Sequence, r3.Sequence)
+
+	// The same picture in another widget, too
+	New(solid(2, 2, blue), Options{Protocol: gowid.GraphicsSixel}).Render(sz, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, 2, cache.puts)
+	New(solid(2, 2, blue), Options{Protocol: gowid.GraphicsITerm2}).Render(sz, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, 3, cache.puts)
+}
+
+// apng returns an animated PNG of frames, each with a frame control chunk made by fctl, playing loops
+// times.
+func apng(t *testing.T, loops int, frames []image.Image, fctl func(i int) []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl, uint32(len(frames)))
+	binary.BigEndian.PutUint32(actl[4:], uint32(loops))
+	seq := 0
+	for i, f := range frames {
+		chunks, err := pngChunks(encodePNG(f))
+		assert.NoError(t, err)
+		for _, c := range chunks {
+			switch c.typ {
+			case "IHDR":
+				if i == 0 {
+					writePNGChunk(&buf, "IHDR", c.data)
+					writePNGChunk(&buf, "acTL", actl)
+				}
+				d := fctl(i)
+				binary.BigEndian.PutUint32(d, uint32(seq))
+				seq++
+				writePNGChunk(&buf, "fcTL", d)
+			case "IDAT":
+				if i == 0 {
+					writePNGChunk(&buf, "IDAT", c.data)
+					break
+				}
+				data := make([]byte, 4, 4+len(c.data))
+				binary.BigEndian.PutUint32(data, uint32(seq))
+				seq++
+				writePNGChunk(&buf, "fdAT", append(data, c.data...))
+			}
+		}
+	}
+	writePNGChunk(&buf, "IEND", nil)
+	return buf.Bytes()
+}
+
+// fctl returns a frame control chunk for a w x h frame at x, y.
+func fctl(w, h, x, y int, delayMs int, dispose, blend byte) []byte {
+	res := make([]byte, 26)
+	binary.BigEndian.PutUint32(res[4:], uint32(w))
+	binary.BigEndian.PutUint32(res[8:], uint32(h))
+	binary.BigEndian.PutUint32(res[12:], uint32(x))
+	binary.BigEndian.PutUint32(res[16:], uint32(y))
+	binary.BigEndian.PutUint16(res[20:], uint16(delayMs))
+	binary.BigEndian.PutUint16(res[22:], 1000)
+	res[24], res[25] = dispose, blend
+	return res
+}
+
+func TestAPNG1(t *testing.T) {
+	// The second frame only covers the bottom right pixel, and is drawn over the first
+	data := apng(t, 2, []image.Image{solid(2, 2, red), solid(1, 1, blue)}, func(i int) []byte {
+		if i == 0 {
+			return fctl(2, 2, 0, 0, 50, apngDisposeNone, 0)
+		}
+		return fctl(1, 1, 1, 1, 200, apngDisposeNone, apngBlendOver)
+	})
+	frames, delays, loops, err := apngFrames(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, loops)
+	assert.Equal(t, []time.Duration{50 * time.Millisecond, 200 * time.Millisecond}, delays)
+	assert.Equal(t, 2, len(frames))
+	assert.Equal(t, color.RGBAModel.Convert(red), color.RGBAModel.Convert(frames[1].At(0, 0)))
+	assert.Equal(t, color.RGBAModel.Convert(blue), color.RGBAModel.Convert(frames[1].At(1, 1)))
+
+	// A PNG that isn't animated has no frames
+	frames, _, _, err = apngFrames(encodePNG(solid(2, 2, red)))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(frames))
+
+	dir, err := ioutil.TempDir("", "gowid-image")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "anim.png")
+	assert.NoError(t, ioutil.WriteFile(path, data, 0644))
+	w, err := NewFromFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, w.Frames())
+	assert.True(t, w.Playing())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
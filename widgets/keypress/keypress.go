@@ -166,7 +166,7 @@ func UserInput(w IKeyPresser, ev interface{}, size gowid.IRenderSize, focus gowi
 }
 
 func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
-	return w.SubWidget().Render(size, focus, app)
+	return gowid.Render(w.SubWidget(), size, focus, app)
 }
 
 //======================================================================
@@ -0,0 +1,352 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package layers provides a widget that stacks any number of overlays - menus, tooltips, dialogs - above
+// a base widget, in an explicit z-order, each with its own policy for user input.
+package layers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/overlay"
+	"github.com/gcla/gowid/widgets/padding"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// InputPolicy says how a layer takes user input, relative to the layers beneath it.
+type InputPolicy int
+
+const (
+	// Occluding layers get input before the layers beneath them. Keys they don't handle are passed down,
+	// as are mouse events outside the layer - mouse events over the layer never reach the layers it
+	// covers. This is the default.
+	Occluding InputPolicy = iota
+	// Modal layers get all input - nothing is passed to the layers beneath, e.g. for a dialog that must be
+	// answered.
+	Modal
+	// PassThrough layers take no input, and never have the focus, e.g. for a tooltip or a notification.
+	PassThrough
+)
+
+func (p InputPolicy) String() string {
+	switch p {
+	case Occluding:
+		return "occluding"
+	case Modal:
+		return "modal"
+	case PassThrough:
+		return "passthrough"
+	default:
+		return fmt.Sprintf("policy(%d)", int(p))
+	}
+}
+
+// Options determines where a layer is placed, and how it takes input. The zero value centers the widget,
+// as a fixed widget, above any layers already added with the same Z.
+type Options struct {
+	Z      int                    // Layers with a higher Z are drawn above those with a lower
+	VAlign gowid.IVAlignment      // Defaults to gowid.VAlignMiddle{}
+	Height gowid.IWidgetDimension // Defaults to gowid.RenderFlow{}
+	HAlign gowid.IHAlignment      // Defaults to gowid.HAlignMiddle{}
+	Width  gowid.IWidgetDimension // Defaults to gowid.RenderFixed{}
+	Input  InputPolicy
+	// Opacity, if between 0 and 1, makes the layer translucent - see overlay.Options.Opacity.
+	Opacity float64
+	// Dim, if greater than 0, is the opacity of a black shade laid over everything beneath the layer -
+	// see overlay.Options.Dim.
+	Dim float64
+}
+
+// Layer is a widget placed in a stack - see Widget.Add.
+type Layer struct {
+	w     gowid.IWidget
+	opts  Options
+	seq   int // Orders layers with the same Z - the layer added or raised last is on top
+	stack *Widget
+}
+
+func (l *Layer) String() string {
+	return fmt.Sprintf("layer[z=%d,%v,%v]", l.opts.Z, l.opts.Input, l.w)
+}
+
+// Widget returns the widget displayed in the layer.
+func (l *Layer) Widget() gowid.IWidget {
+	return l.w
+}
+
+// SetWidget changes the widget displayed in the layer.
+func (l *Layer) SetWidget(w gowid.IWidget, app gowid.IApp) {
+	l.w = w
+}
+
+func (l *Layer) Options() Options {
+	return l.opts
+}
+
+// SetOptions changes the placement, input policy and z-order of the layer.
+func (l *Layer) SetOptions(opts Options, app gowid.IApp) {
+	l.opts = opts.withDefaults()
+	if l.stack != nil {
+		l.stack.sort()
+	}
+}
+
+func (l *Layer) Z() int {
+	return l.opts.Z
+}
+
+// SetZ moves the layer to a new place in the stack, above any other layers with the same Z.
+func (l *Layer) SetZ(z int, app gowid.IApp) {
+	if l.stack != nil {
+		l.stack.setZ(l, z)
+	}
+}
+
+// Raise moves the layer above the others with the same Z.
+func (l *Layer) Raise(app gowid.IApp) {
+	l.SetZ(l.opts.Z, app)
+}
+
+// Lower moves the layer below the others with the same Z.
+func (l *Layer) Lower(app gowid.IApp) {
+	if l.stack != nil {
+		l.stack.seq++
+		l.seq = -l.stack.seq
+		l.stack.sort()
+	}
+}
+
+// Remove takes the layer out of its stack. It returns false if it was already removed.
+func (l *Layer) Remove(app gowid.IApp) bool {
+	if l.stack == nil {
+		return false
+	}
+	return l.stack.Remove(l, app)
+}
+
+func (o Options) withDefaults() Options {
+	if o.VAlign == nil {
+		o.VAlign = gowid.VAlignMiddle{}
+	}
+	if o.Height == nil {
+		o.Height = gowid.RenderFlow{}
+	}
+	if o.HAlign == nil {
+		o.HAlign = gowid.HAlignMiddle{}
+	}
+	if o.Width == nil {
+		o.Width = gowid.RenderFixed{}
+	}
+	return o
+}
+
+//======================================================================
+
+// Widget draws a base widget with a stack of layers above it. It is an ISettableComposite whose
+// SubWidget is the base, so it can be registered with the app - see Register - to hold every overlay
+// the app shows, whatever its root widget.
+type Widget struct {
+	base   gowid.IWidget
+	layers []*Layer // Bottom first
+	seq    int
+	*gowid.Callbacks
+	gowid.SubWidgetCallbacks
+}
+
+var _ gowid.IWidget = (*Widget)(nil)
+var _ gowid.ISettableComposite = (*Widget)(nil)
+var _ gowid.IAccessibleChildren = (*Widget)(nil)
+
+func New(base gowid.IWidget) *Widget {
+	res := &Widget{
+		base: base,
+	}
+	res.SubWidgetCallbacks = gowid.SubWidgetCallbacks{CB: &res.Callbacks}
+	return res
+}
+
+// Register returns a new stack, registered with the app like a menu, so that its layers are drawn over
+// the app's view.
+func Register(app gowid.IApp) *Widget {
+	res := New(nil)
+	app.RegisterMenu(res)
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("layers[%d]", len(w.layers))
+}
+
+func (w *Widget) SubWidget() gowid.IWidget {
+	return w.base
+}
+
+func (w *Widget) SetSubWidget(base gowid.IWidget, app gowid.IApp) {
+	w.base = base
+	gowid.RunWidgetCallbacks(w.Callbacks, gowid.SubWidgetCB{}, app, w)
+}
+
+// Add places a widget in a new layer, above the layers with the same or a lower Z, and returns the layer.
+func (w *Widget) Add(lw gowid.IWidget, app gowid.IApp, opts ...Options) *Layer {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	w.seq++
+	res := &Layer{
+		w:     lw,
+		opts:  opt.withDefaults(),
+		seq:   w.seq,
+		stack: w,
+	}
+	w.layers = append(w.layers, res)
+	w.sort()
+	return res
+}
+
+// Remove takes a layer out of the stack. It returns false if the layer isn't in it.
+func (w *Widget) Remove(l *Layer, app gowid.IApp) bool {
+	for i, l2 := range w.layers {
+		if l2 == l {
+			w.layers = append(w.layers[:i], w.layers[i+1:]...)
+			l.stack = nil
+			return true
+		}
+	}
+	return false
+}
+
+// Layers returns the layers in the stack, bottom first.
+func (w *Widget) Layers() []*Layer {
+	res := make([]*Layer, len(w.layers))
+	copy(res, w.layers)
+	return res
+}
+
+// Find returns the layer displaying the widget, if there is one.
+func (w *Widget) Find(lw gowid.IWidget) (*Layer, bool) {
+	for _, l := range w.layers {
+		if l.w == lw {
+			return l, true
+		}
+	}
+	return nil, false
+}
+
+// FocusLayer returns the top layer that can take input, which has the focus, or nil if there is none and
+// the focus is with the base widget.
+func (w *Widget) FocusLayer() *Layer {
+	for i := len(w.layers) - 1; i >= 0; i-- {
+		if w.layers[i].opts.Input != PassThrough {
+			return w.layers[i]
+		}
+	}
+	return nil
+}
+
+func (w *Widget) setZ(l *Layer, z int) {
+	w.seq++
+	l.opts.Z = z
+	l.seq = w.seq
+	w.sort()
+}
+
+func (w *Widget) sort() {
+	sort.SliceStable(w.layers, func(i, j int) bool {
+		if w.layers[i].opts.Z != w.layers[j].opts.Z {
+			return w.layers[i].opts.Z < w.layers[j].opts.Z
+		}
+		return w.layers[i].seq < w.layers[j].seq
+	})
+}
+
+// AccessibleChildren returns the base and the widgets in the layers, bottom first, so that the layers can
+// be found in the app's accessibility tree.
+func (w *Widget) AccessibleChildren(app gowid.IApp) ([]gowid.IWidget, int) {
+	res := make([]gowid.IWidget, 0, len(w.layers)+1)
+	focus := -1
+	if w.base != nil {
+		res = append(res, w.base)
+		focus = 0
+	}
+	fl := w.FocusLayer()
+	for _, l := range w.layers {
+		if l == fl {
+			focus = len(res)
+		}
+		res = append(res, l.w)
+	}
+	return res, focus
+}
+
+func (w *Widget) Selectable() bool {
+	if w.base != nil && w.base.Selectable() {
+		return true
+	}
+	for _, l := range w.layers {
+		if l.opts.Input != PassThrough && l.w.Selectable() {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	return gowid.RenderSize(w.base, size, gowid.NotSelected, app)
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	fl := w.FocusLayer()
+	res := gowid.Render(w.base, size, focus.And(fl == nil), app).Duplicate()
+	for _, l := range w.layers {
+		if l.opts.Dim > 0 {
+			gowid.ShadeCanvas(res, gowid.MakeTCellColorExt(tcell.ColorBlack), l.opts.Dim)
+		}
+		p := padding.New(l.w, l.opts.VAlign, l.opts.Height, l.opts.HAlign, l.opts.Width)
+		c := gowid.Render(p, size, focus.And(l == fl), app)
+		if l.opts.Opacity > 0 && l.opts.Opacity < 1 {
+			gowid.SetCanvasOpacity(c, l.opts.Opacity)
+		}
+		res.MergeUnder(c, 0, 0, l != fl)
+	}
+	return res
+}
+
+// UserInput offers the event to the layers from the top down, as their input policies allow, and then
+// to the base widget.
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	_, isMouse := ev.(*tcell.EventMouse)
+	fl := w.FocusLayer()
+	for i := len(w.layers) - 1; i >= 0; i-- {
+		l := w.layers[i]
+		if l.opts.Input == PassThrough {
+			continue
+		}
+		occluded := false
+		checker := overlay.NewMouseChecker(l.w, func() {
+			occluded = true
+		})
+		p := padding.New(checker, l.opts.VAlign, l.opts.Height, l.opts.HAlign, l.opts.Width)
+		if gowid.UserInputIfSelectable(p, ev, size, focus.And(l == fl), app) {
+			return true
+		}
+		if l.opts.Input == Modal || (isMouse && occluded) {
+			return false
+		}
+	}
+	if w.base == nil {
+		return false
+	}
+	return gowid.UserInputIfSelectable(w.base, ev, size, focus.And(fl == nil), app)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,115 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package layers
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func clickable(label string, clicks *[]string) *button.Widget {
+	b := button.NewBare(text.New(label))
+	b.OnClick(gowid.MakeWidgetCallback("test", func(app gowid.IApp, w gowid.IWidget) {
+		*clicks = append(*clicks, label)
+	}))
+	return b
+}
+
+func TestLayers1(t *testing.T) {
+	clicks := []string{}
+	base := pile.NewFlow(
+		clickable("base0.....", &clicks),
+		clickable("base1.....", &clicks),
+		clickable("base2.....", &clicks),
+	)
+	stack := New(base)
+	sim := gwtest.NewSimT(t, stack, gwtest.SimOptions{Cols: 10, Rows: 3})
+	defer sim.Close()
+
+	top := gowid.VAlignTop{}
+	left := gowid.HAlignLeft{}
+	menu := stack.Add(clickable("menu", &clicks), sim, Options{Z: 1, VAlign: top, HAlign: left})
+	tip := stack.Add(text.New("tip"), sim, Options{Z: 2, VAlign: top, HAlign: left, Input: PassThrough})
+	sim.Redraw()
+	sim.Frame()
+
+	// The tooltip is drawn over the menu, but takes no input
+	sim.AssertLine(t, 0, "tipu0.....")
+	assert.Equal(t, menu, stack.FocusLayer())
+	sim.Key(tcell.KeyEnter)
+	assert.Equal(t, []string{"menu"}, clicks)
+
+	// Mouse events over a layer go to it, and not to the base; others go to the base
+	sim.Click(2, 0, tcell.Button1)
+	assert.Equal(t, []string{"menu", "menu"}, clicks)
+	sim.Click(5, 1, tcell.Button1)
+	assert.Equal(t, []string{"menu", "menu", "base1....."}, clicks)
+
+	// Order by z, and within a z, by when raised or lowered
+	tip.SetZ(0, sim)
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 0, "menu0.....")
+	other := stack.Add(text.New("xx"), sim, Options{Z: 1, VAlign: top, HAlign: left, Input: PassThrough})
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 0, "xxnu0.....")
+	other.Lower(sim)
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 0, "menu0.....")
+	assert.Equal(t, []*Layer{tip, other, menu}, stack.Layers())
+
+	// A modal layer gets everything
+	e := edit.New()
+	modal := stack.Add(e, sim, Options{Z: 5, VAlign: gowid.VAlignBottom{}, Width: gowid.RenderWithUnits{U: 4}, Input: Modal})
+	sim.Redraw()
+	sim.Frame()
+	assert.Equal(t, modal, stack.FocusLayer())
+	sim.Click(5, 1, tcell.Button1)
+	sim.Rune('a')
+	assert.Equal(t, "a", e.Text())
+	assert.Equal(t, []string{"menu", "menu", "base1....."}, clicks)
+
+	assert.True(t, modal.Remove(sim))
+	assert.False(t, modal.Remove(sim))
+	assert.True(t, stack.Remove(menu, sim))
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 0, "xxpe0.....")
+	assert.Nil(t, stack.FocusLayer())
+	sim.Click(5, 0, tcell.Button1)
+	assert.Equal(t, []string{"menu", "menu", "base1.....", "base0....."}, clicks)
+}
+
+func TestRegister1(t *testing.T) {
+	sim := gwtest.NewSimT(t, text.New("view"), gwtest.SimOptions{Cols: 6, Rows: 1})
+	defer sim.Close()
+
+	stack := Register(sim)
+	stack.Add(text.New("!"), sim, Options{HAlign: gowid.HAlignRight{}})
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 0, "view !")
+
+	n, ok := gowid.AccessibilityTree(stack, sim).Find(gowid.RoleText, "!")
+	assert.True(t, ok)
+	assert.True(t, n.Focused)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
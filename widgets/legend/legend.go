@@ -0,0 +1,316 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package legend provides a legend for charts whose entries the user can click, or select, to hide or
+// show each series, or to highlight one. Charts read the legend's state as they render, so several charts
+// can share one legend.
+package legend
+
+import (
+	"fmt"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/fill"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/styled"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// DimColor is used for the series that aren't highlighted, while one is.
+var DimColor gowid.IColor = gowid.ColorDarkGray
+
+// Entry describes one series.
+type Entry struct {
+	Name  string
+	Color gowid.IColor
+}
+
+// ISeries is the state of a legend's series, consulted by charts as they render.
+type ISeries interface {
+	SeriesCount() int
+	SeriesColor(i int) gowid.IColor // The color to draw series i with, dimmed if another is highlighted
+	SeriesHidden(i int) bool
+	Highlighted() int // The highlighted series, or -1
+}
+
+// SeriesColor returns the color for series i from s, or def if s doesn't describe series i.
+func SeriesColor(s ISeries, i int, def gowid.IColor) gowid.IColor {
+	if s == nil || i < 0 || i >= s.SeriesCount() {
+		return def
+	}
+	return s.SeriesColor(i)
+}
+
+// SeriesHidden returns true if s hides series i.
+func SeriesHidden(s ISeries, i int) bool {
+	return s != nil && i >= 0 && i < s.SeriesCount() && s.SeriesHidden(i)
+}
+
+type Change struct{}
+
+type IWidget interface {
+	gowid.ICompositeWidget
+	ISeries
+	Entries() []Entry
+}
+
+// Options is used to configure the legend.
+type Options struct {
+	Vertical     bool       // If true, entries are stacked; otherwise they're in a row
+	HighlightKey gowid.IKey // Highlights the focused entry, or clears the highlight; defaults to 'h'
+}
+
+// Widget displays an entry for each series, as a colored swatch and the series' name. Clicking an entry,
+// or pressing enter or space on it, hides or shows its series; pressing the highlight key highlights it.
+type Widget struct {
+	gowid.IWidget
+	entries     []Entry
+	hidden      []bool
+	highlighted int
+	labels      []*text.Widget
+	ascii       bool // True if the labels were made to be drawn with ASCII only
+	opts        Options
+	Callbacks   *gowid.Callbacks
+}
+
+func New(entries []Entry, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.HighlightKey == nil {
+		opt.HighlightKey = gowid.MakeKey('h')
+	}
+	res := &Widget{
+		entries:     entries,
+		hidden:      make([]bool, len(entries)),
+		highlighted: -1,
+		opts:        opt,
+		Callbacks:   gowid.NewCallbacks(),
+	}
+	res.IWidget = res.build()
+	var _ IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("legend[%d]", len(w.entries))
+}
+
+func (w *Widget) SubWidget() gowid.IWidget {
+	return w.IWidget
+}
+
+func (w *Widget) SetSubWidget(wi gowid.IWidget, app gowid.IApp) {
+	w.IWidget = wi
+}
+
+func (w *Widget) SubWidgetSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	return size
+}
+
+func (w *Widget) Entries() []Entry {
+	res := make([]Entry, len(w.entries))
+	copy(res, w.entries)
+	return res
+}
+
+func (w *Widget) SeriesCount() int {
+	return len(w.entries)
+}
+
+func (w *Widget) SeriesColor(i int) gowid.IColor {
+	if w.highlighted != -1 && w.highlighted != i {
+		return DimColor
+	}
+	return w.entries[i].Color
+}
+
+func (w *Widget) SeriesHidden(i int) bool {
+	return w.hidden[i]
+}
+
+func (w *Widget) Highlighted() int {
+	return w.highlighted
+}
+
+// SetHidden hides or shows series i.
+func (w *Widget) SetHidden(i int, hidden bool, app gowid.IApp) {
+	if w.hidden[i] == hidden {
+		return
+	}
+	w.hidden[i] = hidden
+	w.changed(app)
+}
+
+func (w *Widget) ToggleHidden(i int, app gowid.IApp) {
+	w.SetHidden(i, !w.hidden[i], app)
+}
+
+// SetHighlighted highlights series i, dimming the others. -1 clears the highlight.
+func (w *Widget) SetHighlighted(i int, app gowid.IApp) {
+	if w.highlighted == i {
+		return
+	}
+	w.highlighted = i
+	w.changed(app)
+}
+
+func (w *Widget) OnChange(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w.Callbacks, Change{}, f)
+}
+
+func (w *Widget) RemoveOnChange(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w.Callbacks, Change{}, f)
+}
+
+func (w *Widget) changed(app gowid.IApp) {
+	w.relabel(app)
+	gowid.RunWidgetCallbacks(w.Callbacks, Change{}, app, w)
+}
+
+func (w *Widget) relabel(app gowid.IApp) {
+	w.ascii = gowid.ASCIIOnly(app)
+	for i, label := range w.labels {
+		label.SetContent(app, w.content(i, app))
+	}
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	// The labels are made before the app is known
+	if gowid.ASCIIOnly(app) != w.ascii {
+		w.relabel(app)
+	}
+	return w.IWidget.Render(size, focus, app)
+}
+
+// content returns the swatch and name of entry i, styled for its current state.
+func (w *Widget) content(i int, app gowid.IApp) *text.Content {
+	swatch := gowid.ASCIIString(app, "■", "#")
+	if w.hidden[i] {
+		swatch = gowid.ASCIIString(app, "□", "o")
+	}
+	name := gowid.ICellStyler(gowid.MakeForeground(gowid.ColorNone))
+	if w.hidden[i] {
+		name = gowid.MakeForeground(DimColor)
+	} else if w.highlighted == i {
+		name = gowid.MakeStyledAs(gowid.StyleBold)
+	}
+	return text.NewContent([]text.ContentSegment{
+		text.StyledContent(swatch, gowid.MakeForeground(w.entries[i].Color)),
+		text.StyledContent(" "+w.entries[i].Name, name),
+	})
+}
+
+// build makes a widget for each entry.
+func (w *Widget) build() gowid.IWidget {
+	ws := make([]gowid.IContainerWidget, 0, len(w.entries)*2)
+	w.labels = make([]*text.Widget, len(w.entries))
+	w.ascii = gowid.ASCIIOnly(nil)
+	for i := range w.entries {
+		i := i
+		w.labels[i] = text.NewFromContent(w.content(i, nil))
+		btn := button.NewBare(w.labels[i])
+		btn.OnClick(gowid.MakeWidgetCallback("legend", func(app gowid.IApp, _ gowid.IWidget) {
+			w.ToggleHidden(i, app)
+		}))
+		entry := styled.NewFocus(&entryWidget{Widget: btn, legend: w, index: i}, gowid.MakeStyledAs(gowid.StyleReverse))
+		if w.opts.Vertical {
+			ws = append(ws, &gowid.ContainerWidget{IWidget: entry, D: gowid.RenderFlow{}})
+			continue
+		}
+		if i > 0 {
+			ws = append(ws, &gowid.ContainerWidget{IWidget: fill.New(' '), D: gowid.RenderWithUnits{U: 2}})
+		}
+		ws = append(ws, &gowid.ContainerWidget{IWidget: entry, D: gowid.RenderFixed{}})
+	}
+	if w.opts.Vertical {
+		return pile.New(ws)
+	}
+	return columns.New(ws)
+}
+
+//======================================================================
+
+// entryWidget adds the highlight key to an entry's button.
+type entryWidget struct {
+	*button.Widget
+	legend *Widget
+	index  int
+}
+
+func (w *entryWidget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if kev, ok := ev.(*tcell.EventKey); ok && gowid.KeysEqual(kev, w.legend.opts.HighlightKey) {
+		if w.legend.highlighted == w.index {
+			w.legend.SetHighlighted(-1, app)
+		} else {
+			w.legend.SetHighlighted(w.index, app)
+		}
+		return true
+	}
+	return w.Widget.UserInput(ev, size, focus, app)
+}
+
+//======================================================================
+
+// Series wraps a chart that draws one series, e.g. one of a group of asciigraph plots, so that the legend
+// controls it. While its series is hidden, a blank space is drawn instead; while another series is
+// highlighted, the chart is drawn in DimColor.
+type Series struct {
+	gowid.IWidget
+	series ISeries
+	index  int
+}
+
+var _ gowid.ICompositeWidget = (*Series)(nil)
+
+func NewSeries(chart gowid.IWidget, series ISeries, index int) *Series {
+	return &Series{
+		IWidget: chart,
+		series:  series,
+		index:   index,
+	}
+}
+
+func (w *Series) String() string {
+	return fmt.Sprintf("series[%d,%v]", w.index, w.IWidget)
+}
+
+func (w *Series) SubWidget() gowid.IWidget {
+	return w.IWidget
+}
+
+func (w *Series) SetSubWidget(wi gowid.IWidget, app gowid.IApp) {
+	w.IWidget = wi
+}
+
+func (w *Series) SubWidgetSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	return size
+}
+
+func (w *Series) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	if SeriesHidden(w.series, w.index) {
+		box := w.IWidget.RenderSize(size, focus, app)
+		return fill.New(' ').Render(gowid.RenderBox{C: box.BoxColumns(), R: box.BoxRows()}, focus, app)
+	}
+	res := w.IWidget.Render(size, focus, app)
+	if hl := w.series.Highlighted(); hl != -1 && hl != w.index {
+		dim := gowid.IColorToTCell(DimColor, gowid.ColorNone, app.GetColorMode())
+		gowid.RangeOverCanvas(res, gowid.CellRangeFunc(func(c gowid.Cell) gowid.Cell {
+			return c.WithForegroundColor(dim)
+		}))
+	}
+	return res
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,83 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package legend
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestLegend1(t *testing.T) {
+	l := New([]Entry{{"cpu", gowid.ColorRed}, {"mem", gowid.ColorBlue}})
+	c := l.Render(gowid.RenderFlowWith{C: 14}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "■ cpu  ■ mem  ", c.String())
+	assert.Equal(t, 2, l.SeriesCount())
+	assert.Equal(t, gowid.ColorBlue, l.SeriesColor(1))
+
+	changes := 0
+	l.OnChange(gowid.MakeWidgetCallback("test", func(app gowid.IApp, w gowid.IWidget) {
+		changes++
+	}))
+
+	chart := NewSeries(text.New("xx"), l, 1)
+	sim := gwtest.NewSimT(t, pile.NewFlow(l, chart), gwtest.SimOptions{Cols: 14, Rows: 2})
+	defer sim.Close()
+	sim.AssertLine(t, 1, "xx            ")
+
+	// Hide the second series
+	sim.Key(tcell.KeyRight)
+	sim.Key(tcell.KeyEnter)
+	assert.True(t, l.SeriesHidden(1))
+	assert.Equal(t, 1, changes)
+	sim.AssertLine(t, 0, "■ cpu  □ mem  ")
+	sim.AssertLine(t, 1, "              ")
+
+	// Clicking shows it again
+	sim.Click(8, 0, tcell.Button1)
+	assert.False(t, l.SeriesHidden(1))
+	assert.Equal(t, 2, changes)
+	sim.AssertLine(t, 1, "xx            ")
+
+	// Highlighting one series dims the others
+	sim.Click(2, 0, tcell.Button1)
+	assert.True(t, l.SeriesHidden(0))
+	sim.Rune('h')
+	assert.Equal(t, 0, l.Highlighted())
+	assert.Equal(t, DimColor, l.SeriesColor(1))
+	assert.Equal(t, gowid.ColorRed, l.SeriesColor(0))
+	_, st := sim.Cell(0, 1)
+	fg, _, _ := st.Decompose()
+	assert.Equal(t, tcell.ColorDarkGray, fg)
+	sim.Rune('h')
+	assert.Equal(t, -1, l.Highlighted())
+	assert.Equal(t, gowid.ColorBlue, l.SeriesColor(1))
+	assert.Equal(t, 5, changes)
+}
+
+func TestLegend2(t *testing.T) {
+	l := New([]Entry{{"a", gowid.ColorRed}, {"b", gowid.ColorBlue}}, Options{Vertical: true, HighlightKey: gowid.MakeKey('!')})
+	c := l.Render(gowid.RenderFlowWith{C: 4}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "■ a \n■ b ", c.String())
+
+	assert.Equal(t, gowid.ColorGreen, SeriesColor(l, 2, gowid.ColorGreen))
+	assert.Equal(t, gowid.ColorGreen, SeriesColor(nil, 0, gowid.ColorGreen))
+	assert.False(t, SeriesHidden(nil, 0))
+	l.SetHidden(1, true, gwtest.D)
+	assert.True(t, SeriesHidden(l, 1))
+	assert.False(t, SeriesHidden(l, 3))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,55 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package list
+
+import (
+	"github.com/gcla/gowid"
+)
+
+//======================================================================
+
+// MaxAccessibleItems is the most items of a list reported by AccessibleChildren, so that a walker with no
+// end - e.g. one generating items on demand - can still be described.
+var MaxAccessibleItems = 1000
+
+var _ gowid.IAccessible = (*Widget)(nil)
+var _ gowid.IAccessibleChildren = (*Widget)(nil)
+
+func (w *Widget) AccessibleRole() gowid.Role {
+	return gowid.RoleList
+}
+
+func (w *Widget) AccessibleLabel(app gowid.IApp) string {
+	return ""
+}
+
+// AccessibleChildren returns the items of the list, from the first if the walker has a first position,
+// otherwise from the one in focus, up to MaxAccessibleItems.
+func (w *Widget) AccessibleChildren(app gowid.IApp) ([]gowid.IWidget, int) {
+	focus := w.Walker().Focus()
+	pos := focus
+	if homer, ok := w.Walker().(IWalkerHome); ok {
+		pos = homer.First()
+	}
+	res := []gowid.IWidget{}
+	resFocus := -1
+	for pos != nil && len(res) < MaxAccessibleItems {
+		cur := w.Walker().At(pos)
+		if cur == nil {
+			break
+		}
+		if focus != nil && pos.Equal(focus) {
+			resFocus = len(res)
+		}
+		res = append(res, cur)
+		pos = w.Walker().Next(pos)
+	}
+	return res, resFocus
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,102 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package list
+
+import (
+	"reflect"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+)
+
+//======================================================================
+
+// IWalkerIDs is implemented by a walker whose items have IDs that stay with them when the walker's data is
+// reloaded - e.g. the keys of database rows - so that a list can keep its view across a reload. See
+// Widget.Anchor. A walker that doesn't implement it identifies each item by its widget, which suits a
+// reload that keeps the widgets of the items that remain, e.g. by appending new ones.
+type IWalkerIDs interface {
+	PositionID(pos IWalkerPosition) (interface{}, bool)
+	PositionForID(id interface{}) (IWalkerPosition, bool)
+}
+
+// PositionID returns the ID of the item of walker at pos.
+func PositionID(walker IWalker, pos IWalkerPosition) (interface{}, bool) {
+	if ids, ok := walker.(IWalkerIDs); ok {
+		return ids.PositionID(pos)
+	}
+	w := walker.At(pos)
+	if w == nil || !reflect.TypeOf(w).Comparable() {
+		return nil, false
+	}
+	return w, true
+}
+
+// PositionForID returns the position of the item of walker with the ID given. Without IWalkerIDs, only a
+// bounded walker with a first position can be searched.
+func PositionForID(walker IWalker, id interface{}) (IWalkerPosition, bool) {
+	if ids, ok := walker.(IWalkerIDs); ok {
+		return ids.PositionForID(id)
+	}
+	bounded, ok := walker.(IBoundedWalker)
+	if !ok {
+		return nil, false
+	}
+	home, ok := walker.(IWalkerHome)
+	if !ok {
+		return nil, false
+	}
+	pos := home.First()
+	for i := 0; pos != nil && i < bounded.Length(); i++ {
+		if w := walker.At(pos); w != nil && reflect.TypeOf(w).Comparable() && w == id {
+			return pos, true
+		}
+		pos = walker.Next(pos)
+	}
+	return nil, false
+}
+
+// Anchor returns the item in focus, by its ID - see IWalkerIDs - and the row of the view at which it
+// starts, as the list was last rendered.
+func (w *Widget) Anchor(app gowid.IApp) (gowid.ViewAnchor, bool) {
+	if w.lastSize == nil {
+		return gowid.ViewAnchor{}, false
+	}
+	id, ok := PositionID(w.Walker(), w.Walker().Focus())
+	if !ok {
+		return gowid.ViewAnchor{}, false
+	}
+	top, _, _ := w.RenderSubwidgets(w.lastSize, gowid.Focused, app)
+	row := -w.st.linesOffTop
+	for _, sr := range top {
+		row += sr.Canvas.BoxRows()
+	}
+	return gowid.ViewAnchor{ID: id, Row: row}, true
+}
+
+// RestoreAnchor focuses the item with the anchor's ID, and puts it at the anchor's row of the view.
+func (w *Widget) RestoreAnchor(a gowid.ViewAnchor, app gowid.IApp) bool {
+	pos, ok := PositionForID(w.Walker(), a.ID)
+	if !ok {
+		return false
+	}
+	changed := !pos.Equal(w.Walker().Focus())
+	w.Walker().SetFocus(pos, app)
+	w.st.linesOffTop = gwutil.Max(0, -a.Row)
+	w.st.topToBottomRatioValid = true
+	w.st.topToBottomRatio = 0
+	if rows, ok := w.lastSize.(gowid.IRows); ok && rows.Rows() > 0 {
+		w.st.topToBottomRatio = float32(gwutil.Max(0, a.Row)) / float32(rows.Rows())
+	}
+	if changed {
+		gowid.RunWidgetCallbacks(w, gowid.FocusCB{}, app, w.Walker().At(pos))
+	}
+	return true
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
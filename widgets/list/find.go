@@ -0,0 +1,84 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package list
+
+import (
+	"github.com/gcla/gowid"
+)
+
+//======================================================================
+
+var _ gowid.IFindable = (*Widget)(nil)
+
+// FindLimit is the most widgets FindNext renders in one call. A search that reaches it stops, and the
+// next call carries on from there, so that a long list doesn't hold up the UI.
+var FindLimit = 1000
+
+// findState records where a search that reached FindLimit stopped.
+type findState struct {
+	spec    gowid.FindSpec
+	forward bool
+	from    IWalkerPosition // The position the search started from
+	resume  IWalkerPosition // The position the search stopped at
+}
+
+// FindNext looks for the first selectable widget below (or above, if forward is false) those displayed
+// when the list was last rendered that matches spec when rendered. If one is found, it is given focus and
+// scrolled to the top (or bottom) of the list, so that the app's find mode can continue from there. At
+// most FindLimit widgets are looked at; if none of those match, false is returned and the next call for
+// the same spec and direction, while the list hasn't moved, continues the search where this one stopped.
+func (w *Widget) FindNext(spec gowid.FindSpec, forward bool, app gowid.IApp) bool {
+	if w.lastSize == nil {
+		return false
+	}
+
+	top, middle, bottom := w.RenderSubwidgets(w.lastSize, gowid.Focused, app)
+	pos := middle.Position
+	if forward && len(bottom) > 0 {
+		pos = bottom[len(bottom)-1].Position
+	} else if !forward && len(top) > 0 {
+		pos = top[len(top)-1].Position
+	}
+
+	from := pos
+	if f := w.find; f != nil && f.spec == spec && f.forward == forward && f.from.Equal(from) {
+		pos = f.resume
+	}
+	w.find = nil
+
+	subSize := w.SubWidgetSize(w.lastSize, gowid.Focused, app)
+	for n := 0; ; n++ {
+		if n == FindLimit {
+			w.find = &findState{spec: spec, forward: forward, from: from, resume: pos}
+			return false
+		}
+		if forward {
+			pos = w.Walker().Next(pos)
+		} else {
+			pos = w.Walker().Previous(pos)
+		}
+		cur := w.Walker().At(pos)
+		if cur == nil {
+			return false
+		}
+		if !cur.Selectable() || !spec.MatchesCanvas(cur.Render(subSize, gowid.NotSelected, app)) {
+			continue
+		}
+
+		w.Walker().SetFocus(pos, app)
+		if forward {
+			w.GoToTop(app)
+		} else {
+			w.GoToBottom(app)
+		}
+		gowid.RunWidgetCallbacks(w, gowid.FocusCB{}, app, cur)
+		return true
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
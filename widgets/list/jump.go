@@ -0,0 +1,190 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package list
+
+import (
+	"strings"
+
+	"github.com/gcla/gowid"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// DefaultJumpLabels are the characters used to label rows in jump mode, home row first.
+const DefaultJumpLabels = "asdfghjklqwertyuiopzxcvbnm"
+
+// IJump is implemented by widgets that support an avy-style jump mode, in which each
+// visible selectable row is overlaid with a short label; typing a label moves focus
+// directly to that row.
+type IJump interface {
+	StartJump(app gowid.IApp)
+	CancelJump(app gowid.IApp)
+	Jumping() bool
+}
+
+var _ IJump = (*Widget)(nil)
+
+type jumpState struct {
+	active bool
+	typed  string
+}
+
+// StartJump puts the list into jump mode. It is left when a complete label is typed, or
+// when any input that can't begin a label is received.
+func (w *Widget) StartJump(app gowid.IApp) {
+	w.jump = jumpState{active: true}
+}
+
+// CancelJump leaves jump mode without moving focus.
+func (w *Widget) CancelJump(app gowid.IApp) {
+	w.jump = jumpState{}
+}
+
+// Jumping returns true if the list is in jump mode.
+func (w *Widget) Jumping() bool {
+	return w.jump.active
+}
+
+// JumpLabels returns n distinct labels built from chars. If there are enough chars, each
+// label is a single character; otherwise every label has two, so that no label is the
+// prefix of another. If n is more than can be built from two characters, fewer labels
+// are returned.
+func JumpLabels(n int, chars string) []string {
+	runes := []rune(chars)
+	k := len(runes)
+	res := make([]string, 0, n)
+	if n <= k {
+		for i := 0; i < n; i++ {
+			res = append(res, string(runes[i]))
+		}
+		return res
+	}
+	for i := 0; i < n && i < k*k; i++ {
+		res = append(res, string([]rune{runes[i/k], runes[i%k]}))
+	}
+	return res
+}
+
+// jumpTargets returns the indices, into the screen-ordered list of subrenders, of the
+// rows that can be jumped to.
+func jumpTargets(all []SubRenders) []int {
+	res := make([]int, 0, len(all))
+	for i, r := range all {
+		if r.Widget.Selectable() {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+func (w *Widget) renderJump(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	top, middle, bottom := w.RenderSubwidgets(size, focus, app)
+	res := stackSubRenders(top, middle, bottom, size)
+
+	all := make([]SubRenders, 0, len(top)+len(bottom)+1)
+	for i := len(top); i > 0; i-- {
+		all = append(all, top[i-1])
+	}
+	all = append(all, middle)
+	all = append(all, bottom...)
+
+	f, b, s := w.options.JumpStyle.GetStyle(app)
+	style := gowid.MakeCell(' ',
+		gowid.IColorToTCell(f, gowid.ColorNone, app.GetColorMode()),
+		gowid.IColorToTCell(b, gowid.ColorNone, app.GetColorMode()),
+		s,
+	)
+
+	ys := make([]int, len(all))
+	y := 0
+	for i, r := range all {
+		ys[i] = y
+		y += r.Canvas.BoxRows()
+	}
+
+	targets := jumpTargets(all)
+	for i, label := range JumpLabels(len(targets), w.options.JumpLabels) {
+		if !strings.HasPrefix(label, w.jump.typed) {
+			continue
+		}
+		row := ys[targets[i]]
+		if all[targets[i]].Canvas.BoxRows() == 0 || row >= res.BoxRows() {
+			continue
+		}
+		for x, r := range []rune(label[len(w.jump.typed):]) {
+			if x < res.BoxColumns() {
+				res.SetCellAt(x, row, style.WithRune(r))
+			}
+		}
+	}
+
+	return res
+}
+
+// jumpInput handles a keypress in jump mode. all is the list of subrenders in screen order,
+// and screenLines the number of lines the list occupies.
+func (w *Widget) jumpInput(ev *tcell.EventKey, all []SubRenders, screenLines int, app gowid.IApp) {
+	if ev.Key() != tcell.KeyRune {
+		w.CancelJump(app)
+		return
+	}
+
+	typed := w.jump.typed + string(ev.Rune())
+	targets := jumpTargets(all)
+	var target int
+	found, prefix := false, false
+	for i, label := range JumpLabels(len(targets), w.options.JumpLabels) {
+		if label == typed {
+			target, found = targets[i], true
+			break
+		} else if strings.HasPrefix(label, typed) {
+			prefix = true
+		}
+	}
+
+	switch {
+	case found:
+		w.CancelJump(app)
+		w.jumpTo(all, target, screenLines, app)
+	case prefix:
+		w.jump.typed = typed
+	default:
+		w.CancelJump(app)
+	}
+}
+
+// jumpTo focuses the subrender at index i, leaving it at the same place on the screen.
+func (w *Widget) jumpTo(all []SubRenders, i int, screenLines int, app gowid.IApp) {
+	oldpos := w.Walker().Focus()
+	pos := all[i].Position
+	if pos.Equal(oldpos) {
+		return
+	}
+
+	prefCol := calcPrefPosition(w.Walker().At(oldpos))
+
+	linesAbove := 0
+	for j := 0; j < i; j++ {
+		linesAbove += all[j].Canvas.BoxRows()
+	}
+
+	w.Walker().SetFocus(pos, app)
+	w.st.linesOffTop = 0
+	if screenLines > 0 {
+		w.st.topToBottomRatioValid = true
+		w.st.topToBottomRatio = float32(linesAbove) / float32(screenLines)
+	}
+
+	if !prefCol.IsNone() {
+		setPrefPosition(app, w.Walker().At(pos), prefCol.Val())
+	}
+	gowid.RunWidgetCallbacks(w, gowid.FocusCB{}, app, w.Walker().At(pos))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -204,7 +204,11 @@ type Widget struct {
 	// This says how many lines to cut from the top of the widget rendered at the top of the listbox.
 	// It might be too big to be rendered fully in the space.
 	st      state
+	jump    jumpState
 	options Options
+	// The size of the last render, so that content beyond what is displayed can be searched
+	lastSize gowid.IRenderSize
+	find     *findState // Where a search that stopped early got to
 	gowid.AddressProvidesID
 	*gowid.Callbacks
 	gowid.FocusCallbacks
@@ -215,6 +219,10 @@ type Options struct {
 	//SelectedStyle gowid.ICellStyler // apply a style to the selected widget - orthogonal to focus styling
 	DownKeys []vim.KeyPress
 	UpKeys   []vim.KeyPress
+	// JumpKeys start jump mode - see StartJump. No keys are bound by default.
+	JumpKeys   []vim.KeyPress
+	JumpLabels string            // Characters used to build jump labels; defaults to DefaultJumpLabels
+	JumpStyle  gowid.ICellStyler // Style for jump labels; defaults to reverse video
 }
 
 type IndexedWidget struct {
@@ -239,6 +247,12 @@ func New(walker IWalker, opts ...Options) *Widget {
 	if opt.UpKeys == nil {
 		opt.UpKeys = vim.AllUpKeys
 	}
+	if opt.JumpLabels == "" {
+		opt.JumpLabels = DefaultJumpLabels
+	}
+	if opt.JumpStyle == nil {
+		opt.JumpStyle = gowid.MakeStyledAs(gowid.StyleReverse)
+	}
 	res := &Widget{
 		walker:  walker,
 		options: opt,
@@ -247,6 +261,8 @@ func New(walker IWalker, opts ...Options) *Widget {
 	res.goToTop()
 
 	var _ gowid.IWidget = res
+	var _ gowid.IScrollValues = res
+	var _ gowid.IViewAnchor = res
 
 	return res
 }
@@ -281,6 +297,24 @@ func (w *IndexedWidget) SetWalker(l IWalker, app gowid.IApp) {
 	w.Widget.SetWalker(l, app)
 }
 
+// ActivateCB is the callback type for OnActivate.
+type ActivateCB struct{}
+
+// OnActivate registers a callback that is run when the user double-clicks an item in the list. The
+// callback is given the item's widget.
+func (w *Widget) OnActivate(f gowid.IWidgetChangedCallback) {
+	if w.Callbacks == nil {
+		w.Callbacks = gowid.NewCallbacks()
+	}
+	gowid.AddWidgetCallback(w.Callbacks, ActivateCB{}, f)
+}
+
+func (w *Widget) RemoveOnActivate(f gowid.IIdentity) {
+	if w.Callbacks != nil {
+		gowid.RemoveWidgetCallback(w.Callbacks, ActivateCB{}, f)
+	}
+}
+
 func (w *Widget) State() interface{} {
 	return w.st
 }
@@ -325,11 +359,56 @@ func (w *Widget) InMiddle() bool {
 	return w.st.topToBottomRatioValid && gwutil.AlmostEqual(float64(w.st.topToBottomRatio), 0.5)
 }
 
+// ScrollValues returns the items above, in and below the view. Only a list of bounded length, rendered as
+// a box, scrolls - see IBoundedWalker.
+func (w *Widget) ScrollValues(o gowid.Orientation, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) (gowid.ScrollValues, bool) {
+	if _, ok := size.(gowid.IRenderBox); !ok || o != gowid.Vertical {
+		return gowid.ScrollValues{}, false
+	}
+	top, middle, bottom, err := CalculateOnScreen(w, size, focus, app)
+	if err != nil {
+		return gowid.ScrollValues{}, false
+	}
+	return gowid.ScrollValues{Total: top + middle + bottom, Offset: top, Visible: middle}, true
+}
+
+// ScrollTo moves the focus to the item at index offset, and puts it at the top of the view.
+func (w *Widget) ScrollTo(o gowid.Orientation, offset int, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) {
+	if o != gowid.Vertical {
+		return
+	}
+	pos, ok := w.Walker().Focus().(IBoundedWalkerPosition)
+	if !ok {
+		return
+	}
+	var cur IWalkerPosition = pos
+	for i := pos.ToInt(); i < offset; i++ {
+		next := w.Walker().Next(cur)
+		if w.Walker().At(next) == nil {
+			break
+		}
+		cur = next
+	}
+	for i := pos.ToInt(); i > offset; i-- {
+		prev := w.Walker().Previous(cur)
+		if w.Walker().At(prev) == nil {
+			break
+		}
+		cur = prev
+	}
+	w.Walker().SetFocus(cur, app)
+	w.GoToTop(app)
+}
+
 func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
 	return gowid.CalculateRenderSizeFallback(w, size, focus, app)
 }
 
 func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	w.lastSize = size
+	if w.jump.active {
+		return w.renderJump(size, focus, app)
+	}
 	return Render(w, size, focus, app)
 }
 
@@ -385,11 +464,9 @@ func (w *Widget) RenderSubwidgets(size gowid.IRenderSize, focus gowid.Selector,
 		//foobar := styled.New(curWidget, gowid.MakeStyledAs(gowid.StyleReverse))
 		var curToRender gowid.IWidget = curWidget
 		if haveCols {
-			//c = gowid.Render(curWidget, gowid.RenderFlowWith{C: cols.Columns()}, focus, app)
-			c = curToRender.Render(gowid.RenderFlowWith{C: cols.Columns()}, focus, app)
+			c = gowid.Render(curToRender, gowid.RenderFlowWith{C: cols.Columns()}, focus, app)
 		} else {
-			//c = gowid.Render(curWidget, gowid.RenderFixed{}, focus, app)
-			c = curToRender.Render(gowid.RenderFixed{}, focus, app)
+			c = gowid.Render(curToRender, gowid.RenderFixed{}, focus, app)
 		}
 		creallines := c.BoxRows()
 		middle = SubRenders{curWidget, curPos, c, creallines}
@@ -438,9 +515,9 @@ func (w *Widget) RenderSubwidgets(size gowid.IRenderSize, focus gowid.Selector,
 				} else {
 					var upC gowid.ICanvas
 					if haveCols {
-						upC = upWidget.Render(gowid.RenderFlowWith{C: cols.Columns()}, gowid.NotSelected, app)
+						upC = gowid.Render(upWidget, gowid.RenderFlowWith{C: cols.Columns()}, gowid.NotSelected, app)
 					} else {
-						upC = upWidget.Render(gowid.RenderFixed{}, gowid.NotSelected, app)
+						upC = gowid.Render(upWidget, gowid.RenderFixed{}, gowid.NotSelected, app)
 					}
 					upreallines := upC.BoxRows()
 					if haveLinesNeeded {
@@ -465,9 +542,9 @@ func (w *Widget) RenderSubwidgets(size gowid.IRenderSize, focus gowid.Selector,
 				} else {
 					var downC gowid.ICanvas
 					if haveCols {
-						downC = downWidget.Render(gowid.RenderFlowWith{C: cols.Columns()}, gowid.NotSelected, app)
+						downC = gowid.Render(downWidget, gowid.RenderFlowWith{C: cols.Columns()}, gowid.NotSelected, app)
 					} else {
-						downC = downWidget.Render(gowid.RenderFixed{}, gowid.NotSelected, app)
+						downC = gowid.Render(downWidget, gowid.RenderFixed{}, gowid.NotSelected, app)
 					}
 					downreallines := downC.BoxRows()
 					if haveLinesNeeded {
@@ -531,9 +608,14 @@ func CalculateOnScreen(w IListFns, size gowid.IRenderSize, focus gowid.Selector,
 }
 
 func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
-	rows, haveRows := size.(gowid.IRows)
-
 	top, middle, bottom := w.RenderSubwidgets(size, focus, app)
+	return stackSubRenders(top, middle, bottom, size)
+}
+
+// stackSubRenders assembles the canvases returned by RenderSubwidgets into the list's canvas,
+// padded to the number of rows in size, if specified.
+func stackSubRenders(top []SubRenders, middle SubRenders, bottom []SubRenders, size gowid.IRenderSize) gowid.ICanvas {
+	rows, haveRows := size.(gowid.IRows)
 
 	topC := gowid.NewCanvas()
 	bottomC := gowid.NewCanvas()
@@ -549,6 +631,10 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	if haveRows && (topC.BoxRows() < rows.Rows()) {
 		gowid.AppendBlankLines(topC, rows.Rows()-topC.BoxRows())
 	}
+	// An empty list still takes up its width
+	if cols, ok := size.(gowid.IColumns); ok && topC.BoxColumns() < cols.Columns() {
+		topC.ExtendRight(gowid.EmptyLine(cols.Columns() - topC.BoxColumns()))
+	}
 
 	return topC
 }
@@ -672,6 +758,17 @@ func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.S
 	}
 	startPosition := position
 
+	if w.jump.active {
+		if evk, ok := ev.(*tcell.EventKey); ok {
+			initTopMiddleBottom()
+			initListOfSubRenders()
+			calculateScreenLines()
+			w.jumpInput(evk, all, numLinesToUse, app)
+			return true
+		}
+		w.CancelJump(app)
+	}
+
 	dirMoved := 0
 	if evm, ok := ev.(*tcell.EventMouse); ok {
 		initTopMiddleBottom()
@@ -755,6 +852,9 @@ func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.S
 			pgDown = true
 		case k == tcell.KeyPgUp:
 			pgUp = true
+		case vim.KeyIn(evk, w.options.JumpKeys):
+			w.StartJump(app)
+			res = true
 		default:
 		}
 		// But if the input is from the mouse, the list can handle it as well as any subwidget. For example,
@@ -796,6 +896,9 @@ func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.S
 						for {
 							if curPosition.Equal(position) {
 								res = true
+								if cev, ok := gowid.ClickEventFor(ev, app); ok && cev.Count == 2 {
+									gowid.RunWidgetCallbacks(w, ActivateCB{}, app, w.Walker().At(position))
+								}
 								break
 							} else if dirMoved > 0 && curPosition.GreaterThan(position) {
 								res = false
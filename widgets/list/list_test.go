@@ -10,6 +10,7 @@ import (
 
 	"github.com/gcla/gowid"
 	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/vim"
 	"github.com/gcla/gowid/widgets/checkbox"
 	"github.com/gcla/gowid/widgets/disable"
 	"github.com/gcla/gowid/widgets/fixedadapter"
@@ -370,6 +371,125 @@ func TestDisabled1(t *testing.T) {
 	assert.Equal(t, 3, fpos)
 }
 
+func TestJump1(t *testing.T) {
+	ws := make([]gowid.IWidget, 0)
+	for i := 0; i < 6; i++ {
+		ws = append(ws, selectable.New(text.New(fmt.Sprintf("row%d", i))))
+	}
+	lb := New(NewSimpleListWalker(ws), Options{
+		JumpKeys:   []vim.KeyPress{vim.Key('f')},
+		JumpLabels: "as",
+		JumpStyle:  gowid.MakeStyledAs(gowid.StyleNone),
+	})
+	sz := gowid.RenderBox{C: 4, R: 3}
+
+	fpos := -1
+	lb.OnFocusChanged(gowid.WidgetCallback{Name: "cb", WidgetChangedFunction: func(app gowid.IApp, w gowid.IWidget) {
+		fpos = lb.Walker().Focus().(ListPos).ToInt()
+	}})
+
+	lb.UserInput(tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	assert.True(t, lb.Jumping())
+
+	// Three rows visible, two label characters, so labels have two characters
+	c1 := lb.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "aaw0\nasw1\nsaw2", c1.String())
+
+	lb.UserInput(tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	assert.True(t, lb.Jumping())
+	c1 = lb.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "row0\nrow1\naow2", c1.String())
+
+	lb.UserInput(tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	assert.False(t, lb.Jumping())
+	assert.Equal(t, 2, fpos)
+
+	// Focus moved without scrolling
+	c1 = lb.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "row0\nrow1\nrow2", c1.String())
+
+	// A key that matches no label cancels jump mode without moving focus
+	lb.StartJump(gwtest.D)
+	lb.UserInput(tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	assert.False(t, lb.Jumping())
+	assert.Equal(t, 2, fpos)
+
+	lb.StartJump(gwtest.D)
+	lb.UserInput(tcell.NewEventKey(tcell.KeyEscape, ' ', tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	assert.False(t, lb.Jumping())
+}
+
+func TestJumpLabels1(t *testing.T) {
+	assert.Equal(t, []string{"a", "s"}, JumpLabels(2, "asd"))
+	assert.Equal(t, []string{"aa", "as", "sa", "ss"}, JumpLabels(5, "as"))
+}
+
+func TestScrollValues1(t *testing.T) {
+	ws := make([]gowid.IWidget, 0)
+	for _, s := range []string{"a", "b", "c", "d", "e", "f"} {
+		ws = append(ws, selectable.New(text.New(s)))
+	}
+	lb := New(NewSimpleListWalker(ws))
+	sz := gowid.RenderBox{C: 1, R: 3}
+	lb.Render(sz, gowid.Focused, gwtest.D)
+
+	sv, ok := lb.ScrollValues(gowid.Vertical, sz, gowid.Focused, gwtest.D)
+	assert.True(t, ok)
+	assert.Equal(t, gowid.ScrollValues{Total: 6, Offset: 0, Visible: 3}, sv)
+	_, ok = lb.ScrollValues(gowid.Horizontal, sz, gowid.Focused, gwtest.D)
+	assert.False(t, ok)
+
+	lb.ScrollTo(gowid.Vertical, 3, sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "d\ne\nf", lb.Render(sz, gowid.Focused, gwtest.D).String())
+	sv, _ = lb.ScrollValues(gowid.Vertical, sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, 3, sv.Offset)
+}
+
+func TestKeepView1(t *testing.T) {
+	ws := make([]gowid.IWidget, 0)
+	for _, s := range []string{"a", "b", "c", "d"} {
+		ws = append(ws, selectable.New(text.New(s)))
+	}
+	lb := New(NewSimpleListWalker(ws))
+	sz := gowid.RenderBox{C: 1, R: 3}
+	for i := 0; i < 2; i++ {
+		lb.UserInput(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	}
+	assert.Equal(t, "a\nb\nc", lb.Render(sz, gowid.Focused, gwtest.D).String())
+
+	// Items are added above; the widgets of those already listed identify them
+	assert.True(t, gowid.KeepView(lb, gwtest.D, func(app gowid.IApp) {
+		lb.SetWalker(NewSimpleListWalker(append([]gowid.IWidget{selectable.New(text.New("x"))}, ws...)), app)
+	}))
+	assert.Equal(t, "a\nb\nc", lb.Render(sz, gowid.Focused, gwtest.D).String())
+	assert.Equal(t, ListPos(3), lb.Walker().Focus())
+}
+
+func TestFindLimit1(t *testing.T) {
+	ws := make([]gowid.IWidget, 0)
+	for i := 0; i < 20; i++ {
+		ws = append(ws, selectable.New(text.New(fmt.Sprintf("row%02d", i))))
+	}
+	lb := New(NewSimpleListWalker(ws))
+	lb.Render(gowid.RenderBox{C: 5, R: 3}, gowid.Focused, gwtest.D)
+
+	defer func(n int) { FindLimit = n }(FindLimit)
+	FindLimit = 5
+
+	// Each call looks at five more rows, carrying on from the last
+	spec := gowid.FindSpec{Query: "row15"}
+	assert.False(t, lb.FindNext(spec, true, gwtest.D))
+	assert.False(t, lb.FindNext(spec, true, gwtest.D))
+	assert.True(t, lb.FindNext(spec, true, gwtest.D))
+	assert.Equal(t, ListPos(15), lb.Walker().Focus())
+
+	// A different search starts again from what's displayed
+	lb.Render(gowid.RenderBox{C: 5, R: 3}, gowid.Focused, gwtest.D)
+	assert.False(t, lb.FindNext(gowid.FindSpec{Query: "row00"}, false, gwtest.D))
+	assert.False(t, lb.FindNext(gowid.FindSpec{Query: "row02"}, false, gwtest.D))
+	assert.Equal(t, ListPos(15), lb.Walker().Focus())
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
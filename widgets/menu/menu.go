@@ -263,7 +263,7 @@ func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Se
 func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
 	bfocus := focus.And(w.Overlay().BottomGetsFocus())
 
-	bottomC := w.Overlay().Bottom().Render(size, bfocus, app)
+	bottomC := gowid.Render(w.Overlay().Bottom(), size, bfocus, app)
 
 	off, ok := bottomC.GetMark(w.Name())
 	if !ok {
@@ -0,0 +1,772 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package menubar provides a bar of menu titles drawn above a widget, whose menus drop down when chosen,
+// with submenus, keyboard accelerators and mouse support. Menus are drawn as layers - see package layers.
+package menubar
+
+import (
+	"fmt"
+	"unicode"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/layers"
+	"github.com/gcla/gowid/widgets/null"
+	"github.com/gdamore/tcell"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+//======================================================================
+
+// DefaultActivateKey moves the focus to the bar, unless Options says otherwise.
+var DefaultActivateKey gowid.IKey = gowid.MakeKeyExt(tcell.KeyF10)
+
+// Item is an entry in a menu. The character after an "&" in its label is its mnemonic, which chooses it
+// when typed while its menu is open; "&&" is drawn as an ampersand.
+type Item struct {
+	Label string
+	// Shortcut is a key that runs Action while the menus are closed, if the widget the bar is drawn above
+	// doesn't handle it, in the form accepted by gowid.ParseKey - e.g. "Ctrl-S". It is shown beside the
+	// label.
+	Shortcut  string
+	Action    func(app gowid.IApp)
+	Items     []Item // If not empty, a submenu, opened instead of running Action
+	Disabled  bool
+	Separator bool // If true, the item is a line between groups of items, and can't be chosen
+}
+
+// Separator is a line between groups of items in a menu.
+var Separator = Item{Separator: true}
+
+// Menu is a title in the bar, and the items in the menu it opens. Its label can have a mnemonic, like an
+// Item's, which opens the menu when typed with Alt.
+type Menu struct {
+	Label string
+	Items []Item
+}
+
+// Options is used to configure the widget.
+type Options struct {
+	ActivateKey   gowid.IKey        // Moves the focus to the bar; defaults to DefaultActivateKey
+	Style         gowid.ICellStyler // The style of the bar and menus; if nil, they are unstyled
+	FocusStyle    gowid.ICellStyler // The style of the chosen title or item; defaults to reverse video
+	DisabledStyle gowid.ICellStyler // The style of disabled items; defaults to dim
+}
+
+func (o Options) withDefaults() Options {
+	if o.ActivateKey == nil {
+		o.ActivateKey = DefaultActivateKey
+	}
+	if o.FocusStyle == nil {
+		o.FocusStyle = gowid.MakeStyledAs(gowid.StyleReverse)
+	}
+	if o.DisabledStyle == nil {
+		o.DisabledStyle = gowid.MakeStyledAs(gowid.StyleDim)
+	}
+	return o
+}
+
+// level is a menu that is open, and where it is drawn.
+type level struct {
+	items         []Item
+	focus         int // The chosen item, or -1 if none can be chosen
+	x, y          int
+	width, height int // Including the frame
+	layer         *layers.Layer
+}
+
+// Widget draws a bar of menu titles above a widget. The bar is activated with Options.ActivateKey, by
+// typing a menu's mnemonic with Alt, or by clicking a title. While it is active, it takes all input -
+// the arrow keys move between menus and items, Enter chooses an item, and Esc closes a menu. While it
+// isn't, input goes to the widget beneath, and then to the items' shortcuts.
+type Widget struct {
+	menus   []Menu
+	content gowid.IWidget
+	opts    Options
+	stack   *layers.Widget
+	active  int      // The menu whose title is chosen, or -1 if the bar isn't active
+	open    []*level // The menus open, the one dropped from the bar first
+	cols    int      // The size the widget was last rendered at, so menus can be kept on the screen
+	rows    int
+	*gowid.Callbacks
+	gowid.SubWidgetCallbacks
+}
+
+func New(content gowid.IWidget, menus []Menu, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	res := &Widget{
+		menus:   append([]Menu(nil), menus...),
+		content: content,
+		opts:    opt.withDefaults(),
+		active:  -1,
+	}
+	res.stack = layers.New(&body{mb: res})
+	res.SubWidgetCallbacks = gowid.SubWidgetCallbacks{CB: &res.Callbacks}
+	var _ gowid.ICompositeWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("menubar[%d]", len(w.menus))
+}
+
+// Menus returns a copy of the bar's menus.
+func (w *Widget) Menus() []Menu {
+	return append([]Menu(nil), w.menus...)
+}
+
+// SetMenus replaces the bar's menus, closing any that are open.
+func (w *Widget) SetMenus(menus []Menu, app gowid.IApp) {
+	w.Close(app)
+	w.menus = append([]Menu(nil), menus...)
+}
+
+// SubWidget returns the widget drawn beneath the bar.
+func (w *Widget) SubWidget() gowid.IWidget {
+	if w.content == nil {
+		return null.New()
+	}
+	return w.content
+}
+
+func (w *Widget) SetSubWidget(wi gowid.IWidget, app gowid.IApp) {
+	w.content = wi
+	gowid.RunWidgetCallbacks(w.Callbacks, gowid.SubWidgetCB{}, app, w)
+}
+
+func (w *Widget) SubWidgetSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	res, _ := contentSize(size)
+	return res
+}
+
+// IsOpen returns true if a menu is open.
+func (w *Widget) IsOpen() bool {
+	return len(w.open) > 0
+}
+
+// Active returns the index of the menu whose title is chosen, or -1 if the bar isn't active.
+func (w *Widget) Active() int {
+	return w.active
+}
+
+// Open drops down the i'th menu, closing any others. An index out of range is ignored.
+func (w *Widget) Open(i int, app gowid.IApp) {
+	if i < 0 || i >= len(w.menus) {
+		return
+	}
+	w.closeFrom(0, app)
+	w.active = i
+	w.push(w.menus[i].Items, w.titleX(i), 1, app)
+}
+
+// Close closes every menu, and deactivates the bar.
+func (w *Widget) Close(app gowid.IApp) {
+	w.closeFrom(0, app)
+	w.active = -1
+}
+
+// closeFrom closes the open menus from the n'th on.
+func (w *Widget) closeFrom(n int, app gowid.IApp) {
+	for i := len(w.open) - 1; i >= n; i-- {
+		w.open[i].layer.Remove(app)
+	}
+	if n < len(w.open) {
+		w.open = w.open[:n]
+	}
+}
+
+// push opens a menu of items at x, y, moved if it would be drawn off the screen.
+func (w *Widget) push(items []Item, x, y int, app gowid.IApp) {
+	l := &level{items: items, focus: -1, x: x, y: y}
+	l.width, l.height = MenuSize(items)
+	if w.cols > 0 && l.x+l.width > w.cols {
+		l.x = gwutil.Max(0, w.cols-l.width)
+	}
+	if w.rows > 0 && l.y+l.height > w.rows {
+		l.y = gwutil.Max(0, w.rows-l.height)
+	}
+	l.focus = Step(l.items, -1, 1)
+	l.layer = w.stack.Add(&popup{mb: w, level: len(w.open)}, app, layers.Options{
+		Z:      len(w.open) + 1,
+		VAlign: gowid.VAlignTop{Margin: l.y},
+		Height: gowid.RenderWithUnits{U: l.height},
+		HAlign: gowid.HAlignLeft{Margin: l.x},
+		Width:  gowid.RenderWithUnits{U: l.width},
+	})
+	w.open = append(w.open, l)
+}
+
+// openSubmenu opens the submenu of the chosen item of the innermost menu, beside it - to the right, or
+// to the left if there is no room.
+func (w *Widget) openSubmenu(app gowid.IApp) {
+	parent := w.open[len(w.open)-1]
+	items := parent.items[parent.focus].Items
+	width, _ := MenuSize(items)
+	x := parent.x + parent.width
+	if w.cols > 0 && x+width > w.cols && parent.x-width >= 0 {
+		x = parent.x - width
+	}
+	w.push(items, x, parent.y+parent.focus, app)
+}
+
+// choose runs the action of the chosen item of the innermost menu, or opens its submenu.
+func (w *Widget) choose(app gowid.IApp) {
+	l := w.open[len(w.open)-1]
+	if l.focus == -1 {
+		return
+	}
+	item := l.items[l.focus]
+	if len(item.Items) > 0 {
+		w.openSubmenu(app)
+		return
+	}
+	w.Close(app)
+	if item.Action != nil {
+		item.Action(app)
+	}
+}
+
+// Step returns the index of the item that can be chosen after from, moving by dir and wrapping at the
+// ends, or -1 if there is none - e.g. to move the focus of a menu drawn with DrawMenu.
+func Step(items []Item, from int, dir int) int {
+	n := len(items)
+	for i := 1; i <= n; i++ {
+		j := ((from+dir*i)%n + n) % n
+		if Selectable(items[j]) {
+			return j
+		}
+	}
+	return -1
+}
+
+// Selectable returns true if the item can be chosen - it isn't a separator, and isn't disabled.
+func Selectable(item Item) bool {
+	return !item.Separator && !item.Disabled
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+func (w *Widget) Selectable() bool {
+	return true
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	return w.stack.RenderSize(size, focus, app)
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	res := w.stack.Render(size, focus, app)
+	w.cols, w.rows = res.BoxColumns(), res.BoxRows()
+	return res
+}
+
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if w.active != -1 {
+		switch ev := ev.(type) {
+		case *tcell.EventKey:
+			w.menuKey(ev, app)
+			return true
+		case *tcell.EventMouse:
+			return w.menuMouse(ev, app)
+		}
+	}
+	evk, isKey := ev.(*tcell.EventKey)
+	if isKey && len(w.menus) > 0 {
+		if gowid.KeysEqual(evk, w.opts.ActivateKey) {
+			w.active = 0
+			return true
+		}
+		if evk.Key() == tcell.KeyRune && evk.Modifiers() == tcell.ModAlt {
+			if i := w.menuWithMnemonic(evk.Rune()); i != -1 {
+				w.Open(i, app)
+				return true
+			}
+		}
+	}
+	if w.stack.UserInput(ev, size, focus, app) {
+		return true
+	}
+	if isKey {
+		if item, ok := w.shortcut(evk); ok {
+			item.Action(app)
+			return true
+		}
+	}
+	return false
+}
+
+// menuKey handles a key while the bar is active.
+func (w *Widget) menuKey(ev *tcell.EventKey, app gowid.IApp) {
+	if gowid.KeysEqual(ev, w.opts.ActivateKey) {
+		w.Close(app)
+		return
+	}
+	if ev.Key() == tcell.KeyRune && ev.Modifiers() == tcell.ModAlt {
+		if i := w.menuWithMnemonic(ev.Rune()); i != -1 {
+			w.Open(i, app)
+		}
+		return
+	}
+	n := len(w.menus)
+	if len(w.open) == 0 {
+		// Only the title is chosen
+		switch ev.Key() {
+		case tcell.KeyEsc:
+			w.Close(app)
+		case tcell.KeyLeft:
+			w.active = (w.active + n - 1) % n
+		case tcell.KeyRight:
+			w.active = (w.active + 1) % n
+		case tcell.KeyEnter, tcell.KeyDown, tcell.KeyUp:
+			w.Open(w.active, app)
+		case tcell.KeyRune:
+			if i := w.menuWithMnemonic(ev.Rune()); i != -1 {
+				w.Open(i, app)
+			}
+		}
+		return
+	}
+	l := w.open[len(w.open)-1]
+	switch ev.Key() {
+	case tcell.KeyEsc:
+		// Closing the menu dropped from the bar leaves its title chosen
+		w.closeFrom(len(w.open)-1, app)
+	case tcell.KeyUp:
+		if l.focus != -1 {
+			l.focus = Step(l.items, l.focus, -1)
+		}
+	case tcell.KeyDown:
+		if l.focus != -1 {
+			l.focus = Step(l.items, l.focus, 1)
+		}
+	case tcell.KeyHome:
+		l.focus = Step(l.items, -1, 1)
+	case tcell.KeyEnd:
+		l.focus = Step(l.items, len(l.items), -1)
+	case tcell.KeyEnter:
+		w.choose(app)
+	case tcell.KeyLeft:
+		if len(w.open) > 1 {
+			w.closeFrom(len(w.open)-1, app)
+		} else {
+			w.Open((w.active+n-1)%n, app)
+		}
+	case tcell.KeyRight:
+		if l.focus != -1 && len(l.items[l.focus].Items) > 0 && Selectable(l.items[l.focus]) {
+			w.openSubmenu(app)
+		} else {
+			w.Open((w.active+1)%n, app)
+		}
+	case tcell.KeyRune:
+		if ev.Rune() == ' ' {
+			w.choose(app)
+			return
+		}
+		for i, item := range l.items {
+			if _, m, _ := parseLabel(item.Label); Selectable(item) && m != 0 && SameLetter(m, ev.Rune()) {
+				l.focus = i
+				w.choose(app)
+				return
+			}
+		}
+	}
+}
+
+// menuMouse handles the mouse while the bar is active. A click on a title opens its menu, or closes it if
+// it is open; a click on an item chooses it when the button is released; and a click anywhere else closes
+// the menus.
+func (w *Widget) menuMouse(ev *tcell.EventMouse, app gowid.IApp) bool {
+	x, y := ev.Position()
+	pressed := false
+	switch ev.Buttons() {
+	case tcell.Button1, tcell.Button2, tcell.Button3:
+		pressed = true
+	case tcell.ButtonNone:
+	default:
+		return true
+	}
+	for d := len(w.open) - 1; d >= 0; d-- {
+		l := w.open[d]
+		if x < l.x || x >= l.x+l.width || y < l.y || y >= l.y+l.height {
+			continue
+		}
+		i := y - l.y - 1
+		if i < 0 || i >= len(l.items) || !Selectable(l.items[i]) {
+			return true
+		}
+		if pressed {
+			if len(w.open) > d+1 && l.focus == i {
+				// Still over the item whose submenu is open
+				return true
+			}
+			w.closeFrom(d+1, app)
+			l.focus = i
+			if len(l.items[i].Items) > 0 {
+				w.openSubmenu(app)
+			}
+		} else if !app.GetLastMouseState().NoButtonClicked() && l.focus == i && len(w.open) == d+1 {
+			w.choose(app)
+		}
+		return true
+	}
+	if y == 0 {
+		if i := w.titleAt(x); i != -1 {
+			if pressed && !app.GetLastMouseState().LeftIsClicked() {
+				if len(w.open) > 0 && w.active == i {
+					w.Close(app)
+				} else {
+					w.Open(i, app)
+				}
+			}
+			return true
+		}
+	}
+	if pressed {
+		w.Close(app)
+	}
+	return true
+}
+
+// menuWithMnemonic returns the index of the menu whose mnemonic is r, or -1 if there is none.
+func (w *Widget) menuWithMnemonic(r rune) int {
+	for i, m := range w.menus {
+		if _, mn, _ := parseLabel(m.Label); mn != 0 && SameLetter(mn, r) {
+			return i
+		}
+	}
+	return -1
+}
+
+// shortcut returns the item, in any menu, whose shortcut is the key, if it can be chosen.
+func (w *Widget) shortcut(k gowid.IKey) (Item, bool) {
+	var find func(items []Item) (Item, bool)
+	find = func(items []Item) (Item, bool) {
+		for _, item := range items {
+			if !Selectable(item) {
+				continue
+			}
+			if len(item.Items) > 0 {
+				if res, ok := find(item.Items); ok {
+					return res, true
+				}
+				continue
+			}
+			if item.Shortcut == "" || item.Action == nil {
+				continue
+			}
+			if sk, err := gowid.ParseKey(item.Shortcut); err == nil && gowid.KeysEqual(sk, k) {
+				return item, true
+			}
+		}
+		return Item{}, false
+	}
+	for _, m := range w.menus {
+		if res, ok := find(m.Items); ok {
+			return res, true
+		}
+	}
+	return Item{}, false
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// parseLabel returns a label as drawn, its mnemonic - or 0 if it has none - and the mnemonic's index
+// among the label's runes.
+func parseLabel(label string) (string, rune, int) {
+	rs := []rune(label)
+	res := make([]rune, 0, len(rs))
+	var mnemonic rune
+	pos := -1
+	for i := 0; i < len(rs); i++ {
+		if rs[i] == '&' && i+1 < len(rs) {
+			i++
+			if rs[i] != '&' && mnemonic == 0 {
+				mnemonic, pos = rs[i], len(res)
+			}
+		}
+		res = append(res, rs[i])
+	}
+	return string(res), mnemonic, pos
+}
+
+// Mnemonic returns the mnemonic of a label - the character after its first single "&" - or 0 if it has
+// none.
+func Mnemonic(label string) rune {
+	_, res, _ := parseLabel(label)
+	return res
+}
+
+// SameLetter returns true if a and b are the same letter, whatever their case - e.g. a mnemonic, and a
+// key typed to choose it.
+func SameLetter(a, b rune) bool {
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
+
+// titleX returns the column at which the i'th menu's title is drawn.
+func (w *Widget) titleX(i int) int {
+	x := 0
+	for j := 0; j < i; j++ {
+		x += titleWidth(w.menus[j])
+	}
+	return x
+}
+
+// titleAt returns the index of the menu whose title is drawn at column x, or -1 if there is none.
+func (w *Widget) titleAt(x int) int {
+	start := 0
+	for i, m := range w.menus {
+		end := start + titleWidth(m)
+		if x >= start && x < end {
+			return i
+		}
+		start = end
+	}
+	return -1
+}
+
+func titleWidth(m Menu) int {
+	l, _, _ := parseLabel(m.Label)
+	return runewidth.StringWidth(l) + 2
+}
+
+// MenuSize returns the width and height of a menu of items, including its frame, as drawn by DrawMenu.
+func MenuSize(items []Item) (int, int) {
+	labels, shortcuts, arrow := 0, 0, 0
+	for _, item := range items {
+		l, _, _ := parseLabel(item.Label)
+		labels = gwutil.Max(labels, runewidth.StringWidth(l))
+		if item.Shortcut != "" {
+			shortcuts = gwutil.Max(shortcuts, runewidth.StringWidth(item.Shortcut)+2)
+		}
+		if len(item.Items) > 0 {
+			arrow = 2
+		}
+	}
+	return labels + shortcuts + arrow + 4, len(items) + 2
+}
+
+// cells returns the cells the bar and menus are drawn with - plain, chosen and disabled.
+func cells(opts Options, app gowid.IApp) (gowid.Cell, gowid.Cell, gowid.Cell) {
+	if opts.Style == nil {
+		return gowid.CellFromRune(' '), styleCell(opts.FocusStyle, app), styleCell(opts.DisabledStyle, app)
+	}
+	return styleCell(opts.Style, app),
+		styleCell(gowid.MakeStyleMod(opts.Style, opts.FocusStyle), app),
+		styleCell(gowid.MakeStyleMod(opts.Style, opts.DisabledStyle), app)
+}
+
+// drawLabel draws a label at x, y, with its mnemonic underlined, and returns the column after it.
+func drawLabel(c gowid.ICanvas, x, y int, label string, base gowid.Cell) int {
+	l, _, pos := parseLabel(label)
+	for i, r := range []rune(l) {
+		cell := base.WithRune(r)
+		if i == pos {
+			cell = cell.WithStyle(cell.Style().MergeUnder(gowid.StyleUnderline))
+		}
+		if x >= 0 && x < c.BoxColumns() {
+			c.SetCellAt(x, y, cell)
+		}
+		x += gwutil.Max(1, runewidth.RuneWidth(r))
+	}
+	return x
+}
+
+func drawString(c gowid.ICanvas, x, y int, s string, base gowid.Cell) int {
+	for _, r := range s {
+		if x >= 0 && x < c.BoxColumns() {
+			c.SetCellAt(x, y, base.WithRune(r))
+		}
+		x += gwutil.Max(1, runewidth.RuneWidth(r))
+	}
+	return x
+}
+
+func styleCell(styler gowid.ICellStyler, app gowid.IApp) gowid.Cell {
+	fgCol, bgCol, style := styler.GetStyle(app)
+	mode := app.GetColorMode()
+	return gowid.MakeCell(' ', gowid.IColorToTCell(fgCol, gowid.ColorNone, mode),
+		gowid.IColorToTCell(bgCol, gowid.ColorNone, mode), style)
+}
+
+//======================================================================
+
+// body draws the bar above the widget's content. It is the base of the widget's stack of layers, beneath
+// the open menus.
+type body struct {
+	mb *Widget
+}
+
+func (b *body) Selectable() bool {
+	return true
+}
+
+func (b *body) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	switch sz := size.(type) {
+	case gowid.IRenderBox:
+		return gowid.RenderBox{C: sz.BoxColumns(), R: sz.BoxRows()}
+	case gowid.IRenderFlowWith:
+		content := gowid.RenderSize(b.mb.SubWidget(), size, focus, app)
+		return gowid.RenderBox{C: sz.FlowColumns(), R: content.BoxRows() + 1}
+	default:
+		panic(gowid.WidgetSizeError{Widget: b.mb, Size: size, Required: "gowid.IRenderFlowWith or gowid.IRenderBox"})
+	}
+}
+
+func (b *body) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	box := b.RenderSize(size, focus, app)
+	cols, rows := box.BoxColumns(), box.BoxRows()
+	if rows == 0 {
+		return gowid.NewCanvasOfSize(cols, 0)
+	}
+	res := gowid.NewCanvasOfSize(cols, 1)
+	plain, chosen, _ := cells(b.mb.opts, app)
+	for x := 0; x < cols; x++ {
+		res.SetCellAt(x, 0, plain)
+	}
+	x := 0
+	for i, m := range b.mb.menus {
+		cell := plain
+		if i == b.mb.active {
+			cell = chosen
+		}
+		x = drawString(res, x, 0, " ", cell)
+		x = drawLabel(res, x, 0, m.Label, cell)
+		x = drawString(res, x, 0, " ", cell)
+	}
+	if csize, ok := contentSize(size); ok {
+		// The content has the focus unless the bar is active
+		res.AppendBelow(gowid.Render(b.mb.SubWidget(), csize, focus.And(b.mb.active == -1), app), true, false)
+	}
+	return res
+}
+
+// UserInput passes a click on a title to the bar, and everything else to the content.
+func (b *body) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if evm, ok := ev.(*tcell.EventMouse); ok {
+		if _, y := evm.Position(); y == 0 {
+			return b.mb.menuMouse(evm, app)
+		}
+		ev = gowid.TranslatedMouseEvent(ev, 0, -1)
+	}
+	if csize, ok := contentSize(size); ok {
+		return gowid.UserInputIfSelectable(b.mb.SubWidget(), ev, csize, focus, app)
+	}
+	return false
+}
+
+// contentSize returns the size at which the content is rendered, beneath the bar, and false if there is
+// no room for it.
+func contentSize(size gowid.IRenderSize) (gowid.IRenderSize, bool) {
+	if box, ok := size.(gowid.IRenderBox); ok {
+		if box.BoxRows() < 2 {
+			return nil, false
+		}
+		return gowid.RenderBox{C: box.BoxColumns(), R: box.BoxRows() - 1}, true
+	}
+	return size, true
+}
+
+//======================================================================
+
+// popup draws an open menu, in a layer above the body. The widget handles its input.
+type popup struct {
+	mb    *Widget
+	level int
+	gowid.RejectUserInput
+}
+
+func (p *popup) Selectable() bool {
+	return true
+}
+
+func (p *popup) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	if box, ok := size.(gowid.IRenderBox); ok {
+		return gowid.RenderBox{C: box.BoxColumns(), R: box.BoxRows()}
+	}
+	if p.level >= len(p.mb.open) {
+		return gowid.RenderBox{}
+	}
+	l := p.mb.open[p.level]
+	return gowid.RenderBox{C: l.width, R: l.height}
+}
+
+func (p *popup) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	box := p.RenderSize(size, focus, app)
+	if p.level >= len(p.mb.open) {
+		return gowid.NewCanvasOfSize(box.BoxColumns(), box.BoxRows())
+	}
+	l := p.mb.open[p.level]
+	return DrawMenu(l.items, l.focus, box.BoxColumns(), box.BoxRows(), p.mb.opts, app)
+}
+
+//======================================================================
+
+// DrawMenu returns a canvas of a menu of items, framed, with the item at index focus chosen - or none, if
+// it is -1 - in the styles of opts. MenuSize returns the size it should be drawn at. It lets other
+// widgets, like a context menu, draw menus that look the same as those of the bar.
+func DrawMenu(items []Item, focus int, cols, rows int, opts Options, app gowid.IApp) gowid.ICanvas {
+	res := gowid.NewCanvasOfSize(cols, rows)
+	if cols < 2 || rows < 2 {
+		return res
+	}
+	plain, chosen, disabled := cells(opts.withDefaults(), app)
+	hline, vline := gowid.ASCIIRune(app, '─', '-'), gowid.ASCIIRune(app, '│', '|')
+	frame := func(y int, left, fill, right rune) {
+		res.SetCellAt(0, y, plain.WithRune(left))
+		for x := 1; x < cols-1; x++ {
+			res.SetCellAt(x, y, plain.WithRune(fill))
+		}
+		res.SetCellAt(cols-1, y, plain.WithRune(right))
+	}
+	frame(0, gowid.ASCIIRune(app, '┌', '+'), hline, gowid.ASCIIRune(app, '┐', '+'))
+	frame(rows-1, gowid.ASCIIRune(app, '└', '+'), hline, gowid.ASCIIRune(app, '┘', '+'))
+
+	right := cols - 2 // The column of the space before the right edge
+	end := right      // The column after the shortcuts
+	for _, it := range items {
+		if len(it.Items) > 0 {
+			end = right - 2
+			break
+		}
+	}
+	for i, item := range items {
+		y := i + 1
+		if y >= rows-1 {
+			break
+		}
+		if item.Separator {
+			frame(y, gowid.ASCIIRune(app, '├', '+'), hline, gowid.ASCIIRune(app, '┤', '+'))
+			continue
+		}
+		frame(y, vline, ' ', vline)
+		cell := plain
+		switch {
+		case item.Disabled:
+			cell = disabled
+		case i == focus:
+			cell = chosen
+		}
+		for x := 1; x < cols-1; x++ {
+			res.SetCellAt(x, y, cell)
+		}
+		drawLabel(res, 2, y, item.Label, cell)
+		if len(item.Items) > 0 {
+			res.SetCellAt(right-1, y, cell.WithRune(gowid.ASCIIRune(app, '▸', '>')))
+		}
+		if item.Shortcut != "" {
+			drawString(res, end-runewidth.StringWidth(item.Shortcut), y, item.Shortcut, cell)
+		}
+	}
+	return res
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,140 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package menubar
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func testMenus(chosen *[]string) []Menu {
+	item := func(label, shortcut string) Item {
+		return Item{Label: label, Shortcut: shortcut, Action: func(app gowid.IApp) {
+			*chosen = append(*chosen, label)
+		}}
+	}
+	return []Menu{
+		{Label: "&File", Items: []Item{
+			item("&New", "Ctrl-N"),
+			{Label: "&Recent", Items: []Item{item("&a.txt", ""), item("&b.txt", "")}},
+			Separator,
+			{Label: "&Print", Disabled: true},
+			item("&Quit", "Ctrl-Q"),
+		}},
+		{Label: "&Edit", Items: []Item{item("&Undo", ""), item("Cu&t", "")}},
+	}
+}
+
+func TestKeys1(t *testing.T) {
+	chosen := []string{}
+	w := New(text.New("content"), testMenus(&chosen))
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 30, Rows: 9})
+	defer sim.Close()
+
+	sim.AssertLine(t, 0, " File  Edit                   ")
+	sim.AssertLine(t, 1, "content                       ")
+
+	// F10 activates the bar, and Enter opens the chosen menu
+	sim.Key(tcell.KeyF10)
+	assert.Equal(t, 0, w.Active())
+	assert.False(t, w.IsOpen())
+	sim.Key(tcell.KeyRight)
+	sim.Key(tcell.KeyEnter)
+	assert.True(t, w.IsOpen())
+	sim.AssertLine(t, 1, "conten┌──────┐                ")
+	sim.AssertLine(t, 2, "      │ Undo │                ")
+	sim.AssertLine(t, 3, "      │ Cut  │                ")
+	sim.AssertLine(t, 4, "      └──────┘                ")
+
+	// Left wraps to the first menu; Down skips the separator and the disabled item
+	sim.Key(tcell.KeyLeft)
+	sim.AssertLine(t, 1, "┌──────────────────┐          ")
+	sim.AssertLine(t, 2, "│ New     Ctrl-N   │          ")
+	sim.AssertLine(t, 3, "│ Recent         ▸ │          ")
+	sim.AssertLine(t, 4, "├──────────────────┤          ")
+	sim.AssertLine(t, 5, "│ Print            │          ")
+	sim.AssertLine(t, 6, "│ Quit    Ctrl-Q   │          ")
+	sim.Key(tcell.KeyDown)
+	sim.Key(tcell.KeyDown)
+	sim.Key(tcell.KeyEnter)
+	assert.Equal(t, []string{"&Quit"}, chosen)
+	assert.False(t, w.IsOpen())
+	assert.Equal(t, -1, w.Active())
+
+	// Alt and a mnemonic open a menu; Right opens a submenu, and a mnemonic chooses from it
+	sim.Rune('f', tcell.ModAlt)
+	sim.Key(tcell.KeyDown)
+	sim.Key(tcell.KeyRight)
+	sim.AssertLine(t, 2, "│ New     Ctrl-N   │┌───────┐ ")
+	sim.AssertLine(t, 3, "│ Recent         ▸ ││ a.txt │ ")
+	sim.AssertLine(t, 4, "├──────────────────┤│ b.txt │ ")
+	sim.Rune('b')
+	assert.Equal(t, []string{"&Quit", "&b.txt"}, chosen)
+	assert.False(t, w.IsOpen())
+
+	// Esc closes one menu at a time, then deactivates the bar
+	sim.Rune('e', tcell.ModAlt)
+	sim.Key(tcell.KeyEsc)
+	assert.False(t, w.IsOpen())
+	assert.Equal(t, 1, w.Active())
+	sim.Key(tcell.KeyEsc)
+	assert.Equal(t, -1, w.Active())
+
+	// Shortcuts work while the menus are closed
+	sim.Key(tcell.KeyCtrlN)
+	assert.Equal(t, []string{"&Quit", "&b.txt", "&New"}, chosen)
+}
+
+func TestMouse1(t *testing.T) {
+	chosen := []string{}
+	w := New(text.New("content"), testMenus(&chosen))
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 30, Rows: 9})
+	defer sim.Close()
+
+	// A click on a title opens its menu, and another closes it
+	sim.Click(2, 0, tcell.Button1)
+	assert.True(t, w.IsOpen())
+	sim.Click(2, 0, tcell.Button1)
+	assert.False(t, w.IsOpen())
+
+	// A click on an item with a submenu opens it; a click on an item in it chooses it
+	sim.Click(8, 0, tcell.Button1)
+	sim.Click(3, 0, tcell.Button1)
+	sim.Click(3, 3, tcell.Button1)
+	assert.True(t, w.IsOpen())
+	sim.Click(22, 4, tcell.Button1)
+	assert.Equal(t, []string{"&b.txt"}, chosen)
+	assert.False(t, w.IsOpen())
+
+	// Clicks on disabled items do nothing; a click elsewhere closes the menus
+	sim.Click(3, 0, tcell.Button1)
+	sim.Click(3, 5, tcell.Button1)
+	assert.True(t, w.IsOpen())
+	sim.Click(25, 7, tcell.Button1)
+	assert.False(t, w.IsOpen())
+	assert.Equal(t, []string{"&b.txt"}, chosen)
+}
+
+func TestLabel1(t *testing.T) {
+	l, m, pos := parseLabel("Save && E&xit")
+	assert.Equal(t, "Save & Exit", l)
+	assert.Equal(t, 'x', m)
+	assert.Equal(t, 8, pos)
+	l, m, _ = parseLabel("Plain")
+	assert.Equal(t, "Plain", l)
+	assert.Equal(t, rune(0), m)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -70,6 +70,16 @@ type IOverlay interface {
 	BottomGetsCursor() bool
 }
 
+// ITranslucent is implemented by an overlay whose top widget may be translucent - see Options.Opacity.
+type ITranslucent interface {
+	Opacity() float64
+}
+
+// IDimmer is implemented by an overlay that may dim the bottom widget - see Options.Dim.
+type IDimmer interface {
+	Dim() float64
+}
+
 type IWidget interface {
 	gowid.IWidget
 	IOverlay
@@ -108,6 +118,12 @@ type Options struct {
 	BottomGetsFocus  bool
 	TopGetsNoFocus   bool
 	BottomGetsCursor bool
+	// Opacity, if between 0 and 1, makes the top widget translucent, so the bottom widget shows through
+	// it - see gowid.Cell.WithOpacity. 0, the default, means opaque.
+	Opacity float64
+	// Dim, if greater than 0, is the opacity of a black shade laid over the bottom widget, e.g. to
+	// draw the eye to a dialog on top.
+	Dim float64
 }
 
 func New(top, bottom gowid.IWidget,
@@ -130,6 +146,8 @@ func New(top, bottom gowid.IWidget,
 	}
 	var _ gowid.IWidget = res
 	var _ IWidgetSettable = res
+	var _ ITranslucent = res
+	var _ IDimmer = res
 	return res
 }
 
@@ -149,6 +167,22 @@ func (w *Widget) TopGetsFocus() bool {
 	return !w.opts.TopGetsNoFocus
 }
 
+func (w *Widget) Opacity() float64 {
+	return w.opts.Opacity
+}
+
+func (w *Widget) SetOpacity(opacity float64, app gowid.IApp) {
+	w.opts.Opacity = opacity
+}
+
+func (w *Widget) Dim() float64 {
+	return w.opts.Dim
+}
+
+func (w *Widget) SetDim(dim float64, app gowid.IApp) {
+	w.opts.Dim = dim
+}
+
 func (w *Widget) Top() gowid.IWidget {
 	return w.top
 }
@@ -265,13 +299,19 @@ func Render(w IOverlay, size gowid.IRenderSize, focus gowid.Selector, app gowid.
 	bfocus := focus.And(w.BottomGetsFocus())
 	tfocus := focus.And(w.TopGetsFocus())
 
-	bottomC := w.Bottom().Render(size, bfocus, app)
+	bottomC := gowid.Render(w.Bottom(), size, bfocus, app)
 	if w.Top() == nil {
 		return bottomC
 	} else {
 		bottomC2 := bottomC.Duplicate()
+		if dw, ok := w.(IDimmer); ok && dw.Dim() > 0 {
+			gowid.ShadeCanvas(bottomC2, gowid.MakeTCellColorExt(tcell.ColorBlack), dw.Dim())
+		}
 		p2 := padding.New(w.Top(), w.VAlign(), w.Height(), w.HAlign(), w.Width())
-		topC := p2.Render(size, tfocus, app)
+		topC := gowid.Render(p2, size, tfocus, app)
+		if tw, ok := w.(ITranslucent); ok && tw.Opacity() > 0 && tw.Opacity() < 1 {
+			gowid.SetCanvasOpacity(topC, tw.Opacity())
+		}
 		bottomC2.MergeUnder(topC, 0, 0, w.BottomGetsCursor())
 		return bottomC2
 	}
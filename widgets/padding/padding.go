@@ -191,7 +191,7 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 
 	subSize := w.SubWidgetSize(size, focus, app)
 
-	subWidgetCanvas := w.SubWidget().Render(subSize, focus, app)
+	subWidgetCanvas := gowid.Render(w.SubWidget(), subSize, focus, app)
 	subWidgetMaxColumn := subWidgetCanvas.BoxColumns()
 
 	var myCols int
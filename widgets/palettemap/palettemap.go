@@ -122,7 +122,7 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	})
 	override := NewOverride(app, &newAttrs)
 
-	res := w.SubWidget().Render(size, focus, override)
+	res := gowid.Render(w.SubWidget(), size, focus, override)
 	return res
 }
 
@@ -152,6 +152,12 @@ func (a *PaletteOverride) RangeOverPalette(f func(k string, v gowid.ICellStyler)
 	a.Palette.RangeOverPalette(f)
 }
 
+// Stylesheet returns the underlying app's stylesheet, so that widgets inside the palettemap are
+// still styled by it.
+func (a *PaletteOverride) Stylesheet() *gowid.Stylesheet {
+	return gowid.StylesheetFor(a.IApp)
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
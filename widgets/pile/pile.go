@@ -48,6 +48,8 @@ type Options struct {
 	DoNotSetSelected bool // Whether or not to set the focus.Selected field for the selected child
 	DownKeys         []vim.KeyPress
 	UpKeys           []vim.KeyPress
+	// Justify places the rows left over if the widgets don't use them all - by default, at the bottom
+	Justify gowid.Justify
 }
 
 var _ gowid.IWidget = (*Widget)(nil)
@@ -134,6 +136,10 @@ func (w *Widget) SetFocus(app gowid.IApp, i int) {
 	}
 }
 
+func (w *Widget) Justify() gowid.Justify {
+	return w.opt.Justify
+}
+
 func (w *Widget) Wrap() bool {
 	return w.opt.Wrap
 }
@@ -160,6 +166,7 @@ func (w *Widget) SetSubWidgets(widgets []gowid.IWidget, app gowid.IApp) {
 			ws[i] = &gowid.ContainerWidget{IWidget: iw, D: gowid.RenderFlow{}}
 		}
 	}
+	gowid.LayoutChanging(app, w)
 	oldFocus := w.Focus()
 	w.widgets = ws
 	w.SetFocus(app, oldFocus)
@@ -175,6 +182,7 @@ func (w *Widget) Dimensions() []gowid.IWidgetDimension {
 }
 
 func (w *Widget) SetDimensions(dimensions []gowid.IWidgetDimension, app gowid.IApp) {
+	gowid.LayoutChanging(app, w)
 	for i, id := range dimensions {
 		w.widgets[i].SetDimension(id)
 	}
@@ -277,10 +285,16 @@ func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Se
 
 	subs := w.SubWidgets()
 
+	heights := make([]int, len(ss))
+	for i, s := range ss {
+		heights[i] = s.BoxRows()
+	}
+	gaps := justifyGaps(w, size, heights)
+
 	focusEvent := func(selectable bool) {
-		srows := 0
+		srows := gaps[subfocus]
 		for i := 0; i < subfocus; i++ {
-			srows += ss[i].BoxRows()
+			srows += gaps[i] + ss[i].BoxRows()
 		}
 
 		subSize := ss2[subfocus]
@@ -317,6 +331,7 @@ func UserInput(w IWidget, ev interface{}, size gowid.IRenderSize, focus gowid.Se
 			curY := 0
 		Loop:
 			for i, c := range ss {
+				curY += gaps[i]
 				if my < curY+c.BoxRows() && my >= curY {
 					subSize := ss2[i]
 					forChild = subs[i].UserInput(gowid.TranslatedMouseEvent(ev, 0, -curY), subSize, focus.SelectIf(w.SelectChild(focus) && i == subfocus), app)
@@ -420,8 +435,12 @@ func RenderSize(w gowid.ICompositeMultipleWidget, size gowid.IRenderSize, focus
 		maxrow += sz.BoxRows()
 	}
 
-	if sz, ok := size.(gowid.IRenderBox); ok {
-		maxrow = gwutil.Min(maxrow, sz.BoxRows())
+	// As Render pads or chops the pile to fit
+	if rows, ok := size.(gowid.IRows); ok {
+		maxrow = rows.Rows()
+	}
+	if cols, ok := size.(gowid.IColumns); ok {
+		maxcol = gwutil.Max(maxcol, cols.Columns())
 	}
 
 	return gowid.RenderBox{maxcol, maxrow}
@@ -450,7 +469,18 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	res := gowid.NewCanvas()
 	trim := false
 
+	heights := make([]int, len(canvases))
+	for i, c := range canvases {
+		if c != nil {
+			heights[i] = c.BoxRows()
+		}
+	}
+	gaps := justifyGaps(w, size, heights)
+
 	for i := 0; i < len(canvases); i++ {
+		if gaps[i] > 0 {
+			gowid.AppendBlankLines(res, gaps[i])
+		}
 		// Can be nil if weighted widgets were included but there wasn't enough space
 		if canvases[i] != nil {
 			// make sure each canvas uses up the width it is alloted - so if I render
@@ -479,6 +509,19 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	return res
 }
 
+// justifyGaps returns the rows to leave above each subwidget, given the heights it will have - see
+// gowid.JustifyGaps.
+func justifyGaps(w interface{}, size gowid.IRenderSize, heights []int) []int {
+	leftover := 0
+	if rows, ok := size.(gowid.IRows); ok {
+		leftover = rows.Rows()
+		for _, r := range heights {
+			leftover -= r
+		}
+	}
+	return gowid.JustifyGaps(gowid.JustifyOf(w), leftover, len(heights))
+}
+
 func RenderedChildrenSizes(w IWidget, size gowid.IRenderSize, focus gowid.Selector, focusIdx int, app gowid.IApp) ([]gowid.IRenderBox, []gowid.IRenderSize) {
 	fn2 := BoxMakerFunc(func(w gowid.IWidget, subSize gowid.IRenderSize, focus gowid.Selector, subApp gowid.IApp) gowid.IRenderBox {
 		return w.RenderSize(subSize, focus, subApp)
@@ -493,7 +536,7 @@ func RenderedChildrenSizes(w IWidget, size gowid.IRenderSize, focus gowid.Select
 
 func RenderSubwidgets(w IWidget, size gowid.IRenderSize, focus gowid.Selector, focusIdx int, app gowid.IApp) []gowid.ICanvas {
 	fn1 := BoxMakerFunc(func(w gowid.IWidget, subSize gowid.IRenderSize, focus gowid.Selector, subApp gowid.IApp) gowid.IRenderBox {
-		return w.Render(subSize, focus, subApp)
+		return gowid.Render(w, subSize, focus, subApp)
 	})
 
 	canvases, _ := w.RenderBoxMaker(size, focus, focusIdx, app, fn1)
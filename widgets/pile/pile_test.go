@@ -289,6 +289,47 @@ bar
 baz`[1:], c.String())
 }
 
+func TestJustify1(t *testing.T) {
+	clicks := []string{}
+	clickable := func(label string) gowid.IWidget {
+		b := button.NewBare(text.New(label))
+		b.OnClick(gowid.MakeWidgetCallback("test", func(app gowid.IApp, w gowid.IWidget) {
+			clicks = append(clicks, label)
+		}))
+		return b
+	}
+	subs := []gowid.IContainerWidget{
+		&gowid.ContainerWidget{clickable("a"), gowid.RenderFlow{}},
+		&gowid.ContainerWidget{clickable("b"), gowid.RenderFlow{}},
+		&gowid.ContainerWidget{clickable("c"), gowid.RenderFlow{}},
+	}
+	for _, tc := range []struct {
+		justify gowid.Justify
+		rows    string
+	}{
+		{gowid.JustifyStart, "abc  "},
+		{gowid.JustifyEnd, "  abc"},
+		{gowid.JustifyCenter, " abc "},
+		{gowid.JustifySpaceBetween, "a b c"},
+	} {
+		w := New(subs, Options{Justify: tc.justify})
+		c := w.Render(gowid.RenderBox{C: 1, R: 5}, gowid.Focused, gwtest.D)
+		assert.Equal(t, strings.Join(strings.Split(tc.rows, ""), "\n"), c.String(), "%v", tc.justify)
+	}
+
+	w := New(subs, Options{Justify: gowid.JustifySpaceBetween})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 1, Rows: 5})
+	defer sim.Close()
+	sim.Click(0, 2, tcell.Button1)
+	sim.Click(0, 3, tcell.Button1)
+	sim.Click(0, 4, tcell.Button1)
+	assert.Equal(t, []string{"b", "c"}, clicks)
+	assert.Equal(t, 2, w.Focus())
+	sim.Key(tcell.KeyUp)
+	sim.Key(tcell.KeyEnter)
+	assert.Equal(t, []string{"b", "c", "b"}, clicks)
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
@@ -0,0 +1,466 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package plot provides a line chart that draws time series with braille characters, so that each cell
+// holds a grid of two by four points. It has axes, a key to its series, and can show a moving window of
+// the latest data, for dashboards that stream it.
+package plot
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/annotation"
+	"github.com/gcla/gowid/widgets/legend"
+	"github.com/mattn/go-runewidth"
+)
+
+//======================================================================
+
+// DefaultHeight is the height of a chart rendered as a flow or fixed widget, unless Options says otherwise.
+var DefaultHeight = 12
+
+// DefaultWidth is the width of a chart rendered as a fixed widget.
+var DefaultWidth = 60
+
+// DefaultTimeFormat formats the times at the ends of the time axis, unless Options says otherwise.
+var DefaultTimeFormat = "15:04:05"
+
+// Series is a line on the chart. Its data can be added to while the chart is shown - e.g. from another
+// goroutine - and the chart draws the latest of it each time it renders.
+type Series struct {
+	Name  string
+	Color gowid.IColor // If nil, the line is drawn in the terminal's default color
+	Data  *gowid.TimeSeries
+}
+
+// Options is used to configure the widget.
+type Options struct {
+	// Window, if not 0, is the span of time shown, ending at the time of the app's clock - so the lines
+	// move left as time passes. Otherwise the chart shows every point, from the first to the last.
+	Window time.Duration
+	// Min and Max are the values at the bottom and top of the value axis. If they are equal, the axis
+	// fits the points shown.
+	Min, Max   float64
+	Height     int                    // The height as a flow widget; defaults to DefaultHeight
+	NoAxes     bool                   // If true, the axes and their labels aren't drawn
+	NoKey      bool                   // If true, the key to the series' names and colors isn't drawn
+	Format     func(v float64) string // Formats the value axis labels; defaults to 4 significant digits
+	TimeFormat string                 // Formats the time axis labels; defaults to DefaultTimeFormat
+	// Legend, if not nil, hides and highlights series, and gives their colors - see legend.ISeries. A
+	// chart with a separate legend usually has NoKey set.
+	Legend legend.ISeries
+}
+
+// Widget is a line chart of one or more time series. Consecutive points are joined by lines, and a NaN
+// value breaks a line.
+type Widget struct {
+	series []Series
+	opts   Options
+	notes  annotation.Annotations
+	gowid.RejectUserInput
+	gowid.NotSelectable
+}
+
+func New(series []Series, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Height <= 0 {
+		opt.Height = DefaultHeight
+	}
+	if opt.Format == nil {
+		opt.Format = func(v float64) string {
+			return strconv.FormatFloat(v, 'g', 4, 64)
+		}
+	}
+	if opt.TimeFormat == "" {
+		opt.TimeFormat = DefaultTimeFormat
+	}
+	res := &Widget{
+		series: series,
+		opts:   opt,
+	}
+	var _ gowid.IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("plot[%d]", len(w.series))
+}
+
+// Series returns the lines of the chart.
+func (w *Widget) Series() []Series {
+	return w.series
+}
+
+// SetSeries replaces the lines of the chart.
+func (w *Widget) SetSeries(series []Series, app gowid.IApp) {
+	w.series = series
+}
+
+func (w *Widget) GetAnnotations() annotation.Annotations {
+	return w.notes
+}
+
+// SetAnnotations sets the reference lines and regions drawn on the plot. Horizontal lines and regions are
+// placed by value; vertical ones by time, given as a value by TimeValue.
+func (w *Widget) SetAnnotations(notes annotation.Annotations, app gowid.IApp) {
+	w.notes = notes
+}
+
+// TimeValue returns t as the value of a vertical annotation line or region - see SetAnnotations.
+func TimeValue(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+// Window returns the span of time the chart shows - see Options.Window. It returns zero times if there
+// are no points to show.
+func (w *Widget) Window(app gowid.IApp) (time.Time, time.Time) {
+	if w.opts.Window > 0 {
+		now := gowid.ClockFor(app).Now()
+		return now.Add(-w.opts.Window), now
+	}
+	var from, to time.Time
+	for i, s := range w.series {
+		if w.hidden(i) || s.Data == nil {
+			continue
+		}
+		points := s.Data.Points()
+		if len(points) == 0 {
+			continue
+		}
+		if from.IsZero() || points[0].Time.Before(from) {
+			from = points[0].Time
+		}
+		if last := points[len(points)-1].Time; to.IsZero() || last.After(to) {
+			to = last
+		}
+	}
+	return from, to
+}
+
+// Range returns the values at the bottom and top of the value axis, given the points of each series
+// shown - see Options.Min and Options.Max.
+func (w *Widget) Range(points [][]gowid.TimePoint) (float64, float64) {
+	if w.opts.Min != w.opts.Max {
+		return w.opts.Min, w.opts.Max
+	}
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, ps := range points {
+		for _, p := range ps {
+			if !math.IsNaN(p.Value) {
+				min, max = math.Min(min, p.Value), math.Max(max, p.Value)
+			}
+		}
+	}
+	switch {
+	case math.IsInf(min, 1):
+		return 0, 1
+	case min == max:
+		return min - 1, max + 1
+	}
+	return min, max
+}
+
+func (w *Widget) hidden(i int) bool {
+	return legend.SeriesHidden(w.opts.Legend, i)
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	cols, rows := w.size(size)
+	return gowid.RenderBox{C: cols, R: rows}
+}
+
+func (w *Widget) size(size gowid.IRenderSize) (int, int) {
+	switch sz := size.(type) {
+	case gowid.IRenderBox:
+		return sz.BoxColumns(), sz.BoxRows()
+	case gowid.IRenderFlowWith:
+		return sz.FlowColumns(), w.opts.Height
+	default:
+		return DefaultWidth, w.opts.Height
+	}
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	cols, rows := w.size(size)
+	res := gowid.NewCanvasOfSize(cols, rows)
+
+	// Rows beneath the plot - the time axis and its labels, then the key
+	below := 0
+	if !w.opts.NoAxes {
+		below += 2
+	}
+	if !w.opts.NoKey && len(w.series) > 0 {
+		below++
+	}
+	height := rows - below
+	if height <= 0 || cols <= 1 {
+		return res
+	}
+
+	from, to := w.Window(app)
+	points := make([][]gowid.TimePoint, len(w.series))
+	for i, s := range w.series {
+		if !w.hidden(i) && s.Data != nil && !from.IsZero() {
+			// Twice the columns, for the two points across each braille cell
+			points[i] = s.Data.Downsampled(from, to.Add(1), 2*cols, gowid.DownsampleLTTB)
+		}
+	}
+	min, max := w.Range(points)
+
+	// The value axis, with its labels to the left
+	left := 0
+	if !w.opts.NoAxes {
+		ticks := []float64{max, min}
+		if height >= 5 {
+			ticks = []float64{max, (min + max) / 2, min}
+		}
+		labels := make([]string, len(ticks))
+		for i, t := range ticks {
+			labels[i] = w.opts.Format(t)
+			left = gwutil.Max(left, runewidth.StringWidth(labels[i]))
+		}
+		left = gwutil.Min(left, cols-1)
+		for y := 0; y < height; y++ {
+			res.SetCellAt(left, y, gowid.CellFromRune(gowid.ASCIIRune(app, '│', '|')))
+		}
+		for i, label := range labels {
+			y := i * (height - 1) / (len(labels) - 1)
+			drawString(res, left-runewidth.StringWidth(label), y, label, gowid.Cell{})
+			res.SetCellAt(left, y, gowid.CellFromRune(gowid.ASCIIRune(app, '┤', '+')))
+		}
+		left++
+
+		for x := left; x < cols; x++ {
+			res.SetCellAt(x, height, gowid.CellFromRune(gowid.ASCIIRune(app, '─', '-')))
+		}
+		res.SetCellAt(left-1, height, gowid.CellFromRune(gowid.ASCIIRune(app, '└', '+')))
+		if !from.IsZero() {
+			drawString(res, left, height+1, from.Format(w.opts.TimeFormat), gowid.Cell{})
+			end := to.Format(w.opts.TimeFormat)
+			if x := cols - runewidth.StringWidth(end); x > left+runewidth.StringWidth(end) {
+				drawString(res, x, height+1, end, gowid.Cell{})
+			}
+		}
+	}
+	width := cols - left
+
+	// The lines, on a grid of dots - the highlighted series is drawn last, so it's on top
+	grid := newDots(width, height)
+	order := make([]int, 0, len(w.series))
+	hl := -1
+	if w.opts.Legend != nil {
+		hl = w.opts.Legend.Highlighted()
+	}
+	for i := range w.series {
+		if i != hl {
+			order = append(order, i)
+		}
+	}
+	if hl >= 0 && hl < len(w.series) {
+		order = append(order, hl)
+	}
+	span := float64(to.Sub(from))
+	for _, i := range order {
+		dotX := func(t time.Time) int {
+			if span <= 0 {
+				return grid.cols - 1
+			}
+			return int(gwutil.Round(float64(t.Sub(from)) / span * float64(grid.cols-1)))
+		}
+		dotY := func(v float64) int {
+			frac := (v - min) / (max - min)
+			return grid.rows - 1 - int(gwutil.Round(frac*float64(grid.rows-1)))
+		}
+		joined := false
+		var px, py int
+		for _, p := range points[i] {
+			if math.IsNaN(p.Value) {
+				joined = false
+				continue
+			}
+			x, y := dotX(p.Time), dotY(p.Value)
+			if joined {
+				grid.line(px, py, x, y, i)
+			} else {
+				grid.set(x, y, i)
+			}
+			joined, px, py = true, x, y
+		}
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, s := grid.cell(x, y, app)
+			if s == -1 {
+				continue
+			}
+			res.SetCellAt(left+x, y, w.seriesCell(s, app).WithRune(r))
+		}
+	}
+
+	// Annotations - lines pass behind the points, and regions shade only the cells without any
+	annotation.Draw(res, w.notes, annotation.Axes{
+		Left: left,
+		Cols: width,
+		Rows: height,
+		Row:  annotation.Scale(min, max, 0, height, true),
+		Col: func(v float64) int {
+			if span <= 0 {
+				return left + width - 1
+			}
+			return left + int(gwutil.Round((v-TimeValue(from))*float64(time.Second)/span*float64(width-1)))
+		},
+		Shade: func(c gowid.Cell) bool {
+			return !c.HasRune()
+		},
+	}, app)
+
+	// The key, e.g. "■ cpu  ■ memory"
+	if !w.opts.NoKey && len(w.series) > 0 {
+		x := 0
+		for i, s := range w.series {
+			if w.hidden(i) {
+				continue
+			}
+			if x > 0 {
+				x += 2
+			}
+			res.SetCellAt(x, rows-1, w.seriesCell(i, app).WithRune(gowid.ASCIIRune(app, '■', '#')))
+			x = drawString(res, x+2, rows-1, s.Name, gowid.Cell{})
+		}
+	}
+	return res
+}
+
+// seriesCell returns a blank cell in the color of series i.
+func (w *Widget) seriesCell(i int, app gowid.IApp) gowid.Cell {
+	color := legend.SeriesColor(w.opts.Legend, i, w.series[i].Color)
+	if color == nil {
+		return gowid.Cell{}
+	}
+	return gowid.Cell{}.WithForegroundColor(gowid.IColorToTCell(color, gowid.ColorNone, app.GetColorMode()))
+}
+
+// drawString draws s from column x of row y, as far as the canvas goes, and returns the column after it.
+func drawString(c gowid.ICanvas, x, y int, s string, base gowid.Cell) int {
+	for _, r := range s {
+		if x >= 0 && x < c.BoxColumns() {
+			c.SetCellAt(x, y, base.WithRune(r))
+		}
+		x += gwutil.Max(1, runewidth.RuneWidth(r))
+	}
+	return x
+}
+
+//======================================================================
+
+// dots is a grid of points, two across and four down in each cell, each belonging to a series.
+type dots struct {
+	cols, rows int
+	series     []int // The series that set each dot, or -1
+}
+
+func newDots(cols, rows int) *dots {
+	res := &dots{
+		cols:   cols * 2,
+		rows:   rows * 4,
+		series: make([]int, cols*2*rows*4),
+	}
+	for i := range res.series {
+		res.series[i] = -1
+	}
+	return res
+}
+
+func (d *dots) set(x, y int, series int) {
+	if x >= 0 && x < d.cols && y >= 0 && y < d.rows {
+		d.series[y*d.cols+x] = series
+	}
+}
+
+// line sets the dots on the line from one dot to another, with Bresenham's algorithm.
+func (d *dots) line(x0, y0, x1, y1 int, series int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		d.set(x0, y0, series)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+var (
+	// The bits of the braille dots in a cell, by row, from the top, then column
+	brailleBits = [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+)
+
+// cell returns the character for a cell of the grid, and the series that set the most of its dots, or
+// -1 if none are set. If widgets should draw with ASCII only, a cell with any dots set is drawn as '*'.
+func (d *dots) cell(x, y int, app gowid.IApp) (rune, int) {
+	res := rune(0x2800)
+	set := make([]int, 0, 8)
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 2; col++ {
+			if s := d.series[(y*4+row)*d.cols+x*2+col]; s != -1 {
+				res |= brailleBits[row][col]
+				set = append(set, s)
+			}
+		}
+	}
+	best, most := -1, 0
+	for _, s := range set {
+		n := 0
+		for _, t := range set {
+			if t == s {
+				n++
+			}
+		}
+		if n > most {
+			best, most = s, n
+		}
+	}
+	if gowid.ASCIIOnly(app) {
+		res = '*'
+	}
+	return res, best
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
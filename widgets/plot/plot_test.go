@@ -0,0 +1,87 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package plot
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/annotation"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+var t0 = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func series(values ...float64) *gowid.TimeSeries {
+	res := gowid.NewTimeSeries(100)
+	for i, v := range values {
+		res.Add(t0.Add(time.Duration(i)*time.Second), v)
+	}
+	return res
+}
+
+func TestPlot1(t *testing.T) {
+	w := New([]Series{{Name: "up", Data: series(0, 1, 2, 3)}}, Options{Height: 5})
+	c := w.Render(gowid.RenderFlowWith{C: 20}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, ""+
+		"3┤         ⣀⣀⠤⠤⠒⠒⠒⠉⠉\n"+
+		"0┤⣀⡠⠤⠤⠔⠒⠒⠉⠉         \n"+
+		" └──────────────────\n"+
+		"  12:00:00  12:00:03\n"+
+		"■ up                ", c.String())
+
+	// A NaN breaks the line
+	w = New([]Series{{Data: series(1, math.NaN(), 1)}}, Options{Height: 1, NoAxes: true, NoKey: true})
+	c = w.Render(gowid.RenderFlowWith{C: 4}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "⠂  ⠐", c.String())
+}
+
+func TestWindow1(t *testing.T) {
+	data := series(0, 1, 2, 3)
+	w := New([]Series{{Data: data}}, Options{Window: 2 * time.Second, Height: 1, NoAxes: true, NoKey: true})
+	clock := gowid.NewFakeClock(t0.Add(3 * time.Second))
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 10, Rows: 1, Clock: clock})
+	defer sim.Close()
+
+	from, to := w.Window(sim)
+	assert.Equal(t, t0.Add(time.Second), from)
+	assert.Equal(t, t0.Add(3*time.Second), to)
+
+	// As time passes, the lines move left, and the axis fits the points shown
+	clock.Advance(time.Second)
+	data.Add(t0.Add(4*time.Second), 10)
+	from, _ = w.Window(sim)
+	assert.Equal(t, t0.Add(2*time.Second), from)
+	min, max := w.Range([][]gowid.TimePoint{data.Window(from, t0.Add(5*time.Second))})
+	assert.Equal(t, 2.0, min)
+	assert.Equal(t, 10.0, max)
+}
+
+func TestAnnotations1(t *testing.T) {
+	w := New([]Series{{Data: series(0, 1, 2, 3)}}, Options{Height: 5, NoKey: true})
+	w.SetAnnotations(annotation.Annotations{
+		Lines: []annotation.Line{
+			{Value: 1.5, Label: "slo"},
+			{Value: TimeValue(t0.Add(2 * time.Second)), Vertical: true},
+		},
+	}, gwtest.D)
+	c := w.Render(gowid.RenderFlowWith{C: 20}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, ""+
+		"3┤           │⢀⣀⠤⠔⠒⠉\n"+
+		" │─────⢀⣀⠤⠤⠒⠒⠉⠁──slo\n"+
+		"0┤⣀⠤⠔⠒⠉⠁     │      \n"+
+		" └──────────────────\n"+
+		"  12:00:00  12:00:03", c.String())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -83,6 +83,19 @@ func (w *Widget) Text() string {
 	return fmt.Sprintf("%d %%", percent)
 }
 
+func (w *Widget) AccessibleRole() gowid.Role {
+	return gowid.RoleProgressBar
+}
+
+func (w *Widget) AccessibleLabel(app gowid.IApp) string {
+	return ""
+}
+
+// AccessibleState returns the percentage complete, as displayed.
+func (w *Widget) AccessibleState(app gowid.IApp) string {
+	return w.Text()
+}
+
 func (w *Widget) OnSetProgress(f gowid.IWidgetChangedCallback) {
 	gowid.AddWidgetCallback(w.Callbacks, ProgressCB{}, f)
 }
@@ -165,10 +178,6 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	fnorm, _, _ := w.Normal().GetStyle(app)
 	percentStyle := gowid.MakePaletteEntry(fnorm, gowid.NoColor{})
 
-	fcomp, bcomp, scomp := w.Complete().GetStyle(app)
-	fcompCol := gowid.IColorToTCell(fcomp, gowid.ColorNone, app.GetColorMode())
-	bcompCol := gowid.IColorToTCell(bcomp, gowid.ColorNone, app.GetColorMode())
-
 	cur, done := w.Progress(), w.Target()
 	var cutoff int
 	if done == 0 {
@@ -176,7 +185,12 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	} else {
 		cutoff = (cur * cols) / done
 	}
+	// If the complete style is e.g. a gowid.GradientStyler, it spans the whole bar, and is revealed as
+	// progress is made
 	for i := 0; i < cutoff; i++ {
+		fcomp, bcomp, scomp := gowid.StylerForPosition(w.Complete(), i, cols).GetStyle(app)
+		fcompCol := gowid.IColorToTCell(fcomp, gowid.ColorNone, app.GetColorMode())
+		bcompCol := gowid.IColorToTCell(bcomp, gowid.ColorNone, app.GetColorMode())
 		barCanvas.SetCellAt(i, 0, barCanvas.CellAt(i, 0).WithForegroundColor(fcompCol).WithBackgroundColor(bcompCol).WithStyle(scomp))
 	}
 
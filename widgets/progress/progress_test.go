@@ -70,6 +70,27 @@ func TestCanvas23(t *testing.T) {
 	}
 }
 
+func TestGradient1(t *testing.T) {
+	red, green := gowid.MakeRGBColor("#ff0000"), gowid.MakeRGBColor("#00ff00")
+	w := New(Options{
+		Normal:   gowid.EmptyPalette{},
+		Complete: gowid.MakeBackgroundGradient(gowid.HSLSpace, red, green),
+		Target:   100,
+		Current:  50,
+	})
+	c := w.Render(gowid.RenderFlowWith{C: 10}, gowid.NotSelected, gwtest.D)
+	mode := gwtest.D.GetColorMode()
+
+	// The gradient spans the whole bar; only the completed part is shown
+	assert.Equal(t, gowid.IColorToTCell(red, gowid.ColorNone, mode), c.CellAt(0, 0).BackgroundColor())
+	assert.Equal(t, gowid.IColorToTCell(gowid.MakeRGBColor("#ffff00"), gowid.ColorNone, mode), c.CellAt(4, 0).BackgroundColor())
+	assert.Equal(t, gowid.ColorNone, c.CellAt(5, 0).BackgroundColor())
+
+	w.SetProgress(gwtest.D, 100)
+	c = w.Render(gowid.RenderFlowWith{C: 10}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, gowid.IColorToTCell(green, gowid.ColorNone, mode), c.CellAt(9, 0).BackgroundColor())
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
@@ -94,6 +94,22 @@ func (w *Widget) IsChecked() bool {
 	return w.Selected
 }
 
+func (w *Widget) AccessibleRole() gowid.Role {
+	return gowid.RoleRadioButton
+}
+
+func (w *Widget) AccessibleLabel(app gowid.IApp) string {
+	return ""
+}
+
+// AccessibleState returns "checked" or "unchecked".
+func (w *Widget) AccessibleState(app gowid.IApp) string {
+	if w.IsChecked() {
+		return "checked"
+	}
+	return "unchecked"
+}
+
 func (w *Widget) Click(app gowid.IApp) {
 	if app.GetMouseState().NoButtonClicked() || app.GetMouseState().LeftIsClicked() {
 		w.Select(app)
@@ -0,0 +1,336 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package regextester provides a widget for trying out a regular expression - a pattern input, toggles
+// for the pattern's flags, and a sample text, with the sample's matches and capture groups highlighted
+// as the user types. Tools that filter with regular expressions - log viewers, packet filters - can
+// embed it to let the user build a filter, then use its compiled Regexp.
+package regextester
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/checkbox"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+)
+
+//======================================================================
+
+// DefaultGroupStyles are used to highlight capture groups, unless Options says otherwise - group 1 with
+// the first, and so on, starting again at the first if there are more groups than styles.
+var DefaultGroupStyles = []gowid.ICellStyler{
+	gowid.MakePaletteEntry(gowid.ColorBlack, gowid.ColorCyan),
+	gowid.MakePaletteEntry(gowid.ColorBlack, gowid.ColorYellow),
+	gowid.MakePaletteEntry(gowid.ColorBlack, gowid.ColorGreen),
+	gowid.MakePaletteEntry(gowid.ColorBlack, gowid.ColorMagenta),
+}
+
+// Flags are the flags the pattern is compiled with, which the user can toggle.
+type Flags struct {
+	CaseInsensitive bool // i - letters match upper and lower case
+	MultiLine       bool // m - ^ and $ match at the beginning and end of each line
+	DotNewline      bool // s - . matches \n
+	Ungreedy        bool // U - swaps the meanings of x* and x*?, x+ and x+?, and so on
+}
+
+// prefix returns the flags as a group to put before the pattern, e.g. "(?im)".
+func (f Flags) prefix() string {
+	res := ""
+	for _, fl := range []struct {
+		on bool
+		c  string
+	}{{f.CaseInsensitive, "i"}, {f.MultiLine, "m"}, {f.DotNewline, "s"}, {f.Ungreedy, "U"}} {
+		if fl.on {
+			res += fl.c
+		}
+	}
+	if res == "" {
+		return ""
+	}
+	return "(?" + res + ")"
+}
+
+// Options is used to configure the widget.
+type Options struct {
+	Pattern     string
+	Flags       Flags
+	Sample      string
+	MatchStyle  gowid.ICellStyler   // The style of each match; defaults to reverse video
+	GroupStyles []gowid.ICellStyler // The styles of the capture groups; defaults to DefaultGroupStyles
+	ErrorStyle  gowid.ICellStyler   // The style of the error for an invalid pattern; defaults to red
+}
+
+// Change is the key for callbacks run when the pattern, its flags or the sample change.
+type Change struct{}
+
+type IWidget interface {
+	gowid.ICompositeWidget
+	Pattern() string
+	Flags() Flags
+	Sample() string
+	Regexp() (*regexp.Regexp, error)
+}
+
+// Widget lays out, from the top, the pattern input, the flag toggles, the sample text, a line saying how
+// many matches there are - or why the pattern is invalid - and the sample again, with each match
+// highlighted, and the capture groups within it highlighted in their own styles.
+type Widget struct {
+	gowid.IWidget
+	opts      Options
+	pattern   *edit.Widget
+	flags     [4]*checkbox.Widget // i, m, s, U
+	sample    *edit.Widget
+	status    *text.Widget
+	preview   *text.Widget
+	re        *regexp.Regexp
+	err       error
+	matches   [][]int
+	setting   bool // True while SetFlags toggles the checkboxes, so that changes are reported once
+	Callbacks *gowid.Callbacks
+}
+
+func New(opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MatchStyle == nil {
+		opt.MatchStyle = gowid.MakeStyledAs(gowid.StyleReverse)
+	}
+	if opt.GroupStyles == nil {
+		opt.GroupStyles = DefaultGroupStyles
+	}
+	if opt.ErrorStyle == nil {
+		opt.ErrorStyle = gowid.MakeForeground(gowid.ColorRed)
+	}
+	res := &Widget{
+		opts:      opt,
+		pattern:   edit.New(edit.Options{Caption: "Pattern: ", Text: opt.Pattern}),
+		sample:    edit.New(edit.Options{Text: opt.Sample}),
+		status:    text.New(""),
+		preview:   text.New(""),
+		Callbacks: gowid.NewCallbacks(),
+	}
+	res.IWidget = res.build()
+	res.update(nil)
+	var _ IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("regextester[%s]", w.Pattern())
+}
+
+func (w *Widget) SubWidget() gowid.IWidget {
+	return w.IWidget
+}
+
+func (w *Widget) SetSubWidget(wi gowid.IWidget, app gowid.IApp) {
+	w.IWidget = wi
+}
+
+func (w *Widget) SubWidgetSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	return size
+}
+
+// build lays out the widget's parts, and has them report their changes.
+func (w *Widget) build() gowid.IWidget {
+	onChange := gowid.MakeWidgetCallback("regextester", func(app gowid.IApp, _ gowid.IWidget) {
+		if !w.setting {
+			w.update(app)
+		}
+	})
+	w.pattern.OnTextSet(onChange)
+	w.sample.OnTextSet(onChange)
+
+	flags := w.opts.Flags
+	on := []bool{flags.CaseInsensitive, flags.MultiLine, flags.DotNewline, flags.Ungreedy}
+	toggles := make([]interface{}, 0, len(w.flags)*2+1)
+	toggles = append(toggles, text.New("Flags: "))
+	for i, label := range []string{" i  ", " m  ", " s  ", " U"} {
+		w.flags[i] = checkbox.New(on[i])
+		w.flags[i].OnClick(onChange)
+		toggles = append(toggles, w.flags[i], text.New(label))
+	}
+
+	return pile.NewFlow(
+		w.pattern,
+		columns.NewFixed(toggles...),
+		text.New("Sample:"),
+		w.sample,
+		w.status,
+		w.preview,
+	)
+}
+
+// Pattern returns the pattern, without its flags.
+func (w *Widget) Pattern() string {
+	return w.pattern.Text()
+}
+
+func (w *Widget) SetPattern(pattern string, app gowid.IApp) {
+	w.pattern.SetText(pattern, app)
+}
+
+func (w *Widget) Flags() Flags {
+	return Flags{
+		CaseInsensitive: w.flags[0].IsChecked(),
+		MultiLine:       w.flags[1].IsChecked(),
+		DotNewline:      w.flags[2].IsChecked(),
+		Ungreedy:        w.flags[3].IsChecked(),
+	}
+}
+
+func (w *Widget) SetFlags(flags Flags, app gowid.IApp) {
+	w.setting = true
+	w.flags[0].SetChecked(app, flags.CaseInsensitive)
+	w.flags[1].SetChecked(app, flags.MultiLine)
+	w.flags[2].SetChecked(app, flags.DotNewline)
+	w.flags[3].SetChecked(app, flags.Ungreedy)
+	w.setting = false
+	w.update(app)
+}
+
+func (w *Widget) Sample() string {
+	return w.sample.Text()
+}
+
+func (w *Widget) SetSample(sample string, app gowid.IApp) {
+	w.sample.SetText(sample, app)
+}
+
+// Expr returns the pattern with its flags, as it is compiled - e.g. "(?i)err.*".
+func (w *Widget) Expr() string {
+	return w.Flags().prefix() + w.Pattern()
+}
+
+// Regexp returns the pattern compiled with its flags, or the error that says why it is invalid. If the
+// pattern is empty, both are nil.
+func (w *Widget) Regexp() (*regexp.Regexp, error) {
+	return w.re, w.err
+}
+
+// Matches returns the matches in the sample, as regexp.FindAllStringSubmatchIndex does.
+func (w *Widget) Matches() [][]int {
+	return w.matches
+}
+
+func (w *Widget) OnChange(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w.Callbacks, Change{}, f)
+}
+
+func (w *Widget) RemoveOnChange(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w.Callbacks, Change{}, f)
+}
+
+// update compiles the pattern, and finds and highlights its matches in the sample. The app is nil when
+// the widget is first built, before it is in an app.
+func (w *Widget) update(app gowid.IApp) {
+	w.re, w.err, w.matches = nil, nil, nil
+	if w.Pattern() != "" {
+		w.re, w.err = regexp.Compile(w.Expr())
+	}
+
+	switch {
+	case w.err != nil:
+		w.status.SetContent(app, text.NewContent([]text.ContentSegment{
+			text.StyledContent(describeError(w.err, w.Flags().prefix()), w.opts.ErrorStyle),
+		}))
+		w.preview.SetText(w.Sample(), app)
+	case w.re == nil:
+		w.status.SetText("", app)
+		w.preview.SetText(w.Sample(), app)
+	default:
+		w.matches = w.re.FindAllStringSubmatchIndex(w.Sample(), -1)
+		w.status.SetText(describeMatches(len(w.matches), w.re.NumSubexp()), app)
+		w.preview.SetContent(app, w.highlight())
+	}
+
+	if app != nil {
+		gowid.RunWidgetCallbacks(w.Callbacks, Change{}, app, w)
+	}
+}
+
+// highlight returns the sample, with the matches in MatchStyle and their groups in the group styles. A
+// group nested in another is drawn over it.
+func (w *Widget) highlight() *text.Content {
+	sample := w.Sample()
+	// For each byte of the sample, -1 if unmatched, 0 if matched, and n if in group n
+	marks := make([]int, len(sample))
+	for i := range marks {
+		marks[i] = -1
+	}
+	for _, m := range w.matches {
+		for g := 0; g*2 < len(m); g++ {
+			if m[g*2] < 0 {
+				continue
+			}
+			for i := m[g*2]; i < m[g*2+1]; i++ {
+				marks[i] = g
+			}
+		}
+	}
+
+	segs := make([]text.ContentSegment, 0)
+	start := 0
+	for i := 1; i <= len(sample); i++ {
+		if i < len(sample) && marks[i] == marks[start] {
+			continue
+		}
+		switch g := marks[start]; {
+		case g < 0:
+			segs = append(segs, text.StringContent(sample[start:i]))
+		case g == 0:
+			segs = append(segs, text.StyledContent(sample[start:i], w.opts.MatchStyle))
+		default:
+			segs = append(segs, text.StyledContent(sample[start:i], w.opts.GroupStyles[(g-1)%len(w.opts.GroupStyles)]))
+		}
+		start = i
+	}
+	return text.NewContent(segs)
+}
+
+func describeMatches(matches int, groups int) string {
+	var res string
+	switch matches {
+	case 0:
+		res = "No matches"
+	case 1:
+		res = "1 match"
+	default:
+		res = fmt.Sprintf("%d matches", matches)
+	}
+	switch groups {
+	case 0:
+	case 1:
+		res += ", 1 group"
+	default:
+		res += fmt.Sprintf(", %d groups", groups)
+	}
+	return res
+}
+
+// describeError explains why the pattern is invalid, quoting the part of the pattern at fault, without
+// the flags the widget added.
+func describeError(err error, prefix string) string {
+	var serr *syntax.Error
+	if errors.As(err, &serr) {
+		return fmt.Sprintf("Invalid pattern: %s: %s", serr.Code, strings.TrimPrefix(serr.Expr, prefix))
+	}
+	return fmt.Sprintf("Invalid pattern: %v", err)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
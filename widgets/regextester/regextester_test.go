@@ -0,0 +1,80 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package regextester
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestRegexTester1(t *testing.T) {
+	w := New(Options{Pattern: "(e)r+", Sample: "Error error"})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 30, Rows: 7})
+	defer sim.Close()
+
+	changes := 0
+	w.OnChange(gowid.MakeWidgetCallback("test", func(app gowid.IApp, _ gowid.IWidget) {
+		changes++
+	}))
+
+	sim.AssertLine(t, 0, "Pattern: (e)r+                ")
+	sim.AssertLine(t, 1, "Flags: [ ] i  [ ] m  [ ] s  [ ")
+	sim.AssertLine(t, 4, "1 match, 1 group              ")
+	sim.AssertLine(t, 5, "Error error                   ")
+	assert.Equal(t, [][]int{{6, 9, 6, 7}}, w.Matches())
+
+	// The group, the rest of the match and the unmatched text are each drawn differently
+	style := func(x int) tcell.Style {
+		_, st := sim.Cell(x, 5)
+		return st
+	}
+	assert.NotEqual(t, style(6), style(7))
+	assert.NotEqual(t, style(7), style(9))
+	assert.NotEqual(t, style(6), style(9))
+	assert.Equal(t, style(0), style(9))
+
+	// Flags are toggled from the keyboard
+	sim.Key(tcell.KeyDown)
+	sim.Rune(' ')
+	assert.Equal(t, Flags{CaseInsensitive: true}, w.Flags())
+	assert.Equal(t, "(?i)(e)r+", w.Expr())
+	sim.AssertLine(t, 4, "2 matches, 1 group            ")
+	assert.Equal(t, 1, changes)
+
+	// An invalid pattern is explained, without the flags
+	w.SetPattern("(e", sim)
+	re, err := w.Regexp()
+	assert.Nil(t, re)
+	assert.Error(t, err)
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 4, "Invalid pattern: missing closi")
+	assert.Nil(t, w.Matches())
+
+	w.SetPattern("", sim)
+	w.SetFlags(Flags{MultiLine: true}, sim)
+	assert.Equal(t, 4, changes)
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 4, "                              ")
+	re, err = w.Regexp()
+	assert.Nil(t, re)
+	assert.NoError(t, err)
+
+	w.SetPattern("^e", sim)
+	w.SetSample("one\ntwo\nend", sim)
+	assert.Equal(t, [][]int{{8, 9}}, w.Matches())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,379 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package report lays long content out on pages of a fixed size, with headers and footers, like a
+// printed report. The widget previews the pages one at a time, and each page can be exported as plain
+// text or with ANSI escape sequences - for tools whose output is pasted or piped elsewhere.
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+//======================================================================
+
+// The size of a page, unless the options say otherwise - a screen's worth.
+var (
+	DefaultPageCols = 80
+	DefaultPageRows = 24
+)
+
+// Decoration returns the header or footer of a page, given its number, counting from 1, and the
+// number of pages. It is rendered as a flow widget at the width of the page, and should be the same
+// height on every page.
+type Decoration func(page, pages int) gowid.IWidget
+
+// Centered returns a Decoration showing a line centered on each page, formatted with the page number and
+// the number of pages - e.g. Centered("Page %d of %d").
+func Centered(format string) Decoration {
+	return func(page, pages int) gowid.IWidget {
+		return text.New(fmt.Sprintf(format, page, pages), text.Options{Align: gowid.HAlignMiddle{}})
+	}
+}
+
+// Options is used to configure the widget.
+type Options struct {
+	PageCols int               // DefaultPageCols if 0
+	PageRows int               // DefaultPageRows if 0, including the header and footer
+	Header   Decoration        // If not nil, at the top of each page
+	Footer   Decoration        // If not nil, at the bottom of each page
+	Paper    gowid.ICellStyler // The page's colors, beneath the content's own - the terminal's if nil
+	Desk     gowid.ICellStyler // The colors around the page in the preview - dark gray if nil
+}
+
+// For callback registration
+type PageCB struct{}
+
+// Widget previews content laid out on pages. Content is rendered as a flow widget at the width of a page
+// and cut into pages, between the header and footer. The preview shows one page, and a status line
+// beneath; PgDn and PgUp, or n and p, turn the pages, Home and End go to the first and last, and the
+// arrow keys and mouse wheel move a page that doesn't fit in the preview.
+type Widget struct {
+	content gowid.IWidget
+	opts    Options
+	page    int // Of the preview, from 0
+	top     int // The first row of the page shown, if it doesn't fit
+	left    int // The first column of the page shown, if it doesn't fit
+	*gowid.Callbacks
+}
+
+var _ gowid.IWidget = (*Widget)(nil)
+
+// New returns a widget that lays content out on pages.
+func New(content gowid.IWidget, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.PageCols <= 0 {
+		opt.PageCols = DefaultPageCols
+	}
+	if opt.PageRows <= 0 {
+		opt.PageRows = DefaultPageRows
+	}
+	if opt.Paper == nil {
+		opt.Paper = gowid.MakeStyledAs(gowid.StyleNone)
+	}
+	if opt.Desk == nil {
+		opt.Desk = gowid.MakePaletteEntry(gowid.ColorWhite, gowid.ColorDarkGray)
+	}
+	return &Widget{
+		content:   content,
+		opts:      opt,
+		Callbacks: gowid.NewCallbacks(),
+	}
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("report[%v]", w.content)
+}
+
+func (w *Widget) Content() gowid.IWidget {
+	return w.content
+}
+
+// SetContent replaces the content laid out, returning the preview to the first page.
+func (w *Widget) SetContent(content gowid.IWidget, app gowid.IApp) {
+	w.content = content
+	w.SetPage(0, app)
+}
+
+// Page returns the page previewed, counting from 0.
+func (w *Widget) Page() int {
+	return w.page
+}
+
+// SetPage previews page, counting from 0, or the nearest page there is.
+func (w *Widget) SetPage(page int, app gowid.IApp) {
+	page = gwutil.Max(0, gwutil.Min(page, w.Pages(app)-1))
+	w.top, w.left = 0, 0
+	if page != w.page {
+		w.page = page
+		gowid.RunWidgetCallbacks(w, PageCB{}, app, w)
+	}
+}
+
+func (w *Widget) NextPage(app gowid.IApp) {
+	w.SetPage(w.page+1, app)
+}
+
+func (w *Widget) PrevPage(app gowid.IApp) {
+	w.SetPage(w.page-1, app)
+}
+
+// OnPage registers a callback run when the preview turns to another page.
+func (w *Widget) OnPage(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w, PageCB{}, f)
+}
+
+func (w *Widget) RemoveOnPage(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w, PageCB{}, f)
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// layout is the content cut into pages.
+type layout struct {
+	body     gowid.ICanvas // The content, at the width of a page
+	header   int           // The rows taken by the header
+	footer   int           // And the footer
+	bodyRows int           // The rows of content on each page
+	pages    int
+}
+
+func (w *Widget) layout(app gowid.IApp) layout {
+	res := layout{
+		header: decorationRows(w.opts.Header, w.opts.PageCols, app),
+		footer: decorationRows(w.opts.Footer, w.opts.PageCols, app),
+	}
+	res.bodyRows = gwutil.Max(1, w.opts.PageRows-res.header-res.footer)
+	res.body = gowid.Render(w.content, gowid.RenderFlowWith{C: w.opts.PageCols}, gowid.NotSelected, app)
+	res.pages = gwutil.Max(1, (res.body.BoxRows()+res.bodyRows-1)/res.bodyRows)
+	return res
+}
+
+func decorationRows(d Decoration, cols int, app gowid.IApp) int {
+	if d == nil {
+		return 0
+	}
+	return gowid.RenderSize(d(1, 1), gowid.RenderFlowWith{C: cols}, gowid.NotSelected, app).BoxRows()
+}
+
+// Pages returns the number of pages the content takes - at least 1, even if there is no content.
+func (w *Widget) Pages(app gowid.IApp) int {
+	return w.layout(app).pages
+}
+
+// RenderPage returns the canvas of page, counting from 0, as it would be printed.
+func (w *Widget) RenderPage(page int, app gowid.IApp) gowid.ICanvas {
+	return w.renderPage(page, w.layout(app), app)
+}
+
+func (w *Widget) renderPage(page int, l layout, app gowid.IApp) gowid.ICanvas {
+	cols, rows := w.opts.PageCols, w.opts.PageRows
+	paper := styleCell(w.opts.Paper, app).WithRune(' ')
+	res := gowid.NewCanvasOfSizeExt(cols, rows, paper)
+
+	draw := func(c gowid.ICanvas, from, n, y int) {
+		for row := 0; row < n && from+row < c.BoxRows() && y+row < rows; row++ {
+			for x := 0; x < cols && x < c.BoxColumns(); x++ {
+				res.SetCellAt(x, y+row, paper.MergeUnder(c.CellAt(x, from+row)))
+			}
+		}
+	}
+	if w.opts.Header != nil {
+		header := gowid.Render(w.opts.Header(page+1, l.pages), gowid.RenderFlowWith{C: cols},
+			gowid.NotSelected, app)
+		draw(header, 0, l.header, 0)
+	}
+	draw(l.body, page*l.bodyRows, l.bodyRows, l.header)
+	if w.opts.Footer != nil {
+		footer := gowid.Render(w.opts.Footer(page+1, l.pages), gowid.RenderFlowWith{C: cols},
+			gowid.NotSelected, app)
+		draw(footer, 0, l.footer, rows-l.footer)
+	}
+	return res
+}
+
+// PageText returns page, counting from 0, as plain text - a line for each row, without trailing spaces.
+func (w *Widget) PageText(page int, app gowid.IApp) string {
+	lines := strings.Split(gowid.SnapshotCanvas(w.RenderPage(page, app)).Text(), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PageANSI returns page, counting from 0, as text with ANSI escape sequences for its colors and styles.
+func (w *Widget) PageANSI(page int, app gowid.IApp) string {
+	return gowid.SnapshotCanvas(w.RenderPage(page, app)).ANSI()
+}
+
+// WriteText writes every page to out as plain text, each followed by a form feed, as printers expect.
+func (w *Widget) WriteText(out io.Writer, app gowid.IApp) error {
+	return w.write(out, w.PageText, app)
+}
+
+// WriteANSI writes every page to out with ANSI escape sequences, each followed by a form feed.
+func (w *Widget) WriteANSI(out io.Writer, app gowid.IApp) error {
+	return w.write(out, w.PageANSI, app)
+}
+
+func (w *Widget) write(out io.Writer, page func(int, gowid.IApp) string, app gowid.IApp) error {
+	for i := 0; i < w.Pages(app); i++ {
+		if _, err := io.WriteString(out, page(i, app)+"\n\f"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+func (w *Widget) Selectable() bool {
+	return true
+}
+
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		switch ev.Key() {
+		case tcell.KeyPgDn:
+			w.NextPage(app)
+		case tcell.KeyPgUp:
+			w.PrevPage(app)
+		case tcell.KeyHome:
+			w.SetPage(0, app)
+		case tcell.KeyEnd:
+			w.SetPage(w.Pages(app)-1, app)
+		case tcell.KeyDown:
+			w.top++
+		case tcell.KeyUp:
+			w.top--
+		case tcell.KeyRight:
+			w.left++
+		case tcell.KeyLeft:
+			w.left--
+		case tcell.KeyRune:
+			switch ev.Rune() {
+			case 'n', ' ':
+				w.NextPage(app)
+			case 'p':
+				w.PrevPage(app)
+			default:
+				return false
+			}
+		default:
+			return false
+		}
+	case *tcell.EventMouse:
+		switch ev.Buttons() {
+		case tcell.WheelDown:
+			w.top++
+		case tcell.WheelUp:
+			w.top--
+		default:
+			return false
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	cols, rows := w.previewSize(size)
+	return gowid.RenderBox{C: cols, R: rows}
+}
+
+// previewSize returns the size of the preview - as big as a page and the status line, unless the
+// size given says otherwise.
+func (w *Widget) previewSize(size gowid.IRenderSize) (int, int) {
+	cols, rows := w.opts.PageCols, w.opts.PageRows+1
+	if c, ok := size.(gowid.IColumns); ok {
+		cols = c.Columns()
+	}
+	if r, ok := size.(gowid.IRows); ok {
+		rows = r.Rows()
+	}
+	return cols, rows
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	cols, rows := w.previewSize(size)
+	l := w.layout(app)
+	if w.page >= l.pages {
+		w.page = l.pages - 1
+	}
+	page := w.renderPage(w.page, l, app)
+
+	res := gowid.NewCanvasOfSizeExt(cols, rows, styleCell(w.opts.Desk, app).WithRune(' '))
+	if rows == 0 {
+		return res
+	}
+
+	// The page, centered if it fits, and otherwise from the rows and columns scrolled to
+	view := rows - 1
+	w.top = gwutil.Max(0, gwutil.Min(w.top, w.opts.PageRows-view))
+	w.left = gwutil.Max(0, gwutil.Min(w.left, w.opts.PageCols-cols))
+	x, y := 0, 0
+	if w.opts.PageCols < cols {
+		x = (cols - w.opts.PageCols) / 2
+	}
+	for row := 0; row < view && w.top+row < w.opts.PageRows; row++ {
+		for col := 0; x+col < cols && w.left+col < w.opts.PageCols; col++ {
+			res.SetCellAt(x+col, y+row, page.CellAt(w.left+col, w.top+row))
+		}
+	}
+
+	status := fmt.Sprintf(" Page %d of %d", w.page+1, l.pages)
+	if w.opts.PageRows > view {
+		status += fmt.Sprintf(", rows %d-%d", w.top+1, gwutil.Min(w.top+view, w.opts.PageRows))
+	}
+	if w.opts.PageCols > cols {
+		status += fmt.Sprintf(", columns %d-%d", w.left+1, w.left+cols)
+	}
+	keys := "PgUp/PgDn "
+	bar := gowid.MakeCell(' ', gowid.ColorNone, gowid.ColorNone, gowid.StyleReverse)
+	for col := 0; col < cols; col++ {
+		res.SetCellAt(col, rows-1, bar)
+	}
+	drawString(res, 0, rows-1, status, bar)
+	if cols-runewidth.StringWidth(keys) > runewidth.StringWidth(status) {
+		drawString(res, cols-runewidth.StringWidth(keys), rows-1, keys, bar)
+	}
+	return res
+}
+
+// drawString draws s on canvas from x, y in the style of cell.
+func drawString(canvas gowid.ICanvas, x, y int, s string, cell gowid.Cell) {
+	for _, r := range s {
+		if x >= canvas.BoxColumns() {
+			break
+		}
+		canvas.SetCellAt(x, y, cell.WithRune(r))
+		x += gwutil.Max(1, runewidth.RuneWidth(r))
+	}
+}
+
+func styleCell(styler gowid.ICellStyler, app gowid.IApp) gowid.Cell {
+	fgCol, bgCol, style := styler.GetStyle(app)
+	mode := app.GetColorMode()
+	return gowid.MakeCell(0, gowid.IColorToTCell(fgCol, gowid.ColorNone, mode),
+		gowid.IColorToTCell(bgCol, gowid.ColorNone, mode), style)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,86 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestReport1(t *testing.T) {
+	lines := make([]string, 0)
+	for i := 1; i <= 10; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	w := New(text.New(strings.Join(lines, "\n")), Options{
+		PageCols: 12,
+		PageRows: 5,
+		Header: func(page, pages int) gowid.IWidget {
+			return text.New("Sales")
+		},
+		Footer: Centered("%d/%d"),
+	})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 16, Rows: 7})
+	defer sim.Close()
+
+	// Three lines of content fit between the header and footer
+	assert.Equal(t, 4, w.Pages(sim))
+	assert.Equal(t, "Sales\nline 1\nline 2\nline 3\n    1/4", w.PageText(0, sim))
+	assert.Equal(t, "Sales\nline 10\n\n\n    4/4", w.PageText(3, sim))
+	assert.Contains(t, w.PageANSI(1, sim), "line 4")
+
+	var out bytes.Buffer
+	assert.NoError(t, w.WriteText(&out, sim))
+	assert.Equal(t, 4, strings.Count(out.String(), "\f"))
+	assert.True(t, strings.HasPrefix(out.String(), "Sales\nline 1\n"))
+
+	// The page is centered in the preview, above the status line
+	sim.AssertLine(t, 0, "  Sales         ")
+	sim.AssertLine(t, 4, "      1/4       ")
+	sim.AssertLine(t, 5, "                ")
+	sim.AssertLine(t, 6, " Page 1 of 4    ")
+
+	turned := 0
+	w.OnPage(gowid.WidgetCallback{Name: "test", WidgetChangedFunction: func(app gowid.IApp, wi gowid.IWidget) {
+		turned++
+	}})
+	sim.Key(tcell.KeyPgDn)
+	sim.AssertLine(t, 1, "  line 4        ")
+	sim.Key(tcell.KeyEnd)
+	assert.Equal(t, 3, w.Page())
+	sim.AssertLine(t, 6, " Page 4 of 4    ")
+	// Past the last page, nothing happens
+	sim.Rune('n')
+	assert.Equal(t, 3, w.Page())
+	sim.Rune('p')
+	sim.Key(tcell.KeyHome)
+	assert.Equal(t, 0, w.Page())
+	assert.Equal(t, 4, turned)
+
+	// A page that doesn't fit can be scrolled
+	sim.Resize(24, 4)
+	sim.AssertLine(t, 3, " Page 1 of 4, rows 1-3  ")
+	sim.Key(tcell.KeyDown)
+	sim.Key(tcell.KeyDown)
+	sim.Key(tcell.KeyDown)
+	sim.AssertLine(t, 0, "      line 2            ")
+	sim.AssertLine(t, 2, "          1/4           ")
+	sim.AssertLine(t, 3, " Page 1 of 4, rows 3-5  ")
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,352 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package scrollbar provides a widget that draws scrollbars beside another widget, showing how far it is
+// scrolled, and scrolling it when they are clicked or dragged. The widget inside must implement
+// gowid.IScrollValues, as the list, text, table and frozen widgets do.
+package scrollbar
+
+import (
+	"fmt"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+var (
+	// VerticalRunes are the trough and handle of a vertical scrollbar; when drawing with ASCII only, they
+	// are '|' and '#'.
+	VerticalRunes = Runes{Trough: '│', Handle: '█'}
+	// HorizontalRunes are the trough and handle of a horizontal scrollbar; when drawing with ASCII only,
+	// they are '-' and '#'.
+	HorizontalRunes = Runes{Trough: '─', Handle: '█'}
+)
+
+// Runes are drawn for the parts of a scrollbar.
+type Runes struct {
+	Trough, Handle rune
+}
+
+// Options is used to configure the widget.
+type Options struct {
+	Vertical    bool              // Draw a scrollbar to the right; if neither is set, this is the default
+	Horizontal  bool              // Draw a scrollbar beneath
+	TroughStyle gowid.ICellStyler // The style of the part of the bar not covered by the handle
+	HandleStyle gowid.ICellStyler // The style of the handle, which shows the part of the content in view
+}
+
+type IScrollbar interface {
+	Options() Options
+}
+
+type IWidget interface {
+	gowid.ICompositeWidget
+	IScrollbar
+}
+
+// Widget wraps a widget that scrolls, and shows its position with scrollbars. Clicking a bar scrolls the
+// widget so that the handle is centered where it was clicked, and dragging the handle scrolls it along.
+// Other input goes to the widget inside. If the widget inside doesn't implement gowid.IScrollValues, or
+// doesn't scroll at the size it's rendered, the handle fills the bar.
+type Widget struct {
+	gowid.IWidget
+	opts Options
+	drag *drag
+	*gowid.Callbacks
+	gowid.SubWidgetCallbacks
+}
+
+// drag is a scrollbar's handle being dragged.
+type drag struct {
+	o    gowid.Orientation
+	grab int // The distance from the start of the handle to the pointer
+}
+
+func New(inner gowid.IWidget, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if !opt.Vertical && !opt.Horizontal {
+		opt.Vertical = true
+	}
+	res := &Widget{
+		IWidget: inner,
+		opts:    opt,
+	}
+	res.SubWidgetCallbacks = gowid.SubWidgetCallbacks{CB: &res.Callbacks}
+	var _ IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("scrollbar[%v]", w.SubWidget())
+}
+
+func (w *Widget) SubWidget() gowid.IWidget {
+	return w.IWidget
+}
+
+func (w *Widget) SetSubWidget(wi gowid.IWidget, app gowid.IApp) {
+	w.IWidget = wi
+	gowid.RunWidgetCallbacks(w.Callbacks, gowid.SubWidgetCB{}, app, w)
+}
+
+func (w *Widget) Options() Options {
+	return w.opts
+}
+
+func (w *Widget) Selectable() bool {
+	return w.SubWidget().Selectable()
+}
+
+func (w *Widget) SubWidgetSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	return SubWidgetSize(w, size, focus, app)
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	return RenderSize(w, size, focus, app)
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	return Render(w, size, focus, app)
+}
+
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	ss := w.SubWidgetSize(size, focus, app)
+	cols, rows := barSpace(w)
+
+	evm, ok := ev.(*tcell.EventMouse)
+	if !ok {
+		return gowid.UserInputIfSelectable(w.SubWidget(), ev, ss, focus, app)
+	}
+
+	box := w.RenderSize(size, focus, app)
+	width, height := box.BoxColumns()-cols, box.BoxRows()-rows
+	mx, my := evm.Position()
+
+	if w.drag != nil {
+		if evm.Buttons()&tcell.Button1 == 0 {
+			w.drag = nil
+			gowid.ReleaseInput(app)
+			return true
+		}
+		pos := my
+		if w.drag.o == gowid.Horizontal {
+			pos = mx
+		}
+		w.scrollToHandle(w.drag.o, pos-w.drag.grab, size, focus, app)
+		return true
+	}
+
+	var o gowid.Orientation
+	var pos int
+	switch {
+	case mx < width && my < height:
+		return gowid.UserInputIfSelectable(w.SubWidget(), ev, ss, focus, app)
+	case cols > 0 && mx == width && my < height:
+		o, pos = gowid.Vertical, my
+	case rows > 0 && my == height && mx < width:
+		o, pos = gowid.Horizontal, mx
+	default:
+		return false
+	}
+
+	switch evm.Buttons() {
+	case tcell.Button1:
+		sv, ok := w.scrollValues(o, size, focus, app)
+		if !ok || !sv.CanScroll() {
+			return true
+		}
+		n := height
+		if o == gowid.Horizontal {
+			n = width
+		}
+		length, start := Handle(sv, n)
+		grab := length / 2
+		if pos >= start && pos < start+length {
+			grab = pos - start
+		}
+		w.scrollToHandle(o, pos-grab, size, focus, app)
+		w.drag = &drag{o: o, grab: grab}
+		gowid.GrabInput(app, w, gowid.GrabOptions{
+			NoClickOutsideRelease: true,
+			OnRelease: func(app gowid.IApp) {
+				w.drag = nil
+			},
+		})
+		return true
+	case tcell.WheelUp, tcell.WheelDown, tcell.WheelLeft, tcell.WheelRight:
+		// Scroll the widget inside, as if the wheel were turned over its edge
+		return gowid.UserInputIfSelectable(w.SubWidget(),
+			gowid.TranslatedMouseEvent(ev, gwutil.Min(0, width-1-mx), gwutil.Min(0, height-1-my)), ss, focus, app)
+	}
+	return false
+}
+
+// scrollValues returns how far the widget inside is scrolled along o, rendered inside w at size.
+func (w *Widget) scrollValues(o gowid.Orientation, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) (gowid.ScrollValues, bool) {
+	if sv, ok := w.SubWidget().(gowid.IScrollValues); ok {
+		return sv.ScrollValues(o, w.SubWidgetSize(size, focus, app), focus, app)
+	}
+	return gowid.ScrollValues{}, false
+}
+
+// scrollToHandle scrolls the widget inside so that the handle of the bar along o starts at pos.
+func (w *Widget) scrollToHandle(o gowid.Orientation, pos int, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) {
+	sv, ok := w.scrollValues(o, size, focus, app)
+	if !ok || !sv.CanScroll() {
+		return
+	}
+	cols, rows := barSpace(w)
+	box := w.RenderSize(size, focus, app)
+	n := box.BoxRows() - rows
+	if o == gowid.Horizontal {
+		n = box.BoxColumns() - cols
+	}
+	w.SubWidget().(gowid.IScrollValues).ScrollTo(o, Offset(sv, n, pos), w.SubWidgetSize(size, focus, app), focus, app)
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// Handle returns the length of the handle of a bar n cells long, showing sv, and where along the bar it
+// starts.
+func Handle(sv gowid.ScrollValues, n int) (int, int) {
+	if !sv.CanScroll() || n <= 1 {
+		return n, 0
+	}
+	length := gwutil.Max(1, gwutil.Min(n-1, divRound(n*sv.Visible, sv.Total)))
+	start := divRound((n-length)*sv.Offset, sv.Total-sv.Visible)
+	return length, gwutil.Max(0, gwutil.Min(start, n-length))
+}
+
+// Offset returns the offset at which content, scrolled as sv says, puts the handle of a bar n cells long
+// at pos - the inverse of Handle.
+func Offset(sv gowid.ScrollValues, n int, pos int) int {
+	length, _ := Handle(sv, n)
+	if length >= n {
+		return 0
+	}
+	pos = gwutil.Max(0, gwutil.Min(pos, n-length))
+	return divRound(pos*(sv.Total-sv.Visible), n-length)
+}
+
+func divRound(a, b int) int {
+	return (a + b/2) / b
+}
+
+// barSpace returns the columns and rows taken by w's scrollbars.
+func barSpace(w IScrollbar) (int, int) {
+	cols, rows := 0, 0
+	if w.Options().Vertical {
+		cols = 1
+	}
+	if w.Options().Horizontal {
+		rows = 1
+	}
+	return cols, rows
+}
+
+// SubWidgetSize returns the size at which the widget inside is rendered - the size given, less the space
+// for the scrollbars.
+func SubWidgetSize(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	cols, rows := barSpace(w)
+	switch sz := size.(type) {
+	case gowid.IRenderBox:
+		return gowid.RenderBox{C: gwutil.Max(0, sz.BoxColumns()-cols), R: gwutil.Max(0, sz.BoxRows()-rows)}
+	case gowid.IRenderFlowWith:
+		return gowid.RenderFlowWith{C: gwutil.Max(0, sz.FlowColumns()-cols)}
+	default:
+		return size
+	}
+}
+
+func RenderSize(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	if box, ok := size.(gowid.IRenderBox); ok {
+		return gowid.RenderBox{C: box.BoxColumns(), R: box.BoxRows()}
+	}
+	cols, rows := barSpace(w)
+	inner := w.SubWidget().RenderSize(w.SubWidgetSize(size, focus, app), focus, app)
+	return gowid.RenderBox{C: inner.BoxColumns() + cols, R: inner.BoxRows() + rows}
+}
+
+func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	ss := w.SubWidgetSize(size, focus, app)
+	res := gowid.Render(w.SubWidget(), ss, focus, app)
+	width, height := res.BoxColumns(), res.BoxRows()
+
+	sv, _ := w.SubWidget().(gowid.IScrollValues)
+	values := func(o gowid.Orientation) gowid.ScrollValues {
+		if sv != nil {
+			if v, ok := sv.ScrollValues(o, ss, focus, app); ok {
+				return v
+			}
+		}
+		return gowid.ScrollValues{}
+	}
+
+	opts := w.Options()
+	trough, handle := gowid.CellFromRune(' '), gowid.CellFromRune(' ')
+	if opts.TroughStyle != nil {
+		trough = styleCell(opts.TroughStyle, app)
+	}
+	if opts.HandleStyle != nil {
+		handle = styleCell(opts.HandleStyle, app)
+	}
+
+	if opts.Vertical {
+		runes := Runes{
+			Trough: gowid.ASCIIRune(app, VerticalRunes.Trough, '|'),
+			Handle: gowid.ASCIIRune(app, VerticalRunes.Handle, '#'),
+		}
+		bar := gowid.NewCanvasWithLines(nil)
+		for _, c := range barCells(values(gowid.Vertical), height, runes, trough, handle) {
+			bar.AppendLine([]gowid.Cell{c}, false)
+		}
+		res.AppendRight(bar, false)
+	}
+	if opts.Horizontal {
+		runes := Runes{
+			Trough: gowid.ASCIIRune(app, HorizontalRunes.Trough, '-'),
+			Handle: gowid.ASCIIRune(app, HorizontalRunes.Handle, '#'),
+		}
+		line := barCells(values(gowid.Horizontal), width, runes, trough, handle)
+		if opts.Vertical {
+			line = append(line, gowid.CellFromRune(' '))
+		}
+		res.AppendBelow(gowid.NewCanvasWithLines([][]gowid.Cell{line}), false, false)
+	}
+
+	return res
+}
+
+// barCells returns the cells of a bar n cells long, showing sv.
+func barCells(sv gowid.ScrollValues, n int, runes Runes, trough gowid.Cell, handle gowid.Cell) []gowid.Cell {
+	length, start := Handle(sv, n)
+	res := make([]gowid.Cell, n)
+	for i := range res {
+		if i >= start && i < start+length {
+			res[i] = handle.WithRune(runes.Handle)
+		} else {
+			res[i] = trough.WithRune(runes.Trough)
+		}
+	}
+	return res
+}
+
+func styleCell(styler gowid.ICellStyler, app gowid.IApp) gowid.Cell {
+	fgCol, bgCol, style := styler.GetStyle(app)
+	mode := app.GetColorMode()
+	return gowid.MakeCell(0, gowid.IColorToTCell(fgCol, gowid.ColorNone, mode),
+		gowid.IColorToTCell(bgCol, gowid.ColorNone, mode), style)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
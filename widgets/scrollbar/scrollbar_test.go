@@ -0,0 +1,60 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package scrollbar
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestHandle1(t *testing.T) {
+	sv := gowid.ScrollValues{Total: 100, Offset: 0, Visible: 10}
+	l, s := Handle(sv, 10)
+	assert.Equal(t, 1, l)
+	assert.Equal(t, 0, s)
+	sv.Offset = 90
+	_, s = Handle(sv, 10)
+	assert.Equal(t, 9, s)
+	assert.Equal(t, 90, Offset(sv, 10, 9))
+	assert.Equal(t, 0, Offset(sv, 10, -3))
+
+	l, s = Handle(gowid.ScrollValues{Total: 5, Visible: 10}, 10)
+	assert.Equal(t, 10, l)
+	assert.Equal(t, 0, s)
+}
+
+func TestScrollbar1(t *testing.T) {
+	txt := text.New("a\nb\nc\nd\ne\nf")
+	w := New(txt)
+	sz := gowid.RenderBox{C: 2, R: 3}
+
+	c := w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "a█\nb█\nc│", c.String())
+
+	// Clicking the bottom of the bar centers the handle there
+	assert.True(t, w.UserInput(tcell.NewEventMouse(1, 2, tcell.Button1, 0), sz, gowid.Focused, gwtest.D))
+	assert.True(t, w.UserInput(tcell.NewEventMouse(1, 2, tcell.ButtonNone, 0), sz, gowid.Focused, gwtest.D))
+	c = w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "d│\ne█\nf█", c.String())
+
+	// Dragging the handle back up
+	assert.True(t, w.UserInput(tcell.NewEventMouse(1, 2, tcell.Button1, 0), sz, gowid.Focused, gwtest.D))
+	assert.True(t, w.UserInput(tcell.NewEventMouse(1, 0, tcell.Button1, 0), sz, gowid.Focused, gwtest.D))
+	assert.True(t, w.UserInput(tcell.NewEventMouse(1, 0, tcell.ButtonNone, 0), sz, gowid.Focused, gwtest.D))
+	c = w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "a█\nb█\nc│", c.String())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
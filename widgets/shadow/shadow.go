@@ -34,14 +34,34 @@ type Widget struct {
 	offset int // Means y offset, x is 2*y because cells are not squares -
 	// we just guess at a reasonable look for a reasonable
 	// aspect ratio
+	opts Options
 	*gowid.Callbacks
 	gowid.SubWidgetCallbacks
 }
 
-func New(inner gowid.IWidget, offset int) *Widget {
+// Options can make the shadow translucent, so that what is beneath the widget shows through the shadow,
+// darkened, rather than being hidden by it.
+type Options struct {
+	// Opacity, if between 0 and 1, is how much the shadow hides what is beneath it - see
+	// gowid.Cell.WithOpacity. It takes effect when the widget is laid over another, e.g. by an
+	// overlay. 0, the default, means opaque.
+	Opacity float64
+}
+
+// IOpacity is implemented by a shadow that may be translucent.
+type IOpacity interface {
+	Opacity() float64
+}
+
+func New(inner gowid.IWidget, offset int, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
 	res := &Widget{
 		IWidget: inner,
 		offset:  offset,
+		opts:    opt,
 	}
 	res.SubWidgetCallbacks = gowid.SubWidgetCallbacks{CB: &res.Callbacks}
 	var _ gowid.ICompositeWidget = res
@@ -70,6 +90,14 @@ func (w *Widget) SetOffset(x int, app gowid.IApp) {
 	w.offset = x
 }
 
+func (w *Widget) Opacity() float64 {
+	return w.opts.Opacity
+}
+
+func (w *Widget) SetOpacity(opacity float64, app gowid.IApp) {
+	w.opts.Opacity = opacity
+}
+
 func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
 	return UserInput(w, ev, size, focus, app)
 }
@@ -105,10 +133,14 @@ func UserInput(w gowid.ICompositeWidget, ev interface{}, size gowid.IRenderSize,
 
 func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
 	newSize := w.SubWidgetSize(size, focus, app)
-	innerCanvas := w.SubWidget().Render(newSize, focus, app)
+	innerCanvas := gowid.Render(w.SubWidget(), newSize, focus, app)
 
-	shadowCanvas := gowid.NewCanvasOfSizeExt(innerCanvas.BoxColumns(), innerCanvas.BoxRows(),
-		gowid.MakeCell(' ', gowid.MakeTCellColorExt(tcell.ColorDefault), gowid.MakeTCellColorExt(tcell.ColorBlack), gowid.StyleNone))
+	shadowCell := gowid.MakeCell(' ', gowid.MakeTCellColorExt(tcell.ColorDefault), gowid.MakeTCellColorExt(tcell.ColorBlack), gowid.StyleNone)
+	if ow, ok := w.(IOpacity); ok && ow.Opacity() > 0 && ow.Opacity() < 1 {
+		// No rune or foreground, so the text beneath shows through
+		shadowCell = gowid.MakeCell(0, gowid.ColorNone, gowid.MakeTCellColorExt(tcell.ColorBlack), gowid.StyleNone).WithOpacity(ow.Opacity())
+	}
+	shadowCanvas := gowid.NewCanvasOfSizeExt(innerCanvas.BoxColumns(), innerCanvas.BoxRows(), shadowCell)
 
 	shadowCanvas.ExtendLeft(gowid.EmptyLine(w.Offset() * 2))
 
@@ -0,0 +1,311 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package sparkline provides a widget that draws a series of samples as a compact chart, with block or
+// braille characters - e.g. to show the recent history of a measurement in a monitoring app.
+package sparkline
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/legend"
+)
+
+//======================================================================
+
+// DefaultCapacity is the number of samples a sparkline keeps, unless Options says otherwise.
+var DefaultCapacity = 1024
+
+// Threshold styles the samples at or above Value, until the next threshold - e.g. to draw high readings
+// in red.
+type Threshold struct {
+	Value float64
+	Style gowid.ICellStyler
+}
+
+// Options is used to configure the widget.
+type Options struct {
+	// Min and Max are the values at the bottom and top of the chart. If they are equal, the chart is
+	// scaled to the samples shown.
+	Min, Max float64
+	// Braille, if true, draws two samples to a cell, with four levels per row, using braille characters.
+	// Otherwise, each cell draws a sample with eight levels per row, using block characters.
+	Braille    bool
+	Height     int               // The number of rows, rendered as a flow or fixed widget; defaults to 1
+	Capacity   int               // The number of samples kept; defaults to DefaultCapacity
+	Style      gowid.ICellStyler // The style of samples below every threshold; if nil, they are unstyled
+	Thresholds []Threshold       // In ascending order of Value
+	// Legend, if not nil, treats the sparkline as series Series of a group - e.g. one of a pile of
+	// sparklines sharing a legend.Widget. While the series is hidden, nothing is drawn; while another is
+	// highlighted, the samples are drawn in legend.DimColor. Otherwise the legend's color for the series
+	// is used for samples below every threshold.
+	Legend legend.ISeries
+	Series int
+}
+
+// Widget draws the latest of the samples appended to it, oldest on the left, scaled between Options.Min
+// and Options.Max. Samples are kept in a ring of Options.Capacity, so appending doesn't allocate. A NaN
+// sample is drawn as a gap.
+type Widget struct {
+	opts    Options
+	samples []float64
+	start   int // Index of the oldest sample
+	n       int // Number of samples
+	gowid.RejectUserInput
+	gowid.NotSelectable
+}
+
+func New(opts Options) *Widget {
+	if opts.Height <= 0 {
+		opts.Height = 1
+	}
+	if opts.Capacity <= 0 {
+		opts.Capacity = DefaultCapacity
+	}
+	res := &Widget{
+		opts:    opts,
+		samples: make([]float64, opts.Capacity),
+	}
+	var _ gowid.IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("sparkline[%d]", w.n)
+}
+
+// Append adds samples to the right of the chart. Once there are Options.Capacity samples, the oldest are
+// dropped. It must be called on the app goroutine - from another, use app.Run.
+func (w *Widget) Append(values ...float64) {
+	for _, v := range values {
+		c := len(w.samples)
+		if w.n < c {
+			w.samples[(w.start+w.n)%c] = v
+			w.n++
+		} else {
+			w.samples[w.start] = v
+			w.start = (w.start + 1) % c
+		}
+	}
+}
+
+// SetSamples replaces the samples, keeping the latest Options.Capacity.
+func (w *Widget) SetSamples(values []float64) {
+	w.Clear()
+	w.Append(values...)
+}
+
+// Clear removes every sample.
+func (w *Widget) Clear() {
+	w.start, w.n = 0, 0
+}
+
+// Samples returns a copy of the samples, oldest first.
+func (w *Widget) Samples() []float64 {
+	return w.latest(w.n)
+}
+
+// Len returns the number of samples.
+func (w *Widget) Len() int {
+	return w.n
+}
+
+// latest returns the last n samples, oldest first.
+func (w *Widget) latest(n int) []float64 {
+	n = gwutil.Min(n, w.n)
+	res := make([]float64, n)
+	for i := range res {
+		res[i] = w.samples[(w.start+w.n-n+i)%len(w.samples)]
+	}
+	return res
+}
+
+// Range returns the values at the bottom and top of the chart, drawing samples - from Options, or the
+// least and greatest of samples if Options.Min and Options.Max are equal.
+func (w *Widget) Range(samples []float64) (float64, float64) {
+	if w.opts.Min != w.opts.Max {
+		return w.opts.Min, w.opts.Max
+	}
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range samples {
+		if !math.IsNaN(v) {
+			min, max = math.Min(min, v), math.Max(max, v)
+		}
+	}
+	if math.IsInf(min, 1) {
+		return 0, 0
+	}
+	return min, max
+}
+
+// Style returns the style of a sample, from the thresholds it is at or above.
+func (w *Widget) Style(v float64) gowid.ICellStyler {
+	res := w.opts.Style
+	for _, t := range w.opts.Thresholds {
+		if v >= t.Value {
+			res = t.Style
+		}
+	}
+	return res
+}
+
+// cell returns a blank cell styled for a sample - see Options.Thresholds and Options.Legend.
+func (w *Widget) cell(v float64, app gowid.IApp) gowid.Cell {
+	res := gowid.CellFromRune(' ')
+	if style := w.Style(v); style != nil {
+		res = styleCell(style, app)
+	}
+	l, i := w.opts.Legend, w.opts.Series
+	if l == nil || i < 0 || i >= l.SeriesCount() {
+		return res
+	}
+	above := len(w.opts.Thresholds) > 0 && v >= w.opts.Thresholds[0].Value
+	if hl := l.Highlighted(); above && (hl == -1 || hl == i) {
+		return res
+	}
+	return res.WithForegroundColor(gowid.IColorToTCell(l.SeriesColor(i), gowid.ColorNone, app.GetColorMode()))
+}
+
+// perCell returns the number of samples drawn in each cell.
+func (w *Widget) perCell(app gowid.IApp) int {
+	if w.opts.Braille && !gowid.ASCIIOnly(app) {
+		return 2
+	}
+	return 1
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	cols, rows := w.size(size, app)
+	return gowid.RenderBox{C: cols, R: rows}
+}
+
+func (w *Widget) size(size gowid.IRenderSize, app gowid.IApp) (int, int) {
+	switch sz := size.(type) {
+	case gowid.IRenderBox:
+		return sz.BoxColumns(), sz.BoxRows()
+	case gowid.IRenderFlowWith:
+		return sz.FlowColumns(), w.opts.Height
+	default:
+		per := w.perCell(app)
+		return (w.n + per - 1) / per, w.opts.Height
+	}
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	cols, rows := w.size(size, app)
+	res := gowid.NewCanvasOfSize(cols, rows)
+	if cols <= 0 || rows <= 0 || legend.SeriesHidden(w.opts.Legend, w.opts.Series) {
+		return res
+	}
+	per := w.perCell(app)
+	samples := w.latest(cols * per)
+	min, max := w.Range(samples)
+
+	// The number of levels a sample fills, from 1 for min to all of them for max; 0 for a gap
+	levelsPerRow := 8
+	if per == 2 {
+		levelsPerRow = 4
+	}
+	levels := levelsPerRow * rows
+	level := func(v float64) int {
+		switch {
+		case math.IsNaN(v):
+			return 0
+		case max <= min:
+			return 1
+		}
+		frac := math.Max(0, math.Min(1, (v-min)/(max-min)))
+		return 1 + int(frac*float64(levels-1)+0.5)
+	}
+
+	// Samples are drawn against the right edge
+	x0 := cols - (len(samples)+per-1)/per
+	for i := 0; i*per < len(samples); i++ {
+		cell := samples[i*per : gwutil.Min(len(samples), (i+1)*per)]
+		if per == 2 && len(samples)%2 == 1 {
+			// An odd sample out is drawn on its own, in the right of the first cell
+			if i == 0 {
+				cell = []float64{math.NaN(), samples[0]}
+			} else {
+				cell = samples[i*2-1 : i*2+1]
+			}
+		}
+		peak := math.NaN()
+		for _, v := range cell {
+			if !math.IsNaN(v) && (math.IsNaN(peak) || v > peak) {
+				peak = v
+			}
+		}
+		if math.IsNaN(peak) {
+			continue
+		}
+		base := w.cell(peak, app)
+		for row := 0; row < rows; row++ {
+			var r rune
+			if per == 2 {
+				r = brailleCell(level(cell[0]), level(cell[1]), row)
+			} else {
+				r = blocks(app)[gwutil.Min(8, gwutil.Max(0, level(cell[0])-row*8))]
+			}
+			if r != ' ' {
+				res.SetCellAt(x0+i, rows-1-row, base.WithRune(r))
+			}
+		}
+	}
+	return res
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+var (
+	// Partial blocks, from an eighth of a cell to a full one
+	verticalBlocks = []rune(" ▁▂▃▄▅▆▇█")
+	// Drawn instead if widgets should draw with ASCII only - see App.SetASCIIOnly
+	asciiBlocks = []rune(" ...::::#")
+	// The braille dots of the left and right columns of a cell, from the bottom up
+	brailleLeft  = []rune{0x40, 0x04, 0x02, 0x01}
+	brailleRight = []rune{0x80, 0x20, 0x10, 0x08}
+)
+
+// blocks returns the partial blocks to draw with, unless widgets should draw with ASCII only.
+func blocks(app gowid.IApp) []rune {
+	if gowid.ASCIIOnly(app) {
+		return asciiBlocks
+	}
+	return verticalBlocks
+}
+
+// brailleCell returns the braille character for the row'th row from the bottom, filled to left and
+// right levels in its two columns, with four levels per row.
+func brailleCell(left, right int, row int) rune {
+	res := rune(0x2800)
+	for i := 0; i < 4; i++ {
+		if left > row*4+i {
+			res |= brailleLeft[i]
+		}
+		if right > row*4+i {
+			res |= brailleRight[i]
+		}
+	}
+	if res == 0x2800 {
+		return ' '
+	}
+	return res
+}
+
+func styleCell(styler gowid.ICellStyler, app gowid.IApp) gowid.Cell {
+	fgCol, bgCol, style := styler.GetStyle(app)
+	mode := app.GetColorMode()
+	return gowid.MakeCell(0, gowid.IColorToTCell(fgCol, gowid.ColorNone, mode),
+		gowid.IColorToTCell(bgCol, gowid.ColorNone, mode), style)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,90 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package sparkline
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/legend"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestSparkline1(t *testing.T) {
+	w := New(Options{Min: 0, Max: 7, Capacity: 8})
+	w.Append(0, 1, 2, 3, 4, 5, 6, 7)
+	c := w.Render(gowid.RenderFlowWith{C: 8}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "▁▂▃▄▅▆▇█", c.String())
+
+	// The oldest samples are dropped; a gap is blank, and samples are drawn against the right edge
+	w.Append(math.NaN(), 7)
+	assert.Equal(t, 8, w.Len())
+	assert.Equal(t, []float64{2, 3, 4, 5, 6, 7}, w.Samples()[:6])
+	c = w.Render(gowid.RenderFlowWith{C: 10}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "  ▃▄▅▆▇█ █", c.String())
+
+	// Two rows, scaled to the samples
+	w = New(Options{Height: 2})
+	w.SetSamples([]float64{10, 20, 30})
+	c = w.Render(gowid.RenderFixed{}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, " ▁█\n▁██", c.String())
+}
+
+func TestBraille1(t *testing.T) {
+	w := New(Options{Min: 0, Max: 3, Braille: true})
+	w.Append(0, 1, 2, 3, 3)
+	c := w.Render(gowid.RenderFlowWith{C: 3}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "⢀⣴⣿", c.String())
+}
+
+func TestThresholds1(t *testing.T) {
+	red := gowid.MakePaletteRef("red")
+	w := New(Options{Thresholds: []Threshold{{Value: 5, Style: red}}})
+	assert.Nil(t, w.Style(4))
+	assert.Equal(t, red, w.Style(5))
+	assert.Equal(t, red, w.Style(9))
+}
+
+func TestLegend1(t *testing.T) {
+	l := legend.New([]legend.Entry{{Name: "cpu", Color: gowid.ColorBlue}, {Name: "disk", Color: gowid.ColorGreen}})
+	high := []Threshold{{Value: 6, Style: gowid.MakeForeground(gowid.ColorRed)}}
+	cpu := New(Options{Min: 0, Max: 7, Legend: l, Series: 0, Thresholds: high})
+	cpu.Append(1, 7)
+	disk := New(Options{Min: 0, Max: 7, Legend: l, Series: 1})
+	disk.Append(3, 4)
+	sz := gowid.RenderFlowWith{C: 2}
+	color := func(c gowid.IColor) gowid.TCellColor {
+		return gowid.IColorToTCell(c, gowid.ColorNone, gwtest.D.GetColorMode())
+	}
+
+	// The legend colors the samples below every threshold
+	c := cpu.Render(sz, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "▂█", c.String())
+	assert.Equal(t, color(gowid.ColorBlue), c.CellAt(0, 0).ForegroundColor())
+	assert.Equal(t, color(gowid.ColorRed), c.CellAt(1, 0).ForegroundColor())
+
+	// Highlighting another series dims every sample, even those above a threshold
+	l.SetHighlighted(1, gwtest.D)
+	c = cpu.Render(sz, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, color(legend.DimColor), c.CellAt(0, 0).ForegroundColor())
+	assert.Equal(t, color(legend.DimColor), c.CellAt(1, 0).ForegroundColor())
+	c = disk.Render(sz, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, color(gowid.ColorGreen), c.CellAt(0, 0).ForegroundColor())
+
+	// Hiding a series blanks it
+	l.SetHighlighted(-1, gwtest.D)
+	l.SetHidden(1, true, gwtest.D)
+	c = disk.Render(sz, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "  ", c.String())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
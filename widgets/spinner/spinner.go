@@ -8,11 +8,13 @@ package spinner
 import (
 	"fmt"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/gcla/gowid"
 	"github.com/gcla/gowid/widgets/styled"
 	"github.com/gcla/gowid/widgets/text"
+	"github.com/mattn/go-runewidth"
 )
 
 //======================================================================
@@ -36,13 +38,21 @@ type IWidget interface {
 	Styler() gowid.ICellStyler
 }
 
+// IFrames is implemented by a spinner that cycles through a set of frames, like FramesBraille, rather
+// than drawing a wave across its width.
+type IFrames interface {
+	// Frames returns the frames to draw, or nil to draw a wave.
+	Frames() []string
+}
+
 // Widget is the concrete type of a progressbar widget.
 type Widget struct {
 	enabled   bool
 	label     string
 	idx       int
-	ticker    *time.Ticker
-	stopChan  chan struct{}
+	frames    FrameSet
+	interval  time.Duration
+	timer     *gowid.Timer
 	styler    gowid.ICellStyler
 	Callbacks *gowid.Callbacks
 	gowid.RejectUserInput
@@ -56,6 +66,16 @@ type ChangeStateCB struct{}
 
 var wave []rune
 
+// asciiWave is drawn instead of wave if widgets should draw with ASCII only - see App.SetASCIIOnly.
+var asciiWave = []rune("\\ /")
+
+func spinnerWave(app gowid.IApp) []rune {
+	if gowid.ASCIIOnly(app) {
+		return asciiWave
+	}
+	return wave
+}
+
 func init() {
 	if runtime.GOOS == "windows" {
 		wave = []rune("▲     ")
@@ -64,20 +84,63 @@ func init() {
 	}
 }
 
+// FrameSet is a sequence of frames a spinner cycles through, and how long each is shown.
+type FrameSet struct {
+	Frames   []string
+	Interval time.Duration
+}
+
+var (
+	// FramesBraille is a dot circling a braille cell - "⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏".
+	FramesBraille = FrameSet{
+		Frames:   []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+		Interval: 80 * time.Millisecond,
+	}
+	// FramesLine is a line turning - "-\|/". It is drawn in place of frames that aren't ASCII if widgets
+	// should draw with ASCII only - see App.SetASCIIOnly.
+	FramesLine = FrameSet{
+		Frames:   []string{"-", "\\", "|", "/"},
+		Interval: 100 * time.Millisecond,
+	}
+	// FramesDots is an ellipsis being typed - ".", "..", "...".
+	FramesDots = FrameSet{
+		Frames:   []string{"   ", ".  ", ".. ", "..."},
+		Interval: 300 * time.Millisecond,
+	}
+)
+
+// DefaultInterval is the time between frames of a spinner started with Start, unless Options or the
+// frame set say otherwise.
+var DefaultInterval = 100 * time.Millisecond
+
 // Options is used for passing arguments to the progressbar initializer, New().
 type Options struct {
 	Label  string
 	Styler gowid.ICellStyler
+	// Frames, if set, makes the spinner cycle through a frame set like FramesBraille, followed by Label,
+	// instead of drawing a wave across its width. While stopped, it draws spaces in place of the frame.
+	Frames   FrameSet
+	Interval time.Duration // The time between frames - defaults to that of Frames, then DefaultInterval
 }
 
 // New will return an initialized spinner
 func New(args Options) *Widget {
+	interval := args.Interval
+	if interval <= 0 {
+		interval = args.Frames.Interval
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
 	res := &Widget{
 		label:     args.Label,
 		styler:    args.Styler,
+		frames:    args.Frames,
+		interval:  interval,
 		Callbacks: gowid.NewCallbacks(),
 	}
 	var _ IWidget = res
+	var _ IFrames = res
 	return res
 }
 
@@ -94,9 +157,52 @@ func (w *Widget) Index() int {
 }
 
 func (w *Widget) SpinnerLen() int {
+	if frames := w.Frames(); frames != nil {
+		return len(frames)
+	}
 	return len(wave)
 }
 
+// Frames returns the frames of the spinner's frame set, or nil if the spinner draws a wave.
+func (w *Widget) Frames() []string {
+	if len(w.frames.Frames) == 0 {
+		return nil
+	}
+	return w.frames.Frames
+}
+
+// cycleLen returns the number of updates before the spinner is back where it began, whether it is drawn
+// with its own glyphs or with ASCII only.
+func (w *Widget) cycleLen() int {
+	if frames := w.Frames(); frames != nil {
+		return lcm(len(frames), len(FramesLine.Frames))
+	}
+	return lcm(len(wave), len(asciiWave))
+}
+
+func lcm(a, b int) int {
+	x, y := a, b
+	for y != 0 {
+		x, y = y, x%y
+	}
+	return a / x * b
+}
+
+// asciiFrames returns frames, or FramesLine in their place if they aren't ASCII and widgets drawn in app
+// should draw with ASCII only.
+func asciiFrames(frames []string, app gowid.IApp) []string {
+	if gowid.ASCIIOnly(app) {
+		for _, f := range frames {
+			for _, r := range f {
+				if r > 0x7f {
+					return FramesLine.Frames
+				}
+			}
+		}
+	}
+	return frames
+}
+
 func (w *Widget) OnChangeState(f gowid.IWidgetChangedCallback) {
 	gowid.AddWidgetCallback(w.Callbacks, ChangeStateCB{}, f)
 }
@@ -109,11 +215,42 @@ func (w *Widget) Enabled() bool {
 	return w.enabled
 }
 
+// Update moves the spinner on a frame. A spinner started with Start is updated for you.
 func (w *Widget) Update() {
+	if w.Frames() != nil {
+		w.idx = (w.idx + 1) % w.cycleLen()
+		return
+	}
 	w.idx -= 1
 	if w.idx < 0 {
-		w.idx = len(wave) - 1
+		w.idx = w.cycleLen() - 1
+	}
+}
+
+// Start enables the spinner and moves it on a frame each interval, with the app's timer, until Stop is
+// called. It must be called on the app goroutine - from another, use app.Run.
+func (w *Widget) Start(app gowid.IApp) {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = gowid.Every(app, w.interval, func(app gowid.IApp) {
+		w.Update()
+	})
+	w.SetEnabled(true, app)
+}
+
+// Stop stops and disables the spinner. It must be called on the app goroutine.
+func (w *Widget) Stop(app gowid.IApp) {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
 	}
+	w.SetEnabled(false, app)
+}
+
+// Running returns true between Start and Stop.
+func (w *Widget) Running() bool {
+	return w.timer != nil
 }
 
 func (w *Widget) SetEnabled(enabled bool, app gowid.IApp) {
@@ -141,6 +278,12 @@ func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.
 
 // Render will render a progressbar IWidget.
 func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	if f, ok := w.(IFrames); ok {
+		if frames := f.Frames(); frames != nil {
+			return renderFrames(w, asciiFrames(frames, app), size, focus, app)
+		}
+	}
+
 	flow, isFlow := size.(gowid.IRenderFlowWith)
 	if !isFlow {
 		panic(gowid.WidgetSizeError{Widget: w, Size: size, Required: "gowid.IRenderFlowWith"})
@@ -148,11 +291,12 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	cols := flow.FlowColumns()
 
 	display := make([]rune, cols)
-	wi := w.Index()
+	wave := spinnerWave(app)
+	wi := w.Index() % len(wave)
 	for i := 0; i < cols; i++ {
 		display[i] = wave[wi]
 		wi += 1
-		if wi == w.SpinnerLen() {
+		if wi == len(wave) {
 			wi = 0
 		}
 	}
@@ -167,6 +311,26 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	return barCanvas
 }
 
+// renderFrames renders a spinner that cycles through frames, padded to the width of the widest, then its
+// label.
+func renderFrames(w IWidget, frames []string, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	width := 0
+	for _, f := range frames {
+		if n := runewidth.StringWidth(f); n > width {
+			width = n
+		}
+	}
+	frame := strings.Repeat(" ", width)
+	if w.Enabled() {
+		f := frames[w.Index()%len(frames)]
+		frame = f + strings.Repeat(" ", width-runewidth.StringWidth(f))
+	}
+	if w.Text() != "" {
+		frame += " " + w.Text()
+	}
+	return styled.New(text.New(frame), w.Styler()).Render(size, focus, app)
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
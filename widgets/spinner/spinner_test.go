@@ -0,0 +1,48 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package spinner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestFrames1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := New(Options{Label: "Saving", Frames: FramesLine})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 10, Rows: 1, Clock: clock})
+	defer sim.Close()
+
+	sz := gowid.RenderFixed{}
+	assert.Equal(t, "  Saving", w.Render(sz, gowid.NotSelected, gwtest.D).String())
+
+	w.Start(sim)
+	assert.True(t, w.Enabled())
+	assert.Equal(t, "- Saving", w.Render(sz, gowid.NotSelected, gwtest.D).String())
+	clock.Advance(100 * time.Millisecond)
+	sim.Frame()
+	assert.Equal(t, "\\ Saving", w.Render(sz, gowid.NotSelected, gwtest.D).String())
+
+	w.Stop(sim)
+	assert.False(t, w.Running())
+	assert.Equal(t, 0, clock.Pending())
+	assert.Equal(t, "  Saving", w.Render(sz, gowid.NotSelected, gwtest.D).String())
+
+	w = New(Options{Frames: FramesDots})
+	w.SetEnabled(true, gwtest.D)
+	w.Update()
+	assert.Equal(t, ".  ", w.Render(gowid.RenderFlowWith{C: 3}, gowid.NotSelected, gwtest.D).String())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
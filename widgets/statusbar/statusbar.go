@@ -0,0 +1,353 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package statusbar provides a one-line bar of text segments, aligned to its left, center and right,
+// which hides its least important segments when it is too narrow to show them all.
+package statusbar
+
+import (
+	"fmt"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/mattn/go-runewidth"
+)
+
+//======================================================================
+
+// DefaultSeparator is drawn between adjacent segments of a section, unless the options say otherwise.
+var DefaultSeparator = " │ "
+
+// Section says where in the bar a segment is drawn.
+type Section int
+
+const (
+	Left Section = iota
+	Center
+	Right
+)
+
+func (s Section) String() string {
+	switch s {
+	case Left:
+		return "left"
+	case Center:
+		return "center"
+	case Right:
+		return "right"
+	default:
+		return fmt.Sprintf("section(%d)", int(s))
+	}
+}
+
+// Segment is one piece of text in the bar. Segments of a section are drawn in the order they were given,
+// and empty segments aren't drawn at all.
+type Segment struct {
+	Name     string            // Identifies the segment, to update it with SetText and SetStyle
+	Section  Section           // Where the segment is drawn
+	Text     string            // The segment's text
+	Style    gowid.ICellStyler // Applied over the bar's style; if nil, the bar's style is used
+	Priority int               // When the bar is too narrow, the segments with the lowest priority are hidden first
+}
+
+// Options is used to configure the bar.
+type Options struct {
+	Style     gowid.ICellStyler // The style of the whole bar; if nil, it is unstyled
+	Separator string            // Drawn between segments of a section; defaults to DefaultSeparator
+	Ellipsis  string            // Ends a segment cut short because it alone is too wide; defaults to "…"
+}
+
+func (o Options) withDefaults() Options {
+	if o.Separator == "" {
+		o.Separator = DefaultSeparator
+	}
+	if o.Ellipsis == "" {
+		o.Ellipsis = "…"
+	}
+	return o
+}
+
+// Widget is the status bar. It is one row high if rendered with a flow size; rendered with a box size,
+// it fills the box, drawing the segments on the first row. When its segments don't all fit, it hides the
+// one with the lowest priority - of those with the same priority, the last given - until the rest fit,
+// with at least one column between sections. The left section is drawn at the left of the bar, the right
+// section at the right, and the center section in the middle, moved aside if it would cover the others.
+type Widget struct {
+	segments []Segment
+	opts     Options
+	gowid.RejectUserInput
+	gowid.NotSelectable
+}
+
+func New(segments []Segment, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	res := &Widget{
+		segments: append([]Segment(nil), segments...),
+		opts:     opt.withDefaults(),
+	}
+	var _ gowid.IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("statusbar[%d segments]", len(w.segments))
+}
+
+// Segments returns a copy of the bar's segments.
+func (w *Widget) Segments() []Segment {
+	return append([]Segment(nil), w.segments...)
+}
+
+// SetSegments replaces all the bar's segments.
+func (w *Widget) SetSegments(segments []Segment, app gowid.IApp) {
+	w.segments = append([]Segment(nil), segments...)
+}
+
+// Segment returns the segment with the given name, and false if there is none.
+func (w *Widget) Segment(name string) (Segment, bool) {
+	if i := w.find(name); i != -1 {
+		return w.segments[i], true
+	}
+	return Segment{}, false
+}
+
+// SetSegment replaces the segment with the same name as seg, or adds seg to the end of the bar if there
+// is none.
+func (w *Widget) SetSegment(seg Segment, app gowid.IApp) {
+	if i := w.find(seg.Name); i != -1 {
+		w.segments[i] = seg
+	} else {
+		w.segments = append(w.segments, seg)
+	}
+}
+
+// SetText sets the text of the named segment, and returns false if there is no such segment. Setting
+// it to "" hides the segment until it is set again.
+func (w *Widget) SetText(name string, text string, app gowid.IApp) bool {
+	i := w.find(name)
+	if i == -1 {
+		return false
+	}
+	w.segments[i].Text = text
+	return true
+}
+
+// SetStyle sets the style of the named segment, and returns false if there is no such segment.
+func (w *Widget) SetStyle(name string, style gowid.ICellStyler, app gowid.IApp) bool {
+	i := w.find(name)
+	if i == -1 {
+		return false
+	}
+	w.segments[i].Style = style
+	return true
+}
+
+func (w *Widget) find(name string) int {
+	for i, seg := range w.segments {
+		if seg.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Shown returns the names of the segments drawn when the bar is cols wide, in the order they were given.
+func (w *Widget) Shown(cols int) []string {
+	res := []string{}
+	for _, p := range w.layout(cols) {
+		res = append(res, w.segments[p.seg].Name)
+	}
+	return res
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	switch sz := size.(type) {
+	case gowid.IRenderBox:
+		return gowid.RenderBox{C: sz.BoxColumns(), R: sz.BoxRows()}
+	case gowid.IRenderFlowWith:
+		return gowid.RenderBox{C: sz.FlowColumns(), R: 1}
+	default:
+		panic(gowid.WidgetSizeError{Widget: w, Size: size, Required: "gowid.IRenderFlowWith or gowid.IRenderBox"})
+	}
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	box := w.RenderSize(size, focus, app)
+	cols, rows := box.BoxColumns(), box.BoxRows()
+	res := gowid.NewCanvasOfSize(cols, rows)
+	if rows == 0 {
+		return res
+	}
+
+	plain := gowid.CellFromRune(' ')
+	if w.opts.Style != nil {
+		plain = styleCell(w.opts.Style, app)
+	}
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			res.SetCellAt(x, y, plain)
+		}
+	}
+
+	for _, p := range w.layout(cols) {
+		seg := w.segments[p.seg]
+		cell := plain
+		if seg.Style != nil {
+			if w.opts.Style != nil {
+				cell = styleCell(gowid.MakeStyleMod(w.opts.Style, seg.Style), app)
+			} else {
+				cell = styleCell(seg.Style, app)
+			}
+		}
+		if p.sep > 0 {
+			drawString(res, p.x-p.sep, w.opts.Separator, plain)
+		}
+		drawString(res, p.x, p.text, cell)
+	}
+
+	return res
+}
+
+//======================================================================
+
+// placed is a segment as drawn - where, and with what text.
+type placed struct {
+	seg  int    // The segment's index
+	x    int    // The column of its text
+	text string // Its text, cut short if it alone is too wide
+	sep  int    // The width of the separator drawn before it, or 0 if it is first in its section
+}
+
+// layout works out which segments are drawn at cols columns, and where.
+func (w *Widget) layout(cols int) []placed {
+	shown := make([]bool, len(w.segments))
+	count := 0
+	for i, seg := range w.segments {
+		if seg.Text != "" {
+			shown[i] = true
+			count++
+		}
+	}
+	sepw := runewidth.StringWidth(w.opts.Separator)
+
+	var widths [3]int
+	measure := func() int {
+		widths = [3]int{}
+		started := [3]bool{}
+		for i, seg := range w.segments {
+			if !shown[i] {
+				continue
+			}
+			s := section(seg)
+			if started[s] {
+				widths[s] += sepw
+			}
+			started[s] = true
+			widths[s] += runewidth.StringWidth(seg.Text)
+		}
+		res, n := 0, 0
+		for s := range widths {
+			if started[s] {
+				res += widths[s]
+				n++
+			}
+		}
+		if n > 1 {
+			res += n - 1
+		}
+		return res
+	}
+
+	for measure() > cols && count > 1 {
+		drop := -1
+		for i, seg := range w.segments {
+			if shown[i] && (drop == -1 || seg.Priority <= w.segments[drop].Priority) {
+				drop = i
+			}
+		}
+		shown[drop] = false
+		count--
+	}
+
+	// One segment, maybe still too wide - cut it short
+	texts := make([]string, len(w.segments))
+	for i, seg := range w.segments {
+		texts[i] = seg.Text
+		if shown[i] && count == 1 && runewidth.StringWidth(seg.Text) > cols {
+			texts[i] = runewidth.Truncate(seg.Text, cols, w.opts.Ellipsis)
+			if runewidth.StringWidth(texts[i]) > cols {
+				// The ellipsis itself doesn't fit
+				texts[i] = runewidth.Truncate(seg.Text, cols, "")
+			}
+			widths[section(seg)] = runewidth.StringWidth(texts[i])
+		}
+	}
+
+	// Where each section starts
+	var start [3]int
+	start[Right] = cols - widths[Right]
+	start[Center] = (cols - widths[Center]) / 2
+	lo := widths[Left]
+	if lo > 0 {
+		lo++
+	}
+	hi := start[Right] - widths[Center]
+	if widths[Right] > 0 {
+		hi--
+	}
+	start[Center] = gwutil.Max(lo, gwutil.Min(start[Center], hi))
+
+	res := make([]placed, 0, count)
+	x := start
+	started := [3]bool{}
+	for i, seg := range w.segments {
+		if !shown[i] {
+			continue
+		}
+		s := section(seg)
+		p := placed{seg: i, text: texts[i]}
+		if started[s] {
+			p.sep = sepw
+			x[s] += sepw
+		}
+		started[s] = true
+		p.x = x[s]
+		x[s] += runewidth.StringWidth(texts[i])
+		res = append(res, p)
+	}
+	return res
+}
+
+// section returns the segment's section, treating an unknown one as the left.
+func section(seg Segment) Section {
+	if seg.Section < Left || seg.Section > Right {
+		return Left
+	}
+	return seg.Section
+}
+
+func drawString(c gowid.ICanvas, x int, s string, base gowid.Cell) {
+	for _, r := range s {
+		if x >= 0 && x < c.BoxColumns() {
+			c.SetCellAt(x, 0, base.WithRune(r))
+		}
+		x += gwutil.Max(1, runewidth.RuneWidth(r))
+	}
+}
+
+func styleCell(styler gowid.ICellStyler, app gowid.IApp) gowid.Cell {
+	fgCol, bgCol, style := styler.GetStyle(app)
+	mode := app.GetColorMode()
+	return gowid.MakeCell(' ', gowid.IColorToTCell(fgCol, gowid.ColorNone, mode),
+		gowid.IColorToTCell(bgCol, gowid.ColorNone, mode), style)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
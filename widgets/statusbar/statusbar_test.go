@@ -0,0 +1,61 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package statusbar
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func testBar() *Widget {
+	return New([]Segment{
+		{Name: "mode", Section: Left, Text: "INSERT", Priority: 3},
+		{Name: "file", Section: Left, Text: "main.go", Priority: 2},
+		{Name: "msg", Section: Center, Text: "saved", Priority: 0},
+		{Name: "pos", Section: Right, Text: "12:4", Priority: 3},
+		{Name: "enc", Section: Right, Text: "utf-8", Priority: 1},
+	}, Options{Separator: "|"})
+}
+
+func TestLayout1(t *testing.T) {
+	w := testBar()
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 40, Rows: 1})
+	defer sim.Close()
+
+	sim.AssertLine(t, 0, "INSERT|main.go   saved        12:4|utf-8")
+
+	// Segments are hidden lowest priority first
+	assert.Equal(t, []string{"mode", "file", "pos", "enc"}, w.Shown(30))
+	assert.Equal(t, []string{"mode", "file", "pos"}, w.Shown(24))
+	assert.Equal(t, []string{"mode", "pos"}, w.Shown(15))
+	// Of segments with the same priority, the last given is hidden first
+	assert.Equal(t, []string{"mode"}, w.Shown(8))
+	sim.Resize(20, 1)
+	sim.AssertLine(t, 0, "INSERT|main.go  12:4")
+
+	// A lone segment too wide for the bar is cut short
+	sim.Resize(3, 1)
+	sim.AssertLine(t, 0, "IN…")
+
+	// Segments are updated in place; an empty one is hidden
+	sim.Resize(40, 1)
+	sim.Run(gowid.RunFunction(func(app gowid.IApp) {
+		assert.True(t, w.SetText("msg", "building...", app))
+		assert.True(t, w.SetText("file", "", app))
+		assert.False(t, w.SetText("nope", "x", app))
+	}))
+	sim.Frame()
+	sim.AssertLine(t, 0, "INSERT        building...     12:4|utf-8")
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -24,16 +24,20 @@ type Widget struct {
 	gowid.IWidget
 	focusRange    []AttributeRange
 	notFocusRange []AttributeRange
+	hoverRange    []AttributeRange
 	options       Options
 	*gowid.Callbacks
 	gowid.SubWidgetCallbacks
+	gowid.Hoverable
+	gowid.AddressProvidesID
 }
 
 type Options struct {
 	OverWrite bool // If true, then apply the style over any style below; if false, style underneath takes precedence
 }
 
-// Very simple way to color an entire widget
+// Very simple way to color an entire widget. If styler is a gowid.IStateStyler, like gowid.StateStyle, the
+// variant for the inner widget's state - e.g. focused or disabled - is used.
 func New(inner gowid.IWidget, styler gowid.ICellStyler, opts ...Options) *Widget {
 	res := NewWithRanges(
 		inner,
@@ -68,6 +72,16 @@ func NewExt(inner gowid.IWidget, notFocusStyler, focusStyler gowid.ICellStyler,
 	return res
 }
 
+// NewHover styles the widget with styler while the mouse pointer is over it, layered over the inner
+// widget's own style - e.g. to highlight buttons or list rows on hover. Hover tracking must be enabled
+// with App.EnableHover. Any styled widget whose styler is a gowid.IStateStyler, like gowid.StateStyle,
+// also uses the styler's hover variant.
+func NewHover(inner gowid.IWidget, styler gowid.ICellStyler, opts ...Options) *Widget {
+	res := NewExt(inner, nil, nil, opts...)
+	res.hoverRange = []AttributeRange{AttributeRange{0, -1, styler}}
+	return res
+}
+
 func NewWithRanges(inner gowid.IWidget, notFocusRange []AttributeRange, focusRange []AttributeRange, opts ...Options) *Widget {
 	var opt Options
 	if len(opts) > 0 {
@@ -106,24 +120,35 @@ func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app go
 }
 
 func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	gowid.TrackHover(w, ev, app)
 	return gowid.UserInputIfSelectable(w.IWidget, ev, size, focus, app)
 }
 
 func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
-	canvas := w.SubWidget().Render(size, focus, app)
-
-	cols := canvas.BoxColumns()
+	canvas := gowid.Render(w.SubWidget(), size, focus, app)
 
 	var attrSpecs []AttributeRange
-	var f1 gowid.TCellColor
-	var b1 gowid.TCellColor
-
 	if focus.Focus {
 		attrSpecs = w.focusRange
 	} else {
 		attrSpecs = w.notFocusRange
 	}
 
+	state := gowid.StyleStateOf(w.SubWidget(), focus) | w.StyleState()
+	w.applyRanges(canvas, attrSpecs, state, app)
+	if w.Hovered() {
+		w.applyRanges(canvas, w.hoverRange, state, app)
+	}
+
+	return canvas
+}
+
+func (w *Widget) applyRanges(canvas gowid.ICanvas, attrSpecs []AttributeRange, state gowid.StyleState, app gowid.IApp) {
+	cols := canvas.BoxColumns()
+
+	var f1 gowid.TCellColor
+	var b1 gowid.TCellColor
+
 	x := cols
 	y := canvas.BoxRows()
 	max := x * y
@@ -132,7 +157,9 @@ func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.
 		for _, attr := range attrSpecs {
 			// TODO - bounds checks
 			if attr.Styler != nil {
-				f, b, s := attr.Styler.GetStyle(app)
+				styler := gowid.StylerForState(attr.Styler, state)
+				f, b, s := styler.GetStyle(app)
+				_, positional := styler.(gowid.IPositionStyler)
 				for i := attr.Start; true; i++ {
 					if attr.End != -1 && i == attr.End {
 						break
@@ -141,6 +168,9 @@ func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.
 						break
 					}
 					col, row := i%cols, i/cols
+					if positional {
+						f, b, s = gowid.StylerForPosition(styler, col, cols).GetStyle(app)
+					}
 
 					c := canvas.CellAt(col, row)
 					c2 := c
@@ -164,8 +194,6 @@ func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.
 			}
 		}
 	}
-
-	return canvas
 }
 
 //======================================================================
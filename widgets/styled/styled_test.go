@@ -10,8 +10,11 @@ import (
 
 	"github.com/gcla/gowid"
 	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/disable"
+	"github.com/gcla/gowid/widgets/selectable"
 	"github.com/gcla/gowid/widgets/text"
 	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
 )
 
 //======================================================================
@@ -31,6 +34,41 @@ func TestCanvas12(t *testing.T) {
 	}
 }
 
+func TestStates1(t *testing.T) {
+	style := gowid.StateStyle{
+		Normal:   gowid.MakePaletteEntry(gowid.ColorGreen, gowid.ColorBlack),
+		Focus:    gowid.MakeForeground(gowid.ColorWhite),
+		Disabled: gowid.MakeStyledPaletteEntry(gowid.ColorDarkGray, gowid.NoColor{}, gowid.StyleDim),
+	}
+	dw := disable.NewEnabled(selectable.New(text.New("hi")))
+	w := New(dw, style)
+
+	c := w.Render(gowid.RenderFlowWith{C: 2}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, gowid.ColorGreen, c.CellAt(0, 0).ForegroundColor())
+
+	c = w.Render(gowid.RenderFlowWith{C: 2}, gowid.Focused, gwtest.D)
+	assert.Equal(t, gowid.ColorWhite, c.CellAt(0, 0).ForegroundColor())
+	assert.Equal(t, gowid.ColorBlack, c.CellAt(0, 0).BackgroundColor())
+
+	dw.Disable()
+	c = w.Render(gowid.RenderFlowWith{C: 2}, gowid.Focused, gwtest.D)
+	assert.Equal(t, gowid.ColorDarkGray, c.CellAt(0, 0).ForegroundColor())
+	assert.Equal(t, gowid.ColorBlack, c.CellAt(0, 0).BackgroundColor())
+	assert.Equal(t, gowid.StyleDim, c.CellAt(0, 0).Style())
+}
+
+func TestGradient1(t *testing.T) {
+	black, white := gowid.MakeRGBColor("#000000"), gowid.MakeRGBColor("#ffffff")
+	w := New(text.New("abcde"), gowid.MakeBackgroundGradient(gowid.RGBSpace, black, white))
+
+	c := w.Render(gowid.RenderFlowWith{C: 5}, gowid.NotSelected, gwtest.D)
+	mode := gwtest.D.GetColorMode()
+	assert.Equal(t, gowid.IColorToTCell(black, gowid.ColorNone, mode), c.CellAt(0, 0).BackgroundColor())
+	assert.Equal(t, gowid.IColorToTCell(white, gowid.ColorNone, mode), c.CellAt(4, 0).BackgroundColor())
+	assert.NotEqual(t, c.CellAt(0, 0).BackgroundColor(), c.CellAt(2, 0).BackgroundColor())
+	assert.Equal(t, gowid.ColorNone, c.CellAt(2, 0).ForegroundColor())
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
@@ -0,0 +1,81 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package table
+
+import (
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/list"
+)
+
+//======================================================================
+
+var _ list.IWalkerIDs = (*Widget)(nil)
+var _ gowid.IViewAnchor = (*Widget)(nil)
+
+// PositionID returns the RowId of the row at pos, so that the table's list can keep its view when the
+// model is replaced - see Anchor. Dividers have no ID.
+func (w *Widget) PositionID(pos list.IWalkerPosition) (interface{}, bool) {
+	row := int(pos.(Position))
+	if w.HorzDivider() != nil {
+		if row%2 == 1 {
+			return nil, false
+		}
+		row /= 2
+	}
+	return w.Model().RowIdentifier(row)
+}
+
+// PositionForID returns the position of the row with the RowId given. If the model doesn't implement
+// IInvertible, it must be bounded, and is searched.
+func (w *Widget) PositionForID(id interface{}) (list.IWalkerPosition, bool) {
+	rid, ok := id.(RowId)
+	if !ok {
+		return nil, false
+	}
+	row := -1
+	if inv, ok := w.Model().(IInvertible); ok {
+		if r, ok := inv.IdentifierToRow(rid); ok {
+			row = r
+		}
+	} else if bm, ok := w.Model().(IBoundedModel); ok {
+		for r := 0; r < bm.Rows(); r++ {
+			if other, ok := w.Model().RowIdentifier(r); ok && other == rid {
+				row = r
+				break
+			}
+		}
+	}
+	if row < 0 {
+		return nil, false
+	}
+	if w.HorzDivider() != nil {
+		row *= 2
+	}
+	return Position(row), true
+}
+
+// Anchor returns the RowId of the row in focus, and the row of the view at which it is shown, as the table
+// was last rendered. Use it with RestoreAnchor, or gowid.KeepView, to keep the view steady when the model
+// is replaced with SetModel - e.g. as live data is reloaded.
+func (w *Widget) Anchor(app gowid.IApp) (gowid.ViewAnchor, bool) {
+	if a, ok := w.listw.IWidget.(gowid.IViewAnchor); ok {
+		return a.Anchor(app)
+	}
+	return gowid.ViewAnchor{}, false
+}
+
+// RestoreAnchor focuses the row with the anchor's RowId, at the anchor's row of the view.
+func (w *Widget) RestoreAnchor(a gowid.ViewAnchor, app gowid.IApp) bool {
+	if va, ok := w.listw.IWidget.(gowid.IViewAnchor); ok {
+		return va.RestoreAnchor(a, app)
+	}
+	return false
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,416 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package table
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/checkbox"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/dialog"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+)
+
+//======================================================================
+
+// ColumnsChangedCB is used to register callbacks run when the columns a table shows, or their order,
+// change - see Widget.OnColumnsChanged.
+type ColumnsChangedCB struct{}
+
+// IColumnNames is implemented by a model that can name its columns. The column chooser lists columns by
+// these names; a model that doesn't implement it has its columns listed as "Column 1", "Column 2", etc.
+type IColumnNames interface {
+	ColumnName(col int) string
+}
+
+// ColumnName returns the name of the model's col'th column.
+func ColumnName(model IModel, col int) string {
+	if m, ok := model.(IColumnNames); ok {
+		if res := m.ColumnName(col); res != "" {
+			return res
+		}
+	}
+	return fmt.Sprintf("Column %d", col+1)
+}
+
+//======================================================================
+
+// ColumnLayout records which of a model's columns a table shows, in what order, and how wide. Columns are
+// identified by their index in the model. A layout can be serialized as JSON, which is how a table persists
+// it.
+type ColumnLayout struct {
+	Order  []int  `json:"order"`            // Every column of the model, in the order displayed
+	Hidden []bool `json:"hidden"`           // Indexed by model column; true if the column is not displayed
+	Widths []int  `json:"widths,omitempty"` // Indexed by model column; if > 0, the column's width as resized
+}
+
+// DefaultColumnLayout returns the layout that shows all n columns of a model in the model's order.
+func DefaultColumnLayout(n int) ColumnLayout {
+	res := ColumnLayout{
+		Order:  make([]int, n),
+		Hidden: make([]bool, n),
+	}
+	for i := 0; i < n; i++ {
+		res.Order[i] = i
+	}
+	return res
+}
+
+// Visible returns the model columns displayed, in order.
+func (l ColumnLayout) Visible() []int {
+	res := make([]int, 0, len(l.Order))
+	for _, col := range l.Order {
+		if !l.Hidden[col] {
+			res = append(res, col)
+		}
+	}
+	return res
+}
+
+// IsDefault returns true if the layout shows every column in the model's order, at the model's widths.
+func (l ColumnLayout) IsDefault() bool {
+	for i, col := range l.Order {
+		if col != i || l.Hidden[col] {
+			return false
+		}
+	}
+	for _, w := range l.Widths {
+		if w > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Width returns the width a model column has been resized to, or 0 if it has the model's width.
+func (l ColumnLayout) Width(col int) int {
+	if col >= 0 && col < len(l.Widths) {
+		return l.Widths[col]
+	}
+	return 0
+}
+
+// Fit returns a copy of the layout adjusted for a model with n columns - e.g. one restored from a previous
+// session, before the model gained or lost columns. Columns that no longer exist are dropped, new columns
+// are shown at the end, and if every column would be hidden, the first is shown.
+func (l ColumnLayout) Fit(n int) ColumnLayout {
+	res := ColumnLayout{
+		Order:  make([]int, 0, n),
+		Hidden: make([]bool, n),
+	}
+	seen := make([]bool, n)
+	for _, col := range l.Order {
+		if col < 0 || col >= n || seen[col] {
+			continue
+		}
+		seen[col] = true
+		res.Order = append(res.Order, col)
+		res.Hidden[col] = col < len(l.Hidden) && l.Hidden[col]
+		if w := l.Width(col); w > 0 {
+			if res.Widths == nil {
+				res.Widths = make([]int, n)
+			}
+			res.Widths[col] = w
+		}
+	}
+	for col := 0; col < n; col++ {
+		if !seen[col] {
+			res.Order = append(res.Order, col)
+		}
+	}
+	if n > 0 && len(res.Visible()) == 0 {
+		res.Hidden[res.Order[0]] = false
+	}
+	return res
+}
+
+//======================================================================
+
+// columnView is an IModel that presents the visible columns of another model, in the layout's order and at
+// the layout's widths.
+type columnView struct {
+	IModel
+	layout  ColumnLayout
+	visible []int
+}
+
+var _ IModel = (*columnView)(nil)
+
+// boundedColumnView is a columnView of an IBoundedModel, so that a table can still tell how many rows
+// there are.
+type boundedColumnView struct {
+	*columnView
+}
+
+var _ IBoundedModel = (*boundedColumnView)(nil)
+
+func newColumnView(model IModel, layout ColumnLayout) IModel {
+	res := &columnView{
+		IModel:  model,
+		layout:  layout,
+		visible: layout.Visible(),
+	}
+	if _, ok := model.(IBoundedModel); ok {
+		return &boundedColumnView{res}
+	}
+	return res
+}
+
+func (m *columnView) pick(ws []gowid.IWidget) []gowid.IWidget {
+	if ws == nil {
+		return nil
+	}
+	res := make([]gowid.IWidget, 0, len(m.visible))
+	for _, col := range m.visible {
+		if col < len(ws) {
+			res = append(res, ws[col])
+		}
+	}
+	return res
+}
+
+func (m *columnView) Columns() int {
+	return len(m.visible)
+}
+
+func (m *columnView) CellWidgets(row RowId) []gowid.IWidget {
+	return m.pick(m.IModel.CellWidgets(row))
+}
+
+func (m *columnView) HeaderWidgets() []gowid.IWidget {
+	return m.pick(m.IModel.HeaderWidgets())
+}
+
+func (m *columnView) Widths() []gowid.IWidgetDimension {
+	widths := m.IModel.Widths()
+	if widths == nil && m.layout.Widths == nil {
+		return nil
+	}
+	res := make([]gowid.IWidgetDimension, len(m.visible))
+	for i, col := range m.visible {
+		switch {
+		case m.layout.Width(col) > 0:
+			res[i] = gowid.RenderWithUnits{U: m.layout.Width(col)}
+		case col < len(widths):
+			res[i] = widths[col]
+		default:
+			res[i] = gowid.RenderWithWeight{W: 1}
+		}
+	}
+	return res
+}
+
+// SortByColumn sorts the model by the model column shown at col, if the model is sortable.
+func (m *columnView) SortByColumn(col int, ascending bool) bool {
+	if s, ok := m.IModel.(ISortable); ok && col >= 0 && col < len(m.visible) {
+		return s.SortByColumn(m.visible[col], ascending)
+	}
+	return false
+}
+
+func (m *boundedColumnView) Rows() int {
+	return m.IModel.(IBoundedModel).Rows()
+}
+
+//======================================================================
+
+// ColumnLayout returns the table's current column layout.
+func (w *Widget) ColumnLayout() ColumnLayout {
+	if w.layout == nil {
+		return DefaultColumnLayout(w.source.Columns())
+	}
+	return w.layout.Fit(w.source.Columns())
+}
+
+// SetColumnLayout changes the columns the table shows, and their order. If Options.ColumnLayoutKey is set,
+// the layout is saved in the app's cache under that key, so that RestoreColumnLayout can apply it in a later
+// session. Callbacks registered with OnColumnsChanged are run.
+func (w *Widget) SetColumnLayout(layout ColumnLayout, app gowid.IApp) error {
+	w.setColumnLayout(layout, app)
+	var err error
+	if w.opt.ColumnLayoutKey != "" {
+		var data []byte
+		data, err = json.Marshal(w.ColumnLayout())
+		if err == nil {
+			err = gowid.CacheFor(app).Put(w.opt.ColumnLayoutKey, data, 0)
+		} else {
+			err = errors.WithStack(err)
+		}
+	}
+	gowid.RunWidgetCallbacks(w.Callbacks, ColumnsChangedCB{}, app, w)
+	return err
+}
+
+// RestoreColumnLayout applies the layout saved under Options.ColumnLayoutKey by SetColumnLayout, perhaps in
+// a previous session. It returns false if there is no key or no saved layout.
+func (w *Widget) RestoreColumnLayout(app gowid.IApp) bool {
+	if w.opt.ColumnLayoutKey == "" {
+		return false
+	}
+	data, ok := gowid.CacheFor(app).Get(w.opt.ColumnLayoutKey)
+	if !ok {
+		return false
+	}
+	var layout ColumnLayout
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return false
+	}
+	w.setColumnLayout(layout, app)
+	gowid.RunWidgetCallbacks(w.Callbacks, ColumnsChangedCB{}, app, w)
+	return true
+}
+
+func (w *Widget) setColumnLayout(layout ColumnLayout, app gowid.IApp) {
+	layout = layout.Fit(w.source.Columns())
+	if layout.IsDefault() {
+		w.layout = nil
+	} else {
+		w.layout = &layout
+	}
+	w.SetModel(w.source, app)
+}
+
+// OnColumnsChanged registers a callback run when the table's column layout changes - e.g. from the column
+// chooser.
+func (w *Widget) OnColumnsChanged(f gowid.IWidgetChangedCallback) {
+	if w.Callbacks == nil {
+		w.Callbacks = gowid.NewCallbacks()
+	}
+	gowid.AddWidgetCallback(w.Callbacks, ColumnsChangedCB{}, f)
+}
+
+func (w *Widget) RemoveOnColumnsChanged(f gowid.IIdentity) {
+	if w.Callbacks != nil {
+		gowid.RemoveWidgetCallback(w.Callbacks, ColumnsChangedCB{}, f)
+	}
+}
+
+// OpenColumnChooser opens a ColumnChooser for the table in a dialog over the whole app. It is also opened
+// by the keys in Options.ColumnChooserKeys.
+func (w *Widget) OpenColumnChooser(app gowid.IApp) *dialog.Widget {
+	d := dialog.New(NewColumnChooser(w), dialog.Options{
+		Buttons:       dialog.CloseOnly,
+		FocusOnWidget: true,
+	})
+	d.OpenGlobally(gowid.RenderWithRatio{R: 0.4}, app)
+	return d
+}
+
+//======================================================================
+
+// ColumnChooser lists every column of a table with a checkbox that shows or hides it. The focused column
+// can be moved up and down the list - and so left and right in the table - with shift-up and shift-down,
+// or K and J. Changes are applied to the table as they are made.
+type ColumnChooser struct {
+	*pile.Widget
+	table  *Widget
+	layout ColumnLayout
+	rows   []gowid.IWidget
+	checks []*checkbox.Widget // Indexed by model column
+}
+
+var _ gowid.IWidget = (*ColumnChooser)(nil)
+
+func NewColumnChooser(table *Widget) *ColumnChooser {
+	res := &ColumnChooser{
+		table:  table,
+		layout: table.ColumnLayout(),
+	}
+	model := table.SourceModel()
+	res.checks = make([]*checkbox.Widget, len(res.layout.Hidden))
+	res.rows = make([]gowid.IWidget, 0, len(res.layout.Order))
+	for _, col := range res.layout.Order {
+		col := col
+		cb := checkbox.New(!res.layout.Hidden[col])
+		cb.OnClick(gowid.WidgetCallback{Name: "cb", WidgetChangedFunction: func(app gowid.IApp, widget gowid.IWidget) {
+			res.setShown(col, cb.IsChecked(), app)
+		}})
+		res.checks[col] = cb
+		res.rows = append(res.rows, columns.New([]gowid.IContainerWidget{
+			&gowid.ContainerWidget{IWidget: cb, D: gowid.RenderFixed{}},
+			&gowid.ContainerWidget{IWidget: text.New(" " + ColumnName(model, col)), D: gowid.RenderWithWeight{W: 1}},
+		}))
+	}
+	cws := make([]gowid.IContainerWidget, len(res.rows))
+	for i, row := range res.rows {
+		cws[i] = &gowid.ContainerWidget{IWidget: row, D: gowid.RenderFlow{}}
+	}
+	res.Widget = pile.New(cws)
+	return res
+}
+
+func (w *ColumnChooser) String() string {
+	return fmt.Sprintf("columnchooser[%d]", len(w.rows))
+}
+
+// Layout returns the layout chosen.
+func (w *ColumnChooser) Layout() ColumnLayout {
+	return w.layout
+}
+
+// setShown shows or hides a column. The last column shown can't be hidden.
+func (w *ColumnChooser) setShown(col int, shown bool, app gowid.IApp) {
+	if shown == !w.layout.Hidden[col] {
+		return
+	}
+	if !shown && len(w.layout.Visible()) == 1 {
+		w.checks[col].SetChecked(app, true)
+		return
+	}
+	w.layout.Hidden[col] = !shown
+	w.table.SetColumnLayout(w.layout, app)
+}
+
+// Move moves the focused column delta places down the list, or up if delta is negative. It returns false
+// if the column is already at the end of the list.
+func (w *ColumnChooser) Move(delta int, app gowid.IApp) bool {
+	from := w.Focus()
+	to := from + delta
+	if from < 0 || to < 0 || to >= len(w.rows) || delta == 0 {
+		return false
+	}
+	order := w.layout.Order
+	col, row := order[from], w.rows[from]
+	if delta > 0 {
+		copy(order[from:to], order[from+1:to+1])
+		copy(w.rows[from:to], w.rows[from+1:to+1])
+	} else {
+		copy(order[to+1:from+1], order[to:from])
+		copy(w.rows[to+1:from+1], w.rows[to:from])
+	}
+	order[to], w.rows[to] = col, row
+	// Undoing this alone would leave the rows out of step with the table's column order
+	gowid.WithoutLayoutUndo(app, func(app gowid.IApp) {
+		w.Widget.SetSubWidgets(w.rows, app)
+	})
+	w.Widget.SetFocus(app, to)
+	w.table.SetColumnLayout(w.layout, app)
+	return true
+}
+
+func (w *ColumnChooser) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if evk, ok := ev.(*tcell.EventKey); ok {
+		switch {
+		case evk.Key() == tcell.KeyUp && evk.Modifiers()&tcell.ModShift != 0,
+			evk.Key() == tcell.KeyRune && evk.Rune() == 'K':
+			return w.Move(-1, app)
+		case evk.Key() == tcell.KeyDown && evk.Modifiers()&tcell.ModShift != 0,
+			evk.Key() == tcell.KeyRune && evk.Rune() == 'J':
+			return w.Move(1, app)
+		}
+	}
+	return w.Widget.UserInput(ev, size, focus, app)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
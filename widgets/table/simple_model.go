@@ -28,6 +28,7 @@ import (
 
 type LayoutOptions struct {
 	Widths []gowid.IWidgetDimension
+	Align  []gowid.IHAlignment // How each column's cells are aligned; left if nil
 }
 
 type StyleOptions struct {
@@ -66,6 +67,8 @@ type SimpleModel struct {
 }
 
 var _ IBoundedModel = (*SimpleModel)(nil)
+var _ ISortable = (*SimpleModel)(nil)
+var _ IColumnAlign = (*SimpleModel)(nil)
 
 func defaultOptions() SimpleOptions {
 	return SimpleOptions{
@@ -156,6 +159,14 @@ func (c *SimpleModel) Rows() int {
 	return len(c.Data)
 }
 
+// ColumnName returns the header of column col, so that the column chooser can list columns by name.
+func (c *SimpleModel) ColumnName(col int) string {
+	if col >= 0 && col < len(c.Headers) {
+		return c.Headers[col]
+	}
+	return ""
+}
+
 var widthOneHeightMax RenderWithUnitsMax = RenderWithUnitsMax{
 	RenderWithUnits: gowid.RenderWithUnits{1},
 }
@@ -267,18 +278,36 @@ type ISimpleRowProvider interface {
 	GetStyle() StyleOptions
 }
 
+// IColumnAlign is implemented by a model that aligns the cells of each column - e.g. numbers to the right.
+type IColumnAlign interface {
+	ColumnAlign(col int) gowid.IHAlignment
+}
+
+// ColumnAlign returns the alignment of column col's cells, from LayoutOptions.Align.
+func (c *SimpleModel) ColumnAlign(col int) gowid.IHAlignment {
+	if col >= 0 && col < len(c.Layout.Align) && c.Layout.Align[col] != nil {
+		return c.Layout.Align[col]
+	}
+	return gowid.HAlignLeft{}
+}
+
 func (c *SimpleModel) GetStyle() StyleOptions {
 	return c.Style
 }
 
-// Provides a "cell" which is stitched together with columns to provide a "row"
+// Provides a "cell" which is stitched together with columns to provide a "row". If c is an IColumnAlign,
+// the cell's text is aligned as it says for column i.
 func SimpleCellWidget(c ISimpleRowProvider, i int, s string) gowid.IWidget {
+	var opts text.Options
+	if a, ok := c.(IColumnAlign); ok {
+		opts.Align = a.ColumnAlign(i)
+	}
 	var w gowid.IWidget
 	if c.GetStyle().CellStyleProvided {
-		b := button.NewBare(text.New(s))
+		b := button.NewBare(text.New(s, opts))
 		w = isselected.New(b, styled.New(b, c.GetStyle().CellStyleSelected), styled.New(b, c.GetStyle().CellStyleFocus))
 	} else {
-		w = styled.NewExt(button.NewBare(text.New(s)), nil, gowid.MakeStyledAs(gowid.StyleReverse))
+		w = styled.NewExt(button.NewBare(text.New(s, opts)), nil, gowid.MakeStyledAs(gowid.StyleReverse))
 	}
 	return w
 }
@@ -344,6 +373,23 @@ func (c *SimpleModel) Widths() []gowid.IWidgetDimension {
 	return c.Layout.Widths
 }
 
+// SortByColumn sorts the rows by column col, using its comparator. It returns false if the column has
+// none.
+func (c *SimpleModel) SortByColumn(col int, ascending bool) bool {
+	if col < 0 || col >= len(c.Comparators) || c.Comparators[col] == nil {
+		return false
+	}
+	var sorter sort.Interface = &SimpleTableByColumn{
+		SimpleModel: c,
+		Column:      col,
+	}
+	if !ascending {
+		sorter = sort.Reverse(sorter)
+	}
+	sort.Stable(sorter)
+	return true
+}
+
 //======================================================================
 
 // SimpleTableByColumn is a SimpleTable with a selected column; it's intended
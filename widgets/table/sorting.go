@@ -0,0 +1,91 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package table
+
+import (
+	"github.com/gcla/gowid"
+)
+
+//======================================================================
+
+// SortedCB is used to register callbacks run when a table is sorted - see Widget.OnSorted.
+type SortedCB struct{}
+
+// ISortable is implemented by a model whose rows can be sorted by a column. SortByColumn returns false if
+// the rows can't be sorted by col.
+type ISortable interface {
+	SortByColumn(col int, ascending bool) bool
+}
+
+// SortByColumn sorts the table's rows by the col'th column displayed, if its model is an ISortable. It
+// returns false if the rows weren't sorted. Callbacks registered with OnSorted are run.
+func (w *Widget) SortByColumn(col int, ascending bool, app gowid.IApp) bool {
+	s, ok := w.model.(ISortable)
+	if !ok || !s.SortByColumn(col, ascending) {
+		return false
+	}
+	w.sortCol, w.sortAsc, w.sorted = w.modelColumn(col), ascending, true
+	gowid.RunWidgetCallbacks(w.Callbacks, SortedCB{}, app, w)
+	return true
+}
+
+// SortColumn returns the displayed column the table was last sorted by with SortByColumn, and true if the
+// sort was ascending. The column is -1 if the table hasn't been sorted that way, or the column is hidden.
+func (w *Widget) SortColumn() (int, bool) {
+	if !w.sorted {
+		return -1, false
+	}
+	for i, col := range w.visibleColumns() {
+		if col == w.sortCol {
+			return i, w.sortAsc
+		}
+	}
+	return -1, false
+}
+
+// sortByFocusColumn sorts the table by the column in focus - ascending, unless it is already sorted
+// ascending by that column. It is bound to Options.SortKeys.
+func (w *Widget) sortByFocusColumn(app gowid.IApp) bool {
+	pos, err := w.FocusXY()
+	if err != nil {
+		return false
+	}
+	col, asc := w.SortColumn()
+	return w.SortByColumn(pos.Column, !(col == pos.Column && asc), app)
+}
+
+// OnSorted registers a callback run when the table is sorted with SortByColumn, or with Options.SortKeys.
+func (w *Widget) OnSorted(f gowid.IWidgetChangedCallback) {
+	if w.Callbacks == nil {
+		w.Callbacks = gowid.NewCallbacks()
+	}
+	gowid.AddWidgetCallback(w.Callbacks, SortedCB{}, f)
+}
+
+func (w *Widget) RemoveOnSorted(f gowid.IIdentity) {
+	if w.Callbacks != nil {
+		gowid.RemoveWidgetCallback(w.Callbacks, SortedCB{}, f)
+	}
+}
+
+// visibleColumns returns the model columns displayed, in order.
+func (w *Widget) visibleColumns() []int {
+	return w.ColumnLayout().Visible()
+}
+
+// modelColumn returns the model column displayed as the col'th.
+func (w *Widget) modelColumn(col int) int {
+	vis := w.visibleColumns()
+	if col >= 0 && col < len(vis) {
+		return vis[col]
+	}
+	return -1
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
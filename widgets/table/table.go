@@ -11,6 +11,7 @@ import (
 
 	"github.com/gcla/gowid"
 	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/vim"
 	"github.com/gcla/gowid/widgets/columns"
 	"github.com/gcla/gowid/widgets/isselected"
 	"github.com/gcla/gowid/widgets/list"
@@ -193,7 +194,15 @@ type Widget struct {
 	wrapper          *pile.Widget
 	header           gowid.IWidget
 	listw            *ListWithPreferedColumn
-	model            IModel
+	model            IModel        // The model rendered - source, or a view of its chosen columns
+	source           IModel        // The model provided by the caller
+	layout           *ColumnLayout // nil if every column is shown in the model's order
+	sortCol          int           // The model column last sorted by, if sorted
+	sortAsc          bool
+	sorted           bool
+	lastCols         int // The width of the last render
+	hoff             int // How far the table is scrolled sideways, if narrower than Options.MinWidth
+	hfocus           int // The column in focus when the table was last scrolled to it
 	cur              int
 	cache            *lru.Cache
 	flowHorzDivider  *gowid.ContainerWidget
@@ -217,6 +226,22 @@ var _ list.IWalkerEnd = (*BoundedWidget)(nil)
 
 type Options struct {
 	CacheSize int
+	JumpKeys  []vim.KeyPress // Keys that start jump mode in the table's rows - see StartJump
+	// ColumnChooserKeys open the column chooser - see OpenColumnChooser. No keys are bound by default.
+	ColumnChooserKeys []vim.KeyPress
+	// ColumnLayoutKey, if not empty, is the key under which the column layout is saved in the app's
+	// cache - see SetColumnLayout and RestoreColumnLayout.
+	ColumnLayoutKey string
+	// SortKeys sort the rows by the column in focus, if the model is an ISortable - pressed again, the
+	// order is reversed. No keys are bound by default.
+	SortKeys []vim.KeyPress
+	// NarrowColumnKeys and WidenColumnKeys resize the column in focus - see ResizeColumn. No keys are
+	// bound by default.
+	NarrowColumnKeys []vim.KeyPress
+	WidenColumnKeys  []vim.KeyPress
+	// MinWidth, if greater than 0, is the narrowest the table is drawn. If there is less room, the table
+	// scrolls sideways to keep the column in focus in view; the mouse wheel scrolls it too.
+	MinWidth int
 }
 
 func New(model IModel, opts ...Options) *Widget {
@@ -244,18 +269,24 @@ func New(model IModel, opts ...Options) *Widget {
 
 	// res acts as a ListWalker and a widget
 	res := &Widget{
-		listw: listw,
-		cur:   0,
-		cache: cache,
+		listw:  listw,
+		cur:    0,
+		cache:  cache,
+		source: model,
+		hfocus: -1,
 	}
 
 	res.FocusCallbacks = gowid.FocusCallbacks{CB: &res.Callbacks}
 
+	lopt := list.Options{
+		JumpKeys: opt.JumpKeys,
+	}
+
 	switch model.(type) {
 	case IBoundedModel:
-		listw.IWidget = list.NewBounded(&BoundedWidget{res})
+		listw.IWidget = list.NewBounded(&BoundedWidget{res}, lopt)
 	default:
-		listw.IWidget = list.New(res)
+		listw.IWidget = list.New(res, lopt)
 	}
 
 	res.update(listw, 0, model, opt)
@@ -417,7 +448,8 @@ func (w *Widget) CalculateOnScreen(size gowid.IRenderSize, focus gowid.Selector,
 func (w *Widget) SetModel(model IModel, app gowid.IApp) {
 	oldpos, olderr := w.FocusXY()
 	w.cache.Purge() // gcla later todo
-	w.update(w.listw, w.cur, model, w.opt)
+	w.source = model
+	w.update(w.listw, w.cur, w.view(model), w.opt)
 	if olderr == nil {
 		w.SetFocusXY(app, oldpos) // mght not be able to set old focus, if model shape has changed
 	} else {
@@ -456,10 +488,25 @@ func (w *Widget) Cache() *lru.Cache {
 	return w.cache
 }
 
+// Model returns the model the table renders. If some columns are hidden or reordered - see
+// SetColumnLayout - this is a view of the model provided.
 func (w *Widget) Model() IModel {
 	return w.model
 }
 
+// SourceModel returns the model provided to New or SetModel.
+func (w *Widget) SourceModel() IModel {
+	return w.source
+}
+
+// view returns the model to render for model, given the table's column layout.
+func (w *Widget) view(model IModel) IModel {
+	if w.layout == nil {
+		return model
+	}
+	return newColumnView(model, w.layout.Fit(model.Columns()))
+}
+
 func (w *Widget) CurrentRow() int {
 	return w.cur
 }
@@ -514,9 +561,68 @@ func (w *Widget) SetFocusOnData(app gowid.IApp) bool {
 	return cur != w.wrapper.Focus()
 }
 
+// StartJump moves focus to the table's rows, if there are any, and labels each visible row;
+// typing a label moves focus to that row. See list.IJump.
+func (w *Widget) StartJump(app gowid.IApp) {
+	if j, ok := w.listw.IWidget.(list.IJump); ok {
+		w.SetFocusOnData(app)
+		j.StartJump(app)
+	}
+}
+
+// CancelJump leaves jump mode without moving focus.
+func (w *Widget) CancelJump(app gowid.IApp) {
+	if j, ok := w.listw.IWidget.(list.IJump); ok {
+		j.CancelJump(app)
+	}
+}
+
+// Jumping returns true if the table is in jump mode.
+func (w *Widget) Jumping() bool {
+	if j, ok := w.listw.IWidget.(list.IJump); ok {
+		return j.Jumping()
+	}
+	return false
+}
+
+var _ list.IJump = (*Widget)(nil)
+
+// FindNext lets the app's find mode search rows beyond those displayed. See gowid.IFindable.
+func (w *Widget) FindNext(spec gowid.FindSpec, forward bool, app gowid.IApp) bool {
+	if f, ok := w.listw.IWidget.(gowid.IFindable); ok && f.FindNext(spec, forward, app) {
+		w.SetFocusOnData(app)
+		return true
+	}
+	return false
+}
+
+var _ gowid.IFindable = (*Widget)(nil)
+
 func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if evk, ok := ev.(*tcell.EventKey); ok {
+		switch {
+		case vim.KeyIn(evk, w.opt.ColumnChooserKeys):
+			w.OpenColumnChooser(app)
+			return true
+		case vim.KeyIn(evk, w.opt.SortKeys):
+			return w.sortByFocusColumn(app)
+		case vim.KeyIn(evk, w.opt.NarrowColumnKeys):
+			return w.resizeFocusColumn(-1, app)
+		case vim.KeyIn(evk, w.opt.WidenColumnKeys):
+			return w.resizeFocusColumn(1, app)
+		}
+	}
 	oldpos, olderr := w.FocusXY()
-	res := w.wrapper.UserInput(ev, size, focus, app)
+	var res bool
+	if wide, ok := w.wideSize(size); ok {
+		cols := size.(gowid.IRenderBox).BoxColumns()
+		res = w.wrapper.UserInput(gowid.TranslatedMouseEvent(ev, w.hoff, 0), wide, focus, app)
+		if !res {
+			res = w.scrollInput(ev, wide, cols)
+		}
+	} else {
+		res = w.wrapper.UserInput(ev, size, focus, app)
+	}
 	newpos, newerr := w.FocusXY()
 	if olderr != newerr || oldpos != newpos {
 		gowid.RunWidgetCallbacks(w.Callbacks, gowid.FocusCB{}, app, w)
@@ -525,6 +631,13 @@ func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.S
 }
 
 func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	if wide, ok := w.wideSize(size); ok {
+		w.lastCols = wide.C
+		return w.renderScrolled(wide, size.(gowid.IRenderBox).BoxColumns(), focus, app)
+	}
+	if cols, ok := size.(gowid.IColumns); ok {
+		w.lastCols = cols.Columns()
+	}
 	return w.wrapper.Render(size, focus, app)
 }
 
@@ -595,23 +708,30 @@ func (t *Widget) RowToWidget(ws []gowid.IWidget) gowid.IWidget {
 	return RowToWidget(t, ws)
 }
 
-func RowToWidget(t IRowToWidget, ws []gowid.IWidget) gowid.IWidget {
-	var res gowid.IWidget
-	if ws != nil {
-		cws := make([]gowid.IContainerWidget, 0)
+// RowToWidgets returns the cells of a row, sized by the model's widths, with the table's vertical
+// dividers between them - ready to lay out in a columns widget.
+func RowToWidgets(t IRowToWidget, ws []gowid.IWidget) []gowid.IContainerWidget {
+	cws := make([]gowid.IContainerWidget, 0)
+	if t.VertDivider() != nil {
+		cws = append(cws, t.VertDivider())
+	}
+	for i, w := range ws {
+		var dim gowid.IWidgetDimension = gowid.RenderWithWeight{1}
+		if t.Model().Widths() != nil && i < len(t.Model().Widths()) {
+			dim = t.Model().Widths()[i]
+		}
+		cws = append(cws, &gowid.ContainerWidget{w, dim})
 		if t.VertDivider() != nil {
 			cws = append(cws, t.VertDivider())
 		}
-		for i, w := range ws {
-			var dim gowid.IWidgetDimension = gowid.RenderWithWeight{1}
-			if t.Model().Widths() != nil && i < len(t.Model().Widths()) {
-				dim = t.Model().Widths()[i]
-			}
-			cws = append(cws, &gowid.ContainerWidget{w, dim})
-			if t.VertDivider() != nil {
-				cws = append(cws, t.VertDivider())
-			}
-		}
+	}
+	return cws
+}
+
+func RowToWidget(t IRowToWidget, ws []gowid.IWidget) gowid.IWidget {
+	var res gowid.IWidget
+	if ws != nil {
+		cws := RowToWidgets(t, ws)
 		colsWhenFocusOrSelected := columns.New(cws)
 		colsWhenNotSelected := columns.New(cws, columns.Options{
 			// Don't let columns set focus.select for selected child
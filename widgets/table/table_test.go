@@ -441,8 +441,141 @@ bbbbbbbbbbb,2
 
 }
 
+func TestColumnLayout1(t *testing.T) {
+	l := ColumnLayout{Order: []int{2, 0, 7, 2}, Hidden: []bool{true, false, false}}.Fit(4)
+	assert.Equal(t, []int{2, 0, 1, 3}, l.Order)
+	assert.Equal(t, []int{2, 1, 3}, l.Visible())
+	assert.False(t, l.IsDefault())
+	assert.True(t, DefaultColumnLayout(3).IsDefault())
+
+	l = ColumnLayout{Order: []int{1, 0}, Hidden: []bool{true, true}}.Fit(2)
+	assert.Equal(t, []int{1}, l.Visible())
+}
+
+func TestColumnChooser1(t *testing.T) {
+	gowid.DefaultCache = gowid.NewMemoryCache(10)
+
+	model := MyTable{
+		rows: [][]gowid.IWidget{
+			{makew("w1r0"), makew("w2r0"), makew("w3r0")},
+		},
+		ver: true,
+	}
+
+	sz := gowid.RenderFlowWith{C: 19}
+
+	w1 := New(model, Options{ColumnLayoutKey: "table.test"})
+	changed := 0
+	w1.OnColumnsChanged(gowid.WidgetCallback{"cb", func(app gowid.IApp, w gowid.IWidget) {
+		changed++
+	}})
+
+	chooser := NewColumnChooser(w1)
+	c1 := chooser.Render(gowid.RenderFlowWith{C: 14}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "[X] Column 1  \n[X] Column 2  \n[X] Column 3  ", c1.String())
+
+	chooser.UserInput(tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone), gowid.RenderFlowWith{C: 14}, gowid.Focused, gwtest.D)
+	assert.Equal(t, 1, changed)
+	c1 = w1.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "|w2r0    |w3r0    |", c1.String())
+
+	assert.True(t, chooser.Move(2, gwtest.D))
+	assert.Equal(t, 2, changed)
+	assert.Equal(t, []int{1, 2, 0}, w1.ColumnLayout().Order)
+
+	chooser.UserInput(tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone), gowid.RenderFlowWith{C: 14}, gowid.Focused, gwtest.D)
+	c1 = w1.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "|w2r0 |w3r0 |w1r0 |", c1.String())
+
+	w2 := New(model, Options{ColumnLayoutKey: "table.test"})
+	assert.True(t, w2.RestoreColumnLayout(gwtest.D))
+	assert.Equal(t, []int{1, 2, 0}, w2.ColumnLayout().Visible())
+}
+
+func TestSortResize1(t *testing.T) {
+	csv := strings.TrimSuffix(`
+1,c,-2
+3,a,1.2
+2,b,3.4
+`[1:], "\n")
+
+	sz := gowid.RenderFlowWith{C: 13}
+	t1 := NewCsvModel(strings.NewReader(csv), false, SimpleOptions{
+		Style: StyleOptions{
+			VerticalSeparator: fill.New('|'),
+		},
+	})
+	w1 := New(t1)
+	sorted := 0
+	w1.OnSorted(gowid.WidgetCallback{"cb", func(app gowid.IApp, w gowid.IWidget) {
+		sorted++
+	}})
+
+	assert.True(t, w1.SortByColumn(1, true, gwtest.D))
+	assert.Equal(t, 1, sorted)
+	col, asc := w1.SortColumn()
+	assert.Equal(t, 1, col)
+	assert.True(t, asc)
+	c1 := w1.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t,
+		strings.TrimSuffix(`
+|3  |a  |1.2|
+|2  |b  |3.4|
+|1  |c  |-2 |
+`[1:], "\n"), c1.String())
+
+	assert.True(t, w1.SortByColumn(0, false, gwtest.D))
+	c1 = w1.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t,
+		strings.TrimSuffix(`
+|3  |a  |1.2|
+|2  |b  |3.4|
+|1  |c  |-2 |
+`[1:], "\n"), c1.String())
+
+	assert.NoError(t, w1.ResizeColumn(0, 2, gwtest.D))
+	assert.Equal(t, []int{5, 0, 0}, w1.ColumnLayout().Widths)
+	c1 = w1.Render(gowid.RenderFlowWith{C: 15}, gowid.Focused, gwtest.D)
+	assert.Equal(t,
+		strings.TrimSuffix(`
+|3    |a  |1.2|
+|2    |b  |3.4|
+|1    |c  |-2 |
+`[1:], "\n"), c1.String())
+
+	assert.NoError(t, w1.ResetColumnWidths(gwtest.D))
+	assert.True(t, w1.ColumnLayout().IsDefault())
+
+	w2 := New(t1, Options{MinWidth: 13})
+	c1 = w2.Render(gowid.RenderBox{C: 7, R: 3}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "|3  |a \n|2  |b \n|1  |c ", c1.String())
+	w2.UserInput(tcell.NewEventMouse(0, 0, tcell.WheelRight, 0), gowid.RenderBox{C: 7, R: 3}, gowid.Focused, gwtest.D)
+	c1 = w2.Render(gowid.RenderBox{C: 7, R: 3}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "3  |a  \n2  |b  \n1  |c  ", c1.String())
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
 // fill-column: 110
 // End:
+
+func TestKeepView1(t *testing.T) {
+	t1 := NewCsvModel(strings.NewReader("a\nb\nc\nd\ne\nf"), false, SimpleOptions{})
+	w1 := New(t1)
+	sz := gowid.RenderBox{C: 1, R: 3}
+	for i := 0; i < 2; i++ {
+		w1.UserInput(tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	}
+	assert.Equal(t, "a\nb\nc", w1.Render(sz, gowid.Focused, gwtest.D).String())
+	a, ok := w1.Anchor(gwtest.D)
+	assert.True(t, ok)
+	assert.Equal(t, gowid.ViewAnchor{ID: RowId(2), Row: 2}, a)
+
+	// Row c stays in focus, at the bottom of the view
+	assert.True(t, gowid.KeepView(w1, gwtest.D, func(app gowid.IApp) {
+		w1.SortByColumn(0, false, app)
+	}))
+	assert.Equal(t, "e\nd\nc", w1.Render(sz, gowid.Focused, gwtest.D).String())
+	assert.False(t, w1.RestoreAnchor(gowid.ViewAnchor{ID: RowId(10)}, gwtest.D))
+}
@@ -0,0 +1,215 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package table
+
+import (
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// DefaultColumnWidth is the width a column is resized from if the width it is drawn at can't be worked out.
+var DefaultColumnWidth = 10
+
+// extent is where a column is drawn in a row of the table.
+type extent struct {
+	x, width int
+}
+
+// ResizeColumn makes the col'th column displayed delta columns wider, or narrower if delta is negative -
+// starting from the width it was last drawn at. It keeps at least one column. The width is part of the
+// column layout, so it is saved and restored along with it - see SetColumnLayout.
+func (w *Widget) ResizeColumn(col int, delta int, app gowid.IApp) error {
+	mcol := w.modelColumn(col)
+	if mcol == -1 {
+		return nil
+	}
+	layout := w.ColumnLayout()
+	width := layout.Width(mcol)
+	if width == 0 {
+		width = DefaultColumnWidth
+		if exts := w.columnExtents(w.lastCols, app); col < len(exts) {
+			width = exts[col].width
+		}
+	}
+	if layout.Widths == nil {
+		layout.Widths = make([]int, len(layout.Hidden))
+	}
+	layout.Widths[mcol] = gwutil.Max(1, width+delta)
+	return w.SetColumnLayout(layout, app)
+}
+
+// ResetColumnWidths gives every column the width the model asks for again.
+func (w *Widget) ResetColumnWidths(app gowid.IApp) error {
+	layout := w.ColumnLayout()
+	layout.Widths = nil
+	return w.SetColumnLayout(layout, app)
+}
+
+// resizeFocusColumn resizes the column in focus. It is bound to Options.NarrowColumnKeys and
+// Options.WidenColumnKeys.
+func (w *Widget) resizeFocusColumn(delta int, app gowid.IApp) bool {
+	pos, err := w.FocusXY()
+	if err != nil {
+		return false
+	}
+	w.ResizeColumn(pos.Column, delta, app)
+	return true
+}
+
+// columnExtents returns where each column displayed is drawn in a row cols wide, or nil if there are no rows
+// to measure.
+func (w *Widget) columnExtents(cols int, app gowid.IApp) []extent {
+	if cols <= 0 {
+		return nil
+	}
+	ws := w.model.HeaderWidgets()
+	if len(ws) == 0 {
+		if rid, ok := w.model.RowIdentifier(0); ok {
+			ws = w.model.CellWidgets(rid)
+		}
+	}
+	if len(ws) == 0 {
+		return nil
+	}
+	widths := columns.New(RowToWidgets(w, ws)).WidgetWidths(gowid.RenderFlowWith{C: cols}, gowid.NotSelected, -1, app)
+	res := make([]extent, 0, len(ws))
+	x := 0
+	for i, width := range widths {
+		if w.VertDivider() == nil || i%2 == 1 {
+			res = append(res, extent{x: x, width: width})
+		}
+		x += width
+	}
+	return res
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// wideSize returns the size at which to render the table, if it is to be scrolled sideways because size is
+// narrower than Options.MinWidth.
+func (w *Widget) wideSize(size gowid.IRenderSize) (gowid.RenderBox, bool) {
+	if box, ok := size.(gowid.IRenderBox); ok && box.BoxColumns() < w.opt.MinWidth {
+		return gowid.RenderBox{C: w.opt.MinWidth, R: box.BoxRows()}, true
+	}
+	return gowid.RenderBox{}, false
+}
+
+// followFocus scrolls a table that is wider than cols so that the column in focus is in view, if the focus
+// has moved to another column since the last render.
+func (w *Widget) followFocus(wide gowid.RenderBox, cols int, app gowid.IApp) {
+	maxOff := gwutil.Max(0, wide.C-cols)
+	w.hoff = gwutil.Min(w.hoff, maxOff)
+	pos, err := w.FocusXY()
+	if err != nil || pos.Column == w.hfocus {
+		return
+	}
+	w.hfocus = pos.Column
+	exts := w.columnExtents(wide.C, app)
+	if pos.Column >= len(exts) {
+		return
+	}
+	ext := exts[pos.Column]
+	w.hoff = gwutil.Min(maxOff, gwutil.Max(0, gwutil.Max(gwutil.Min(w.hoff, ext.x), ext.x+ext.width-cols)))
+}
+
+// renderScrolled renders the table at its minimum width, and returns the part scrolled into view.
+func (w *Widget) renderScrolled(wide gowid.RenderBox, cols int, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	w.followFocus(wide, cols, app)
+	res := w.wrapper.Render(wide, focus, app)
+	res.TrimLeft(wide.C - w.hoff)
+	res.TrimRight(cols)
+	if res.CursorEnabled() {
+		if pos := res.CursorCoords(); pos.X < 0 || pos.X >= cols {
+			res.SetCursorCoords(-1, -1)
+		}
+	}
+	return res
+}
+
+// scrollInput scrolls a table that is wider than it is drawn with the mouse wheel, returning true if it
+// was scrolled.
+func (w *Widget) scrollInput(ev interface{}, wide gowid.RenderBox, cols int) bool {
+	evm, ok := ev.(*tcell.EventMouse)
+	if !ok {
+		return false
+	}
+	off := w.hoff
+	switch evm.Buttons() {
+	case tcell.WheelLeft:
+		off--
+	case tcell.WheelRight:
+		off++
+	default:
+		return false
+	}
+	off = gwutil.Max(0, gwutil.Min(off, wide.C-cols))
+	if off == w.hoff {
+		return false
+	}
+	w.hoff = off
+	return true
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// ScrollValues returns the rows above, in and below the view, or - if the table is scrolled sideways, see
+// Options.MinWidth - the columns to the left, in and to the right of it.
+func (w *Widget) ScrollValues(o gowid.Orientation, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) (gowid.ScrollValues, bool) {
+	if o == gowid.Horizontal {
+		wide, ok := w.wideSize(size)
+		if !ok {
+			return gowid.ScrollValues{}, false
+		}
+		cols := size.(gowid.IRenderBox).BoxColumns()
+		return gowid.ScrollValues{Total: wide.C, Offset: gwutil.Min(w.hoff, wide.C-cols), Visible: cols}, true
+	}
+	if sv, lsize, ok := w.listScrollValues(size, focus, app); ok {
+		return sv.ScrollValues(o, lsize, focus, app)
+	}
+	return gowid.ScrollValues{}, false
+}
+
+// ScrollTo makes row offset the first in view, or scrolls the table sideways to column offset.
+func (w *Widget) ScrollTo(o gowid.Orientation, offset int, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) {
+	if o == gowid.Horizontal {
+		if wide, ok := w.wideSize(size); ok {
+			w.hoff = gwutil.Max(0, gwutil.Min(offset, wide.C-size.(gowid.IRenderBox).BoxColumns()))
+		}
+		return
+	}
+	if sv, lsize, ok := w.listScrollValues(size, focus, app); ok {
+		sv.ScrollTo(o, offset, lsize, focus, app)
+	}
+}
+
+// listScrollValues returns the list of the table's rows, and the size it is rendered at beneath the
+// header.
+func (w *Widget) listScrollValues(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) (gowid.IScrollValues, gowid.IRenderSize, bool) {
+	sv, ok := w.listw.IWidget.(gowid.IScrollValues)
+	if !ok {
+		return nil, nil, false
+	}
+	if wide, ok := w.wideSize(size); ok {
+		size = wide
+	}
+	_, sizes := pile.RenderedChildrenSizes(w.wrapper, size, focus, w.wrapper.Focus(), app)
+	for i, sub := range w.wrapper.SubWidgets() {
+		if sub == w.listw && i < len(sizes) {
+			return sv, sizes[i], true
+		}
+	}
+	return nil, nil, false
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,483 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package tabs provides a container widget that shows one of several widgets at a time, beneath a bar
+// of tabs that switch between them with the keyboard or the mouse.
+package tabs
+
+import (
+	"fmt"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/vim"
+	"github.com/gcla/gowid/widgets/null"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+//======================================================================
+
+// TabChangedCB is the callback name used when a different tab is selected.
+type TabChangedCB struct{}
+
+// TabsChangedCB is the callback name used when tabs are added, removed or moved.
+type TabsChangedCB struct{}
+
+var (
+	// DefaultNextKeys select the next tab, unless Options says otherwise.
+	DefaultNextKeys = []vim.KeyPress{vim.NewKeyPress(tcell.KeyPgDn, 0, tcell.ModCtrl)}
+	// DefaultPrevKeys select the previous tab, unless Options says otherwise.
+	DefaultPrevKeys = []vim.KeyPress{vim.NewKeyPress(tcell.KeyPgUp, 0, tcell.ModCtrl)}
+	// DefaultCloseLabel is shown on closable tabs, unless Options says otherwise.
+	DefaultCloseLabel = "x"
+)
+
+// Tab is a widget shown by the container, and how its tab is labeled.
+type Tab struct {
+	Label    string
+	Content  gowid.IWidget
+	Closable bool // If true, the tab has a close button, which removes it when clicked
+}
+
+// Options is used to configure the widget.
+type Options struct {
+	Style         gowid.ICellStyler // The style of the tabs not selected; if nil, they are unstyled
+	SelectedStyle gowid.ICellStyler // The style of the selected tab; defaults to reverse video
+	NextKeys      []vim.KeyPress    // Select the next tab; defaults to DefaultNextKeys
+	PrevKeys      []vim.KeyPress    // Select the previous tab; defaults to DefaultPrevKeys
+	CloseLabel    string            // The close button; defaults to DefaultCloseLabel
+}
+
+type ITabs interface {
+	Tabs() []Tab
+	Current() int
+	SetCurrent(i int, app gowid.IApp)
+}
+
+type IWidget interface {
+	gowid.IWidget
+	ITabs
+}
+
+// Widget shows the content of the selected tab beneath a bar of tab labels. If the labels don't all fit,
+// the bar scrolls to keep the selected tab in view, with arrows at either end showing there are more.
+// Input goes to the selected tab's content first; what it doesn't handle switches tabs - the next and
+// previous keys, a click on a label, or the mouse wheel over the bar.
+type Widget struct {
+	tabs  []Tab
+	cur   int // The selected tab, or -1 if there are none
+	first int // The first tab shown, if they don't all fit
+	opts  Options
+	*gowid.Callbacks
+	gowid.IsSelectable
+}
+
+func New(tabs []Tab, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.SelectedStyle == nil {
+		opt.SelectedStyle = gowid.MakeStyledAs(gowid.StyleReverse)
+	}
+	if opt.NextKeys == nil {
+		opt.NextKeys = DefaultNextKeys
+	}
+	if opt.PrevKeys == nil {
+		opt.PrevKeys = DefaultPrevKeys
+	}
+	if opt.CloseLabel == "" {
+		opt.CloseLabel = DefaultCloseLabel
+	}
+	res := &Widget{
+		tabs: append([]Tab(nil), tabs...),
+		cur:  -1,
+		opts: opt,
+	}
+	if len(tabs) > 0 {
+		res.cur = 0
+	}
+	var _ IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("tabs[%d/%d]", w.cur, len(w.tabs))
+}
+
+// Tabs returns a copy of the container's tabs, in the order they are shown.
+func (w *Widget) Tabs() []Tab {
+	return append([]Tab(nil), w.tabs...)
+}
+
+// Current returns the index of the selected tab, or -1 if there are no tabs.
+func (w *Widget) Current() int {
+	return w.cur
+}
+
+// SetCurrent selects the i'th tab. An index out of range is ignored.
+func (w *Widget) SetCurrent(i int, app gowid.IApp) {
+	if i < 0 || i >= len(w.tabs) || i == w.cur {
+		return
+	}
+	w.cur = i
+	gowid.RunWidgetCallbacks(w.Callbacks, TabChangedCB{}, app, w)
+}
+
+// Next selects the tab after the current one, returning false if it is the last.
+func (w *Widget) Next(app gowid.IApp) bool {
+	if w.cur+1 >= len(w.tabs) {
+		return false
+	}
+	w.SetCurrent(w.cur+1, app)
+	return true
+}
+
+// Prev selects the tab before the current one, returning false if it is the first.
+func (w *Widget) Prev(app gowid.IApp) bool {
+	if w.cur <= 0 {
+		return false
+	}
+	w.SetCurrent(w.cur-1, app)
+	return true
+}
+
+// AddTab adds a tab after the others. If it is the first, it is selected.
+func (w *Widget) AddTab(tab Tab, app gowid.IApp) {
+	w.InsertTab(len(w.tabs), tab, app)
+}
+
+// InsertTab adds a tab at index i, moving those from i on along one. The same tab stays selected.
+func (w *Widget) InsertTab(i int, tab Tab, app gowid.IApp) {
+	i = gwutil.Max(0, gwutil.Min(i, len(w.tabs)))
+	w.tabs = append(w.tabs, Tab{})
+	copy(w.tabs[i+1:], w.tabs[i:])
+	w.tabs[i] = tab
+	changed := false
+	switch {
+	case w.cur == -1:
+		w.cur = 0
+		changed = true
+	case i <= w.cur:
+		w.cur++
+	}
+	gowid.RunWidgetCallbacks(w.Callbacks, TabsChangedCB{}, app, w)
+	if changed {
+		gowid.RunWidgetCallbacks(w.Callbacks, TabChangedCB{}, app, w)
+	}
+}
+
+// RemoveTab removes the i'th tab, returning false if there is no such tab. If it was selected, the tab
+// that takes its place is selected - or the one before, if it was the last.
+func (w *Widget) RemoveTab(i int, app gowid.IApp) bool {
+	if i < 0 || i >= len(w.tabs) {
+		return false
+	}
+	w.tabs = append(w.tabs[:i], w.tabs[i+1:]...)
+	changed := false
+	switch {
+	case i < w.cur:
+		w.cur--
+	case i == w.cur:
+		w.cur = gwutil.Min(w.cur, len(w.tabs)-1)
+		changed = true
+	}
+	gowid.RunWidgetCallbacks(w.Callbacks, TabsChangedCB{}, app, w)
+	if changed {
+		gowid.RunWidgetCallbacks(w.Callbacks, TabChangedCB{}, app, w)
+	}
+	return true
+}
+
+// MoveTab moves the tab at index from to index to, returning false if either is out of range. The same
+// tab stays selected.
+func (w *Widget) MoveTab(from, to int, app gowid.IApp) bool {
+	if from < 0 || from >= len(w.tabs) || to < 0 || to >= len(w.tabs) {
+		return false
+	}
+	if from == to {
+		return true
+	}
+	tab := w.tabs[from]
+	if from < to {
+		copy(w.tabs[from:to], w.tabs[from+1:to+1])
+	} else {
+		copy(w.tabs[to+1:from+1], w.tabs[to:from])
+	}
+	w.tabs[to] = tab
+	switch {
+	case w.cur == from:
+		w.cur = to
+	case from < w.cur && w.cur <= to:
+		w.cur--
+	case to <= w.cur && w.cur < from:
+		w.cur++
+	}
+	gowid.RunWidgetCallbacks(w.Callbacks, TabsChangedCB{}, app, w)
+	return true
+}
+
+// OnTabChanged adds a callback run when a different tab is selected, including when the selected tab is
+// removed.
+func (w *Widget) OnTabChanged(f gowid.IWidgetChangedCallback) {
+	if w.Callbacks == nil {
+		w.Callbacks = gowid.NewCallbacks()
+	}
+	gowid.AddWidgetCallback(w.Callbacks, TabChangedCB{}, f)
+}
+
+func (w *Widget) RemoveOnTabChanged(f gowid.IIdentity) {
+	if w.Callbacks != nil {
+		gowid.RemoveWidgetCallback(w.Callbacks, TabChangedCB{}, f)
+	}
+}
+
+// OnTabsChanged adds a callback run when tabs are added, removed - including with a close button - or
+// moved.
+func (w *Widget) OnTabsChanged(f gowid.IWidgetChangedCallback) {
+	if w.Callbacks == nil {
+		w.Callbacks = gowid.NewCallbacks()
+	}
+	gowid.AddWidgetCallback(w.Callbacks, TabsChangedCB{}, f)
+}
+
+func (w *Widget) RemoveOnTabsChanged(f gowid.IIdentity) {
+	if w.Callbacks != nil {
+		gowid.RemoveWidgetCallback(w.Callbacks, TabsChangedCB{}, f)
+	}
+}
+
+// content returns the selected tab's widget.
+func (w *Widget) content() gowid.IWidget {
+	if w.cur == -1 || w.tabs[w.cur].Content == nil {
+		return null.New()
+	}
+	return w.tabs[w.cur].Content
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	switch sz := size.(type) {
+	case gowid.IRenderBox:
+		return gowid.RenderBox{C: sz.BoxColumns(), R: sz.BoxRows()}
+	case gowid.IRenderFlowWith:
+		content := w.content().RenderSize(size, focus, app)
+		return gowid.RenderBox{C: sz.FlowColumns(), R: content.BoxRows() + 1}
+	default:
+		panic(gowid.WidgetSizeError{Widget: w, Size: size, Required: "gowid.IRenderFlowWith or gowid.IRenderBox"})
+	}
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	box := w.RenderSize(size, focus, app)
+	cols, rows := box.BoxColumns(), box.BoxRows()
+	if rows == 0 {
+		return gowid.NewCanvasOfSize(cols, 0)
+	}
+	res := w.renderBar(w.layout(cols), cols, app)
+	if csize, ok := contentSize(size); ok {
+		res.AppendBelow(gowid.Render(w.content(), csize, focus, app), true, false)
+	}
+	return res
+}
+
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	cols := w.RenderSize(size, focus, app).BoxColumns()
+	if evm, ok := ev.(*tcell.EventMouse); ok {
+		if _, y := evm.Position(); y == 0 {
+			return w.barInput(evm, w.layout(cols), app)
+		}
+		ev = gowid.TranslatedMouseEvent(ev, 0, -1)
+	}
+	if csize, ok := contentSize(size); ok && gowid.UserInputIfSelectable(w.content(), ev, csize, focus, app) {
+		return true
+	}
+	if evk, ok := ev.(*tcell.EventKey); ok {
+		switch {
+		case vim.KeyIn(evk, w.opts.NextKeys):
+			return w.Next(app)
+		case vim.KeyIn(evk, w.opts.PrevKeys):
+			return w.Prev(app)
+		}
+	}
+	return false
+}
+
+// contentSize returns the size at which the selected tab's content is rendered, beneath the bar, and
+// false if there is no room for it.
+func contentSize(size gowid.IRenderSize) (gowid.IRenderSize, bool) {
+	if box, ok := size.(gowid.IRenderBox); ok {
+		if box.BoxRows() < 2 {
+			return nil, false
+		}
+		return gowid.RenderBox{C: box.BoxColumns(), R: box.BoxRows() - 1}, true
+	}
+	return size, true
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// barItem is where a tab's label is drawn in the bar.
+type barItem struct {
+	tab   int
+	x     int
+	width int
+	close int // The column of the close button, or -1 if the tab has none or it is cut off
+}
+
+// bar is the layout of the tab bar.
+type bar struct {
+	items       []barItem
+	left, right bool // True if there are tabs off either end of the bar, shown by an arrow
+}
+
+// label returns the text of the i'th tab's label, and the offset of its close button, or -1.
+func (w *Widget) label(i int) (string, int) {
+	tab := w.tabs[i]
+	res := " " + tab.Label + " "
+	if !tab.Closable {
+		return res, -1
+	}
+	return res + w.opts.CloseLabel + " ", runewidth.StringWidth(res)
+}
+
+// layout works out which tabs are shown in a bar cols wide. If they don't all fit, it scrolls the bar as
+// little as possible to show the selected tab, leaving a column at either end for the arrows.
+func (w *Widget) layout(cols int) bar {
+	total := 0
+	for i := range w.tabs {
+		l, _ := w.label(i)
+		total += runewidth.StringWidth(l)
+	}
+	start, end := 0, cols
+	if total > cols {
+		start, end = 1, cols-1
+	} else {
+		w.first = 0
+	}
+	avail := gwutil.Max(0, end-start)
+
+	if w.cur != -1 {
+		if w.cur < w.first {
+			w.first = w.cur
+		}
+		for w.first < w.cur {
+			width := 0
+			for i := w.first; i <= w.cur; i++ {
+				l, _ := w.label(i)
+				width += runewidth.StringWidth(l)
+			}
+			if width <= avail {
+				break
+			}
+			w.first++
+		}
+	}
+	w.first = gwutil.Max(0, gwutil.Min(w.first, len(w.tabs)-1))
+
+	res := bar{left: total > cols && w.first > 0}
+	x := start
+	last := w.first - 1
+	for i := w.first; i < len(w.tabs) && x < end; i++ {
+		l, close := w.label(i)
+		width := runewidth.StringWidth(l)
+		if x+width > end {
+			if i > w.first {
+				break
+			}
+			// Too wide for the bar on its own - cut it off
+			width = end - x
+		}
+		if close != -1 {
+			if close+runewidth.StringWidth(w.opts.CloseLabel) <= width {
+				close += x
+			} else {
+				close = -1
+			}
+		}
+		res.items = append(res.items, barItem{tab: i, x: x, width: width, close: close})
+		x += width
+		last = i
+	}
+	res.right = total > cols && last < len(w.tabs)-1
+	return res
+}
+
+func (w *Widget) renderBar(b bar, cols int, app gowid.IApp) gowid.ICanvas {
+	segs := make([]text.ContentSegment, 0, len(b.items)+3)
+	x := 0
+	if b.left {
+		segs = append(segs, text.StringContent("<"))
+		x = 1
+	}
+	for _, item := range b.items {
+		if item.x > x {
+			segs = append(segs, text.StringContent(fmt.Sprintf("%*s", item.x-x, "")))
+		}
+		l, _ := w.label(item.tab)
+		l = runewidth.Truncate(l, item.width, "")
+		l += fmt.Sprintf("%*s", item.width-runewidth.StringWidth(l), "")
+		style := w.opts.Style
+		if item.tab == w.cur {
+			style = w.opts.SelectedStyle
+		}
+		if style == nil {
+			segs = append(segs, text.StringContent(l))
+		} else {
+			segs = append(segs, text.StyledContent(l, style))
+		}
+		x = item.x + item.width
+	}
+	if b.right && cols > x {
+		segs = append(segs, text.StringContent(fmt.Sprintf("%*s>", cols-x-1, "")))
+	}
+	t := text.NewFromContentExt(text.NewContent(segs), text.Options{Wrap: text.WrapClip})
+	res := t.Render(gowid.RenderFlowWith{C: cols}, gowid.NotSelected, app)
+	if res.BoxRows() == 0 {
+		return gowid.NewCanvasOfSize(cols, 1)
+	}
+	return res
+}
+
+// barInput handles the mouse over the bar. A click on a label selects its tab, or closes it if on the
+// close button; a click on an arrow, or the wheel, selects the tab before or after.
+func (w *Widget) barInput(ev *tcell.EventMouse, b bar, app gowid.IApp) bool {
+	switch ev.Buttons() {
+	case tcell.WheelUp, tcell.WheelLeft:
+		return w.Prev(app)
+	case tcell.WheelDown, tcell.WheelRight:
+		return w.Next(app)
+	case tcell.Button1:
+		if app.GetLastMouseState().LeftIsClicked() {
+			return true
+		}
+	default:
+		return false
+	}
+	x, _ := ev.Position()
+	for _, item := range b.items {
+		if x < item.x || x >= item.x+item.width {
+			continue
+		}
+		if item.close != -1 && x >= item.close && x < item.close+runewidth.StringWidth(w.opts.CloseLabel) {
+			return w.RemoveTab(item.tab, app)
+		}
+		w.SetCurrent(item.tab, app)
+		return true
+	}
+	switch {
+	case b.left && x == 0:
+		return w.Prev(app)
+	case b.right && len(b.items) > 0 && x >= b.items[len(b.items)-1].x+b.items[len(b.items)-1].width:
+		return w.Next(app)
+	}
+	return false
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,76 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package tabs
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestTabs1(t *testing.T) {
+	w := New([]Tab{
+		{Label: "one", Content: text.New("first")},
+		{Label: "two", Content: text.New("second"), Closable: true},
+	})
+	changed := 0
+	w.OnTabChanged(gowid.WidgetCallback{"cb", func(app gowid.IApp, w gowid.IWidget) {
+		changed++
+	}})
+	sz := gowid.RenderBox{C: 16, R: 2}
+
+	c := w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, " one  two x     \nfirst           ", c.String())
+
+	assert.True(t, w.UserInput(tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModCtrl), sz, gowid.Focused, gwtest.D))
+	assert.Equal(t, 1, w.Current())
+	assert.Equal(t, 1, changed)
+	assert.False(t, w.UserInput(tcell.NewEventKey(tcell.KeyPgDn, 0, tcell.ModCtrl), sz, gowid.Focused, gwtest.D))
+	c = w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, " one  two x     \nsecond          ", c.String())
+
+	// Click on the first label, then on the second tab's close button
+	assert.True(t, w.UserInput(tcell.NewEventMouse(1, 0, tcell.Button1, 0), sz, gowid.Focused, gwtest.D))
+	assert.Equal(t, 0, w.Current())
+	assert.True(t, w.UserInput(tcell.NewEventMouse(10, 0, tcell.Button1, 0), sz, gowid.Focused, gwtest.D))
+	assert.Equal(t, 1, len(w.Tabs()))
+	assert.Equal(t, 0, w.Current())
+
+	w.InsertTab(0, Tab{Label: "zero"}, gwtest.D)
+	assert.Equal(t, 1, w.Current())
+	assert.True(t, w.MoveTab(1, 0, gwtest.D))
+	assert.Equal(t, 0, w.Current())
+	assert.Equal(t, "one", w.Tabs()[0].Label)
+}
+
+func TestOverflow1(t *testing.T) {
+	w := New([]Tab{{Label: "aaa"}, {Label: "bbb"}, {Label: "ccc"}, {Label: "ddd"}})
+	sz := gowid.RenderBox{C: 12, R: 1}
+
+	c := w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "  aaa  bbb >", c.String())
+
+	w.SetCurrent(3, gwtest.D)
+	c = w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "< ccc  ddd  ", c.String())
+
+	// The arrow selects the tab off that end
+	assert.True(t, w.UserInput(tcell.NewEventMouse(0, 0, tcell.Button1, 0), sz, gowid.Focused, gwtest.D))
+	assert.Equal(t, 2, w.Current())
+	assert.True(t, w.UserInput(tcell.NewEventMouse(0, 0, tcell.WheelUp, 0), sz, gowid.Focused, gwtest.D))
+	c = w.Render(sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "< bbb  ccc >", c.String())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,548 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package tailmux provides a widget that merges lines from several sources - channels or io.Readers,
+// like the logs of a set of services - into one chronological view, like tail -f of many files at once.
+// Each source's lines are labeled and colored, and sources can be paused or hidden.
+package tailmux
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/list"
+	"github.com/gcla/gowid/widgets/selectable"
+	"github.com/gcla/gowid/widgets/styled"
+	"github.com/gcla/gowid/widgets/text"
+)
+
+//======================================================================
+
+// DefaultMaxLines is the number of lines the widget keeps, unless Options says otherwise.
+var DefaultMaxLines = 10000
+
+// DefaultBuffer is the number of lines held for a source before they are displayed, unless SourceOptions
+// says otherwise.
+var DefaultBuffer = 1000
+
+// DefaultStyles are used in turn to style the labels of sources that don't specify a style.
+var DefaultStyles = []gowid.ICellStyler{
+	gowid.MakeForeground(gowid.ColorCyan),
+	gowid.MakeForeground(gowid.ColorGreen),
+	gowid.MakeForeground(gowid.ColorYellow),
+	gowid.MakeForeground(gowid.ColorMagenta),
+	gowid.MakeForeground(gowid.ColorOrange),
+	gowid.MakeForeground(gowid.ColorRed),
+}
+
+// Overflow says what happens to a source's new lines when its buffer is full - because lines arrive
+// faster than the app can display them, or the source is paused.
+type Overflow int
+
+const (
+	// Block stops reading from the source until there's room in its buffer, so a fast writer is slowed
+	// to the pace of the app.
+	Block Overflow = iota
+	// Drop discards new lines until there's room. The view shows how many were dropped.
+	Drop
+)
+
+// SourceOptions is used to configure a source.
+type SourceOptions struct {
+	Label     string                              // Shown before each line; defaults to the source's name
+	Style     gowid.ICellStyler                   // The style of the label; defaults to one of DefaultStyles
+	Buffer    int                                 // Lines held before they are displayed; defaults to DefaultBuffer
+	Overflow  Overflow                            // What to do with new lines when the buffer is full
+	Timestamp func(line string) (time.Time, bool) // If not nil, places a line by its own time, not when it arrived
+}
+
+func (o SourceOptions) buffer() int {
+	if o.Buffer <= 0 {
+		return DefaultBuffer
+	}
+	return o.Buffer
+}
+
+// Options is used to configure the widget.
+type Options struct {
+	MaxLines   int               // The most lines kept, oldest discarded first; defaults to DefaultMaxLines
+	StyleLines bool              // If true, each line is styled like its label, not just the label
+	FocusStyle gowid.ICellStyler // If not nil, the focused line is styled with this
+}
+
+func (o Options) maxLines() int {
+	if o.MaxLines <= 0 {
+		return DefaultMaxLines
+	}
+	return o.MaxLines
+}
+
+//======================================================================
+
+// Line is one line from a source.
+type Line struct {
+	Source  *Source
+	Time    time.Time // When the line arrived, or the time given by the source's Timestamp function
+	Text    string
+	Dropped int // If > 0, this line marks where the source dropped this many lines; Text is empty
+}
+
+// Source is a stream of lines displayed by the widget.
+type Source struct {
+	name    string
+	opts    SourceOptions
+	w       *Widget
+	paused  bool
+	hidden  bool
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []*Line // Received, but not yet displayed
+	dropped int
+	removed bool
+	done    bool
+	err     error
+}
+
+func (s *Source) Name() string {
+	return s.name
+}
+
+func (s *Source) Label() string {
+	if s.opts.Label != "" {
+		return s.opts.Label
+	}
+	return s.name
+}
+
+func (s *Source) Style() gowid.ICellStyler {
+	return s.opts.Style
+}
+
+func (s *Source) Paused() bool {
+	return s.paused
+}
+
+func (s *Source) Hidden() bool {
+	return s.hidden
+}
+
+// Done returns true once the source has no more lines, and any error reading from it.
+func (s *Source) Done() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done, s.err
+}
+
+// receive queues a line, or drops it if the buffer is full. If the source blocks, it waits until there's
+// room. It returns false if the source has been removed.
+func (s *Source) receive(line string, app gowid.IApp) bool {
+	t := gowid.ClockFor(app).Now()
+	if s.opts.Timestamp != nil {
+		if lt, ok := s.opts.Timestamp(line); ok {
+			t = lt
+		}
+	}
+	s.mu.Lock()
+	for s.opts.Overflow == Block && len(s.queue) >= s.opts.buffer() && !s.removed {
+		s.cond.Wait()
+	}
+	if s.removed {
+		s.mu.Unlock()
+		return false
+	}
+	if len(s.queue) >= s.opts.buffer() {
+		s.dropped++
+	} else {
+		s.queue = append(s.queue, &Line{Source: s, Time: t, Text: line})
+	}
+	s.mu.Unlock()
+	s.w.schedule(app)
+	return true
+}
+
+// stopWhenDone stops the source once ctx is done - when the app quits - so that its goroutine doesn't
+// wait forever for room in the queue.
+func (s *Source) stopWhenDone(ctx context.Context) {
+	if ctx.Done() == nil {
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		s.removed = true
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	}()
+}
+
+func (s *Source) finish(err error, app gowid.IApp) {
+	s.mu.Lock()
+	s.done = true
+	s.err = err
+	s.mu.Unlock()
+	s.w.schedule(app)
+}
+
+// take returns the lines waiting to be displayed, making room for more.
+func (s *Source) take(app gowid.IApp) []*Line {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := s.queue
+	if s.dropped > 0 {
+		t := gowid.ClockFor(app).Now()
+		if len(res) > 0 {
+			t = res[len(res)-1].Time
+		}
+		res = append(res, &Line{Source: s, Time: t, Dropped: s.dropped})
+		s.dropped = 0
+	}
+	s.queue = nil
+	s.cond.Broadcast()
+	return res
+}
+
+//======================================================================
+
+type IWidget interface {
+	list.IWidget
+	Sources() []*Source
+	Lines() []*Line
+}
+
+// Widget displays the lines of its sources, oldest first. While the last line is focused, the view
+// follows new lines as they arrive; moving the focus up stops it, so the user can read back without the
+// view moving.
+type Widget struct {
+	*list.Widget
+	opts    Options
+	walker  *walker
+	sources []*Source
+	lines   []*Line // All lines kept, oldest first
+	mu      sync.Mutex
+	pending bool // True if a flush has been scheduled with app.Run
+}
+
+func New(opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	res := &Widget{
+		opts: opt,
+	}
+	res.walker = &walker{w: res}
+	res.Widget = list.New(res.walker)
+	var _ IWidget = res
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("tailmux[%d sources, %d lines]", len(w.sources), len(w.lines))
+}
+
+// Sources returns the widget's sources, in the order they were added.
+func (w *Widget) Sources() []*Source {
+	res := make([]*Source, len(w.sources))
+	copy(res, w.sources)
+	return res
+}
+
+// Lines returns the lines the widget has kept, oldest first, including those of hidden sources.
+func (w *Widget) Lines() []*Line {
+	res := make([]*Line, len(w.lines))
+	copy(res, w.lines)
+	return res
+}
+
+func (w *Widget) addSource(name string, opts []SourceOptions) *Source {
+	var opt SourceOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Style == nil {
+		opt.Style = DefaultStyles[len(w.sources)%len(DefaultStyles)]
+	}
+	res := &Source{
+		name: name,
+		opts: opt,
+		w:    w,
+	}
+	res.cond = sync.NewCond(&res.mu)
+	w.sources = append(w.sources, res)
+	return res
+}
+
+// AddChannel adds a source that reads lines from ch until it is closed. Like the other methods of the
+// widget, it must be called from the app's goroutine; lines are read in a goroutine of their own.
+func (w *Widget) AddChannel(name string, ch <-chan string, app gowid.IApp, opts ...SourceOptions) *Source {
+	src := w.addSource(name, opts)
+	ctx := gowid.ContextFor(app)
+	src.stopWhenDone(ctx)
+	go func() {
+		for {
+			select {
+			case line, ok := <-ch:
+				if !ok {
+					src.finish(nil, app)
+					return
+				}
+				if !src.receive(line, app) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return src
+}
+
+// AddReader adds a source that reads lines from r until it returns an error, e.g. io.EOF.
+func (w *Widget) AddReader(name string, r io.Reader, app gowid.IApp, opts ...SourceOptions) *Source {
+	src := w.addSource(name, opts)
+	src.stopWhenDone(gowid.ContextFor(app))
+	go func() {
+		br := bufio.NewReader(r)
+		for {
+			line, err := br.ReadString('\n')
+			if line != "" {
+				if !src.receive(strings.TrimRight(line, "\r\n"), app) {
+					return
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				src.finish(err, app)
+				return
+			}
+		}
+	}()
+	return src
+}
+
+// RemoveSource stops reading from src and removes its lines from the view.
+func (w *Widget) RemoveSource(src *Source, app gowid.IApp) {
+	src.mu.Lock()
+	src.removed = true
+	src.queue = nil
+	src.cond.Broadcast()
+	src.mu.Unlock()
+	for i, s := range w.sources {
+		if s == src {
+			w.sources = append(w.sources[:i], w.sources[i+1:]...)
+			break
+		}
+	}
+	lines := w.lines[:0]
+	for _, l := range w.lines {
+		if l.Source != src {
+			lines = append(lines, l)
+		}
+	}
+	w.lines = lines
+	w.refresh(app)
+}
+
+// SetPaused pauses or resumes src. The lines of a paused source are held in its buffer, and displayed
+// when it's resumed; once the buffer is full, the source blocks or drops lines, as configured.
+func (w *Widget) SetPaused(src *Source, paused bool, app gowid.IApp) {
+	src.paused = paused
+	if !paused {
+		w.flush(app)
+	}
+}
+
+func (w *Widget) TogglePaused(src *Source, app gowid.IApp) {
+	w.SetPaused(src, !src.paused, app)
+}
+
+// SetHidden hides or shows the lines of src. A hidden source is still read, and its lines are kept.
+func (w *Widget) SetHidden(src *Source, hidden bool, app gowid.IApp) {
+	src.hidden = hidden
+	w.refresh(app)
+}
+
+func (w *Widget) ToggleHidden(src *Source, app gowid.IApp) {
+	w.SetHidden(src, !src.hidden, app)
+}
+
+// Clear discards the lines displayed so far.
+func (w *Widget) Clear(app gowid.IApp) {
+	w.lines = nil
+	w.refresh(app)
+}
+
+// schedule arranges for new lines to be displayed. It can be called from any goroutine; however many
+// lines arrive before the app gets to it, they are displayed together.
+func (w *Widget) schedule(app gowid.IApp) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pending {
+		return
+	}
+	w.pending = true
+	app.Run(gowid.RunFunction(func(app gowid.IApp) {
+		w.flush(app)
+	}))
+}
+
+// flush adds the lines waiting in each source that isn't paused to the view.
+func (w *Widget) flush(app gowid.IApp) {
+	w.mu.Lock()
+	w.pending = false
+	w.mu.Unlock()
+
+	for _, src := range w.sources {
+		if src.paused {
+			continue
+		}
+		for _, line := range src.take(app) {
+			w.insert(line)
+		}
+	}
+	if limit := w.opts.maxLines(); len(w.lines) > limit {
+		w.lines = append([]*Line(nil), w.lines[len(w.lines)-limit:]...)
+	}
+	w.refresh(app)
+}
+
+// insert adds line in time order. Lines usually arrive in order, so the search starts from the end.
+func (w *Widget) insert(line *Line) {
+	i := len(w.lines)
+	for i > 0 && w.lines[i-1].Time.After(line.Time) {
+		i--
+	}
+	w.lines = append(w.lines, nil)
+	copy(w.lines[i+1:], w.lines[i:])
+	w.lines[i] = line
+}
+
+// refresh rebuilds the view from the lines of the sources that aren't hidden, keeping the focus on the
+// same line - or on the last line, if it was there before.
+func (w *Widget) refresh(app gowid.IApp) {
+	wk := w.walker
+	following := wk.focus >= len(wk.visible)-1
+	var focused *Line
+	if !following {
+		focused = wk.visible[wk.focus]
+	}
+
+	wk.visible = wk.visible[:0]
+	wk.labelWidth = 0
+	for _, src := range w.sources {
+		if l := len(src.Label()); l > wk.labelWidth {
+			wk.labelWidth = l
+		}
+	}
+	wk.focus = -1
+	for _, l := range w.lines {
+		if l.Source.hidden {
+			continue
+		}
+		if l == focused {
+			wk.focus = len(wk.visible)
+		}
+		wk.visible = append(wk.visible, l)
+	}
+	if following || wk.focus == -1 {
+		wk.focus = len(wk.visible) - 1
+		if wk.focus < 0 {
+			wk.focus = 0
+		}
+		w.GoToBottom(app)
+	}
+}
+
+//======================================================================
+
+// walker presents the visible lines to the list.
+type walker struct {
+	w          *Widget
+	visible    []*Line
+	focus      int
+	labelWidth int
+}
+
+var _ list.IBoundedWalker = (*walker)(nil)
+var _ list.IWalkerHome = (*walker)(nil)
+var _ list.IWalkerEnd = (*walker)(nil)
+
+func (wk *walker) lineWidget(l *Line) gowid.IWidget {
+	label := fmt.Sprintf("%-*s ", wk.labelWidth, l.Source.Label())
+	msg := l.Text
+	if l.Dropped > 0 {
+		msg = fmt.Sprintf("[%d lines dropped]", l.Dropped)
+	}
+	var content []text.ContentSegment
+	if wk.w.opts.StyleLines || l.Dropped > 0 {
+		content = []text.ContentSegment{text.StyledContent(label+msg, l.Source.opts.Style)}
+	} else {
+		content = []text.ContentSegment{text.StyledContent(label, l.Source.opts.Style), text.StringContent(msg)}
+	}
+	var res gowid.IWidget = text.NewFromContent(text.NewContent(content))
+	if wk.w.opts.FocusStyle != nil {
+		res = styled.NewFocus(res, wk.w.opts.FocusStyle)
+	}
+	return selectable.New(res)
+}
+
+func (wk *walker) At(pos list.IWalkerPosition) gowid.IWidget {
+	i := int(pos.(list.ListPos))
+	if i < 0 || i >= len(wk.visible) {
+		return nil
+	}
+	return wk.lineWidget(wk.visible[i])
+}
+
+func (wk *walker) Focus() list.IWalkerPosition {
+	return list.ListPos(wk.focus)
+}
+
+func (wk *walker) SetFocus(pos list.IWalkerPosition, app gowid.IApp) {
+	wk.focus = int(pos.(list.ListPos))
+}
+
+func (wk *walker) Next(pos list.IWalkerPosition) list.IWalkerPosition {
+	i := int(pos.(list.ListPos))
+	if i+1 >= len(wk.visible) {
+		return list.ListPos(-1)
+	}
+	return list.ListPos(i + 1)
+}
+
+func (wk *walker) Previous(pos list.IWalkerPosition) list.IWalkerPosition {
+	return list.ListPos(int(pos.(list.ListPos)) - 1)
+}
+
+func (wk *walker) Length() int {
+	return len(wk.visible)
+}
+
+func (wk *walker) First() list.IWalkerPosition {
+	if len(wk.visible) == 0 {
+		return nil
+	}
+	return list.ListPos(0)
+}
+
+func (wk *walker) Last() list.IWalkerPosition {
+	if len(wk.visible) == 0 {
+		return nil
+	}
+	return list.ListPos(len(wk.visible) - 1)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,188 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package tailmux
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+// waitFor runs the sim's queued functions until cond holds, or fails the test after a second.
+func waitFor(t *testing.T, sim *gwtest.Sim, cond func() bool) {
+	for i := 0; i < 200; i++ {
+		sim.Frame()
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for lines")
+}
+
+func texts(w *Widget) []string {
+	res := make([]string, 0)
+	for _, l := range w.Lines() {
+		res = append(res, l.Text)
+	}
+	return res
+}
+
+func queued(src *Source) int {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	return len(src.queue)
+}
+
+func TestTail1(t *testing.T) {
+	w := New()
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 12, Rows: 3})
+	defer sim.Close()
+
+	web := make(chan string)
+	db := make(chan string)
+	srcWeb := w.AddChannel("web", web, sim)
+	srcDb := w.AddChannel("db", db, sim, SourceOptions{Label: "sql"})
+
+	web <- "w1"
+	waitFor(t, sim, func() bool { return len(w.Lines()) == 1 })
+	db <- "d1"
+	waitFor(t, sim, func() bool { return len(w.Lines()) == 2 })
+	web <- "w2"
+	waitFor(t, sim, func() bool { return len(w.Lines()) == 3 })
+	sim.Redraw()
+	sim.Frame()
+
+	sim.AssertLine(t, 0, "web w1      ")
+	sim.AssertLine(t, 1, "sql d1      ")
+	sim.AssertLine(t, 2, "web w2      ")
+	_, st := sim.Cell(0, 1)
+	fg, _, _ := st.Decompose()
+	assert.Equal(t, tcell.ColorGreen, fg)
+
+	// The view follows new lines
+	web <- "w3"
+	waitFor(t, sim, func() bool { return len(w.Lines()) == 4 })
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 2, "web w3      ")
+
+	w.SetHidden(srcWeb, true, sim)
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 0, "sql d1      ")
+	sim.AssertLine(t, 1, "            ")
+	w.ToggleHidden(srcWeb, sim)
+
+	// Lines of a paused source are held until it is resumed
+	w.SetPaused(srcDb, true, sim)
+	db <- "d2"
+	waitFor(t, sim, func() bool { return queued(srcDb) == 1 })
+	time.Sleep(time.Millisecond)
+	web <- "w4"
+	waitFor(t, sim, func() bool { return len(w.Lines()) == 5 })
+	assert.Equal(t, []string{"w1", "d1", "w2", "w3", "w4"}, texts(w))
+	w.TogglePaused(srcDb, sim)
+	assert.Equal(t, []string{"w1", "d1", "w2", "w3", "d2", "w4"}, texts(w))
+
+	close(db)
+	waitFor(t, sim, func() bool {
+		done, _ := srcDb.Done()
+		return done
+	})
+	w.RemoveSource(srcWeb, sim)
+	assert.Equal(t, []string{"d1", "d2"}, texts(w))
+	assert.Equal(t, []*Source{srcDb}, w.Sources())
+}
+
+func TestTail2(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := New(Options{MaxLines: 3})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 12, Rows: 3, Clock: clock})
+	defer sim.Close()
+
+	// Lines are placed by their own timestamps
+	stamp := func(line string) (time.Time, bool) {
+		d, err := time.ParseDuration(strings.Fields(line)[0])
+		if err != nil {
+			return time.Time{}, false
+		}
+		return clock.Now().Add(d), true
+	}
+	w.AddReader("a", strings.NewReader("1s a1\n3s a3\n"), sim, SourceOptions{Timestamp: stamp})
+	waitFor(t, sim, func() bool { return len(w.Lines()) == 2 })
+	w.AddReader("b", strings.NewReader("2s b2\r\n4s b4"), sim, SourceOptions{Timestamp: stamp})
+	waitFor(t, sim, func() bool { return len(w.Lines()) == 3 })
+	assert.Equal(t, []string{"2s b2", "3s a3", "4s b4"}, texts(w))
+}
+
+func TestTail3(t *testing.T) {
+	w := New()
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 30, Rows: 3})
+	defer sim.Close()
+
+	// A paused source drops lines once its buffer is full
+	ch := make(chan string)
+	src := w.AddChannel("x", ch, sim, SourceOptions{Buffer: 2, Overflow: Drop})
+	w.SetPaused(src, true, sim)
+	for _, l := range []string{"1", "2", "3", "4"} {
+		ch <- l
+	}
+	close(ch)
+	waitFor(t, sim, func() bool {
+		done, _ := src.Done()
+		return done
+	})
+	w.SetPaused(src, false, sim)
+	assert.Equal(t, []string{"1", "2", ""}, texts(w))
+	assert.Equal(t, 2, w.Lines()[2].Dropped)
+	sim.Redraw()
+	sim.Frame()
+	sim.AssertLine(t, 2, "x [2 lines dropped]           ")
+
+	// A blocking source stops reading until there's room
+	ch = make(chan string)
+	src = w.AddChannel("y", ch, sim, SourceOptions{Buffer: 1})
+	w.SetPaused(src, true, sim)
+	ch <- "1"
+	ch <- "2" // Read, but held until there's room
+	select {
+	case ch <- "3":
+		t.Fatalf("Source should block")
+	case <-time.After(20 * time.Millisecond):
+	}
+	w.SetPaused(src, false, sim)
+	ch <- "3"
+	waitFor(t, sim, func() bool { return len(w.Lines()) == 6 })
+	assert.Equal(t, []string{"1", "2", "", "1", "2", "3"}, texts(w))
+
+	// The user can scroll back without the view moving
+	sim.Key(tcell.KeyUp)
+	assert.Equal(t, 4, w.walker.focus)
+	ch <- "4"
+	waitFor(t, sim, func() bool { return len(w.Lines()) == 7 })
+	assert.Equal(t, 4, w.walker.focus)
+	sim.AssertLine(t, 1, "y 2                           ")
+
+	// Until they return to the end
+	sim.Key(tcell.KeyEnd)
+	ch <- "5"
+	waitFor(t, sim, func() bool { return len(w.Lines()) == 8 })
+	assert.Equal(t, 7, w.walker.focus)
+	sim.AssertLine(t, 2, "y 5                           ")
+	close(ch)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -8,6 +8,7 @@ package terminal
 
 import (
 	"bytes"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
@@ -1209,6 +1210,10 @@ func (c *Canvas) PushCursor(r rune) {
 	x, y := c.TermCursor()
 	wid := runewidth.RuneWidth(r)
 
+	if wid == 0 && c.combine(r, x, y) {
+		return
+	}
+
 	if !c.terminal.Modes().DontAutoWrap {
 		if x+wid == c.BoxColumns() && !c.isRottenCursor {
 			c.isRottenCursor = true
@@ -1236,6 +1241,22 @@ func (c *Canvas) PushCursor(r rune) {
 	}
 }
 
+// combine adds r, a rune with no width like an accent, to the character before the cursor at (x, y), so that
+// they are drawn as one grapheme cluster. It returns false if there is no character there.
+func (c *Canvas) combine(r rune, x, y int) bool {
+	if !c.isRottenCursor {
+		x--
+	}
+	if x >= 1 && runewidth.RuneWidth(c.CellAt(x-1, y).Rune()) == 2 {
+		x--
+	}
+	if x < 0 || y < 0 || x >= c.BoxColumns() || y >= c.BoxRows() || !c.CellAt(x, y).HasRune() {
+		return false
+	}
+	c.SetCellAt(x, y, c.CellAt(x, y).AddCombining(r))
+	return true
+}
+
 func (c *Canvas) PushRune(r rune, x, y int) {
 	r2 := c.charset.ApplyMapping(r)
 
@@ -1349,6 +1370,14 @@ func (c *Canvas) ParseOSC(osc []byte) {
 		c.RunCallbacks(Title{}, string(osc[1:]))
 	case len(osc) > 1 && osc[0] == '3' && osc[1] == ';':
 		c.RunCallbacks(Title{}, string(osc[2:]))
+	case bytes.HasPrefix(osc, []byte("52;")):
+		// 52;<selections>;<base64 data> - a query for the clipboard's contents has "?" as the data
+		parts := bytes.SplitN(osc[3:], []byte(";"), 2)
+		if len(parts) == 2 && string(parts[1]) != "?" {
+			if data, err := base64.StdEncoding.DecodeString(string(parts[1])); err == nil {
+				c.RunCallbacks(Clipboard{}, string(data))
+			}
+		}
 	}
 }
 
@@ -96,11 +96,13 @@ func (t HotKey) HotKey() tcell.Key {
 type Bell struct{}
 type LEDs struct{}
 type Title struct{}
+type Clipboard struct{}
 type ProcessExited struct{}
 
 type bell struct{}
 type leds struct{}
 type title struct{}
+type clipboard struct{}
 
 type Options struct {
 	Command           []string
@@ -108,6 +110,7 @@ type Options struct {
 	HotKey            IHotKeyProvider
 	HotKeyPersistence IHotKeyPersistence // the period of time a hotKey sticks after the first post-hotKey keypress
 	Scrollback        int
+	ForwardClipboard  bool // If true, text the application copies with OSC 52 is sent on to the system clipboard
 }
 
 // Widget is a widget that hosts a terminal-based application. The user provides the
@@ -126,10 +129,11 @@ type Widget struct {
 	curWidth, curHeight int
 	terminfo            *terminfo.Terminfo
 	title               string
+	clipboard           string
 	leds                LEDSState
 	hotKeyDown          bool
 	hotKeyDownTime      time.Time
-	hotKeyTimer         *time.Timer
+	hotKeyTimer         gowid.ITimer
 	isScrolling         bool
 	Callbacks           *gowid.Callbacks
 	gowid.IsSelectable
@@ -229,6 +233,30 @@ func (w *Widget) GetTitle() string {
 	return w.title
 }
 
+// SetClipboard is called when the application in the terminal copies text using an OSC 52 escape
+// sequence. If Options.ForwardClipboard is true, the text is sent on with app.CopyToClipboard(), so
+// that e.g. text yanked in vim reaches the system clipboard.
+func (w *Widget) SetClipboard(text string, app gowid.IApp) {
+	w.clipboard = text
+	if w.params.ForwardClipboard {
+		app.CopyToClipboard(text)
+	}
+	gowid.RunWidgetCallbacks(w.Callbacks, Clipboard{}, app, w)
+}
+
+// GetClipboard returns the text the application in the terminal last copied.
+func (w *Widget) GetClipboard() string {
+	return w.clipboard
+}
+
+func (w *Widget) OnClipboard(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w.Callbacks, Clipboard{}, f)
+}
+
+func (w *Widget) RemoveOnClipboard(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w.Callbacks, Clipboard{}, f)
+}
+
 func (w *Widget) OnProcessExited(f gowid.IWidgetChangedCallback) {
 	gowid.AddWidgetCallback(w.Callbacks, ProcessExited{}, f)
 }
@@ -265,8 +293,9 @@ func (w *Widget) SetHotKeyActive(app gowid.IApp, down bool) {
 	}
 
 	if down {
-		w.hotKeyDownTime = time.Now()
-		w.hotKeyTimer = time.AfterFunc(w.HotKeyDuration(), func() {
+		clock := gowid.ClockFor(app)
+		w.hotKeyDownTime = clock.Now()
+		w.hotKeyTimer = clock.AfterFunc(w.HotKeyDuration(), func() {
 			app.Run(gowid.RunFunction(func(app gowid.IApp) {
 				w.SetHotKeyActive(app, false)
 			}))
@@ -472,6 +501,13 @@ func (w *Widget) StartCommand(app gowid.IApp, width, height int) error {
 		}))
 	}})
 
+	canvas.AddCallback(Clipboard{}, gowid.Callback{clipboard{}, func(args ...interface{}) {
+		text := args[0].(string)
+		app.Run(gowid.RunFunction(func(app gowid.IApp) {
+			w.SetClipboard(text, app)
+		}))
+	}})
+
 	canvas.AddCallback(Bell{}, gowid.Callback{bell{}, func(args ...interface{}) {
 		app.Run(gowid.RunFunction(func(app gowid.IApp) {
 			w.Bell(app)
@@ -647,14 +683,13 @@ func init() {
 // avoid the extra process.
 func findTerminfo(name string) (*terminfo.Terminfo, error) {
 	cachedTerminfoMutex.Lock()
+	defer cachedTerminfoMutex.Unlock()
 	if ti, ok := cachedTerminfo[name]; ok {
-		cachedTerminfoMutex.Unlock()
 		return ti, nil
 	}
 	ti, _, e := dynamic.LoadTerminfo(name)
 	if e == nil {
 		cachedTerminfo[name] = ti
-		cachedTerminfoMutex.Unlock()
 		return ti, nil
 	}
 	ti, e = terminfo.LookupTerminfo(name)
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
 	"github.com/gcla/gowid/gwutil"
 	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/terminfo"
@@ -857,6 +858,26 @@ func TestCanvasVttest1(t *testing.T) {
 	AssertTermPositionIs(76, 3, c, t)
 }
 
+func TestClipboard1(t *testing.T) {
+	f := FakeTerminal{modes: &Modes{}}
+	c := NewCanvasOfSize(10, 1, 100, &f)
+	copied := make([]string, 0)
+	c.AddCallback(Clipboard{}, gowid.Callback{"cb", func(args ...interface{}) {
+		copied = append(copied, args[0].(string))
+	}})
+
+	_, err := io.Copy(c, strings.NewReader("a\x1b]52;c;aGVsbG8=\x07b\x1b]52;c;?\x07c\x1b]52;p;d29ybGQ=\x1b\\d"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hello", "world"}, copied)
+	assert.Equal(t, "abcd      ", c.String())
+
+	w, err := NewExt(Options{Command: []string{"true"}, ForwardClipboard: true})
+	assert.NoError(t, err)
+	w.SetClipboard("hello", gwtest.D)
+	assert.Equal(t, "hello", w.GetClipboard())
+	assert.Equal(t, "hello", gwtest.D.ClipboardContents())
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
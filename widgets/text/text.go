@@ -8,12 +8,12 @@ package text
 import (
 	"fmt"
 	"io"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/gcla/gowid"
 	"github.com/gcla/gowid/gwutil"
-	"github.com/mattn/go-runewidth"
 )
 
 //======================================================================
@@ -87,6 +87,12 @@ func StyledContent(text string, style gowid.ICellStyler) ContentSegment {
 	return ContentSegment{style, text}
 }
 
+// LinkedContent makes a ContentSegment whose text links to url, styled with style if one is
+// provided. See gowid.Hyperlink.
+func LinkedContent(text string, url string, style ...gowid.ICellStyler) ContentSegment {
+	return ContentSegment{gowid.MakeHyperlink(url, style...), text}
+}
+
 // StyledRune is a styled rune.
 type StyledRune struct {
 	Chr  rune
@@ -159,6 +165,7 @@ func (h Content) RangeOver(start, end int, attrs gowid.IRenderContext, proc gowi
 	var s gowid.StyleAttrs
 	var f2 gowid.TCellColor
 	var g2 gowid.TCellColor
+	var link string
 
 	for idx, j := start, 0; idx < end; idx, j = idx+1, j+1 {
 		if h[idx].Attr != nil {
@@ -166,9 +173,10 @@ func (h Content) RangeOver(start, end int, attrs gowid.IRenderContext, proc gowi
 				f, g, s = h[idx].Attr.GetStyle(attrs)
 				f2 = gowid.IColorToTCell(f, gowid.ColorNone, attrs.GetColorMode())
 				g2 = gowid.IColorToTCell(g, gowid.ColorNone, attrs.GetColorMode())
+				link = gowid.HyperlinkOf(h[idx].Attr)
 				curStyler = h[idx].Attr
 			}
-			proc.ProcessCell(gowid.MakeCell(h[idx].Chr, f2, g2, s))
+			proc.ProcessCell(gowid.MakeCell(h[idx].Chr, f2, g2, s).WithHyperlink(link))
 		} else {
 			proc.ProcessCell(gowid.MakeCell(h[idx].Chr, gowid.ColorNone, gowid.ColorNone, gowid.StyleNone))
 		}
@@ -185,13 +193,10 @@ func (h Content) Length() int {
 	return len(h)
 }
 
-// Width returns the number of screen cells the content takes. Different from Length if >1-width runes are used.
+// Width returns the number of screen cells the content takes. Different from Length if >1-width runes, or
+// grapheme clusters of several runes, are used.
 func (h Content) Width() int {
-	res := 0
-	for _, r := range h {
-		res += runewidth.RuneWidth(r.Chr)
-	}
-	return res
+	return gowid.StringWidth(h.String())
 }
 
 // String implements fmt.Stringer.
@@ -221,6 +226,7 @@ type Widget struct {
 	text         IContent
 	wrap         WrapType
 	align        gowid.IHAlignment
+	direction    gowid.TextDirection
 	opts         Options
 	linesFromTop int
 	Callbacks    *gowid.Callbacks
@@ -231,6 +237,7 @@ type Widget struct {
 var _ gowid.IWidget = (*Widget)(nil)
 var _ io.Reader = (*Widget)(nil)
 var _ fmt.Stringer = (*Widget)(nil)
+var _ gowid.IScrollValues = (*Widget)(nil)
 
 type CopyableWidget struct {
 	*Widget
@@ -266,11 +273,27 @@ type IWidget interface {
 	ClipIndicator() string
 }
 
+// IDirection is implemented by text widgets whose paragraphs can be made to run in one direction,
+// rather than the direction of their first letter. This package's Widget type implements it.
+type IDirection interface {
+	Direction() gowid.TextDirection
+}
+
+// DirectionOf returns the direction of w's paragraphs if it is an IDirection, otherwise
+// gowid.DirectionAuto.
+func DirectionOf(w interface{}) gowid.TextDirection {
+	if d, ok := w.(IDirection); ok {
+		return d.Direction()
+	}
+	return gowid.DirectionAuto
+}
+
 // Options is used to provide arguments to the various New initialization functions.
 type Options struct {
 	Wrap          WrapType
 	ClipIndicator string
 	Align         gowid.IHAlignment
+	Direction     gowid.TextDirection // If DirectionRTL and Align is nil, lines are aligned right
 }
 
 // New initializes a text widget with a string and some extra arguments e.g. to align
@@ -308,12 +331,17 @@ func NewFromContent(content IContent) *Widget {
 // as wrapping, alignment, etc.
 func NewFromContentExt(content IContent, opts Options) *Widget {
 	if opts.Align == nil {
-		opts.Align = gowid.HAlignLeft{}
+		if opts.Direction == gowid.DirectionRTL {
+			opts.Align = gowid.HAlignRight{}
+		} else {
+			opts.Align = gowid.HAlignLeft{}
+		}
 	}
 	res := &Widget{
 		text:      content,
 		wrap:      opts.Wrap,
 		align:     opts.Align,
+		direction: opts.Direction,
 		opts:      opts,
 		Callbacks: gowid.NewCallbacks(),
 	}
@@ -324,6 +352,19 @@ func (w *Widget) String() string {
 	return fmt.Sprintf("text")
 }
 
+func (w *Widget) AccessibleRole() gowid.Role {
+	return gowid.RoleText
+}
+
+// AccessibleLabel returns the text displayed, with whitespace at either end removed.
+func (w *Widget) AccessibleLabel(app gowid.IApp) string {
+	runes := make([]rune, 0, w.Content().Length())
+	for i := 0; i < w.Content().Length(); i++ {
+		runes = append(runes, w.Content().ChrAt(i))
+	}
+	return strings.TrimSpace(string(runes))
+}
+
 // Writer is a wrapper around a text Widget which, by including the app, can be used
 // to implement io.Writer.
 type Writer struct {
@@ -376,6 +417,12 @@ func (w *Widget) SetText(text string, app gowid.IApp) {
 	w.SetContent(app, NewContent([]ContentSegment{*content}))
 }
 
+// CopyToClipboard sends the widget's text, without styling, to the system clipboard with
+// app.CopyToClipboard().
+func (w *Widget) CopyToClipboard(app gowid.IApp) error {
+	return app.CopyToClipboard(w.Content().String())
+}
+
 func (w *Widget) Wrap() WrapType {
 	return w.wrap
 }
@@ -392,6 +439,15 @@ func (w *Widget) SetAlign(align gowid.IHAlignment, app gowid.IApp) {
 	w.align = align
 }
 
+// Direction returns the direction of the widget's paragraphs - by default, gowid.DirectionAuto.
+func (w *Widget) Direction() gowid.TextDirection {
+	return w.direction
+}
+
+func (w *Widget) SetDirection(dir gowid.TextDirection, app gowid.IApp) {
+	w.direction = dir
+}
+
 func (w *Widget) LinesFromTop() int {
 	return w.linesFromTop
 }
@@ -400,6 +456,23 @@ func (w *Widget) SetLinesFromTop(l int, app gowid.IApp) {
 	w.linesFromTop = l
 }
 
+// ScrollValues returns the lines of text above, in and below the view, when the widget is rendered as a
+// box. Text rendered any other way is shown in full, so doesn't scroll.
+func (w *Widget) ScrollValues(o gowid.Orientation, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) (gowid.ScrollValues, bool) {
+	if _, ok := size.(gowid.IRenderBox); !ok || o != gowid.Vertical {
+		return gowid.ScrollValues{}, false
+	}
+	top, middle, bottom := CalculateTopMiddleBottom(w, size)
+	return gowid.ScrollValues{Total: top + middle + bottom, Offset: top, Visible: middle}, true
+}
+
+// ScrollTo makes line offset the first in view.
+func (w *Widget) ScrollTo(o gowid.Orientation, offset int, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) {
+	if o == gowid.Vertical {
+		w.SetLinesFromTop(gwutil.Max(0, offset), app)
+	}
+}
+
 func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
 	return gowid.CalculateRenderSizeFallback(w, size, focus, app)
 }
@@ -463,6 +536,7 @@ func CalculateTopMiddleBottom(w IWidget, size gowid.IRenderSize) (int, int, int)
 	}
 
 	layout := MakeTextLayout(content, maxCol, w.Wrap(), w.Align())
+	layout.Direction = DirectionOf(w)
 
 	if cursor {
 		_, crow = GetCoordsFromCursorPos(cursorPos, maxCol, layout, w.Content())
@@ -489,15 +563,34 @@ func CalculateTopMiddleBottom(w IWidget, size gowid.IRenderSize) (int, int, int)
 // ContentToCellArray is a helper type; it can be used to construct a Cell array by passing
 // it to a RangeOver() function.
 type ContentToCellArray struct {
-	Cells []gowid.Cell
-	Cur   int
+	Cells    []gowid.Cell
+	Cur      int
+	grapheme gowid.GraphemeState
+	last     int  // The cell holding the current grapheme cluster
+	started  bool // True once a cell has been filled
 }
 
 var _ gowid.ICellProcessor = (*ContentToCellArray)(nil)
 
 func (m *ContentToCellArray) ProcessCell(cell gowid.Cell) gowid.Cell {
+	if !cell.HasRune() {
+		return cell
+	}
+	first, wid := m.grapheme.Add(cell.Rune())
+	if !first && m.started {
+		// Part of the grapheme cluster in the last cell, like an accent, which may widen it
+		m.Cells[m.last] = m.Cells[m.last].AddCombining(cell.Rune())
+		m.Cur += wid
+		return cell
+	}
+	// Runes with no width, like NUL, take up no columns in the text layout, so
+	// mustn't occupy a cell here
+	if wid == 0 {
+		return cell
+	}
 	m.Cells[m.Cur] = cell
-	m.Cur += runewidth.RuneWidth(cell.Rune())
+	m.last, m.started = m.Cur, true
+	m.Cur += wid
 	return cell
 }
 
@@ -529,6 +622,7 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 			curcol := 0
 			maxRow = 1
 			var last rune
+			widths := segmentWidths(w.Content(), 0, w.Content().Length())
 			// This is lame - find a better way
 			for i := 0; i < w.Content().Length(); i++ {
 				last = w.Content().ChrAt(i)
@@ -539,7 +633,7 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 					}
 					curcol = 0
 				} else {
-					curcol += runewidth.RuneWidth(last)
+					curcol += widths[i]
 				}
 			}
 			if curcol > maxCol {
@@ -561,6 +655,8 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	}
 
 	layout := MakeTextLayout(content, maxCol, w.Wrap(), w.Align())
+	layout.Direction = DirectionOf(w)
+	bidi := layout.bidi(content, 0, content.Length())
 
 	lines := make([][]gowid.Cell, len(layout.Lines))
 
@@ -572,7 +668,11 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 		// Make enough cells to be able to render double-width runes. The second cell will be left
 		// empty.
 		lines[x] = make([]gowid.Cell, segment.EndWidth-segment.StartWidth)
-		w.Content().RangeOver(segment.StartLength, segment.EndLength, app, &ContentToCellArray{Cells: lines[x]})
+		if bidi == nil {
+			w.Content().RangeOver(segment.StartLength, segment.EndLength, app, &ContentToCellArray{Cells: lines[x]})
+		} else {
+			visualLine(lines[x], segment, bidi, w.Content(), app)
+		}
 		if segment.Clipped {
 			//for i := len(w.ClipIndicator())-1; i >=0; i-- {
 			ind := w.ClipIndicator()
@@ -648,6 +748,62 @@ type IChrAt interface {
 	ChrAt(i int) rune
 }
 
+// segmentWidths returns the width of each rune from index start to end, with the width of each grapheme
+// cluster given to its first rune - see gowid.GraphemeWidths.
+func segmentWidths(at IChrAt, start int, end int) []int {
+	return gowid.GraphemeWidths(runesOf(at, start, end))
+}
+
+// cellList is an ICellProcessor that collects the cells it is given.
+type cellList []gowid.Cell
+
+func (l *cellList) ProcessCell(cell gowid.Cell) gowid.Cell {
+	*l = append(*l, cell)
+	return cell
+}
+
+// visualLine fills cells with the content of line in the order it is displayed, reversing runs of
+// right-to-left text and mirroring their brackets.
+func visualLine(cells []gowid.Cell, line LineLayout, bidi *gowid.BidiText, content IContent, app gowid.IRenderContext) {
+	logical := make(cellList, 0, line.EndLength-line.StartLength)
+	content.RangeOver(line.StartLength, line.EndLength, app, &logical)
+	starts := gowid.GraphemeStarts(runesOf(content, line.StartLength, line.EndLength))
+	arr := &ContentToCellArray{Cells: cells}
+	for _, i := range bidi.VisualOrder(line.StartLength, line.EndLength) {
+		cell := logical[i-line.StartLength]
+		if starts[i-line.StartLength] {
+			// Clusters are no longer next to the same neighbors, so mustn't join them
+			arr.grapheme.Reset()
+		}
+		if bidi.Level(i)%2 == 1 && cell.HasRune() {
+			cell = cell.WithRune(gowid.BidiMirror(cell.Rune()))
+		}
+		arr.ProcessCell(cell)
+	}
+}
+
+func runesOf(at IChrAt, start int, end int) []rune {
+	res := make([]rune, gwutil.Max(0, end-start))
+	for i := range res {
+		res[i] = at.ChrAt(start + i)
+	}
+	return res
+}
+
+// visualColumns returns the column at which each rune of line is displayed, taking the direction of the
+// text into account, and the widths of the runes. bidi covers the paragraph holding line, which starts at
+// index para.
+func visualColumns(line LineLayout, bidi *gowid.BidiText, para int, at IChrAt) ([]int, []int) {
+	widths := segmentWidths(at, line.StartLength, line.EndLength)
+	cols := make([]int, len(widths))
+	col := 0
+	for _, i := range bidi.VisualOrder(line.StartLength-para, line.EndLength-para) {
+		cols[i+para-line.StartLength] = col
+		col += widths[i+para-line.StartLength]
+	}
+	return cols, widths
+}
+
 // zero-based
 func GetCoordsFromCursorPos(cursorPos int, maxCol int, layout *TextLayout, at IChrAt) (x int, y int) {
 	var crow, ccol int
@@ -656,9 +812,30 @@ func GetCoordsFromCursorPos(cursorPos int, maxCol int, layout *TextLayout, at IC
 			crow = lineNumber
 
 			ccol = 0
-			for i := segment.StartLength; i < gwutil.Min(segment.EndLength, cursorPos); i++ {
-				ccol += runewidth.RuneWidth(at.ChrAt(i))
+			if bidi, para := layout.paragraph(at, segment); bidi != nil {
+				cols, widths := visualColumns(segment, bidi, para, at)
+				switch {
+				case cursorPos < segment.EndLength:
+					ccol = cols[cursorPos-segment.StartLength]
+				case cursorPos > segment.StartLength:
+					// At the end of the line, the cursor follows the last cluster in its direction
+					last := cursorPos - 1 - segment.StartLength
+					for last > 0 && widths[last] == 0 {
+						last--
+					}
+					if bidi.Level(segment.StartLength+last-para)%2 == 0 {
+						ccol = cols[last] + widths[last]
+					} else {
+						ccol = cols[last] - 1
+					}
+				}
+			} else {
+				widths := segmentWidths(at, segment.StartLength, gwutil.Min(segment.EndLength, cursorPos))
+				for _, wid := range widths {
+					ccol += wid
+				}
 			}
+			ccol = gwutil.Max(0, ccol+layout.indent(segment))
 		}
 	}
 	return ccol, crow
@@ -678,15 +855,42 @@ func GetCursorPosFromCoords(ccol int, crow int, layout *TextLayout, at IChrAt) i
 		startw := layout.Lines[crow].StartWidth
 		endw := layout.Lines[crow].EndWidth
 
+		ccol -= layout.indent(layout.Lines[crow])
+		if bidi, para := layout.paragraph(at, layout.Lines[crow]); bidi != nil {
+			return cursorPosFromVisualCol(ccol, layout.Lines[crow], bidi, para, at)
+		}
+
+		// Stop at the start of a grapheme cluster, rather than among its runes
+		widths := segmentWidths(at, start, layout.Lines[crow].EndLength)
 		col := 0
-		for i := 0; i < gwutil.Min(endw-startw, ccol); {
-			i += runewidth.RuneWidth(at.ChrAt(col + start))
+		for i := 0; i < gwutil.Min(endw-startw, ccol) && col < len(widths); {
+			i += widths[col]
 			col += 1
+			for col < len(widths) && widths[col] == 0 {
+				col += 1
+			}
 		}
 		return start + col
 	}
 }
 
+// cursorPosFromVisualCol returns the position of the cluster displayed at column ccol of line, taking the
+// direction of the text into account. Beyond the text, it returns the start or end of the line, whichever
+// is displayed on that side.
+func cursorPosFromVisualCol(ccol int, line LineLayout, bidi *gowid.BidiText, para int, at IChrAt) int {
+	cols, widths := visualColumns(line, bidi, para, at)
+	for i := range cols {
+		if widths[i] > 0 && cols[i] <= ccol && ccol < cols[i]+widths[i] {
+			return line.StartLength + i
+		}
+	}
+	rtl := bidi.BaseLevel(line.StartLength-para)%2 == 1
+	if (ccol < 0) == rtl {
+		return line.EndLength
+	}
+	return line.StartLength
+}
+
 //======================================================================
 
 type LineLayout struct {
@@ -698,7 +902,49 @@ type LineLayout struct {
 }
 
 type TextLayout struct {
-	Lines []LineLayout
+	Lines     []LineLayout
+	Width     int
+	Align     gowid.IHAlignment
+	Direction gowid.TextDirection // Set this before finding cursor positions if not gowid.DirectionAuto
+	length    int
+}
+
+// indent returns the number of columns by which line is moved right to align it.
+func (l *TextLayout) indent(line LineLayout) int {
+	width := line.EndWidth - line.StartWidth
+	if width >= l.Width {
+		return 0
+	}
+	switch l.Align.(type) {
+	case gowid.HAlignRight:
+		return l.Width - width
+	case gowid.HAlignMiddle:
+		return (l.Width - width) / 2
+	default:
+		return 0
+	}
+}
+
+// bidi resolves the direction of the text from start to end, or returns nil if it all runs left-to-right.
+func (l *TextLayout) bidi(at IChrAt, start int, end int) *gowid.BidiText {
+	res := gowid.NewBidiText(runesOf(at, start, end), l.Direction)
+	if res.LeftToRight() {
+		return nil
+	}
+	return res
+}
+
+// paragraph resolves the direction of the paragraph holding line, and returns it with the index at which
+// the paragraph starts - or nil if the paragraph runs left-to-right.
+func (l *TextLayout) paragraph(at IChrAt, line LineLayout) (*gowid.BidiText, int) {
+	start, end := line.StartLength, line.EndLength
+	for start > 0 && at.ChrAt(start-1) != '\n' {
+		start--
+	}
+	for end < l.length && at.ChrAt(end) != '\n' {
+		end++
+	}
+	return l.bidi(at, start, end), start
 }
 
 // MakeTextLayout builds an array of line layouts from an IContent object. It applies the provided
@@ -714,9 +960,10 @@ func MakeTextLayout(content IContent, width int, wrap WrapType, align gowid.IHAl
 			skippingToEndOfLine := false // true if we had to cut off the text and are looking for a newline
 			startOfCurrentLineLength := 0
 			startOfCurrentLineWidth := 0
+			widths := segmentWidths(content, 0, content.Length())
 			for startOfCurrentLineLength+indexInLineLength < content.Length() {
 				c := content.ChrAt(startOfCurrentLineLength + indexInLineLength)
-				wid := runewidth.RuneWidth(c)
+				wid := widths[startOfCurrentLineLength+indexInLineLength]
 				if !skippingToEndOfLine && indexInLineWidth+wid > width { // end of space and no newline found
 					lines = append(lines, LineLayout{
 						StartLength: startOfCurrentLineLength,
@@ -763,9 +1010,11 @@ func MakeTextLayout(content IContent, width int, wrap WrapType, align gowid.IHAl
 			indexInSegmentWidth := 0  // current line index
 			startOfCurrentSegmentLength := 0
 			startOfCurrentSegmentWidth := 0
+			widths := segmentWidths(content, 0, content.Length())
 			for startOfCurrentSegmentLength+indexInSegmentLength < content.Length() {
 				c := content.ChrAt(startOfCurrentSegmentLength + indexInSegmentLength)
-				if indexInSegmentWidth+runewidth.RuneWidth(c) > width { // end of space and no newline found
+				wid := widths[startOfCurrentSegmentLength+indexInSegmentLength]
+				if indexInSegmentWidth+wid > width { // end of space and no newline found
 					lines = append(lines, LineLayout{
 						StartLength: startOfCurrentSegmentLength,
 						StartWidth:  startOfCurrentSegmentWidth,
@@ -790,7 +1039,7 @@ func MakeTextLayout(content IContent, width int, wrap WrapType, align gowid.IHAl
 					indexInSegmentLength = 0
 					indexInSegmentWidth = 0
 				} else {
-					indexInSegmentWidth += runewidth.RuneWidth(c)
+					indexInSegmentWidth += wid
 					indexInSegmentLength += 1
 				}
 			}
@@ -806,7 +1055,12 @@ func MakeTextLayout(content IContent, width int, wrap WrapType, align gowid.IHAl
 			panic(fmt.Errorf("Wrap %v not supported yet", wrap))
 		}
 	}
-	return &TextLayout{lines}
+	return &TextLayout{
+		Lines:  lines,
+		Width:  width,
+		Align:  align,
+		length: content.Length(),
+	}
 }
 
 //======================================================================
@@ -398,6 +398,73 @@ func TestChinese1(t *testing.T) {
 	assert.Equal(t, "|你|好|，|世|界|", c1.String())
 }
 
+func TestZeroWidth1(t *testing.T) {
+	// Zero-width runes don't occupy a cell, even at the end of a line - a combining
+	// character is drawn with the rune before it
+	w := New("ab\u033e")
+	c1 := w.Render(gowid.RenderFixed{}, gowid.Focused, gwtest.D)
+	assert.Equal(t, 2, c1.BoxColumns())
+	assert.Equal(t, "ab\u033e", c1.String())
+	assert.Equal(t, []rune{'\u033e'}, c1.CellAt(1, 0).Combining())
+
+	w = New("a\x00b")
+	c1 = w.Render(gowid.RenderFlowWith{C: 4}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "ab  ", c1.String())
+}
+
+func TestGraphemes1(t *testing.T) {
+	// A flag, a family joined with zero-width joiners, and a heart with emoji presentation
+	// are each one cluster, two columns wide
+	str := "\U0001F1EF\U0001F1F5\U0001F468\u200d\U0001F469\u200d\U0001F467\u2764\ufe0fe\u0301"
+	w := New(str)
+	assert.Equal(t, 7, w.Content().Width())
+	c1 := w.Render(gowid.RenderFixed{}, gowid.Focused, gwtest.D)
+	assert.Equal(t, 7, c1.BoxColumns())
+	assert.Equal(t, str, c1.String())
+	assert.Equal(t, '\U0001F468', c1.CellAt(2, 0).Rune())
+	assert.Equal(t, 'e', c1.CellAt(6, 0).Rune())
+
+	// Clusters are never split across lines
+	c1 = w.Render(gowid.RenderFlowWith{C: 3}, gowid.Focused, gwtest.D)
+	assert.Equal(t, 3, c1.BoxRows())
+	assert.Equal(t, "\U0001F1EF\U0001F1F5 ", strings.Split(c1.String(), "\n")[0])
+
+	// Positions in the text map to the start of a cluster
+	layout := MakeTextLayout(w.Content(), 10, WrapAny, gowid.HAlignLeft{})
+	x, _ := GetCoordsFromCursorPos(7, 10, layout, w.Content())
+	assert.Equal(t, 4, x)
+	assert.Equal(t, 2, GetCursorPosFromCoords(2, 0, layout, w.Content()))
+	assert.Equal(t, 7, GetCursorPosFromCoords(3, 0, layout, w.Content()))
+	assert.Equal(t, 7, GetCursorPosFromCoords(4, 0, layout, w.Content()))
+	assert.Equal(t, 9, GetCursorPosFromCoords(6, 0, layout, w.Content()))
+}
+
+func TestBidi1(t *testing.T) {
+	// A paragraph starting with Hebrew runs right-to-left, with brackets mirrored
+	w := New("(\u05d0\u05d1\u05d2) abc")
+	c1 := w.Render(gowid.RenderFixed{}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "abc (\u05d2\u05d1\u05d0)", c1.String())
+
+	// Right-to-left widgets are aligned right by default
+	w = New("abc", Options{Direction: gowid.DirectionRTL})
+	assert.Equal(t, gowid.DirectionRTL, w.Direction())
+	c1 = w.Render(gowid.RenderFlowWith{C: 6}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "   abc", c1.String())
+
+	w = New("\u05d0\u05d1\u05d2 abc")
+	layout := MakeTextLayout(w.Content(), 10, WrapAny, gowid.HAlignRight{})
+	x, _ := GetCoordsFromCursorPos(0, 10, layout, w.Content())
+	assert.Equal(t, 9, x)
+	x, _ = GetCoordsFromCursorPos(4, 10, layout, w.Content())
+	assert.Equal(t, 3, x)
+	// At the end, the cursor is after the English word
+	x, _ = GetCoordsFromCursorPos(7, 10, layout, w.Content())
+	assert.Equal(t, 6, x)
+	assert.Equal(t, 1, GetCursorPosFromCoords(8, 0, layout, w.Content()))
+	assert.Equal(t, 5, GetCursorPosFromCoords(4, 0, layout, w.Content()))
+	assert.Equal(t, 7, GetCursorPosFromCoords(0, 0, layout, w.Content()))
+}
+
 //======================================================================
 // Local Variables:
 // mode: Go
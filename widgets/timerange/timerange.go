@@ -0,0 +1,308 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package timerange provides a widget to select a range of time, either relative to now using presets
+// like "last 1h", or with explicit start and end times, whose dates are chosen from a calendar.
+package timerange
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/button"
+	"github.com/gcla/gowid/widgets/calendar"
+	"github.com/gcla/gowid/widgets/columns"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/radio"
+	"github.com/gcla/gowid/widgets/text"
+)
+
+//======================================================================
+
+// Range is a span of time. If Preset is not nil, the range was chosen relative to the time it was
+// requested, and End is that time.
+type Range struct {
+	Start  time.Time
+	End    time.Time
+	Preset *Preset
+}
+
+// Duration returns the length of the range.
+func (r Range) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+func (r Range) String() string {
+	if r.Preset != nil {
+		return r.Preset.Label
+	}
+	return fmt.Sprintf("%v - %v", r.Start, r.End)
+}
+
+// Preset is a range of time ending now.
+type Preset struct {
+	Label    string
+	Duration time.Duration
+}
+
+// DefaultPresets are used if Options.Presets is nil.
+var DefaultPresets = []Preset{
+	{"Last 15m", 15 * time.Minute},
+	{"Last 1h", time.Hour},
+	{"Last 24h", 24 * time.Hour},
+	{"Last 7d", 7 * 24 * time.Hour},
+}
+
+// DefaultTimeLayout is used to parse and display the times of day of the custom start and end if
+// Options.TimeLayout is empty.
+const DefaultTimeLayout = "15:04"
+
+// RangeCB is the key for callbacks issued when the selected range changes.
+type RangeCB struct{}
+
+// Custom is the value returned by PresetIndex when an explicit start and end have been chosen.
+const Custom = -1
+
+type Options struct {
+	Presets    []Preset
+	DateFormat string         // How the custom start and end dates are shown; defaults to calendar.DefaultFormat
+	TimeLayout string         // Time of day layout for custom start and end, as used by time.Parse
+	Location   *time.Location // Custom times are interpreted in this location; defaults to time.Local
+	Initial    int            // Index of the preset selected initially
+}
+
+type InvalidRangeError struct {
+	Start time.Time
+	End   time.Time
+}
+
+var _ error = InvalidRangeError{}
+
+func (e InvalidRangeError) Error() string {
+	return fmt.Sprintf("Range start %v is not before end %v", e.Start, e.End)
+}
+
+// Widget lets the user choose one of a set of preset ranges relative to now, or a custom start and end.
+// The first line holds a radio button for each preset, followed by one for the custom range; the second
+// line holds the custom range's start and end - each a date, chosen from a calendar that pops up (see
+// calendar.Picker), and a time of day - and a button to apply them. Choosing a date applies the range
+// at once, if the other date has been chosen too.
+type Widget struct {
+	gowid.IWidget
+	opts      Options
+	group     []radio.IWidget
+	radios    []*radio.Widget // One per preset, then one for the custom range
+	startDate *calendar.Picker
+	startTime *edit.Widget
+	endDate   *calendar.Picker
+	endTime   *edit.Widget
+	errText   *text.Widget
+	custom    Range
+	*gowid.Callbacks
+}
+
+var _ gowid.IWidget = (*Widget)(nil)
+
+func New(opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Presets == nil {
+		opt.Presets = DefaultPresets
+	}
+	if opt.DateFormat == "" {
+		opt.DateFormat = calendar.DefaultFormat
+	}
+	if opt.TimeLayout == "" {
+		opt.TimeLayout = DefaultTimeLayout
+	}
+	if opt.Location == nil {
+		opt.Location = time.Local
+	}
+
+	midnight := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Format(opt.TimeLayout)
+	pickerOpts := calendar.PickerOptions{Format: opt.DateFormat, Placeholder: "date"}
+	res := &Widget{
+		opts:      opt,
+		group:     make([]radio.IWidget, 0),
+		startDate: calendar.NewPicker(pickerOpts),
+		startTime: edit.New(edit.Options{Text: midnight}),
+		endDate:   calendar.NewPicker(pickerOpts),
+		endTime:   edit.New(edit.Options{Text: midnight}),
+		errText:   text.New(""),
+		Callbacks: gowid.NewCallbacks(),
+	}
+	for _, p := range []*calendar.Picker{res.startDate, res.endDate} {
+		p.OnChange(gowid.WidgetCallback{"cb", func(app gowid.IApp, w gowid.IWidget) {
+			if _, ok := res.startDate.Date(); !ok {
+				return
+			}
+			if _, ok := res.endDate.Date(); ok {
+				res.applyCustom(app)
+			}
+		}})
+	}
+
+	presetCols := make([]interface{}, 0)
+	for i := 0; i <= len(opt.Presets); i++ {
+		label := "Custom"
+		if i < len(opt.Presets) {
+			label = opt.Presets[i].Label
+		}
+		isCustom := i == len(opt.Presets)
+		rb := radio.New(&res.group)
+		rb.OnClick(gowid.WidgetCallback{"cb", func(app gowid.IApp, w gowid.IWidget) {
+			switch {
+			case !w.(*radio.Widget).IsChecked():
+			case isCustom && res.custom.End.IsZero():
+				// Take the range from the edit fields; the callbacks run if it's valid
+				res.applyCustom(app)
+			default:
+				gowid.RunWidgetCallbacks(res.Callbacks, RangeCB{}, app, res)
+			}
+		}})
+		res.radios = append(res.radios, rb)
+		presetCols = append(presetCols, rb, text.New(" "+label+"  "))
+	}
+	if opt.Initial > 0 && opt.Initial < len(opt.Presets) {
+		res.radios[0].SetStateInternal(false)
+		res.radios[opt.Initial].SetStateInternal(true)
+	}
+
+	// Room for the date, then the picker's arrow
+	dateWidth := gowid.RenderWithUnits{U: len(opt.DateFormat) + 2}
+	timeWidth := gowid.RenderWithUnits{U: len(opt.TimeLayout) + 1}
+	apply := button.New(text.New("Apply"))
+	apply.OnClick(gowid.WidgetCallback{"cb", func(app gowid.IApp, w gowid.IWidget) {
+		res.applyCustom(app)
+	}})
+
+	res.IWidget = pile.NewFlow(
+		columns.NewFixed(presetCols...),
+		columns.New([]gowid.IContainerWidget{
+			&gowid.ContainerWidget{text.New("From "), gowid.RenderFixed{}},
+			&gowid.ContainerWidget{res.startDate, dateWidth},
+			&gowid.ContainerWidget{text.New(" "), gowid.RenderFixed{}},
+			&gowid.ContainerWidget{res.startTime, timeWidth},
+			&gowid.ContainerWidget{text.New(" To "), gowid.RenderFixed{}},
+			&gowid.ContainerWidget{res.endDate, dateWidth},
+			&gowid.ContainerWidget{text.New(" "), gowid.RenderFixed{}},
+			&gowid.ContainerWidget{res.endTime, timeWidth},
+			&gowid.ContainerWidget{apply, gowid.RenderFixed{}},
+		}),
+		res.errText,
+	)
+
+	return res
+}
+
+func (w *Widget) String() string {
+	return "timerange"
+}
+
+// PresetIndex returns the index of the selected preset, or Custom.
+func (w *Widget) PresetIndex() int {
+	for i, rb := range w.radios {
+		if rb.IsChecked() && i < len(w.opts.Presets) {
+			return i
+		}
+	}
+	return Custom
+}
+
+// Range returns the selected range. A preset range ends at the current time according to the app's clock.
+// If the custom range is selected but no valid start and end have been applied, the range is zero.
+func (w *Widget) Range(app gowid.IApp) Range {
+	i := w.PresetIndex()
+	if i == Custom {
+		return w.custom
+	}
+	p := w.opts.Presets[i]
+	now := gowid.ClockFor(app).Now()
+	return Range{
+		Start:  now.Add(-p.Duration),
+		End:    now,
+		Preset: &p,
+	}
+}
+
+// SetPreset selects the preset with index i.
+func (w *Widget) SetPreset(i int, app gowid.IApp) {
+	if i >= 0 && i < len(w.opts.Presets) {
+		w.radios[i].Select(app)
+	}
+}
+
+// SetCustom selects the range from start to end, and displays their dates and times. An
+// InvalidRangeError is returned if start is not before end.
+func (w *Widget) SetCustom(start, end time.Time, app gowid.IApp) error {
+	start, end = start.In(w.opts.Location), end.In(w.opts.Location)
+	w.startDate.SetDate(start, app)
+	w.startTime.SetText(start.Format(w.opts.TimeLayout), app)
+	w.endDate.SetDate(end, app)
+	w.endTime.SetText(end.Format(w.opts.TimeLayout), app)
+	return w.setCustom(start, end, app)
+}
+
+func (w *Widget) setCustom(start, end time.Time, app gowid.IApp) error {
+	if !start.Before(end) {
+		err := InvalidRangeError{Start: start, End: end}
+		w.errText.SetText(err.Error(), app)
+		return err
+	}
+	w.errText.SetText("", app)
+	w.custom = Range{Start: start, End: end}
+	custom := w.radios[len(w.radios)-1]
+	if custom.IsChecked() {
+		gowid.RunWidgetCallbacks(w.Callbacks, RangeCB{}, app, w)
+	} else {
+		custom.Select(app)
+	}
+	return nil
+}
+
+func (w *Widget) applyCustom(app gowid.IApp) {
+	start, err := w.parse(w.startDate, w.startTime)
+	if err != nil {
+		w.errText.SetText(fmt.Sprintf("Invalid start: %v", err), app)
+		return
+	}
+	end, err := w.parse(w.endDate, w.endTime)
+	if err != nil {
+		w.errText.SetText(fmt.Sprintf("Invalid end: %v", err), app)
+		return
+	}
+	w.setCustom(start, end, app)
+}
+
+// parse returns the time on the date chosen in picker at the time of day in field.
+func (w *Widget) parse(picker *calendar.Picker, field *edit.Widget) (time.Time, error) {
+	date, ok := picker.Date()
+	if !ok {
+		return time.Time{}, fmt.Errorf("no date chosen")
+	}
+	t, err := time.Parse(w.opts.TimeLayout, field.Text())
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), t.Second(), 0, w.opts.Location), nil
+}
+
+// OnRangeChanged registers a callback issued when the user chooses a preset or applies a custom range.
+func (w *Widget) OnRangeChanged(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w.Callbacks, RangeCB{}, f)
+}
+
+func (w *Widget) RemoveOnRangeChanged(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w.Callbacks, RangeCB{}, f)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
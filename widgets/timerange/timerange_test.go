@@ -0,0 +1,105 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package timerange
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeRange1(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	app := gwtest.NewTestAppWithClock(gowid.NewFakeClock(now))
+
+	w := New(Options{Location: time.UTC, Initial: 1})
+	assert.Equal(t, 1, w.PresetIndex())
+	r := w.Range(app)
+	assert.Equal(t, now.Add(-time.Hour), r.Start)
+	assert.Equal(t, now, r.End)
+	assert.Equal(t, "Last 1h", r.String())
+
+	c := w.Render(gowid.RenderFlowWith{C: 70}, gowid.Focused, app)
+	lines := strings.Split(c.String(), "\n")
+	assert.Equal(t, "( ) Last 15m  (X) Last 1h  ( ) Last 24h  ( ) Last 7d  ( ) Custom  ", lines[0][0:66])
+	assert.Equal(t, "From ", lines[1][0:5])
+
+	var changes []Range
+	w.OnRangeChanged(gowid.WidgetCallback{"cb", func(app gowid.IApp, w2 gowid.IWidget) {
+		changes = append(changes, w2.(*Widget).Range(app))
+	}})
+
+	w.SetPreset(2, app)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, 24*time.Hour, changes[0].Duration())
+
+	err := w.SetCustom(now, now.Add(-time.Minute), app)
+	assert.Equal(t, InvalidRangeError{Start: now, End: now.Add(-time.Minute)}, err)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, 2, w.PresetIndex())
+
+	start := time.Date(2020, 5, 1, 9, 30, 0, 0, time.UTC)
+	assert.NoError(t, w.SetCustom(start, start.Add(2*time.Hour), app))
+	assert.Equal(t, Custom, w.PresetIndex())
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, Range{Start: start, End: start.Add(2 * time.Hour)}, changes[1])
+	assert.Equal(t, "2020-05-01", w.startDate.Value())
+	assert.Equal(t, "09:30", w.startTime.Text())
+
+	w.endTime.SetText("soon", app)
+	w.applyCustom(app)
+	assert.Equal(t, 2, len(changes))
+	assert.Contains(t, w.errText.Content().String(), "Invalid end")
+
+	w.endDate.SetDate(time.Date(2020, 5, 2, 0, 0, 0, 0, time.UTC), app)
+	w.endTime.SetText("00:00", app)
+	w.applyCustom(app)
+	assert.Equal(t, 3, len(changes))
+	assert.Equal(t, "", w.errText.Content().String())
+	assert.Equal(t, time.Date(2020, 5, 2, 0, 0, 0, 0, time.UTC), w.Range(app).End)
+}
+
+func TestPickDates1(t *testing.T) {
+	now := time.Date(2020, 6, 10, 12, 0, 0, 0, time.UTC)
+	w := New(Options{Location: time.UTC})
+	var changes []Range
+	w.OnRangeChanged(gowid.WidgetCallback{"cb", func(app gowid.IApp, w2 gowid.IWidget) {
+		changes = append(changes, w2.(*Widget).Range(app))
+	}})
+	root := pile.New([]gowid.IContainerWidget{
+		&gowid.ContainerWidget{IWidget: w, D: gowid.RenderFlow{}},
+		&gowid.ContainerWidget{IWidget: text.New(""), D: gowid.RenderWithWeight{W: 1}},
+	})
+	sim := gwtest.NewSimT(t, root, gwtest.SimOptions{Cols: 70, Rows: 14, Clock: gowid.NewFakeClock(now)})
+	defer sim.Close()
+	assert.Equal(t, "From date ▾       00:00  To date ▾       00:00 <Apply>", strings.TrimRight(sim.Line(1), " "))
+
+	// Choosing the start date alone doesn't apply the range
+	sim.Click(5, 1, tcell.Button1)
+	sim.Key(tcell.KeyLeft)
+	sim.Key(tcell.KeyEnter)
+	assert.Equal(t, 0, len(changes))
+
+	// Choosing the end date too does
+	sim.Click(28, 1, tcell.Button1)
+	sim.Key(tcell.KeyDown)
+	sim.Key(tcell.KeyEnter)
+	assert.Equal(t, "From 2020-06-09 ▾ 00:00  To 2020-06-17 ▾ 00:00 <Apply>", strings.TrimRight(sim.Line(1), " "))
+	assert.Equal(t, []Range{{Start: time.Date(2020, 6, 9, 0, 0, 0, 0, time.UTC), End: time.Date(2020, 6, 17, 0, 0, 0, 0, time.UTC)}}, changes)
+	assert.Equal(t, Custom, w.PresetIndex())
+
+	// Then changing either date applies it again
+	sim.Click(5, 1, tcell.Button1)
+	sim.Key(tcell.KeyRight)
+	sim.Key(tcell.KeyEnter)
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, time.Date(2020, 6, 10, 0, 0, 0, 0, time.UTC), w.Range(sim).Start)
+}
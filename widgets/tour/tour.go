@@ -0,0 +1,452 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package tour provides a guided tour of an application's widgets, for a first-run experience. Each step
+// highlights a widget, dimming everything else, and explains it in a bubble beside it.
+package tour
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/framed"
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+)
+
+//======================================================================
+
+// DefaultWidth is the widest a bubble is, including its frame, unless the options say otherwise.
+var DefaultWidth = 44
+
+// Step is a stop on the tour.
+type Step struct {
+	ID     string        // The widget to highlight, by the ID set with gowid.Stylesheet.SetID - or
+	Target gowid.IWidget // the widget itself. If neither is displayed, the bubble is centered.
+	Title  string
+	Text   string // Paragraphs are separated by newlines, and wrapped to fit the bubble
+}
+
+// Options is used to configure the widget.
+type Options struct {
+	Width  int               // The widest a bubble is - DefaultWidth if 0
+	Dim    gowid.ICellStyler // Applied outside the highlighted widget - gowid.StyleDim if nil
+	Bubble gowid.ICellStyler // The bubble's colors - white on blue if nil
+	Frame  framed.FrameRunes // The bubble's frame - framed.UnicodeFrame if zero
+}
+
+// For callback registration
+type StepCB struct{}
+type DoneCB struct{}
+
+// control is a clickable label in the bubble.
+type control struct {
+	label string
+	rect  gowid.Rect
+	act   func(w *Widget, app gowid.IApp)
+}
+
+// Widget shows its child, and while a tour is running, the tour on top of it. The bubble has controls to
+// move through the steps, which can also be driven with the keyboard: Enter or Right for the next step,
+// Left for the previous one, and Esc to skip the rest of the tour. Input doesn't reach the child until
+// the tour is over.
+type Widget struct {
+	gowid.IWidget
+	steps     []Step
+	opts      Options
+	step      int // -1 when no tour is running
+	completed bool
+	controls  []control // Where the bubble's controls were last drawn, for the mouse
+	*gowid.Callbacks
+	gowid.SubWidgetCallbacks
+}
+
+var _ gowid.ICompositeWidget = (*Widget)(nil)
+
+// New returns a widget that shows inner, and a tour of steps once Start is called.
+func New(inner gowid.IWidget, steps []Step, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Width <= 0 {
+		opt.Width = DefaultWidth
+	}
+	if opt.Dim == nil {
+		opt.Dim = gowid.MakeStyledAs(gowid.StyleDim)
+	}
+	if opt.Bubble == nil {
+		opt.Bubble = gowid.MakePaletteEntry(gowid.ColorWhite, gowid.ColorBlue)
+	}
+	if opt.Frame == (framed.FrameRunes{}) {
+		opt.Frame = framed.UnicodeFrame
+	}
+	res := &Widget{
+		IWidget:   inner,
+		steps:     steps,
+		opts:      opt,
+		step:      -1,
+		Callbacks: gowid.NewCallbacks(),
+	}
+	res.SubWidgetCallbacks = gowid.SubWidgetCallbacks{CB: &res.Callbacks}
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("tour[%v]", w.SubWidget())
+}
+
+func (w *Widget) SubWidget() gowid.IWidget {
+	return w.IWidget
+}
+
+func (w *Widget) SetSubWidget(wi gowid.IWidget, app gowid.IApp) {
+	w.IWidget = wi
+	gowid.RunWidgetCallbacks(w, gowid.SubWidgetCB{}, app, w)
+}
+
+func (w *Widget) SubWidgetSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderSize {
+	return size
+}
+
+func (w *Widget) Steps() []Step {
+	return w.steps
+}
+
+// SetSteps replaces the tour's steps, ending any tour running.
+func (w *Widget) SetSteps(steps []Step, app gowid.IApp) {
+	if w.Running() {
+		w.finish(false, app)
+	}
+	w.steps = steps
+}
+
+// Running returns true between Start and the end of the tour.
+func (w *Widget) Running() bool {
+	return w.step >= 0
+}
+
+// Step returns the index of the step shown, or -1 if no tour is running.
+func (w *Widget) Step() int {
+	return w.step
+}
+
+// Completed returns true if the last tour went through every step, rather than being skipped.
+func (w *Widget) Completed() bool {
+	return w.completed
+}
+
+// OnStep registers a callback run when the tour moves to a step, including the first.
+func (w *Widget) OnStep(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w, StepCB{}, f)
+}
+
+func (w *Widget) RemoveOnStep(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w, StepCB{}, f)
+}
+
+// OnDone registers a callback run when the tour ends, whether completed or skipped - see Completed.
+func (w *Widget) OnDone(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w, DoneCB{}, f)
+}
+
+func (w *Widget) RemoveOnDone(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w, DoneCB{}, f)
+}
+
+// Start starts the tour at its first step, or from the beginning again if it is running.
+func (w *Widget) Start(app gowid.IApp) {
+	if len(w.steps) == 0 {
+		return
+	}
+	w.completed = false
+	w.setStep(0, app)
+}
+
+// Next moves to the next step, completing the tour after the last.
+func (w *Widget) Next(app gowid.IApp) {
+	if !w.Running() {
+		return
+	}
+	if w.step+1 < len(w.steps) {
+		w.setStep(w.step+1, app)
+	} else {
+		w.finish(true, app)
+	}
+}
+
+// Back moves to the previous step, if there is one.
+func (w *Widget) Back(app gowid.IApp) {
+	if w.step > 0 {
+		w.setStep(w.step-1, app)
+	}
+}
+
+// Skip ends the tour without completing it.
+func (w *Widget) Skip(app gowid.IApp) {
+	if w.Running() {
+		w.finish(false, app)
+	}
+}
+
+func (w *Widget) setStep(step int, app gowid.IApp) {
+	w.step = step
+	gowid.RunWidgetCallbacks(w, StepCB{}, app, w)
+}
+
+func (w *Widget) finish(completed bool, app gowid.IApp) {
+	w.step = -1
+	w.completed = completed
+	w.controls = nil
+	gowid.RunWidgetCallbacks(w, DoneCB{}, app, w)
+}
+
+func (w *Widget) Selectable() bool {
+	return w.Running() || w.IWidget.Selectable()
+}
+
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	if !w.Running() {
+		return gowid.UserInputIfSelectable(w.IWidget, ev, size, focus, app)
+	}
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		switch ev.Key() {
+		case tcell.KeyEnter, tcell.KeyRight:
+			w.Next(app)
+		case tcell.KeyLeft:
+			w.Back(app)
+		case tcell.KeyEscape:
+			w.Skip(app)
+		default:
+			// Not for the widgets beneath, but the app may want it - e.g. to quit
+			return false
+		}
+	case *tcell.EventMouse:
+		if ev.Buttons() == tcell.Button1 {
+			mx, my := ev.Position()
+			for _, c := range w.controls {
+				if c.rect.Contains(mx, my) {
+					c.act(w, app)
+					break
+				}
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	return gowid.RenderSize(w.IWidget, size, focus, app)
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	res := gowid.Render(w.IWidget, size, focus, app)
+	w.controls = nil
+	if !w.Running() {
+		return res
+	}
+	step := w.steps[w.step]
+	target, found := gowid.LocateWidget(w.IWidget, size, focus, app, func(wi gowid.IWidget) bool {
+		return matches(step, wi, app)
+	})
+
+	dim := styleCell(w.opts.Dim, app)
+	for y := 0; y < res.BoxRows(); y++ {
+		for x := 0; x < res.BoxColumns(); x++ {
+			if !found || !target.Contains(x, y) {
+				res.SetCellAt(x, y, res.CellAt(x, y).MergeDisplayAttrsUnder(dim))
+			}
+		}
+	}
+
+	cols, rows := res.BoxColumns(), res.BoxRows()
+	bubble, controls := w.bubble(step, gwutil.Min(w.opts.Width, cols), app)
+	bx, by := Place(target, found, bubble.BoxColumns(), bubble.BoxRows(), cols, rows)
+	res.MergeUnder(bubble, bx, by, false)
+	for i := range controls {
+		controls[i].rect.X += bx
+		controls[i].rect.Y += by
+	}
+	w.controls = controls
+	return res
+}
+
+// Place returns where a bubble of bcols by brows is drawn on a canvas of cols by rows to explain the
+// target rectangle: below it if there is room, otherwise above it, to its right or to its left - or, if
+// it won't fit beside the target, or there is no target, centered.
+func Place(target gowid.Rect, found bool, bcols, brows, cols, rows int) (int, int) {
+	clamp := func(v, size, space int) int {
+		return gwutil.Max(0, gwutil.Min(v, space-size))
+	}
+	switch {
+	case !found:
+	case target.Y+target.Rows+brows <= rows:
+		return clamp(target.X, bcols, cols), target.Y + target.Rows
+	case target.Y-brows >= 0:
+		return clamp(target.X, bcols, cols), target.Y - brows
+	case target.X+target.Cols+bcols <= cols:
+		return target.X + target.Cols, clamp(target.Y, brows, rows)
+	case target.X-bcols >= 0:
+		return target.X - bcols, clamp(target.Y, brows, rows)
+	}
+	return gwutil.Max(0, (cols-bcols)/2), gwutil.Max(0, (rows-brows)/2)
+}
+
+// bubble returns the canvas of the bubble explaining step, no wider than width, and its controls, placed
+// relative to the bubble.
+func (w *Widget) bubble(step Step, width int, app gowid.IApp) (gowid.ICanvas, []control) {
+	controls := []control{{label: "Skip", act: (*Widget).Skip}}
+	if w.step > 0 {
+		controls = append(controls, control{label: gowid.ASCIIString(app, "◂ Back", "< Back"), act: (*Widget).Back})
+	}
+	if w.step+1 < len(w.steps) {
+		controls = append(controls, control{label: gowid.ASCIIString(app, "Next ▸", "Next >"), act: (*Widget).Next})
+	} else {
+		controls = append(controls, control{label: "Done", act: (*Widget).Next})
+	}
+	progress := fmt.Sprintf("%d/%d", w.step+1, len(w.steps))
+	controlsWidth := runewidth.StringWidth(progress)
+	for _, c := range controls {
+		controlsWidth += 2 + runewidth.StringWidth(c.label)
+	}
+
+	// A column of frame and a column of padding on either side
+	inner := gwutil.Max(1, width-4)
+	lines := wrap(step.Text, inner)
+	natural := gwutil.Max(controlsWidth, runewidth.StringWidth(step.Title))
+	for _, line := range lines {
+		natural = gwutil.Max(natural, runewidth.StringWidth(line))
+	}
+	inner = gwutil.Min(inner, natural)
+	title := runewidth.Truncate(step.Title, inner, gowid.ASCIIString(app, "…", "~"))
+
+	rows := len(lines) + 3 // The frame, and the controls
+	if title != "" {
+		rows += 2 // And a blank line beneath
+	}
+	cols := inner + 4
+	blank := styleCell(w.opts.Bubble, app).WithRune(' ')
+	res := gowid.NewCanvasOfSizeExt(cols, rows, blank)
+
+	f := w.opts.Frame
+	res.SetCellAt(0, 0, blank.WithRune(f.Tl))
+	res.SetCellAt(cols-1, 0, blank.WithRune(f.Tr))
+	res.SetCellAt(0, rows-1, blank.WithRune(f.Bl))
+	res.SetCellAt(cols-1, rows-1, blank.WithRune(f.Br))
+	for x := 1; x < cols-1; x++ {
+		res.SetCellAt(x, 0, blank.WithRune(f.T))
+		res.SetCellAt(x, rows-1, blank.WithRune(f.B))
+	}
+	for y := 1; y < rows-1; y++ {
+		res.SetCellAt(0, y, blank.WithRune(f.L))
+		res.SetCellAt(cols-1, y, blank.WithRune(f.R))
+	}
+
+	y := 1
+	if title != "" {
+		drawString(res, 2, y, title, blank.WithStyle(gowid.StyleBold))
+		y += 2
+	}
+	for _, line := range lines {
+		drawString(res, 2, y, line, blank)
+		y++
+	}
+	drawString(res, 2, y, progress, blank)
+	x := cols - 2 - controlsWidth + runewidth.StringWidth(progress)
+	for i := range controls {
+		x += 2
+		cell := blank
+		if i == len(controls)-1 {
+			// The default, chosen with Enter
+			cell = blank.WithStyle(gowid.StyleReverse)
+		}
+		n := drawString(res, x, y, controls[i].label, cell)
+		controls[i].rect = gowid.Rect{X: x, Y: y, Cols: n, Rows: 1}
+		x += n
+	}
+	return res, controls
+}
+
+// drawString draws s on canvas from x, y in the style of cell and returns the columns it took.
+func drawString(canvas gowid.ICanvas, x, y int, s string, cell gowid.Cell) int {
+	start := x
+	for _, r := range s {
+		if x >= canvas.BoxColumns() {
+			break
+		}
+		canvas.SetCellAt(x, y, cell.WithRune(r))
+		x += gwutil.Max(1, runewidth.RuneWidth(r))
+	}
+	return x - start
+}
+
+// wrap breaks text into lines no wider than width, between words where it can.
+func wrap(text string, width int) []string {
+	res := make([]string, 0)
+	if text == "" {
+		return res
+	}
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			for runewidth.StringWidth(word) > width {
+				if line != "" {
+					res = append(res, line)
+					line = ""
+				}
+				head := runewidth.Truncate(word, width, "")
+				res = append(res, head)
+				word = word[len(head):]
+			}
+			switch {
+			case line == "":
+				line = word
+			case runewidth.StringWidth(line)+1+runewidth.StringWidth(word) <= width:
+				line += " " + word
+			default:
+				res = append(res, line)
+				line = word
+			}
+		}
+		res = append(res, line)
+	}
+	return res
+}
+
+// matches returns true if wi is the widget step highlights.
+func matches(step Step, wi gowid.IWidget, app gowid.IApp) bool {
+	if step.Target != nil && comparable(wi) && comparable(step.Target) && wi == step.Target {
+		return true
+	}
+	if step.ID != "" {
+		if s := gowid.StylesheetFor(app); s != nil && s.ID(wi) == step.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// comparable returns true if w can be compared with ==, which panics for e.g. a widget that is a slice.
+func comparable(w gowid.IWidget) bool {
+	return reflect.TypeOf(w).Comparable()
+}
+
+func styleCell(styler gowid.ICellStyler, app gowid.IApp) gowid.Cell {
+	fgCol, bgCol, style := styler.GetStyle(app)
+	mode := app.GetColorMode()
+	return gowid.MakeCell(0, gowid.IColorToTCell(fgCol, gowid.ColorNone, mode),
+		gowid.IColorToTCell(bgCol, gowid.ColorNone, mode), style)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
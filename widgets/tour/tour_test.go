@@ -0,0 +1,134 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package tour
+
+import (
+	"testing"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/widgets/framed"
+	"github.com/gcla/gowid/widgets/pile"
+	"github.com/gcla/gowid/widgets/text"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func dimmed(sim *gwtest.Sim, x, y int) bool {
+	_, st := sim.Cell(x, y)
+	_, _, attrs := st.Decompose()
+	return attrs&tcell.AttrDim != 0
+}
+
+func TestTour1(t *testing.T) {
+	menu := text.New("File Edit View")
+	body := text.New("Hello")
+	status := text.New("Ready")
+	view := pile.NewFlow(menu, body, status)
+
+	w := New(view, []Step{
+		{Target: menu, Title: "Menu", Text: "Everything starts here."},
+		{ID: "status", Text: "Progress is shown here."},
+		{ID: "missing", Text: "Not on the screen."},
+	}, Options{Width: 30, Frame: framed.UnicodeAlt2Frame})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 40, Rows: 12})
+	defer sim.Close()
+
+	styles := gowid.NewStylesheet()
+	styles.SetID(status, "status")
+	sim.SetStylesheet(styles)
+
+	var done []bool
+	w.OnDone(gowid.WidgetCallback{Name: "test", WidgetChangedFunction: func(app gowid.IApp, wi gowid.IWidget) {
+		done = append(done, w.Completed())
+	}})
+
+	w.Start(sim)
+	sim.Frame()
+	assert.Equal(t, 0, w.Step())
+
+	// The menu is highlighted, and the bubble sits just below it, over the rest of the view
+	assert.False(t, dimmed(sim, 0, 0))
+	assert.False(t, dimmed(sim, 13, 0))
+	assert.True(t, dimmed(sim, 35, 1))
+	assert.True(t, dimmed(sim, 35, 5))
+	sim.AssertLine(t, 1, "╔═════════════════════════╗             ")
+	sim.AssertLine(t, 2, "║ Menu                    ║             ")
+	sim.AssertLine(t, 4, "║ Everything starts here. ║             ")
+	sim.AssertLine(t, 5, "║ 1/3        Skip  Next ▸ ║             ")
+
+	// Keys other than the tour's don't reach the view
+	sim.Rune('x')
+	assert.Equal(t, 0, w.Step())
+
+	sim.Key(tcell.KeyEnter)
+	assert.Equal(t, 1, w.Step())
+	assert.True(t, dimmed(sim, 0, 0))
+	assert.False(t, dimmed(sim, 0, 2))
+	// The bubble is drawn below the status line, inside its frame
+	_, y, ok := sim.Find("Progress is shown here.")
+	assert.True(t, ok)
+	assert.Equal(t, 4, y)
+	sim.AssertContains(t, "2/3")
+
+	// Back with a click
+	x, y, ok := sim.Find("◂ Back")
+	assert.True(t, ok)
+	sim.Click(x+1, y, tcell.Button1)
+	assert.Equal(t, 0, w.Step())
+
+	// A target not on the screen has the bubble centered
+	sim.Key(tcell.KeyRight)
+	sim.Key(tcell.KeyRight)
+	assert.Equal(t, 2, w.Step())
+	x, y, ok = sim.Find("Not on the screen.")
+	assert.True(t, ok)
+	assert.Equal(t, 5, y)
+	assert.True(t, dimmed(sim, 0, 0))
+	sim.AssertContains(t, "Done")
+
+	sim.Key(tcell.KeyEnter)
+	assert.False(t, w.Running())
+	assert.Equal(t, []bool{true}, done)
+	assert.False(t, dimmed(sim, 0, 0))
+	sim.AssertLine(t, 1, "Hello                                   ")
+
+	w.Start(sim)
+	sim.Key(tcell.KeyEscape)
+	assert.False(t, w.Running())
+	assert.Equal(t, []bool{true, false}, done)
+}
+
+func TestPlace1(t *testing.T) {
+	target := gowid.Rect{X: 30, Y: 2, Cols: 5, Rows: 1}
+	x, y := Place(target, true, 20, 4, 40, 10)
+	assert.Equal(t, 20, x)
+	assert.Equal(t, 3, y)
+
+	target.Y = 8
+	_, y = Place(target, true, 20, 4, 40, 10)
+	assert.Equal(t, 4, y)
+
+	// No room above or below - to the left
+	x, y = Place(gowid.Rect{X: 25, Y: 0, Cols: 5, Rows: 10}, true, 20, 4, 40, 10)
+	assert.Equal(t, 5, x)
+	assert.Equal(t, 0, y)
+
+	x, y = Place(gowid.Rect{}, false, 20, 4, 40, 10)
+	assert.Equal(t, 10, x)
+	assert.Equal(t, 3, y)
+}
+
+func TestWrap1(t *testing.T) {
+	assert.Equal(t, []string{"the quick", "brown fox", "", "jumps"}, wrap("the quick brown fox\n\njumps", 10))
+	assert.Equal(t, []string{"abcd", "efgh", "ij"}, wrap("abcdefghij", 4))
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,211 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package unitedit provides an edit widget for a number with a unit, like "1.5GiB", "250ms" or "75%".
+// The text is parsed as the user types, and the widget exposes the value in canonical form - bytes,
+// nanoseconds or a fraction.
+package unitedit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gcla/gowid/widgets/edit"
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// ValueCB is the key for callbacks issued when the widget's value changes.
+type ValueCB struct{}
+
+type Options struct {
+	Unit    IUnit // Defaults to Bytes
+	Value   float64
+	Min     gwutil.Float64Option
+	Max     gwutil.Float64Option
+	Caption string
+}
+
+// OutOfRangeError is returned if a value is below the widget's minimum or above its maximum.
+type OutOfRangeError struct {
+	Value float64
+	Min   gwutil.Float64Option
+	Max   gwutil.Float64Option
+	Unit  IUnit
+}
+
+var _ error = OutOfRangeError{}
+
+func (e OutOfRangeError) Error() string {
+	if !e.Min.IsNone() && e.Value < e.Min.Val() {
+		return fmt.Sprintf("%s is less than the minimum, %s", e.Unit.Format(e.Value), e.Unit.Format(e.Min.Val()))
+	}
+	return fmt.Sprintf("%s is greater than the maximum, %s", e.Unit.Format(e.Value), e.Unit.Format(e.Max.Val()))
+}
+
+// Widget is an edit widget that holds a number with a unit. The up and down keys change the value by a
+// step appropriate to the unit and the value's magnitude - e.g. by 1GiB if the value is displayed in GiB;
+// page up and page down change it by ten steps. If the text is not a valid value in range, Value()
+// returns the last value that was, and Err() returns the reason.
+type Widget struct {
+	*edit.Widget
+	opts   Options
+	value  float64
+	err    error
+	update bool // Set while the widget updates its own text
+}
+
+var _ gowid.IWidget = (*Widget)(nil)
+
+func New(opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Unit == nil {
+		opt.Unit = Bytes
+	}
+	res := &Widget{
+		Widget: edit.New(edit.Options{
+			Caption: opt.Caption,
+			Text:    opt.Unit.Format(opt.Value),
+		}),
+		opts:  opt,
+		value: opt.Value,
+	}
+	res.err = res.check(opt.Value)
+	res.Widget.OnTextSet(gowid.WidgetCallback{"cb", func(app gowid.IApp, w gowid.IWidget) {
+		if !res.update {
+			res.parse(app)
+		}
+	}})
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("unitedit[%s]", w.Text())
+}
+
+// Value returns the canonical value, e.g. a number of bytes. If the text isn't valid, it's the last
+// valid value.
+func (w *Widget) Value() float64 {
+	return w.value
+}
+
+// Err returns nil if the text is a valid value within range; otherwise it is a ParseError or an
+// OutOfRangeError.
+func (w *Widget) Err() error {
+	return w.err
+}
+
+// Unit returns the unit used to parse and format the value.
+func (w *Widget) Unit() IUnit {
+	return w.opts.Unit
+}
+
+// SetValue sets the value, and displays it in the unit's format. An OutOfRangeError is returned, and the
+// widget is unchanged, if v is out of range.
+func (w *Widget) SetValue(v float64, app gowid.IApp) error {
+	if err := w.check(v); err != nil {
+		return err
+	}
+	w.update = true
+	w.SetText(w.opts.Unit.Format(v), app)
+	w.SetCursorPos(len([]rune(w.Text())), app)
+	w.update = false
+	w.setValue(v, nil, app)
+	return nil
+}
+
+// OnValueChanged registers a callback issued when the value changes, either because the user typed a
+// new valid value, or stepped it with the arrow keys, or because SetValue was called.
+func (w *Widget) OnValueChanged(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w.Callbacks, ValueCB{}, f)
+}
+
+func (w *Widget) RemoveOnValueChanged(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w.Callbacks, ValueCB{}, f)
+}
+
+func (w *Widget) check(v float64) error {
+	if (!w.opts.Min.IsNone() && v < w.opts.Min.Val()) || (!w.opts.Max.IsNone() && v > w.opts.Max.Val()) {
+		return OutOfRangeError{Value: v, Min: w.opts.Min, Max: w.opts.Max, Unit: w.opts.Unit}
+	}
+	return nil
+}
+
+func (w *Widget) clamp(v float64) float64 {
+	if !w.opts.Min.IsNone() && v < w.opts.Min.Val() {
+		v = w.opts.Min.Val()
+	}
+	if !w.opts.Max.IsNone() && v > w.opts.Max.Val() {
+		v = w.opts.Max.Val()
+	}
+	return v
+}
+
+func (w *Widget) setValue(v float64, err error, app gowid.IApp) {
+	w.err = err
+	if err == nil && v != w.value {
+		w.value = v
+		gowid.RunWidgetCallbacks(w.Callbacks, ValueCB{}, app, w)
+	}
+}
+
+func (w *Widget) parse(app gowid.IApp) {
+	v, err := w.opts.Unit.Parse(w.Text())
+	if err == nil {
+		err = w.check(v)
+	}
+	w.setValue(v, err, app)
+}
+
+// Step changes the value by n steps - up if n is positive - limited to the widget's range.
+func (w *Widget) Step(n int, app gowid.IApp) {
+	v := w.value
+	for i := 0; i < n; i++ {
+		v += w.opts.Unit.Step(v, true)
+	}
+	for i := 0; i > n; i-- {
+		v -= w.opts.Unit.Step(v, false)
+	}
+	w.SetValue(w.clamp(v), app)
+}
+
+func (w *Widget) UserInput(ev interface{}, size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) bool {
+	switch ev := ev.(type) {
+	case *tcell.EventKey:
+		switch ev.Key() {
+		case tcell.KeyUp:
+			w.Step(1, app)
+			return true
+		case tcell.KeyDown:
+			w.Step(-1, app)
+			return true
+		case tcell.KeyPgUp:
+			w.Step(10, app)
+			return true
+		case tcell.KeyPgDn:
+			w.Step(-10, app)
+			return true
+		case tcell.KeyEnter:
+			// The value is a single line; let an enclosing widget use Enter, e.g. to submit a form
+			return false
+		}
+	case *gowid.PasteEvent:
+		ev2 := *ev
+		ev2.Text = strings.Join(strings.Fields(ev.Text), "")
+		return w.Widget.UserInput(&ev2, size, focus, app)
+	}
+	return w.Widget.UserInput(ev, size, focus, app)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,130 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package unitedit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/gcla/gowid/gwutil"
+	"github.com/gdamore/tcell"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnits1(t *testing.T) {
+	for _, c := range []struct {
+		unit IUnit
+		in   string
+		val  float64
+		out  string
+	}{
+		{Bytes, "1.5GiB", 1.5 * (1 << 30), "1.5GiB"},
+		{Bytes, "2 kb", 2000, "1.95KiB"},
+		{Bytes, "512", 512, "512B"},
+		{Bytes, "1m", 1000000, "976.56KiB"},
+		{Bytes, "1MiB", 1 << 20, "1MiB"},
+		{Duration, "250ms", float64(250 * time.Millisecond), "250ms"},
+		{Duration, "1h30m", float64(90 * time.Minute), "1h30m0s"},
+		{Percent, "75%", 0.75, "75%"},
+		{Percent, "12.5", 0.125, "12.5%"},
+	} {
+		v, err := c.unit.Parse(c.in)
+		assert.NoError(t, err, c.in)
+		assert.InDelta(t, c.val, v, 1e-9, c.in)
+		assert.Equal(t, c.out, c.unit.Format(v), c.in)
+	}
+
+	for _, c := range []struct {
+		unit IUnit
+		in   string
+	}{
+		{Bytes, "1.5 parsecs"},
+		{Bytes, "GiB"},
+		{Duration, "250"},
+		{Percent, "75%%"},
+	} {
+		_, err := c.unit.Parse(c.in)
+		assert.IsType(t, ParseError{}, err, c.in)
+	}
+
+	assert.Equal(t, float64(1<<30), Bytes.Step(1.5*(1<<30), true))
+	assert.Equal(t, float64(1<<30), Bytes.Step(1<<30, true))
+	assert.Equal(t, float64(1<<20), Bytes.Step(1<<30, false))
+	assert.Equal(t, float64(1), Bytes.Step(0, false))
+	assert.Equal(t, float64(time.Millisecond), Duration.Step(float64(250*time.Millisecond), true))
+}
+
+func TestUnitEdit1(t *testing.T) {
+	w := New(Options{
+		Unit:  Bytes,
+		Value: 1 << 30,
+		Min:   gwutil.SomeFloat64(0),
+		Max:   gwutil.SomeFloat64(2.5 * (1 << 30)),
+	})
+	assert.Equal(t, "1GiB", w.Text())
+	assert.NoError(t, w.Err())
+
+	changes := 0
+	w.OnValueChanged(gowid.WidgetCallback{"cb", func(app gowid.IApp, w gowid.IWidget) {
+		changes++
+	}})
+
+	sz := gowid.RenderFlowWith{C: 10}
+	w.UserInput(gwtest.CursorUp(), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "2GiB", w.Text())
+	assert.Equal(t, float64(2<<30), w.Value())
+	assert.Equal(t, 1, changes)
+
+	// Stepping is limited to the range
+	w.UserInput(gwtest.CursorUp(), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "2.5GiB", w.Text())
+	assert.Equal(t, 2, changes)
+	w.UserInput(gwtest.CursorDown(), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "1.5GiB", w.Text())
+	assert.Equal(t, 3, changes)
+
+	// Stepping down from a whole unit moves to the smaller unit
+	w.Step(-2, gwtest.D)
+	assert.Equal(t, "511MiB", w.Text())
+	w.UserInput(tcell.NewEventKey(tcell.KeyPgDn, ' ', tcell.ModNone), sz, gowid.Focused, gwtest.D)
+	assert.Equal(t, "501MiB", w.Text())
+
+	// Typed text is parsed as it changes
+	w.SetText("300MB", gwtest.D)
+	assert.NoError(t, w.Err())
+	assert.Equal(t, float64(300000000), w.Value())
+	w.SetText("300MX", gwtest.D)
+	assert.IsType(t, ParseError{}, w.Err())
+	assert.Equal(t, float64(300000000), w.Value())
+	w.SetText("3GiB", gwtest.D)
+	assert.Equal(t, "3GiB is greater than the maximum, 2.5GiB", w.Err().Error())
+	assert.Equal(t, float64(300000000), w.Value())
+
+	assert.IsType(t, OutOfRangeError{}, w.SetValue(-1, gwtest.D))
+	assert.NoError(t, w.SetValue(1<<20, gwtest.D))
+	assert.Equal(t, "1MiB", w.Text())
+	assert.NoError(t, w.Err())
+
+	// Enter is left to the enclosing widget
+	assert.False(t, w.UserInput(tcell.NewEventKey(tcell.KeyEnter, ' ', tcell.ModNone), sz, gowid.Focused, gwtest.D))
+	assert.Equal(t, "1MiB", w.Text())
+}
+
+func TestUnitEdit2(t *testing.T) {
+	w := New(Options{Unit: Percent, Value: 0.5, Caption: "Load: "})
+	c := w.Render(gowid.RenderFlowWith{C: 10}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "Load: 50% ", c.String())
+
+	w.UserInput(gwtest.CursorDown(), gowid.RenderFlowWith{C: 10}, gowid.Focused, gwtest.D)
+	assert.Equal(t, "49%", w.Text())
+	assert.InDelta(t, 0.49, w.Value(), 1e-9)
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,201 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package unitedit
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gcla/gowid/gwutil"
+)
+
+//======================================================================
+
+// IUnit converts between the text the user types and a canonical numeric value.
+type IUnit interface {
+	// Parse returns the canonical value of s, e.g. 1610612736 for "1.5GiB".
+	Parse(s string) (float64, error)
+	// Format returns the text to display for v.
+	Format(v float64) string
+	// Step returns the amount by which v is changed when the user presses up (if up is true)
+	// or down.
+	Step(v float64, up bool) float64
+}
+
+// ParseError is returned if the text cannot be parsed as a value of the widget's unit.
+type ParseError struct {
+	Input string
+	Unit  string
+	Err   error // The underlying error, if any
+}
+
+var _ error = ParseError{}
+
+func (e ParseError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("Could not parse %q as %s: %v", e.Input, e.Unit, e.Err)
+	}
+	return fmt.Sprintf("Could not parse %q as %s", e.Input, e.Unit)
+}
+
+func (e ParseError) Cause() error {
+	return e.Err
+}
+
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
+
+var numberRE = regexp.MustCompile(`^\s*([-+]?(?:[0-9]+\.?[0-9]*|\.[0-9]+))\s*([^\s0-9]*)\s*$`)
+
+// splitNumber separates s into a number and a unit suffix.
+func splitNumber(s string) (float64, string, bool) {
+	m := numberRE.FindStringSubmatch(s)
+	if m == nil {
+		return 0, "", false
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return v, m[2], true
+}
+
+// formatNumber formats v with at most two decimal places, and no trailing zeros.
+func formatNumber(v float64) string {
+	res := strconv.FormatFloat(v, 'f', 2, 64)
+	res = strings.TrimRight(res, "0")
+	res = strings.TrimSuffix(res, ".")
+	if res == "-0" {
+		res = "0"
+	}
+	return res
+}
+
+// stepFor returns the largest of units that is no more than v when stepping up, or less than v when
+// stepping down, so that the step is one of the unit in which v is displayed, and stepping down from a
+// whole unit moves into the smaller unit. units must be in increasing order.
+func stepFor(units []float64, v float64, up bool) float64 {
+	if v < 0 {
+		return stepFor(units, -v, !up)
+	}
+	res := units[0]
+	for _, u := range units {
+		if u < v || (up && u == v) {
+			res = u
+		}
+	}
+	return res
+}
+
+//======================================================================
+
+type bytesUnit struct{}
+
+// Bytes is a quantity of data. The canonical value is a number of bytes. The user can type an IEC
+// suffix like "KiB" or "GiB", or an SI suffix like "KB" or "GB"; suffixes are not case-sensitive, and
+// "B" is optional. Values are displayed with IEC suffixes, e.g. "1.5GiB".
+var Bytes IUnit = bytesUnit{}
+
+var iecPrefixes = []string{"", "Ki", "Mi", "Gi", "Ti", "Pi", "Ei"}
+var iecScales = []float64{1, 1 << 10, 1 << 20, 1 << 30, 1 << 40, 1 << 50, 1 << 60}
+
+func (u bytesUnit) Parse(s string) (float64, error) {
+	v, suffix, ok := splitNumber(s)
+	if !ok {
+		return 0, ParseError{Input: s, Unit: "bytes"}
+	}
+	suffix = strings.TrimSuffix(strings.ToLower(suffix), "b")
+	if suffix == "" {
+		return v, nil
+	}
+	for i, p := range iecPrefixes[1:] {
+		switch suffix {
+		case strings.ToLower(p):
+			return v * iecScales[i+1], nil
+		case strings.ToLower(p[0:1]):
+			return v * float64(gwutil.IPow(1000, i+1)), nil
+		}
+	}
+	return 0, ParseError{Input: s, Unit: "bytes"}
+}
+
+func (u bytesUnit) Format(v float64) string {
+	i := 0
+	for i+1 < len(iecScales) && (v >= iecScales[i+1] || -v >= iecScales[i+1]) {
+		i++
+	}
+	return formatNumber(v/iecScales[i]) + iecPrefixes[i] + "B"
+}
+
+func (u bytesUnit) Step(v float64, up bool) float64 {
+	return stepFor(iecScales, v, up)
+}
+
+//======================================================================
+
+type durationUnit struct{}
+
+// Duration is a span of time. The canonical value is a number of nanoseconds, so it can be converted
+// to a time.Duration. Text is parsed with time.ParseDuration, e.g. "250ms" or "1h30m".
+var Duration IUnit = durationUnit{}
+
+var durationScales = []float64{
+	float64(time.Nanosecond),
+	float64(time.Microsecond),
+	float64(time.Millisecond),
+	float64(time.Second),
+	float64(time.Minute),
+	float64(time.Hour),
+}
+
+func (u durationUnit) Parse(s string) (float64, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, ParseError{Input: s, Unit: "duration", Err: err}
+	}
+	return float64(d), nil
+}
+
+func (u durationUnit) Format(v float64) string {
+	return time.Duration(v).String()
+}
+
+func (u durationUnit) Step(v float64, up bool) float64 {
+	return stepFor(durationScales, v, up)
+}
+
+//======================================================================
+
+type percentUnit struct{}
+
+// Percent is a proportion. The canonical value is a fraction, so "75%" is 0.75. The "%" is optional when
+// typing. The value steps by 1%.
+var Percent IUnit = percentUnit{}
+
+func (u percentUnit) Parse(s string) (float64, error) {
+	v, suffix, ok := splitNumber(s)
+	if !ok || (suffix != "" && suffix != "%") {
+		return 0, ParseError{Input: s, Unit: "percentage"}
+	}
+	return v / 100, nil
+}
+
+func (u percentUnit) Format(v float64) string {
+	return formatNumber(v*100) + "%"
+}
+
+func (u percentUnit) Step(v float64, up bool) float64 {
+	return 0.01
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,292 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package video provides a widget that plays a stream of frames - e.g. from a webcam or a screen capture -
+// at a capped frame rate, drawn like the image widget's pictures.
+package video
+
+import (
+	"fmt"
+	stdimage "image"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/widgets/image"
+)
+
+//======================================================================
+
+// DefaultFPS is the most frames per second a widget shows unless told otherwise. Terminals redraw
+// slowly, and more frames than this mostly cost CPU.
+var DefaultFPS = 10
+
+// IFrameSource is a stream of frames.
+type IFrameSource interface {
+	// NextFrame blocks until the next frame is ready and returns it, or returns io.EOF when the stream
+	// has ended. Any other error also ends the stream. If the source is also an io.Closer, Close is called
+	// when the widget is stopped, and should make a blocked NextFrame return.
+	NextFrame() (stdimage.Image, error)
+}
+
+// ChannelSource is a source of the frames sent on a channel. The stream ends when the channel is closed.
+type ChannelSource <-chan stdimage.Image
+
+var _ IFrameSource = ChannelSource(nil)
+
+func (c ChannelSource) NextFrame() (stdimage.Image, error) {
+	img, ok := <-c
+	if !ok {
+		return nil, io.EOF
+	}
+	return img, nil
+}
+
+// Options is used to configure the widget.
+type Options struct {
+	FPS      int                    // The most frames shown per second - DefaultFPS if 0
+	Protocol gowid.GraphicsProtocol // Defaults to the app's - see gowid.GraphicsProtocolFor
+}
+
+// For callback registration
+type FrameCB struct{}
+type EndCB struct{}
+
+// Widget plays the frames of a source. Frames are read on a goroutine of their own, as fast as the
+// source provides them, and shown on the app goroutine no more often than the frame rate allows. A frame
+// that arrives before the last one has been shown replaces it, so if the source or the app falls behind,
+// frames are dropped and the widget stays current rather than lagging further behind.
+type Widget struct {
+	source    IFrameSource
+	protocol  gowid.GraphicsProtocol
+	regions   image.RegionCache
+	current   stdimage.Image
+	shown     int
+	err       error
+	stop      chan struct{} // Closed to stop the stream; nil when stopped
+	mu        sync.Mutex    // Guards the fields below, shared with the reading goroutine
+	fps       int
+	latest    stdimage.Image
+	scheduled bool
+	timer     *gowid.Timer // Shows the latest frame when its time comes
+	last      time.Time
+	dropped   int
+	Callbacks *gowid.Callbacks
+	gowid.RejectUserInput
+	gowid.NotSelectable
+}
+
+var _ image.IWidget = (*Widget)(nil)
+
+// New returns a widget that plays the frames of source once Start is called.
+func New(source IFrameSource, opts ...Options) *Widget {
+	var opt Options
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.FPS <= 0 {
+		opt.FPS = DefaultFPS
+	}
+	return &Widget{
+		source:    source,
+		fps:       opt.FPS,
+		protocol:  opt.Protocol,
+		current:   stdimage.NewRGBA(stdimage.Rect(0, 0, 0, 0)),
+		Callbacks: gowid.NewCallbacks(),
+	}
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("video[%d fps]", w.FPS())
+}
+
+// Image returns the frame being shown - an empty picture until the first arrives.
+func (w *Widget) Image() stdimage.Image {
+	return w.current
+}
+
+func (w *Widget) FPS() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.fps
+}
+
+func (w *Widget) SetFPS(fps int, app gowid.IApp) {
+	if fps <= 0 {
+		fps = DefaultFPS
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.fps = fps
+}
+
+// Shown returns the number of frames shown.
+func (w *Widget) Shown() int {
+	return w.shown
+}
+
+// Dropped returns the number of frames read from the source and replaced by a later one before they
+// could be shown.
+func (w *Widget) Dropped() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dropped
+}
+
+// Running returns true between Start and the stream's end, or Stop.
+func (w *Widget) Running() bool {
+	return w.stop != nil
+}
+
+// Err returns the error that ended the stream, or nil if it ended with io.EOF or is still running.
+func (w *Widget) Err() error {
+	return w.err
+}
+
+func (w *Widget) OnFrame(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w.Callbacks, FrameCB{}, f)
+}
+
+func (w *Widget) RemoveOnFrame(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w.Callbacks, FrameCB{}, f)
+}
+
+// OnEnd registers a callback run on the app goroutine when the stream ends - see Err.
+func (w *Widget) OnEnd(f gowid.IWidgetChangedCallback) {
+	gowid.AddWidgetCallback(w.Callbacks, EndCB{}, f)
+}
+
+func (w *Widget) RemoveOnEnd(f gowid.IIdentity) {
+	gowid.RemoveWidgetCallback(w.Callbacks, EndCB{}, f)
+}
+
+// Start starts reading and showing frames. It must be called on the app goroutine.
+func (w *Widget) Start(app gowid.IApp) {
+	if w.stop != nil {
+		return
+	}
+	w.err = nil
+	w.stop = make(chan struct{})
+	w.mu.Lock()
+	w.latest = nil
+	w.scheduled = false
+	w.mu.Unlock()
+	go w.read(w.stop, app)
+}
+
+// Stop stops showing frames, leaving the last one displayed, and closes the source if it is an io.Closer.
+// It must be called on the app goroutine.
+func (w *Widget) Stop(app gowid.IApp) {
+	if w.stop == nil {
+		return
+	}
+	close(w.stop)
+	w.stop = nil
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.mu.Unlock()
+	if c, ok := w.source.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// read reads frames until the stream ends or stop is closed.
+func (w *Widget) read(stop chan struct{}, app gowid.IApp) {
+	for {
+		img, err := w.source.NextFrame()
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		if err != nil {
+			app.Run(gowid.RunFunction(func(app gowid.IApp) {
+				w.end(stop, err, app)
+			}))
+			return
+		}
+		w.offer(img, stop, app)
+	}
+}
+
+// offer makes img the next frame to show, replacing any not shown yet, and arranges for it to be shown
+// once the last frame has been up for long enough.
+func (w *Widget) offer(img stdimage.Image, stop chan struct{}, app gowid.IApp) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	select {
+	case <-stop:
+		return
+	default:
+	}
+	if w.latest != nil {
+		w.dropped++
+	}
+	w.latest = img
+	if w.scheduled {
+		return
+	}
+	w.scheduled = true
+	clock := gowid.ClockFor(app)
+	wait := time.Second/time.Duration(w.fps) - clock.Since(w.last)
+	if wait < 0 {
+		wait = 0
+	}
+	w.timer = gowid.After(app, wait, func(app gowid.IApp) {
+		w.show(app)
+	})
+}
+
+func (w *Widget) show(app gowid.IApp) {
+	w.mu.Lock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	img := w.latest
+	w.latest = nil
+	w.scheduled = false
+	w.last = gowid.ClockFor(app).Now()
+	w.mu.Unlock()
+
+	if img == nil {
+		return
+	}
+	w.current = img
+	w.shown++
+	gowid.RunWidgetCallbacks(w.Callbacks, FrameCB{}, app, w)
+}
+
+func (w *Widget) end(stop chan struct{}, err error, app gowid.IApp) {
+	if stop != w.stop {
+		return
+	}
+	// Show the last frame, whether or not its time has come
+	w.show(app)
+	w.stop = nil
+	if err != io.EOF {
+		w.err = err
+	}
+	gowid.RunWidgetCallbacks(w.Callbacks, EndCB{}, app, w)
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	return gowid.CalculateRenderSizeFallback(w, size, focus, app)
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	res := image.Render(w, size, focus, app)
+	w.regions.Mark(res, w.current, w.shown, w.protocol, app)
+	return res
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
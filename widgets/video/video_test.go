@@ -0,0 +1,100 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package video
+
+import (
+	stdimage "image"
+	"image/color"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+var (
+	red  = color.RGBA{0xff, 0, 0, 0xff}
+	blue = color.RGBA{0, 0, 0xff, 0xff}
+)
+
+func solid(c color.Color) *stdimage.RGBA {
+	res := stdimage.NewRGBA(stdimage.Rect(0, 0, 2, 2))
+	for i := 0; i < 4; i++ {
+		res.Set(i%2, i/2, c)
+	}
+	return res
+}
+
+func TestFrameRate1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	w := New(ChannelSource(nil), Options{FPS: 10})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 2, Rows: 1, Clock: clock})
+	defer sim.Close()
+
+	// Play the part of the reading goroutine
+	w.stop = make(chan struct{})
+	w.offer(solid(red), w.stop, sim)
+	clock.Advance(0)
+	sim.Frame()
+	assert.Equal(t, 1, w.Shown())
+	assert.Equal(t, solid(red), w.Image())
+
+	// Frames arriving faster than the frame rate replace each other
+	w.offer(solid(blue), w.stop, sim)
+	w.offer(solid(red), w.stop, sim)
+	assert.Equal(t, 1, w.Dropped())
+	clock.Advance(99 * time.Millisecond)
+	sim.Frame()
+	assert.Equal(t, 1, w.Shown())
+	clock.Advance(time.Millisecond)
+	sim.Frame()
+	assert.Equal(t, 2, w.Shown())
+	sim.AssertLine(t, 0, "▀▀")
+
+	// The last frame is shown when the stream ends
+	w.offer(solid(blue), w.stop, sim)
+	w.end(w.stop, io.EOF, sim)
+	assert.Equal(t, 3, w.Shown())
+	assert.Equal(t, solid(blue), w.Image())
+	assert.False(t, w.Running())
+	assert.NoError(t, w.Err())
+
+	// A frame scheduled before the stream ended is ignored
+	clock.Advance(time.Second)
+	sim.Frame()
+	assert.Equal(t, 3, w.Shown())
+}
+
+func TestChannelSource1(t *testing.T) {
+	ch := make(chan stdimage.Image)
+	w := New(ChannelSource(ch))
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 2, Rows: 1})
+	defer sim.Close()
+	ended := false
+	w.OnEnd(gowid.WidgetCallback{Name: "end", WidgetChangedFunction: func(app gowid.IApp, w gowid.IWidget) {
+		ended = true
+	}})
+
+	w.Start(sim)
+	assert.True(t, w.Running())
+	ch <- solid(red)
+	close(ch)
+	for deadline := time.Now().Add(5 * time.Second); !ended && time.Now().Before(deadline); {
+		sim.Frame()
+		time.Sleep(time.Millisecond)
+	}
+	assert.True(t, ended)
+	assert.Equal(t, 1, w.Shown())
+	assert.Equal(t, solid(red), w.Image())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -155,7 +155,7 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	var rowsToUseInResult int
 
 	subSize := w.SubWidgetSize(size, focus, app)
-	subWidgetCanvas = w.SubWidget().Render(subSize, focus, app)
+	subWidgetCanvas = gowid.Render(w.SubWidget(), subSize, focus, app)
 	subWidgetRows := subWidgetCanvas.BoxRows()
 
 	// Compute number of rows to use in final canvas
@@ -20,6 +20,17 @@ type VerticalScrollbarRunes struct {
 	Up, Down, Space, Handle rune
 }
 
+// ASCII returns the runes, unless widgets should draw with ASCII only - see App.SetASCIIOnly - in which
+// case each rune that isn't ASCII is replaced with its counterpart in VerticalScrollbarAsciiRunes.
+func (r VerticalScrollbarRunes) ASCII(app gowid.IApp) VerticalScrollbarRunes {
+	return VerticalScrollbarRunes{
+		Up:     gowid.ASCIIRune(app, r.Up, VerticalScrollbarAsciiRunes.Up),
+		Down:   gowid.ASCIIRune(app, r.Down, VerticalScrollbarAsciiRunes.Down),
+		Space:  gowid.ASCIIRune(app, r.Space, VerticalScrollbarAsciiRunes.Space),
+		Handle: gowid.ASCIIRune(app, r.Handle, VerticalScrollbarAsciiRunes.Handle),
+	}
+}
+
 var (
 	VerticalScrollbarAsciiRunes   = VerticalScrollbarRunes{'^', 'v', ' ', '#'}
 	VerticalScrollbarUnicodeRunes = VerticalScrollbarRunes{'▲', '▼', ' ', '█'}
@@ -305,9 +316,10 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 		fixSplit(2, 0, 1, &splits)
 	}
 
-	fill := gowid.CellFromRune(w.GetRunes().Handle)
+	runes := w.GetRunes().ASCII(app)
+	fill := gowid.CellFromRune(runes.Handle)
 	fillArr := make([]gowid.Cell, 0)
-	blank := gowid.CellFromRune(w.GetRunes().Space)
+	blank := gowid.CellFromRune(runes.Space)
 	blankArr := make([]gowid.Cell, 0)
 	for i := 0; i < cols; i++ {
 		fillArr = append(fillArr, fill)
@@ -360,10 +372,10 @@ func Render(w IWidget, size gowid.IRenderSize, focus gowid.Selector, app gowid.I
 	l := len(res.Lines)
 	if l > 0 {
 		for i := 0; i < len(res.Lines[0]); i++ {
-			res.Lines[0][i] = res.Lines[0][i].WithRune(w.GetRunes().Up)
+			res.Lines[0][i] = res.Lines[0][i].WithRune(runes.Up)
 		}
 		for i := 0; i < len(res.Lines[0]); i++ {
-			res.Lines[l-1][i] = res.Lines[l-1][i].WithRune(w.GetRunes().Down)
+			res.Lines[l-1][i] = res.Lines[l-1][i].WithRune(runes.Down)
 		}
 	}
 
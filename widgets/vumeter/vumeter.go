@@ -0,0 +1,384 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+// Package vumeter provides an audio level meter - a bar for each channel, on a decibel scale, that falls
+// back smoothly after each sound and marks recent peaks.
+package vumeter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwutil"
+)
+
+//======================================================================
+
+// LevelFunc returns the current level of each channel, in dBFS - 0 for full scale, and negative for
+// quieter sounds. Return one level for a mono signal, two for stereo. It is called on the app goroutine
+// with each tick of the meter, so should return quickly, e.g. with levels measured by an audio goroutine.
+type LevelFunc func(app gowid.IApp) []float64
+
+var (
+	// DefaultInterval is the time between readings of the level - about 30 a second.
+	DefaultInterval = time.Second / 30
+	// DefaultPeakHold is how long the peak marker stays put before following the level down.
+	DefaultPeakHold = 1500 * time.Millisecond
+	// DefaultFalloff is how fast a bar falls after a sound, in dB per second.
+	DefaultFalloff = 24.0
+	// DefaultMinDB is the level at the bottom of the scale.
+	DefaultMinDB = -60.0
+	// DefaultHeight is the height of a vertical meter given a flow size.
+	DefaultHeight = 10
+)
+
+// DefaultGradient colors a bar green for the lower half of the scale, then through yellow to red at full
+// scale.
+var DefaultGradient = gowid.MakeGradient(gowid.RGBSpace,
+	gowid.MakeRGBColor("#00c000"),
+	gowid.MakeRGBColor("#00c000"),
+	gowid.MakeRGBColor("#00c000"),
+	gowid.MakeRGBColor("#e0e000"),
+	gowid.MakeRGBColor("#ff0000"),
+)
+
+// Options is used to configure the meter.
+type Options struct {
+	Level    LevelFunc       // Polled with each tick once the meter is started; if nil, call Update instead
+	Channels int             // Defaults to 1 - mono; 2 is stereo
+	Labels   []string        // If not nil, a label for each channel, e.g. "L" and "R"
+	MinDB    float64         // The bottom of the scale, below 0 - DefaultMinDB if 0
+	Interval time.Duration   // Defaults to DefaultInterval
+	PeakHold time.Duration   // Defaults to DefaultPeakHold; if negative, peaks aren't marked
+	Falloff  float64         // In dB per second - defaults to DefaultFalloff; if negative, bars drop at once
+	Vertical bool            // If true, bars rise from the bottom, side by side
+	Scale    bool            // If true, the dB scale is drawn beside the bars
+	Gradient *gowid.Gradient // Colors a bar along its length; defaults to DefaultGradient
+}
+
+// Widget is a level meter. Each reading of the level is smoothed as a real meter's needle is: a bar
+// rises at once to a louder level, but falls back at the falloff rate, and a peak marker holds the
+// loudest recent level for a while. Ticks are timed with the app's clock (see gowid.ClockFor) and run on
+// the app goroutine, so the app is redrawn after each.
+type Widget struct {
+	opts     Options
+	levels   []float64 // Displayed, in dB
+	peaks    []float64
+	peakAt   []time.Time
+	last     time.Time // When the last reading was made
+	timer    *gowid.Timer
+	gradient gowid.Gradient
+	gowid.RejectUserInput
+	gowid.NotSelectable
+}
+
+func New(opts Options) *Widget {
+	if opts.Channels <= 0 {
+		opts.Channels = 1
+	}
+	if opts.MinDB >= 0 {
+		opts.MinDB = DefaultMinDB
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.PeakHold == 0 {
+		opts.PeakHold = DefaultPeakHold
+	}
+	if opts.Falloff == 0 {
+		opts.Falloff = DefaultFalloff
+	}
+	res := &Widget{
+		opts:     opts,
+		levels:   make([]float64, opts.Channels),
+		peaks:    make([]float64, opts.Channels),
+		peakAt:   make([]time.Time, opts.Channels),
+		gradient: DefaultGradient,
+	}
+	if opts.Gradient != nil {
+		res.gradient = *opts.Gradient
+	}
+	for i := range res.levels {
+		res.levels[i] = opts.MinDB
+		res.peaks[i] = opts.MinDB
+	}
+	return res
+}
+
+func (w *Widget) String() string {
+	return fmt.Sprintf("vumeter[%d]", w.opts.Channels)
+}
+
+// Levels returns the level displayed for each channel, in dB.
+func (w *Widget) Levels() []float64 {
+	return w.levels
+}
+
+// Peaks returns the level at which each channel's peak marker is displayed, in dB.
+func (w *Widget) Peaks() []float64 {
+	return w.peaks
+}
+
+// Start polls the Level function with each tick until Stop is called. It must be called on the app
+// goroutine.
+func (w *Widget) Start(app gowid.IApp) {
+	w.Stop()
+	w.timer = gowid.Every(app, w.opts.Interval, func(app gowid.IApp) {
+		if w.opts.Level != nil {
+			w.Update(w.opts.Level(app), app)
+		}
+	})
+}
+
+// Stop stops polling, leaving the meter as it is.
+func (w *Widget) Stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+}
+
+// Running returns true between Start and Stop.
+func (w *Widget) Running() bool {
+	return w.timer != nil
+}
+
+// Update applies a reading of the level of each channel, in dBFS. Channels without a reading, and
+// readings of -Inf or NaN, count as silence. It must be called on the app goroutine - from another, use
+// app.Run.
+func (w *Widget) Update(levels []float64, app gowid.IApp) {
+	now := gowid.ClockFor(app).Now()
+	elapsed := 0.0
+	if !w.last.IsZero() {
+		elapsed = now.Sub(w.last).Seconds()
+	}
+	w.last = now
+
+	for i := range w.levels {
+		level := w.opts.MinDB
+		if i < len(levels) && !math.IsNaN(levels[i]) {
+			level = math.Max(levels[i], w.opts.MinDB)
+		}
+		if w.opts.Falloff > 0 {
+			level = math.Max(level, w.levels[i]-w.opts.Falloff*elapsed)
+		}
+		w.levels[i] = level
+		if level >= w.peaks[i] {
+			w.peaks[i], w.peakAt[i] = level, now
+		} else if now.Sub(w.peakAt[i]) >= w.opts.PeakHold {
+			w.peaks[i] = level
+		}
+	}
+}
+
+// position returns how far up the scale db is, from 0 to 1.
+func (w *Widget) position(db float64) float64 {
+	return math.Max(0, math.Min(1, (db-w.opts.MinDB)/-w.opts.MinDB))
+}
+
+// ticks returns the levels marked on the scale.
+func (w *Widget) ticks() []float64 {
+	res := make([]float64, 0)
+	for _, t := range []float64{-60, -50, -40, -30, -20, -10, -6, -3, 0} {
+		if t > w.opts.MinDB {
+			res = append(res, t)
+		}
+	}
+	return append([]float64{w.opts.MinDB}, res...)
+}
+
+func (w *Widget) labelWidth() int {
+	res := 0
+	for _, l := range w.opts.Labels {
+		res = gwutil.Max(res, gowid.StringWidth(l))
+	}
+	return res
+}
+
+// scaleWidth returns the width of the scale of a vertical meter.
+func (w *Widget) scaleWidth() int {
+	if !w.opts.Scale {
+		return 0
+	}
+	return len(strconv.Itoa(int(w.opts.MinDB))) + 1
+}
+
+func (w *Widget) RenderSize(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.IRenderBox {
+	cols, rows := w.size(size)
+	return gowid.RenderBox{C: cols, R: rows}
+}
+
+func (w *Widget) size(size gowid.IRenderSize) (int, int) {
+	n := w.opts.Channels
+	extra := 0
+	if w.opts.Vertical {
+		if w.opts.Labels != nil {
+			extra = 1
+		}
+	} else if w.opts.Scale {
+		extra = 1
+	}
+	switch sz := size.(type) {
+	case gowid.IRenderBox:
+		return sz.BoxColumns(), sz.BoxRows()
+	case gowid.IRenderFlowWith:
+		if w.opts.Vertical {
+			return sz.FlowColumns(), DefaultHeight + extra
+		}
+		return sz.FlowColumns(), n + extra
+	default:
+		if w.opts.Vertical {
+			return w.scaleWidth() + gwutil.Max(2, w.labelWidth())*n + n - 1, DefaultHeight + extra
+		}
+		return 40, n + extra
+	}
+}
+
+func (w *Widget) Render(size gowid.IRenderSize, focus gowid.Selector, app gowid.IApp) gowid.ICanvas {
+	cols, rows := w.size(size)
+	res := gowid.NewCanvasOfSize(cols, rows)
+	if w.opts.Vertical {
+		w.renderVertical(res, cols, rows, app)
+	} else {
+		w.renderHorizontal(res, cols, rows, app)
+	}
+	return res
+}
+
+//''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''''
+
+// Partial blocks, from an eighth of a cell to a full one
+var (
+	horizontalBlocks = []rune(" ▏▎▍▌▋▊▉█")
+	verticalBlocks   = []rune(" ▁▂▃▄▅▆▇█")
+	// Drawn instead if widgets should draw with ASCII only - see App.SetASCIIOnly
+	asciiBlocks = []rune(" ...::::#")
+)
+
+// blocks returns the partial blocks to draw with, unless widgets should draw with ASCII only.
+func blocks(unicode []rune, app gowid.IApp) []rune {
+	if gowid.ASCIIOnly(app) {
+		return asciiBlocks
+	}
+	return unicode
+}
+
+// bar draws a bar of length cells filled to pos, from 0 to 1, with its peak marker at peak. set is called
+// with each cell to draw and its index along the bar.
+func (w *Widget) bar(length int, pos, peak float64, blocks []rune, marker rune, app gowid.IApp, set func(i int, c gowid.Cell)) {
+	if length <= 0 {
+		return
+	}
+	mode := app.GetColorMode()
+	eighths := int(pos*float64(length*8) + 0.5)
+	peakCell := -1
+	if w.opts.PeakHold > 0 && peak > 0 {
+		peakCell = gwutil.Min(length-1, int(peak*float64(length)))
+	}
+	for i := 0; i < length; i++ {
+		col := gowid.IColorToTCell(w.gradient.At((float64(i)+0.5)/float64(length)), gowid.ColorNone, mode)
+		e := gwutil.Min(8, gwutil.Max(0, eighths-i*8))
+		switch {
+		case e == 0 && i == peakCell:
+			set(i, gowid.CellFromRune(marker).WithForegroundColor(col))
+		case e > 0:
+			set(i, gowid.CellFromRune(blocks[e]).WithForegroundColor(col))
+		}
+	}
+}
+
+func (w *Widget) renderHorizontal(c *gowid.Canvas, cols, rows int, app gowid.IApp) {
+	lw := w.labelWidth()
+	if lw > 0 {
+		lw++
+	}
+	length := cols - lw
+	for ch := 0; ch < w.opts.Channels && ch < rows; ch++ {
+		if ch < len(w.opts.Labels) {
+			drawString(c, 0, ch, w.opts.Labels[ch], lw)
+		}
+		w.bar(length, w.position(w.levels[ch]), w.position(w.peaks[ch]), blocks(horizontalBlocks, app), gowid.ASCIIRune(app, '▕', '|'), app, func(i int, cell gowid.Cell) {
+			c.SetCellAt(lw+i, ch, cell)
+		})
+	}
+	if !w.opts.Scale || w.opts.Channels >= rows || length <= 0 {
+		return
+	}
+	// Labels under their levels, leaving out those that would overlap
+	next := 0
+	for _, t := range w.ticks() {
+		s := strconv.Itoa(int(t))
+		x := int(w.position(t) * float64(length-1))
+		x = gwutil.Min(gwutil.Max(x-len(s)/2, 0), length-len(s))
+		if x < next {
+			continue
+		}
+		drawString(c, lw+x, w.opts.Channels, s, len(s))
+		next = x + len(s) + 1
+	}
+}
+
+func (w *Widget) renderVertical(c *gowid.Canvas, cols, rows int, app gowid.IApp) {
+	height := rows
+	if w.opts.Labels != nil {
+		height--
+	}
+	if height <= 0 {
+		return
+	}
+	sw := w.scaleWidth()
+	n := w.opts.Channels
+	// Share the columns between the channels, with a gap between each
+	width := gwutil.Max(1, (cols-sw-(n-1))/n)
+	for ch := 0; ch < n; ch++ {
+		x0 := sw + ch*(width+1)
+		if x0 >= cols {
+			break
+		}
+		if ch < len(w.opts.Labels) {
+			drawString(c, x0, height, w.opts.Labels[ch], width)
+		}
+		w.bar(height, w.position(w.levels[ch]), w.position(w.peaks[ch]), blocks(verticalBlocks, app), gowid.ASCIIRune(app, '▁', '_'), app, func(i int, cell gowid.Cell) {
+			for x := x0; x < x0+width && x < cols; x++ {
+				c.SetCellAt(x, height-1-i, cell)
+			}
+		})
+	}
+	if sw == 0 {
+		return
+	}
+	// Labels beside their levels, leaving out those that would overlap
+	next := height
+	for _, t := range w.ticks() {
+		y := height - 1 - int(w.position(t)*float64(height-1))
+		if y >= next {
+			continue
+		}
+		s := strconv.Itoa(int(t))
+		drawString(c, sw-1-len(s), y, s, len(s))
+		next = y
+	}
+}
+
+// drawString draws s at column x and row y of c, in at most width columns.
+func drawString(c *gowid.Canvas, x, y int, s string, width int) {
+	for _, r := range s {
+		rw := gowid.StringWidth(string(r))
+		if width < rw || x >= c.BoxColumns() {
+			return
+		}
+		c.SetCellAt(x, y, gowid.CellFromRune(r))
+		x += rw
+		width -= rw
+	}
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
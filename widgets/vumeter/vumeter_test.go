@@ -0,0 +1,76 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package vumeter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gcla/gowid"
+	"github.com/gcla/gowid/gwtest"
+	"github.com/stretchr/testify/assert"
+)
+
+//======================================================================
+
+func TestBallistics1(t *testing.T) {
+	clock := gowid.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	level := []float64{-6, math.Inf(-1)}
+	w := New(Options{
+		Channels: 2,
+		Interval: 100 * time.Millisecond,
+		Falloff:  10,
+		PeakHold: time.Second,
+		Level: func(app gowid.IApp) []float64 {
+			return level
+		},
+	})
+	sim := gwtest.NewSimT(t, w, gwtest.SimOptions{Cols: 10, Rows: 2, Clock: clock})
+	defer sim.Close()
+
+	w.Start(sim)
+	clock.Advance(100 * time.Millisecond)
+	sim.Frame()
+	assert.Equal(t, []float64{-6, -60}, w.Levels())
+
+	// The bar falls back at the falloff rate, and the peak holds
+	level = []float64{-40, -20}
+	clock.Advance(100 * time.Millisecond)
+	sim.Frame()
+	assert.InDelta(t, -7, w.Levels()[0], 1e-9)
+	assert.Equal(t, -20.0, w.Levels()[1])
+	assert.Equal(t, []float64{-6, -20}, w.Peaks())
+	for i := 0; i < 9; i++ {
+		clock.Advance(100 * time.Millisecond)
+		sim.Frame()
+	}
+	assert.InDelta(t, -16, w.Levels()[0], 1e-9)
+	assert.InDelta(t, -16, w.Peaks()[0], 1e-9)
+
+	w.Stop()
+	clock.Advance(time.Second)
+	sim.Frame()
+	assert.InDelta(t, -16, w.Levels()[0], 1e-9)
+	assert.Equal(t, 0, clock.Pending())
+}
+
+func TestRender1(t *testing.T) {
+	w := New(Options{Channels: 2, Labels: []string{"L", "R"}, Scale: true, MinDB: -40, Falloff: -1})
+	w.Update([]float64{-20, 0}, gwtest.D)
+	w.Update([]float64{-30, 0}, gwtest.D)
+	c := w.Render(gowid.RenderFlowWith{C: 10}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "L ██  ▕   \nR ████████\n  -40 -10 ", c.String())
+
+	w = New(Options{Vertical: true, Labels: []string{"M"}, Scale: true, MinDB: -40, PeakHold: -1})
+	w.Update([]float64{-20}, gwtest.D)
+	c = w.Render(gowid.RenderBox{C: 5, R: 5}, gowid.NotSelected, gwtest.D)
+	assert.Equal(t, "  0  \n-10  \n-20 █\n-40 █\n    M", c.String())
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End:
@@ -0,0 +1,239 @@
+// Copyright 2019 Graham Clark. All rights reserved.  Use of this source
+// code is governed by the MIT license that can be found in the LICENSE
+// file.
+
+package gowid
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell"
+)
+
+//======================================================================
+
+// DuplicateWorkspaceError is returned when a workspace is added with the name of one the app already has.
+type DuplicateWorkspaceError struct {
+	Name string
+}
+
+var _ error = DuplicateWorkspaceError{}
+
+func (e DuplicateWorkspaceError) Error() string {
+	return fmt.Sprintf("Workspace %q already exists", e.Name)
+}
+
+// Workspace is one of an app's root layouts, e.g. a "capture" view and an "analysis" view, only one of
+// which is shown at a time - see App.AddWorkspace. Each keeps its own widgets, so a workspace that is
+// switched away from and back to has the focus and scroll positions it had before.
+type Workspace struct {
+	Name string
+	View IWidget
+	Key  IKey // If not nil, pressing this key switches to the workspace
+
+	// OnShow, if not nil, is called when the workspace becomes the one shown, and OnHide when it stops
+	// being - e.g. to resume and pause background work that only matters while the workspace is seen.
+	OnShow func(app IApp)
+	OnHide func(app IApp)
+}
+
+// IWorkspaceAware is implemented by a widget that wants to know when the workspace it is in is shown or
+// hidden - see App.AddWorkspace. The app finds such widgets by walking the workspace's widgets, as
+// AccessibilityTree does, so a widget that is not reachable from the workspace's view isn't told.
+type IWorkspaceAware interface {
+	WorkspaceShown(name string, app IApp)
+	WorkspaceHidden(name string, app IApp)
+}
+
+type workspaces struct {
+	list    []*Workspace
+	current int // Index into list, or -1 if no workspace has been shown
+	next    IKey
+	prev    IKey
+}
+
+//======================================================================
+
+// AddWorkspace adds a root layout that the app can switch to. The first workspace added is shown
+// straight away, replacing the app's view; later ones are hidden until SwitchWorkspace is called, or the
+// workspace's key is pressed. Menus registered with the app stay above whichever workspace is shown.
+func (a *App) AddWorkspace(ws Workspace) error {
+	for _, w := range a.workspaces.list {
+		if w.Name == ws.Name {
+			return DuplicateWorkspaceError{Name: ws.Name}
+		}
+	}
+	a.workspaces.list = append(a.workspaces.list, &ws)
+	if a.workspaces.current == -1 {
+		a.showWorkspace(len(a.workspaces.list) - 1)
+	}
+	return nil
+}
+
+// RemoveWorkspace removes the named workspace. If it is shown, the app switches to the next one first.
+// The last workspace can't be removed. It returns false if the workspace could not be removed.
+func (a *App) RemoveWorkspace(name string) bool {
+	i := a.workspaceIndex(name)
+	if i == -1 || len(a.workspaces.list) == 1 {
+		return false
+	}
+	if i == a.workspaces.current {
+		a.NextWorkspace()
+	}
+	a.workspaces.list = append(a.workspaces.list[:i], a.workspaces.list[i+1:]...)
+	if a.workspaces.current > i {
+		a.workspaces.current--
+	}
+	return true
+}
+
+// Workspaces returns the names of the app's workspaces, in the order they were added.
+func (a *App) Workspaces() []string {
+	res := make([]string, 0, len(a.workspaces.list))
+	for _, w := range a.workspaces.list {
+		res = append(res, w.Name)
+	}
+	return res
+}
+
+// CurrentWorkspace returns the name of the workspace shown, or "" if the app has none.
+func (a *App) CurrentWorkspace() string {
+	if a.workspaces.current == -1 {
+		return ""
+	}
+	return a.workspaces.list[a.workspaces.current].Name
+}
+
+// SwitchWorkspace shows the named workspace in place of the current one. The current workspace's OnHide,
+// and the WorkspaceHidden method of each of its IWorkspaceAware widgets, are called first; then those of
+// the new workspace for being shown. It returns false if there is no such workspace.
+func (a *App) SwitchWorkspace(name string) bool {
+	i := a.workspaceIndex(name)
+	if i == -1 {
+		return false
+	}
+	a.showWorkspace(i)
+	return true
+}
+
+// NextWorkspace switches to the workspace added after the current one, wrapping around to the first.
+func (a *App) NextWorkspace() {
+	a.cycleWorkspace(1)
+}
+
+// PrevWorkspace switches to the workspace added before the current one, wrapping around to the last.
+func (a *App) PrevWorkspace() {
+	a.cycleWorkspace(-1)
+}
+
+// SetWorkspaceKeys sets the keys that switch to the next and previous workspaces. Either may be nil.
+func (a *App) SetWorkspaceKeys(next, prev IKey) {
+	a.workspaces.next = next
+	a.workspaces.prev = prev
+}
+
+func (a *App) workspaceIndex(name string) int {
+	for i, w := range a.workspaces.list {
+		if w.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (a *App) cycleWorkspace(delta int) {
+	n := len(a.workspaces.list)
+	if n == 0 {
+		return
+	}
+	a.showWorkspace((a.workspaces.current + delta + n) % n)
+}
+
+func (a *App) showWorkspace(i int) {
+	ws := &a.workspaces
+	if i == ws.current {
+		return
+	}
+	if ws.current != -1 {
+		old := ws.list[ws.current]
+		notifyWorkspace(old.View, a, func(w IWorkspaceAware) {
+			w.WorkspaceHidden(old.Name, a)
+		})
+		if old.OnHide != nil {
+			old.OnHide(a)
+		}
+	}
+	ws.current = i
+	cur := ws.list[i]
+	a.setView(cur.View)
+	if cur.OnShow != nil {
+		cur.OnShow(a)
+	}
+	notifyWorkspace(cur.View, a, func(w IWorkspaceAware) {
+		w.WorkspaceShown(cur.Name, a)
+	})
+	a.Redraw()
+}
+
+// setView makes w the app's view, beneath any registered menus.
+func (a *App) setView(w IWidget) {
+	var parent ISettableComposite
+	cur := a.viewPlusMenus
+	for cur != a.view {
+		m, ok := cur.(IMenuCompatible)
+		if !ok {
+			parent = nil
+			break
+		}
+		parent, cur = m, m.SubWidget()
+	}
+	if parent == nil {
+		a.viewPlusMenus = w
+	} else {
+		parent.SetSubWidget(w, a)
+	}
+	a.view = w
+}
+
+// notifyWorkspace calls f with each IWorkspaceAware widget beneath w, including w.
+func notifyWorkspace(w IWidget, app IApp, f func(w IWorkspaceAware)) {
+	if w == nil {
+		return
+	}
+	if aw, ok := w.(IWorkspaceAware); ok {
+		f(aw)
+	}
+	children, _ := accessibleChildren(w, app)
+	for _, c := range children {
+		notifyWorkspace(c, app, f)
+	}
+}
+
+// workspaceInput returns true if ev was a keypress that switched workspaces.
+func (a *App) workspaceInput(ev interface{}) bool {
+	kev, ok := ev.(*tcell.EventKey)
+	if !ok || len(a.workspaces.list) == 0 {
+		return false
+	}
+	switch {
+	case a.workspaces.next != nil && KeysEqual(kev, a.workspaces.next):
+		a.NextWorkspace()
+		return true
+	case a.workspaces.prev != nil && KeysEqual(kev, a.workspaces.prev):
+		a.PrevWorkspace()
+		return true
+	}
+	for _, w := range a.workspaces.list {
+		if w.Key != nil && KeysEqual(kev, w.Key) {
+			a.SwitchWorkspace(w.Name)
+			return true
+		}
+	}
+	return false
+}
+
+//======================================================================
+// Local Variables:
+// mode: Go
+// fill-column: 110
+// End: